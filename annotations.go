@@ -0,0 +1,66 @@
+package gotype
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// defaultAnnotationMarkers contains the default prefixes of the marker comments. See `WithAnnotationMarkers`.
+var defaultAnnotationMarkers = []string{"+gotype:"}
+
+// fieldAnnotations parses the annotations of a struct's field from its comments.
+//
+// The marker lines, like `// +gotype:min=1` or `// +gotype:required`, are parsed from both the doc comment and the
+// trailing comment of the field, and the value of a marker without "=" is empty. The other text of the trailing
+// comment is parsed as a comma-separated list of annotations, like `// required, max 64 chars`, whose keys are their
+// first words, or the text before "=" or ":", like "max": "64 chars". The doc comment is prose, so it's only parsed for
+// markers.
+func (f *astTypeGenerator) fieldAnnotations(field *ast.Field) map[string]string {
+	annotations := make(map[string]string)
+	for _, group := range []*ast.CommentGroup{field.Doc, field.Comment} {
+		for _, line := range strings.Split(group.Text(), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			if key, value, ok := f.parseAnnotationMarker(line); ok {
+				annotations[key] = value
+				continue
+			}
+			if group != field.Comment {
+				continue
+			}
+			for _, item := range strings.Split(line, ",") {
+				if key, value := parseAnnotation(item); key != "" {
+					annotations[key] = value
+				}
+			}
+		}
+	}
+	if len(annotations) == 0 {
+		return nil
+	}
+	return annotations
+}
+
+// parseAnnotationMarker parses a marker line, like "+gotype:min=1".
+func (f *astTypeGenerator) parseAnnotationMarker(line string) (string, string, bool) {
+	for _, prefix := range f.config.annotationMarkers {
+		if !strings.HasPrefix(line, prefix) {
+			continue
+		}
+		key, value, _ := strings.Cut(strings.TrimPrefix(line, prefix), "=")
+		return strings.TrimSpace(key), strings.TrimSpace(value), true
+	}
+	return "", "", false
+}
+
+// parseAnnotation parses a free-form annotation, like "required", "max 64 chars", "max=64" or "max: 64".
+func parseAnnotation(item string) (string, string) {
+	item = strings.TrimSpace(item)
+	if index := strings.IndexAny(item, "=:"); index >= 0 {
+		return strings.TrimSpace(item[:index]), strings.TrimSpace(item[index+1:])
+	}
+	key, value, _ := strings.Cut(item, " ")
+	return key, strings.TrimSpace(value)
+}
@@ -0,0 +1,27 @@
+package gotype
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldAnnotations(t *testing.T) {
+	typeSpec := TypeSpec{PackagePath: testdataPackage + "/annotations", Name: "User"}
+
+	types, err := GenerateTypesFromSpecs(typeSpec)
+	require.NoError(t, err)
+	fields := types[0].StructType.Fields
+	require.Len(t, fields, 4)
+	assert.Equal(t, map[string]string{"min": "1", "trim": "", "required": "", "max": "64 chars"}, fields[0].Annotations)
+	assert.Equal(t, map[string]string{"max": "150"}, fields[1].Annotations)
+	assert.Equal(t, map[string]string{"format": "email"}, fields[2].Annotations)
+	assert.Nil(t, fields[3].Annotations)
+
+	types, err = NewGenerator(WithAnnotationMarkers("+kubebuilder:validation:")).GenerateTypesFromSpecs(typeSpec)
+	require.NoError(t, err)
+	fields = types[0].StructType.Fields
+	assert.Equal(t, map[string]string{"required": "", "max": "64 chars"}, fields[0].Annotations)
+	assert.Equal(t, "18", fields[1].Annotations["Minimum"])
+}
@@ -0,0 +1,234 @@
+package gotype
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/module"
+)
+
+// archiveSourceFinder finds the packages of the modules stored inside zip archives, like the ones inside the module
+// cache or served by a module proxy, and reads their source files without unpacking the archives. The packages of the
+// other modules are found by the `fallback` SourceFinder.
+type archiveSourceFinder struct {
+	archivePaths []string
+	fallback     SourceFinder
+	buildConfig  *Config
+
+	// archives contains the opened archives. They're opened on the first use, so the errors are reported by the
+	// generator. It's guarded by mu.
+	archives []*moduleArchive
+	mu       sync.Mutex
+}
+
+// moduleArchive is the content of a module's zip archive.
+type moduleArchive struct {
+	module module.Version
+
+	// files maps the source files' names to their entries. The names are the archive's path joined with the entries'
+	// names, so they can be told apart from the files on the disk.
+	files map[string]*zip.File
+
+	// packages maps the package paths to the names of their source files.
+	packages map[string][]string
+}
+
+func (s *archiveSourceFinder) GetPackageSourceFiles(packagePath string) ([]string, error) {
+	archive, err := s.findArchive(packagePath)
+	if err != nil {
+		return nil, err
+	}
+	if archive == nil {
+		return s.fallback.GetPackageSourceFiles(packagePath)
+	}
+
+	packagePath, _ = splitPackageVersion(packagePath)
+	goSources := make([]string, 0, len(archive.packages[packagePath]))
+	for _, filename := range archive.packages[packagePath] {
+		match, err := s.buildConfig.matchFile(filepath.Dir(filename), filepath.Base(filename), archive.open)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read build constraints of %s: %w", filename, err)
+		}
+		if match {
+			goSources = append(goSources, filename)
+		}
+	}
+	return goSources, nil
+}
+
+func (s *archiveSourceFinder) ReadSourceFile(filename string) ([]byte, error) {
+	s.mu.Lock()
+	archives := s.archives
+	s.mu.Unlock()
+
+	for _, archive := range archives {
+		if _, ok := archive.files[filename]; !ok {
+			continue
+		}
+
+		reader, err := archive.open(filename)
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		return ioutil.ReadAll(reader)
+	}
+
+	if reader, ok := s.fallback.(sourceReader); ok {
+		return reader.ReadSourceFile(filename)
+	}
+	return ioutil.ReadFile(filename)
+}
+
+// ListPackages returns the packages matched by `pattern`, see `defaultSourceFinder.ListPackages`. The packages of the
+// archived modules are listed from their archives.
+func (s *archiveSourceFinder) ListPackages(pattern string) ([]string, error) {
+	rootPackage := strings.TrimSuffix(pattern, "/...")
+	archive, err := s.findArchive(rootPackage)
+	if err != nil {
+		return nil, err
+	}
+	if archive == nil {
+		if lister, ok := s.fallback.(packageLister); ok {
+			return lister.ListPackages(pattern)
+		}
+		return []string{pattern}, nil
+	}
+	if rootPackage == pattern {
+		return []string{pattern}, nil
+	}
+
+	rootPackage, _ = splitPackageVersion(rootPackage)
+	return listPackagesUnder(archive.packages, rootPackage), nil
+}
+
+// listPackagesUnder returns the sorted paths of the `packages` which are `rootPackage` or are inside it. Like the go
+// tool, the packages inside the "testdata" and "vendor" directories are skipped.
+func listPackagesUnder(packages map[string][]string, rootPackage string) []string {
+	packagePaths := make([]string, 0)
+	for packagePath := range packages {
+		if packagePath != rootPackage && !strings.HasPrefix(packagePath, rootPackage+"/") {
+			continue
+		}
+		skipped := false
+		for _, name := range strings.Split(strings.TrimPrefix(packagePath, rootPackage), "/") {
+			if name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				skipped = true
+			}
+		}
+		if !skipped {
+			packagePaths = append(packagePaths, packagePath)
+		}
+	}
+	sort.Strings(packagePaths)
+	return packagePaths
+}
+
+// findArchive returns the archive of the module containing the package, or nil when the package isn't archived. A
+// package path having a version only matches the archive of that version.
+func (s *archiveSourceFinder) findArchive(packagePath string) (*moduleArchive, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.openArchives(); err != nil {
+		return nil, err
+	}
+
+	packagePath, version := splitPackageVersion(packagePath)
+	for _, archive := range s.archives {
+		if version != "" && version != archive.module.Version {
+			continue
+		}
+		if packagePath == archive.module.Path || strings.HasPrefix(packagePath, archive.module.Path+"/") {
+			return archive, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *archiveSourceFinder) openArchives() error {
+	if len(s.archives) == len(s.archivePaths) {
+		return nil
+	}
+
+	for _, archivePath := range s.archivePaths[len(s.archives):] {
+		archive, err := openModuleArchive(normalizePath(archivePath))
+		if err != nil {
+			return err
+		}
+		s.archives = append(s.archives, archive)
+	}
+	return nil
+}
+
+// open opens the archived source file named `filename`.
+func (a *moduleArchive) open(filename string) (io.ReadCloser, error) {
+	file, ok := a.files[filename]
+	if !ok {
+		return nil, fmt.Errorf("file %s is not inside module %s", filename, a.module.String())
+	}
+	reader, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("cannot open %s inside module %s: %w", file.Name, a.module.String(), err)
+	}
+	return reader, nil
+}
+
+// openModuleArchive reads the module's zip archive. The entries of the archive are named like
+// "example.com/mod@v1.2.3/pkg/file.go", following the module zip format. The archive is read into the memory, the
+// entries are only decompressed when they're parsed.
+func openModuleArchive(archivePath string) (*moduleArchive, error) {
+	content, err := ioutil.ReadFile(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read module archive: %w", err)
+	}
+	reader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read module archive %s: %w", archivePath, err)
+	}
+
+	archive := &moduleArchive{files: make(map[string]*zip.File), packages: make(map[string][]string)}
+	for _, file := range reader.File {
+		at := strings.Index(file.Name, "@")
+		end := -1
+		if at >= 0 {
+			end = strings.Index(file.Name[at:], "/")
+		}
+		if end < 0 {
+			return nil, fmt.Errorf("invalid module archive %s: file %s is outside of the module", archivePath, file.Name)
+		}
+		prefix := file.Name[:at+end]
+		modVer := module.Version{Path: prefix[:at], Version: prefix[at+1:]}
+
+		if archive.module.Path == "" {
+			archive.module = modVer
+		} else if archive.module != modVer {
+			return nil, fmt.Errorf("invalid module archive %s: it contains %s and %s", archivePath, archive.module, modVer)
+		}
+
+		name := strings.TrimPrefix(file.Name, prefix+"/")
+		if file.FileInfo().IsDir() || path.Ext(name) != ".go" {
+			continue
+		}
+
+		filename := filepath.Join(archivePath, filepath.FromSlash(file.Name))
+		archive.files[filename] = file
+		packagePath := modVer.Path
+		if dir := path.Dir(name); dir != "." {
+			packagePath += "/" + dir
+		}
+		archive.packages[packagePath] = append(archive.packages[packagePath], filename)
+	}
+
+	if archive.module.Path == "" {
+		return nil, fmt.Errorf("invalid module archive %s: it's empty", archivePath)
+	}
+	return archive, nil
+}
@@ -0,0 +1,531 @@
+package gotype
+
+import (
+	"fmt"
+	"go/token"
+	"reflect"
+)
+
+// TypeID identifies a node of a TypeArena, which is its index inside `TypeArena.Nodes`.
+type TypeID int32
+
+// NoType is the TypeID of a missing type, like the Underlying of an unresolved QualType.
+const NoType TypeID = -1
+
+// TypeNodeKind represents the kind of a TypeNode, that is, the non-nil field of the Type it represents.
+type TypeNodeKind uint8
+
+const (
+	// TypeNodeInvalid represents an empty Type.
+	TypeNodeInvalid TypeNodeKind = iota
+	TypeNodePrimitive
+	TypeNodeQual
+	TypeNodeChan
+	TypeNodeSlice
+	TypeNodePtr
+	TypeNodeArray
+	TypeNodeMap
+	TypeNodeFunc
+	TypeNodeStruct
+	TypeNodeInterface
+	TypeNodeTypeParam
+)
+
+// NodeSpan is the range [Start, End) of the elements of a TypeNode stored inside one of the slices of its TypeArena.
+type NodeSpan struct {
+	Start int32
+	End   int32
+}
+
+// TypeNode is a Type stored inside a TypeArena, referring to its children by their TypeIDs, and to its lists, like the
+// fields of a struct, by their spans inside the slices of the arena.
+type TypeNode struct {
+	Kind TypeNodeKind
+
+	// Name contains the kind of a primitive type, the name of a named type, or the name of a type parameter.
+	Name string
+
+	// Package and ShortPackagePath contain the package of a named type.
+	Package          string
+	ShortPackagePath string
+
+	// Len contains the bits of a primitive type, the length of an array, or the ChanTypeDir of a channel.
+	Len int
+
+	// Elem contains the element of a channel, a slice, a pointer, an array or a map, or the Underlying of a named
+	// type, which is NoType when it's unresolved.
+	Elem TypeID
+
+	// Key contains the key of a map.
+	Key TypeID
+
+	// IsVariadic is true for a variadic function.
+	IsVariadic bool
+
+	// Args contains the span inside `TypeArena.IDs` of the type arguments of a named type, or of the embedded
+	// interfaces of an interface.
+	Args NodeSpan
+
+	// Fields contains the span inside `TypeArena.Fields` of the fields of a struct, or of the inputs of a function.
+	Fields NodeSpan
+
+	// Results contains the span inside `TypeArena.Fields` of the outputs of a function.
+	Results NodeSpan
+
+	// Methods contains the span inside `TypeArena.Methods` of the methods of an interface, or of the methods declared
+	// with a type declaration as their receiver, see `Type.Methods`.
+	Methods NodeSpan
+
+	// Unions contains the span inside `TypeArena.Unions` of the unions of an interface.
+	Unions NodeSpan
+
+	// TypeParams contains the span inside `TypeArena.TypeParams` of the type parameters of a generic type.
+	TypeParams NodeSpan
+
+	// Chain contains the span inside `TypeArena.Links` of the declaration chain of a named type.
+	Chain NodeSpan
+
+	// Position contains the location of the type declaration the type is generated from, see `Type.Position`.
+	Position token.Position
+
+	// Doc contains the documentation comment of the type declaration, see `Type.Doc`.
+	Doc string
+
+	// IsAlias is true when the type declaration is an alias, see `Type.IsAlias`.
+	IsAlias bool
+}
+
+// FieldNode is a TypeField stored inside a TypeArena.
+type FieldNode struct {
+	Name        string
+	Type        TypeID
+	Position    token.Position
+	Tag         reflect.StructTag
+	Annotations map[string]string
+	Embedded    bool
+	Doc         string
+	Comment     string
+}
+
+// MethodNode is an InterfaceTypeMethod or a Method stored inside a TypeArena. Func is the TypeID of a function, and
+// Origin is the TypeID of a named type, or NoType.
+type MethodNode struct {
+	Name            string
+	Func            TypeID
+	Origin          TypeID
+	PointerReceiver bool
+	Doc             string
+	Comment         string
+	Position        token.Position
+}
+
+// TypeParamNode is a TypeParam stored inside a TypeArena. ConstraintInterface is the TypeID of an interface, or NoType.
+type TypeParamNode struct {
+	Name                string
+	Constraint          TypeID
+	ConstraintInterface TypeID
+}
+
+// TermNode is a TypeTerm stored inside a TypeArena.
+type TermNode struct {
+	Tilde bool
+	Type  TypeID
+}
+
+// TypeArena is a flat representation of the Type graphs, whose types are nodes stored inside a slice and referring to
+// their children by index, instead of a deep graph of small heap objects. It's cheaper to build, compare and serialize
+// for large extractions, like the types of a monorepo, since its few slices are encoded as they are, e.g. by
+// encoding/gob or encoding/json.
+//
+// The types are interned: the identical subgraphs, like the Underlying of a named type referenced by many fields, are
+// stored once, so two types added to the same arena are equal when they have the same TypeID. The equality covers all
+// the fields of the types, like the positions and the names of the function parameters, unlike Identical.
+//
+// Add converts a Type into the arena, and Type converts it back. A TypeArena isn't safe for concurrent use.
+type TypeArena struct {
+	Nodes      []TypeNode
+	IDs        []TypeID
+	Fields     []FieldNode
+	Methods    []MethodNode
+	TypeParams []TypeParamNode
+	Unions     []NodeSpan
+	Terms      []TermNode
+	Links      []DeclarationLink
+
+	// index contains the TypeIDs of the nodes keyed by their contents. It's rebuilt by Add when the arena is decoded.
+	index map[string]TypeID
+}
+
+// typeNodeContent is the content of a TypeNode, including the elements of its spans, which identifies it.
+type typeNodeContent struct {
+	node       TypeNode
+	ids        []TypeID
+	fields     []FieldNode
+	results    []FieldNode
+	methods    []MethodNode
+	unions     [][]TermNode
+	typeParams []TypeParamNode
+	chain      []DeclarationLink
+}
+
+// key returns the key of the content inside the index. The spans of the node are part of the content already.
+func (c typeNodeContent) key() string {
+	node := c.node
+	node.Args, node.Fields, node.Results, node.Methods = NodeSpan{}, NodeSpan{}, NodeSpan{}, NodeSpan{}
+	node.Unions, node.TypeParams, node.Chain = NodeSpan{}, NodeSpan{}, NodeSpan{}
+	c.node = node
+	// the empty lists are nil, whether they're built by Add or sliced from the arena.
+	if len(c.ids) == 0 {
+		c.ids = nil
+	}
+	if len(c.fields) == 0 {
+		c.fields = nil
+	}
+	if len(c.results) == 0 {
+		c.results = nil
+	}
+	if len(c.methods) == 0 {
+		c.methods = nil
+	}
+	if len(c.unions) == 0 {
+		c.unions = nil
+	}
+	if len(c.typeParams) == 0 {
+		c.typeParams = nil
+	}
+	if len(c.chain) == 0 {
+		c.chain = nil
+	}
+	// %#v quotes the strings and sorts the maps, so the key is unambiguous and deterministic.
+	return fmt.Sprintf("%#v", c)
+}
+
+// Add stores `typ` inside the arena and returns its TypeID. Adding an identical type returns the same TypeID.
+func (a *TypeArena) Add(typ Type) TypeID {
+	if a.index == nil {
+		a.reindex()
+	}
+
+	c := typeNodeContent{node: TypeNode{
+		Elem:     NoType,
+		Key:      NoType,
+		Position: typ.Position,
+		Doc:      typ.Doc,
+		IsAlias:  typ.IsAlias,
+	}}
+	for _, param := range typ.TypeParams {
+		constraintInterface := NoType
+		if param.ConstraintInterface != nil {
+			constraintInterface = a.Add(Type{InterfaceType: param.ConstraintInterface})
+		}
+		c.typeParams = append(c.typeParams, TypeParamNode{
+			Name:                param.Name,
+			Constraint:          a.Add(param.Constraint),
+			ConstraintInterface: constraintInterface,
+		})
+	}
+
+	switch {
+	case typ.PrimitiveType != nil:
+		c.node.Kind = TypeNodePrimitive
+		c.node.Name = string(typ.PrimitiveType.Kind)
+		c.node.Len = typ.PrimitiveType.Bits
+	case typ.QualType != nil:
+		c.node.Kind = TypeNodeQual
+		c.node.Name = typ.QualType.Name
+		c.node.Package = typ.QualType.Package
+		c.node.ShortPackagePath = typ.QualType.ShortPackagePath
+		if typ.QualType.Underlying != nil {
+			c.node.Elem = a.Add(*typ.QualType.Underlying)
+		}
+		for _, arg := range typ.QualType.TypeArgs {
+			c.ids = append(c.ids, a.Add(arg))
+		}
+		c.chain = typ.QualType.Chain
+	case typ.ChanType != nil:
+		c.node.Kind = TypeNodeChan
+		c.node.Len = int(typ.ChanType.Dir)
+		c.node.Elem = a.Add(typ.ChanType.Elem)
+	case typ.SliceType != nil:
+		c.node.Kind = TypeNodeSlice
+		c.node.Elem = a.Add(typ.SliceType.Elem)
+	case typ.PtrType != nil:
+		c.node.Kind = TypeNodePtr
+		c.node.Elem = a.Add(typ.PtrType.Elem)
+	case typ.ArrayType != nil:
+		c.node.Kind = TypeNodeArray
+		c.node.Len = typ.ArrayType.Len
+		c.node.Elem = a.Add(typ.ArrayType.Elem)
+	case typ.MapType != nil:
+		c.node.Kind = TypeNodeMap
+		c.node.Key = a.Add(typ.MapType.Key)
+		c.node.Elem = a.Add(typ.MapType.Elem)
+	case typ.FuncType != nil:
+		c.node.Kind = TypeNodeFunc
+		c.node.IsVariadic = typ.FuncType.IsVariadic
+		c.fields = a.fieldNodes(typ.FuncType.Inputs)
+		c.results = a.fieldNodes(typ.FuncType.Outputs)
+	case typ.StructType != nil:
+		c.node.Kind = TypeNodeStruct
+		c.fields = a.fieldNodes(typ.StructType.Fields)
+	case typ.InterfaceType != nil:
+		c.node.Kind = TypeNodeInterface
+		for _, method := range typ.InterfaceType.Methods {
+			origin := NoType
+			if method.Origin != nil {
+				origin = a.Add(method.Origin.Type())
+			}
+			funcType := method.Func
+			c.methods = append(c.methods, MethodNode{
+				Name:     method.Name,
+				Func:     a.Add(Type{FuncType: &funcType}),
+				Origin:   origin,
+				Doc:      method.Doc,
+				Comment:  method.Comment,
+				Position: method.Position,
+			})
+		}
+		for _, embedded := range typ.InterfaceType.Embedded {
+			c.ids = append(c.ids, a.Add(embedded.Type()))
+		}
+		for _, union := range typ.InterfaceType.Unions {
+			terms := make([]TermNode, 0, len(union))
+			for _, term := range union {
+				terms = append(terms, TermNode{Tilde: term.Tilde, Type: a.Add(term.Type)})
+			}
+			c.unions = append(c.unions, terms)
+		}
+	case typ.TypeParamType != nil:
+		c.node.Kind = TypeNodeTypeParam
+		c.node.Name = typ.TypeParamType.Name
+	}
+	// an interface can't be a receiver, so its node never has both kinds of methods.
+	for _, method := range typ.Methods {
+		funcType := method.Func
+		c.methods = append(c.methods, MethodNode{
+			Name:            method.Name,
+			Func:            a.Add(Type{FuncType: &funcType}),
+			Origin:          NoType,
+			PointerReceiver: method.PointerReceiver,
+			Doc:             method.Doc,
+			Position:        method.Position,
+		})
+	}
+
+	key := c.key()
+	if id, ok := a.index[key]; ok {
+		return id
+	}
+	id := a.store(c)
+	a.index[key] = id
+	return id
+}
+
+func (a *TypeArena) fieldNodes(fields []TypeField) []FieldNode {
+	nodes := make([]FieldNode, 0, len(fields))
+	for _, field := range fields {
+		nodes = append(nodes, FieldNode{
+			Name:        field.Name,
+			Type:        a.Add(field.Type),
+			Position:    field.Position,
+			Tag:         field.Tag,
+			Annotations: field.Annotations,
+			Embedded:    field.Embedded,
+			Doc:         field.Doc,
+			Comment:     field.Comment,
+		})
+	}
+	return nodes
+}
+
+// store appends the content to the arena's slices.
+func (a *TypeArena) store(c typeNodeContent) TypeID {
+	node := c.node
+	node.Args = NodeSpan{Start: int32(len(a.IDs))}
+	a.IDs = append(a.IDs, c.ids...)
+	node.Args.End = int32(len(a.IDs))
+
+	node.Fields = NodeSpan{Start: int32(len(a.Fields))}
+	a.Fields = append(a.Fields, c.fields...)
+	node.Fields.End = int32(len(a.Fields))
+	node.Results = NodeSpan{Start: int32(len(a.Fields))}
+	a.Fields = append(a.Fields, c.results...)
+	node.Results.End = int32(len(a.Fields))
+
+	node.Methods = NodeSpan{Start: int32(len(a.Methods))}
+	a.Methods = append(a.Methods, c.methods...)
+	node.Methods.End = int32(len(a.Methods))
+
+	node.Unions = NodeSpan{Start: int32(len(a.Unions))}
+	for _, terms := range c.unions {
+		span := NodeSpan{Start: int32(len(a.Terms))}
+		a.Terms = append(a.Terms, terms...)
+		span.End = int32(len(a.Terms))
+		a.Unions = append(a.Unions, span)
+	}
+	node.Unions.End = int32(len(a.Unions))
+
+	node.TypeParams = NodeSpan{Start: int32(len(a.TypeParams))}
+	a.TypeParams = append(a.TypeParams, c.typeParams...)
+	node.TypeParams.End = int32(len(a.TypeParams))
+
+	node.Chain = NodeSpan{Start: int32(len(a.Links))}
+	a.Links = append(a.Links, c.chain...)
+	node.Chain.End = int32(len(a.Links))
+
+	a.Nodes = append(a.Nodes, node)
+	return TypeID(len(a.Nodes) - 1)
+}
+
+// content returns the content of the stored node.
+func (a *TypeArena) content(node TypeNode) typeNodeContent {
+	c := typeNodeContent{
+		node:       node,
+		ids:        a.IDs[node.Args.Start:node.Args.End],
+		fields:     a.Fields[node.Fields.Start:node.Fields.End],
+		results:    a.Fields[node.Results.Start:node.Results.End],
+		methods:    a.Methods[node.Methods.Start:node.Methods.End],
+		typeParams: a.TypeParams[node.TypeParams.Start:node.TypeParams.End],
+		chain:      a.Links[node.Chain.Start:node.Chain.End],
+	}
+	for _, span := range a.Unions[node.Unions.Start:node.Unions.End] {
+		c.unions = append(c.unions, a.Terms[span.Start:span.End])
+	}
+	return c
+}
+
+// reindex rebuilds the index of the nodes, like after the arena is decoded.
+func (a *TypeArena) reindex() {
+	a.index = make(map[string]TypeID, len(a.Nodes))
+	for id, node := range a.Nodes {
+		a.index[a.content(node).key()] = TypeID(id)
+	}
+}
+
+// Type converts the node `id` back into a Type. The returned Type doesn't share its pointers with the arena nor with
+// the other returned Types. The empty lists, like the fields of an empty struct, are converted back as nil slices.
+func (a *TypeArena) Type(id TypeID) Type {
+	node := a.Nodes[id]
+	c := a.content(node)
+
+	typ := Type{Position: node.Position, Doc: node.Doc, IsAlias: node.IsAlias}
+	for _, param := range c.typeParams {
+		typeParam := TypeParam{Name: param.Name, Constraint: a.Type(param.Constraint)}
+		if param.ConstraintInterface != NoType {
+			typeParam.ConstraintInterface = a.Type(param.ConstraintInterface).InterfaceType
+		}
+		typ.TypeParams = append(typ.TypeParams, typeParam)
+	}
+
+	switch node.Kind {
+	case TypeNodePrimitive:
+		typ.PrimitiveType = &PrimitiveType{Kind: PrimitiveKind(node.Name), Bits: node.Len}
+	case TypeNodeQual:
+		typ.QualType = &QualType{
+			Package:          node.Package,
+			ShortPackagePath: node.ShortPackagePath,
+			Name:             node.Name,
+			TypeArgs:         a.types(c.ids),
+		}
+		if node.Elem != NoType {
+			underlying := a.Type(node.Elem)
+			typ.QualType.Underlying = &underlying
+		}
+		if len(c.chain) > 0 {
+			typ.QualType.Chain = append([]DeclarationLink(nil), c.chain...)
+		}
+	case TypeNodeChan:
+		typ.ChanType = &ChanType{Dir: ChanTypeDir(node.Len), Elem: a.Type(node.Elem)}
+	case TypeNodeSlice:
+		typ.SliceType = &SliceType{Elem: a.Type(node.Elem)}
+	case TypeNodePtr:
+		typ.PtrType = &PtrType{Elem: a.Type(node.Elem)}
+	case TypeNodeArray:
+		typ.ArrayType = &ArrayType{Len: node.Len, Elem: a.Type(node.Elem)}
+	case TypeNodeMap:
+		typ.MapType = &MapType{Key: a.Type(node.Key), Elem: a.Type(node.Elem)}
+	case TypeNodeFunc:
+		typ.FuncType = &FuncType{
+			Inputs:     a.typeFields(c.fields),
+			Outputs:    a.typeFields(c.results),
+			IsVariadic: node.IsVariadic,
+		}
+	case TypeNodeStruct:
+		typ.StructType = &StructType{Fields: a.typeFields(c.fields)}
+	case TypeNodeInterface:
+		typ.InterfaceType = a.interfaceType(c)
+	case TypeNodeTypeParam:
+		typ.TypeParamType = &TypeParamType{Name: node.Name}
+	}
+	if node.Kind != TypeNodeInterface {
+		for _, method := range c.methods {
+			typ.Methods = append(typ.Methods, Method{
+				Name:            method.Name,
+				Func:            *a.Type(method.Func).FuncType,
+				PointerReceiver: method.PointerReceiver,
+				Doc:             method.Doc,
+				Position:        method.Position,
+			})
+		}
+	}
+	return typ
+}
+
+func (a *TypeArena) interfaceType(c typeNodeContent) *InterfaceType {
+	interfaceType := &InterfaceType{}
+	for _, method := range c.methods {
+		interfaceMethod := InterfaceTypeMethod{
+			Name:     method.Name,
+			Func:     *a.Type(method.Func).FuncType,
+			Doc:      method.Doc,
+			Comment:  method.Comment,
+			Position: method.Position,
+		}
+		if method.Origin != NoType {
+			interfaceMethod.Origin = a.Type(method.Origin).QualType
+		}
+		interfaceType.Methods = append(interfaceType.Methods, interfaceMethod)
+	}
+	for _, id := range c.ids {
+		interfaceType.Embedded = append(interfaceType.Embedded, *a.Type(id).QualType)
+	}
+	for _, terms := range c.unions {
+		union := make([]TypeTerm, 0, len(terms))
+		for _, term := range terms {
+			union = append(union, TypeTerm{Tilde: term.Tilde, Type: a.Type(term.Type)})
+		}
+		interfaceType.Unions = append(interfaceType.Unions, union)
+	}
+	return interfaceType
+}
+
+func (a *TypeArena) types(ids []TypeID) []Type {
+	if len(ids) == 0 {
+		return nil
+	}
+	types := make([]Type, 0, len(ids))
+	for _, id := range ids {
+		types = append(types, a.Type(id))
+	}
+	return types
+}
+
+func (a *TypeArena) typeFields(nodes []FieldNode) []TypeField {
+	if len(nodes) == 0 {
+		return nil
+	}
+	fields := make([]TypeField, 0, len(nodes))
+	for _, node := range nodes {
+		fields = append(fields, TypeField{
+			Name:        node.Name,
+			Type:        a.Type(node.Type),
+			Position:    node.Position,
+			Tag:         node.Tag,
+			Annotations: node.Annotations,
+			Embedded:    node.Embedded,
+			Doc:         node.Doc,
+			Comment:     node.Comment,
+		})
+	}
+	return fields
+}
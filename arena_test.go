@@ -0,0 +1,84 @@
+package gotype
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypeArena(t *testing.T) {
+	specs := []TypeSpec{
+		{PackagePath: testdataPackage + "/generics", Name: "Repository"},
+		{PackagePath: testdataPackage + "/generics", Name: "Vector"},
+		{PackagePath: testdataPackage + "/generics", Name: "Tree"},
+		{PackagePath: testdataPackage + "/ifaces", Name: "Diamond"},
+		{PackagePath: testdataPackage + "/declarations", Name: "Client"},
+		{PackagePath: testdataPackage + "/annotations", Name: "User"},
+	}
+	generator := NewGenerator(WithDeepResolution(), WithEmbeddedInterfaces(), WithMethods())
+	types, err := generator.GenerateTypesFromSpecs(specs...)
+	require.NoError(t, err)
+
+	arena := &TypeArena{}
+	ids := make([]TypeID, 0, len(types))
+	for _, typ := range types {
+		ids = append(ids, arena.Add(typ))
+	}
+	for n, id := range ids {
+		converted := arena.Type(id)
+		assert.Equal(t, TypeString(types[n]), TypeString(converted))
+		assert.True(t, Identical(types[n], converted))
+		assert.Equal(t, types[n].String(""), converted.String(""))
+		require.Len(t, converted.Methods, len(types[n].Methods))
+		for m, method := range types[n].Methods {
+			assert.Equal(t, method.Name, converted.Methods[m].Name)
+			assert.Equal(t, method.PointerReceiver, converted.Methods[m].PointerReceiver)
+			assert.Equal(t, method.Func.String(""), converted.Methods[m].Func.String(""))
+		}
+		// adding the converted type finds the same node.
+		assert.Equal(t, id, arena.Add(converted))
+	}
+
+	// the decoded arena is equivalent, and keeps interning the types.
+	data, err := json.Marshal(arena)
+	require.NoError(t, err)
+	decoded := &TypeArena{}
+	require.NoError(t, json.Unmarshal(data, decoded))
+	for n, id := range ids {
+		assert.Equal(t, arena.Type(id), decoded.Type(id))
+		assert.Equal(t, id, decoded.Add(types[n]))
+	}
+	assert.Equal(t, len(arena.Nodes), len(decoded.Nodes))
+}
+
+func TestTypeArena_Interning(t *testing.T) {
+	user := Type{QualType: &QualType{Package: "example.com/user", ShortPackagePath: "user", Name: "User"}}
+	underlying := Type{StructType: &StructType{Fields: []TypeField{
+		{Name: "Name", Type: Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindString}}, Tag: `json:"name"`},
+	}}}
+	user.QualType.Underlying = &underlying
+	stringType := Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindString}}
+	team := Type{StructType: &StructType{Fields: []TypeField{
+		{Name: "Owner", Type: user},
+		{Name: "Members", Type: Type{SliceType: &SliceType{Elem: user}}},
+		{Name: "Admins", Type: Type{MapType: &MapType{Key: stringType, Elem: user}}},
+	}}}
+
+	arena := &TypeArena{}
+	id := arena.Add(team)
+	// string, the struct of User, User, []User, map[string]User and the team's struct.
+	assert.Len(t, arena.Nodes, 6)
+	assert.Equal(t, id, arena.Add(team))
+	assert.Equal(t, arena.Fields[arena.Nodes[id].Fields.Start].Type, arena.Add(user))
+
+	// the tags are part of the identity.
+	retagged := Type{StructType: &StructType{Fields: []TypeField{
+		{Name: "Name", Type: Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindString}}},
+	}}}
+	assert.NotEqual(t, arena.Add(underlying), arena.Add(retagged))
+
+	assert.Equal(t, TypeNodeInvalid, arena.Nodes[arena.Add(Type{})].Kind)
+	assert.Equal(t, Type{}, arena.Type(arena.Add(Type{})))
+}
@@ -0,0 +1,157 @@
+package gotype
+
+import (
+	"fmt"
+	"go/ast"
+	"path"
+	"strconv"
+)
+
+// ConformanceAssertion asserts that a named type implements an interface.
+type ConformanceAssertion struct {
+	// Type contains the named type implementing the interface.
+	Type QualType
+
+	// Interface contains the implemented interface.
+	Interface QualType
+
+	// Pointer tells that only the pointer to the type implements the interface, because some of the methods are
+	// declared with pointer receivers.
+	Pointer bool
+}
+
+// RenderConformanceAssertions renders the Golang's source file of the package `packageName`, whose path is
+// `packagePath`, containing a compile-time assertion for each of the `assertions`, like
+// `var _ api.Store = (*impl.FileStore)(nil)`, so the conformance is checked by the compiler. The types declared inside
+// the package itself are referred to without their package names, and the imported packages sharing the same name are
+// aliased. The packages of the QualTypes without a ShortPackagePath are assumed to be named after the last element of
+// their paths.
+func RenderConformanceAssertions(
+	packagePath string,
+	packageName string,
+	assertions []ConformanceAssertion,
+) (string, error) {
+	imports := []Import{{Name: packageName, Package: packagePath}}
+	for _, assertion := range assertions {
+		if assertion.Type.Name == "" || assertion.Interface.Name == "" {
+			return "", fmt.Errorf("cannot render conformance assertion of an unnamed type or interface")
+		}
+		imports = append(imports, assertion.Interface.Type().Imports()...)
+		imports = append(imports, assertion.Type.Type().Imports()...)
+	}
+	for i, imp := range imports {
+		if imp.Name == "" {
+			imports[i].Name = path.Base(imp.Package)
+		}
+	}
+	imports = AliasImports(imports)
+
+	w := &codeWriter{}
+	w.line(0, "package %s", packageName)
+	if len(imports) > 1 {
+		w.line(0, "")
+		w.line(0, "import (")
+		for _, imp := range imports[1:] {
+			if imp.Name == path.Base(imp.Package) {
+				w.line(1, "%s", strconv.Quote(imp.Package))
+			} else {
+				w.line(1, "%s %s", imp.Name, strconv.Quote(imp.Package))
+			}
+		}
+		w.line(0, ")")
+	}
+	if len(assertions) == 0 {
+		return w.b.String(), nil
+	}
+
+	w.line(0, "")
+	w.line(0, "var (")
+	for _, assertion := range assertions {
+		iface := assertion.Interface.Type().WithImports(imports).String(packageName)
+		typ := assertion.Type.Type().WithImports(imports).String(packageName)
+		if assertion.Pointer {
+			w.line(1, "_ %s = (*%s)(nil)", iface, typ)
+		} else {
+			w.line(1, "_ %s = *new(%s)", iface, typ)
+		}
+	}
+	w.line(0, ")")
+	return w.b.String(), nil
+}
+
+// FindImplementations reports which of the interfaces declared inside the packages are implemented by the named types
+// declared inside the packages. See `TypeGenerator.FindImplementations` for the details.
+func FindImplementations(packagePatterns ...string) ([]ConformanceAssertion, error) {
+	return defaultAstTypeGenerator.FindImplementations(packagePatterns...)
+}
+
+func (f *astTypeGenerator) FindImplementations(packagePatterns ...string) ([]ConformanceAssertion, error) {
+	f = f.fork()
+	packages, err := f.expandPackagePatterns(packagePatterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	type namedInterface struct {
+		qualType QualType
+		iface    InterfaceType
+	}
+
+	interfaces := make([]namedInterface, 0)
+	concrete := make([]QualType, 0)
+	for _, packagePath := range packages {
+		names, err := f.getDeclaredTypeNames(packagePath)
+		if err != nil {
+			return nil, err
+		}
+
+		specs := make([]TypeSpec, 0, len(names))
+		for _, name := range names {
+			if ast.IsExported(name) {
+				specs = append(specs, TypeSpec{PackagePath: packagePath, Name: name})
+			}
+		}
+		types, err := f.generateTypesFromSpecs(specs)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, typ := range types {
+			qualType := QualType{Package: packagePath, Name: specs[i].Name}
+			switch {
+			case typ.IsGeneric():
+				// the generic declarations can't be asserted without being instantiated.
+			case typ.InterfaceType != nil:
+				iface := typ.InterfaceType
+				if len(iface.Methods) > 0 && len(iface.Embedded) == 0 && len(iface.Unions) == 0 {
+					interfaces = append(interfaces, namedInterface{qualType: qualType, iface: *iface})
+				}
+			default:
+				concrete = append(concrete, qualType)
+			}
+		}
+	}
+
+	results := make([]ConformanceAssertion, 0)
+	for _, qualType := range concrete {
+		methods, err := f.generateMethods(qualType.Package, qualType.Name)
+		if err != nil {
+			return nil, err
+		}
+		valueMethods, pointerMethods := methodSet(methods, false), methodSet(methods, true)
+
+		for _, iface := range interfaces {
+			assertion := ConformanceAssertion{
+				Type:      f.rewriteQualType(qualType),
+				Interface: f.rewriteQualType(iface.qualType),
+			}
+			if Implements(valueMethods, iface.iface) {
+				results = append(results, assertion)
+			} else if Implements(pointerMethods, iface.iface) {
+				assertion.Pointer = true
+				results = append(results, assertion)
+			}
+		}
+	}
+	return results, nil
+}
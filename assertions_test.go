@@ -0,0 +1,56 @@
+package gotype
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindImplementations(t *testing.T) {
+	api := testdataPackage + "/assertions/api"
+	impl := testdataPackage + "/assertions/impl"
+
+	store, closer := QualType{Package: api, Name: "Store"}, QualType{Package: api, Name: "Closer"}
+	memoryStore, fileStore := QualType{Package: impl, Name: "MemoryStore"}, QualType{Package: impl, Name: "FileStore"}
+
+	assertions, err := FindImplementations(testdataPackage + "/assertions/...")
+	require.NoError(t, err)
+	assert.Equal(t, []ConformanceAssertion{
+		{Type: memoryStore, Interface: store},
+		{Type: memoryStore, Interface: closer, Pointer: true},
+		{Type: fileStore, Interface: store, Pointer: true},
+	}, assertions)
+}
+
+func TestRenderConformanceAssertions(t *testing.T) {
+	api := testdataPackage + "/assertions/api"
+	impl := testdataPackage + "/assertions/impl"
+
+	code, err := RenderConformanceAssertions(impl, "impl", []ConformanceAssertion{
+		{Type: QualType{Package: impl, Name: "FileStore"}, Interface: QualType{Package: api, Name: "Store"}, Pointer: true},
+		{Type: QualType{Package: impl, Name: "MemoryStore"}, Interface: QualType{Package: api, Name: "Store"}},
+		{
+			Type:      QualType{Package: impl, Name: "MemoryStore"},
+			Interface: QualType{Package: "example.com/other/api", ShortPackagePath: "api", Name: "Closer"},
+			Pointer:   true,
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `package impl
+
+import (
+	"github.com/armantarkhanian/gotype/testdata/assertions/api"
+	api2 "example.com/other/api"
+)
+
+var (
+	_ api.Store = (*FileStore)(nil)
+	_ api.Store = *new(MemoryStore)
+	_ api2.Closer = (*MemoryStore)(nil)
+)
+`, code)
+
+	_, err = RenderConformanceAssertions(impl, "impl", []ConformanceAssertion{{Type: QualType{Name: "FileStore"}}})
+	assert.EqualError(t, err, "cannot render conformance assertion of an unnamed type or interface")
+}
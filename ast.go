@@ -1,36 +1,181 @@
 package gotype
 
 import (
+	"context"
 	"fmt"
 	"go/ast"
 	"go/parser"
 	"go/token"
-	"os"
+	"go/types"
+	"io/ioutil"
 	"path"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/mod/module"
 )
 
-type sourceFinder interface {
+// SourceFinder finds the source files of the packages. A SourceFinder can also implement
+// `ListPackages(pattern string) ([]string, error)` to expand package patterns like "example.com/...", and
+// `ReadSourceFile(filename string) ([]byte, error)` when its source files aren't stored on the disk. The built-in
+// SourceFinders can be composed using ChainFinder and MergeFinder, see `WithSourceFinder`.
+type SourceFinder interface {
+	// GetPackageSourceFiles returns the names of the source files of the package. The returned error wraps
+	// ErrPackageNotFound when the package can't be found.
 	GetPackageSourceFiles(packagePath string) ([]string, error)
 }
 
+// packageLister is implemented by the SourceFinders which are able to expand package patterns like "example.com/...".
+type packageLister interface {
+	ListPackages(pattern string) ([]string, error)
+}
+
+// sourceReader is implemented by the SourceFinders whose source files aren't stored on the disk, like the files inside
+// an archive. The files of the other sourceFinders are read from the disk.
+type sourceReader interface {
+	ReadSourceFile(filename string) ([]byte, error)
+}
+
+// typeParamSuffix is appended to the type parameter names stored inside the import map.
+const typeParamSuffix = "__typeparam"
+
+// localTypeSuffix is appended to the names of the types declared inside a function body stored inside the import map.
+const localTypeSuffix = "__local"
+
+// astTypeGenerator implements TypeGenerator. The SourceFinder, the configuration and the FileSet are shared by the
+// goroutines using the generator, while the other fields are the state of a single call, see `fork`.
 type astTypeGenerator struct {
-	sourceFinder sourceFinder
+	sourceFinder SourceFinder
+	config       config
+	fset         *token.FileSet
+
+	// tracing contains the specs being resolved, the innermost is the last. See `WithTrace`.
+	tracing []TypeSpec
+
+	// embedding contains the chain of the embedded interfaces being generated, the innermost is the last.
+	embedding []QualType
+
+	// declarations contains the declarations parsed so far, keyed by their QualType keys. They are used by the deep
+	// resolution to fill `QualType.Chain`.
+	declarations map[string]DeclarationLink
+
+	// labels contains the profiler labels of the running phase, see `startPhase`.
+	labels context.Context
+
+	// parsed contains the files parsed by the call, so each file is parsed once even when several declarations, or
+	// several kinds of declarations, are searched inside its package.
+	parsed map[string]parsedFile
+
+	// constants evaluates the constant expressions of the call, like the array lengths, see `constEvaluator`.
+	constants *constEvaluator
+
+	// typeChecked contains the packages type-checked by the call, see `WithTypeChecking`.
+	typeChecked map[string]*typeCheckedPackage
+
+	// pins contains the versions of the modules pinned by the call, see `modulePins`.
+	pins *modulePins
+}
+
+// modulePins maps the module paths to the versions pinned by the package paths like "example.com/mod/pkg@v1.2.3"
+// found by a call, so the other packages of the modules are found in the same versions during the call. It's shared
+// by the forks working on the same call, see `generatePackagesConcurrently`.
+type modulePins struct {
+	mu       sync.Mutex
+	versions map[string]string
+}
+
+// pin pins the version of the module `mod`.
+func (p *modulePins) pin(mod module.Version) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.versions == nil {
+		p.versions = make(map[string]string)
+	}
+	p.versions[mod.Path] = mod.Version
+}
+
+// pinnedPath returns the package path with the version of its module when the module is pinned. The longest pinned
+// module path wins, so nested modules don't depend on the map's iteration order.
+func (p *modulePins) pinnedPath(packagePath string) string {
+	if p == nil {
+		return packagePath
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pinnedModule, pinnedVersion := "", ""
+	for modulePath, version := range p.versions {
+		if packagePath == modulePath || strings.HasPrefix(packagePath, modulePath+"/") {
+			if len(modulePath) > len(pinnedModule) {
+				pinnedModule, pinnedVersion = modulePath, version
+			}
+		}
+	}
+	if pinnedModule == "" {
+		return packagePath
+	}
+	return packagePath + "@" + pinnedVersion
+}
+
+// parsedFile is the result of parsing a source file. The AST may be partial when there's a syntax error.
+type parsedFile struct {
+	file *ast.File
+	err  error
+}
+
+// fork returns a generator sharing the SourceFinder, the configuration and the FileSet of `f`, with an empty state.
+// Each exported method works on its own fork, so a TypeGenerator can be used by multiple goroutines concurrently.
+func (f *astTypeGenerator) fork() *astTypeGenerator {
+	return &astTypeGenerator{sourceFinder: f.sourceFinder, config: f.config, fset: f.fset, pins: &modulePins{}}
 }
 
 func (f *astTypeGenerator) GenerateTypesFromSpecs(typeSpecs ...TypeSpec) ([]Type, error) {
-	packagePathToSpecs := f.groupTypeSpecByPackage(typeSpecs)
+	f = f.fork()
+	if f.config.determinismCheck {
+		return f.generateCheckedTypesFromSpecs(typeSpecs)
+	}
+	if f.config.partialResults {
+		return f.generatePartialTypesFromSpecs(typeSpecs)
+	}
 
-	resultMap := make(map[TypeSpec]Type)
-	for packagePath, specs := range packagePathToSpecs {
-		types, err := f.generateTypesInSinglePackage(packagePath, specs...)
-		if err != nil {
+	results, err := f.generateTypesFromSpecs(typeSpecs)
+	if err != nil {
+		return nil, err
+	}
+	for i, spec := range typeSpecs {
+		if results[i], err = f.withMethods(spec, results[i]); err != nil {
+			return nil, err
+		}
+		if err := f.checkVisibility(spec, results[i]); err != nil {
 			return nil, err
 		}
+		results[i] = f.rewritePackagePaths(results[i])
+	}
+	return results, nil
+}
+
+// generateTypesFromSpecs generates the types specified by `typeSpecs` without checking their visibility nor rewriting
+// their package paths, so the generator can use it to generate the types it depends on.
+func (f *astTypeGenerator) generateTypesFromSpecs(typeSpecs []TypeSpec) ([]Type, error) {
+	if f.config.typeChecking {
+		return f.generateTypesUsingTypeChecker(typeSpecs)
+	}
+
+	packagePaths, packagePathToSpecs := f.groupTypeSpecByPackage(typeSpecs)
+
+	packageTypes, err := f.generatePackages(packagePaths, packagePathToSpecs)
+	if err != nil {
+		return nil, err
+	}
 
-		for i, typ := range types {
-			resultMap[TypeSpec{PackagePath: packagePath, Name: specs[i]}] = typ
+	resultMap := make(map[TypeSpec]Type)
+	for i, packagePath := range packagePaths {
+		for j, typ := range packageTypes[i] {
+			resultMap[TypeSpec{PackagePath: packagePath, Name: packagePathToSpecs[packagePath][j]}] = typ
 		}
 	}
 
@@ -38,28 +183,82 @@ func (f *astTypeGenerator) GenerateTypesFromSpecs(typeSpecs ...TypeSpec) ([]Type
 	for _, spec := range typeSpecs {
 		results = append(results, resultMap[spec])
 	}
+
+	if f.config.deepResolution {
+		resolver := newDeepResolver(f)
+		for i, spec := range typeSpecs {
+			resolved, err := resolver.resolveDeclaration(spec, results[i])
+			if err != nil {
+				return nil, err
+			}
+			results[i] = resolved
+		}
+	}
+
 	return results, nil
 }
 
-func (f *astTypeGenerator) groupTypeSpecByPackage(typeSpecs []TypeSpec) map[string][]string {
+// generatePackages generates the types of each package, in the order of `packagePaths`. The packages are generated
+// concurrently when the generator is configured using `WithConcurrency`.
+func (f *astTypeGenerator) generatePackages(
+	packagePaths []string,
+	packagePathToSpecs map[string][]string,
+) ([][]Type, error) {
+	if f.config.concurrency > 1 && len(packagePaths) > 1 {
+		return f.generatePackagesConcurrently(packagePaths, packagePathToSpecs)
+	}
+
+	results := make([][]Type, 0, len(packagePaths))
+	for _, packagePath := range packagePaths {
+		types, err := f.generateTypesInSinglePackage(packagePath, packagePathToSpecs[packagePath]...)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, types)
+	}
+	return results, nil
+}
+
+// groupTypeSpecByPackage groups the type names by their package. The packages are returned sorted and the names of
+// each package keep the order of `typeSpecs`, so the packages are always processed in a deterministic order.
+func (f *astTypeGenerator) groupTypeSpecByPackage(typeSpecs []TypeSpec) ([]string, map[string][]string) {
 	result := make(map[string][]string)
+	seen := make(map[TypeSpec]struct{})
+	packagePaths := make([]string, 0)
 	for _, spec := range typeSpecs {
+		if _, ok := seen[spec]; ok {
+			continue
+		}
+		seen[spec] = struct{}{}
+
+		if _, ok := result[spec.PackagePath]; !ok {
+			packagePaths = append(packagePaths, spec.PackagePath)
+		}
 		result[spec.PackagePath] = append(result[spec.PackagePath], spec.Name)
 	}
-	return result
+	sort.Strings(packagePaths)
+	return packagePaths, result
 }
 
-func (f *astTypeGenerator) generateTypesInSinglePackage(packagePath string, names ...string) ([]Type, error) {
-	goSources, err := f.sourceFinder.GetPackageSourceFiles(packagePath)
+func (f *astTypeGenerator) generateTypesInSinglePackage(packagePath string, names ...string) (_ []Type, err error) {
+	end := f.startPhase(phaseGeneratePackage, "gotype.package", packagePath)
+	defer func() { end(err) }()
+
+	goSources, err := f.getPackageSourceFiles(packagePath)
 	if err != nil {
 		return nil, err
 	}
+	// the types declared in a version-pinned package are qualified by the package path without the version.
+	packagePath, _ = splitPackageVersion(packagePath)
 
 	remainingNames := make(map[string]struct{})
 	for _, name := range names {
 		remainingNames[name] = struct{}{}
 	}
 
+	var parseErrs ParseErrors
+	searchedFiles := make([]string, 0, len(goSources))
+	foundTypes := make([]string, 0)
 	resultMap := make(map[string]Type)
 	for _, source := range goSources {
 		if len(remainingNames) == 0 {
@@ -68,29 +267,56 @@ func (f *astTypeGenerator) generateTypesInSinglePackage(packagePath string, name
 
 		fileAst, err := f.parseAstFile(source)
 		if err != nil {
-			return nil, err
+			fileErr, ok := f.tolerateParseError(source, fileAst, err)
+			if !ok {
+				return nil, err
+			}
+			parseErrs = append(parseErrs, fileErr)
 		}
+		searchedFiles = append(searchedFiles, source)
+		foundTypes = append(foundTypes, f.getFileTypeNames(fileAst)...)
 
 		importMap := f.generateImportMap(packagePath, fileAst)
 
-		for name := range remainingNames {
-			spec := f.getDeclarationByName(fileAst, name)
+		for _, name := range names {
+			if _, ok := remainingNames[name]; !ok {
+				continue
+			}
+
+			spec, specImportMap := f.getDeclarationByName(fileAst, name), importMap
+			if spec == nil && f.config.localTypes {
+				spec, specImportMap = f.getLocalDeclarationByName(fileAst, name, importMap)
+			}
 			if spec != nil {
-				resultMap[name], err = f.generateTypeFromExpr(spec.Type, packagePath, importMap)
+				f.beginTrace(TypeSpec{PackagePath: packagePath, Name: name})
+				f.explain(spec.Pos(), "found declaration of %s.%s in %s", packagePath, name, source)
+				f.recordDeclaration(name, spec, packagePath)
+				typ, err := f.generateTypeFromTypeSpec(spec, packagePath, specImportMap)
+				f.endTrace()
 				if err != nil {
 					return nil, err
 				}
+				typ.Doc = typeSpecDoc(fileAst, spec)
+				resultMap[name] = typ
 				delete(remainingNames, name)
 			}
 		}
 	}
 
 	if len(remainingNames) != 0 {
-		// TODO (jauhararifin): give better error message
-		for name := range remainingNames {
-			return nil, fmt.Errorf("cannot find definition of %s. Probably you should organize your go.mod file and impots", name)
+		for _, name := range names {
+			if _, ok := remainingNames[name]; ok {
+				return nil, &TypeNotFoundError{
+					PackagePath:   packagePath,
+					Name:          name,
+					SearchedFiles: searchedFiles,
+					FoundTypes:    foundTypes,
+					ParseErrors:   parseErrs,
+				}
+			}
 		}
 	}
+	f.warnParseErrors(parseErrs)
 
 	results := make([]Type, 0, len(names))
 	for _, name := range names {
@@ -100,19 +326,94 @@ func (f *astTypeGenerator) generateTypesInSinglePackage(packagePath string, name
 	return results, nil
 }
 
+// isOmitted reports whether the declaration named `name` is omitted, because it's unexported and `WithExportedOnly` is
+// set.
+func (f *astTypeGenerator) isOmitted(name string) bool {
+	return f.config.exportedOnly && !ast.IsExported(name)
+}
+
+// position returns the location of `pos`. The locations inside the files carrying //line directives, like the generated
+// ones, map back to the original source files, unless `WithoutLineDirectives` is set.
+func (f *astTypeGenerator) position(pos token.Pos) token.Position {
+	return f.fset.PositionFor(pos, !f.config.ignoreLineDirectives)
+}
+
+func (f *astTypeGenerator) logf(format string, args ...interface{}) {
+	if f.config.logger != nil {
+		f.config.logger.Printf(format, args...)
+	}
+}
+
+func (f *astTypeGenerator) getPackageSourceFiles(packagePath string) (goSources []string, err error) {
+	end := f.startPhase(phaseFindPackage, "gotype.package", packagePath)
+	defer func() { end(err) }()
+
+	start := time.Now()
+	findPath := packagePath
+	if _, version := splitPackageVersion(packagePath); version == "" {
+		findPath = f.pins.pinnedPath(packagePath)
+	}
+	goSources, err = f.sourceFinder.GetPackageSourceFiles(findPath)
+	if err != nil {
+		return nil, err
+	}
+	if mod, ok := findModule(findPath, f.sourceFinder); ok && f.pins != nil {
+		f.pins.pin(mod)
+	}
+
+	if f.config.hooks.PackageResolved != nil {
+		f.config.hooks.PackageResolved(packagePath, len(goSources), time.Since(start))
+	}
+
+	// like the go tool, the files are visited in the order of their names, whatever order the SourceFinder uses, so the
+	// declarations of a package are always visited in the source order. The slice may be cached by the SourceFinder.
+	goSources = append([]string(nil), goSources...)
+	sort.SliceStable(goSources, func(i, j int) bool {
+		return filepath.Base(goSources[i]) < filepath.Base(goSources[j])
+	})
+	return goSources, nil
+}
+
+func (f *astTypeGenerator) readSourceFile(filename string) ([]byte, error) {
+	if reader, ok := f.sourceFinder.(sourceReader); ok {
+		return reader.ReadSourceFile(filename)
+	}
+	return ioutil.ReadFile(filename)
+}
+
 func (f *astTypeGenerator) parseAstFile(filename string) (*ast.File, error) {
-	file, err := os.Open(filename)
+	if parsed, ok := f.parsed[filename]; ok {
+		return parsed.file, parsed.err
+	}
+
+	fileAst, err := f.readAndParseFile(filename)
+	if f.parsed == nil {
+		f.parsed = make(map[string]parsedFile)
+	}
+	f.parsed[filename] = parsedFile{file: fileAst, err: err}
+	return fileAst, err
+}
+
+func (f *astTypeGenerator) readAndParseFile(filename string) (fileAst *ast.File, err error) {
+	end := f.startPhase(phaseParseFile, "gotype.file", filename)
+	defer func() { end(err) }()
+
+	f.logf("parsing %s", filename)
+	start := time.Now()
+	content, err := f.readSourceFile(filename)
 	if err != nil {
 		return nil, fmt.Errorf("cannot open file: %w", err)
 	}
-	defer file.Close()
 
-	fset := token.NewFileSet()
-	fileAst, err := parser.ParseFile(fset, filepath.Base(filename), file, 0)
+	fileAst, err = parser.ParseFile(f.fset, filename, content, parser.ParseComments)
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse go code: %w", err)
+		// the partial AST is returned as well, so it can still be searched in tolerant mode.
+		return fileAst, fmt.Errorf("cannot parse go code: %w", err)
 	}
 
+	if f.config.hooks.FileParsed != nil {
+		f.config.hooks.FileParsed(filename, len(content), time.Since(start))
+	}
 	return fileAst, nil
 }
 
@@ -164,6 +465,142 @@ func (*astTypeGenerator) getDeclarationByName(f *ast.File, name string) *ast.Typ
 	return nil
 }
 
+// recordDeclaration records the declaration of the type `spec` named `name` inside the package, see `QualType.Chain`.
+func (f *astTypeGenerator) recordDeclaration(name string, spec *ast.TypeSpec, packagePath string) {
+	if f.declarations == nil {
+		f.declarations = make(map[string]DeclarationLink)
+	}
+	link := DeclarationLink{
+		Package:  packagePath,
+		Name:     name,
+		Alias:    spec.Assign.IsValid(),
+		Position: f.position(spec.Pos()),
+	}
+	f.declarations[qualTypeKey(QualType{Package: link.Package, Name: link.Name})] = link
+}
+
+func (f *astTypeGenerator) generateTypeFromTypeSpec(
+	spec *ast.TypeSpec,
+	packagePath string,
+	importMap map[string]string,
+) (Type, error) {
+	importMap = f.withTypeParams(importMap, spec.TypeParams)
+
+	typeParams, err := f.generateTypeParams(spec.TypeParams, packagePath, importMap)
+	if err != nil {
+		return Type{}, err
+	}
+
+	typ, err := f.generateTypeFromExpr(spec.Type, packagePath, importMap)
+	if err != nil {
+		return Type{}, err
+	}
+	typ.TypeParams = typeParams
+	typ.Position = f.position(spec.Name.Pos())
+	typ.IsAlias = spec.Assign.IsValid()
+
+	return typ, nil
+}
+
+// typeSpecDoc returns the documentation comment of the type declaration `spec` of `file`, which is either declared at
+// the package level or inside a function, see `WithLocalTypes`.
+func typeSpecDoc(file *ast.File, spec *ast.TypeSpec) string {
+	doc := ""
+	ast.Inspect(file, func(node ast.Node) bool {
+		decl, ok := node.(*ast.GenDecl)
+		if !ok || decl.Tok != token.TYPE {
+			return true
+		}
+		for _, s := range decl.Specs {
+			if s == spec {
+				doc = specDoc(decl, spec.Doc)
+			}
+		}
+		return false
+	})
+	return doc
+}
+
+// withTypeParams returns a copy of `importMap` which also contains the type parameters declared in `typeParams`, so
+// the identifiers referring to them are recognized as TypeParamType. Type parameters are stored using the
+// `typeParamSuffix` suffix, so they don't clash with the imported package names.
+func (*astTypeGenerator) withTypeParams(importMap map[string]string, typeParams *ast.FieldList) map[string]string {
+	if typeParams == nil || len(typeParams.List) == 0 {
+		return importMap
+	}
+
+	scoped := make(map[string]string, len(importMap)+typeParams.NumFields())
+	for k, v := range importMap {
+		scoped[k] = v
+	}
+	for _, field := range typeParams.List {
+		for _, name := range field.Names {
+			scoped[name.Name+typeParamSuffix] = name.Name
+		}
+	}
+	return scoped
+}
+
+func (f *astTypeGenerator) generateTypeParams(
+	typeParams *ast.FieldList,
+	packagePath string,
+	importMap map[string]string,
+) ([]TypeParam, error) {
+	if typeParams == nil {
+		return nil, nil
+	}
+
+	params := make([]TypeParam, 0, typeParams.NumFields())
+	for _, field := range typeParams.List {
+		var constraint Type
+		switch t := field.Type.(type) {
+		case *ast.BinaryExpr, *ast.UnaryExpr:
+			// an inline union like `[T int | string]` is a shorthand of `[T interface{ int | string }]`.
+			terms, err := f.generateTypeTerms(t, packagePath, importMap)
+			if err != nil {
+				return nil, err
+			}
+			constraint = InterfaceType{Unions: [][]TypeTerm{terms}}.Type()
+		default:
+			var err error
+			if constraint, err = f.generateTypeFromExpr(field.Type, packagePath, importMap); err != nil {
+				return nil, err
+			}
+		}
+
+		constraintInterface, err := f.resolveConstraintInterface(constraint)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range field.Names {
+			params = append(params, TypeParam{
+				Name:                name.Name,
+				Constraint:          constraint,
+				ConstraintInterface: constraintInterface,
+			})
+		}
+	}
+	return params, nil
+}
+
+// resolveConstraintInterface returns the interface behind a type parameter's constraint. A named constraint, possibly
+// declared in another package, is resolved to its full InterfaceType. It returns nil for the predeclared comparable.
+func (f *astTypeGenerator) resolveConstraintInterface(constraint Type) (*InterfaceType, error) {
+	if constraint.InterfaceType != nil {
+		return constraint.InterfaceType, nil
+	}
+	if constraint.QualType == nil || constraint.QualType.Package == "" {
+		return nil, nil
+	}
+
+	typ, err := f.generateEmbeddedType(*constraint.QualType)
+	if err != nil {
+		return nil, err
+	}
+	return typ.InterfaceType, nil
+}
+
 func (f *astTypeGenerator) generateTypeFromExpr(
 	e ast.Expr,
 	targetPkgPath string,
@@ -174,6 +611,12 @@ func (f *astTypeGenerator) generateTypeFromExpr(
 		return f.generateTypeFromSelectorExpr(v, importMap)
 	case *ast.Ident:
 		return f.generateTypeFromIdent(v, targetPkgPath, importMap), nil
+	case *ast.IndexExpr:
+		return f.generateTypeFromInstantiation(v.X, []ast.Expr{v.Index}, targetPkgPath, importMap)
+	case *ast.IndexListExpr:
+		return f.generateTypeFromInstantiation(v.X, v.Indices, targetPkgPath, importMap)
+	case *ast.ParenExpr:
+		return f.generateTypeFromExpr(v.X, targetPkgPath, importMap)
 	case *ast.StarExpr:
 		typ, err := f.generateTypeFromStarExpr(v, targetPkgPath, importMap)
 		if err != nil {
@@ -213,10 +656,26 @@ func (f *astTypeGenerator) generateTypeFromExpr(
 		}
 		return Type{InterfaceType: &typ}, nil
 	}
-	return Type{}, fmt.Errorf("unrecognized type: %v", e)
+	if err := f.degrade(e.Pos(), fmt.Errorf("unrecognized type: %v", e)); err != nil {
+		return Type{}, err
+	}
+	return Type{}, nil
 }
 
 func (f *astTypeGenerator) generateTypeFromIdent(ident *ast.Ident, packagePath string, importMap map[string]string) Type {
+	if _, ok := importMap[ident.Name+typeParamSuffix]; ok {
+		f.explain(ident.Pos(), "identifier %s resolved as a type parameter", ident.Name)
+		return Type{TypeParamType: &TypeParamType{Name: ident.Name}}
+	}
+	if name, ok := importMap[ident.Name+localTypeSuffix]; ok {
+		f.explain(ident.Pos(), "identifier %s resolved as the local type %s of package %s", ident.Name, name, packagePath)
+		return Type{QualType: &QualType{
+			Package:          packagePath,
+			ShortPackagePath: importMap[packagePath+"__short"],
+			Name:             name,
+		}}
+	}
+
 	switch ident.Name {
 	case string(PrimitiveKindBool):
 		return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindBool}}
@@ -225,7 +684,7 @@ func (f *astTypeGenerator) generateTypeFromIdent(ident *ast.Ident, packagePath s
 	case string(PrimitiveKindRune):
 		return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindRune}}
 	case string(PrimitiveKindInt):
-		return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindInt}}
+		return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindInt, Bits: f.config.buildConfig.wordBits()}}
 	case string(PrimitiveKindInt8):
 		return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindInt8}}
 	case string(PrimitiveKindInt16):
@@ -235,7 +694,7 @@ func (f *astTypeGenerator) generateTypeFromIdent(ident *ast.Ident, packagePath s
 	case string(PrimitiveKindInt64):
 		return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindInt64}}
 	case string(PrimitiveKindUint):
-		return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindUint}}
+		return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindUint, Bits: f.config.buildConfig.wordBits()}}
 	case string(PrimitiveKindUint8):
 		return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindUint8}}
 	case string(PrimitiveKindUint16):
@@ -245,7 +704,7 @@ func (f *astTypeGenerator) generateTypeFromIdent(ident *ast.Ident, packagePath s
 	case string(PrimitiveKindUint64):
 		return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindUint64}}
 	case string(PrimitiveKindUintptr):
-		return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindUintptr}}
+		return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindUintptr, Bits: f.config.buildConfig.wordBits()}}
 	case string(PrimitiveKindFloat32):
 		return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindFloat32}}
 	case string(PrimitiveKindFloat64):
@@ -258,8 +717,15 @@ func (f *astTypeGenerator) generateTypeFromIdent(ident *ast.Ident, packagePath s
 		return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindString}}
 	case "error":
 		return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindError}}
+	case "any":
+		return Type{InterfaceType: &InterfaceType{}}
+	case "comparable":
+		// comparable is a predeclared interface, so it doesn't belong to any package.
+		return Type{QualType: &QualType{Name: ident.Name}}
 	}
 
+	f.explain(ident.Pos(), "identifier %s resolved as a type of package %s", ident.Name, packagePath)
+
 	// Так и не понял, почему заходим сюда, но на некоторых импоратх, мы сюда заходим и это все ломает
 	// Покопался в коде, сделал костыль, но надо будет потом все сделать по уму
 	return Type{QualType: &QualType{
@@ -284,6 +750,16 @@ func (f *astTypeGenerator) generateTypeFromSelectorExpr(
 	if !ok {
 		return Type{}, fmt.Errorf("unrecognized identifier: %s", shortImport)
 	}
+	f.explain(
+		selectorExpr.Pos(),
+		"selector %s.%s resolved using import %s",
+		shortImport,
+		selectorExpr.Sel.Name,
+		importPath,
+	)
+	if importPath == "C" {
+		f.warn(selectorExpr.Pos(), "cgo type C.%s cannot be resolved", selectorExpr.Sel.Name)
+	}
 
 	return Type{QualType: &QualType{
 		Package:          importPath,
@@ -292,6 +768,35 @@ func (f *astTypeGenerator) generateTypeFromSelectorExpr(
 	}}, nil
 }
 
+// generateTypeFromInstantiation generates the type of an instantiated generic type like `cache.Store[string, *User]`.
+func (f *astTypeGenerator) generateTypeFromInstantiation(
+	genericExpr ast.Expr,
+	argExprs []ast.Expr,
+	packagePath string,
+	importMap map[string]string,
+) (Type, error) {
+	generic, err := f.generateTypeFromExpr(genericExpr, packagePath, importMap)
+	if err != nil {
+		return Type{}, err
+	}
+	if generic.QualType == nil {
+		return Type{}, fmt.Errorf("unrecognized generic type: %v", genericExpr)
+	}
+
+	args := make([]Type, 0, len(argExprs))
+	for _, argExpr := range argExprs {
+		arg, err := f.generateTypeFromExpr(argExpr, packagePath, importMap)
+		if err != nil {
+			return Type{}, err
+		}
+		args = append(args, arg)
+	}
+
+	qualType := *generic.QualType
+	qualType.TypeArgs = args
+	return Type{QualType: &qualType}, nil
+}
+
 func (f *astTypeGenerator) generateTypeFromStarExpr(
 	starExpr *ast.StarExpr,
 	packagePath string,
@@ -317,13 +822,17 @@ func (f *astTypeGenerator) generateTypeFromArrayType(
 		return Type{SliceType: &SliceType{Elem: elem}}, nil
 	}
 
-	lit, ok := arrayType.Len.(*ast.BasicLit)
-	if !ok {
-		return Type{}, fmt.Errorf("unrecognized array length: %v", arrayType.Len)
-	}
-	lenn, ok := parseInt(lit.Value)
-	if !ok {
-		return Type{}, fmt.Errorf("unrecognized array length: %v", lit.Value)
+	lenn := 0
+	if lit, ok := arrayType.Len.(*ast.BasicLit); ok {
+		if lenn, ok = parseInt(lit.Value); !ok {
+			if err := f.degrade(lit.Pos(), fmt.Errorf("unrecognized array length: %v", lit.Value)); err != nil {
+				return Type{}, err
+			}
+		}
+	} else if lenn, ok = f.evaluateArrayLen(arrayType.Len, packagePath, importMap); !ok {
+		if err := f.degrade(arrayType.Len.Pos(), fmt.Errorf("unrecognized array length: %s", types.ExprString(arrayType.Len))); err != nil {
+			return Type{}, err
+		}
 	}
 
 	elem, err := f.generateTypeFromExpr(arrayType.Elt, packagePath, importMap)
@@ -339,27 +848,18 @@ func (f *astTypeGenerator) generateTypeFromFuncType(
 	packagePath string,
 	importMap map[string]string,
 ) (FuncType, error) {
-	params, isVariadic, err := f.generateTypeFromFieldList(
-		funcType.Params,
-		f.getInputNamesFromAst(funcType.Params.List),
-		packagePath,
-		importMap,
-	)
+	params, isVariadic, err := f.generateTypeFromFieldList(funcType.Params, packagePath, importMap)
 	if err != nil {
 		return FuncType{}, err
 	}
 
 	var results []TypeField = nil
 	if funcType.Results != nil {
-		if results, _, err = f.generateTypeFromFieldList(
-			funcType.Results,
-			f.getOutputNamesFromAst(funcType.Results.List),
-			packagePath,
-			importMap,
-		); err != nil {
+		if results, _, err = f.generateTypeFromFieldList(funcType.Results, packagePath, importMap); err != nil {
 			return FuncType{}, err
 		}
 	}
+	f.nameUnnamedFields(params, results)
 
 	return FuncType{
 		Inputs:     params,
@@ -368,9 +868,10 @@ func (f *astTypeGenerator) generateTypeFromFuncType(
 	}, nil
 }
 
+// generateTypeFromFieldList generates the parameters or the results of a function. The unnamed ones are named by
+// `nameUnnamedFields` once all of them are generated.
 func (f *astTypeGenerator) generateTypeFromFieldList(
 	fields *ast.FieldList,
-	names []string,
 	packagePath string,
 	importMap map[string]string,
 ) (types []TypeField, isVariadic bool, err error) {
@@ -379,7 +880,6 @@ func (f *astTypeGenerator) generateTypeFromFieldList(
 	}
 
 	types = make([]TypeField, 0, fields.NumFields())
-	i := 0
 	for _, field := range fields.List {
 		typExpr := field.Type
 		if v, ok := field.Type.(*ast.Ellipsis); ok {
@@ -394,53 +894,24 @@ func (f *astTypeGenerator) generateTypeFromFieldList(
 
 		if len(field.Names) == 0 {
 			types = append(types, TypeField{
-				Name: names[i],
-				Type: typ,
+				Type:     typ,
+				Position: f.position(field.Pos()),
 			})
-			i++
 			continue
 		}
 
-		for range field.Names {
+		for _, name := range field.Names {
 			types = append(types, TypeField{
-				Name: names[i],
-				Type: typ,
+				Name:     name.String(),
+				Type:     typ,
+				Position: f.position(name.Pos()),
 			})
-			i++
 		}
 	}
 
 	return
 }
 
-func (f *astTypeGenerator) getInputNamesFromAst(inputs []*ast.Field) []string {
-	return f.getNamesFromExpr(inputs, "arg")
-}
-
-func (f *astTypeGenerator) getOutputNamesFromAst(inputs []*ast.Field) []string {
-	return f.getNamesFromExpr(inputs, "out")
-}
-
-func (f *astTypeGenerator) getNamesFromExpr(params []*ast.Field, prefix string) []string {
-	names := make([]string, 0, len(params))
-	i := 0
-	for _, p := range params {
-		n := len(p.Names)
-		if n == 0 {
-			n = 1
-		}
-		for j := 0; j < n; j++ {
-			if len(p.Names) > 0 {
-				names = append(names, p.Names[j].String())
-			} else {
-				i++
-				names = append(names, fmt.Sprintf("%s%d", prefix, i))
-			}
-		}
-	}
-	return names
-}
-
 func (f *astTypeGenerator) generateTypeFromMapType(
 	mapType *ast.MapType,
 	packagePath string,
@@ -490,18 +961,59 @@ func (f *astTypeGenerator) generateTypeFromStructType(
 
 	fields := make([]TypeField, 0, structType.Fields.NumFields())
 	for _, field := range structType.Fields.List {
+		var tag reflect.StructTag
+		if field.Tag != nil {
+			value, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				return StructType{}, fmt.Errorf("invalid struct tag %s: %w", field.Tag.Value, err)
+			}
+			tag = reflect.StructTag(value)
+		}
+
 		for _, name := range field.Names {
+			if f.isOmitted(name.String()) {
+				continue
+			}
 			fieldType, err := f.generateTypeFromExpr(field.Type, packagePath, importMap)
 			if err != nil {
 				return StructType{}, err
 			}
 
 			fields = append(fields, TypeField{
-				Name: name.String(),
-				Type: fieldType,
+				Name:        name.String(),
+				Type:        fieldType,
+				Position:    f.position(name.Pos()),
+				Tag:         tag,
+				Annotations: f.fieldAnnotations(field),
+				Doc:         field.Doc.Text(),
+				Comment:     field.Comment.Text(),
+			})
+		}
+
+		// the name of an embedded field is the name of its type, like `Reader` for `io.Reader` or `Base` for `*Base`.
+		if len(field.Names) == 0 {
+			name := embeddedFieldName(field.Type)
+			if f.isOmitted(name) {
+				continue
+			}
+			fieldType, err := f.generateTypeFromExpr(field.Type, packagePath, importMap)
+			if err != nil {
+				return StructType{}, err
+			}
+
+			fields = append(fields, TypeField{
+				Name:        name,
+				Type:        fieldType,
+				Position:    f.position(field.Pos()),
+				Tag:         tag,
+				Annotations: f.fieldAnnotations(field),
+				Embedded:    true,
+				Doc:         field.Doc.Text(),
+				Comment:     field.Comment.Text(),
 			})
 		}
 	}
+	f.sortFields(fields)
 
 	return StructType{Fields: fields}, nil
 }
@@ -517,31 +1029,251 @@ func (f *astTypeGenerator) generateTypeFromInterfaceType(
 
 	nMethod := interfaceType.Methods.NumFields()
 	methods := make([]InterfaceTypeMethod, 0, nMethod)
+	var embedded []QualType
+	var unions [][]TypeTerm
 	for _, field := range interfaceType.Methods.List {
+		var origin QualType
 		switch t := field.Type.(type) {
 		case *ast.FuncType:
 			name := field.Names[0].String()
-			funcTypeAST := field.Type.(*ast.FuncType)
-			funcType, err := f.generateTypeFromFuncType(funcTypeAST, packagePath, importMap)
+			if f.isOmitted(name) {
+				continue
+			}
+			funcType, err := f.generateTypeFromFuncType(t, packagePath, importMap)
 			if err != nil {
 				return InterfaceType{}, err
 			}
-			methods = append(methods, InterfaceTypeMethod{Name: name, Func: funcType})
-		case *ast.Ident:
-			innerInterface, err := f.GenerateTypesFromSpecs(TypeSpec{PackagePath: packagePath, Name: t.Name})
+			methods, err = f.appendInterfaceMethods(methods, InterfaceTypeMethod{
+				Name:     name,
+				Func:     funcType,
+				Doc:      field.Doc.Text(),
+				Comment:  field.Comment.Text(),
+				Position: f.position(field.Names[0].Pos()),
+			})
 			if err != nil {
 				return InterfaceType{}, err
 			}
-			methods = append(methods, innerInterface[0].InterfaceType.Methods...)
-		case *ast.SelectorExpr:
-			x, sel := t.X.(*ast.Ident).Name, t.Sel.Name
-			innerInterface, err := f.GenerateTypesFromSpecs(TypeSpec{PackagePath: importMap[x], Name: sel})
+			continue
+		case *ast.Ident, *ast.SelectorExpr:
+			typ, err := f.generateTypeFromExpr(t, packagePath, importMap)
 			if err != nil {
 				return InterfaceType{}, err
 			}
-			methods = append(methods, innerInterface[0].InterfaceType.Methods...)
+			switch {
+			case typ.PrimitiveType != nil && typ.PrimitiveType.Kind == PrimitiveKindError:
+				embedded = append(embedded, errorOrigin)
+				if !f.config.keepEmbeddedInterfaces {
+					if methods, err = f.appendInterfaceMethods(methods, f.errorMethods()...); err != nil {
+						return InterfaceType{}, err
+					}
+				}
+				continue
+			case typ.InterfaceType != nil:
+				// any doesn't add anything to the method set nor to the type set.
+				continue
+			case typ.QualType == nil:
+				unions = append(unions, []TypeTerm{{Type: typ}})
+				continue
+			}
+			origin = *typ.QualType
+		case *ast.BinaryExpr, *ast.UnaryExpr, *ast.ParenExpr:
+			terms, err := f.generateTypeTerms(t, packagePath, importMap)
+			if err != nil {
+				return InterfaceType{}, err
+			}
+			unions = append(unions, terms)
+			continue
+		default:
+			f.warn(field.Pos(), "unsupported interface element %s is skipped", types.ExprString(field.Type))
+			continue
+		}
+
+		// predeclared interfaces, like comparable, can't be resolved since they don't belong to any package.
+		if f.config.keepEmbeddedInterfaces || origin.Package == "" {
+			embedded = append(embedded, origin)
+			continue
+		}
+
+		innerInterface, err := f.generateEmbeddedType(origin)
+		if err != nil {
+			return InterfaceType{}, err
+		}
+		if innerInterface.InterfaceType == nil {
+			// embedding a non-interface type inside a constraint means a single-term union.
+			unions = append(unions, []TypeTerm{{Type: origin.Type()}})
+			continue
+		}
+
+		embedded = append(embedded, origin)
+		methods, err = f.appendInterfaceMethods(methods, f.embedInterfaceMethods(innerInterface, origin)...)
+		if err != nil {
+			return InterfaceType{}, err
 		}
+		unions = append(unions, innerInterface.InterfaceType.Unions...)
 	}
 
-	return InterfaceType{Methods: methods}, nil
+	f.sortMethods(methods)
+
+	return InterfaceType{Methods: methods, Embedded: embedded, Unions: unions}, nil
+}
+
+// generateTypeTerms generates the terms of a union element like `int | ~string | Float` inside a constraint
+// interface. Unless the embedded interfaces are kept, a term referring to a constraint interface consisting of a
+// single union, like `constraints.Float`, is replaced by the terms of that union.
+func (f *astTypeGenerator) generateTypeTerms(
+	e ast.Expr,
+	packagePath string,
+	importMap map[string]string,
+) ([]TypeTerm, error) {
+	switch v := e.(type) {
+	case *ast.ParenExpr:
+		return f.generateTypeTerms(v.X, packagePath, importMap)
+	case *ast.BinaryExpr:
+		if v.Op != token.OR {
+			return nil, fmt.Errorf("unrecognized type term: %v", e)
+		}
+		left, err := f.generateTypeTerms(v.X, packagePath, importMap)
+		if err != nil {
+			return nil, err
+		}
+		right, err := f.generateTypeTerms(v.Y, packagePath, importMap)
+		if err != nil {
+			return nil, err
+		}
+		return append(left, right...), nil
+	case *ast.UnaryExpr:
+		if v.Op != token.TILDE {
+			return nil, fmt.Errorf("unrecognized type term: %v", e)
+		}
+		typ, err := f.generateTypeFromExpr(v.X, packagePath, importMap)
+		if err != nil {
+			return nil, err
+		}
+		return []TypeTerm{{Tilde: true, Type: typ}}, nil
+	}
+
+	typ, err := f.generateTypeFromExpr(e, packagePath, importMap)
+	if err != nil {
+		return nil, err
+	}
+
+	if typ.QualType != nil && typ.QualType.Package != "" && !f.config.keepEmbeddedInterfaces {
+		inner, err := f.generateEmbeddedType(*typ.QualType)
+		if err != nil {
+			return nil, err
+		}
+		if i := inner.InterfaceType; i != nil && len(i.Methods) == 0 && len(i.Unions) == 1 {
+			return i.Unions[0], nil
+		}
+	}
+
+	return []TypeTerm{{Type: typ}}, nil
+}
+
+// generateEmbeddedType generates the declaration of `q`, which is embedded inside the interface being generated or
+// used as a constraint. Since it's generated recursively, the embedding chain is tracked to report cyclic embeddings
+// and embeddings deeper than the configured maximum depth, instead of overflowing the stack.
+func (f *astTypeGenerator) generateEmbeddedType(q QualType) (Type, error) {
+	chain := append(append([]QualType{}, f.embedding...), q)
+	for _, embedded := range f.embedding {
+		if embedded.Package == q.Package && embedded.Name == q.Name {
+			return Type{}, fmt.Errorf("invalid recursive embedding: %s", embeddingChainString(chain))
+		}
+	}
+	if f.config.maxEmbeddingDepth > 0 && len(f.embedding) >= f.config.maxEmbeddingDepth {
+		return Type{}, fmt.Errorf(
+			"embedding exceeds the maximum depth of %d: %s",
+			f.config.maxEmbeddingDepth,
+			embeddingChainString(chain),
+		)
+	}
+
+	f.embedding = chain
+	defer func() { f.embedding = chain[:len(chain)-1] }()
+
+	types, err := f.generateTypesFromSpecs([]TypeSpec{{PackagePath: q.Package, Name: q.Name}})
+	if err != nil {
+		return Type{}, err
+	}
+	return types[0], nil
+}
+
+func embeddingChainString(chain []QualType) string {
+	names := make([]string, 0, len(chain))
+	for _, q := range chain {
+		names = append(names, q.Package+"."+q.Name)
+	}
+	return strings.Join(names, " -> ")
+}
+
+// appendInterfaceMethods appends `newMethods` into `methods`. A method that has the same name and an identical
+// signature with an already appended method is skipped, which happens when the same interface is embedded through
+// several paths. A method with the same name but different signature is a conflict and reported as an error.
+func (*astTypeGenerator) appendInterfaceMethods(
+	methods []InterfaceTypeMethod,
+	newMethods ...InterfaceTypeMethod,
+) ([]InterfaceTypeMethod, error) {
+	for _, newMethod := range newMethods {
+		duplicated := false
+		for _, method := range methods {
+			if method.Name != newMethod.Name {
+				continue
+			}
+
+			if !identicalFunc(method.Func, newMethod.Func) {
+				return nil, fmt.Errorf(
+					"duplicate method %s with different signatures: %s (%s) and %s (%s)",
+					method.Name,
+					method.Func.String(""),
+					describeMethodOrigin(method),
+					newMethod.Func.String(""),
+					describeMethodOrigin(newMethod),
+				)
+			}
+			duplicated = true
+			break
+		}
+
+		if !duplicated {
+			methods = append(methods, newMethod)
+		}
+	}
+	return methods, nil
+}
+
+func describeMethodOrigin(method InterfaceTypeMethod) string {
+	if method.Origin == nil {
+		return "declared directly"
+	}
+	return "from " + strings.TrimPrefix(method.Origin.Package+"."+method.Origin.Name, ".")
+}
+
+// errorOrigin is the origin of the method of the predeclared error interface, which doesn't belong to any package.
+var errorOrigin = QualType{Name: "error"}
+
+// errorMethods returns the methods of the predeclared error interface, to be flattened into the interfaces embedding
+// it.
+func (f *astTypeGenerator) errorMethods() []InterfaceTypeMethod {
+	outputs := []TypeField{{Type: PrimitiveType{Kind: PrimitiveKindString}.Type()}}
+	f.nameUnnamedFields(nil, outputs)
+	origin := errorOrigin
+	return []InterfaceTypeMethod{{Name: "Error", Func: FuncType{Outputs: outputs}, Origin: &origin}}
+}
+
+// embedInterfaceMethods returns the methods of an embedded interface. Methods that don't have an origin yet are
+// attributed to the embedded interface itself, so the origin always points to the interface declaring the method.
+func (*astTypeGenerator) embedInterfaceMethods(embedded Type, origin QualType) []InterfaceTypeMethod {
+	if embedded.InterfaceType == nil {
+		return nil
+	}
+
+	methods := make([]InterfaceTypeMethod, 0, len(embedded.InterfaceType.Methods))
+	for _, method := range embedded.InterfaceType.Methods {
+		if method.Origin == nil {
+			o := origin
+			method.Origin = &o
+		}
+		methods = append(methods, method)
+	}
+	return methods
 }
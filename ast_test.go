@@ -0,0 +1,1192 @@
+package gotype
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/module"
+	modzip "golang.org/x/mod/zip"
+)
+
+const testdataPackage = "github.com/armantarkhanian/gotype/testdata"
+
+func TestInterfaceMethodOrigin(t *testing.T) {
+	types, err := GenerateTypesFromSpecs(TypeSpec{PackagePath: testdataPackage + "/ifaces", Name: "NamedReadCloser"})
+	require.NoError(t, err)
+	require.Len(t, types, 1)
+	require.NotNil(t, types[0].InterfaceType)
+
+	origins := make(map[string]string)
+	for _, method := range types[0].InterfaceType.Methods {
+		if method.Origin == nil {
+			origins[method.Name] = ""
+			continue
+		}
+		origins[method.Name] = method.Origin.Package + "." + method.Origin.Name
+	}
+
+	assert.Equal(t, map[string]string{
+		"Read":  testdataPackage + "/ifaces.Reader",
+		"Close": "io.Closer",
+		"Name":  "",
+	}, origins)
+}
+
+func TestInterfaceMethodDoc(t *testing.T) {
+	types, err := GenerateTypesFromSpecs(TypeSpec{PackagePath: testdataPackage + "/ifaces", Name: "NamedReadCloser"})
+	require.NoError(t, err)
+	require.NotNil(t, types[0].InterfaceType)
+
+	methods := types[0].InterfaceType.Methods
+	require.Len(t, methods, 3)
+	assert.Equal(t, "Name", methods[2].Name)
+	assert.Equal(t, "Name returns the name of the reader.\nIt never returns an empty string.\n", methods[2].Doc)
+	assert.Equal(t, "e.g. \"stdin\"\n", methods[2].Comment)
+}
+
+func TestInterfaceEmbedded(t *testing.T) {
+	spec := TypeSpec{PackagePath: testdataPackage + "/ifaces", Name: "ReadCloser"}
+	expectedEmbedded := []QualType{
+		{Package: testdataPackage + "/ifaces", ShortPackagePath: "ifaces", Name: "Reader"},
+		{Package: "io", ShortPackagePath: "io", Name: "Closer"},
+	}
+
+	types, err := GenerateTypesFromSpecs(spec)
+	require.NoError(t, err)
+	assert.Equal(t, expectedEmbedded, types[0].InterfaceType.Embedded)
+	assert.Len(t, types[0].InterfaceType.Methods, 2)
+
+	types, err = NewGenerator(WithEmbeddedInterfaces()).GenerateTypesFromSpecs(spec)
+	require.NoError(t, err)
+	assert.Equal(t, expectedEmbedded, types[0].InterfaceType.Embedded)
+	assert.Empty(t, types[0].InterfaceType.Methods)
+}
+
+func TestInterfaceEmbeddedIdentity(t *testing.T) {
+	reader, err := ParseTypeString("interface{io.Reader}")
+	require.NoError(t, err)
+	writer, err := ParseTypeString("interface{io.Writer}")
+	require.NoError(t, err)
+	assert.False(t, Identical(reader, writer))
+	assert.True(t, Identical(reader, reader))
+
+	// the embedded interfaces are compared regardless of their order, like the methods.
+	types, err := NewGenerator(WithEmbeddedInterfaces()).GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: testdataPackage + "/ifaces", Name: "ReadCloser"},
+	)
+	require.NoError(t, err)
+	reordered := *types[0].InterfaceType
+	reordered.Embedded = []QualType{reordered.Embedded[1], reordered.Embedded[0]}
+	assert.True(t, Identical(types[0], Type{InterfaceType: &reordered}))
+	reordered.Embedded = reordered.Embedded[:1]
+	assert.False(t, Identical(types[0], Type{InterfaceType: &reordered}))
+}
+
+func TestInterfaceDuplicateMethods(t *testing.T) {
+	types, err := GenerateTypesFromSpecs(TypeSpec{PackagePath: testdataPackage + "/ifaces", Name: "Diamond"})
+	require.NoError(t, err)
+
+	names := make([]string, 0)
+	for _, method := range types[0].InterfaceType.Methods {
+		names = append(names, method.Name)
+	}
+	assert.Equal(t, []string{"Read", "Close", "Name"}, names)
+
+	_, err = GenerateTypesFromSpecs(TypeSpec{PackagePath: testdataPackage + "/ifaces", Name: "Conflict"})
+	assert.EqualError(t, err, "duplicate method Name with different signatures: "+
+		"func() (out1 string) (from "+testdataPackage+"/ifaces.NamedReadCloser) and func() (out1 int) (from "+testdataPackage+"/ifaces.Namer)")
+}
+
+func TestInterfaceEmbeddingPredeclared(t *testing.T) {
+	spec := TypeSpec{PackagePath: testdataPackage + "/ifaces", Name: "CodedError"}
+	for name, generator := range map[string]TypeGenerator{
+		"ast":       NewGenerator(),
+		"typecheck": NewGenerator(WithTypeChecking()),
+	} {
+		types, err := generator.GenerateTypesFromSpecs(spec)
+		require.NoError(t, err, name)
+
+		iface := types[0].InterfaceType
+		require.NotNil(t, iface, name)
+		assert.Empty(t, iface.Unions, name)
+		assert.Equal(t, []QualType{{Name: "error"}}, iface.Embedded, name)
+		methods := make(map[string]InterfaceTypeMethod)
+		for _, method := range iface.Methods {
+			methods[method.Name] = method
+		}
+		require.Len(t, methods, 2, name)
+		assert.Contains(t, methods, "Code", name)
+		assert.Equal(t, "func() (out1 string)", methods["Error"].Func.String(""), name)
+		assert.Equal(t, &QualType{Name: "error"}, methods["Error"].Origin, name)
+	}
+}
+
+func TestMinimalInterface(t *testing.T) {
+	iface, err := MinimalInterface(
+		TypeSpec{PackagePath: testdataPackage + "/minimize/store", Name: "Store"},
+		testdataPackage+"/minimize/consumer",
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "interface {\n"+
+		"    Get(key string) (out1 string, out2 error)\n"+
+		"    Put(key string, value string) (out1 error)\n"+
+		"    Close() (out1 error)\n"+
+		"}", iface.String(""))
+	assert.Equal(t, "Get returns the value of the key.\n", iface.Methods[0].Doc)
+}
+
+func TestOrdering(t *testing.T) {
+	spec := TypeSpec{PackagePath: testdataPackage + "/ifaces", Name: "Diamond"}
+	methodNames := func(typ Type) []string {
+		names := make([]string, 0)
+		for _, method := range typ.InterfaceType.Methods {
+			names = append(names, method.Name)
+		}
+		return names
+	}
+
+	types, err := NewGenerator().GenerateTypesFromSpecs(spec)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Read", "Close", "Name"}, methodNames(types[0]))
+	assert.Equal(t, 6, types[0].InterfaceType.Methods[0].Position.Line)
+
+	types, err = NewGenerator(WithOrdering(OrderingAlphabetical)).GenerateTypesFromSpecs(spec)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Close", "Name", "Read"}, methodNames(types[0]))
+}
+
+func TestWellKnownInterfacesOf(t *testing.T) {
+	report, err := WellKnownInterfacesOf(TypeSpec{PackagePath: testdataPackage + "/conformance", Name: "Celsius"})
+	require.NoError(t, err)
+	assert.Equal(t, []QualType{
+		{Package: "fmt", ShortPackagePath: "fmt", Name: "Stringer"},
+	}, report.Value)
+	assert.Equal(t, []QualType{
+		{Package: "fmt", ShortPackagePath: "fmt", Name: "Stringer"},
+		{Package: "io", ShortPackagePath: "io", Name: "Reader"},
+		{Package: "encoding/json", ShortPackagePath: "json", Name: "Marshaler"},
+	}, report.Pointer)
+
+	report, err = WellKnownInterfacesOf(TypeSpec{PackagePath: testdataPackage + "/conformance", Name: "Temperatures"})
+	require.NoError(t, err)
+	assert.Equal(t, []QualType{{Package: "sort", ShortPackagePath: "sort", Name: "Interface"}}, report.Value)
+}
+
+func TestAnalyzeInterfaceBloat(t *testing.T) {
+	packagePath := testdataPackage + "/bloat"
+	results, err := AnalyzeInterfaceBloat(packagePath, 2, packagePath)
+	require.NoError(t, err)
+	assert.Equal(t, []InterfaceBloat{{
+		Interface:              QualType{Package: packagePath, Name: "Repository"},
+		MethodCount:            3,
+		TooManyMethods:         true,
+		MaxMethodsUsedTogether: 2,
+		NeverUsedTogether:      true,
+	}}, results)
+}
+
+func TestFindEquivalentInterfaces(t *testing.T) {
+	groups, err := FindEquivalentInterfaces(testdataPackage + "/equivalent/...")
+	require.NoError(t, err)
+	assert.Equal(t, [][]QualType{{
+		{Package: testdataPackage + "/equivalent/a", Name: "Fetcher"},
+		{Package: testdataPackage + "/equivalent/b", Name: "Downloader"},
+	}}, groups)
+}
+
+func TestDeterministicErrors(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		_, err := GenerateTypesFromSpecs(
+			TypeSpec{PackagePath: testdataPackage + "/ifaces", Name: "Reader"},
+			TypeSpec{PackagePath: testdataPackage + "/ifaces", Name: "Missing1"},
+			TypeSpec{PackagePath: testdataPackage + "/ifaces", Name: "Missing2"},
+		)
+		assert.EqualError(
+			t,
+			err,
+			"cannot find definition of Missing1 in package "+testdataPackage+"/ifaces, searched files: ifaces.go, "+
+				"declared types: Reader, ReadCloser, NamedReadCloser, Closer, Diamond, Namer, Conflict, CodedError",
+		)
+	}
+}
+
+func TestTypeNotFoundError(t *testing.T) {
+	_, err := GenerateTypesFromSpecs(TypeSpec{PackagePath: testdataPackage + "/ifaces", Name: "Missing"})
+	assert.True(t, errors.Is(err, ErrTypeNotFound))
+
+	var notFoundErr *TypeNotFoundError
+	require.True(t, errors.As(err, &notFoundErr))
+	assert.Equal(t, testdataPackage+"/ifaces", notFoundErr.PackagePath)
+	assert.Equal(t, "Missing", notFoundErr.Name)
+	require.Len(t, notFoundErr.SearchedFiles, 1)
+	assert.Equal(t, "ifaces.go", filepath.Base(notFoundErr.SearchedFiles[0]))
+	assert.Contains(t, notFoundErr.FoundTypes, "Reader")
+}
+
+func TestWarnings(t *testing.T) {
+	spec := TypeSpec{PackagePath: testdataPackage + "/warnings", Name: "Degraded"}
+
+	_, err := GenerateTypesFromSpecs(spec)
+	assert.EqualError(t, err, "unrecognized array length: cap(sizes)")
+
+	warnings := make([]string, 0)
+	types, err := NewGenerator(WithWarningHandler(func(w Warning) {
+		warnings = append(warnings, fmt.Sprintf("%d:%d: %s", w.Position.Line, w.Position.Column, w.Message))
+	})).GenerateTypesFromSpecs(spec)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"7:10: unrecognized array length: cap(sizes)",
+	}, warnings)
+	assert.Equal(t, "struct {\n    io.Reader\n    Buffer [0]byte\n    Name string\n}", types[0].String(""))
+}
+
+type testLogger []string
+
+func (l *testLogger) Printf(format string, args ...interface{}) {
+	*l = append(*l, fmt.Sprintf(format, args...))
+}
+
+func TestLogger(t *testing.T) {
+	logger := &testLogger{}
+	generator := NewGenerator(WithLogger(logger))
+
+	spec := TypeSpec{PackagePath: testdataPackage + "/ifaces", Name: "Reader"}
+	_, err := generator.GenerateTypesFromSpecs(spec)
+	require.NoError(t, err)
+	_, err = generator.GenerateTypesFromSpecs(spec)
+	require.NoError(t, err)
+
+	assert.Contains(t, *logger, "source files cache miss: package "+testdataPackage+"/ifaces")
+	assert.Contains(t, *logger, "source files cache hit: package "+testdataPackage+"/ifaces")
+}
+
+func TestHooks(t *testing.T) {
+	packages := make([]string, 0)
+	bytesRead := 0
+	generator := NewGenerator(WithHooks(Hooks{
+		PackageResolved: func(packagePath string, files int, duration time.Duration) {
+			packages = append(packages, packagePath)
+		},
+		FileParsed: func(filename string, bytes int, duration time.Duration) {
+			bytesRead += bytes
+		},
+	}))
+
+	_, err := generator.GenerateTypesFromSpecs(TypeSpec{PackagePath: testdataPackage + "/ifaces", Name: "Reader"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{testdataPackage + "/ifaces"}, packages)
+	assert.Greater(t, bytesRead, 0)
+}
+
+func TestPartialResults(t *testing.T) {
+	reader := TypeSpec{PackagePath: testdataPackage + "/ifaces", Name: "Reader"}
+	missing := TypeSpec{PackagePath: testdataPackage + "/ifaces", Name: "Missing"}
+	closer := TypeSpec{PackagePath: testdataPackage + "/ifaces", Name: "Closer"}
+
+	types, err := NewGenerator(WithPartialResults()).GenerateTypesFromSpecs(reader, missing, closer)
+	require.Error(t, err)
+
+	var partialErr *PartialError
+	require.True(t, errors.As(err, &partialErr))
+	require.Len(t, partialErr.Errors, 1)
+	assert.True(t, errors.Is(partialErr.Errors[missing], ErrTypeNotFound))
+
+	require.Len(t, types, 3)
+	assert.NotNil(t, types[0].InterfaceType)
+	assert.Equal(t, Type{}, types[1])
+	assert.NotNil(t, types[2].InterfaceType)
+}
+
+func TestTolerantParsing(t *testing.T) {
+	valid := TypeSpec{PackagePath: testdataPackage + "/broken", Name: "Valid"}
+
+	_, err := GenerateTypesFromSpecs(valid)
+	require.Error(t, err)
+
+	warnings := make([]string, 0)
+	generator := NewGenerator(WithTolerantParsing(), WithWarningHandler(func(w Warning) {
+		warnings = append(warnings, fmt.Sprintf("%s:%d: %s", filepath.Base(w.Position.Filename), w.Position.Line, w.Message))
+	}))
+	types, err := generator.GenerateTypesFromSpecs(valid)
+	require.NoError(t, err)
+	assert.Equal(t, "struct {\n    Name string\n}", types[0].String(""))
+	assert.Len(t, warnings, 1)
+	assert.True(t, strings.HasPrefix(warnings[0], "a.go:4: "), warnings[0])
+
+	_, err = generator.GenerateTypesFromSpecs(TypeSpec{PackagePath: testdataPackage + "/broken", Name: "Missing"})
+	var parseErrs ParseErrors
+	require.True(t, errors.As(err, &parseErrs))
+	require.Len(t, parseErrs, 1)
+	assert.Equal(t, "a.go", filepath.Base(parseErrs[0].Filename))
+}
+
+func TestTrace(t *testing.T) {
+	trace := NewTrace()
+	spec := TypeSpec{PackagePath: testdataPackage + "/ifaces", Name: "ReadCloser"}
+	_, err := NewGenerator(WithTrace(trace)).GenerateTypesFromSpecs(spec)
+	require.NoError(t, err)
+
+	steps := trace.Steps(spec)
+	require.Len(t, steps, 3)
+	assert.True(t, strings.HasPrefix(steps[0].Message, "found declaration of "+testdataPackage+"/ifaces.ReadCloser in "))
+	assert.Equal(t, "identifier Reader resolved as a type of package "+testdataPackage+"/ifaces", steps[1].Message)
+	assert.Equal(t, 10, steps[1].Position.Line)
+	assert.Equal(t, "selector io.Closer resolved using import io", steps[2].Message)
+	assert.Equal(t, 11, steps[2].Position.Line)
+
+	assert.NotEmpty(t, trace.Steps(TypeSpec{PackagePath: testdataPackage + "/ifaces", Name: "Reader"}))
+	assert.Contains(t, trace.Explain(spec), "ifaces.go:11:2: selector io.Closer resolved using import io")
+}
+
+func TestTrace_ZeroValue(t *testing.T) {
+	trace := &Trace{}
+	spec := TypeSpec{PackagePath: testdataPackage + "/ifaces", Name: "ReadCloser"}
+	_, err := NewGenerator(WithTrace(trace)).GenerateTypesFromSpecs(spec)
+	require.NoError(t, err)
+	assert.Len(t, trace.Steps(spec), 3)
+}
+
+func TestEmbeddingGuard(t *testing.T) {
+	pkg := testdataPackage + "/embedding"
+
+	_, err := GenerateTypesFromSpecs(TypeSpec{PackagePath: pkg, Name: "Ping"})
+	assert.EqualError(t, err, "invalid recursive embedding: "+pkg+".Pong -> "+pkg+".Ping -> "+pkg+".Pong")
+
+	outer := TypeSpec{PackagePath: pkg, Name: "Outer"}
+	_, err = GenerateTypesFromSpecs(outer)
+	require.NoError(t, err)
+
+	_, err = NewGenerator(WithMaxEmbeddingDepth(1)).GenerateTypesFromSpecs(outer)
+	assert.EqualError(t, err, "embedding exceeds the maximum depth of 1: "+pkg+".Middle -> "+pkg+".Inner")
+
+	_, err = NewGenerator(WithMaxEmbeddingDepth(0)).GenerateTypesFromSpecs(outer)
+	require.NoError(t, err)
+
+	_, err = NewGenerator(WithMaxEmbeddingDepth(-1)).GenerateTypesFromSpecs(TypeSpec{PackagePath: pkg, Name: "Ping"})
+	assert.EqualError(t, err, "invalid recursive embedding: "+pkg+".Pong -> "+pkg+".Ping -> "+pkg+".Pong")
+}
+
+func TestEmbeddedStructFields(t *testing.T) {
+	pkg := testdataPackage + "/embedding"
+	types, err := GenerateTypesFromSpecs(TypeSpec{PackagePath: pkg, Name: "Document"})
+	require.NoError(t, err)
+
+	fields := types[0].StructType.Fields
+	require.Len(t, fields, 3)
+	assert.Equal(t, "Reader", fields[0].Name)
+	assert.True(t, fields[0].Embedded)
+	assert.Equal(t, QualType{Package: "io", ShortPackagePath: "io", Name: "Reader"}.Type(), fields[0].Type)
+	assert.Equal(t, "Base", fields[1].Name)
+	assert.True(t, fields[1].Embedded)
+	assert.Equal(t, `json:"base"`, string(fields[1].Tag))
+	assert.Equal(t, "Title", fields[2].Name)
+	assert.False(t, fields[2].Embedded)
+	assert.Equal(t, "struct {\n"+
+		"    io.Reader\n"+
+		"    *embedding.Base `json:\"base\"`\n"+
+		"    Title string\n"+
+		"}", types[0].String(""))
+
+	typeString := TypeString(types[0])
+	assert.Equal(t, `struct{io.Reader; *`+pkg+`.Base "json:\"base\""; Title string}`, typeString)
+	parsed, err := ParseTypeString(typeString)
+	require.NoError(t, err)
+	assert.True(t, Identical(types[0], parsed))
+	assert.Equal(t, "Base", parsed.StructType.Fields[1].Name)
+}
+
+func TestVersionPinnedPackage(t *testing.T) {
+	modCache, err := filepath.Abs(filepath.Join("testdata", "modcache"))
+	require.NoError(t, err)
+	t.Setenv("GOMODCACHE", modCache)
+
+	types, err := NewGenerator(WithDeepResolution()).GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: "example.com/pinned@v1.2.3", Name: "Config"},
+	)
+	require.NoError(t, err)
+	require.NotNil(t, types[0].StructType)
+	require.Len(t, types[0].StructType.Fields, 3)
+
+	// the other packages of the module are found in the pinned version as well.
+	option := types[0].StructType.Fields[2].Type.QualType
+	require.NotNil(t, option)
+	assert.Equal(t, "example.com/pinned/sub", option.Package)
+	require.NotNil(t, option.Underlying)
+	assert.Equal(t, "struct {\n    Enabled bool\n}", option.Underlying.String(""))
+
+	path, version := splitPackageVersion("example.com/pinned/sub@v1.2.3")
+	assert.Equal(t, "example.com/pinned/sub", path)
+	assert.Equal(t, "v1.2.3", version)
+}
+
+func TestVersionPinnedPackageScope(t *testing.T) {
+	modCache, err := filepath.Abs(filepath.Join("testdata", "modcache"))
+	require.NoError(t, err)
+	t.Setenv("GOMODCACHE", modCache)
+
+	generator := NewGenerator(WithDeepResolution())
+	_, err = generator.GenerateTypesFromSpecs(TypeSpec{PackagePath: "example.com/pinned@v1.2.3", Name: "Config"})
+	require.NoError(t, err)
+
+	// the version is pinned by the previous call only, so the module isn't required by the main module anymore.
+	_, err = generator.GenerateTypesFromSpecs(TypeSpec{PackagePath: "example.com/pinned/sub", Name: "Option"})
+	assert.True(t, errors.Is(err, ErrPackageNotFound))
+	_, err = generator.GenerateTypesFromSpecs(TypeSpec{PackagePath: "example.com/pinned", Name: "Config"})
+	assert.True(t, errors.Is(err, ErrPackageNotFound))
+}
+
+func TestModuleFetching(t *testing.T) {
+	moduleDir, err := filepath.Abs(filepath.Join("testdata", "modcache", "example.com", "pinned@v1.2.3"))
+	require.NoError(t, err)
+
+	// the module is served by a file based proxy, so it's fetched without network access.
+	proxyDir := t.TempDir()
+	versionDir := filepath.Join(proxyDir, "example.com", "pinned", "@v")
+	require.NoError(t, os.MkdirAll(versionDir, 0o755))
+	goMod, err := os.ReadFile(filepath.Join(moduleDir, "go.mod"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(versionDir, "list"), []byte("v1.2.3\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(versionDir, "v1.2.3.info"), []byte(`{"Version":"v1.2.3"}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(versionDir, "v1.2.3.mod"), goMod, 0o644))
+	createModuleArchive(t, moduleDir, filepath.Join(versionDir, "v1.2.3.zip"))
+
+	t.Setenv("GOPROXY", "file://"+filepath.ToSlash(proxyDir))
+	t.Setenv("GOMODCACHE", t.TempDir())
+	t.Setenv("GOSUMDB", "off")
+	t.Setenv("GOFLAGS", "-modcacherw")
+
+	workDir := t.TempDir()
+	require.NoError(t, os.WriteFile(
+		filepath.Join(workDir, "go.mod"),
+		[]byte("module example.com/app\n\ngo 1.18\n\nrequire example.com/pinned v1.2.3\n"),
+		0o644,
+	))
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(workDir))
+	defer func() { require.NoError(t, os.Chdir(wd)) }()
+
+	spec := TypeSpec{PackagePath: "example.com/pinned", Name: "Config"}
+	_, err = NewGenerator().GenerateTypesFromSpecs(spec)
+	assert.EqualError(t, err, "cannot find module example.com/pinned@v1.2.3: package not found")
+
+	types, err := NewGenerator(WithModuleFetching()).GenerateTypesFromSpecs(spec)
+	require.NoError(t, err)
+	require.NotNil(t, types[0].StructType)
+	assert.Len(t, types[0].StructType.Fields, 3)
+}
+
+func TestModuleArchives(t *testing.T) {
+	moduleDir, err := filepath.Abs(filepath.Join("testdata", "modcache", "example.com", "pinned@v1.2.3"))
+	require.NoError(t, err)
+	archivePath := filepath.Join(t.TempDir(), "v1.2.3.zip")
+	createModuleArchive(t, moduleDir, archivePath)
+
+	generator := NewGenerator(WithModuleArchives(archivePath), WithDeepResolution())
+	types, err := generator.GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: "example.com/pinned", Name: "Config"},
+		TypeSpec{PackagePath: testdataPackage + "/ifaces", Name: "Reader"},
+	)
+	require.NoError(t, err)
+	require.NotNil(t, types[0].StructType)
+	option := types[0].StructType.Fields[2].Type.QualType
+	require.NotNil(t, option.Underlying)
+	assert.Equal(t, "struct {\n    Enabled bool\n}", option.Underlying.String(""))
+	assert.NotNil(t, types[1].InterfaceType)
+
+	packages, err := generator.(*astTypeGenerator).expandPackagePatterns("example.com/pinned/...")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com/pinned", "example.com/pinned/sub"}, packages)
+}
+
+// createModuleArchive creates the zip archive of the module at version v1.2.3 stored inside `moduleDir`.
+func createModuleArchive(t *testing.T, moduleDir, archivePath string) {
+	zipFile, err := os.Create(archivePath)
+	require.NoError(t, err)
+	pinned := module.Version{Path: "example.com/pinned", Version: "v1.2.3"}
+	require.NoError(t, modzip.CreateFromDir(zipFile, pinned, moduleDir))
+	require.NoError(t, zipFile.Close())
+}
+
+func TestTargetPackageVisibility(t *testing.T) {
+	client := TypeSpec{PackagePath: testdataPackage + "/visibility/api", Name: "Client"}
+	secret := testdataPackage + "/visibility/internal/secret"
+
+	_, err := NewGenerator(WithTargetPackage(testdataPackage + "/visibility/cmd")).GenerateTypesFromSpecs(client)
+	require.NoError(t, err)
+
+	_, err = NewGenerator(WithTargetPackage("example.com/app")).GenerateTypesFromSpecs(client)
+	assert.True(t, errors.Is(err, ErrInternalPackage))
+	assert.EqualError(t, err, "use of internal package: "+testdataPackage+"/visibility/api.Client depends on "+
+		secret+", which cannot be imported by example.com/app")
+
+	warnings := make([]Warning, 0)
+	generator := NewGenerator(
+		WithTargetPackage("example.com/app"),
+		WithWarningHandler(func(w Warning) { warnings = append(warnings, w) }),
+	)
+	_, err = generator.GenerateTypesFromSpecs(client, TypeSpec{PackagePath: secret, Name: "Token"})
+	require.NoError(t, err)
+	assert.Len(t, warnings, 2)
+
+	assert.True(t, isImportable("internal/poll", "net"))
+	assert.False(t, isImportable("internal/poll", "example.com/app"))
+	assert.True(t, isImportable("example.com/a/internal/b/internal/c", "example.com/a/internal/b/d"))
+	assert.False(t, isImportable("example.com/a/internal/b/internal/c", "example.com/a/e"))
+}
+
+func TestPackagePathRewrite(t *testing.T) {
+	generator := NewGenerator(
+		WithDeepResolution(),
+		WithPackagePathRewrite(RewriteModulePath(testdataPackage, "example.com/renamed")),
+	)
+	types, err := generator.GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: testdataPackage + "/visibility/api", Name: "Client"},
+		TypeSpec{PackagePath: testdataPackage + "/ifaces", Name: "ReadCloser"},
+	)
+	require.NoError(t, err)
+
+	token := types[0].StructType.Fields[1].Type.MapType.Elem.QualType
+	assert.Equal(t, "example.com/renamed/visibility/internal/secret", token.Package)
+	require.NotNil(t, token.Underlying)
+
+	origins := make(map[string]string)
+	for _, method := range types[1].InterfaceType.Methods {
+		require.NotNil(t, method.Origin)
+		origins[method.Name] = method.Origin.Package
+	}
+	assert.Equal(t, map[string]string{"Read": "example.com/renamed/ifaces", "Close": "io"}, origins)
+
+	rewrite := RewriteModulePath("example.com/old", "example.com/new")
+	assert.Equal(t, "example.com/new", rewrite("example.com/old"))
+	assert.Equal(t, "example.com/new/pkg", rewrite("example.com/old/pkg"))
+	assert.Equal(t, "example.com/older/pkg", rewrite("example.com/older/pkg"))
+}
+
+func TestConfig(t *testing.T) {
+	pkg := testdataPackage + "/buildtags"
+	generate := func(name string, opts ...Option) error {
+		_, err := NewGenerator(opts...).GenerateTypesFromSpecs(TypeSpec{PackagePath: pkg, Name: name})
+		return err
+	}
+
+	// without a Config, all the files are read.
+	assert.NoError(t, generate("Special"))
+	assert.NoError(t, generate("TestOnly"))
+
+	assert.NoError(t, generate("Common", WithConfig(Config{})))
+	assert.True(t, errors.Is(generate("Special", WithConfig(Config{})), ErrTypeNotFound))
+	assert.NoError(t, generate("Special", WithConfig(Config{Tags: []string{"special"}})))
+	assert.NoError(t, generate("Special", WithConfig(Config{BuildFlags: []string{"-tags", "other,special"}})))
+	assert.True(t, errors.Is(generate("TestOnly", WithConfig(Config{})), ErrTypeNotFound))
+	assert.NoError(t, generate("TestOnly", WithConfig(Config{Tests: true})))
+	assert.True(t, errors.Is(generate("WindowsOnly", WithConfig(Config{Env: []string{"GOOS=linux"}})), ErrTypeNotFound))
+	assert.NoError(t, generate("WindowsOnly", WithConfig(Config{Env: []string{"GOOS=windows"}})))
+
+	// the go.mod file is looked up inside Dir.
+	_, err := NewGenerator(WithConfig(Config{Dir: filepath.Join("testdata", "modcache", "example.com", "pinned@v1.2.3")})).
+		GenerateTypesFromSpecs(TypeSpec{PackagePath: "example.com/pinned/sub", Name: "Option"})
+	assert.NoError(t, err)
+}
+
+func TestMainModules(t *testing.T) {
+	// the workspace is found from a directory nested inside one of its modules.
+	dir := filepath.Join("testdata", "workspace", "app", "cmd")
+	types, err := NewGenerator(WithConfig(Config{Dir: dir}), WithDeepResolution()).GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: "example.com/app", Name: "App"},
+	)
+	require.NoError(t, err)
+	lib := types[0].StructType.Fields[0].Type.QualType
+	assert.Equal(t, "example.com/lib", lib.Package)
+	require.NotNil(t, lib.Underlying)
+
+	_, err = NewGenerator(WithConfig(Config{Dir: dir, Env: []string{"GOWORK=off"}})).GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: "example.com/lib", Name: "Lib"},
+	)
+	assert.Error(t, err)
+}
+
+func TestGitRevision(t *testing.T) {
+	repoDir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repoDir
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	writeFile := func(name, content string) {
+		filename := filepath.Join(repoDir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0o755))
+		require.NoError(t, os.WriteFile(filename, []byte(content), 0o644))
+	}
+
+	git("init", "-q")
+	writeFile("go.mod", "module example.com/repo\n\ngo 1.18\n")
+	writeFile("user/user.go", "package user\n\ntype User struct {\n\tName string\n}\n")
+	writeFile("nested/go.mod", "module example.com/nested\n\ngo 1.18\n")
+	writeFile("nested/nested.go", "package nested\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "v1")
+	git("tag", "v1.0.0")
+	writeFile("user/user.go", "package user\n\ntype User struct {\n\tName string\n\tAge  int\n}\n")
+	writeFile("order/order.go", "package order\n\ntype Order struct{}\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "v2")
+
+	spec := TypeSpec{PackagePath: "example.com/repo/user", Name: "User"}
+	generator := NewGenerator(WithConfig(Config{Dir: filepath.Join(repoDir, "user")}), WithGitRevision("v1.0.0"))
+	types, err := generator.GenerateTypesFromSpecs(spec)
+	require.NoError(t, err)
+	assert.Equal(t, "struct {\n    Name string\n}", types[0].String(""))
+
+	packages, err := generator.(*astTypeGenerator).expandPackagePatterns("example.com/repo/...")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com/repo/user"}, packages)
+
+	types, err = NewGenerator(WithConfig(Config{Dir: repoDir}), WithGitRevision("HEAD")).GenerateTypesFromSpecs(spec)
+	require.NoError(t, err)
+	assert.Len(t, types[0].StructType.Fields, 2)
+
+	_, err = NewGenerator(WithConfig(Config{Dir: repoDir}), WithGitRevision("v9")).GenerateTypesFromSpecs(spec)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot find git revision v9")
+}
+
+func TestChainFinder(t *testing.T) {
+	finder := NewChainFinder(
+		NewVendorFinder(filepath.Join("testdata", "finders", "vendor"), nil),
+		NewModuleFinder(nil),
+	)
+	types, err := NewGenerator(WithSourceFinder(finder)).GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: "example.com/vendored", Name: "Vendored"},
+		TypeSpec{PackagePath: testdataPackage + "/ifaces", Name: "Reader"},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "struct {\n    Name string\n}", types[0].String(""))
+	assert.NotNil(t, types[1].InterfaceType)
+
+	_, err = finder.GetPackageSourceFiles("example.com/missing")
+	assert.True(t, errors.Is(err, ErrPackageNotFound))
+}
+
+func TestGoListFinder(t *testing.T) {
+	finder := NewGoListFinder(nil)
+	types, err := NewGenerator(WithSourceFinder(finder)).GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: testdataPackage + "/ifaces", Name: "Reader"},
+		TypeSpec{PackagePath: "io", Name: "Reader"},
+		TypeSpec{PackagePath: "./testdata/declarations", Name: "Level"},
+	)
+	require.NoError(t, err)
+	assert.NotNil(t, types[0].InterfaceType)
+	assert.Equal(t, "interface {\n    Read(p []byte) (n int, err error)\n}", types[1].String(""))
+	assert.Equal(t, "int", types[2].String(""))
+
+	sourceFiles, err := finder.GetPackageSourceFiles(testdataPackage + "/ifaces")
+	require.NoError(t, err)
+	require.Len(t, sourceFiles, 1)
+	assert.Equal(t, "ifaces.go", filepath.Base(sourceFiles[0]))
+
+	_, err = finder.GetPackageSourceFiles("example.com/missing")
+	assert.True(t, errors.Is(err, ErrPackageNotFound))
+	_, err = finder.GetPackageSourceFiles(testdataPackage + "/ifaces@v1.0.0")
+	assert.True(t, errors.Is(err, ErrPackageNotFound))
+
+	// like the go tool, the testdata directories are skipped.
+	modulePath := strings.TrimSuffix(testdataPackage, "/testdata")
+	packages, err := finder.(packageLister).ListPackages(modulePath + "/...")
+	require.NoError(t, err)
+	assert.Equal(t, []string{modulePath, modulePath + "/gotypetest"}, packages)
+}
+
+func TestMergeFinder(t *testing.T) {
+	finder := NewMergeFinder(
+		NewModuleFinder(nil),
+		NewDirFinder(testdataPackage+"/finders", filepath.Join("testdata", "finders_gen"), nil),
+	)
+	generator := NewGenerator(WithSourceFinder(finder), WithDeepResolution())
+	types, err := generator.GenerateTypesFromSpecs(TypeSpec{PackagePath: testdataPackage + "/finders", Name: "Model"})
+	require.NoError(t, err)
+	id := types[0].StructType.Fields[0].Type.QualType
+	require.NotNil(t, id.Underlying)
+	assert.Equal(t, "int64", id.Underlying.String(""))
+
+	packages, err := generator.(*astTypeGenerator).expandPackagePatterns(testdataPackage + "/finders/...")
+	require.NoError(t, err)
+	assert.Equal(t, []string{testdataPackage + "/finders"}, packages)
+}
+
+func TestDeclarationChain(t *testing.T) {
+	types, err := NewGenerator(WithDeepResolution()).GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: testdataPackage + "/chain", Name: "Holder"},
+	)
+	require.NoError(t, err)
+
+	field := types[0].StructType.Fields[0].Type.QualType
+	links := make([]string, 0, len(field.Chain))
+	for _, link := range field.Chain {
+		name := strings.TrimPrefix(link.Package, testdataPackage+"/") + "." + link.Name
+		if link.Alias {
+			name += " (alias)"
+		}
+		links = append(links, name)
+	}
+	expected := []string{"chain.A (alias)", "chain.B", "chain.C", "chain/inner.Shape (alias)", "chain/inner.shape"}
+	assert.Equal(t, expected, links)
+	assert.Equal(t, "chain.go", filepath.Base(field.Chain[1].Position.Filename))
+	assert.Equal(t, 11, field.Chain[1].Position.Line)
+
+	assert.Equal(t, field.Chain[1:], field.Underlying.QualType.Chain)
+	types, err = NewGenerator().GenerateTypesFromSpecs(TypeSpec{PackagePath: testdataPackage + "/chain", Name: "Holder"})
+	require.NoError(t, err)
+	assert.Nil(t, types[0].StructType.Fields[0].Type.QualType.Chain)
+}
+
+func TestTypePositions(t *testing.T) {
+	spec := TypeSpec{PackagePath: testdataPackage + "/chain", Name: "Holder"}
+	for _, generator := range []TypeGenerator{NewGenerator(WithDeepResolution()), NewGenerator(WithTypeChecking())} {
+		types, err := generator.GenerateTypesFromSpecs(spec)
+		require.NoError(t, err)
+		assert.Equal(t, "chain.go", filepath.Base(types[0].Position.Filename))
+		assert.Equal(t, 5, types[0].Position.Line)
+		assert.Equal(t, token.Position{}, types[0].StructType.Fields[0].Type.Position)
+	}
+
+	types, err := NewGenerator(WithDeepResolution()).GenerateTypesFromSpecs(spec)
+	require.NoError(t, err)
+	underlying := types[0].StructType.Fields[0].Type.QualType.Underlying
+	require.NotNil(t, underlying)
+	assert.Equal(t, 9, underlying.Position.Line)
+}
+
+func TestTypeDocs(t *testing.T) {
+	specs := []TypeSpec{
+		{PackagePath: testdataPackage + "/docs", Name: "Order"},
+		{PackagePath: testdataPackage + "/docs", Name: "Store"},
+	}
+	generators := []TypeGenerator{
+		NewGenerator(WithDeepResolution()),
+		NewGenerator(WithDeepResolution(), WithTypeChecking()),
+	}
+	for _, generator := range generators {
+		types, err := generator.GenerateTypesFromSpecs(specs...)
+		require.NoError(t, err)
+
+		order := types[0]
+		assert.Equal(t, "Order is an order.\n", order.Doc)
+		assert.Equal(t, "ID identifies the order.\n", order.StructType.Fields[0].Doc)
+		assert.Equal(t, "", order.StructType.Fields[0].Comment)
+		assert.Equal(t, "", order.StructType.Fields[1].Doc)
+		assert.Equal(t, "Status is the order's status.\n", order.StructType.Fields[1].Comment)
+		assert.Equal(t, "", order.StructType.Fields[1].Type.Doc)
+		status := order.StructType.Fields[1].Type.QualType.Underlying
+		require.NotNil(t, status)
+		assert.Equal(t, "Status is the status of an Order.\n", status.Doc)
+		assert.Equal(t, 13, status.Position.Line)
+
+		store := types[1]
+		assert.Equal(t, "Store stores the orders.\n", store.Doc)
+		assert.Equal(t, "Get", store.InterfaceType.Methods[0].Name)
+		assert.Equal(t, "Get returns an order.\n", store.InterfaceType.Methods[0].Doc)
+	}
+}
+
+func TestTypeAliases(t *testing.T) {
+	specs := []TypeSpec{
+		{PackagePath: testdataPackage + "/chain", Name: "A"},
+		{PackagePath: testdataPackage + "/chain", Name: "B"},
+	}
+	for _, generator := range []TypeGenerator{NewGenerator(), NewGenerator(WithTypeChecking())} {
+		types, err := generator.GenerateTypesFromSpecs(specs...)
+		require.NoError(t, err)
+		assert.True(t, types[0].IsAlias)
+		assert.False(t, types[1].IsAlias)
+		assert.Equal(t, "B", types[0].QualType.Name)
+		assert.Equal(t, "C", types[1].QualType.Name)
+	}
+}
+
+func TestTypeMethods(t *testing.T) {
+	specs := []TypeSpec{
+		{PackagePath: testdataPackage + "/docs", Name: "Order"},
+		{PackagePath: testdataPackage + "/docs", Name: "Item"},
+		{PackagePath: testdataPackage + "/generics", Name: "Tree"},
+	}
+	generators := []TypeGenerator{NewGenerator(WithMethods()), NewGenerator(WithMethods(), WithTypeChecking())}
+	for _, generator := range generators {
+		types, err := generator.GenerateTypesFromSpecs(specs...)
+		require.NoError(t, err)
+
+		order := types[0].Methods
+		require.Len(t, order, 2)
+		assert.Equal(t, "Total", order[0].Name)
+		assert.True(t, order[0].PointerReceiver)
+		assert.Equal(t, "Total returns the total price.\n", order[0].Doc)
+		assert.Equal(t, 26, order[0].Position.Line)
+		assert.Equal(t, "float64", order[0].Func.Outputs[0].Type.String(""))
+		assert.Equal(t, "count", order[1].Name)
+		assert.False(t, order[1].PointerReceiver)
+		assert.Equal(t, "", order[1].Doc)
+
+		assert.Nil(t, types[1].Methods)
+
+		// the signatures refer to the type parameters declared by the type, not by the receivers.
+		tree := types[2].Methods
+		require.Len(t, tree, 2)
+		assert.Equal(t, "Insert", tree[0].Name)
+		assert.Equal(t, "T", tree[0].Func.Inputs[0].Type.String(""))
+		assert.Equal(t, "*generics.Tree[T]", tree[0].Func.Outputs[0].Type.String(""))
+		assert.Equal(t, "Size", tree[1].Name)
+	}
+
+	types, err := NewGenerator(WithMethods(), WithExportedOnly()).GenerateTypesFromSpecs(specs[0])
+	require.NoError(t, err)
+	require.Len(t, types[0].Methods, 1)
+	assert.Equal(t, "Total", types[0].Methods[0].Name)
+
+	types, err = NewGenerator().GenerateTypesFromSpecs(specs[0])
+	require.NoError(t, err)
+	assert.Nil(t, types[0].Methods)
+}
+
+func TestMaxDepth(t *testing.T) {
+	spec := TypeSpec{PackagePath: testdataPackage + "/chain", Name: "Holder"}
+	types, err := NewGenerator(WithDeepResolution(), WithMaxDepth(2)).GenerateTypesFromSpecs(spec)
+	require.NoError(t, err)
+
+	a := types[0].StructType.Fields[0].Type.QualType
+	require.NotNil(t, a.Underlying)
+	b := a.Underlying.QualType
+	require.NotNil(t, b.Underlying)
+	c := b.Underlying.QualType
+	assert.Equal(t, "C", c.Name)
+	assert.Nil(t, c.Underlying)
+	assert.Len(t, a.Chain, 2)
+
+	types, err = NewGenerator(WithDeepResolution(), WithMaxDepth(1)).GenerateTypesFromSpecs(spec)
+	require.NoError(t, err)
+	assert.Nil(t, types[0].StructType.Fields[0].Type.QualType.Underlying.QualType.Underlying)
+
+	types, err = NewGenerator(WithDeepResolution(), WithMaxDepth(0)).GenerateTypesFromSpecs(spec)
+	require.NoError(t, err)
+	assert.NotNil(t, types[0].StructType.Fields[0].Type.QualType.Underlying.QualType.Underlying.QualType.Underlying)
+}
+
+func TestAnalyzeTypeMetrics(t *testing.T) {
+	metrics, err := AnalyzeTypeMetrics(testdataPackage + "/metrics/...")
+	require.NoError(t, err)
+	require.Len(t, metrics, 3)
+
+	assert.Equal(t, TypeMetrics{
+		Type:               QualType{Package: testdataPackage + "/metrics", Name: "Order"},
+		FieldCount:         4,
+		NestingDepth:       4,
+		ReferencedPackages: []string{testdataPackage + "/metrics/sub", "io", "time"},
+		MethodCount:        2,
+	}, metrics[0])
+	assert.Equal(t, TypeMetrics{
+		Type:               QualType{Package: testdataPackage + "/metrics", Name: "Store"},
+		NestingDepth:       1,
+		ReferencedPackages: []string{},
+		MethodCount:        2,
+	}, metrics[1])
+	assert.Equal(t, "Item", metrics[2].Type.Name)
+	assert.Equal(t, 1, metrics[2].FieldCount)
+}
+
+func TestFindUnusedExportedTypes(t *testing.T) {
+	unused, err := FindUnusedExportedTypes(testdataPackage + "/unused/...")
+	require.NoError(t, err)
+	assert.Equal(t, []QualType{
+		{Package: testdataPackage + "/unused/api", Name: "Unused"},
+		{Package: testdataPackage + "/unused/api", Name: "UsedInOwnPackage"},
+		{Package: testdataPackage + "/unused/api", Name: "UsedByTest"},
+		{Package: testdataPackage + "/unused/consumer", Name: "Consumer"},
+	}, unused)
+}
+
+func TestAnalyzeTypeUsage(t *testing.T) {
+	report, err := AnalyzeTypeUsage(testdataPackage + "/usage/...")
+	require.NoError(t, err)
+
+	type usage struct {
+		name         string
+		declarations []string
+		kinds        []UsageKind
+	}
+	usages := make([]usage, 0, len(report.Types))
+	for _, typ := range report.Types {
+		require.Equal(t, len(typ.References), typ.Count)
+		u := usage{name: typ.Package + "." + typ.Name}
+		for _, reference := range typ.References {
+			u.declarations = append(u.declarations, reference.Declaration)
+			u.kinds = append(u.kinds, reference.Kind)
+		}
+		usages = append(usages, u)
+	}
+
+	sub := testdataPackage + "/usage/sub"
+	assert.Equal(t, []usage{
+		{sub + ".Address", []string{"Order.Ship", "Customer.Address"}, []UsageKind{UsageKindSignature, UsageKindField}},
+		{sub + ".Item", []string{"Order.Items", "Reader.Read"}, []UsageKind{UsageKindField, UsageKindSignature}},
+		{"time.Time", []string{"Order.At", "Order.Ship"}, []UsageKind{UsageKindField, UsageKindSignature}},
+		{testdataPackage + "/usage.Customer", []string{"Order.Customer"}, []UsageKind{UsageKindField}},
+		{testdataPackage + "/usage.Order", []string{"Store.Save"}, []UsageKind{UsageKindSignature}},
+		{sub + ".Reader", []string{"Store"}, []UsageKind{UsageKindEmbedding}},
+	}, usages)
+	assert.Equal(t, testdataPackage+"/usage", report.Types[0].References[1].Package)
+	assert.Contains(t, report.Types[0].References[1].Position, "usage.go:18:")
+
+	data, err := report.CSV()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "package,name,count,referencing_package,declaration,kind,position\n")
+	assert.Contains(t, string(data), sub+",Reader,1,"+testdataPackage+"/usage,Store,embedding,\n")
+
+	data, err = report.JSON()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"kind": "embedding"`)
+}
+
+func TestDeterminismCheck(t *testing.T) {
+	specs := []TypeSpec{
+		{PackagePath: testdataPackage + "/usage", Name: "Order"},
+		{PackagePath: testdataPackage + "/chain", Name: "Holder"},
+		{PackagePath: testdataPackage + "/usage", Name: "Store"},
+	}
+	expected, err := NewGenerator(WithDeepResolution()).GenerateTypesFromSpecs(specs...)
+	require.NoError(t, err)
+
+	warnings := 0
+	generator := NewGenerator(
+		WithDeepResolution(),
+		WithDeterminismCheck(),
+		WithWarningHandler(func(Warning) { warnings++ }),
+	)
+	types, err := generator.GenerateTypesFromSpecs(specs...)
+	require.NoError(t, err)
+	assert.Equal(t, expected, types)
+	assert.Zero(t, warnings)
+
+	_, err = generator.GenerateTypesFromSpecs(TypeSpec{PackagePath: testdataPackage + "/usage", Name: "Missing"})
+	assert.True(t, errors.Is(err, ErrTypeNotFound))
+}
+
+// reversedFinder returns the source files found by its SourceFinder in the reverse order.
+type reversedFinder struct {
+	SourceFinder
+}
+
+func (s reversedFinder) GetPackageSourceFiles(packagePath string) ([]string, error) {
+	goSources, err := s.SourceFinder.GetPackageSourceFiles(packagePath)
+	reversed := make([]string, 0, len(goSources))
+	for i := len(goSources) - 1; i >= 0; i-- {
+		reversed = append(reversed, goSources[i])
+	}
+	return reversed, err
+}
+
+func TestDeclarationOrder(t *testing.T) {
+	generator := NewGenerator(WithSourceFinder(reversedFinder{NewModuleFinder(nil)}))
+	metrics, err := generator.AnalyzeTypeMetrics(testdataPackage + "/order")
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(metrics))
+	for _, m := range metrics {
+		names = append(names, m.Type.Name)
+	}
+	assert.Equal(t, []string{"First", "Second", "Third"}, names)
+}
+
+// mapFinder finds the source files of the packages listed by the map, like a SourceFinder implemented outside the
+// package.
+type mapFinder map[string][]string
+
+func (s mapFinder) GetPackageSourceFiles(packagePath string) ([]string, error) {
+	goSources, ok := s[packagePath]
+	if !ok {
+		return nil, fmt.Errorf("cannot find package %s: %w", packagePath, ErrPackageNotFound)
+	}
+	return goSources, nil
+}
+
+func TestCustomSourceFinder(t *testing.T) {
+	finder := mapFinder{"example.com/order": {"testdata/order/a.go", "testdata/order/b.go"}}
+	types, err := NewGenerator(WithSourceFinder(finder)).GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: "example.com/order", Name: "Third"},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "int", types[0].String(""))
+
+	_, err = NewGenerator(WithSourceFinder(finder)).GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: testdataPackage + "/order", Name: "Third"},
+	)
+	assert.True(t, errors.Is(err, ErrPackageNotFound))
+}
+
+func TestNamingPolicy(t *testing.T) {
+	names := func(opts ...Option) []string {
+		types, err := NewGenerator(append(opts, WithOrdering(OrderingSource))...).GenerateTypesFromSpecs(
+			TypeSpec{PackagePath: testdataPackage + "/naming", Name: "Handler"},
+		)
+		require.NoError(t, err)
+
+		result := make([]string, 0)
+		for _, method := range types[0].InterfaceType.Methods {
+			for _, field := range append(append([]TypeField{}, method.Func.Inputs...), method.Func.Outputs...) {
+				result = append(result, field.Name)
+			}
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"arg1", "arg2", "arg3", "out1", "out2", "out1", "out2", "out3"}, names())
+	assert.Equal(
+		t,
+		[]string{"ctx", "arg_2", "err", "out_1", "err2", "out1", "out_1", "err"},
+		names(WithNamingPolicy(NamingPolicy{Separator: "_", TypeNames: ConventionalTypeNames})),
+	)
+	assert.Equal(
+		t,
+		[]string{"Ctx", "In2", "Err", "Out1", "Err2", "out1", "Out1", "Err"},
+		names(WithNamingPolicy(NamingPolicy{InputPrefix: "in", Capitalized: true, TypeNames: ConventionalTypeNames})),
+	)
+}
+
+func TestGenerateFromSpecs(t *testing.T) {
+	pkg := testdataPackage + "/declarations"
+	declarations, err := GenerateFromSpecs(
+		Spec{PackagePath: pkg, Name: "Client"},
+		Spec{PackagePath: pkg, Name: "Client.Do", Kind: SpecKindMethod},
+		Spec{PackagePath: pkg, Name: "New", Kind: SpecKindFunc},
+		Spec{PackagePath: pkg, Name: "Map", Kind: SpecKindFunc},
+		Spec{PackagePath: pkg, Name: "LevelInfo", Kind: SpecKindConst},
+		Spec{PackagePath: pkg, Name: "Ratio", Kind: SpecKindConst},
+		Spec{PackagePath: pkg, Name: "DefaultClient", Kind: SpecKindVar},
+		Spec{PackagePath: pkg, Name: "retries", Kind: SpecKindVar},
+		Spec{PackagePath: pkg, Name: "delay", Kind: SpecKindVar},
+	)
+	require.NoError(t, err)
+	require.Len(t, declarations, 9)
+
+	types := make([]string, 0, len(declarations))
+	for _, declaration := range declarations {
+		types = append(types, declaration.Type.String(""))
+	}
+	assert.Equal(t, []string{
+		"struct {\n    Timeout time.Duration\n}",
+		"func(ctx context.Context, request string) (out1 string, out2 error)",
+		"func(timeout time.Duration) (out1 *declarations.Client)",
+		"func(items []T, fn func(arg1 T) (out1 U)) (out1 []U)",
+		"declarations.Level",
+		"float64",
+		"*declarations.Client",
+		"int",
+		"unknown",
+	}, types)
+
+	assert.Equal(t, "Client calls the remote service.\n", declarations[0].Doc)
+	assert.Equal(t, 9, declarations[0].Position.Line)
+	assert.Equal(t, &Receiver{
+		Name:    "c",
+		Pointer: true,
+		Type:    QualType{Package: pkg, ShortPackagePath: "declarations", Name: "Client"},
+	}, declarations[1].Receiver)
+	assert.Nil(t, declarations[2].Receiver)
+	assert.Equal(t, "Do sends the request.\n", declarations[1].Doc)
+	require.Len(t, declarations[3].Type.TypeParams, 2)
+	assert.Equal(t, "iota", declarations[4].Value)
+	assert.Equal(t, "", declarations[4].Doc)
+	assert.Equal(t, "2 * 1.5", declarations[5].Value)
+	assert.Equal(t, "DefaultClient is used by default.\n", declarations[6].Doc)
+	assert.Equal(t, "time.Now()", declarations[8].Value)
+
+	_, err = GenerateFromSpecs(Spec{PackagePath: pkg, Name: "Missing", Kind: SpecKindVar})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrDeclarationNotFound))
+	assert.Contains(t, err.Error(), "cannot find var Missing in package "+pkg)
+
+	_, err = GenerateFromSpecs(Spec{PackagePath: pkg, Name: "Missing"})
+	assert.True(t, errors.Is(err, ErrDeclarationNotFound))
+}
+
+func TestExportedOnly(t *testing.T) {
+	pkg := testdataPackage + "/exported"
+	generator := NewGenerator(WithExportedOnly()).(*astTypeGenerator)
+
+	types, err := generator.GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: pkg, Name: "User"},
+		TypeSpec{PackagePath: pkg, Name: "Store"},
+		TypeSpec{PackagePath: pkg, Name: "session"},
+	)
+	require.NoError(t, err)
+	require.Len(t, types[0].StructType.Fields, 1)
+	assert.Equal(t, "Name", types[0].StructType.Fields[0].Name)
+	require.Len(t, types[1].InterfaceType.Methods, 1)
+	assert.Equal(t, "Get", types[1].InterfaceType.Methods[0].Name)
+	assert.NotNil(t, types[2].StructType)
+
+	names, err := generator.getDeclaredTypeNames(pkg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"User", "Store"}, names)
+
+	methods, err := generator.generateMethods(pkg, "User")
+	require.NoError(t, err)
+	require.Len(t, methods, 1)
+	assert.Equal(t, "Greet", methods[0].method.Name)
+
+	types, err = GenerateTypesFromSpecs(TypeSpec{PackagePath: pkg, Name: "User"})
+	require.NoError(t, err)
+	assert.Len(t, types[0].StructType.Fields, 2)
+}
+
+func TestLocalTypes(t *testing.T) {
+	pkg := testdataPackage + "/local"
+	specs := []TypeSpec{
+		{PackagePath: pkg, Name: "Handler.request"},
+		{PackagePath: pkg, Name: "Server.Serve.response"},
+		{PackagePath: pkg, Name: "Map.entry"},
+	}
+
+	types, err := NewGenerator(WithLocalTypes(), WithDeepResolution()).GenerateTypesFromSpecs(specs...)
+	require.NoError(t, err)
+	assert.Equal(t, "struct {\n    ID int\n    From local.Handler.address\n}", types[0].String(""))
+	from := types[0].StructType.Fields[1].Type.QualType
+	require.NotNil(t, from.Underlying)
+	assert.Equal(t, "struct {\n    Host string\n}", from.Underlying.String(""))
+	assert.Equal(t, "struct {\n    Body []byte\n}", types[1].String(""))
+	assert.Equal(t, "struct {\n    Key K\n    Value V\n}", types[2].String(""))
+
+	_, err = GenerateTypesFromSpecs(specs[0])
+	assert.True(t, errors.Is(err, ErrTypeNotFound))
+}
+
+func TestLineDirectives(t *testing.T) {
+	pkg := testdataPackage + "/linedirective"
+	spec := TypeSpec{PackagePath: pkg, Name: "User"}
+	dir, err := filepath.Abs(filepath.Join("testdata", "linedirective"))
+	require.NoError(t, err)
+
+	types, err := GenerateTypesFromSpecs(spec)
+	require.NoError(t, err)
+	position := types[0].StructType.Fields[1].Position
+	assert.Equal(t, filepath.Join(dir, "model.tmpl"), position.Filename)
+	assert.Equal(t, 12, position.Line)
+
+	declarations, err := GenerateFromSpecs(Spec{PackagePath: pkg, Name: "User.Greet", Kind: SpecKindMethod})
+	require.NoError(t, err)
+	assert.Equal(t, 30, declarations[0].Position.Line)
+
+	types, err = NewGenerator(WithoutLineDirectives()).GenerateTypesFromSpecs(spec)
+	require.NoError(t, err)
+	position = types[0].StructType.Fields[1].Position
+	assert.Equal(t, filepath.Join(dir, "generated.go"), position.Filename)
+	assert.Equal(t, 8, position.Line)
+}
@@ -0,0 +1,339 @@
+package gotype
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+)
+
+// binaryWidths contains the number of bytes used to encode the primitive kinds with a fixed width.
+var binaryWidths = map[PrimitiveKind]int{
+	PrimitiveKindBool:    1,
+	PrimitiveKindByte:    1,
+	PrimitiveKindInt8:    1,
+	PrimitiveKindUint8:   1,
+	PrimitiveKindInt16:   2,
+	PrimitiveKindUint16:  2,
+	PrimitiveKindRune:    4,
+	PrimitiveKindInt32:   4,
+	PrimitiveKindUint32:  4,
+	PrimitiveKindFloat32: 4,
+	PrimitiveKindInt:     8,
+	PrimitiveKindUint:    8,
+	PrimitiveKindInt64:   8,
+	PrimitiveKindUint64:  8,
+	PrimitiveKindFloat64: 8,
+}
+
+// BinaryCodecOption configures RenderBinaryCodec.
+type BinaryCodecOption func(*binaryRenderer)
+
+// WithVarintEncoding makes the rendered codec encode the integers wider than a byte as varints, which are smaller for
+// the small values. By default, the integers are encoded using their fixed widths.
+func WithVarintEncoding() BinaryCodecOption {
+	return func(r *binaryRenderer) {
+		r.varint = true
+	}
+}
+
+// WithBigEndian makes the rendered codec encode the fixed width values using the big endian byte order. By default,
+// the little endian byte order is used.
+func WithBigEndian() BinaryCodecOption {
+	return func(r *binaryRenderer) {
+		r.byteOrder = "binary.BigEndian"
+	}
+}
+
+// binaryRenderer renders the statements encoding and decoding the values of a struct's fields.
+type binaryRenderer struct {
+	codeWriter
+	moduleName string
+	varint     bool
+	byteOrder  string
+}
+
+// RenderBinaryCodec renders the Golang's source code of the `MarshalBinary` and `UnmarshalBinary` methods of the struct
+// type named `typeName`, whose definition is `typ`, implementing the `encoding.BinaryMarshaler` and
+// `encoding.BinaryUnmarshaler` interfaces using a compact layout without reflection. The exported fields are encoded
+// in their declaration order, without any field names or type information:
+//   - the booleans and numbers are encoded using their fixed widths, or as varints using `WithVarintEncoding`.
+//     The `int` and `uint` values are encoded using 8 bytes.
+//   - the strings and byte slices are prefixed by their lengths encoded as uvarints.
+//   - the slices are prefixed by their lengths, and the arrays are encoded element by element.
+//   - the pointers are prefixed by a byte telling whether they're nil.
+//   - `time.Time` values are encoded as the number of nanoseconds since the Unix epoch.
+//
+// The fields of the QualTypes are encoded using their `Underlying` definitions filled by the deep resolution. The
+// unresolved QualTypes, and the resolved QualTypes of struct types, are expected to implement the interfaces as well,
+// e.g. by rendering their codecs using RenderBinaryCodec, and are prefixed by their lengths. The maps, channels,
+// functions and interfaces are not supported. The rendered code only contains the methods, the package clause and
+// imports are left to the caller. It uses the "encoding/binary", "fmt", "io", "math" and "time" packages.
+func RenderBinaryCodec(typeName string, typ Type, moduleName string, opts ...BinaryCodecOption) (string, error) {
+	if typ.StructType == nil {
+		return "", fmt.Errorf("cannot render binary codec of a non-struct type: %s", typ.String(moduleName))
+	}
+	if typ.IsGeneric() {
+		return "", fmt.Errorf("cannot render binary codec of the generic type %s", typeName)
+	}
+
+	r := &binaryRenderer{moduleName: moduleName, byteOrder: "binary.LittleEndian"}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	fields := make([]TypeField, 0, len(typ.StructType.Fields))
+	for _, field := range typ.StructType.Fields {
+		if ast.IsExported(field.Name) {
+			fields = append(fields, field)
+		}
+	}
+
+	// the encoding statements are rendered first, to find out whether the scratch buffer is needed.
+	body := &binaryRenderer{moduleName: r.moduleName, varint: r.varint, byteOrder: r.byteOrder}
+	for _, field := range fields {
+		if err := body.encode(1, "z."+field.Name, field.Type); err != nil {
+			return "", fmt.Errorf("cannot render encoding of field %s: %w", field.Name, err)
+		}
+	}
+
+	r.line(0, "// MarshalBinary implements encoding.BinaryMarshaler.")
+	r.line(0, "func (z *%s) MarshalBinary() ([]byte, error) {", typeName)
+	if strings.Contains(body.b.String(), "scratch") {
+		r.line(1, "var scratch [binary.MaxVarintLen64]byte")
+	}
+	r.line(1, "b := make([]byte, 0, 64)")
+	r.b.WriteString(body.b.String())
+	r.line(1, "return b, nil")
+	r.line(0, "}")
+
+	r.line(0, "")
+	r.line(0, "// UnmarshalBinary implements encoding.BinaryUnmarshaler.")
+	r.line(0, "func (z *%s) UnmarshalBinary(data []byte) error {", typeName)
+	for _, field := range fields {
+		if err := r.decode(1, "z."+field.Name, field.Type); err != nil {
+			return "", fmt.Errorf("cannot render decoding of field %s: %w", field.Name, err)
+		}
+	}
+	r.line(1, "if len(data) != 0 {")
+	r.line(2, "return fmt.Errorf(\"%%d unexpected trailing bytes\", len(data))")
+	r.line(1, "}")
+	r.line(1, "return nil")
+	r.line(0, "}")
+
+	return r.b.String(), nil
+}
+
+// encode renders the statements appending the encoded `expr` of type `typ` into `b`.
+func (r *binaryRenderer) encode(indent int, expr string, typ Type) error {
+	switch {
+	case isByteSlice(typ):
+		r.line(indent, "b = append(b, scratch[:binary.PutUvarint(scratch[:], uint64(len(%s)))]...)", expr)
+		r.line(indent, "b = append(b, %s...)", expr)
+	case isTimeType(typ):
+		return r.encode(indent, expr+".UnixNano()", PrimitiveType{Kind: PrimitiveKindInt64}.Type())
+	case typ.PrimitiveType != nil:
+		return r.encodePrimitive(indent, expr, typ.PrimitiveType.Kind)
+	case typ.QualType != nil:
+		underlying := typ.QualType.Underlying
+		if underlying == nil || underlying.StructType != nil {
+			encoded := r.newVar("v")
+			r.line(indent, "%s, err := %s.MarshalBinary()", encoded, operand(expr))
+			r.line(indent, "if err != nil {")
+			r.line(indent+1, "return nil, err")
+			r.line(indent, "}")
+			return r.encode(indent, encoded, Type{SliceType: &SliceType{Elem: PrimitiveType{Kind: PrimitiveKindByte}.Type()}})
+		}
+		return r.encode(indent, underlying.String(r.moduleName)+"("+expr+")", *underlying)
+	case typ.PtrType != nil:
+		r.line(indent, "if %s == nil {", expr)
+		r.line(indent+1, "b = append(b, 0)")
+		r.line(indent, "} else {")
+		r.line(indent+1, "b = append(b, 1)")
+		if err := r.encode(indent+1, "*"+expr, typ.PtrType.Elem); err != nil {
+			return err
+		}
+		r.line(indent, "}")
+	case typ.SliceType != nil, typ.ArrayType != nil:
+		if typ.SliceType != nil {
+			r.line(indent, "b = append(b, scratch[:binary.PutUvarint(scratch[:], uint64(len(%s)))]...)", expr)
+		}
+		elem := r.newVar("v")
+		r.line(indent, "for _, %s := range %s {", elem, expr)
+		if err := r.encode(indent+1, elem, elemType(typ)); err != nil {
+			return err
+		}
+		r.line(indent, "}")
+	default:
+		return fmt.Errorf("unsupported type %s", typ.String(r.moduleName))
+	}
+	return nil
+}
+
+func (r *binaryRenderer) encodePrimitive(indent int, expr string, kind PrimitiveKind) error {
+	width, ok := binaryWidths[kind]
+	switch {
+	case kind == PrimitiveKindString:
+		r.line(indent, "b = append(b, scratch[:binary.PutUvarint(scratch[:], uint64(len(%s)))]...)", expr)
+		r.line(indent, "b = append(b, %s...)", expr)
+	case kind == PrimitiveKindBool:
+		r.line(indent, "if %s {", expr)
+		r.line(indent+1, "b = append(b, 1)")
+		r.line(indent, "} else {")
+		r.line(indent+1, "b = append(b, 0)")
+		r.line(indent, "}")
+	case !ok:
+		return fmt.Errorf("unsupported type %s", kind)
+	case width == 1:
+		r.line(indent, "b = append(b, byte(%s))", expr)
+	case kind == PrimitiveKindFloat32:
+		r.line(indent, "%s.PutUint32(scratch[:4], math.Float32bits(%s))", r.byteOrder, expr)
+		r.line(indent, "b = append(b, scratch[:4]...)")
+	case kind == PrimitiveKindFloat64:
+		r.line(indent, "%s.PutUint64(scratch[:8], math.Float64bits(%s))", r.byteOrder, expr)
+		r.line(indent, "b = append(b, scratch[:8]...)")
+	case r.varint && kind.IsInteger() && !kind.IsUnsigned():
+		r.line(indent, "b = append(b, scratch[:binary.PutVarint(scratch[:], int64(%s))]...)", expr)
+	case r.varint:
+		r.line(indent, "b = append(b, scratch[:binary.PutUvarint(scratch[:], uint64(%s))]...)", expr)
+	default:
+		r.line(indent, "%s.PutUint%d(scratch[:%d], uint%d(%s))", r.byteOrder, width*8, width, width*8, expr)
+		r.line(indent, "b = append(b, scratch[:%d]...)", width)
+	}
+	return nil
+}
+
+// decode renders the statements reading a value of type `typ` from `data` into `target`.
+func (r *binaryRenderer) decode(indent int, target string, typ Type) error {
+	switch {
+	case isByteSlice(typ):
+		size := r.decodeLength(indent)
+		r.line(indent, "%s = append(%s[:0], data[:%s]...)", target, target, size)
+		r.line(indent, "data = data[%s:]", size)
+	case isTimeType(typ):
+		nanos := r.newVar("v")
+		r.line(indent, "var %s int64", nanos)
+		if err := r.decode(indent, nanos, PrimitiveType{Kind: PrimitiveKindInt64}.Type()); err != nil {
+			return err
+		}
+		r.line(indent, "%s = time.Unix(0, %s)", target, nanos)
+	case typ.PrimitiveType != nil:
+		return r.decodePrimitive(indent, target, typ.PrimitiveType.Kind, string(typ.PrimitiveType.Kind))
+	case typ.QualType != nil:
+		underlying := typ.QualType.Underlying
+		if underlying == nil || underlying.StructType != nil {
+			size := r.decodeLength(indent)
+			r.line(indent, "if err := %s.UnmarshalBinary(data[:%s]); err != nil {", operand(target), size)
+			r.line(indent+1, "return err")
+			r.line(indent, "}")
+			r.line(indent, "data = data[%s:]", size)
+			return nil
+		}
+		if underlying.PrimitiveType != nil {
+			return r.decodePrimitive(indent, target, underlying.PrimitiveType.Kind, typ.String(r.moduleName))
+		}
+		value := r.newVar("v")
+		r.line(indent, "var %s %s", value, underlying.String(r.moduleName))
+		if err := r.decode(indent, value, *underlying); err != nil {
+			return err
+		}
+		r.line(indent, "%s = %s(%s)", target, typ.String(r.moduleName), value)
+	case typ.PtrType != nil:
+		r.line(indent, "if len(data) < 1 {")
+		r.line(indent+1, "return io.ErrUnexpectedEOF")
+		r.line(indent, "}")
+		r.line(indent, "if data[0] == 0 {")
+		r.line(indent+1, "%s = nil", target)
+		r.line(indent+1, "data = data[1:]")
+		r.line(indent, "} else {")
+		r.line(indent+1, "data = data[1:]")
+		r.line(indent+1, "%s = new(%s)", target, typ.PtrType.Elem.String(r.moduleName))
+		if err := r.decode(indent+1, "*"+target, typ.PtrType.Elem); err != nil {
+			return err
+		}
+		r.line(indent, "}")
+	case typ.SliceType != nil:
+		// each element takes at least a byte, so the count is checked like a length to avoid huge allocations.
+		size, index := r.decodeLength(indent), r.newVar("i")
+		r.line(indent, "%s = make(%s, %s)", target, typ.String(r.moduleName), size)
+		r.line(indent, "for %s := range %s {", index, target)
+		if err := r.decode(indent+1, target+"["+index+"]", typ.SliceType.Elem); err != nil {
+			return err
+		}
+		r.line(indent, "}")
+	case typ.ArrayType != nil:
+		index := r.newVar("i")
+		r.line(indent, "for %s := range %s {", index, target)
+		if err := r.decode(indent+1, target+"["+index+"]", typ.ArrayType.Elem); err != nil {
+			return err
+		}
+		r.line(indent, "}")
+	default:
+		return fmt.Errorf("unsupported type %s", typ.String(r.moduleName))
+	}
+	return nil
+}
+
+// decodePrimitive renders the statements reading a value of a primitive kind into `target` whose type is `typeName`.
+func (r *binaryRenderer) decodePrimitive(indent int, target string, kind PrimitiveKind, typeName string) error {
+	width, ok := binaryWidths[kind]
+	switch {
+	case kind == PrimitiveKindString:
+		size := r.decodeLength(indent)
+		r.line(indent, "%s = %s(data[:%s])", target, typeName, size)
+		r.line(indent, "data = data[%s:]", size)
+		return nil
+	case !ok:
+		return fmt.Errorf("unsupported type %s", kind)
+	case r.varint && width > 1 && kind != PrimitiveKindFloat32 && kind != PrimitiveKindFloat64:
+		value, n := r.newVar("v"), r.newVar("n")
+		function := "binary.Uvarint"
+		if kind.IsInteger() && !kind.IsUnsigned() {
+			function = "binary.Varint"
+		}
+		r.line(indent, "%s, %s := %s(data)", value, n, function)
+		r.line(indent, "if %s <= 0 {", n)
+		r.line(indent+1, "return io.ErrUnexpectedEOF")
+		r.line(indent, "}")
+		r.line(indent, "%s = %s(%s)", target, typeName, value)
+		r.line(indent, "data = data[%s:]", n)
+		return nil
+	}
+
+	r.line(indent, "if len(data) < %d {", width)
+	r.line(indent+1, "return io.ErrUnexpectedEOF")
+	r.line(indent, "}")
+	switch {
+	case kind == PrimitiveKindBool:
+		r.line(indent, "%s = data[0] != 0", target)
+	case width == 1:
+		r.line(indent, "%s = %s(data[0])", target, typeName)
+	case kind == PrimitiveKindFloat32:
+		r.line(indent, "%s = %s(math.Float32frombits(%s.Uint32(data)))", target, typeName, r.byteOrder)
+	case kind == PrimitiveKindFloat64:
+		r.line(indent, "%s = %s(math.Float64frombits(%s.Uint64(data)))", target, typeName, r.byteOrder)
+	default:
+		r.line(indent, "%s = %s(%s.Uint%d(data))", target, typeName, r.byteOrder, width*8)
+	}
+	r.line(indent, "data = data[%d:]", width)
+	return nil
+}
+
+// decodeCount renders the statements reading a uvarint count, and returns the name of the variable containing it.
+func (r *binaryRenderer) decodeCount(indent int) string {
+	size, n := r.newVar("l"), r.newVar("n")
+	r.line(indent, "%s, %s := binary.Uvarint(data)", size, n)
+	r.line(indent, "if %s <= 0 {", n)
+	r.line(indent+1, "return io.ErrUnexpectedEOF")
+	r.line(indent, "}")
+	r.line(indent, "data = data[%s:]", n)
+	return size
+}
+
+// decodeLength renders the statements reading the length of a value, and checking that the value isn't truncated.
+func (r *binaryRenderer) decodeLength(indent int) string {
+	size := r.decodeCount(indent)
+	r.line(indent, "if uint64(len(data)) < %s {", size)
+	r.line(indent+1, "return io.ErrUnexpectedEOF")
+	r.line(indent, "}")
+	return size
+}
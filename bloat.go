@@ -0,0 +1,90 @@
+package gotype
+
+// InterfaceBloat describes an interface which is considered bloated by `TypeGenerator.AnalyzeInterfaceBloat`.
+type InterfaceBloat struct {
+	// Interface identifies the bloated interface.
+	Interface QualType
+
+	// MethodCount contains the number of methods of the interface.
+	MethodCount int
+
+	// TooManyMethods is true when MethodCount exceeds the configured threshold.
+	TooManyMethods bool
+
+	// MaxMethodsUsedTogether contains the highest number of the interface's methods used together by a single function
+	// of the consumer packages.
+	MaxMethodsUsedTogether int
+
+	// NeverUsedTogether is true when the interface is used by the consumer packages, but no single function uses all
+	// of its methods. Such interface can probably be split into smaller ones.
+	NeverUsedTogether bool
+}
+
+// AnalyzeInterfaceBloat reports the bloated interfaces declared inside the package. See
+// `TypeGenerator.AnalyzeInterfaceBloat` for the details.
+func AnalyzeInterfaceBloat(packagePath string, maxMethods int, consumerPackages ...string) ([]InterfaceBloat, error) {
+	return defaultAstTypeGenerator.AnalyzeInterfaceBloat(packagePath, maxMethods, consumerPackages...)
+}
+
+func (f *astTypeGenerator) AnalyzeInterfaceBloat(
+	packagePath string,
+	maxMethods int,
+	consumerPackages ...string,
+) ([]InterfaceBloat, error) {
+	f = f.fork()
+	names, err := f.getDeclaredTypeNames(packagePath)
+	if err != nil {
+		return nil, err
+	}
+
+	specs := make([]TypeSpec, 0, len(names))
+	for _, name := range names {
+		specs = append(specs, TypeSpec{PackagePath: packagePath, Name: name})
+	}
+	types, err := f.generateTypesFromSpecs(specs)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]InterfaceBloat, 0)
+	for i, typ := range types {
+		if typ.InterfaceType == nil || len(typ.InterfaceType.Methods) == 0 {
+			continue
+		}
+
+		bloat := InterfaceBloat{
+			Interface:   QualType{Package: packagePath, Name: specs[i].Name},
+			MethodCount: len(typ.InterfaceType.Methods),
+		}
+		bloat.TooManyMethods = maxMethods > 0 && bloat.MethodCount > maxMethods
+
+		used := false
+		for _, consumer := range consumerPackages {
+			usages, err := f.collectMethodUsages(specs[i], consumer)
+			if err != nil {
+				return nil, err
+			}
+			for _, usage := range usages {
+				count := 0
+				for _, method := range typ.InterfaceType.Methods {
+					if _, ok := usage[method.Name]; ok {
+						count++
+					}
+				}
+				if count > 0 {
+					used = true
+				}
+				if count > bloat.MaxMethodsUsedTogether {
+					bloat.MaxMethodsUsedTogether = count
+				}
+			}
+		}
+		bloat.NeverUsedTogether = used && bloat.MaxMethodsUsedTogether < bloat.MethodCount
+
+		if bloat.TooManyMethods || bloat.NeverUsedTogether {
+			results = append(results, bloat)
+		}
+	}
+
+	return results, nil
+}
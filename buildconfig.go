@@ -0,0 +1,156 @@
+package gotype
+
+import (
+	"go/build"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Config controls the context in which the built-in source finders look for the packages, like the `Config` of the
+// "golang.org/x/tools/go/packages" package. Without a Config, the generator uses the process' working directory and
+// environment, and reads all the ".go" files of the packages. See `WithConfig`.
+type Config struct {
+	// Dir is the directory in which the go.mod file is looked up. It defaults to the process' working directory.
+	Dir string
+
+	// Env is the environment, like GOMODCACHE, GOPATH, GOROOT, GOOS and GOARCH, used to find the packages and to
+	// download the modules, with the same format as `os.Environ`. When a variable is set twice, the last one is used.
+	// A nil Env defaults to the process' environment.
+	Env []string
+
+	// BuildFlags contains the go tool's build flags. The `-tags` flag adds to `Tags`, the other flags are ignored.
+	BuildFlags []string
+
+	// Tests makes the finders include the "_test.go" files of the packages.
+	Tests bool
+
+	// Tags contains the build tags satisfied while reading the packages. The files whose build constraints, either
+	// `//go:build` lines or GOOS and GOARCH file name suffixes, aren't satisfied are skipped.
+	Tags []string
+}
+
+// lookupEnv returns the value of the environment variable `key`, from `Env` or from the process' environment.
+func (c *Config) lookupEnv(key string) (string, bool) {
+	if c == nil || c.Env == nil {
+		return os.LookupEnv(key)
+	}
+	value, ok := "", false
+	for _, kv := range c.Env {
+		if k, v, found := strings.Cut(kv, "="); found && k == key {
+			value, ok = v, true
+		}
+	}
+	return value, ok
+}
+
+// environ returns the environment of the commands run by the finders.
+func (c *Config) environ() []string {
+	if c == nil || c.Env == nil {
+		return os.Environ()
+	}
+	return append([]string{}, c.Env...)
+}
+
+// workingDir returns the directory in which the go.mod file is looked up.
+func (c *Config) workingDir() (string, error) {
+	dir := "."
+	if c != nil && c.Dir != "" {
+		dir = c.Dir
+	}
+	wd, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	return normalizePath(wd), nil
+}
+
+// buildTags returns the build tags declared by `Tags` and by the `-tags` build flag.
+func (c *Config) buildTags() []string {
+	tags := append([]string{}, c.Tags...)
+	for i, flag := range c.BuildFlags {
+		value := ""
+		switch {
+		case strings.HasPrefix(flag, "-tags=") || strings.HasPrefix(flag, "--tags="):
+			value = flag[strings.Index(flag, "=")+1:]
+		case (flag == "-tags" || flag == "--tags") && i+1 < len(c.BuildFlags):
+			value = c.BuildFlags[i+1]
+		default:
+			continue
+		}
+		tags = append(tags, strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' })...)
+	}
+	return tags
+}
+
+// goFlags returns the build flags of the go tool's commands run by the finders, that is, the `BuildFlags` with the
+// build tags of `buildTags` in a single `-tags` flag.
+func (c *Config) goFlags() []string {
+	if c == nil {
+		return nil
+	}
+	flags := make([]string, 0, len(c.BuildFlags)+2)
+	if tags := c.buildTags(); len(tags) > 0 {
+		flags = append(flags, "-tags", strings.Join(tags, ","))
+	}
+	for i := 0; i < len(c.BuildFlags); i++ {
+		switch flag := c.BuildFlags[i]; {
+		case strings.HasPrefix(flag, "-tags=") || strings.HasPrefix(flag, "--tags="):
+		case flag == "-tags" || flag == "--tags":
+			i++
+		default:
+			flags = append(flags, flag)
+		}
+	}
+	return flags
+}
+
+// wordBits returns the width of the int, uint and uintptr types under the target architecture, that is, the GOARCH
+// environment variable, or the architecture of the process. Without a Config, the architecture is unknown and zero
+// is returned.
+func (c *Config) wordBits() int {
+	if c == nil {
+		return 0
+	}
+	goarch := runtime.GOARCH
+	if value, ok := c.lookupEnv("GOARCH"); ok && value != "" {
+		goarch = value
+	}
+	switch goarch {
+	case "386", "amd64p32", "arm", "armbe", "mips", "mipsle", "mips64p32", "mips64p32le", "ppc", "riscv", "s390",
+		"sparc":
+		return 32
+	}
+	return 64
+}
+
+// matchFile reports whether the source file `name` inside `dir` is part of the package. Without a Config, every ".go"
+// file is. The file is read using `open` to evaluate its build constraints.
+func (c *Config) matchFile(dir, name string, open func(path string) (io.ReadCloser, error)) (bool, error) {
+	if filepath.Ext(name) != ".go" {
+		return false, nil
+	}
+	if c == nil {
+		return true, nil
+	}
+	if !c.Tests && strings.HasSuffix(name, "_test.go") {
+		return false, nil
+	}
+
+	ctx := build.Default
+	ctx.GOOS, ctx.GOARCH = runtime.GOOS, runtime.GOARCH
+	if goos, ok := c.lookupEnv("GOOS"); ok && goos != "" {
+		ctx.GOOS = goos
+	}
+	if goarch, ok := c.lookupEnv("GOARCH"); ok && goarch != "" {
+		ctx.GOARCH = goarch
+	}
+	if cgo, ok := c.lookupEnv("CGO_ENABLED"); ok {
+		ctx.CgoEnabled = cgo == "1"
+	}
+	ctx.BuildTags = c.buildTags()
+	ctx.OpenFile = open
+	return ctx.MatchFile(dir, name)
+}
@@ -0,0 +1,175 @@
+package gotype
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderMsgpackCodec(t *testing.T) {
+	types, err := NewGenerator(WithDeepResolution()).GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: testdataPackage + "/codec", Name: "Event"},
+		TypeSpec{PackagePath: testdataPackage + "/codec", Name: "Point"},
+	)
+	require.NoError(t, err)
+
+	// the msgp calls are type-checked against the stub of the msgp package inside the fixture.
+	code, err := RenderMsgpackCodec("Event", types[0], "codec")
+	require.NoError(t, err)
+	pointCode, err := RenderMsgpackCodec("Point", types[1], "codec")
+	require.NoError(t, err)
+	require.NoError(t, typeCheckCodec(code, pointCode), code)
+
+	assert.Contains(t, code, "\to = msgp.AppendMapHeader(b, 8)\n\to = msgp.AppendString(o, \"id\")\n")
+	assert.Contains(t, code, "\to = msgp.AppendInt(o, int(z.Level))\n")
+	assert.Contains(t, code, "\to = msgp.AppendBytes(o, z.Payload)\n")
+	assert.Contains(t, code, "\to, err = v3.MarshalMsg(o)\n")
+	assert.Contains(t, code, "\to = msgp.AppendTime(o, z.At)\n")
+	assert.NotContains(t, code, "Ignored")
+	assert.NotContains(t, code, "internal")
+
+	assert.Contains(t, code, "\t\tcase \"level\":\n\t\t\tvar v")
+	assert.Contains(t, code, "\t\t\tz.Level = Level(v")
+	assert.Contains(t, code, "\t\t\tz.Payload, bts, err = msgp.ReadBytesBytes(bts, z.Payload)\n")
+	assert.Contains(t, code, "\t\t\t\tbts, err = z.Points[i")
+	assert.Contains(t, code, "\t\t\t\tz.Origin = new(Point)\n")
+	assert.Contains(t, code, "\t\t\tbts, err = (*z.Origin).UnmarshalMsg(bts)\n")
+	assert.Contains(t, code, "\t\to, err = (*z.Origin).MarshalMsg(o)\n")
+	assert.Contains(t, code, "\t\t\t\treturn nil, msgp.ArrayError{Wanted: 4, Got: n")
+}
+
+func TestRenderBinaryCodec(t *testing.T) {
+	types, err := NewGenerator(WithDeepResolution()).GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: testdataPackage + "/codec", Name: "Packet"},
+		TypeSpec{PackagePath: testdataPackage + "/codec", Name: "Event"},
+		TypeSpec{PackagePath: testdataPackage + "/codec", Name: "Point"},
+	)
+	require.NoError(t, err)
+
+	// the codecs are type-checked with the fixture, so the code using the fields and the imported packages compiles.
+	code, err := RenderBinaryCodec("Packet", types[0], "codec")
+	require.NoError(t, err)
+	pointCode, err := RenderBinaryCodec("Point", types[2], "codec")
+	require.NoError(t, err)
+	require.NoError(t, typeCheckCodec(code, pointCode), code)
+
+	assert.Contains(t, code, "\tbinary.LittleEndian.PutUint32(scratch[:4], uint32(z.Seq))\n")
+	assert.Contains(t, code, "\tbinary.LittleEndian.PutUint64(scratch[:8], uint64(int(z.Level)))\n")
+	assert.Contains(t, code, "\tbinary.LittleEndian.PutUint64(scratch[:8], math.Float64bits(z.Ratio))\n")
+	assert.Contains(t, code, "\tb = append(b, z.Payload...)\n")
+	assert.Contains(t, code, ", err := (*z.Origin).MarshalBinary()\n")
+	assert.Contains(t, code, ".UnixNano()))\n")
+	assert.NotContains(t, code, "internal")
+
+	assert.Contains(t, code, "\tz.Level = Level(binary.LittleEndian.Uint64(data))\n")
+	assert.Contains(t, code, "\tz.Payload = append(z.Payload[:0], data[:l")
+	assert.Contains(t, code, "\t\tif err := (*z.Origin).UnmarshalBinary(data[:l")
+	assert.Contains(t, code, "\t\tz.Flags[i")
+	assert.Contains(t, code, "\tz.At = time.Unix(0, v")
+	assert.Contains(t, code, "\t\treturn fmt.Errorf(\"%d unexpected trailing bytes\", len(data))\n")
+
+	code, err = RenderBinaryCodec("Packet", types[0], "codec", WithVarintEncoding(), WithBigEndian())
+	require.NoError(t, err)
+	pointCode, err = RenderBinaryCodec("Point", types[2], "codec", WithVarintEncoding(), WithBigEndian())
+	require.NoError(t, err)
+	require.NoError(t, typeCheckCodec(code, pointCode), code)
+	assert.Contains(t, code, "\tb = append(b, scratch[:binary.PutUvarint(scratch[:], uint64(z.Seq))]...)\n")
+	assert.Contains(t, code, "\tb = append(b, scratch[:binary.PutVarint(scratch[:], int64(int(z.Level)))]...)\n")
+	assert.Contains(t, code, "\tbinary.BigEndian.PutUint64(scratch[:8], math.Float64bits(z.Ratio))\n")
+	assert.Contains(t, code, " := binary.Varint(data)\n")
+
+	_, err = RenderBinaryCodec("Event", types[1], "codec")
+	assert.EqualError(t, err, "cannot render encoding of field Labels: unsupported type map[string]string")
+}
+
+// codecImports maps the package names used by the rendered codecs to the paths of the packages.
+var codecImports = map[string]string{
+	"binary": "encoding/binary",
+	"fmt":    "fmt",
+	"io":     "io",
+	"math":   "math",
+	"time":   "time",
+	"msgp":   msgpPackage,
+}
+
+// msgpPackage is the path of the msgp package, whose API is stubbed by the codec fixture, see `codecImporter`.
+const msgpPackage = "github.com/tinylib/msgp/msgp"
+
+// codecImporter imports the stub of the msgp package from the codec fixture, and the other packages using `std`, so
+// the stub and the codecs share the imported packages.
+type codecImporter struct {
+	fset *token.FileSet
+	std  types.Importer
+	msgp *types.Package
+}
+
+func (i *codecImporter) Import(packagePath string) (*types.Package, error) {
+	if packagePath != msgpPackage {
+		return i.std.Import(packagePath)
+	}
+	if i.msgp != nil {
+		return i.msgp, nil
+	}
+	stub, err := parser.ParseFile(i.fset, filepath.Join("testdata", "codec", "msgp", "msgp.go"), nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	config := types.Config{Importer: i.std}
+	i.msgp, err = config.Check(msgpPackage, i.fset, []*ast.File{stub}, nil)
+	return i.msgp, err
+}
+
+// typeCheckCodec type-checks the rendered `codes` with the codec fixture, each code importing the packages it uses.
+func typeCheckCodec(codes ...string) error {
+	fset := token.NewFileSet()
+	fixture, err := parser.ParseFile(fset, filepath.Join("testdata", "codec", "codec.go"), nil, 0)
+	if err != nil {
+		return err
+	}
+	files := []*ast.File{fixture}
+	for _, code := range codes {
+		file, err := parser.ParseFile(fset, "", "package codec\n"+codeImports(code)+code, 0)
+		if err != nil {
+			return err
+		}
+		files = append(files, file)
+	}
+	config := types.Config{Importer: &codecImporter{fset: fset, std: importer.Default()}}
+	_, err = config.Check("codec", fset, files, nil)
+	return err
+}
+
+// codeImports returns the import declaration of the packages of `codecImports` used by `code`.
+func codeImports(code string) string {
+	file, err := parser.ParseFile(token.NewFileSet(), "", "package codec\n"+code, 0)
+	if err != nil {
+		return ""
+	}
+	used := make(map[string]bool)
+	ast.Inspect(file, func(node ast.Node) bool {
+		if selector, ok := node.(*ast.SelectorExpr); ok {
+			if ident, ok := selector.X.(*ast.Ident); ok && codecImports[ident.Name] != "" {
+				used[codecImports[ident.Name]] = true
+			}
+		}
+		return true
+	})
+	paths := make([]string, 0, len(used))
+	for path := range used {
+		paths = append(paths, path)
+	}
+	if len(paths) == 0 {
+		return ""
+	}
+	sort.Strings(paths)
+	return "import (\n\t\"" + strings.Join(paths, "\"\n\t\"") + "\"\n)\n"
+}
@@ -0,0 +1,59 @@
+package gotype
+
+import (
+	"fmt"
+	"strings"
+)
+
+// codeWriter accumulates the lines of a rendered Golang's source code.
+type codeWriter struct {
+	b strings.Builder
+
+	// vars is used to name the temporary variables uniquely.
+	vars int
+}
+
+// line writes a line indented by `indent` tabs. An empty `format` writes an empty line.
+func (w *codeWriter) line(indent int, format string, args ...interface{}) {
+	if format == "" {
+		w.b.WriteString("\n")
+		return
+	}
+	w.b.WriteString(strings.Repeat("\t", indent))
+	fmt.Fprintf(&w.b, format, args...)
+	w.b.WriteString("\n")
+}
+
+// newVar returns a unique name of a temporary variable starting with `prefix`.
+func (w *codeWriter) newVar(prefix string) string {
+	w.vars++
+	return fmt.Sprintf("%s%d", prefix, w.vars)
+}
+
+// operand wraps a dereference expression inside parentheses, so its method can be called.
+func operand(expr string) string {
+	if strings.HasPrefix(expr, "*") {
+		return "(" + expr + ")"
+	}
+	return expr
+}
+
+// elemType returns the type of the elements of a slice or an array.
+func elemType(typ Type) Type {
+	if typ.SliceType != nil {
+		return typ.SliceType.Elem
+	}
+	return typ.ArrayType.Elem
+}
+
+func isByteSlice(typ Type) bool {
+	if typ.SliceType == nil || typ.SliceType.Elem.PrimitiveType == nil {
+		return false
+	}
+	kind := typ.SliceType.Elem.PrimitiveType.Kind
+	return kind == PrimitiveKindByte || kind == PrimitiveKindUint8
+}
+
+func isTimeType(typ Type) bool {
+	return typ.QualType != nil && typ.QualType.Package == "time" && typ.QualType.Name == "Time"
+}
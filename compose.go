@@ -0,0 +1,217 @@
+package gotype
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// StructComposer composes a StructType from the fields of existing structs, like the request and response DTOs
+// synthesized from the domain models before rendering. Its methods return the composer, so the operations are
+// chained, and the first failed operation is returned by Build:
+//
+//	request, err := ComposeStruct().
+//		Pick(user, "Name", "Email").
+//		Merge(address).
+//		Rename("Name", "FullName").
+//		SetTag("FullName", "json", "full_name").
+//		Build()
+//
+// The fields keep their tags, so renaming a field doesn't rename its JSON name, unless it's retagged.
+type StructComposer struct {
+	fields []TypeField
+	err    error
+}
+
+// ComposeStruct returns a StructComposer of an empty struct.
+func ComposeStruct() *StructComposer {
+	return &StructComposer{fields: make([]TypeField, 0)}
+}
+
+// Merge appends all the fields of the structs. A field named like an already composed field is a conflict.
+func (c *StructComposer) Merge(structTypes ...StructType) *StructComposer {
+	for _, structType := range structTypes {
+		c.Add(structType.Fields...)
+	}
+	return c
+}
+
+// Pick appends the fields of the struct named `names`, in the order of the names.
+func (c *StructComposer) Pick(structType StructType, names ...string) *StructComposer {
+	for _, name := range names {
+		index := fieldIndex(structType.Fields, name)
+		if index < 0 {
+			c.fail(fmt.Errorf("cannot pick field %s: it isn't found", name))
+			continue
+		}
+		c.Add(structType.Fields[index])
+	}
+	return c
+}
+
+// Omit appends the fields of the struct except the ones named `names`.
+func (c *StructComposer) Omit(structType StructType, names ...string) *StructComposer {
+	omitted := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		if fieldIndex(structType.Fields, name) < 0 {
+			c.fail(fmt.Errorf("cannot omit field %s: it isn't found", name))
+		}
+		omitted[name] = struct{}{}
+	}
+	for _, field := range structType.Fields {
+		if _, ok := omitted[field.Name]; !ok {
+			c.Add(field)
+		}
+	}
+	return c
+}
+
+// Add appends the fields. A field named like an already composed field is a conflict.
+func (c *StructComposer) Add(fields ...TypeField) *StructComposer {
+	for _, field := range fields {
+		if fieldIndex(c.fields, field.Name) >= 0 {
+			c.fail(fmt.Errorf("cannot add field %s: it conflicts with the field of the same name", field.Name))
+			continue
+		}
+		c.fields = append(c.fields, field)
+	}
+	return c
+}
+
+// Remove removes the composed fields named `names`.
+func (c *StructComposer) Remove(names ...string) *StructComposer {
+	for _, name := range names {
+		index := fieldIndex(c.fields, name)
+		if index < 0 {
+			c.fail(fmt.Errorf("cannot remove field %s: it isn't found", name))
+			continue
+		}
+		c.fields = append(c.fields[:index], c.fields[index+1:]...)
+	}
+	return c
+}
+
+// Rename renames the composed field `oldName` to `newName`, keeping its position.
+func (c *StructComposer) Rename(oldName, newName string) *StructComposer {
+	index := fieldIndex(c.fields, oldName)
+	switch {
+	case index < 0:
+		c.fail(fmt.Errorf("cannot rename field %s: it isn't found", oldName))
+	case oldName != newName && fieldIndex(c.fields, newName) >= 0:
+		c.fail(fmt.Errorf("cannot rename field %s to %s: it conflicts with the field of the same name", oldName, newName))
+	default:
+		c.fields[index].Name = newName
+	}
+	return c
+}
+
+// SetType replaces the type of the composed field `name`, like a pointer type making an optional field.
+func (c *StructComposer) SetType(name string, typ Type) *StructComposer {
+	index := fieldIndex(c.fields, name)
+	if index < 0 {
+		c.fail(fmt.Errorf("cannot set type of field %s: it isn't found", name))
+		return c
+	}
+	c.fields[index].Type = typ
+	return c
+}
+
+// SetTag sets the value of the tag key `key` of the composed field `name`, like "json" and "full_name,omitempty". An
+// empty value removes the key.
+func (c *StructComposer) SetTag(name, key, value string) *StructComposer {
+	index := fieldIndex(c.fields, name)
+	if index < 0 {
+		c.fail(fmt.Errorf("cannot set tag of field %s: it isn't found", name))
+		return c
+	}
+	tag, err := setStructTag(c.fields[index].Tag, key, value)
+	if err != nil {
+		c.fail(fmt.Errorf("cannot set tag of field %s: %w", name, err))
+		return c
+	}
+	c.fields[index].Tag = tag
+	return c
+}
+
+// Retag sets the value of the tag key `key` of all the composed fields to the value returned by `fn`, which receives
+// the field with its current tag. An empty value removes the key.
+func (c *StructComposer) Retag(key string, fn func(field TypeField) string) *StructComposer {
+	for _, field := range c.fields {
+		c.SetTag(field.Name, key, fn(field))
+	}
+	return c
+}
+
+// Build returns the composed struct, or the error of the first failed operation. The fields having the same name in
+// a tag key of DefaultTagOptions, like the same JSON name, are a conflict as well.
+func (c *StructComposer) Build() (StructType, error) {
+	if c.err != nil {
+		return StructType{}, c.err
+	}
+	keys := make([]string, 0, len(DefaultTagOptions))
+	for key := range DefaultTagOptions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		names := make(map[string]string)
+		for _, field := range c.fields {
+			value, ok := field.Tag.Lookup(key)
+			name := strings.Split(value, ",")[0]
+			if value == "-" {
+				continue
+			}
+			if !ok || name == "" {
+				name = field.Name
+			}
+			if previous, ok := names[name]; ok {
+				return StructType{}, fmt.Errorf("%s name %q of field %s conflicts with field %s", key, name, field.Name,
+					previous)
+			}
+			names[name] = field.Name
+		}
+	}
+	return StructType{Fields: append([]TypeField(nil), c.fields...)}, nil
+}
+
+func (c *StructComposer) fail(err error) {
+	if c.err == nil {
+		c.err = err
+	}
+}
+
+// fieldIndex returns the index of the field named `name`, or -1.
+func fieldIndex(fields []TypeField, name string) int {
+	for n, field := range fields {
+		if field.Name == name {
+			return n
+		}
+	}
+	return -1
+}
+
+// setStructTag sets the value of the tag key `key`, keeping the order of the other keys. An empty value removes the
+// key.
+func setStructTag(tag reflect.StructTag, key, value string) (reflect.StructTag, error) {
+	pairs, err := parseStructTag(string(tag))
+	if err != nil {
+		return "", err
+	}
+	found := false
+	parts := make([]string, 0, len(pairs)+1)
+	for _, pair := range pairs {
+		if pair.key == key {
+			found = true
+			pair.value = value
+		}
+		if pair.value != "" || pair.key != key {
+			parts = append(parts, pair.key+":"+strconv.Quote(pair.value))
+		}
+	}
+	if !found && value != "" {
+		parts = append(parts, key+":"+strconv.Quote(value))
+	}
+	return reflect.StructTag(strings.Join(parts, " ")), nil
+}
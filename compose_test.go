@@ -0,0 +1,80 @@
+package gotype
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructComposer(t *testing.T) {
+	stringType := Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindString}}
+	user := StructType{Fields: []TypeField{
+		{Name: "ID", Type: Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindInt64}}, Tag: `json:"id" db:"id"`},
+		{Name: "Name", Type: stringType, Tag: `json:"name"`},
+		{Name: "Email", Type: stringType, Tag: `json:"email" validate:"email"`},
+		{Name: "Password", Type: stringType, Tag: `json:"-"`},
+	}}
+	address := StructType{Fields: []TypeField{
+		{Name: "City", Type: stringType, Tag: `json:"city"`},
+		{Name: "Zip", Type: stringType},
+	}}
+
+	composed, err := ComposeStruct().
+		Pick(user, "Email", "Name").
+		Merge(address).
+		Rename("Name", "FullName").
+		SetTag("FullName", "json", "full_name,omitempty").
+		SetTag("Email", "validate", "").
+		SetType("City", Type{PtrType: &PtrType{Elem: stringType}}).
+		Remove("Zip").
+		Retag("xml", func(field TypeField) string {
+			return strings.ToLower(field.Name)
+		}).
+		Build()
+	require.NoError(t, err)
+	assert.Equal(t, "struct {\n"+
+		"    Email string `json:\"email\" xml:\"email\"`\n"+
+		"    FullName string `json:\"full_name,omitempty\" xml:\"fullname\"`\n"+
+		"    City *string `json:\"city\" xml:\"city\"`\n"+
+		"}", Type{StructType: &composed}.String(""))
+	// the composed struct doesn't share its fields with the source structs.
+	assert.Equal(t, "Name", user.Fields[1].Name)
+	assert.Equal(t, reflect.StructTag(`json:"email" validate:"email"`), user.Fields[2].Tag)
+
+	composed, err = ComposeStruct().Omit(user, "Password").Add(address.Fields[1]).Build()
+	require.NoError(t, err)
+	assert.Len(t, composed.Fields, 4)
+	assert.Equal(t, "Zip", composed.Fields[3].Name)
+}
+
+func TestStructComposer_Conflicts(t *testing.T) {
+	stringType := Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindString}}
+	user := StructType{Fields: []TypeField{
+		{Name: "ID", Type: stringType, Tag: `json:"id"`},
+		{Name: "Name", Type: stringType},
+	}}
+	order := StructType{Fields: []TypeField{
+		{Name: "ID", Type: stringType, Tag: `json:"id"`},
+		{Name: "UserID", Type: stringType, Tag: `json:"id"`},
+	}}
+
+	for name, composer := range map[string]*StructComposer{
+		"cannot add field ID: it conflicts with the field of the same name": ComposeStruct().Merge(user, order),
+		"cannot pick field Email: it isn't found":                           ComposeStruct().Pick(user, "ID", "Email"),
+		"cannot omit field Email: it isn't found":                           ComposeStruct().Omit(user, "Email"),
+		"cannot remove field Email: it isn't found":                         ComposeStruct().Merge(user).Remove("Email"),
+		"cannot rename field ID to Name: it conflicts with the field of the same name": ComposeStruct().
+			Merge(user).Rename("ID", "Name"),
+		`cannot set tag of field ID: struct tag key "json" is repeated`: ComposeStruct().
+			Add(TypeField{Name: "ID", Tag: `json:"a" json:"b"`}).SetTag("ID", "xml", "id"),
+		`json name "id" of field UserID conflicts with field ID`: ComposeStruct().Merge(order),
+		`json name "Name" of field FullName conflicts with field Name`: ComposeStruct().Merge(user).
+			Add(TypeField{Name: "FullName", Type: stringType, Tag: `json:"Name"`}),
+	} {
+		_, err := composer.Build()
+		assert.EqualError(t, err, name)
+	}
+}
@@ -0,0 +1,60 @@
+package gotype
+
+import (
+	"sync"
+)
+
+// generatePackagesConcurrently generates the types of each package like generateTypesInSinglePackage, using the
+// configured number of goroutines, see `WithConcurrency`. The results and the first error follow the order of
+// `packagePaths`, so they don't depend on the scheduling.
+func (f *astTypeGenerator) generatePackagesConcurrently(
+	packagePaths []string,
+	packagePathToSpecs map[string][]string,
+) ([][]Type, error) {
+	workers := f.config.concurrency
+	if workers > len(packagePaths) {
+		workers = len(packagePaths)
+	}
+
+	results := make([][]Type, len(packagePaths))
+	errs := make([]error, len(packagePaths))
+	forks := make([]*astTypeGenerator, workers)
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := range forks {
+		forks[w] = f.fork()
+		forks[w].pins = f.pins
+		wg.Add(1)
+		go func(fork *astTypeGenerator) {
+			defer wg.Done()
+			for i := range indices {
+				results[i], errs[i] = fork.generateTypesInSinglePackage(
+					packagePaths[i],
+					packagePathToSpecs[packagePaths[i]]...,
+				)
+			}
+		}(forks[w])
+	}
+	for i := range packagePaths {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the declarations found by the forks are used by the deep resolution.
+	for _, fork := range forks {
+		for key, link := range fork.declarations {
+			if f.declarations == nil {
+				f.declarations = make(map[string]DeclarationLink)
+			}
+			f.declarations[key] = link
+		}
+	}
+	return results, nil
+}
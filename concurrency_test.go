@@ -0,0 +1,44 @@
+package gotype
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrentGeneration(t *testing.T) {
+	specs := []TypeSpec{
+		{PackagePath: testdataPackage + "/usage", Name: "Order"},
+		{PackagePath: testdataPackage + "/usage/sub", Name: "Reader"},
+		{PackagePath: testdataPackage + "/chain", Name: "Holder"},
+		{PackagePath: testdataPackage + "/metrics", Name: "Order"},
+	}
+	expected, err := NewGenerator(WithDeepResolution()).GenerateTypesFromSpecs(specs...)
+	require.NoError(t, err)
+
+	trace := NewTrace()
+	generator := NewGenerator(WithDeepResolution(), WithConcurrency(3), WithTrace(trace))
+	var wg sync.WaitGroup
+	results := make([][]Type, 8)
+	errs := make([]error, len(results))
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = generator.GenerateTypesFromSpecs(specs...)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range results {
+		require.NoError(t, errs[i])
+		assert.Equal(t, expected, results[i])
+	}
+	assert.NotEmpty(t, trace.Steps(specs[0]))
+
+	_, err = generator.GenerateTypesFromSpecs(specs[0], TypeSpec{PackagePath: testdataPackage + "/chain", Name: "Missing"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot find definition of Missing")
+}
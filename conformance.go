@@ -0,0 +1,169 @@
+package gotype
+
+import (
+	"fmt"
+)
+
+// WellKnownInterface represents a well-known interface of the Golang's standard library.
+type WellKnownInterface struct {
+	// QualType identifies the interface. The predeclared `error` interface has an empty package path.
+	QualType QualType
+
+	// Interface contains the methods of the interface.
+	Interface InterfaceType
+}
+
+// WellKnownInterfaces contains the interfaces checked by `TypeGenerator.WellKnownInterfacesOf`.
+var WellKnownInterfaces = []WellKnownInterface{
+	{
+		QualType:  QualType{Package: "fmt", ShortPackagePath: "fmt", Name: "Stringer"},
+		Interface: wellKnownInterface(wellKnownMethod("String", nil, []Type{primitive(PrimitiveKindString)})),
+	},
+	{
+		QualType:  QualType{Name: "error"},
+		Interface: wellKnownInterface(wellKnownMethod("Error", nil, []Type{primitive(PrimitiveKindString)})),
+	},
+	{
+		QualType: QualType{Package: "io", ShortPackagePath: "io", Name: "Reader"},
+		Interface: wellKnownInterface(wellKnownMethod(
+			"Read",
+			[]Type{byteSlice()},
+			[]Type{primitive(PrimitiveKindInt), primitive(PrimitiveKindError)},
+		)),
+	},
+	{
+		QualType: QualType{Package: "io", ShortPackagePath: "io", Name: "Writer"},
+		Interface: wellKnownInterface(wellKnownMethod(
+			"Write",
+			[]Type{byteSlice()},
+			[]Type{primitive(PrimitiveKindInt), primitive(PrimitiveKindError)},
+		)),
+	},
+	{
+		QualType:  QualType{Package: "io", ShortPackagePath: "io", Name: "Closer"},
+		Interface: wellKnownInterface(wellKnownMethod("Close", nil, []Type{primitive(PrimitiveKindError)})),
+	},
+	{
+		QualType: QualType{Package: "encoding", ShortPackagePath: "encoding", Name: "TextMarshaler"},
+		Interface: wellKnownInterface(
+			wellKnownMethod("MarshalText", nil, []Type{byteSlice(), primitive(PrimitiveKindError)}),
+		),
+	},
+	{
+		QualType: QualType{Package: "encoding", ShortPackagePath: "encoding", Name: "TextUnmarshaler"},
+		Interface: wellKnownInterface(
+			wellKnownMethod("UnmarshalText", []Type{byteSlice()}, []Type{primitive(PrimitiveKindError)}),
+		),
+	},
+	{
+		QualType: QualType{Package: "encoding/json", ShortPackagePath: "json", Name: "Marshaler"},
+		Interface: wellKnownInterface(
+			wellKnownMethod("MarshalJSON", nil, []Type{byteSlice(), primitive(PrimitiveKindError)}),
+		),
+	},
+	{
+		QualType: QualType{Package: "encoding/json", ShortPackagePath: "json", Name: "Unmarshaler"},
+		Interface: wellKnownInterface(
+			wellKnownMethod("UnmarshalJSON", []Type{byteSlice()}, []Type{primitive(PrimitiveKindError)}),
+		),
+	},
+	{
+		QualType: QualType{Package: "sort", ShortPackagePath: "sort", Name: "Interface"},
+		Interface: wellKnownInterface(
+			wellKnownMethod("Len", nil, []Type{primitive(PrimitiveKindInt)}),
+			wellKnownMethod(
+				"Less",
+				[]Type{primitive(PrimitiveKindInt), primitive(PrimitiveKindInt)},
+				[]Type{primitive(PrimitiveKindBool)},
+			),
+			wellKnownMethod("Swap", []Type{primitive(PrimitiveKindInt), primitive(PrimitiveKindInt)}, nil),
+		),
+	},
+}
+
+// ConformanceReport contains the well-known interfaces implemented by a named type.
+type ConformanceReport struct {
+	// Value contains the interfaces implemented by the type itself.
+	Value []QualType
+
+	// Pointer contains the interfaces implemented by the pointer to the type. Since the method set of a pointer
+	// includes the methods declared with value receivers, Pointer is a superset of Value.
+	Pointer []QualType
+}
+
+// Implements reports whether a type having the `methodSet` implements `iface`.
+func Implements(methodSet []InterfaceTypeMethod, iface InterfaceType) bool {
+	for _, method := range iface.Methods {
+		found := false
+		for _, m := range methodSet {
+			if m.Name == method.Name {
+				found = identicalFunc(m.Func, method.Func)
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// WellKnownInterfacesOf reports which of the `WellKnownInterfaces` are implemented by the named type specified by
+// `typeSpec`. See `TypeGenerator.WellKnownInterfacesOf` for the details.
+func WellKnownInterfacesOf(typeSpec TypeSpec) (ConformanceReport, error) {
+	return defaultAstTypeGenerator.WellKnownInterfacesOf(typeSpec)
+}
+
+func (f *astTypeGenerator) WellKnownInterfacesOf(typeSpec TypeSpec) (ConformanceReport, error) {
+	f = f.fork()
+	types, err := f.GenerateTypesFromSpecs(typeSpec)
+	if err != nil {
+		return ConformanceReport{}, err
+	}
+
+	var valueMethods, pointerMethods []InterfaceTypeMethod
+	if types[0].InterfaceType != nil {
+		valueMethods = types[0].InterfaceType.Methods
+	} else {
+		methods, err := f.generateMethods(typeSpec.PackagePath, typeSpec.Name)
+		if err != nil {
+			return ConformanceReport{}, err
+		}
+		valueMethods = methodSet(methods, false)
+		pointerMethods = methodSet(methods, true)
+	}
+
+	report := ConformanceReport{}
+	for _, wellKnown := range WellKnownInterfaces {
+		if Implements(valueMethods, wellKnown.Interface) {
+			report.Value = append(report.Value, wellKnown.QualType)
+		}
+		if pointerMethods != nil && Implements(pointerMethods, wellKnown.Interface) {
+			report.Pointer = append(report.Pointer, wellKnown.QualType)
+		}
+	}
+	return report, nil
+}
+
+func wellKnownInterface(methods ...InterfaceTypeMethod) InterfaceType {
+	return InterfaceType{Methods: methods}
+}
+
+func wellKnownMethod(name string, inputs, outputs []Type) InterfaceTypeMethod {
+	funcType := FuncType{}
+	for i, input := range inputs {
+		funcType.Inputs = append(funcType.Inputs, TypeField{Name: fmt.Sprintf("arg%d", i+1), Type: input})
+	}
+	for i, output := range outputs {
+		funcType.Outputs = append(funcType.Outputs, TypeField{Name: fmt.Sprintf("out%d", i+1), Type: output})
+	}
+	return InterfaceTypeMethod{Name: name, Func: funcType}
+}
+
+func primitive(kind PrimitiveKind) Type {
+	return PrimitiveType{Kind: kind}.Type()
+}
+
+func byteSlice() Type {
+	return SliceType{Elem: primitive(PrimitiveKindByte)}.Type()
+}
@@ -0,0 +1,661 @@
+package gotype
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// errNotConstant is returned when an expression isn't a constant expression supported by the constEvaluator.
+var errNotConstant = errors.New("not a constant expression")
+
+// EvaluateConst evaluates the constant named `name` declared inside the package. See `TypeGenerator.EvaluateConst` for
+// the details.
+func EvaluateConst(packagePath, name string) (constant.Value, error) {
+	return defaultAstTypeGenerator.EvaluateConst(packagePath, name)
+}
+
+// EvaluateConstExpr evaluates the constant expression `expr` inside the scope of the package. See
+// `TypeGenerator.EvaluateConstExpr` for the details.
+func EvaluateConstExpr(packagePath, expr string) (constant.Value, error) {
+	return defaultAstTypeGenerator.EvaluateConstExpr(packagePath, expr)
+}
+
+func (f *astTypeGenerator) EvaluateConst(packagePath, name string) (constant.Value, error) {
+	f = f.fork()
+	return f.constEvaluator().evaluateConst(packagePath, name)
+}
+
+func (f *astTypeGenerator) EvaluateConstExpr(packagePath, expr string) (constant.Value, error) {
+	f = f.fork()
+	e := f.constEvaluator()
+	consts, err := e.packageConsts(packagePath)
+	if err != nil {
+		return nil, err
+	}
+	node, err := parser.ParseExpr(expr)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse constant expression %s: %w", expr, err)
+	}
+	return e.evaluate(node, constScope{packagePath: packagePath, importMap: consts.importMap})
+}
+
+// constDecl is the declaration of a constant, whose value is the expression `value` evaluated inside `scope` and
+// converted to the type `typ`, if any.
+type constDecl struct {
+	typ   ast.Expr
+	value ast.Expr
+	scope constScope
+}
+
+// constScope is the scope evaluating a constant expression.
+type constScope struct {
+	packagePath string
+	importMap   map[string]string
+
+	// iota contains the index of the constant's specification inside its declaration group.
+	iota int
+}
+
+// packageConsts contains the constants declared inside a package.
+type packageConsts struct {
+	decls map[string]constDecl
+
+	// importMap contains the imports of all the files of the package, used to evaluate the expressions which don't
+	// belong to a file.
+	importMap map[string]string
+}
+
+// constEvaluator evaluates the constant expressions, like the integers, the strings, iota, the shifts, and the
+// references to the constants of the same package and of the imported packages. The values are computed exactly,
+// without the overflow checks of the compiler.
+type constEvaluator struct {
+	generator *astTypeGenerator
+
+	// packages caches the constants declared inside each package.
+	packages map[string]packageConsts
+
+	// values caches the value of each constant, keyed like the QualTypes.
+	values map[string]constant.Value
+
+	// evaluating contains the keys of the constants being evaluated, used to detect the cycles.
+	evaluating map[string]struct{}
+
+	// exports caches the packages imported from their export data, see `evaluateImportedConst`.
+	exports map[string]exportedPackage
+}
+
+// exportedPackage is a package imported from its export data, or the error importing it.
+type exportedPackage struct {
+	pkg *types.Package
+	err error
+}
+
+// constEvaluator returns the constEvaluator of the call, creating it on the first use.
+func (f *astTypeGenerator) constEvaluator() *constEvaluator {
+	if f.constants == nil {
+		f.constants = &constEvaluator{
+			generator:  f,
+			packages:   make(map[string]packageConsts),
+			values:     make(map[string]constant.Value),
+			evaluating: make(map[string]struct{}),
+			exports:    make(map[string]exportedPackage),
+		}
+	}
+	return f.constants
+}
+
+// evaluateConst evaluates the constant named `name` declared inside the package.
+func (e *constEvaluator) evaluateConst(packagePath, name string) (constant.Value, error) {
+	key := qualTypeKey(QualType{Package: packagePath, Name: name})
+	if value, ok := e.values[key]; ok {
+		return value, nil
+	}
+	if _, ok := e.evaluating[key]; ok {
+		return nil, fmt.Errorf("constant %s refers to itself", key)
+	}
+
+	consts, err := e.packageConsts(packagePath)
+	if err != nil {
+		return nil, err
+	}
+	decl, ok := consts.decls[name]
+	if !ok {
+		return nil, fmt.Errorf("cannot find const %s in package %s: %w", name, packagePath, ErrDeclarationNotFound)
+	}
+
+	e.evaluating[key] = struct{}{}
+	defer delete(e.evaluating, key)
+	value, err := e.evaluate(decl.value, decl.scope)
+	if err != nil {
+		return nil, fmt.Errorf("cannot evaluate const %s: %w", key, err)
+	}
+	value = e.convertConst(decl.typ, value, decl.scope)
+	e.values[key] = value
+	return value, nil
+}
+
+// evaluateImportedConst evaluates the constant named `name` declared inside an imported package. When the constant
+// can't be evaluated from the sources, like a constant calling a built-in function which isn't supported, or when the
+// sources of the package can't be found, its value is read from the export data of the package, built by the go tool.
+func (e *constEvaluator) evaluateImportedConst(packagePath, name string) (constant.Value, error) {
+	value, err := e.evaluateConst(packagePath, name)
+	if err == nil || errors.Is(err, ErrDeclarationNotFound) {
+		return value, err
+	}
+
+	pkg, exportErr := e.exportedPackage(packagePath)
+	if exportErr != nil {
+		return nil, err
+	}
+	obj, ok := pkg.Scope().Lookup(name).(*types.Const)
+	if !ok {
+		return nil, err
+	}
+	e.values[qualTypeKey(QualType{Package: packagePath, Name: name})] = obj.Val()
+	return obj.Val(), nil
+}
+
+// exportedPackage returns the package imported from the export data listed by `go list -export`, run inside the
+// directory and the environment of the generator's Config.
+func (e *constEvaluator) exportedPackage(packagePath string) (*types.Package, error) {
+	if exported, ok := e.exports[packagePath]; ok {
+		return exported.pkg, exported.err
+	}
+	pkg, err := e.importExportData(packagePath)
+	e.exports[packagePath] = exportedPackage{pkg: pkg, err: err}
+	return pkg, err
+}
+
+func (e *constEvaluator) importExportData(packagePath string) (*types.Package, error) {
+	buildConfig := e.generator.config.buildConfig
+	dir, err := buildConfig.workingDir()
+	if err != nil {
+		return nil, fmt.Errorf("cannot list export data of package %s: %w", packagePath, err)
+	}
+	args := append([]string{"list", "-export", "-f", "{{.Export}}"}, buildConfig.goFlags()...)
+	cmd := exec.Command("go", append(args, "--", packagePath)...)
+	cmd.Dir = dir
+	cmd.Env = buildConfig.environ()
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("cannot list export data of package %s: %w", packagePath, err)
+	}
+	exportFile := strings.TrimSpace(string(output))
+	if exportFile == "" {
+		return nil, fmt.Errorf("cannot list export data of package %s: no export data", packagePath)
+	}
+
+	// the export data contains the declarations of the dependencies used by the package, so only the package itself is
+	// looked up.
+	imp := importer.ForCompiler(token.NewFileSet(), "gc", func(path string) (io.ReadCloser, error) {
+		if path != packagePath {
+			return nil, fmt.Errorf("unexpected import of package %s", path)
+		}
+		return os.Open(exportFile)
+	})
+	pkg, err := imp.Import(packagePath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot import export data of package %s: %w", packagePath, err)
+	}
+	return pkg, nil
+}
+
+// packageConsts returns the constants declared inside the package, skipping the test files.
+func (e *constEvaluator) packageConsts(packagePath string) (packageConsts, error) {
+	if consts, ok := e.packages[packagePath]; ok {
+		return consts, nil
+	}
+
+	goSources, err := e.generator.getPackageSourceFiles(packagePath)
+	if err != nil {
+		return packageConsts{}, err
+	}
+
+	consts := packageConsts{decls: make(map[string]constDecl), importMap: make(map[string]string)}
+	for _, source := range goSources {
+		if strings.HasSuffix(source, "_test.go") {
+			continue
+		}
+		fileAst, err := e.generator.parseAstFile(source)
+		if err != nil {
+			return packageConsts{}, err
+		}
+
+		importMap := e.generator.generateImportMap(packagePath, fileAst)
+		for name, importPath := range importMap {
+			if _, ok := consts.importMap[name]; !ok {
+				consts.importMap[name] = importPath
+			}
+		}
+
+		for _, decl := range fileAst.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.CONST {
+				continue
+			}
+
+			// the constants declared without a value repeat the type and the values of the previous constant of the group.
+			var typ ast.Expr
+			var values []ast.Expr
+			for i, spec := range genDecl.Specs {
+				valueSpec := spec.(*ast.ValueSpec)
+				if valueSpec.Type != nil || len(valueSpec.Values) > 0 {
+					typ, values = valueSpec.Type, valueSpec.Values
+				}
+				for j, name := range valueSpec.Names {
+					if j >= len(values) || name.Name == "_" {
+						continue
+					}
+					scope := constScope{packagePath: packagePath, importMap: importMap, iota: i}
+					consts.decls[name.Name] = constDecl{typ: typ, value: values[j], scope: scope}
+				}
+			}
+		}
+	}
+	e.packages[packagePath] = consts
+	return consts, nil
+}
+
+// evaluate evaluates the constant expression `expr` inside `scope`.
+func (e *constEvaluator) evaluate(expr ast.Expr, scope constScope) (constant.Value, error) {
+	switch expr := expr.(type) {
+	case *ast.BasicLit:
+		value := constant.MakeFromLiteral(expr.Value, expr.Kind, 0)
+		if value.Kind() == constant.Unknown {
+			return nil, fmt.Errorf("invalid literal %s", expr.Value)
+		}
+		return value, nil
+	case *ast.Ident:
+		switch expr.Name {
+		case "iota":
+			return constant.MakeInt64(int64(scope.iota)), nil
+		case "true", "false":
+			return constant.MakeBool(expr.Name == "true"), nil
+		}
+		return e.evaluateConst(scope.packagePath, expr.Name)
+	case *ast.SelectorExpr:
+		if x, ok := expr.X.(*ast.Ident); ok {
+			if importPath, ok := scope.importMap[x.Name]; ok {
+				return e.evaluateImportedConst(importPath, expr.Sel.Name)
+			}
+		}
+	case *ast.ParenExpr:
+		return e.evaluate(expr.X, scope)
+	case *ast.UnaryExpr:
+		x, err := e.evaluate(expr.X, scope)
+		if err != nil {
+			return nil, err
+		}
+		var prec uint
+		if expr.Op == token.XOR {
+			prec = e.unsignedBits(expr.X, scope)
+		}
+		return e.unaryOp(expr.Op, x, prec)
+	case *ast.BinaryExpr:
+		x, err := e.evaluate(expr.X, scope)
+		if err != nil {
+			return nil, err
+		}
+		y, err := e.evaluate(expr.Y, scope)
+		if err != nil {
+			return nil, err
+		}
+		return e.binaryOp(expr.Op, x, y)
+	case *ast.CallExpr:
+		return e.evaluateCall(expr, scope)
+	}
+	return nil, fmt.Errorf("%s: %w", types.ExprString(expr), errNotConstant)
+}
+
+// evaluateCall evaluates the conversions of the constants, like `float64(1)` or `time.Duration(5)`, the `len` of the
+// constant strings, the `len` and `cap` of the arrays, see `evaluateArrayLen`, and `unsafe.Sizeof` and
+// `unsafe.Alignof`, see `evaluateUnsafeCall`. The calls of the other built-in functions and of `unsafe.Offsetof` aren't
+// supported.
+func (e *constEvaluator) evaluateCall(call *ast.CallExpr, scope constScope) (constant.Value, error) {
+	notConstant := fmt.Errorf("%s: %w", types.ExprString(call), errNotConstant)
+	if len(call.Args) != 1 || call.Ellipsis.IsValid() {
+		return nil, notConstant
+	}
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		switch fun.Name {
+		case "cap":
+			return e.evaluateArrayLen(call, scope)
+		case "complex", "imag", "max", "min", "new", "real":
+			return nil, notConstant
+		}
+	case *ast.SelectorExpr:
+		if x, ok := fun.X.(*ast.Ident); ok && scope.importMap[x.Name] == "unsafe" {
+			return e.evaluateUnsafeCall(call, scope)
+		}
+	}
+
+	arg, err := e.evaluate(call.Args[0], scope)
+	if ident, ok := call.Fun.(*ast.Ident); ok && ident.Name == "len" {
+		if err != nil {
+			// the length of an array is constant, even though the array isn't.
+			if value, arrayErr := e.evaluateArrayLen(call, scope); arrayErr == nil {
+				return value, nil
+			}
+			return nil, err
+		}
+		if arg.Kind() != constant.String {
+			return nil, notConstant
+		}
+		return constant.MakeInt64(int64(len(constant.StringVal(arg)))), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return e.convertConst(call.Fun, arg, scope), nil
+}
+
+// evaluateArrayLen evaluates `len` and `cap` of an array, or of a pointer to an array, whose type is inferred like the
+// argument of `unsafe.Sizeof`.
+func (e *constEvaluator) evaluateArrayLen(call *ast.CallExpr, scope constScope) (constant.Value, error) {
+	typ, err := e.argumentType(call.Args[0], scope)
+	if err == nil {
+		typ, err = newDeepResolver(e.generator).resolve(typ)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v: %w", types.ExprString(call), err, errNotConstant)
+	}
+
+	for typ.QualType != nil && typ.QualType.Underlying != nil {
+		typ = *typ.QualType.Underlying
+	}
+	if typ.PtrType != nil {
+		typ = typ.PtrType.Elem
+		for typ.QualType != nil && typ.QualType.Underlying != nil {
+			typ = *typ.QualType.Underlying
+		}
+	}
+	if typ.ArrayType == nil {
+		return nil, fmt.Errorf("%s: %w", types.ExprString(call), errNotConstant)
+	}
+	return constant.MakeInt64(int64(typ.ArrayType.Len)), nil
+}
+
+// evaluateUnsafeCall evaluates `unsafe.Sizeof` and `unsafe.Alignof` using the layout of the gc compiler on the target
+// architecture, see `Config`, or on the architecture of the process without a Config. The type of the argument is
+// inferred syntactically: it's either a conversion, like `uint64(0)`, a package-level variable declared with its
+// type, or an expression whose type is inferred like the one of a variable, like a composite literal.
+func (e *constEvaluator) evaluateUnsafeCall(call *ast.CallExpr, scope constScope) (constant.Value, error) {
+	name := call.Fun.(*ast.SelectorExpr).Sel.Name
+	if name != "Sizeof" && name != "Alignof" {
+		return nil, fmt.Errorf("%s: %w", types.ExprString(call), errNotConstant)
+	}
+
+	typ, err := e.argumentType(call.Args[0], scope)
+	if err == nil && isEmptyType(typ) {
+		err = errors.New("cannot infer the type of the argument")
+	}
+	if err == nil {
+		// the sizes of the named types are computed from their definitions.
+		typ, err = newDeepResolver(e.generator).resolve(typ)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v: %w", types.ExprString(call), err, errNotConstant)
+	}
+
+	buildConfig := e.generator.config.buildConfig
+	if buildConfig == nil {
+		buildConfig = &Config{}
+	}
+	layout := layout64
+	if buildConfig.wordBits() == 32 {
+		layout = layout32
+	}
+	var value int64
+	if name == "Sizeof" {
+		value, err = layout.sizeof(typ)
+	} else {
+		value, err = layout.alignof(typ)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v: %w", types.ExprString(call), err, errNotConstant)
+	}
+	return constant.MakeInt64(value), nil
+}
+
+// argumentType returns the type of the argument `arg` of a function of the unsafe package, see `evaluateUnsafeCall`.
+func (e *constEvaluator) argumentType(arg ast.Expr, scope constScope) (Type, error) {
+	f := e.generator
+	switch arg := arg.(type) {
+	case *ast.CallExpr:
+		if len(arg.Args) == 1 {
+			return f.generateTypeFromExpr(arg.Fun, scope.packagePath, scope.importMap)
+		}
+	case *ast.Ident:
+		spec := Spec{PackagePath: scope.packagePath, Name: arg.Name, Kind: SpecKindVar}
+		declarations, err := f.generateDeclarationsInSinglePackage([]Spec{spec})
+		if err != nil {
+			return Type{}, err
+		}
+		return declarations[spec].Type, nil
+	}
+	return f.generateValueType(nil, arg, scope.packagePath, scope.importMap)
+}
+
+// convertConst converts `value` to the numeric type `typ`, like `float64` or a type defined as `float64`, so the
+// following operations don't truncate it. The values converted to the other types are returned unchanged.
+func (e *constEvaluator) convertConst(typ ast.Expr, value constant.Value, scope constScope) constant.Value {
+	if typ == nil || !isNumeric(value) {
+		return value
+	}
+	kind, ok := e.underlyingKind(typ, scope)
+	switch {
+	case !ok:
+		return value
+	case kind.IsFloat():
+		return constant.ToFloat(value)
+	case kind.IsComplex():
+		return constant.ToComplex(value)
+	case kind.IsInteger():
+		return constant.ToInt(value)
+	}
+	return value
+}
+
+// underlyingKind returns the kind of the predeclared type underlying the type `typ`, like `float64` for
+// `type Celsius float64`.
+func (e *constEvaluator) underlyingKind(typ ast.Expr, scope constScope) (PrimitiveKind, bool) {
+	t, err := e.generator.generateTypeFromExpr(typ, scope.packagePath, scope.importMap)
+	if err == nil && t.QualType != nil {
+		t, err = newDeepResolver(e.generator).resolve(t)
+	}
+	if err != nil {
+		return "", false
+	}
+	for t.QualType != nil && t.QualType.Underlying != nil {
+		t = *t.QualType.Underlying
+	}
+	if t.PrimitiveType == nil {
+		return "", false
+	}
+	return t.PrimitiveType.Kind, true
+}
+
+// constKind returns the kind of the predeclared type underlying the type of the constant expression `expr`. It
+// returns false for the untyped expressions, like `1 << 3`.
+func (e *constEvaluator) constKind(expr ast.Expr, scope constScope) (PrimitiveKind, bool) {
+	switch expr := expr.(type) {
+	case *ast.ParenExpr:
+		return e.constKind(expr.X, scope)
+	case *ast.UnaryExpr:
+		return e.constKind(expr.X, scope)
+	case *ast.BinaryExpr:
+		switch expr.Op {
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+			return "", false
+		case token.SHL, token.SHR:
+			return e.constKind(expr.X, scope)
+		}
+		if kind, ok := e.constKind(expr.X, scope); ok {
+			return kind, true
+		}
+		return e.constKind(expr.Y, scope)
+	case *ast.CallExpr:
+		switch fun := expr.Fun.(type) {
+		case *ast.Ident:
+			if fun.Name == "len" || fun.Name == "cap" {
+				return PrimitiveKindInt, true
+			}
+		case *ast.SelectorExpr:
+			if x, ok := fun.X.(*ast.Ident); ok && scope.importMap[x.Name] == "unsafe" {
+				return PrimitiveKindUintptr, true
+			}
+		}
+		return e.underlyingKind(expr.Fun, scope)
+	case *ast.Ident:
+		return e.declKind(scope.packagePath, expr.Name)
+	case *ast.SelectorExpr:
+		if x, ok := expr.X.(*ast.Ident); ok {
+			if importPath, ok := scope.importMap[x.Name]; ok {
+				return e.declKind(importPath, expr.Sel.Name)
+			}
+		}
+	}
+	return "", false
+}
+
+// declKind returns the kind of the predeclared type underlying the type of the constant named `name` declared inside
+// the package, see `constKind`.
+func (e *constEvaluator) declKind(packagePath, name string) (PrimitiveKind, bool) {
+	consts, err := e.packageConsts(packagePath)
+	if err != nil {
+		return "", false
+	}
+	decl, ok := consts.decls[name]
+	if !ok {
+		return "", false
+	}
+	if decl.typ != nil {
+		return e.underlyingKind(decl.typ, decl.scope)
+	}
+	return e.constKind(decl.value, decl.scope)
+}
+
+// unsignedBits returns the size in bits of the unsigned type of the constant expression `expr`, which is the
+// precision of the bitwise complement of its value, or zero when its type isn't unsigned.
+func (e *constEvaluator) unsignedBits(expr ast.Expr, scope constScope) uint {
+	kind, ok := e.constKind(expr, scope)
+	if !ok {
+		return 0
+	}
+	switch kind {
+	case PrimitiveKindUint8, PrimitiveKindByte:
+		return 8
+	case PrimitiveKindUint16:
+		return 16
+	case PrimitiveKindUint32:
+		return 32
+	case PrimitiveKindUint64:
+		return 64
+	case PrimitiveKindUint, PrimitiveKindUintptr:
+		buildConfig := e.generator.config.buildConfig
+		if buildConfig == nil {
+			buildConfig = &Config{}
+		}
+		return uint(buildConfig.wordBits())
+	}
+	return 0
+}
+
+// unaryOp applies the unary operator to `x`. The bitwise complement of an unsigned value uses the precision `prec`,
+// see `constant.UnaryOp`.
+func (*constEvaluator) unaryOp(op token.Token, x constant.Value, prec uint) (constant.Value, error) {
+	switch {
+	case op == token.NOT && x.Kind() == constant.Bool:
+	case (op == token.ADD || op == token.SUB) && isNumeric(x):
+	case op == token.XOR && x.Kind() == constant.Int:
+	default:
+		return nil, fmt.Errorf("invalid operation %s%s", op, x)
+	}
+	return constant.UnaryOp(op, x, prec), nil
+}
+
+func (*constEvaluator) binaryOp(op token.Token, x, y constant.Value) (constant.Value, error) {
+	switch op {
+	case token.SHL, token.SHR:
+		s, ok := constant.Uint64Val(constant.ToInt(y))
+		if x = constant.ToInt(x); !ok || x.Kind() != constant.Int {
+			return nil, fmt.Errorf("invalid shift %s %s %s", x, op, y)
+		}
+		return constant.Shift(x, op, uint(s)), nil
+	case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+		if !comparableConsts(x, y) {
+			return nil, fmt.Errorf("invalid comparison %s %s %s", x, op, y)
+		}
+		return constant.MakeBool(constant.Compare(x, op, y)), nil
+	case token.LAND, token.LOR:
+		if x.Kind() != constant.Bool || y.Kind() != constant.Bool {
+			return nil, fmt.Errorf("invalid operation %s %s %s", x, op, y)
+		}
+	case token.ADD:
+		if !(x.Kind() == constant.String && y.Kind() == constant.String) && !(isNumeric(x) && isNumeric(y)) {
+			return nil, fmt.Errorf("invalid operation %s %s %s", x, op, y)
+		}
+	case token.SUB, token.MUL, token.QUO:
+		if !isNumeric(x) || !isNumeric(y) {
+			return nil, fmt.Errorf("invalid operation %s %s %s", x, op, y)
+		}
+		if op == token.QUO && constant.Sign(y) == 0 {
+			return nil, fmt.Errorf("division by zero %s %s %s", x, op, y)
+		}
+		// the division of integers truncates, like the compiler does.
+		if op == token.QUO && x.Kind() == constant.Int && y.Kind() == constant.Int {
+			op = token.QUO_ASSIGN
+		}
+	case token.REM, token.AND, token.OR, token.XOR, token.AND_NOT:
+		if x.Kind() != constant.Int || y.Kind() != constant.Int {
+			return nil, fmt.Errorf("invalid operation %s %s %s", x, op, y)
+		}
+		if op == token.REM && constant.Sign(y) == 0 {
+			return nil, fmt.Errorf("division by zero %s %s %s", x, op, y)
+		}
+	default:
+		return nil, fmt.Errorf("invalid operation %s %s %s", x, op, y)
+	}
+	return constant.BinaryOp(x, op, y), nil
+}
+
+// evaluateArrayLen evaluates the length of an array declared using a constant expression, like `[Size * 2]byte`.
+func (f *astTypeGenerator) evaluateArrayLen(
+	expr ast.Expr,
+	packagePath string,
+	importMap map[string]string,
+) (int, bool) {
+	value, err := f.constEvaluator().evaluate(expr, constScope{packagePath: packagePath, importMap: importMap})
+	if err != nil {
+		f.explain(expr.Pos(), "cannot evaluate array length %s: %v", types.ExprString(expr), err)
+		return 0, false
+	}
+	n, ok := constant.Int64Val(constant.ToInt(value))
+	if !ok || n < 0 {
+		return 0, false
+	}
+	return int(n), true
+}
+
+func isNumeric(x constant.Value) bool {
+	switch x.Kind() {
+	case constant.Int, constant.Float, constant.Complex:
+		return true
+	}
+	return false
+}
+
+func comparableConsts(x, y constant.Value) bool {
+	return (isNumeric(x) && isNumeric(y)) || x.Kind() == y.Kind()
+}
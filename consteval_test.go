@@ -0,0 +1,103 @@
+package gotype
+
+import (
+	"errors"
+	"fmt"
+	"go/constant"
+	"math/bits"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateConst(t *testing.T) {
+	pkg := testdataPackage + "/consts"
+	expected := map[string]string{
+		"Sunday":     "0",
+		"Monday":     "1",
+		"Thursday":   "4",
+		"FlagA":      "1",
+		"FlagC":      "4",
+		"BufferSize": "4096",
+		"Name":       `"units"`,
+		"NameLen":    "5",
+		"Half":       "1/2",
+		"Quotient":   "3",
+		"Ratio":      "7",
+		"Timeout":    "3000000000",
+		"Enabled":    "true",
+		"Third":      "1/3",
+		"MaxU32":     "4294967295",
+		"MaxByte":    "255",
+		"MinusOne":   "-1",
+	}
+	for name, value := range expected {
+		constValue, err := EvaluateConst(pkg, name)
+		require.NoError(t, err, name)
+		assert.Equal(t, value, constValue.ExactString(), name)
+	}
+
+	ratio, err := EvaluateConst(pkg, "Ratio")
+	require.NoError(t, err)
+	assert.Equal(t, constant.Float, ratio.Kind())
+
+	value, err := EvaluateConstExpr(pkg, "u.KB * 2 + Tuesday")
+	require.NoError(t, err)
+	assert.Equal(t, "2050", value.ExactString())
+
+	_, err = EvaluateConst(pkg, "Missing")
+	assert.True(t, errors.Is(err, ErrDeclarationNotFound))
+	_, err = EvaluateConstExpr(pkg, "1 / 0")
+	assert.EqualError(t, err, "division by zero 1 / 0")
+	_, err = EvaluateConstExpr(pkg, "Buffer{}")
+	assert.True(t, errors.Is(err, errNotConstant))
+
+	types, err := GenerateTypesFromSpecs(TypeSpec{PackagePath: pkg, Name: "Buffer"})
+	require.NoError(t, err)
+	assert.Equal(t, "struct {\n    Data [4]byte\n    Flags [5]bool\n}", types[0].String(""))
+
+	// the constants of the imported packages are evaluated from their sources, like bits.UintSize, which relies on the
+	// bitwise complement of an unsigned constant.
+	types, err = GenerateTypesFromSpecs(TypeSpec{PackagePath: pkg, Name: "Key"})
+	require.NoError(t, err)
+	expectedKey := fmt.Sprintf("struct {\n    Data [64]byte\n    Words [%d]byte\n}", bits.UintSize/8)
+	assert.Equal(t, expectedKey, types[0].String(""))
+	value, err = EvaluateConstExpr(pkg, "bits.UintSize")
+	require.NoError(t, err)
+	assert.Equal(t, strconv.Itoa(bits.UintSize), value.ExactString())
+
+	// unsafe.Sizeof and unsafe.Alignof follow the layout of the target architecture.
+	for goarch, expected := range map[string]string{
+		"amd64": "struct {\n    Word [8]byte\n    Key [72]byte\n    Align [1]byte\n    Halves [8]byte\n    Words [4]byte\n}",
+		"386":   "struct {\n    Word [8]byte\n    Key [68]byte\n    Align [1]byte\n    Halves [8]byte\n    Words [2]byte\n}",
+	} {
+		generator := NewGenerator(WithConfig(Config{Env: append(os.Environ(), "GOARCH="+goarch)}))
+		types, err = generator.GenerateTypesFromSpecs(TypeSpec{PackagePath: pkg, Name: "Sized"})
+		require.NoError(t, err)
+		assert.Equal(t, expected, types[0].String(""), goarch)
+	}
+	_, err = EvaluateConstExpr(pkg, "unsafe.Offsetof(key.Data)")
+	assert.True(t, errors.Is(err, errNotConstant))
+	_, err = EvaluateConstExpr(pkg, "unsafe.Sizeof(missing)")
+	assert.True(t, errors.Is(err, errNotConstant))
+
+	// the bitwise complement of an unsigned constant is computed within the size of its type.
+	types, err = GenerateTypesFromSpecs(TypeSpec{PackagePath: pkg, Name: "Complement"})
+	require.NoError(t, err)
+	assert.Equal(t, "[255]byte", types[0].String(""))
+
+	// len and cap of the arrays are constant.
+	types, err = GenerateTypesFromSpecs(TypeSpec{PackagePath: pkg, Name: "Lengths"})
+	require.NoError(t, err)
+	assert.Equal(t, "struct {\n    Table [6]byte\n    Ptr [5]byte\n}", types[0].String(""))
+	_, err = EvaluateConstExpr(pkg, "len(Buffer{}.Missing)")
+	assert.Error(t, err)
+
+	declarations, err := GenerateFromSpecs(Spec{PackagePath: pkg, Name: "Thursday", Kind: SpecKindConst})
+	require.NoError(t, err)
+	require.NotNil(t, declarations[0].Constant)
+	assert.Equal(t, "4", declarations[0].Constant.ExactString())
+}
@@ -0,0 +1,487 @@
+package gotype
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// ErrDeclarationNotFound is matched by the errors returned when a declaration of any kind can't be found, using
+// `errors.Is`. The errors about the missing types match ErrTypeNotFound as well.
+var ErrDeclarationNotFound = errors.New("declaration not found")
+
+// SpecKind represents the kind of the declaration specified by a Spec.
+type SpecKind int
+
+const (
+	// SpecKindType specifies a type declaration. It's the zero value, so a Spec works like a TypeSpec by default.
+	SpecKindType SpecKind = iota
+
+	// SpecKindFunc specifies a function declared without a receiver.
+	SpecKindFunc
+
+	// SpecKindMethod specifies a method, whose name is qualified by the name of its receiver's type, like
+	// "Client.Do".
+	SpecKindMethod
+
+	// SpecKindConst specifies a constant.
+	SpecKindConst
+
+	// SpecKindVar specifies a package level variable.
+	SpecKindVar
+)
+
+func (k SpecKind) String() string {
+	switch k {
+	case SpecKindType:
+		return "type"
+	case SpecKindFunc:
+		return "func"
+	case SpecKindMethod:
+		return "method"
+	case SpecKindConst:
+		return "const"
+	case SpecKindVar:
+		return "var"
+	}
+	return fmt.Sprintf("SpecKind(%d)", int(k))
+}
+
+// Spec specifies a declaration of any kind inside a package. It's used as a query to `GenerateFromSpecs`, which
+// extracts different kinds of declarations in a single call.
+type Spec struct {
+	// PackagePath contains the package path of the declaration, see `TypeSpec.PackagePath`.
+	PackagePath string
+
+	// Name contains the name of the declaration inside the package. The name of a method is qualified by the name of
+	// its receiver's type, like "Client.Do".
+	Name string
+
+	// Kind contains the kind of the declaration.
+	Kind SpecKind
+}
+
+// Declaration is a declaration generated from a Spec.
+type Declaration struct {
+	// Spec contains the Spec of the declaration.
+	Spec Spec
+
+	// Type contains the definition of a type, the signature of a function or a method, without its receiver, or the
+	// type of a constant or a variable. The type of a constant or a variable declared without an explicit type is
+	// inferred from the literal of its value, like `int` for `1`, or from the type of a composite literal. Type is
+	// empty when it can't be inferred syntactically, like for the result of a function call.
+	Type Type
+
+	// Value contains the expression of the value of a constant or a variable, like "iota + 1". The constants declared
+	// without a value repeat the expression of the previous constant of their group.
+	Value string
+
+	// Constant contains the value of a constant, evaluated by the constant evaluator, see `EvaluateConst`. It's nil for
+	// the other kinds of declarations and for the constants which can't be evaluated, which are reported as warnings.
+	Constant constant.Value
+
+	// Receiver contains the receiver of a method. It's nil for the other kinds of declarations.
+	Receiver *Receiver
+
+	// Doc contains the documentation comment written above the declaration.
+	Doc string
+
+	// Position contains the location where the declaration's name is declared.
+	Position token.Position
+}
+
+// Receiver describes the receiver of a method, so the generators emitting wrappers can mirror it exactly.
+type Receiver struct {
+	// Name contains the receiver's name, like "c" in `func (c *Client) Do()`. It's empty when the receiver is unnamed.
+	Name string
+
+	// Pointer reports whether the receiver is a pointer, like `*Client`.
+	Pointer bool
+
+	// Type contains the receiver's type, without the pointer. The type arguments of a generic type are the receiver's
+	// type parameters, like `Repo[U]` in `func (r *Repo[U]) Get(id U) U`.
+	Type QualType
+
+	// TypeParams contains the names of the type parameters declared by the receiver, like "U" in
+	// `func (r *Repo[U]) Get(id U) U`. They may differ from the names declared by the type, which are the ones used by
+	// the method's signature.
+	TypeParams []string
+}
+
+// GenerateFromSpecs generates the declarations specified by the `specs`. See `TypeGenerator.GenerateFromSpecs` for
+// the details.
+func GenerateFromSpecs(specs ...Spec) ([]Declaration, error) {
+	return defaultAstTypeGenerator.GenerateFromSpecs(specs...)
+}
+
+func (f *astTypeGenerator) GenerateFromSpecs(specs ...Spec) ([]Declaration, error) {
+	f = f.fork()
+
+	typeSpecs := make([]TypeSpec, 0)
+	for _, spec := range specs {
+		if spec.Kind == SpecKindType {
+			typeSpecs = append(typeSpecs, TypeSpec{PackagePath: spec.PackagePath, Name: spec.Name})
+		}
+	}
+	types, err := f.generateTypesFromSpecs(typeSpecs)
+	if err != nil {
+		return nil, err
+	}
+	typeMap := make(map[TypeSpec]Type, len(typeSpecs))
+	for i, spec := range typeSpecs {
+		typeMap[spec] = types[i]
+	}
+
+	declarationMap := make(map[Spec]Declaration)
+	for _, packageSpecs := range groupSpecsByPackage(specs) {
+		declarations, err := f.generateDeclarationsInSinglePackage(packageSpecs)
+		if err != nil {
+			return nil, err
+		}
+		for spec, declaration := range declarations {
+			declarationMap[spec] = declaration
+		}
+	}
+
+	var resolver *deepResolver
+	if f.config.deepResolution {
+		resolver = newDeepResolver(f)
+	}
+
+	results := make([]Declaration, 0, len(specs))
+	for _, spec := range specs {
+		declaration := declarationMap[spec]
+		typeSpec := TypeSpec{PackagePath: spec.PackagePath, Name: spec.Name}
+		if spec.Kind == SpecKindType {
+			declaration.Type = typeMap[typeSpec]
+		} else if resolver != nil {
+			if declaration.Type, err = resolver.resolveDeclaration(typeSpec, declaration.Type); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := f.checkVisibility(typeSpec, declaration.Type); err != nil {
+			return nil, err
+		}
+		declaration.Type = f.rewritePackagePaths(declaration.Type)
+		if declaration.Receiver != nil {
+			declaration.Receiver.Type = f.rewriteQualType(declaration.Receiver.Type)
+		}
+		results = append(results, declaration)
+	}
+	return results, nil
+}
+
+// groupSpecsByPackage groups the distinct specs by their package, the packages sorted by their paths.
+func groupSpecsByPackage(specs []Spec) [][]Spec {
+	groups := make(map[string][]Spec)
+	seen := make(map[Spec]struct{})
+	for _, spec := range specs {
+		if _, ok := seen[spec]; ok {
+			continue
+		}
+		seen[spec] = struct{}{}
+		groups[spec.PackagePath] = append(groups[spec.PackagePath], spec)
+	}
+
+	packagePaths := make([]string, 0, len(groups))
+	for packagePath := range groups {
+		packagePaths = append(packagePaths, packagePath)
+	}
+	sort.Strings(packagePaths)
+
+	result := make([][]Spec, 0, len(packagePaths))
+	for _, packagePath := range packagePaths {
+		result = append(result, groups[packagePath])
+	}
+	return result
+}
+
+// generateDeclarationsInSinglePackage generates the declarations specified by the `specs`, which belong to the same
+// package, walking the package's files once. The types' definitions are generated by generateTypesFromSpecs, only their
+// documentation and position are filled here.
+func (f *astTypeGenerator) generateDeclarationsInSinglePackage(specs []Spec) (map[Spec]Declaration, error) {
+	packagePath := specs[0].PackagePath
+	goSources, err := f.getPackageSourceFiles(packagePath)
+	if err != nil {
+		return nil, err
+	}
+	// the declarations of a version-pinned package are qualified by the package path without the version.
+	unversionedPath, _ := splitPackageVersion(packagePath)
+
+	wanted := make(map[SpecKind]map[string]Spec)
+	for _, spec := range specs {
+		if wanted[spec.Kind] == nil {
+			wanted[spec.Kind] = make(map[string]Spec)
+		}
+		wanted[spec.Kind][spec.Name] = spec
+	}
+
+	declarations := make(map[Spec]Declaration, len(specs))
+	for _, source := range goSources {
+		if strings.HasSuffix(source, "_test.go") {
+			continue
+		}
+		fileAst, err := f.parseAstFile(source)
+		if err != nil {
+			return nil, err
+		}
+		importMap := f.generateImportMap(unversionedPath, fileAst)
+
+		for _, decl := range fileAst.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				spec, ok := wanted[SpecKindFunc][decl.Name.Name]
+				if !ok || decl.Recv != nil {
+					continue
+				}
+				declaration, err := f.generateFuncDeclaration(spec, decl, unversionedPath, importMap)
+				if err != nil {
+					return nil, err
+				}
+				declarations[spec] = declaration
+			case *ast.GenDecl:
+				if err := f.generateGenDeclarations(
+					declarations,
+					wanted,
+					decl,
+					unversionedPath,
+					importMap,
+				); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	for _, spec := range specs {
+		if spec.Kind != SpecKindMethod {
+			continue
+		}
+		declaration, err := f.generateMethodDeclaration(spec)
+		if err != nil {
+			return nil, err
+		}
+		declarations[spec] = declaration
+	}
+
+	for _, spec := range specs {
+		if _, ok := declarations[spec]; !ok && spec.Kind != SpecKindType {
+			return nil, fmt.Errorf("cannot find %s %s in package %s: %w", spec.Kind, spec.Name, packagePath,
+				ErrDeclarationNotFound)
+		}
+	}
+	return declarations, nil
+}
+
+func (f *astTypeGenerator) generateFuncDeclaration(
+	spec Spec,
+	decl *ast.FuncDecl,
+	packagePath string,
+	importMap map[string]string,
+) (Declaration, error) {
+	importMap = f.withTypeParams(importMap, decl.Type.TypeParams)
+	typeParams, err := f.generateTypeParams(decl.Type.TypeParams, packagePath, importMap)
+	if err != nil {
+		return Declaration{}, err
+	}
+
+	funcType, err := f.generateTypeFromFuncType(decl.Type, packagePath, importMap)
+	if err != nil {
+		return Declaration{}, err
+	}
+	return Declaration{
+		Spec:     spec,
+		Type:     Type{FuncType: &funcType, TypeParams: typeParams},
+		Doc:      decl.Doc.Text(),
+		Position: f.position(decl.Name.Pos()),
+	}, nil
+}
+
+func (f *astTypeGenerator) generateMethodDeclaration(spec Spec) (Declaration, error) {
+	dot := strings.Index(spec.Name, ".")
+	if dot < 0 {
+		return Declaration{}, fmt.Errorf("invalid method name %s, it must be qualified by its receiver's type",
+			spec.Name)
+	}
+
+	methods, err := f.generateMethods(spec.PackagePath, spec.Name[:dot])
+	if err != nil {
+		return Declaration{}, err
+	}
+	for _, method := range methods {
+		if method.method.Name == spec.Name[dot+1:] {
+			funcType, receiver := method.method.Func, method.receiver
+			return Declaration{
+				Spec:     spec,
+				Type:     funcType.Type(),
+				Receiver: &receiver,
+				Doc:      method.method.Doc,
+				Position: method.method.Position,
+			}, nil
+		}
+	}
+	return Declaration{}, fmt.Errorf("cannot find method %s in package %s: %w", spec.Name, spec.PackagePath,
+		ErrDeclarationNotFound)
+}
+
+// specDoc returns the documentation comment `doc` of a spec of `decl`. The specs of a declaration without parentheses
+// are documented by the declaration's comment.
+func specDoc(decl *ast.GenDecl, doc *ast.CommentGroup) string {
+	if doc == nil && decl.Lparen == token.NoPos {
+		return decl.Doc.Text()
+	}
+	return doc.Text()
+}
+
+// generateGenDeclarations generates the declarations of the types, constants and variables wanted from `decl`.
+func (f *astTypeGenerator) generateGenDeclarations(
+	declarations map[Spec]Declaration,
+	wanted map[SpecKind]map[string]Spec,
+	decl *ast.GenDecl,
+	packagePath string,
+	importMap map[string]string,
+) error {
+	// the constants declared without a value repeat the type and the values of the previous constant of the group.
+	var typeExpr ast.Expr
+	var values []ast.Expr
+	for _, spec := range decl.Specs {
+		switch spec := spec.(type) {
+		case *ast.TypeSpec:
+			if s, ok := wanted[SpecKindType][spec.Name.Name]; ok {
+				declarations[s] = Declaration{Spec: s, Doc: specDoc(decl, spec.Doc), Position: f.position(spec.Name.Pos())}
+			}
+		case *ast.ValueSpec:
+			kind := SpecKindVar
+			if decl.Tok == token.CONST {
+				kind = SpecKindConst
+			}
+			if kind == SpecKindVar || spec.Type != nil || len(spec.Values) > 0 {
+				typeExpr, values = spec.Type, spec.Values
+			}
+
+			for i, name := range spec.Names {
+				s, ok := wanted[kind][name.Name]
+				if !ok {
+					continue
+				}
+
+				var value ast.Expr
+				if i < len(values) {
+					value = values[i]
+				}
+				typ, err := f.generateValueType(typeExpr, value, packagePath, importMap)
+				if err != nil {
+					return err
+				}
+
+				declaration := Declaration{Spec: s, Type: typ, Doc: specDoc(decl, spec.Doc), Position: f.position(name.Pos())}
+				if value != nil {
+					declaration.Value = types.ExprString(value)
+				}
+				if kind == SpecKindConst {
+					if declaration.Constant, err = f.constEvaluator().evaluateConst(s.PackagePath, s.Name); err != nil {
+						f.warn(name.Pos(), "%v", err)
+					}
+				}
+				declarations[s] = declaration
+			}
+		}
+	}
+	return nil
+}
+
+// generateValueType generates the type of a constant or a variable, declared using `typeExpr` or inferred from the
+// expression of its `value`. An empty Type is returned when the type can't be inferred.
+func (f *astTypeGenerator) generateValueType(
+	typeExpr ast.Expr,
+	value ast.Expr,
+	packagePath string,
+	importMap map[string]string,
+) (Type, error) {
+	if typeExpr != nil {
+		return f.generateTypeFromExpr(typeExpr, packagePath, importMap)
+	}
+
+	switch v := value.(type) {
+	case *ast.BasicLit:
+		kinds := map[token.Token]string{
+			token.INT:    "int",
+			token.FLOAT:  "float64",
+			token.IMAG:   "complex128",
+			token.CHAR:   "rune",
+			token.STRING: "string",
+		}
+		return f.generateTypeFromExpr(ast.NewIdent(kinds[v.Kind]), packagePath, importMap)
+	case *ast.Ident:
+		switch v.Name {
+		case "true", "false":
+			return f.generateTypeFromExpr(ast.NewIdent("bool"), packagePath, importMap)
+		case "iota":
+			return f.generateTypeFromExpr(ast.NewIdent("int"), packagePath, importMap)
+		}
+	case *ast.ParenExpr:
+		return f.generateValueType(nil, v.X, packagePath, importMap)
+	case *ast.UnaryExpr:
+		if v.Op == token.AND {
+			elem, err := f.generateValueType(nil, v.X, packagePath, importMap)
+			if err != nil || isEmptyType(elem) {
+				return elem, err
+			}
+			return PtrType{Elem: elem}.Type(), nil
+		}
+		return f.generateValueType(nil, v.X, packagePath, importMap)
+	case *ast.BinaryExpr:
+		switch v.Op {
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ, token.LAND, token.LOR:
+			return f.generateTypeFromExpr(ast.NewIdent("bool"), packagePath, importMap)
+		case token.SHL, token.SHR:
+			return f.generateValueType(nil, v.X, packagePath, importMap)
+		}
+		// the untyped constants take the kind appearing later in the list int, rune, float, complex.
+		x, err := f.generateValueType(nil, v.X, packagePath, importMap)
+		if err != nil || isEmptyType(x) {
+			return x, err
+		}
+		y, err := f.generateValueType(nil, v.Y, packagePath, importMap)
+		if err != nil || isEmptyType(y) {
+			return y, err
+		}
+		if untypedRank(y) > untypedRank(x) {
+			return y, nil
+		}
+		return x, nil
+	case *ast.CompositeLit:
+		if v.Type != nil {
+			return f.generateTypeFromExpr(v.Type, packagePath, importMap)
+		}
+	}
+	return Type{}, nil
+}
+
+// untypedRank returns the rank of the kind of an untyped constant, see `generateValueType`.
+func untypedRank(t Type) int {
+	if t.PrimitiveType == nil {
+		return 0
+	}
+	switch t.PrimitiveType.Kind {
+	case PrimitiveKindRune:
+		return 1
+	case PrimitiveKindFloat64:
+		return 2
+	case PrimitiveKindComplex128:
+		return 3
+	}
+	return 0
+}
+
+func isEmptyType(t Type) bool {
+	return t.PrimitiveType == nil && t.QualType == nil && t.ChanType == nil && t.SliceType == nil && t.PtrType == nil &&
+		t.ArrayType == nil && t.MapType == nil && t.FuncType == nil && t.StructType == nil && t.InterfaceType == nil &&
+		t.TypeParamType == nil
+}
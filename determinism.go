@@ -0,0 +1,36 @@
+package gotype
+
+import (
+	"fmt"
+	"go/token"
+	"reflect"
+)
+
+// generateCheckedTypesFromSpecs generates the types like GenerateTypesFromSpecs, then generates them again using a
+// fresh generator sharing only the SourceFinder, and compares the results. See `WithDeterminismCheck`.
+func (f *astTypeGenerator) generateCheckedTypesFromSpecs(typeSpecs []TypeSpec) ([]Type, error) {
+	// `f` is a fork, so its configuration can be changed.
+	f.config.determinismCheck = false
+
+	results, err := f.GenerateTypesFromSpecs(typeSpecs...)
+	if err != nil {
+		return results, err
+	}
+
+	// the second generation doesn't report anything, the callbacks already observed the first one.
+	c := f.config
+	c.warningHandler, c.logger, c.hooks, c.trace = nil, nil, Hooks{}, nil
+	again := &astTypeGenerator{sourceFinder: f.sourceFinder, config: c, fset: token.NewFileSet()}
+	rerun, err := again.GenerateTypesFromSpecs(typeSpecs...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate the types again to check the output is deterministic: %w", err)
+	}
+
+	for i, spec := range typeSpecs {
+		if !reflect.DeepEqual(results[i], rerun[i]) {
+			return nil, fmt.Errorf("the type %s.%s is generated differently twice: %w", spec.PackagePath, spec.Name,
+				ErrNondeterministicOutput)
+		}
+	}
+	return results, nil
+}
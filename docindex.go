@@ -0,0 +1,393 @@
+package gotype
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"sort"
+	"strings"
+)
+
+// DocIndex is a machine-readable index of the exported API of packages, generated by
+// `TypeGenerator.GenerateDocIndex` for the static documentation generators and the developer portals. The declarations
+// are identified by their qualified names, like "example.com/shop.Order" for a type, and "example.com/shop.Order.Total"
+// for a field or a method, which are used by the Links to cross-reference them.
+type DocIndex struct {
+	// Packages contains the indexed packages, sorted by their paths.
+	Packages []DocPackage `json:"packages"`
+}
+
+// DocPackage is an indexed package.
+type DocPackage struct {
+	// Path contains the package path.
+	Path string `json:"path"`
+
+	// Name contains the package's name.
+	Name string `json:"name"`
+
+	// Doc contains the package's documentation comment, written above a package clause.
+	Doc string `json:"doc,omitempty"`
+
+	// Types contains the exported types, in the order of their declaration.
+	Types []DocType `json:"types"`
+
+	// Funcs contains the exported functions, in the order of their declaration.
+	Funcs []DocFunc `json:"funcs"`
+
+	// Consts contains the exported constants, in the order of their declaration.
+	Consts []DocValue `json:"consts"`
+
+	// Vars contains the exported package level variables, in the order of their declaration.
+	Vars []DocValue `json:"vars"`
+}
+
+// DocType is an indexed type.
+type DocType struct {
+	// ID contains the qualified name of the type.
+	ID string `json:"id"`
+
+	// Name contains the type's name.
+	Name string `json:"name"`
+
+	// Definition contains the type's definition, like "map[string]int", with the type parameters of a generic type.
+	// The fields and the methods of the structs and the interfaces are listed by Fields and Methods instead.
+	Definition string `json:"definition"`
+
+	// Doc contains the type's documentation comment.
+	Doc string `json:"doc,omitempty"`
+
+	// Position contains the location of the type's declaration.
+	Position string `json:"position"`
+
+	// Fields contains the exported fields of a struct, in the order of their declaration.
+	Fields []DocField `json:"fields,omitempty"`
+
+	// Methods contains the exported methods of an interface, or the exported methods declared with the type as their
+	// receiver, in the order of their declaration.
+	Methods []DocFunc `json:"methods,omitempty"`
+
+	// Links contains the qualified names of the types referenced by the definition, sorted.
+	Links []string `json:"links,omitempty"`
+}
+
+// DocField is an indexed field of a struct.
+type DocField struct {
+	// ID contains the qualified name of the field.
+	ID string `json:"id"`
+
+	// Name contains the field's name.
+	Name string `json:"name"`
+
+	// Type contains the field's type.
+	Type string `json:"type"`
+
+	// Tag contains the field's tag.
+	Tag string `json:"tag,omitempty"`
+
+	// Doc contains the field's documentation comment, or the comment written after the field on the same line.
+	Doc string `json:"doc,omitempty"`
+
+	// Position contains the location of the field's declaration.
+	Position string `json:"position"`
+
+	// Links contains the qualified names of the types referenced by the field's type, sorted.
+	Links []string `json:"links,omitempty"`
+}
+
+// DocFunc is an indexed function or method.
+type DocFunc struct {
+	// ID contains the qualified name of the function or the method.
+	ID string `json:"id"`
+
+	// Name contains the function's name.
+	Name string `json:"name"`
+
+	// Signature contains the function's signature, like "func(id int) (out1 *Order, out2 error)".
+	Signature string `json:"signature"`
+
+	// PointerReceiver is true when the method is declared with a pointer receiver.
+	PointerReceiver bool `json:"pointer_receiver,omitempty"`
+
+	// Doc contains the function's documentation comment.
+	Doc string `json:"doc,omitempty"`
+
+	// Position contains the location of the function's declaration.
+	Position string `json:"position"`
+
+	// Links contains the qualified names of the types referenced by the signature, sorted.
+	Links []string `json:"links,omitempty"`
+}
+
+// DocValue is an indexed constant or variable.
+type DocValue struct {
+	// ID contains the qualified name of the constant or the variable.
+	ID string `json:"id"`
+
+	// Name contains the name of the constant or the variable.
+	Name string `json:"name"`
+
+	// Type contains the type of the constant or the variable, see `Declaration.Type`. It's empty when the type can't
+	// be inferred.
+	Type string `json:"type,omitempty"`
+
+	// Value contains the expression of the value, see `Declaration.Value`.
+	Value string `json:"value,omitempty"`
+
+	// Doc contains the documentation comment.
+	Doc string `json:"doc,omitempty"`
+
+	// Position contains the location of the declaration.
+	Position string `json:"position"`
+
+	// Links contains the qualified names of the types referenced by the type, sorted.
+	Links []string `json:"links,omitempty"`
+}
+
+// JSON encodes the index as indented JSON.
+func (i DocIndex) JSON() ([]byte, error) {
+	data, err := json.MarshalIndent(i, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode the documentation index: %w", err)
+	}
+	return data, nil
+}
+
+// GenerateDocIndex generates the documentation index of the packages matched by `packagePatterns`. See
+// `TypeGenerator.GenerateDocIndex` for the details.
+func GenerateDocIndex(packagePatterns ...string) (DocIndex, error) {
+	return defaultAstTypeGenerator.GenerateDocIndex(packagePatterns...)
+}
+
+func (f *astTypeGenerator) GenerateDocIndex(packagePatterns ...string) (DocIndex, error) {
+	f = f.fork()
+	packages, err := f.expandPackagePatterns(packagePatterns...)
+	if err != nil {
+		return DocIndex{}, err
+	}
+
+	index := DocIndex{Packages: make([]DocPackage, 0, len(packages))}
+	for _, packagePath := range packages {
+		docPackage, err := f.generateDocPackage(packagePath)
+		if err != nil {
+			return DocIndex{}, err
+		}
+		index.Packages = append(index.Packages, docPackage)
+	}
+	sort.Slice(index.Packages, func(i, j int) bool { return index.Packages[i].Path < index.Packages[j].Path })
+	return index, nil
+}
+
+func (f *astTypeGenerator) generateDocPackage(packagePath string) (DocPackage, error) {
+	model, err := f.generateExportedPackageModel(packagePath)
+	if err != nil {
+		return DocPackage{}, err
+	}
+	packageDoc, fieldDocs, err := f.getPackageDocs(packagePath)
+	if err != nil {
+		return DocPackage{}, err
+	}
+
+	path := f.rewritePackagePath(packagePath)
+	docPackage := DocPackage{
+		Path:   path,
+		Name:   model.Name,
+		Doc:    packageDoc,
+		Types:  make([]DocType, 0),
+		Funcs:  make([]DocFunc, 0),
+		Consts: make([]DocValue, 0),
+		Vars:   make([]DocValue, 0),
+	}
+	methods := make(map[string][]DocFunc)
+	for _, declaration := range model.Declarations {
+		id := path + "." + declaration.Spec.Name
+		switch declaration.Spec.Kind {
+		case SpecKindType:
+			docPackage.Types = append(docPackage.Types, docType(id, model.Name, declaration, fieldDocs))
+		case SpecKindFunc:
+			docPackage.Funcs = append(docPackage.Funcs, docFunc(id, model.Name, declaration))
+		case SpecKindMethod:
+			// the methods may be declared before their receivers' types, so they're added to the types afterwards.
+			receiver := strings.SplitN(declaration.Spec.Name, ".", 2)[0]
+			methods[receiver] = append(methods[receiver], docFunc(id, model.Name, declaration))
+		case SpecKindConst, SpecKindVar:
+			value := DocValue{
+				ID:       id,
+				Name:     declaration.Spec.Name,
+				Value:    declaration.Value,
+				Doc:      declaration.Doc,
+				Position: declaration.Position.String(),
+			}
+			if !isEmptyType(declaration.Type) {
+				value.Type = declaration.Type.String(model.Name)
+				value.Links = docLinks(declaration.Type)
+			}
+			if declaration.Spec.Kind == SpecKindConst {
+				docPackage.Consts = append(docPackage.Consts, value)
+			} else {
+				docPackage.Vars = append(docPackage.Vars, value)
+			}
+		}
+	}
+	for i := range docPackage.Types {
+		docPackage.Types[i].Methods = append(docPackage.Types[i].Methods, methods[docPackage.Types[i].Name]...)
+	}
+	return docPackage, nil
+}
+
+// getPackageDocs returns the documentation comment of the package, and the documentation comments of the exported
+// fields of the structs declared at the top level of the package, keyed by the names of the structs and the fields.
+func (f *astTypeGenerator) getPackageDocs(packagePath string) (string, map[string]map[string]string, error) {
+	goSources, err := f.getPackageSourceFiles(packagePath)
+	if err != nil {
+		return "", nil, err
+	}
+
+	packageDoc := ""
+	fieldDocs := make(map[string]map[string]string)
+	for _, source := range goSources {
+		if strings.HasSuffix(source, "_test.go") {
+			continue
+		}
+		fileAst, err := f.parseAstFile(source)
+		if err != nil {
+			return "", nil, err
+		}
+		if packageDoc == "" {
+			packageDoc = fileAst.Doc.Text()
+		}
+
+		for _, decl := range fileAst.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				structType, ok := typeSpec.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+				docs := make(map[string]string)
+				for _, field := range structType.Fields.List {
+					doc := field.Doc.Text()
+					if doc == "" {
+						doc = field.Comment.Text()
+					}
+					for _, name := range field.Names {
+						docs[name.Name] = doc
+					}
+				}
+				fieldDocs[typeSpec.Name.Name] = docs
+			}
+		}
+	}
+	return packageDoc, fieldDocs, nil
+}
+
+func docType(id, packageName string, declaration Declaration, fieldDocs map[string]map[string]string) DocType {
+	typ := declaration.Type
+	docType := DocType{
+		ID:       id,
+		Name:     declaration.Spec.Name,
+		Doc:      declaration.Doc,
+		Position: declaration.Position.String(),
+	}
+
+	params := make([]string, 0, len(typ.TypeParams))
+	for _, param := range typ.TypeParams {
+		params = append(params, param.Name+" "+param.Constraint.String(packageName))
+		docType.Links = append(docType.Links, docLinks(param.Constraint)...)
+	}
+	if len(params) > 0 {
+		docType.Definition = "[" + strings.Join(params, ", ") + "] "
+	}
+
+	switch {
+	case typ.StructType != nil:
+		docType.Definition += "struct"
+		for _, field := range typ.StructType.Fields {
+			if !ast.IsExported(field.Name) {
+				continue
+			}
+			docType.Fields = append(docType.Fields, DocField{
+				ID:       id + "." + field.Name,
+				Name:     field.Name,
+				Type:     field.Type.String(packageName),
+				Tag:      string(field.Tag),
+				Doc:      fieldDocs[declaration.Spec.Name][field.Name],
+				Position: field.Position.String(),
+				Links:    docLinks(field.Type),
+			})
+			docType.Links = append(docType.Links, docLinks(field.Type)...)
+		}
+	case typ.InterfaceType != nil && len(typ.InterfaceType.Unions) == 0:
+		docType.Definition += "interface"
+		for _, method := range typ.InterfaceType.Methods {
+			if !ast.IsExported(method.Name) {
+				continue
+			}
+			methodType := Type{FuncType: &method.Func}
+			docType.Methods = append(docType.Methods, DocFunc{
+				ID:        id + "." + method.Name,
+				Name:      method.Name,
+				Signature: method.Func.String(packageName),
+				Doc:       method.Doc,
+				Position:  method.Position.String(),
+				Links:     docLinks(methodType),
+			})
+			docType.Links = append(docType.Links, docLinks(methodType)...)
+		}
+	default:
+		docType.Definition += typ.String(packageName)
+		docType.Links = append(docType.Links, docLinks(typ)...)
+	}
+	docType.Links = uniqueSortedStrings(docType.Links)
+	return docType
+}
+
+func docFunc(id, packageName string, declaration Declaration) DocFunc {
+	docFunc := DocFunc{
+		ID:        id,
+		Name:      declaration.Spec.Name[strings.LastIndex(declaration.Spec.Name, ".")+1:],
+		Signature: declaration.Type.String(packageName),
+		Doc:       declaration.Doc,
+		Position:  declaration.Position.String(),
+		Links:     docLinks(declaration.Type),
+	}
+	if declaration.Receiver != nil {
+		docFunc.PointerReceiver = declaration.Receiver.Pointer
+	}
+	return docFunc
+}
+
+// docLinks returns the qualified names of the named types referenced by `typ`, sorted. The predeclared types aren't
+// linked.
+func docLinks(typ Type) []string {
+	links := make([]string, 0)
+	collector := usageCollector{
+		add: func(q QualType, _ TypeReference) {
+			links = append(links, q.Package+"."+q.Name)
+		},
+	}
+	collector.collectType(typ, TypeReference{})
+	return uniqueSortedStrings(links)
+}
+
+// uniqueSortedStrings sorts the strings and removes the duplicates. It returns nil for no strings, so they're omitted
+// from JSON.
+func uniqueSortedStrings(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	sort.Strings(values)
+	unique := values[:1]
+	for _, value := range values[1:] {
+		if value != unique[len(unique)-1] {
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,118 @@
+package gotype
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateDocIndex(t *testing.T) {
+	index, err := GenerateDocIndex(testdataPackage + "/docs")
+	require.NoError(t, err)
+	require.Len(t, index.Packages, 1)
+
+	// the positions depend on the location of the repository.
+	for _, typ := range index.Packages[0].Types {
+		assert.Contains(t, typ.Position, "docs.go:")
+	}
+	clearDocPositions(&index)
+
+	docsPackage := testdataPackage + "/docs"
+	assert.Equal(t, DocPackage{
+		Path: docsPackage,
+		Name: "docs",
+		Doc:  "Package docs is documented.\n",
+		Types: []DocType{
+			{ID: docsPackage + ".Status", Name: "Status", Definition: "int", Doc: "Status is the status of an Order.\n"},
+			{
+				ID:         docsPackage + ".Order",
+				Name:       "Order",
+				Definition: "struct",
+				Doc:        "Order is an order.\n",
+				Fields: []DocField{
+					{ID: docsPackage + ".Order.ID", Name: "ID", Type: "int", Tag: `json:"id"`,
+						Doc: "ID identifies the order.\n"},
+					{ID: docsPackage + ".Order.Status", Name: "Status", Type: "Status",
+						Doc: "Status is the order's status.\n", Links: []string{docsPackage + ".Status"}},
+					{ID: docsPackage + ".Order.Items", Name: "Items", Type: "[]Item",
+						Links: []string{docsPackage + ".Item"}},
+					{ID: docsPackage + ".Order.Placed", Name: "Placed", Type: "time.Time", Links: []string{"time.Time"}},
+				},
+				Methods: []DocFunc{
+					{ID: docsPackage + ".Order.Total", Name: "Total", Signature: "func() (out1 float64)",
+						PointerReceiver: true, Doc: "Total returns the total price.\n"},
+				},
+				Links: []string{docsPackage + ".Item", docsPackage + ".Status", "time.Time"},
+			},
+			{
+				ID:         docsPackage + ".Item",
+				Name:       "Item",
+				Definition: "struct",
+				Doc:        "Item is an item.\n",
+				Fields:     []DocField{{ID: docsPackage + ".Item.Name", Name: "Name", Type: "string"}},
+			},
+			{
+				ID:         docsPackage + ".Store",
+				Name:       "Store",
+				Definition: "interface",
+				Doc:        "Store stores the orders.\n",
+				Methods: []DocFunc{
+					{ID: docsPackage + ".Store.Get", Name: "Get", Signature: "func(id int) (out1 *Order, out2 error)",
+						Doc: "Get returns an order.\n", Links: []string{docsPackage + ".Order"}},
+				},
+				Links: []string{docsPackage + ".Order"},
+			},
+			{
+				ID:         docsPackage + ".Set",
+				Name:       "Set",
+				Definition: "[T comparable] map[T]bool",
+				Doc:        "Set is a set.\n",
+			},
+		},
+		Funcs: []DocFunc{
+			{ID: docsPackage + ".NewOrder", Name: "NewOrder", Signature: "func(items ...Item) (out1 *Order)",
+				Doc: "NewOrder creates an Order.\n", Links: []string{docsPackage + ".Item", docsPackage + ".Order"}},
+		},
+		Consts: []DocValue{
+			{ID: docsPackage + ".Version", Name: "Version", Type: "string", Value: `"1.0"`, Doc: "Version is the version.\n"},
+		},
+		Vars: []DocValue{
+			{ID: docsPackage + ".Default", Name: "Default", Type: "*Order", Value: "&Order{}",
+				Doc: "Default is the default order.\n", Links: []string{docsPackage + ".Order"}},
+		},
+	}, index.Packages[0])
+
+	data, err := index.JSON()
+	require.NoError(t, err)
+	var decoded DocIndex
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, index, decoded)
+	assert.Contains(t, string(data), `"id": "`+docsPackage+`.Order.Total"`)
+}
+
+func clearDocPositions(index *DocIndex) {
+	clearFuncs := func(funcs []DocFunc) {
+		for i := range funcs {
+			funcs[i].Position = ""
+		}
+	}
+	for i := range index.Packages {
+		docPackage := &index.Packages[i]
+		for j := range docPackage.Types {
+			docPackage.Types[j].Position = ""
+			for k := range docPackage.Types[j].Fields {
+				docPackage.Types[j].Fields[k].Position = ""
+			}
+			clearFuncs(docPackage.Types[j].Methods)
+		}
+		clearFuncs(docPackage.Funcs)
+		for j := range docPackage.Consts {
+			docPackage.Consts[j].Position = ""
+		}
+		for j := range docPackage.Vars {
+			docPackage.Vars[j].Position = ""
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package gotype
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+)
+
+// entFieldBuilders maps the primitive kinds to the ent's field builders.
+var entFieldBuilders = map[PrimitiveKind]string{
+	PrimitiveKindBool:    "Bool",
+	PrimitiveKindString:  "String",
+	PrimitiveKindInt:     "Int",
+	PrimitiveKindInt8:    "Int8",
+	PrimitiveKindInt16:   "Int16",
+	PrimitiveKindInt32:   "Int32",
+	PrimitiveKindRune:    "Int32",
+	PrimitiveKindInt64:   "Int64",
+	PrimitiveKindUint:    "Uint",
+	PrimitiveKindUint8:   "Uint8",
+	PrimitiveKindByte:    "Uint8",
+	PrimitiveKindUint16:  "Uint16",
+	PrimitiveKindUint32:  "Uint32",
+	PrimitiveKindUint64:  "Uint64",
+	PrimitiveKindFloat32: "Float32",
+	PrimitiveKindFloat64: "Float",
+}
+
+// RenderEntSchema renders the Golang's source code of an ent schema for the struct type named `typeName`, whose
+// definition is `typ`, to ease the migration of plain structs onto ent. The `models` contains the names of the other
+// structs being migrated, which are used to infer the edges:
+//   - a field of a model's type, a pointer to it, or a slice of them becomes an edge to the model.
+//   - a field like `CompanyID`, where `Company` is one of the models, is the foreign key of the unique edge to
+//     `Company`. The edge is added even when the struct doesn't have a field holding the `Company`.
+//
+// The `ID` field is skipped since ent adds it. The other fields are mapped into ent's fields by their types. The
+// fields whose types don't have an ent's field builder are mapped into JSON fields. The QualTypes having `Underlying`
+// definitions filled by the deep resolution are mapped using their underlying types. The rendered code is a scaffold,
+// it only contains the declarations, the package clause and imports are left to the caller. It uses the
+// "entgo.io/ent", "entgo.io/ent/schema/field" and "entgo.io/ent/schema/edge" packages.
+func RenderEntSchema(typeName string, typ Type, models []string, moduleName string) (string, error) {
+	if typ.StructType == nil {
+		return "", fmt.Errorf("cannot render ent schema of a non-struct type: %s", typ.String(moduleName))
+	}
+	if typ.IsGeneric() {
+		return "", fmt.Errorf("cannot render ent schema of the generic type %s", typeName)
+	}
+
+	isModel := make(map[string]struct{}, len(models))
+	for _, model := range models {
+		isModel[model] = struct{}{}
+	}
+	fieldNames := make(map[string]struct{}, len(typ.StructType.Fields))
+	for _, field := range typ.StructType.Fields {
+		fieldNames[field.Name] = struct{}{}
+	}
+
+	fields := make([]string, 0)
+	edges := make([]string, 0)
+	for _, field := range typ.StructType.Fields {
+		if !ast.IsExported(field.Name) || field.Name == "ID" {
+			continue
+		}
+
+		if model, many, ok := entEdgeModel(field.Type, isModel); ok {
+			edge := fmt.Sprintf("edge.To(%q, %s.Type)", snakeCase(field.Name), model)
+			if !many {
+				edge += ".Unique()"
+				if _, ok := fieldNames[field.Name+"ID"]; ok {
+					edge += fmt.Sprintf(".Field(%q)", snakeCase(field.Name+"ID"))
+				}
+			}
+			edges = append(edges, edge)
+			continue
+		}
+
+		fieldCode := entField(snakeCase(field.Name), field.Type, moduleName)
+		// the edge of a foreign key is declared by the field holding the model, when there is one.
+		if model := strings.TrimSuffix(field.Name, "ID"); model != field.Name {
+			_, hasModelField := fieldNames[model]
+			if _, ok := isModel[model]; ok && !hasModelField {
+				edges = append(edges, fmt.Sprintf(
+					"edge.To(%q, %s.Type).Unique().Field(%q)",
+					snakeCase(model),
+					model,
+					snakeCase(field.Name),
+				))
+			}
+		}
+		fields = append(fields, fieldCode)
+	}
+
+	b := strings.Builder{}
+	fmt.Fprintf(&b, "// %s holds the schema definition for the %s entity.\n", typeName, typeName)
+	fmt.Fprintf(&b, "type %s struct {\n\tent.Schema\n}\n", typeName)
+
+	fmt.Fprintf(&b, "\n// Fields of the %s.\n", typeName)
+	fmt.Fprintf(&b, "func (%s) Fields() []ent.Field {\n", typeName)
+	b.WriteString("\treturn []ent.Field{\n")
+	for _, field := range fields {
+		fmt.Fprintf(&b, "\t\t%s,\n", field)
+	}
+	b.WriteString("\t}\n}\n")
+
+	fmt.Fprintf(&b, "\n// Edges of the %s.\n", typeName)
+	fmt.Fprintf(&b, "func (%s) Edges() []ent.Edge {\n", typeName)
+	b.WriteString("\treturn []ent.Edge{\n")
+	for _, edge := range edges {
+		fmt.Fprintf(&b, "\t\t%s,\n", edge)
+	}
+	b.WriteString("\t}\n}\n")
+
+	return b.String(), nil
+}
+
+// entEdgeModel returns the name of the model referred by a field's type, which is a model, a pointer to a model or a
+// slice of them. The returned `many` is true for a slice.
+func entEdgeModel(typ Type, isModel map[string]struct{}) (model string, many bool, ok bool) {
+	if typ.SliceType != nil {
+		typ, many = typ.SliceType.Elem, true
+	}
+	if typ.PtrType != nil {
+		typ = typ.PtrType.Elem
+	}
+	if typ.QualType == nil {
+		return "", false, false
+	}
+	if _, ok := isModel[typ.QualType.Name]; !ok {
+		return "", false, false
+	}
+	return typ.QualType.Name, many, true
+}
+
+// entField renders the ent's field of a struct's field named `name` of type `typ`.
+func entField(name string, typ Type, moduleName string) string {
+	optional := ""
+	if typ.PtrType != nil {
+		typ = typ.PtrType.Elem
+		optional = ".Optional().Nillable()"
+	}
+
+	underlying := typ
+	if typ.QualType != nil && typ.QualType.Underlying != nil {
+		underlying = *typ.QualType.Underlying
+	}
+
+	switch {
+	case typ.QualType != nil && typ.QualType.Package == "time" && typ.QualType.Name == "Time":
+		return fmt.Sprintf("field.Time(%q)%s", name, optional)
+	case underlying.SliceType != nil && underlying.SliceType.Elem.PrimitiveType != nil &&
+		underlying.SliceType.Elem.PrimitiveType.Kind == PrimitiveKindByte:
+		return fmt.Sprintf("field.Bytes(%q)%s", name, optional)
+	case underlying.PrimitiveType != nil:
+		builder, ok := entFieldBuilders[underlying.PrimitiveType.Kind]
+		if !ok {
+			break
+		}
+		code := fmt.Sprintf("field.%s(%q)", builder, name)
+		if typ.QualType != nil {
+			code += fmt.Sprintf(".GoType(%s(%s))", typ.String(moduleName), primitiveTypeDefault(underlying.PrimitiveType))
+		}
+		return code + optional
+	}
+
+	value := "*new(" + typ.String(moduleName) + ")"
+	if underlying.SliceType != nil || underlying.MapType != nil || underlying.StructType != nil {
+		value = typ.String(moduleName) + "{}"
+	}
+	return fmt.Sprintf("field.JSON(%q, %s)%s", name, value, optional)
+}
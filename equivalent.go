@@ -0,0 +1,73 @@
+package gotype
+
+// FindEquivalentInterfaces reports the structurally identical interfaces declared in different packages. See
+// `TypeGenerator.FindEquivalentInterfaces` for the details.
+func FindEquivalentInterfaces(packagePatterns ...string) ([][]QualType, error) {
+	return defaultAstTypeGenerator.FindEquivalentInterfaces(packagePatterns...)
+}
+
+func (f *astTypeGenerator) FindEquivalentInterfaces(packagePatterns ...string) ([][]QualType, error) {
+	f = f.fork()
+	packages, err := f.expandPackagePatterns(packagePatterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	type namedInterface struct {
+		qualType QualType
+		iface    InterfaceType
+	}
+
+	groups := make([][]namedInterface, 0)
+	for _, packagePath := range packages {
+		names, err := f.getDeclaredTypeNames(packagePath)
+		if err != nil {
+			return nil, err
+		}
+
+		specs := make([]TypeSpec, 0, len(names))
+		for _, name := range names {
+			specs = append(specs, TypeSpec{PackagePath: packagePath, Name: name})
+		}
+		types, err := f.generateTypesFromSpecs(specs)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, typ := range types {
+			if typ.InterfaceType == nil || len(typ.InterfaceType.Methods) == 0 {
+				continue
+			}
+
+			current := namedInterface{
+				qualType: QualType{Package: packagePath, Name: specs[i].Name},
+				iface:    *typ.InterfaceType,
+			}
+			found := false
+			for j, group := range groups {
+				if identicalInterface(group[0].iface, current.iface) {
+					groups[j] = append(groups[j], current)
+					found = true
+					break
+				}
+			}
+			if !found {
+				groups = append(groups, []namedInterface{current})
+			}
+		}
+	}
+
+	results := make([][]QualType, 0)
+	for _, group := range groups {
+		packageSet := make(map[string]struct{})
+		qualTypes := make([]QualType, 0, len(group))
+		for _, item := range group {
+			packageSet[item.qualType.Package] = struct{}{}
+			qualTypes = append(qualTypes, f.rewriteQualType(item.qualType))
+		}
+		if len(packageSet) > 1 {
+			results = append(results, qualTypes)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,75 @@
+package gotype
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ErrTypeNotFound is matched by the errors returned when a type declaration can't be found, using `errors.Is`.
+var ErrTypeNotFound = errors.New("type not found")
+
+// ErrPackageNotFound is matched by the errors returned by the SourceFinders when a package can't be found, using
+// `errors.Is`, so a ChainFinder tries the next SourceFinder.
+var ErrPackageNotFound = errors.New("package not found")
+
+// ErrNondeterministicOutput is matched by the errors returned when the generator configured by `WithDeterminismCheck`
+// generates different types for the same specs, using `errors.Is`.
+var ErrNondeterministicOutput = errors.New("nondeterministic output")
+
+// TypeNotFoundError is returned when a type declaration can't be found inside its package. It describes where the
+// declaration has been searched for, so the consumers can render a helpful message.
+type TypeNotFoundError struct {
+	// PackagePath is the package where the declaration has been searched for.
+	PackagePath string
+
+	// Name is the name of the missing type.
+	Name string
+
+	// SearchedFiles contains the source files of the package that have been searched.
+	SearchedFiles []string
+
+	// FoundTypes contains the names of the types declared inside the searched files.
+	FoundTypes []string
+
+	// ParseErrors contains the syntax errors of the searched files, when tolerant parsing is enabled.
+	ParseErrors ParseErrors
+}
+
+func (e *TypeNotFoundError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "cannot find definition of %s in package %s", e.Name, e.PackagePath)
+
+	if len(e.SearchedFiles) == 0 {
+		b.WriteString(", the package has no source files")
+	} else {
+		files := make([]string, 0, len(e.SearchedFiles))
+		for _, file := range e.SearchedFiles {
+			files = append(files, filepath.Base(file))
+		}
+		fmt.Fprintf(&b, ", searched files: %s", strings.Join(files, ", "))
+	}
+
+	if len(e.FoundTypes) > 0 {
+		fmt.Fprintf(&b, ", declared types: %s", strings.Join(e.FoundTypes, ", "))
+	}
+
+	if len(e.ParseErrors) > 0 {
+		fmt.Fprintf(&b, ", some source files can't be parsed: %s", e.ParseErrors)
+	}
+	return b.String()
+}
+
+// Is makes `errors.Is(err, ErrTypeNotFound)` and `errors.Is(err, ErrDeclarationNotFound)` report true.
+func (e *TypeNotFoundError) Is(target error) bool {
+	return target == ErrTypeNotFound || target == ErrDeclarationNotFound
+}
+
+// Unwrap returns the syntax errors of the searched files, if any.
+func (e *TypeNotFoundError) Unwrap() error {
+	if len(e.ParseErrors) == 0 {
+		return nil
+	}
+	return e.ParseErrors
+}
@@ -0,0 +1,159 @@
+package gotype
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+)
+
+// fakeTagKey is the struct tag key used to choose the kind of fake data of a field, like `fake:"email"`.
+const fakeTagKey = "fake"
+
+// fakeStringValues contains the expressions producing the fake strings of each kind supported by the `fake` tag. The
+// kind of a field without the tag is guessed from its name.
+var fakeStringValues = map[string]string{
+	"email": `fmt.Sprintf("user%d@example.com", r.Intn(100000))`,
+	"uuid": `fmt.Sprintf("%08x-%04x-4%03x-%04x-%012x", ` +
+		`r.Uint32(), r.Intn(0x10000), r.Intn(0x1000), 0x8000|r.Intn(0x4000), r.Int63n(1<<48))`,
+	"name":  `[]string{"Alice", "Bob", "Carol", "Dave", "Eve", "Frank"}[r.Intn(6)]`,
+	"url":   `fmt.Sprintf("https://example.com/%d", r.Intn(100000))`,
+	"phone": `fmt.Sprintf("+1%010d", r.Int63n(10000000000))`,
+}
+
+// fakeTime is the expression producing a fake time between 2000 and 2030.
+const fakeTime = "time.Unix(946684800+r.Int63n(946684800), 0).UTC()"
+
+// RenderFaker renders the Golang's source code of a function producing fake instances of the struct type named
+// `typeName`, whose definition is `typ`. The function is named `Fake<typeName>` and takes a `*rand.Rand` as the
+// source of randomness, so the produced data is reproducible.
+//
+// The fake data of a field is chosen using the `fake` tag: `fake:"email"`, `fake:"uuid"`, `fake:"name"`,
+// `fake:"url"` and `fake:"phone"` produce realistic strings, and `fake:"time=<layout>"` produces a string containing
+// a time formatted using the layout. `fake:"-"` leaves the field with its zero value. Without the tag, the kind is
+// guessed from the field's name. The QualTypes are faked using their `Underlying` definitions filled by the deep
+// resolution, and left with their zero values when they're not resolved, except for `time.Time` and `time.Duration`.
+//
+// The rendered code only contains the function, the package clause and imports are left to the caller. It may use
+// the "fmt", "math/rand" and "time" packages.
+func RenderFaker(typeName string, typ Type, moduleName string) (string, error) {
+	if typ.StructType == nil {
+		return "", fmt.Errorf("cannot render faker of a non-struct type: %s", typ.String(moduleName))
+	}
+	if typ.IsGeneric() {
+		return "", fmt.Errorf("cannot render faker of the generic type %s", typeName)
+	}
+
+	b := strings.Builder{}
+	fmt.Fprintf(&b, "func Fake%s(r *rand.Rand) %s {\n", typeName, typeName)
+	fmt.Fprintf(&b, "\treturn %s{\n", typeName)
+	for _, field := range typ.StructType.Fields {
+		value, ok := fakeFieldValue(field, moduleName)
+		if ok {
+			fmt.Fprintf(&b, "\t\t%s: %s,\n", field.Name, value)
+		}
+	}
+	b.WriteString("\t}\n}\n")
+
+	return b.String(), nil
+}
+
+// fakeFieldValue returns the expression producing the fake value of a struct's field. It returns false when the field
+// should be left with its zero value.
+func fakeFieldValue(field TypeField, moduleName string) (string, bool) {
+	kind, ok := field.Tag.Lookup(fakeTagKey)
+	if kind == "-" {
+		return "", false
+	}
+	if !ok {
+		kind = strings.ToLower(field.Name)
+	}
+	return fakeValue(field.Type, kind, moduleName), true
+}
+
+// fakeValue returns the expression producing a fake value of `typ`. The `kind` is only used for strings.
+func fakeValue(typ Type, kind string, moduleName string) string {
+	switch {
+	case typ.PrimitiveType != nil:
+		return fakePrimitiveValue(typ.PrimitiveType.Kind, kind)
+	case typ.QualType != nil:
+		return fakeQualTypeValue(*typ.QualType, kind, moduleName)
+	case typ.SliceType != nil:
+		elem := fakeValue(typ.SliceType.Elem, kind, moduleName)
+		return fmt.Sprintf("%s{%s, %s}", typ.String(moduleName), elem, elem)
+	case typ.MapType != nil:
+		key := fakeValue(typ.MapType.Key, "", moduleName)
+		elem := fakeValue(typ.MapType.Elem, kind, moduleName)
+		return fmt.Sprintf("%s{%s: %s}", typ.String(moduleName), key, elem)
+	case typ.PtrType != nil:
+		elem := fakeValue(typ.PtrType.Elem, kind, moduleName)
+		return fmt.Sprintf("func() %s { v := %s; return &v }()", typ.String(moduleName), elem)
+	case typ.StructType != nil:
+		return fakeStructValue(typ.String(moduleName), *typ.StructType, true, moduleName)
+	case typ.ArrayType != nil:
+		return typ.String(moduleName) + "{}"
+	}
+	return "nil"
+}
+
+func fakePrimitiveValue(primitiveKind PrimitiveKind, kind string) string {
+	switch primitiveKind {
+	case PrimitiveKindBool:
+		return "r.Intn(2) == 1"
+	case PrimitiveKindString:
+		if layout := strings.TrimPrefix(kind, "time="); layout != kind {
+			return fmt.Sprintf("%s.Format(%q)", fakeTime, layout)
+		}
+		if value, ok := fakeStringValues[kind]; ok {
+			return value
+		}
+		return `fmt.Sprintf("lorem-%d", r.Intn(1000))`
+	case PrimitiveKindFloat32, PrimitiveKindFloat64:
+		return fmt.Sprintf("%s(r.Float64() * 100)", primitiveKind)
+	case PrimitiveKindComplex64, PrimitiveKindComplex128:
+		return fmt.Sprintf("%s(complex(r.Float64(), r.Float64()))", primitiveKind)
+	case PrimitiveKindError:
+		return "nil"
+	}
+	return fmt.Sprintf("%s(r.Intn(100))", primitiveKind)
+}
+
+func fakeQualTypeValue(q QualType, kind string, moduleName string) string {
+	if q.Package == "time" && len(q.TypeArgs) == 0 {
+		switch q.Name {
+		case "Time":
+			return fakeTime
+		case "Duration":
+			return "time.Duration(r.Int63n(int64(time.Hour)))"
+		}
+	}
+
+	name := q.Type().String(moduleName)
+	if q.Underlying == nil {
+		return "*new(" + name + ")"
+	}
+
+	underlying := *q.Underlying
+	switch {
+	case underlying.StructType != nil:
+		local := q.ShortPackagePath == moduleName
+		return fakeStructValue(name, *underlying.StructType, local, moduleName)
+	case underlying.PrimitiveType != nil, underlying.SliceType != nil, underlying.MapType != nil:
+		return fmt.Sprintf("%s(%s)", name, fakeValue(underlying, kind, moduleName))
+	}
+	return "*new(" + name + ")"
+}
+
+// fakeStructValue renders a composite literal of a struct type named `name`. The unexported fields are skipped unless
+// the struct is `local`, i.e. declared inside the package where the code is rendered.
+func fakeStructValue(name string, structType StructType, local bool, moduleName string) string {
+	values := make([]string, 0, len(structType.Fields))
+	for _, field := range structType.Fields {
+		if !local && !ast.IsExported(field.Name) {
+			continue
+		}
+		if value, ok := fakeFieldValue(field, moduleName); ok {
+			values = append(values, field.Name+": "+value)
+		}
+	}
+	return name + "{" + strings.Join(values, ", ") + "}"
+}
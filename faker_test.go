@@ -0,0 +1,66 @@
+package gotype
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderFaker(t *testing.T) {
+	types, err := NewGenerator(WithDeepResolution()).GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: testdataPackage + "/fake", Name: "User"},
+	)
+	require.NoError(t, err)
+
+	code, err := RenderFaker("User", types[0], "fake")
+	require.NoError(t, err)
+
+	_, err = parser.ParseFile(token.NewFileSet(), "", "package fake\n"+code, 0)
+	require.NoError(t, err, code)
+
+	assert.Contains(t, code, "func FakeUser(r *rand.Rand) User {")
+	assert.Contains(t, code, "\t\tID: fmt.Sprintf(\"%08x-%04x-4%03x-%04x-%012x\"")
+	assert.Contains(t, code, "\t\tContact: fmt.Sprintf(\"user%d@example.com\", r.Intn(100000)),\n")
+	assert.Contains(t, code, "\t\tName: []string{\"Alice\"")
+	assert.Contains(t, code, "\t\tBirthday: time.Unix(946684800+r.Int63n(946684800), 0).UTC().Format(\"2006-01-02\"),\n")
+	assert.Contains(t, code, "\t\tAge: int(r.Intn(100)),\n")
+	assert.Contains(t, code, "\t\tStatus: Status(fmt.Sprintf(\"lorem-%d\", r.Intn(1000))),\n")
+	assert.Contains(t, code, "\t\tAddress: func() *Address { v := Address{City: fmt.Sprintf(\"lorem-%d\", r.Intn(1000)), "+
+		"street: fmt.Sprintf(\"lorem-%d\", r.Intn(1000))}; return &v }(),\n")
+	assert.Contains(t, code, "\t\tCreatedAt: time.Unix(946684800+r.Int63n(946684800), 0).UTC(),\n")
+	assert.NotContains(t, code, "Secret")
+
+	_, err = RenderFaker("Reader", Type{InterfaceType: &InterfaceType{}}, "")
+	assert.Error(t, err)
+}
+
+func TestRenderFuzzHarness(t *testing.T) {
+	types, err := NewGenerator(WithDeepResolution()).GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: testdataPackage + "/fake", Name: "User"},
+	)
+	require.NoError(t, err)
+
+	code, err := RenderFuzzHarness("User", types[0], "processUser", 5, "fake")
+	require.NoError(t, err)
+
+	_, err = parser.ParseFile(token.NewFileSet(), "", "package fake\n"+code, 0)
+	require.NoError(t, err, code)
+
+	assert.Contains(t, code, "func encodeUser(v User) []interface{} {\n\treturn []interface{}{\n\t\tv.ID,\n")
+	assert.Contains(t, code, "\t\tstring(v.Status),\n")
+	assert.Contains(t, code, "\t\tv.CreatedAt.UnixNano(),\n")
+	assert.Contains(t, code, "\t\tStatus: Status(status),\n")
+	assert.Contains(t, code, "\t\tCreatedAt: time.Unix(0, createdAt).UTC(),\n")
+	assert.NotContains(t, code, "Address")
+	assert.NotContains(t, code, "Tags")
+	assert.Contains(t, code, "func FuzzProcessUser(f *testing.F) {\n\tf.Add(encodeUser(User{})...)\n")
+	assert.Contains(t, code, "\t\tf.Add(encodeUser(FakeUser(rand.New(rand.NewSource(seed))))...)\n")
+	assert.Contains(t, code, "\tf.Fuzz(func(t *testing.T, id string, contact string, ")
+	assert.Contains(t, code, "\t\tprocessUser(decodeUser(id, contact, ")
+
+	assert.Equal(t, "urlPath", fuzzParamName("URLPath"))
+	assert.Equal(t, "type_", fuzzParamName("Type"))
+}
@@ -3,71 +3,187 @@ package gotype
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 
 	"golang.org/x/mod/modfile"
 	"golang.org/x/mod/module"
 )
 
 type defaultSourceFinder struct {
-	cache map[string][]string
+	// mu guards the caches, so the SourceFinder can be used by multiple goroutines concurrently. It's only held while
+	// the caches are read or written, so the packages are found concurrently.
+	mu sync.Mutex
+
+	cache  map[string][]string
+	logger Logger
+
+	// buildConfig contains the directory and environment used to find the packages. It's nil by default, see
+	// `WithConfig`.
+	buildConfig *Config
+
+	// fetchModules enables downloading the required modules missing from the module cache. See `WithModuleFetching`.
+	fetchModules bool
+
+	// modules maps the package paths having a version, like "example.com/mod/pkg@v1.2.3", to the modules found
+	// containing them, see `findModule`.
+	modules map[string]module.Version
 }
 
-func (s *defaultSourceFinder) GetPackageSourceFiles(packagePath string) ([]string, error) {
-	if s.cache == nil {
-		s.cache = make(map[string][]string)
+func (s *defaultSourceFinder) logf(format string, args ...interface{}) {
+	if s.logger != nil {
+		s.logger.Printf(format, args...)
 	}
+}
 
-	if sourceFiles, ok := s.cache[packagePath]; ok {
+func (s *defaultSourceFinder) GetPackageSourceFiles(packagePath string) ([]string, error) {
+	s.mu.Lock()
+	sourceFiles, ok := s.cache[packagePath]
+	s.mu.Unlock()
+	if ok {
+		s.logf("source files cache hit: package %s", packagePath)
 		return sourceFiles, nil
 	}
+	s.logf("source files cache miss: package %s", packagePath)
 
 	packageDir, err := s.findPackageDir(packagePath)
 	if err != nil {
 		return nil, err
 	}
+	if stat, err := os.Stat(packageDir); err != nil || !stat.IsDir() {
+		return nil, fmt.Errorf("cannot find package %s in %s: %w", packagePath, packageDir, ErrPackageNotFound)
+	}
+	s.logf("package %s found in %s", packagePath, packageDir)
 
-	goSources, err := s.getGoSourcesInsideDir(packageDir)
+	goSources, err := getGoSourcesInsideDir(s.buildConfig, packageDir)
 	if err != nil {
 		return nil, err
 	}
+
+	// the lock isn't held while the package is found, so the goroutines missing the cache at the same time find the
+	// package each, storing the same files.
+	s.mu.Lock()
+	if s.cache == nil {
+		s.cache = make(map[string][]string)
+	}
 	s.cache[packagePath] = goSources
+	s.mu.Unlock()
 
 	return goSources, nil
 }
 
-func (s *defaultSourceFinder) findPackageDir(packagePath string) (string, error) {
-	moduleFile, goModFilePath, err := s.findModuleFile()
-	if err != nil {
-		return "", err
+// ListPackages returns the packages matched by `pattern`. A pattern ending with "/..." matches the package and all of
+// its sub packages, other patterns match a single package. Like the go tool, directories named "testdata" or
+// "vendor" and directories whose names start with "." or "_" are skipped.
+func (s *defaultSourceFinder) ListPackages(pattern string) ([]string, error) {
+	if !strings.HasSuffix(pattern, "/...") {
+		return []string{pattern}, nil
 	}
 
-	if packageDir, ok := s.findPackageDirByModulePath(
-		packagePath,
-		moduleFile.Module.Mod.Path,
-		filepath.Dir(goModFilePath),
-	); ok {
-		return packageDir, nil
+	rootPackage := strings.TrimSuffix(pattern, "/...")
+	rootDir, err := s.findPackageDir(rootPackage)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, req := range moduleFile.Require {
-		modPath, err := s.findPackagePathByVersion(req.Mod)
+	return listPackagesInsideDir(s.buildConfig, rootPackage, rootDir)
+}
+
+// listPackagesInsideDir returns the package `rootPackage` found in `rootDir`, and its sub packages found in the sub
+// directories, when they have source files matching `buildConfig`.
+func listPackagesInsideDir(buildConfig *Config, rootPackage, rootDir string) ([]string, error) {
+	packages := make([]string, 0)
+	if err := filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
-			return "", err
+			return fmt.Errorf("got error when listing file: %w", err)
+		}
+
+		if !info.IsDir() {
+			return nil
 		}
 
-		if packageDir, ok := s.findPackageDirByModulePath(packagePath, req.Mod.Path, modPath); ok {
+		name := info.Name()
+		if path != rootDir && (name == "testdata" || name == "vendor" ||
+			strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+			return filepath.SkipDir
+		}
+
+		goSources, err := getGoSourcesInsideDir(buildConfig, path)
+		if err != nil {
+			return err
+		}
+		if len(goSources) == 0 {
+			return nil
+		}
+
+		rel, err := filepath.Rel(rootDir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			packages = append(packages, rootPackage)
+		} else {
+			packages = append(packages, rootPackage+"/"+filepath.ToSlash(rel))
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("error while traversing the directories: %w", err)
+	}
+
+	return packages, nil
+}
+
+func (s *defaultSourceFinder) findPackageDir(packagePath string) (string, error) {
+	if packagePath, version := splitPackageVersion(packagePath); version != "" {
+		return s.findPinnedPackageDir(packagePath, version)
+	}
+	mainModules, err := s.findMainModules()
+	if err != nil {
+		return "", err
+	}
+
+	for _, mod := range mainModules {
+		if packageDir, ok := s.findPackageDirByModulePath(packagePath, mod.path, mod.dir); ok {
 			return packageDir, nil
 		}
 	}
 
+	for _, mod := range mainModules {
+		for _, req := range mod.file.Require {
+			if !strings.HasPrefix(packagePath, req.Mod.Path) {
+				continue
+			}
+
+			modPath, err := s.findPackagePathByVersion(req.Mod)
+			if err != nil && s.fetchModules {
+				var downloaded downloadedModule
+				downloaded, err = s.downloadModule(req.Mod.Path, req.Mod.Version)
+				if err == nil {
+					s.logf("module %s downloaded into %s", req.Mod.String(), downloaded.Dir)
+				}
+				modPath = downloaded.Dir
+			}
+			if err != nil {
+				return "", err
+			}
+
+			if packageDir, ok := s.findPackageDirByModulePath(packagePath, req.Mod.Path, modPath); ok {
+				return packageDir, nil
+			}
+		}
+	}
+
 	modPath, err := s.findPackagePathByVersion(module.Version{})
 	if err != nil {
 		return "", err
@@ -77,55 +193,308 @@ func (s *defaultSourceFinder) findPackageDir(packagePath string) (string, error)
 		return packageDir, nil
 	}
 
-	return "", fmt.Errorf("package %s cannot be found in go.mod file", packagePath)
+	return "", fmt.Errorf("cannot find package %s in go.mod file: %w", packagePath, ErrPackageNotFound)
+}
+
+// findPinnedPackageDir finds the directory of the package inside the module cache, in the module's `version`. The
+// module is downloaded using the go tool when it's not in the cache yet.
+func (s *defaultSourceFinder) findPinnedPackageDir(packagePath, version string) (string, error) {
+	modulePaths := make([]string, 0)
+	for modulePath := packagePath; modulePath != "." && modulePath != "/"; modulePath = path.Dir(modulePath) {
+		modulePaths = append(modulePaths, modulePath)
+	}
+
+	cacheDir := s.findModuleCacheDir()
+	for _, modulePath := range modulePaths {
+		escapedPath, err := module.EscapePath(modulePath)
+		if err != nil {
+			continue
+		}
+		escapedVersion, err := module.EscapeVersion(version)
+		if err != nil {
+			return "", fmt.Errorf("invalid version %s of package %s: %w", version, packagePath, err)
+		}
+
+		moduleDir := filepath.Join(cacheDir, escapedPath+"@"+escapedVersion)
+		if _, ok := s.findPackagePathFromCandidatePath(moduleDir); ok {
+			s.logf("module %s@%s found in %s", modulePath, version, moduleDir)
+			return s.modulePackageDir(packagePath, version, module.Version{Path: modulePath, Version: version}, moduleDir), nil
+		}
+	}
+
+	var downloadErr error
+	for _, modulePath := range modulePaths {
+		downloaded, err := s.downloadModule(modulePath, version)
+		if err != nil {
+			// the error of the longest module path is reported, since the shorter ones are usually not modules.
+			if downloadErr == nil {
+				downloadErr = err
+			}
+			continue
+		}
+		s.logf("module %s@%s downloaded into %s", modulePath, downloaded.Version, downloaded.Dir)
+		mod := module.Version{Path: modulePath, Version: downloaded.Version}
+		return s.modulePackageDir(packagePath, version, mod, downloaded.Dir), nil
+	}
+	return "", fmt.Errorf("cannot find package %s@%s: %w", packagePath, version, downloadErr)
+}
+
+// modulePackageDir returns the directory of the package inside the directory of its module `mod`, found for the
+// package path having the `version`, and records the module, see `findModule`.
+func (s *defaultSourceFinder) modulePackageDir(
+	packagePath, version string,
+	mod module.Version,
+	moduleDir string,
+) string {
+	s.mu.Lock()
+	if s.modules == nil {
+		s.modules = make(map[string]module.Version)
+	}
+	s.modules[packagePath+"@"+version] = mod
+	s.mu.Unlock()
+	return filepath.Join(moduleDir, strings.TrimPrefix(packagePath, mod.Path))
+}
+
+// findModule returns the module containing the package whose path has a version, like "example.com/mod/pkg@v1.2.3",
+// when the package is found already.
+func (s *defaultSourceFinder) findModule(packagePath string) (module.Version, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	mod, ok := s.modules[packagePath]
+	return mod, ok
+}
+
+// moduleFinder is implemented by the SourceFinders finding the packages whose paths have a version inside the module
+// cache, and by the SourceFinders wrapping them, so the generator can pin the versions of the modules, see
+// `modulePins`.
+type moduleFinder interface {
+	findModule(packagePath string) (module.Version, bool)
+}
+
+func (s *ChainFinder) findModule(packagePath string) (module.Version, bool) {
+	return findModule(packagePath, s.finders...)
+}
+
+func (s *MergeFinder) findModule(packagePath string) (module.Version, bool) {
+	return findModule(packagePath, s.finders...)
+}
+
+func (s *archiveSourceFinder) findModule(packagePath string) (module.Version, bool) {
+	return findModule(packagePath, s.fallback)
+}
+
+func (s *gitRevisionSourceFinder) findModule(packagePath string) (module.Version, bool) {
+	return findModule(packagePath, s.fallback)
 }
 
-func (s *defaultSourceFinder) findModuleFile() (*modfile.File, string, error) {
-	goModFilePath, err := s.findGoModFile()
+func (s *watchedSourceFinder) findModule(packagePath string) (module.Version, bool) {
+	return findModule(packagePath, s.fallback)
+}
+
+// findModule returns the module found by the first of the `finders` knowing the module of the package.
+func findModule(packagePath string, finders ...SourceFinder) (module.Version, bool) {
+	for _, finder := range finders {
+		if moduleFinder, ok := finder.(moduleFinder); ok {
+			if mod, ok := moduleFinder.findModule(packagePath); ok {
+				return mod, true
+			}
+		}
+	}
+	return module.Version{}, false
+}
+
+// findModuleCacheDir returns the directory of the module cache, following the go tool's rules.
+func (s *defaultSourceFinder) findModuleCacheDir() string {
+	if modCache, ok := s.buildConfig.lookupEnv("GOMODCACHE"); ok && modCache != "" {
+		return normalizePath(modCache)
+	}
+	if gopath, ok := s.buildConfig.lookupEnv("GOPATH"); ok && gopath != "" {
+		return normalizePath(filepath.Join(filepath.SplitList(gopath)[0], "pkg", "mod"))
+	}
+	homedir, _ := os.UserHomeDir()
+	return normalizePath(filepath.Join(homedir, "go", "pkg", "mod"))
+}
+
+// downloadedModule is the output of `go mod download -json`.
+type downloadedModule struct {
+	Version string
+	Dir     string
+	Error   string
+}
+
+// downloadModule downloads the module into the module cache using the go tool, which uses the configured proxy.
+func (s *defaultSourceFinder) downloadModule(modulePath, version string) (downloadedModule, error) {
+	cmd := exec.Command("go", "mod", "download", "-json", modulePath+"@"+version)
+	// the command runs outside of the current module, so its go.mod and go.sum files aren't modified.
+	cmd.Dir = os.TempDir()
+	cmd.Env = append(s.buildConfig.environ(), "GO111MODULE=on")
+	output, err := cmd.Output()
+
+	downloaded := downloadedModule{}
+	if jsonErr := json.Unmarshal(output, &downloaded); jsonErr != nil {
+		if err == nil {
+			err = jsonErr
+		}
+		return downloadedModule{}, fmt.Errorf("cannot download module %s@%s: %w", modulePath, version, err)
+	}
+	if downloaded.Error != "" {
+		return downloadedModule{}, fmt.Errorf("cannot download module %s@%s: %s", modulePath, version, downloaded.Error)
+	}
+	return downloaded, nil
+}
+
+// splitPackageVersion splits a package path like "example.com/mod/pkg@v1.2.3" into the package's path and the
+// version of its module. The version is empty when the package path doesn't have one.
+func splitPackageVersion(packagePath string) (string, string) {
+	if i := strings.LastIndex(packagePath, "@"); i >= 0 {
+		return packagePath[:i], packagePath[i+1:]
+	}
+	return packagePath, ""
+}
+
+// mainModule is a module whose packages are found in the local directories, like the module of the working
+// directory or the modules of its workspace.
+type mainModule struct {
+	path string
+	dir  string
+	file *modfile.File
+}
+
+// findMainModules returns the main modules of the working directory. When the working directory is inside a
+// workspace, that is, a go.work file is found inside it or one of its parents, the main modules are the modules used
+// by the workspace. Otherwise, the main module is the module of the closest go.mod file. Like the go tool, the GOWORK
+// environment variable sets the path of the go.work file, or disables the workspace when it's "off". The modules are
+// sorted by their paths, the longest first, so a package is found inside the innermost module.
+func (s *defaultSourceFinder) findMainModules() ([]mainModule, error) {
+	wd, err := s.buildConfig.workingDir()
 	if err != nil {
-		return nil, "", err
+		return nil, fmt.Errorf("cannot get current working dir: %w", err)
+	}
+
+	goWorkPath, _ := s.buildConfig.lookupEnv("GOWORK")
+	if goWorkPath != "" && goWorkPath != "off" {
+		goWorkPath = normalizePath(goWorkPath)
+	}
+	if goWorkPath == "" {
+		goWorkPath, _ = findEnclosingFile(wd, "go.work")
+	}
+	if goWorkPath == "" || goWorkPath == "off" {
+		goModPath, ok := findEnclosingFile(wd, "go.mod")
+		if !ok {
+			return nil, fmt.Errorf("no go.mod file found")
+		}
+		mod, err := readMainModule(filepath.Dir(goModPath))
+		if err != nil {
+			return nil, err
+		}
+		s.logf("main module %s found in %s", mod.path, mod.dir)
+		return []mainModule{mod}, nil
 	}
 
-	goModFile, err := os.Open(goModFilePath)
+	moduleDirs, err := readWorkspaceModuleDirs(goWorkPath)
 	if err != nil {
-		return nil, "", fmt.Errorf("cannot open go.mod file: %w", err)
+		return nil, err
+	}
+	mainModules := make([]mainModule, 0, len(moduleDirs))
+	seen := make(map[string]bool)
+	for _, moduleDir := range moduleDirs {
+		// the directories differing only by their case are the same directory on the case-insensitive file systems.
+		if seen[pathKey(moduleDir)] {
+			continue
+		}
+		seen[pathKey(moduleDir)] = true
+
+		mod, err := readMainModule(moduleDir)
+		if err != nil {
+			return nil, err
+		}
+		s.logf("main module %s of workspace %s found in %s", mod.path, goWorkPath, mod.dir)
+		mainModules = append(mainModules, mod)
+	}
+	sort.SliceStable(mainModules, func(i, j int) bool {
+		return len(mainModules[i].path) > len(mainModules[j].path)
+	})
+	return mainModules, nil
+}
+
+// findEnclosingFile looks for the file named `name` inside `dir` and its parents, and returns the closest one.
+func findEnclosingFile(dir, name string) (string, bool) {
+	for {
+		filePath := filepath.Join(dir, name)
+		if stat, err := os.Stat(filePath); err == nil && !stat.IsDir() {
+			return filePath, true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
 	}
-	defer goModFile.Close()
+}
 
-	goModBytes, err := ioutil.ReadAll(goModFile)
+// readMainModule reads the go.mod file of the module inside `moduleDir`.
+func readMainModule(moduleDir string) (mainModule, error) {
+	goModBytes, err := ioutil.ReadFile(filepath.Join(moduleDir, "go.mod"))
 	if err != nil {
-		return nil, "", fmt.Errorf("cannot read go.mod file: %w", err)
+		return mainModule{}, fmt.Errorf("cannot read go.mod file: %w", err)
 	}
 
 	moduleFile, err := modfile.Parse("go.mod", goModBytes, nil)
 	if err != nil {
-		return nil, "", fmt.Errorf("cannot parse go.mod file: %w", err)
+		return mainModule{}, fmt.Errorf("cannot parse go.mod file: %w", err)
+	}
+	if moduleFile.Module == nil {
+		return mainModule{}, fmt.Errorf("go.mod file of %s doesn't declare the module path", moduleDir)
 	}
 
-	return moduleFile, goModFilePath, nil
+	return mainModule{path: moduleFile.Module.Mod.Path, dir: moduleDir, file: moduleFile}, nil
 }
 
-func (s *defaultSourceFinder) findGoModFile() (string, error) {
-	wd, err := os.Getwd()
+// readWorkspaceModuleDirs returns the directories of the modules declared by the `use` directives of a go.work file.
+func readWorkspaceModuleDirs(goWorkPath string) ([]string, error) {
+	goWorkBytes, err := ioutil.ReadFile(goWorkPath)
 	if err != nil {
-		return "", fmt.Errorf("cannot get current working dir: %w", err)
+		return nil, fmt.Errorf("cannot read go.work file: %w", err)
 	}
 
-	maxDepth := 15
-	for i := 0; i < maxDepth; i++ {
-		goModPath := path.Join(wd, "go.mod")
-		if _, err := os.Stat(goModPath); errors.Is(err, os.ErrNotExist) {
-			if wd == "/" {
-				break
+	// the go.work files share the syntax of the go.mod files, and their unknown directives are kept by ParseLax.
+	workFile, err := modfile.ParseLax("go.work", goWorkBytes, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse go.work file: %w", err)
+	}
+
+	uses := make([][]string, 0)
+	for _, stmt := range workFile.Syntax.Stmt {
+		switch stmt := stmt.(type) {
+		case *modfile.Line:
+			if len(stmt.Token) == 2 && stmt.Token[0] == "use" {
+				uses = append(uses, stmt.Token[1:])
+			}
+		case *modfile.LineBlock:
+			if len(stmt.Token) == 1 && stmt.Token[0] == "use" {
+				for _, line := range stmt.Line {
+					uses = append(uses, line.Token)
+				}
 			}
-			wd = path.Join(wd, "..")
-			continue
 		}
-
-		return goModPath, nil
 	}
 
-	return "", fmt.Errorf("no go.mod file found")
+	moduleDirs := make([]string, 0, len(uses))
+	for _, use := range uses {
+		if len(use) != 1 {
+			return nil, fmt.Errorf("cannot parse go.work file: invalid use directive %s", strings.Join(use, " "))
+		}
+		moduleDir := use[0]
+		if unquoted, err := strconv.Unquote(moduleDir); err == nil {
+			moduleDir = unquoted
+		}
+		if !filepath.IsAbs(moduleDir) {
+			moduleDir = filepath.Join(filepath.Dir(goWorkPath), moduleDir)
+		}
+		moduleDirs = append(moduleDirs, normalizePath(moduleDir))
+	}
+	return moduleDirs, nil
 }
 
 func (*defaultSourceFinder) findPackageDirByModulePath(
@@ -142,7 +511,13 @@ func (*defaultSourceFinder) findPackageDirByModulePath(
 func (s *defaultSourceFinder) findPackagePathByVersion(modVer module.Version) (string, error) {
 	lookupDir := make([]string, 0)
 
-	if gohome, ok := os.LookupEnv("GOHOME"); ok {
+	if escapedPath, err := module.EscapePath(modVer.Path); err == nil && modVer.Version != "" {
+		if escapedVersion, err := module.EscapeVersion(modVer.Version); err == nil {
+			lookupDir = append(lookupDir, filepath.Join(s.findModuleCacheDir(), escapedPath+"@"+escapedVersion))
+		}
+	}
+
+	if gohome, ok := s.buildConfig.lookupEnv("GOHOME"); ok {
 		lookupDir = append(lookupDir, filepath.Join(gohome, "pkg", "mod", modVer.Path+"@"+modVer.Version))
 	}
 
@@ -153,16 +528,19 @@ func (s *defaultSourceFinder) findPackagePathByVersion(modVer module.Version) (s
 	}
 	lookupDir = append(lookupDir, filepath.Join(homedir, "go", "pkg", "mod", modVer.Path+"@"+modVer.Version))
 
-	if goroot, ok := os.LookupEnv("GOROOT"); ok {
-		lookupDir = append(lookupDir, filepath.Join(goroot, "src"))
-	}
+	// the standard library is looked up without a module version, a missing module is never found inside it.
+	if modVer.Version == "" {
+		if goroot, ok := s.buildConfig.lookupEnv("GOROOT"); ok {
+			lookupDir = append(lookupDir, filepath.Join(goroot, "src"))
+		}
 
-	if goInstallDir, err := s.findInstalledGoDir(); err == nil {
-		lookupDir = append(lookupDir, goInstallDir)
+		if goInstallDir, err := s.findInstalledGoDir(); err == nil {
+			lookupDir = append(lookupDir, goInstallDir)
+		}
+		lookupDir = append(lookupDir, filepath.Join("/", "usr", "local", "go", "src"))
 	}
-	lookupDir = append(lookupDir, filepath.Join("/", "usr", "local", "go", "src"))
 
-	if gohome, ok := os.LookupEnv("GOHOME"); ok {
+	if gohome, ok := s.buildConfig.lookupEnv("GOHOME"); ok {
 		lookupDir = append(lookupDir, filepath.Join(gohome, "src", "mod", modVer.Path+"@"+modVer.Version))
 	}
 
@@ -172,7 +550,7 @@ func (s *defaultSourceFinder) findPackagePathByVersion(modVer module.Version) (s
 		}
 	}
 
-	return "", fmt.Errorf("cannot find module %s", modVer.String())
+	return "", fmt.Errorf("cannot find module %s: %w", modVer.String(), ErrPackageNotFound)
 }
 
 func (*defaultSourceFinder) findInstalledGoDir() (string, error) {
@@ -202,7 +580,8 @@ func (*defaultSourceFinder) findPackagePathFromCandidatePath(modulePath string)
 	return modulePath, true
 }
 
-func (*defaultSourceFinder) getGoSourcesInsideDir(dir string) ([]string, error) {
+// getGoSourcesInsideDir returns the source files inside `dir` matching `buildConfig`, see `Config.matchFile`.
+func getGoSourcesInsideDir(buildConfig *Config, dir string) ([]string, error) {
 	goSources := make([]string, 0)
 	if err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if path == dir {
@@ -217,7 +596,13 @@ func (*defaultSourceFinder) getGoSourcesInsideDir(dir string) ([]string, error)
 			return filepath.SkipDir
 		}
 
-		if filepath.Ext(info.Name()) == ".go" {
+		match, err := buildConfig.matchFile(dir, info.Name(), func(path string) (io.ReadCloser, error) {
+			return os.Open(path)
+		})
+		if err != nil {
+			return fmt.Errorf("cannot read build constraints of %s: %w", path, err)
+		}
+		if match {
 			goSources = append(goSources, path)
 		}
 
@@ -0,0 +1,264 @@
+package gotype
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// NewModuleFinder returns the SourceFinder used by default, which finds the packages of the main modules of the
+// working directory, that is, the modules of its workspace or of its go.mod file, of their required modules inside
+// the module cache, and of the standard library. A nil `buildConfig` uses the process' working directory and
+// environment, and reads all the ".go" files, see `WithConfig`.
+func NewModuleFinder(buildConfig *Config) SourceFinder {
+	return &defaultSourceFinder{buildConfig: buildConfig}
+}
+
+// NewProxyFinder returns a SourceFinder like NewModuleFinder, which also downloads the required modules missing from
+// the module cache using the go tool, that is, from the configured module proxy. See `WithModuleFetching`.
+func NewProxyFinder(buildConfig *Config) SourceFinder {
+	return &defaultSourceFinder{buildConfig: buildConfig, fetchModules: true}
+}
+
+// NewDirFinder returns a SourceFinder which finds the package `rootPackage` inside `dir`, and its sub packages inside
+// the sub directories, like a module whose go.mod file isn't available or a directory of generated code. The other
+// packages aren't found. The source files match `buildConfig`, a nil `buildConfig` reads all the ".go" files.
+func NewDirFinder(rootPackage, dir string, buildConfig *Config) SourceFinder {
+	return &dirSourceFinder{rootPackage: rootPackage, dir: normalizePath(dir), buildConfig: buildConfig}
+}
+
+// NewVendorFinder returns a SourceFinder which finds the packages inside the vendor directory `vendorDir`, where each
+// package is stored inside the directory named after its path. See `NewDirFinder`.
+func NewVendorFinder(vendorDir string, buildConfig *Config) SourceFinder {
+	return &dirSourceFinder{dir: normalizePath(vendorDir), buildConfig: buildConfig}
+}
+
+// dirSourceFinder finds the packages inside a directory, see `NewDirFinder`. An empty `rootPackage` matches all the
+// packages, like inside a vendor directory.
+type dirSourceFinder struct {
+	rootPackage string
+	dir         string
+	buildConfig *Config
+}
+
+func (s *dirSourceFinder) GetPackageSourceFiles(packagePath string) ([]string, error) {
+	packageDir, err := s.findPackageDir(packagePath)
+	if err != nil {
+		return nil, err
+	}
+	return getGoSourcesInsideDir(s.buildConfig, packageDir)
+}
+
+// ListPackages returns the packages matched by `pattern`, see `defaultSourceFinder.ListPackages`.
+func (s *dirSourceFinder) ListPackages(pattern string) ([]string, error) {
+	if !strings.HasSuffix(pattern, "/...") {
+		return []string{pattern}, nil
+	}
+
+	rootPackage := strings.TrimSuffix(pattern, "/...")
+	rootDir, err := s.findPackageDir(rootPackage)
+	if err != nil {
+		return nil, err
+	}
+	return listPackagesInsideDir(s.buildConfig, rootPackage, rootDir)
+}
+
+func (s *dirSourceFinder) findPackageDir(packagePath string) (string, error) {
+	rel := ""
+	_, version := splitPackageVersion(packagePath)
+	switch {
+	case version != "":
+		return "", fmt.Errorf("cannot find package %s inside %s, it has a version: %w", packagePath, s.dir,
+			ErrPackageNotFound)
+	case s.rootPackage == "":
+		rel = packagePath
+	case packagePath == s.rootPackage:
+	case strings.HasPrefix(packagePath, s.rootPackage+"/"):
+		rel = strings.TrimPrefix(packagePath, s.rootPackage+"/")
+	default:
+		return "", fmt.Errorf("cannot find package %s inside %s: %w", packagePath, s.dir, ErrPackageNotFound)
+	}
+
+	packageDir := filepath.Join(s.dir, filepath.FromSlash(rel))
+	if stat, err := os.Stat(packageDir); err != nil || !stat.IsDir() {
+		return "", fmt.Errorf("cannot find package %s inside %s: %w", packagePath, s.dir, ErrPackageNotFound)
+	}
+	return packageDir, nil
+}
+
+// ChainFinder finds the packages using the first of its SourceFinders able to find them, e.g. inside the workspace,
+// then inside the vendor directory, then inside the module cache, and finally through the module proxy. A
+// SourceFinder is skipped when its error wraps ErrPackageNotFound, the other errors are returned.
+type ChainFinder struct {
+	finders []SourceFinder
+	readers fileReaders
+}
+
+// NewChainFinder returns a ChainFinder trying the `finders` in order.
+func NewChainFinder(finders ...SourceFinder) *ChainFinder {
+	return &ChainFinder{finders: finders}
+}
+
+func (s *ChainFinder) GetPackageSourceFiles(packagePath string) ([]string, error) {
+	for _, finder := range s.finders {
+		goSources, err := finder.GetPackageSourceFiles(packagePath)
+		if errors.Is(err, ErrPackageNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.readers.add(finder, goSources)
+		return goSources, nil
+	}
+	return nil, fmt.Errorf("cannot find package %s using any of the finders: %w", packagePath, ErrPackageNotFound)
+}
+
+// ListPackages returns the packages matched by `pattern`, listed by the first SourceFinder able to find the pattern's
+// root package. The SourceFinders which can't list packages are skipped.
+func (s *ChainFinder) ListPackages(pattern string) ([]string, error) {
+	if !strings.HasSuffix(pattern, "/...") {
+		return []string{pattern}, nil
+	}
+
+	for _, finder := range s.finders {
+		lister, ok := finder.(packageLister)
+		if !ok {
+			continue
+		}
+		packages, err := lister.ListPackages(pattern)
+		if errors.Is(err, ErrPackageNotFound) {
+			continue
+		}
+		return packages, err
+	}
+	return nil, fmt.Errorf("cannot list packages %s using any of the finders: %w", pattern, ErrPackageNotFound)
+}
+
+func (s *ChainFinder) ReadSourceFile(filename string) ([]byte, error) {
+	return s.readers.read(filename)
+}
+
+// MergeFinder overlays the packages found by its SourceFinders, e.g. to add the files of a directory of generated code
+// to the packages found inside the module. The source files of a package are the ones found by all the SourceFinders
+// able to find it. A file found by a later SourceFinder replaces the file having the same name found by an earlier
+// one.
+type MergeFinder struct {
+	finders []SourceFinder
+	readers fileReaders
+}
+
+// NewMergeFinder returns a MergeFinder overlaying the `finders` in order.
+func NewMergeFinder(finders ...SourceFinder) *MergeFinder {
+	return &MergeFinder{finders: finders}
+}
+
+func (s *MergeFinder) GetPackageSourceFiles(packagePath string) ([]string, error) {
+	found := false
+	goSources := make([]string, 0)
+	indices := make(map[string]int)
+	for _, finder := range s.finders {
+		files, err := finder.GetPackageSourceFiles(packagePath)
+		if errors.Is(err, ErrPackageNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+		s.readers.add(finder, files)
+
+		for _, file := range files {
+			name := pathKey(filepath.Base(file))
+			if i, ok := indices[name]; ok {
+				goSources[i] = file
+				continue
+			}
+			indices[name] = len(goSources)
+			goSources = append(goSources, file)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("cannot find package %s using any of the finders: %w", packagePath, ErrPackageNotFound)
+	}
+	return goSources, nil
+}
+
+// ListPackages returns the packages matched by `pattern` listed by any of the SourceFinders. The SourceFinders which
+// can't list packages are skipped.
+func (s *MergeFinder) ListPackages(pattern string) ([]string, error) {
+	if !strings.HasSuffix(pattern, "/...") {
+		return []string{pattern}, nil
+	}
+
+	found := false
+	seen := make(map[string]bool)
+	packages := make([]string, 0)
+	for _, finder := range s.finders {
+		lister, ok := finder.(packageLister)
+		if !ok {
+			continue
+		}
+		listed, err := lister.ListPackages(pattern)
+		if errors.Is(err, ErrPackageNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+		for _, packagePath := range listed {
+			if !seen[packagePath] {
+				seen[packagePath] = true
+				packages = append(packages, packagePath)
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("cannot list packages %s using any of the finders: %w", pattern, ErrPackageNotFound)
+	}
+	sort.Strings(packages)
+	return packages, nil
+}
+
+func (s *MergeFinder) ReadSourceFile(filename string) ([]byte, error) {
+	return s.readers.read(filename)
+}
+
+// fileReaders maps the keys of the source files' names to the SourceFinders which found them, so the files are read by
+// the same SourceFinders, see `sourceReader` and `pathKey`. It can be used by multiple goroutines concurrently.
+type fileReaders struct {
+	mu      sync.Mutex
+	readers map[string]SourceFinder
+}
+
+func (r *fileReaders) add(finder SourceFinder, filenames []string) {
+	if _, ok := finder.(sourceReader); !ok {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.readers == nil {
+		r.readers = make(map[string]SourceFinder)
+	}
+	for _, filename := range filenames {
+		r.readers[pathKey(filename)] = finder
+	}
+}
+
+func (r *fileReaders) read(filename string) ([]byte, error) {
+	r.mu.Lock()
+	reader, ok := r.readers[pathKey(filename)].(sourceReader)
+	r.mu.Unlock()
+
+	if ok {
+		return reader.ReadSourceFile(filename)
+	}
+	return ioutil.ReadFile(filename)
+}
@@ -0,0 +1,183 @@
+package gotype
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+	"unicode"
+)
+
+// fuzzableKinds contains the primitive kinds supported as the arguments of a fuzz target.
+var fuzzableKinds = map[PrimitiveKind]struct{}{
+	PrimitiveKindBool:    {},
+	PrimitiveKindByte:    {},
+	PrimitiveKindRune:    {},
+	PrimitiveKindString:  {},
+	PrimitiveKindInt:     {},
+	PrimitiveKindInt8:    {},
+	PrimitiveKindInt16:   {},
+	PrimitiveKindInt32:   {},
+	PrimitiveKindInt64:   {},
+	PrimitiveKindUint:    {},
+	PrimitiveKindUint8:   {},
+	PrimitiveKindUint16:  {},
+	PrimitiveKindUint32:  {},
+	PrimitiveKindUint64:  {},
+	PrimitiveKindFloat32: {},
+	PrimitiveKindFloat64: {},
+}
+
+// fuzzReservedNames contains the identifiers used by the rendered fuzz harness, which can't be used as the names of the
+// fuzz arguments.
+var fuzzReservedNames = map[string]struct{}{"f": {}, "t": {}, "v": {}, "rand": {}, "testing": {}, "time": {}}
+
+// fuzzField describes how a struct's field is encoded into a fuzz argument and decoded back.
+type fuzzField struct {
+	name    string
+	param   string
+	argType string
+	encode  string
+	decode  string
+}
+
+// RenderFuzzHarness renders the Golang's source code of a fuzz test of the function `targetFunc`, which takes a single
+// argument of the struct type named `typeName`, whose definition is `typ`. Since the fuzzing engine only generates
+// primitive values, the struct is encoded into a list of fuzz arguments by the rendered `encode<typeName>` function and
+// decoded back by the rendered `decode<typeName>` function.
+//
+// The fields having a fuzzable primitive type, `[]byte` or `time.Time` are encoded. The fields of named types are
+// encoded when their `Underlying` definitions, filled by the deep resolution, are fuzzable primitives. The other fields
+// are left with their zero values.
+//
+// The seed corpus contains the zero value of the struct. When `seeds` is positive, it also contains `seeds` instances
+// produced by the `Fake<typeName>` function rendered by RenderFaker. The rendered code only contains the declarations,
+// the package clause and imports are left to the caller. It may use the "math/rand", "testing" and "time" packages.
+func RenderFuzzHarness(typeName string, typ Type, targetFunc string, seeds int, moduleName string) (string, error) {
+	if typ.StructType == nil {
+		return "", fmt.Errorf("cannot render fuzz harness of a non-struct type: %s", typ.String(moduleName))
+	}
+	if typ.IsGeneric() {
+		return "", fmt.Errorf("cannot render fuzz harness of the generic type %s", typeName)
+	}
+
+	fields := make([]fuzzField, 0, len(typ.StructType.Fields))
+	for _, field := range typ.StructType.Fields {
+		if f, ok := newFuzzField(field, moduleName); ok {
+			fields = append(fields, f)
+		}
+	}
+	if len(fields) == 0 {
+		return "", fmt.Errorf("type %s doesn't have any fuzzable field", typeName)
+	}
+
+	params := make([]string, 0, len(fields))
+	args := make([]string, 0, len(fields))
+	for _, f := range fields {
+		params = append(params, f.param+" "+f.argType)
+		args = append(args, f.param)
+	}
+
+	b := strings.Builder{}
+	fmt.Fprintf(&b, "func encode%s(v %s) []interface{} {\n", typeName, typeName)
+	b.WriteString("\treturn []interface{}{\n")
+	for _, f := range fields {
+		fmt.Fprintf(&b, "\t\t%s,\n", f.encode)
+	}
+	b.WriteString("\t}\n}\n")
+
+	fmt.Fprintf(&b, "\nfunc decode%s(%s) %s {\n", typeName, strings.Join(params, ", "), typeName)
+	fmt.Fprintf(&b, "\treturn %s{\n", typeName)
+	for _, f := range fields {
+		fmt.Fprintf(&b, "\t\t%s: %s,\n", f.name, f.decode)
+	}
+	b.WriteString("\t}\n}\n")
+
+	fmt.Fprintf(&b, "\nfunc Fuzz%s(f *testing.F) {\n", exportedName(targetFunc))
+	fmt.Fprintf(&b, "\tf.Add(encode%s(%s{})...)\n", typeName, typeName)
+	if seeds > 0 {
+		fmt.Fprintf(&b, "\tfor seed := int64(1); seed <= %d; seed++ {\n", seeds)
+		fmt.Fprintf(&b, "\t\tf.Add(encode%s(Fake%s(rand.New(rand.NewSource(seed))))...)\n", typeName, typeName)
+		b.WriteString("\t}\n")
+	}
+	fmt.Fprintf(&b, "\tf.Fuzz(func(t *testing.T, %s) {\n", strings.Join(params, ", "))
+	fmt.Fprintf(&b, "\t\t%s(decode%s(%s))\n", targetFunc, typeName, strings.Join(args, ", "))
+	b.WriteString("\t})\n}\n")
+
+	return b.String(), nil
+}
+
+// newFuzzField returns the encoding of a struct's field into a fuzz argument. It returns false when the field's type
+// isn't fuzzable.
+func newFuzzField(field TypeField, moduleName string) (fuzzField, bool) {
+	f := fuzzField{name: field.Name, param: fuzzParamName(field.Name)}
+	typ := field.Type
+
+	switch {
+	case typ.PrimitiveType != nil:
+		if _, ok := fuzzableKinds[typ.PrimitiveType.Kind]; !ok {
+			return fuzzField{}, false
+		}
+		f.argType = string(typ.PrimitiveType.Kind)
+		f.encode = "v." + field.Name
+		f.decode = f.param
+	case typ.SliceType != nil:
+		elem := typ.SliceType.Elem.PrimitiveType
+		if elem == nil || (elem.Kind != PrimitiveKindByte && elem.Kind != PrimitiveKindUint8) {
+			return fuzzField{}, false
+		}
+		f.argType = "[]byte"
+		f.encode = "v." + field.Name
+		f.decode = f.param
+	case typ.QualType != nil && typ.QualType.Package == "time" && typ.QualType.Name == "Time":
+		f.argType = "int64"
+		f.encode = "v." + field.Name + ".UnixNano()"
+		f.decode = "time.Unix(0, " + f.param + ").UTC()"
+	case typ.QualType != nil && typ.QualType.Underlying != nil && typ.QualType.Underlying.PrimitiveType != nil:
+		kind := typ.QualType.Underlying.PrimitiveType.Kind
+		if _, ok := fuzzableKinds[kind]; !ok {
+			return fuzzField{}, false
+		}
+		f.argType = string(kind)
+		f.encode = string(kind) + "(v." + field.Name + ")"
+		f.decode = typ.String(moduleName) + "(" + f.param + ")"
+	default:
+		return fuzzField{}, false
+	}
+	return f, true
+}
+
+// fuzzParamName returns the name of the fuzz argument of a struct's field, which is the field's name starting with a
+// lowercase letter, like `id` for `ID` and `urlPath` for `URLPath`.
+func fuzzParamName(fieldName string) string {
+	name := unexportedName(fieldName)
+	if _, reserved := fuzzReservedNames[name]; reserved || token.IsKeyword(name) {
+		name += "_"
+	}
+	return name
+}
+
+// unexportedName returns the name starting with a lowercase letter, keeping the initialisms together, like `id` for
+// `ID` and `urlPath` for `URLPath`.
+func unexportedName(name string) string {
+	runes := []rune(name)
+	for i := range runes {
+		if !unicode.IsUpper(runes[i]) {
+			break
+		}
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+func exportedName(name string) string {
+	name = strings.ReplaceAll(name, ".", "")
+	runes := []rune(name)
+	if len(runes) == 0 {
+		return name
+	}
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
@@ -0,0 +1,160 @@
+package gotype
+
+import (
+	"fmt"
+)
+
+// Instantiate substitutes the type parameters of the `generic` type with `args`, in the order of their declaration.
+// The substitution is done throughout the whole structure, e.g. inside struct fields and method signatures. The
+// resulting Type has no type parameters.
+func Instantiate(generic Type, args ...Type) (Type, error) {
+	if len(generic.TypeParams) == 0 {
+		return Type{}, fmt.Errorf("cannot instantiate a non-generic type")
+	}
+	if len(args) != len(generic.TypeParams) {
+		return Type{}, fmt.Errorf(
+			"wrong number of type arguments: got %d, expected %d",
+			len(args),
+			len(generic.TypeParams),
+		)
+	}
+
+	mapping := make(map[string]Type, len(args))
+	for i, param := range generic.TypeParams {
+		mapping[param.Name] = args[i]
+	}
+
+	result := substituteTypeParams(generic, mapping)
+	result.TypeParams = nil
+	return result, nil
+}
+
+// substituteTypeParams returns a copy of `t` whose references to the type parameters inside `mapping` are replaced by
+// the mapped types. The original `t` is left untouched.
+func substituteTypeParams(t Type, mapping map[string]Type) Type {
+	return mapType(t, func(t Type) (Type, bool) {
+		if t.TypeParamType == nil {
+			return t, false
+		}
+		if arg, ok := mapping[t.TypeParamType.Name]; ok {
+			return arg, true
+		}
+		return t, true
+	})
+}
+
+func substituteTypeParamsInFunc(funcType FuncType, mapping map[string]Type) FuncType {
+	return *substituteTypeParams(funcType.Type(), mapping).FuncType
+}
+
+// InferTypeArgs infers the type arguments of the generic function `generic` from the types of the arguments passed to
+// it, following the Golang's function argument type inference. Only the type parameters used by the inputs can be
+// inferred, an error is returned when some type parameters can't be inferred or the arguments don't match. For
+// variadic functions, each argument matching the final parameter is unified with its element type.
+func InferTypeArgs(generic Type, args ...Type) ([]Type, error) {
+	if generic.FuncType == nil {
+		return nil, fmt.Errorf("cannot infer type arguments of a non-function type")
+	}
+	if len(generic.TypeParams) == 0 {
+		return nil, fmt.Errorf("cannot infer type arguments of a non-generic function")
+	}
+
+	inputs := generic.FuncType.Inputs
+	if generic.FuncType.IsVariadic {
+		if len(args) < len(inputs)-1 {
+			return nil, fmt.Errorf("not enough arguments: got %d, expected at least %d", len(args), len(inputs)-1)
+		}
+	} else if len(args) != len(inputs) {
+		return nil, fmt.Errorf("wrong number of arguments: got %d, expected %d", len(args), len(inputs))
+	}
+
+	params := make(map[string]struct{}, len(generic.TypeParams))
+	for _, param := range generic.TypeParams {
+		params[param.Name] = struct{}{}
+	}
+
+	inferred := make(map[string]Type)
+	for i, arg := range args {
+		var paramType Type
+		if generic.FuncType.IsVariadic && i >= len(inputs)-1 {
+			last := inputs[len(inputs)-1].Type
+			if last.SliceType != nil {
+				paramType = last.SliceType.Elem
+			} else {
+				paramType = last
+			}
+		} else {
+			paramType = inputs[i].Type
+		}
+
+		if !unifyTypes(paramType, arg, params, inferred) {
+			return nil, fmt.Errorf("type %s of argument %d doesn't match %s", arg.String(""), i+1, paramType.String(""))
+		}
+	}
+
+	result := make([]Type, 0, len(generic.TypeParams))
+	for _, param := range generic.TypeParams {
+		typ, ok := inferred[param.Name]
+		if !ok {
+			return nil, fmt.Errorf("cannot infer type parameter %s", param.Name)
+		}
+		result = append(result, typ)
+	}
+	return result, nil
+}
+
+// unifyTypes unifies the parameter type `x` with the argument type `y`, recording the types bound to the type
+// parameters inside `inferred`. It reports whether both types can be unified.
+func unifyTypes(x, y Type, params map[string]struct{}, inferred map[string]Type) bool {
+	if x.TypeParamType != nil {
+		if _, ok := params[x.TypeParamType.Name]; ok {
+			if bound, ok := inferred[x.TypeParamType.Name]; ok {
+				return Identical(bound, y)
+			}
+			inferred[x.TypeParamType.Name] = y
+			return true
+		}
+	}
+
+	switch {
+	case x.QualType != nil && y.QualType != nil && len(x.QualType.TypeArgs) > 0:
+		if x.QualType.Package != y.QualType.Package || x.QualType.Name != y.QualType.Name ||
+			len(x.QualType.TypeArgs) != len(y.QualType.TypeArgs) {
+			return false
+		}
+		for i := range x.QualType.TypeArgs {
+			if !unifyTypes(x.QualType.TypeArgs[i], y.QualType.TypeArgs[i], params, inferred) {
+				return false
+			}
+		}
+		return true
+	case x.ChanType != nil && y.ChanType != nil:
+		return x.ChanType.Dir == y.ChanType.Dir && unifyTypes(x.ChanType.Elem, y.ChanType.Elem, params, inferred)
+	case x.SliceType != nil && y.SliceType != nil:
+		return unifyTypes(x.SliceType.Elem, y.SliceType.Elem, params, inferred)
+	case x.PtrType != nil && y.PtrType != nil:
+		return unifyTypes(x.PtrType.Elem, y.PtrType.Elem, params, inferred)
+	case x.ArrayType != nil && y.ArrayType != nil:
+		return x.ArrayType.Len == y.ArrayType.Len && unifyTypes(x.ArrayType.Elem, y.ArrayType.Elem, params, inferred)
+	case x.MapType != nil && y.MapType != nil:
+		return unifyTypes(x.MapType.Key, y.MapType.Key, params, inferred) &&
+			unifyTypes(x.MapType.Elem, y.MapType.Elem, params, inferred)
+	case x.FuncType != nil && y.FuncType != nil:
+		return unifyFields(x.FuncType.Inputs, y.FuncType.Inputs, params, inferred) &&
+			unifyFields(x.FuncType.Outputs, y.FuncType.Outputs, params, inferred) &&
+			x.FuncType.IsVariadic == y.FuncType.IsVariadic
+	}
+	return Identical(x, y)
+}
+
+func unifyFields(x, y []TypeField, params map[string]struct{}, inferred map[string]Type) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if !unifyTypes(x[i].Type, y[i].Type, params, inferred) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,267 @@
+package gotype
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstantiate(t *testing.T) {
+	types, err := GenerateTypesFromSpecs(TypeSpec{PackagePath: testdataPackage + "/generics", Name: "Repository"})
+	require.NoError(t, err)
+
+	repository := types[0]
+	require.Len(t, repository.TypeParams, 2)
+	assert.Equal(t, "K", repository.TypeParams[0].Name)
+	assert.Equal(t, "V", repository.TypeParams[1].Name)
+	assert.Equal(t, "comparable", repository.TypeParams[0].Constraint.String(""))
+	assert.Equal(t, "interface{}", repository.TypeParams[1].Constraint.String(""))
+	assert.Equal(t, "interface {\n"+
+		"    Get(id K) (out1 V, out2 error)\n"+
+		"    List() (out1 []V)\n"+
+		"}", repository.String(""))
+
+	user := QualType{Package: "example.com/model", ShortPackagePath: "model", Name: "User"}.Type()
+	instance, err := Instantiate(repository, PrimitiveType{Kind: PrimitiveKindInt}.Type(), user)
+	require.NoError(t, err)
+	assert.Empty(t, instance.TypeParams)
+	assert.Equal(t, "interface {\n"+
+		"    Get(id int) (out1 model.User, out2 error)\n"+
+		"    List() (out1 []model.User)\n"+
+		"}", instance.String(""))
+
+	_, err = Instantiate(repository, user)
+	assert.EqualError(t, err, "wrong number of type arguments: got 1, expected 2")
+}
+
+func TestGenericStructDeclaration(t *testing.T) {
+	types, err := GenerateTypesFromSpecs(TypeSpec{PackagePath: testdataPackage + "/generics", Name: "Pair"})
+	require.NoError(t, err)
+
+	pair := types[0]
+	require.True(t, pair.IsGeneric())
+	require.Len(t, pair.TypeParams, 2)
+	assert.Equal(t, "K", pair.TypeParams[0].Name)
+	assert.Equal(t, "comparable", pair.TypeParams[0].Constraint.String(""))
+	assert.Equal(t, "V", pair.TypeParams[1].Name)
+	assert.Equal(t, "interface{}", pair.TypeParams[1].Constraint.String(""))
+
+	require.NotNil(t, pair.StructType)
+	require.Len(t, pair.StructType.Fields, 2)
+	assert.Equal(t, TypeParamType{Name: "K"}.Type(), pair.StructType.Fields[0].Type)
+	require.NotNil(t, pair.StructType.Fields[1].Type.PtrType)
+	assert.Equal(t, TypeParamType{Name: "V"}.Type(), pair.StructType.Fields[1].Type.PtrType.Elem)
+}
+
+func TestConstraintInterface(t *testing.T) {
+	types, err := GenerateTypesFromSpecs(TypeSpec{PackagePath: testdataPackage + "/generics", Name: "Vector"})
+	require.NoError(t, err)
+	require.Len(t, types[0].TypeParams, 1)
+	require.NotNil(t, types[0].TypeParams[0].ConstraintInterface)
+	assert.Equal(t, "interface {\n    int | float64\n}", types[0].TypeParams[0].ConstraintInterface.String(""))
+	for _, term := range types[0].TypeParams[0].ConstraintInterface.Unions[0] {
+		assert.False(t, term.Tilde)
+	}
+}
+
+func TestConstraintInterfaceFromOtherPackage(t *testing.T) {
+	types, err := GenerateTypesFromSpecs(TypeSpec{PackagePath: testdataPackage + "/generics", Name: "Max"})
+	require.NoError(t, err)
+	require.Len(t, types[0].TypeParams, 1)
+
+	param := types[0].TypeParams[0]
+	assert.Equal(t, "constraints.Ordered", param.Constraint.String(""))
+	require.NotNil(t, param.ConstraintInterface)
+	assert.Equal(t, "interface {\n"+
+		"    ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~float32 | ~float64 | ~string\n"+
+		"}", param.ConstraintInterface.String(""))
+	for _, term := range param.ConstraintInterface.Unions[0] {
+		assert.True(t, term.Tilde)
+	}
+}
+
+func TestRenderGenericMock(t *testing.T) {
+	types, err := GenerateTypesFromSpecs(TypeSpec{PackagePath: testdataPackage + "/generics", Name: "Repository"})
+	require.NoError(t, err)
+
+	code, err := RenderMock("MockRepository", types[0], "")
+	require.NoError(t, err)
+	assert.Equal(t, `type MockRepository[K comparable, V any] struct {
+	GetFunc func(id K) (out1 V, out2 error)
+	ListFunc func() (out1 []V)
+}
+
+func (m *MockRepository[K, V]) Get(id K) (out1 V, out2 error) {
+	return m.GetFunc(id)
+}
+
+func (m *MockRepository[K, V]) List() (out1 []V) {
+	return m.ListFunc()
+}
+`, code)
+}
+
+func TestRenderMock_ParamNamedAfterReceiver(t *testing.T) {
+	intType := PrimitiveType{Kind: PrimitiveKindInt}.Type()
+	iface := InterfaceType{Methods: []InterfaceTypeMethod{{
+		Name: "Get",
+		Func: FuncType{
+			Inputs:  []TypeField{{Name: "m", Type: intType}, {Name: "m_", Type: intType}},
+			Outputs: []TypeField{{Name: "m", Type: intType}},
+		},
+	}}}.Type()
+
+	code, err := RenderMock("Mock", iface, "")
+	require.NoError(t, err)
+	assert.Equal(t, `type Mock struct {
+	GetFunc func(m__ int, m_ int) (m___ int)
+}
+
+func (m *Mock) Get(m__ int, m_ int) (m___ int) {
+	return m.GetFunc(m__, m_)
+}
+`, code)
+}
+
+func TestInferTypeArgs(t *testing.T) {
+	types, err := GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: testdataPackage + "/generics", Name: "Mapper"},
+		TypeSpec{PackagePath: testdataPackage + "/generics", Name: "Appender"},
+	)
+	require.NoError(t, err)
+
+	intType := PrimitiveType{Kind: PrimitiveKindInt}.Type()
+	stringType := PrimitiveType{Kind: PrimitiveKindString}.Type()
+
+	args, err := InferTypeArgs(
+		types[0],
+		SliceType{Elem: intType}.Type(),
+		FuncType{
+			Inputs:  []TypeField{{Name: "n", Type: intType}},
+			Outputs: []TypeField{{Name: "s", Type: stringType}},
+		}.Type(),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []Type{intType, stringType}, args)
+
+	args, err = InferTypeArgs(types[1], SliceType{Elem: stringType}.Type(), stringType, stringType)
+	require.NoError(t, err)
+	assert.Equal(t, []Type{stringType}, args)
+
+	_, err = InferTypeArgs(types[1], SliceType{Elem: stringType}.Type(), intType)
+	assert.EqualError(t, err, "type int of argument 2 doesn't match T")
+}
+
+func TestRenderInstantiatedTypes(t *testing.T) {
+	types, err := GenerateTypesFromSpecs(TypeSpec{PackagePath: testdataPackage + "/generics", Name: "Index"})
+	require.NoError(t, err)
+	assert.Equal(t, "struct {\n"+
+		"    Pairs map[K]generics.Pair[K, *V]\n"+
+		"    Tree *generics.Tree[generics.Pair[string, V]]\n"+
+		"}", types[0].String(""))
+
+	userModel := QualType{Package: "example.com/user/model", ShortPackagePath: "model", Name: "User"}.Type()
+	orderModel := QualType{Package: "example.com/order/model", ShortPackagePath: "model", Name: "Order"}.Type()
+	instance, err := Instantiate(types[0], userModel, orderModel)
+	require.NoError(t, err)
+
+	imports := AliasImports(instance.Imports())
+	assert.Equal(t, []Import{
+		{Name: "model", Package: "example.com/user/model"},
+		{Name: "generics", Package: testdataPackage + "/generics"},
+		{Name: "model2", Package: "example.com/order/model"},
+	}, imports)
+	assert.Equal(t, "struct {\n"+
+		"    Pairs map[model.User]generics.Pair[model.User, *model2.Order]\n"+
+		"    Tree *generics.Tree[generics.Pair[string, model2.Order]]\n"+
+		"}", instance.WithImports(imports).String(""))
+}
+
+func TestGenericReceiverMethods(t *testing.T) {
+	methods, err := defaultAstTypeGenerator.generateMethods(testdataPackage+"/generics", "Tree")
+	require.NoError(t, err)
+	require.Len(t, methods, 2)
+
+	assert.Equal(t, "Insert", methods[0].method.Name)
+	assert.True(t, methods[0].receiver.Pointer)
+	assert.Equal(t, "t", methods[0].receiver.Name)
+	assert.Equal(t, []string{"U"}, methods[0].receiver.TypeParams)
+	assert.Equal(t, "generics.Tree[U]", methods[0].receiver.Type.Type().String(""))
+	assert.Equal(t, "func(value T) (out1 *generics.Tree[T])", methods[0].method.Func.String(""))
+
+	assert.Equal(t, "Size", methods[1].method.Name)
+	assert.False(t, methods[1].receiver.Pointer)
+	assert.Equal(t, []string{"_"}, methods[1].receiver.TypeParams)
+}
+
+func TestFindInstantiations(t *testing.T) {
+	instantiations, err := FindInstantiations(
+		TypeSpec{PackagePath: testdataPackage + "/generics", Name: "Tree"},
+		testdataPackage+"/generics/usage",
+	)
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(instantiations))
+	for _, instantiation := range instantiations {
+		names = append(names, instantiation.Type().String(""))
+	}
+	assert.Equal(t, []string{
+		"generics.Tree[usage.User]",
+		"generics.Tree[string]",
+		"generics.Tree[map[string]int]",
+	}, names)
+}
+
+func TestGenerateInstantiatedFields(t *testing.T) {
+	types, err := GenerateTypesFromSpecs(TypeSpec{PackagePath: testdataPackage + "/generics/usage", Name: "Cache"})
+	require.NoError(t, err)
+
+	fields := types[0].StructType.Fields
+	require.Len(t, fields, 1)
+	entries := fields[0].Type.QualType
+	require.NotNil(t, entries)
+	assert.Equal(t, testdataPackage+"/generics", entries.Package)
+	assert.Equal(t, "Pair", entries.Name)
+	require.Len(t, entries.TypeArgs, 2)
+	assert.Equal(t, PrimitiveType{Kind: PrimitiveKindString}.Type(), entries.TypeArgs[0])
+	assert.Equal(t, "*usage.User", entries.TypeArgs[1].String(""))
+	assert.Equal(t, "generics.Pair[string, *usage.User]", fields[0].Type.String(""))
+}
+
+func TestDeepResolutionOfInstantiatedFields(t *testing.T) {
+	types, err := NewGenerator(WithDeepResolution()).GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: testdataPackage + "/generics/usage", Name: "Profile"},
+	)
+	require.NoError(t, err)
+
+	fields := types[0].StructType.Fields
+	require.Len(t, fields, 2)
+
+	age := fields[0].Type.QualType
+	require.NotNil(t, age.Underlying)
+	assert.Equal(t, "struct {\n    Value int\n    Valid bool\n}", age.Underlying.String(""))
+
+	owner := fields[1].Type.PtrType.Elem.QualType
+	require.NotNil(t, owner.Underlying)
+	ownerFields := owner.Underlying.StructType.Fields
+	assert.Equal(t, "usage.User", ownerFields[0].Type.String(""))
+	assert.NotNil(t, ownerFields[0].Type.QualType.Underlying)
+
+	// the reference to the tree inside the tree closes a cycle.
+	assert.Nil(t, ownerFields[1].Type.PtrType.Elem.QualType.Underlying)
+}
+
+func TestDeepResolutionImportCycle(t *testing.T) {
+	warnings := make([]string, 0)
+	types, err := NewGenerator(WithDeepResolution(), WithWarningHandler(func(w Warning) {
+		warnings = append(warnings, w.Message)
+	})).GenerateTypesFromSpecs(TypeSpec{PackagePath: testdataPackage + "/cycle/a", Name: "A"})
+	require.NoError(t, err)
+
+	field := types[0].StructType.Fields[0].Type.QualType
+	require.NotNil(t, field.Underlying)
+	assert.Nil(t, field.Underlying.StructType.Fields[0].Type.QualType.Underlying)
+	a, b := testdataPackage+"/cycle/a", testdataPackage+"/cycle/b"
+	assert.Equal(t, []string{"import cycle " + a + " -> " + b + " -> " + a}, warnings)
+}
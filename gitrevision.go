@@ -0,0 +1,260 @@
+package gotype
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/modfile"
+)
+
+// gitRevisionSourceFinder finds the packages of the main module as they're stored at a git revision, and reads their
+// source files from the git objects, so the revision doesn't need to be checked out. The packages of the other
+// modules are found by the `fallback` SourceFinder.
+type gitRevisionSourceFinder struct {
+	revision    string
+	fallback    SourceFinder
+	buildConfig *Config
+
+	// tree contains the main module's files at the revision. It's read on the first use, so the errors are reported
+	// by the generator. It's guarded by mu.
+	tree *revisionTree
+	mu   sync.Mutex
+}
+
+// forkAtRevision forks the generator to read the packages of the main module at the git revision `revision`, like
+// `WithGitRevision` does, so two versions of the declarations can be compared.
+func (f *astTypeGenerator) forkAtRevision(revision string) *astTypeGenerator {
+	fork := f.fork()
+	fork.config.gitRevision = revision
+	fork.sourceFinder = &gitRevisionSourceFinder{
+		revision:    revision,
+		fallback:    f.sourceFinder,
+		buildConfig: f.config.buildConfig,
+	}
+	return fork
+}
+
+// revisionTree is the content of the main module at a git revision.
+type revisionTree struct {
+	repoDir    string
+	commit     string
+	modulePath string
+
+	// files maps the source files' names to their paths inside the repository. The names are the repository's
+	// directory joined with "@<revision>" and the files' paths, so they can be told apart from the files on the disk.
+	files map[string]string
+
+	// packages maps the package paths to the names of their source files.
+	packages map[string][]string
+}
+
+func (s *gitRevisionSourceFinder) GetPackageSourceFiles(packagePath string) ([]string, error) {
+	tree, err := s.findTree(packagePath)
+	if err != nil {
+		return nil, err
+	}
+	if tree == nil {
+		return s.fallback.GetPackageSourceFiles(packagePath)
+	}
+
+	goSources := make([]string, 0, len(tree.packages[packagePath]))
+	for _, filename := range tree.packages[packagePath] {
+		match, err := s.buildConfig.matchFile(filepath.Dir(filename), filepath.Base(filename), s.open)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read build constraints of %s: %w", filename, err)
+		}
+		if match {
+			goSources = append(goSources, filename)
+		}
+	}
+	return goSources, nil
+}
+
+func (s *gitRevisionSourceFinder) ReadSourceFile(filename string) ([]byte, error) {
+	s.mu.Lock()
+	tree := s.tree
+	s.mu.Unlock()
+
+	if tree != nil {
+		if _, ok := tree.files[filename]; ok {
+			return s.readFile(filename)
+		}
+	}
+
+	if reader, ok := s.fallback.(sourceReader); ok {
+		return reader.ReadSourceFile(filename)
+	}
+	return ioutil.ReadFile(filename)
+}
+
+// ListPackages returns the packages matched by `pattern`, see `defaultSourceFinder.ListPackages`. The packages of the
+// main module are listed from the revision.
+func (s *gitRevisionSourceFinder) ListPackages(pattern string) ([]string, error) {
+	rootPackage := strings.TrimSuffix(pattern, "/...")
+	tree, err := s.findTree(rootPackage)
+	if err != nil {
+		return nil, err
+	}
+	if tree == nil {
+		if lister, ok := s.fallback.(packageLister); ok {
+			return lister.ListPackages(pattern)
+		}
+		return []string{pattern}, nil
+	}
+	if rootPackage == pattern {
+		return []string{pattern}, nil
+	}
+	return listPackagesUnder(tree.packages, rootPackage), nil
+}
+
+// findTree returns the main module's tree when the package is inside the main module, or nil otherwise. A package
+// path having a version is never inside it.
+func (s *gitRevisionSourceFinder) findTree(packagePath string) (*revisionTree, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tree == nil {
+		tree, err := s.readTree()
+		if err != nil {
+			return nil, err
+		}
+		s.tree = tree
+	}
+
+	if _, version := splitPackageVersion(packagePath); version != "" {
+		return nil, nil
+	}
+	if packagePath == s.tree.modulePath || strings.HasPrefix(packagePath, s.tree.modulePath+"/") {
+		return s.tree, nil
+	}
+	return nil, nil
+}
+
+// readTree lists the files of the revision, and finds the main module as the module of the closest go.mod file
+// enclosing the working directory at the revision. The files of the nested modules are skipped.
+func (s *gitRevisionSourceFinder) readTree() (*revisionTree, error) {
+	wd, err := s.buildConfig.workingDir()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get current working dir: %w", err)
+	}
+
+	tree := &revisionTree{files: make(map[string]string), packages: make(map[string][]string)}
+	topLevel, err := s.git(wd, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("cannot find git repository of %s: %w", wd, err)
+	}
+	// git prints the paths using forward slashes, even on Windows.
+	tree.repoDir = normalizePath(strings.TrimSpace(string(topLevel)))
+	prefix, err := s.git(wd, "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, fmt.Errorf("cannot find git repository of %s: %w", wd, err)
+	}
+	commit, err := s.git(tree.repoDir, "rev-parse", "--verify", "--end-of-options", s.revision+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("cannot find git revision %s: %w", s.revision, err)
+	}
+	tree.commit = strings.TrimSpace(string(commit))
+
+	listing, err := s.git(tree.repoDir, "ls-tree", "-r", "-z", "--name-only", tree.commit)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list files of git revision %s: %w", s.revision, err)
+	}
+	paths := strings.Split(strings.TrimSuffix(string(listing), "\x00"), "\x00")
+
+	// the module directories are relative to the repository's root, "." being the root.
+	moduleDir, moduleDirs := "", make(map[string]bool)
+	wdPath := path.Clean(strings.TrimSpace(string(prefix)))
+	for _, p := range paths {
+		if path.Base(p) != "go.mod" {
+			continue
+		}
+		dir := path.Dir(p)
+		moduleDirs[dir] = true
+		if isInsideDir(wdPath, dir) && (moduleDir == "" || isInsideDir(dir, moduleDir)) {
+			moduleDir = dir
+		}
+	}
+	if moduleDir == "" {
+		return nil, fmt.Errorf("cannot find go.mod file of %s at git revision %s", wd, s.revision)
+	}
+
+	goMod, err := s.git(tree.repoDir, "cat-file", "blob", tree.commit+":"+path.Join(moduleDir, "go.mod"))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read go.mod file at git revision %s: %w", s.revision, err)
+	}
+	tree.modulePath = modfile.ModulePath(goMod)
+	if tree.modulePath == "" {
+		return nil, fmt.Errorf("cannot find module path of go.mod file at git revision %s", s.revision)
+	}
+
+	for _, p := range paths {
+		if path.Ext(p) != ".go" || !isInsideDir(p, moduleDir) {
+			continue
+		}
+		dir := path.Dir(p)
+		nested := false
+		for d := dir; d != moduleDir && !nested; d = path.Dir(d) {
+			nested = moduleDirs[d]
+		}
+		if nested {
+			continue
+		}
+
+		filename := filepath.Join(tree.repoDir, "@"+s.revision, filepath.FromSlash(p))
+		tree.files[filename] = p
+		packagePath := tree.modulePath
+		if dir != moduleDir {
+			packagePath = path.Join(packagePath, strings.TrimPrefix(dir, moduleDir+"/"))
+		}
+		tree.packages[packagePath] = append(tree.packages[packagePath], filename)
+	}
+	return tree, nil
+}
+
+// git runs the git command inside `dir` and returns its output.
+func (s *gitRevisionSourceFinder) git(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = s.buildConfig.environ()
+	output, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		return nil, fmt.Errorf("%w: %s", err, bytes.TrimSpace(exitErr.Stderr))
+	}
+	return output, err
+}
+
+// readFile reads the source file named `filename` from the git objects.
+func (s *gitRevisionSourceFinder) readFile(filename string) ([]byte, error) {
+	p, ok := s.tree.files[filename]
+	if !ok {
+		return nil, fmt.Errorf("file %s is not inside module %s", filename, s.tree.modulePath)
+	}
+	content, err := s.git(s.tree.repoDir, "cat-file", "blob", s.tree.commit+":"+p)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s at git revision %s: %w", p, s.revision, err)
+	}
+	return content, nil
+}
+
+// open opens the source file named `filename`, see `readFile`.
+func (s *gitRevisionSourceFinder) open(filename string) (io.ReadCloser, error) {
+	content, err := s.readFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.NopCloser(bytes.NewReader(content)), nil
+}
+
+// isInsideDir reports whether the slash-separated path `p` is `dir` or is inside it. The paths are relative, "." being
+// the root.
+func isInsideDir(p, dir string) bool {
+	return dir == "." || p == dir || strings.HasPrefix(p, dir+"/")
+}
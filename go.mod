@@ -1,8 +1,17 @@
 module github.com/armantarkhanian/gotype
 
-go 1.14
+go 1.18
 
 require (
+	github.com/fsnotify/fsnotify v1.9.0
 	github.com/stretchr/testify v1.6.1
 	golang.org/x/mod v0.3.0
 )
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+	golang.org/x/xerrors v0.0.0-20191011141410-1b5146add898 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+)
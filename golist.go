@@ -0,0 +1,168 @@
+package gotype
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// NewGoListFinder returns a SourceFinder which finds the packages using `go list`, run inside the directory and the
+// environment of `buildConfig`, so the packages are resolved exactly like by the go tool: the packages of the main
+// module and of its required modules follow the go.mod file, including its replace directives and the vendor
+// directory, the standard library's packages are found inside GOROOT, and the relative paths like "./internal/model"
+// are resolved from the directory. Unlike the other built-in SourceFinders, the build constraints of the go tool's
+// environment are always applied, even with a nil `buildConfig`. The build flags of `buildConfig` are passed to the
+// go tool.
+func NewGoListFinder(buildConfig *Config) SourceFinder {
+	return &goListSourceFinder{buildConfig: buildConfig}
+}
+
+// goListSourceFinder finds the packages using `go list`, see `NewGoListFinder`.
+type goListSourceFinder struct {
+	buildConfig *Config
+
+	// mu guards the cache, so the SourceFinder can be used by multiple goroutines concurrently. It isn't held while
+	// the go tool runs.
+	mu    sync.Mutex
+	cache map[string][]string
+}
+
+// listedPackage is a package listed by `go list -json`.
+type listedPackage struct {
+	ImportPath   string
+	Dir          string
+	GoFiles      []string
+	CgoFiles     []string
+	TestGoFiles  []string
+	XTestGoFiles []string
+	Error        *struct{ Err string }
+}
+
+// sourceFiles returns the paths of the package's source files. The test files are included when `tests` is true.
+func (p listedPackage) sourceFiles(tests bool) []string {
+	names := append(append([]string{}, p.GoFiles...), p.CgoFiles...)
+	if tests {
+		names = append(append(names, p.TestGoFiles...), p.XTestGoFiles...)
+	}
+	sourceFiles := make([]string, 0, len(names))
+	for _, name := range names {
+		sourceFiles = append(sourceFiles, normalizePath(filepath.Join(p.Dir, name)))
+	}
+	return sourceFiles
+}
+
+func (s *goListSourceFinder) GetPackageSourceFiles(packagePath string) ([]string, error) {
+	s.mu.Lock()
+	sourceFiles, ok := s.cache[packagePath]
+	s.mu.Unlock()
+	if ok {
+		return sourceFiles, nil
+	}
+	if _, version := splitPackageVersion(packagePath); version != "" {
+		return nil, fmt.Errorf("cannot list package %s, it has a version: %w", packagePath, ErrPackageNotFound)
+	}
+
+	packages, err := s.list(packagePath)
+	if err != nil {
+		return nil, err
+	}
+	if len(packages) != 1 {
+		return nil, fmt.Errorf("cannot list package %s: %w", packagePath, ErrPackageNotFound)
+	}
+	pkg := packages[0]
+	sourceFiles = pkg.sourceFiles(s.buildConfig != nil && s.buildConfig.Tests)
+	if pkg.Dir == "" || len(sourceFiles) == 0 {
+		message := "no source files"
+		if pkg.Error != nil {
+			message = pkg.Error.Err
+		}
+		return nil, fmt.Errorf("cannot list package %s: %s: %w", packagePath, message, ErrPackageNotFound)
+	}
+	s.store(packagePath, sourceFiles)
+	return sourceFiles, nil
+}
+
+// ListPackages returns the packages matched by `pattern`, listed by the go tool, so the patterns ending with "/..."
+// skip the directories named "testdata" or "vendor" and the directories whose names start with "." or "_". The other
+// patterns match a single package.
+func (s *goListSourceFinder) ListPackages(pattern string) ([]string, error) {
+	if !strings.HasSuffix(pattern, "/...") {
+		return []string{pattern}, nil
+	}
+
+	packages, err := s.list(pattern)
+	if err != nil {
+		return nil, err
+	}
+	packagePaths := make([]string, 0, len(packages))
+	for _, pkg := range packages {
+		sourceFiles := pkg.sourceFiles(s.buildConfig != nil && s.buildConfig.Tests)
+		if pkg.Error != nil || len(sourceFiles) == 0 {
+			continue
+		}
+		// the source files of the listed packages are cached, so they aren't listed again.
+		s.store(pkg.ImportPath, sourceFiles)
+		packagePaths = append(packagePaths, pkg.ImportPath)
+	}
+	if len(packagePaths) == 0 {
+		return nil, fmt.Errorf("cannot list packages %s: %w", pattern, ErrPackageNotFound)
+	}
+	return packagePaths, nil
+}
+
+// store caches the source files of the package.
+func (s *goListSourceFinder) store(packagePath string, sourceFiles []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cache == nil {
+		s.cache = make(map[string][]string)
+	}
+	s.cache[packagePath] = sourceFiles
+}
+
+// invalidatePackage removes the cached source files of the package, see CacheWatcher.
+func (s *goListSourceFinder) invalidatePackage(packagePath string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cache, packagePath)
+}
+
+// list runs `go list -e -json` for the pattern, and returns the listed packages. The errors of the packages, like
+// the missing ones, are reported by their Error.
+func (s *goListSourceFinder) list(pattern string) ([]listedPackage, error) {
+	dir, err := s.buildConfig.workingDir()
+	if err != nil {
+		return nil, fmt.Errorf("cannot list packages %s: %w", pattern, err)
+	}
+	args := append([]string{"list", "-e", "-json"}, s.buildConfig.goFlags()...)
+	cmd := exec.Command("go", append(args, "--", pattern)...)
+	cmd.Dir = dir
+	cmd.Env = s.buildConfig.environ()
+	output, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		err = fmt.Errorf("%w: %s", err, bytes.TrimSpace(exitErr.Stderr))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot list packages %s: %w", pattern, err)
+	}
+
+	packages := make([]listedPackage, 0)
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for {
+		pkg := listedPackage{}
+		if err := decoder.Decode(&pkg); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("cannot decode listed packages %s: %w", pattern, err)
+		}
+		packages = append(packages, pkg)
+	}
+	return packages, nil
+}
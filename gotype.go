@@ -1,9 +1,176 @@
 // gotype package provides functions to parse Golang's source code files and generates a Golang's type representation
 // statically.
+//
+// The output is deterministic: the same source files and options always produce the same types, and the same
+// generated code and reports, since the declarations, the packages and the map keys are always visited in a stable
+// order. See `WithDeterminismCheck` to verify it, e.g. when the generated code is committed.
 package gotype
 
+import (
+	"go/constant"
+	"go/token"
+)
+
 var defaultAstTypeGenerator = &astTypeGenerator{
 	sourceFinder: &defaultSourceFinder{},
+	config:       newConfig(),
+	fset:         token.NewFileSet(),
+}
+
+// TypeGenerator generates Golang's type representation by parsing Golang's source code files.
+// A TypeGenerator can be used by multiple goroutines concurrently, its caches are shared and synchronized.
+//
+// The methods extracting all the types of the packages report them in the source order: the packages in the order of
+// the patterns, the packages matched by a pattern sorted by their paths, the files of a package sorted by their
+// names, and the declarations of a file sorted by their positions, so the generated files diff cleanly between the
+// runs and mirror the source layout.
+type TypeGenerator interface {
+	// GenerateTypesFromSpecs find and parses Golang's source code to generate the `Type`s specified by the
+	// `typeSpecs`.
+	GenerateTypesFromSpecs(typeSpecs ...TypeSpec) ([]Type, error)
+
+	// MinimalInterface computes the minimal interface required by the `consumerPackages` from the type specified by
+	// `typeSpec`, that is, the interface containing only the methods of the type which are used by the consumers. It
+	// supports refactoring a consumer to depend on an interface defined on its side instead of the concrete type.
+	MinimalInterface(typeSpec TypeSpec, consumerPackages ...string) (InterfaceType, error)
+
+	// WellKnownInterfacesOf reports which of the `WellKnownInterfaces` are implemented by the named type specified by
+	// `typeSpec`, both by the type itself and by the pointer to the type. When the type is an interface, only
+	// `ConformanceReport.Value` is filled.
+	WellKnownInterfacesOf(typeSpec TypeSpec) (ConformanceReport, error)
+
+	// AnalyzeInterfaceBloat reports the interfaces declared inside the package that have more than `maxMethods`
+	// methods, or whose methods are never used together by a single function of the `consumerPackages`. A
+	// non-positive `maxMethods` disables the method count check.
+	AnalyzeInterfaceBloat(packagePath string, maxMethods int, consumerPackages ...string) ([]InterfaceBloat, error)
+
+	// FindEquivalentInterfaces scans the packages matched by `packagePatterns` and reports the groups of interfaces
+	// which are structurally identical, regardless of their names, and are declared in more than one package. A
+	// pattern ending with "/..." matches all the packages under it.
+	FindEquivalentInterfaces(packagePatterns ...string) ([][]QualType, error)
+
+	// FindInstantiations lists the distinct concrete instantiations of the generic type specified by `typeSpec`, like
+	// `Repo[User]` and `Repo[Order]`, used inside the packages matched by `packagePatterns`. Instantiations referring
+	// to type parameters, like `Repo[T]` inside another generic declaration, are not concrete and skipped. Only the
+	// type positions are searched, like the types of the fields and variables, the composite literals and the
+	// conversions, so the index expressions of the values, like `repos[i]`, aren't mistaken for instantiations.
+	FindInstantiations(typeSpec TypeSpec, packagePatterns ...string) ([]QualType, error)
+
+	// AnalyzeTypeMetrics computes the complexity metrics of the types declared inside the packages matched by
+	// `packagePatterns`, like their field count, nesting depth, referenced packages and method count, e.g. to feed
+	// architecture dashboards or to enforce lint thresholds. A pattern ending with "/..." matches all the packages
+	// under it.
+	AnalyzeTypeMetrics(packagePatterns ...string) ([]TypeMetrics, error)
+
+	// FindUnusedExportedTypes lists the exported types declared inside the packages matched by `packagePatterns` which
+	// are never referenced outside their own package by the other matched packages, e.g. to prune a large internal
+	// library. The references made by the test files aren't taken into account. A pattern ending with "/..." matches
+	// all the packages under it.
+	FindUnusedExportedTypes(packagePatterns ...string) ([]QualType, error)
+
+	// AnalyzeTypeUsage reports which types are referenced, how often and from where, by the types declared inside the
+	// packages matched by `packagePatterns`: by their fields, by the signatures of their methods, by their embedded
+	// interfaces and by their definitions. The report can be encoded as JSON or CSV, e.g. to feed dependency-health
+	// tooling. A pattern ending with "/..." matches all the packages under it.
+	AnalyzeTypeUsage(packagePatterns ...string) (TypeUsageReport, error)
+
+	// GenerateFromSpecs generates the declarations of any kind specified by the `specs`, like types, functions,
+	// methods, constants and variables, in the order of the `specs`. The files of each package are parsed once, even
+	// when different kinds of declarations are extracted from it. The Types inside the declarations are generated like
+	// the ones returned by GenerateTypesFromSpecs.
+	GenerateFromSpecs(specs ...Spec) ([]Declaration, error)
+
+	// FindImplementations scans the packages matched by `packagePatterns` and reports which of the interfaces declared
+	// inside them are implemented by the named types declared inside them, by the types themselves or only by their
+	// pointers, e.g. to render the compile-time assertions using `RenderConformanceAssertions`. Only the exported
+	// declarations are considered, so the assertions can be rendered inside any package. The generic declarations, the
+	// interfaces without methods and the interfaces whose method sets aren't fully known, like the constraints and the
+	// interfaces kept unflattened by `WithEmbeddedInterfaces`, are skipped. A pattern ending with "/..." matches all
+	// the packages under it.
+	FindImplementations(packagePatterns ...string) ([]ConformanceAssertion, error)
+
+	// AnalyzeSource analyzes the Golang's source code `src` as a standalone package, without any file layout, e.g. for
+	// REPL-like tools and quick one-off extractions. The package clause can be omitted, the package is named "main"
+	// then. The `imports` map the names of the packages referred to by `src` to their paths, like "json" to
+	// "encoding/json", when `src` doesn't import them. The package path of the declarations is SourcePackagePath,
+	// their positions are inside the file "source.go". The blank declarations and the init functions are skipped.
+	AnalyzeSource(src []byte, imports map[string]string) (PackageModel, error)
+
+	// EvaluateConst evaluates the constant named `name` declared inside the package. The constant expressions can use
+	// the literals, iota, the arithmetic, bitwise, shift, comparison and logical operators, the conversions, `len` of
+	// the constant strings, `len` and `cap` of the arrays, `unsafe.Sizeof` and `unsafe.Alignof`, and the references to
+	// the constants of the same package and of the imported packages. The values are computed exactly, without the
+	// overflow checks of the compiler.
+	EvaluateConst(packagePath, name string) (constant.Value, error)
+
+	// EvaluateConstExpr evaluates the constant expression `expr` inside the scope of the package, like EvaluateConst,
+	// e.g. to evaluate the default values of the struct tags referring to constants, like "DefaultPort + 1". The
+	// imports of all the files of the package are visible.
+	EvaluateConstExpr(packagePath, expr string) (constant.Value, error)
+
+	// LintStructTags validates the struct tags of the structs declared inside the packages matched by
+	// `packagePatterns`, including the anonymous structs nested inside them: the malformed tags, which
+	// `reflect.StructTag.Get` can't read, the JSON names used by several fields, including the fields promoted by the
+	// embedded structs, and the options unknown for the keys of `tagOptions`, like "omitempty" for "json". A nil
+	// `tagOptions` checks the DefaultTagOptions. The issues are reported with their positions, in the source order.
+	// A pattern ending with "/..." matches all the packages under it.
+	LintStructTags(tagOptions map[string][]string, packagePatterns ...string) ([]TagIssue, error)
+
+	// FindProviders finds the constructors declared inside the packages matched by `packagePatterns`, that is, the
+	// exported non-generic functions named like `New` or `NewService` returning a value, or a value and an error, and
+	// orders them by their dependencies, which are the types of their inputs. The interfaces declared inside the
+	// packages are bound to the only provided type implementing them. The dependencies which aren't provided are
+	// reported as missing, and the types provided twice and the dependency cycles are errors. The graph is rendered by
+	// RenderProviders. A pattern ending with "/..." matches all the packages under it.
+	FindProviders(packagePatterns ...string) (ProviderGraph, error)
+
+	// CheckWireCompatibility compares the serialization of the type specified by `typeSpec` with the one of its
+	// version at the git revision `baseRevision`, like a tag or a branch, in the `format`, e.g. to check the event
+	// schemas inside a CI pipeline. The revision is read like `WithGitRevision` does, and both versions are generated
+	// using the deep resolution. See CompareWireFormat for the rules.
+	CheckWireCompatibility(typeSpec TypeSpec, baseRevision string, format WireFormat) (WireCompatibilityReport, error)
+
+	// AnalyzeAPIChanges compares the exported API of the package `packagePath`, its exported types, functions,
+	// methods, constants and variables, with the one of its version at the git revision `baseRevision`, like a tag of
+	// the latest release, and reports whether the changes require a major, a minor or a patch version bump. The
+	// revision is read like `WithGitRevision` does, and the test files are ignored. See CompareAPI for the rules.
+	AnalyzeAPIChanges(packagePath, baseRevision string) (APIChangeReport, error)
+
+	// GenerateDocIndex generates a machine-readable index of the exported API of the packages matched by
+	// `packagePatterns`, for the static documentation generators and the developer portals: the packages, their types
+	// with their fields and methods, functions, constants and variables, with their documentation comments. The
+	// declarations are identified by their qualified names, which cross-link the declarations to the types they
+	// reference. Use `DocIndex.JSON` to encode it.
+	GenerateDocIndex(packagePatterns ...string) (DocIndex, error)
+}
+
+// NewGenerator creates a TypeGenerator configured by `opts`. The generator looks for the source files using the
+// go.mod file of the current working directory, unless another SourceFinder is set using `WithSourceFinder`, like a
+// built-in one or a custom implementation of the SourceFinder interface.
+func NewGenerator(opts ...Option) TypeGenerator {
+	c := newConfig(opts...)
+	finder := c.sourceFinder
+	if finder == nil {
+		finder = &defaultSourceFinder{
+			logger:       c.logger,
+			buildConfig:  c.buildConfig,
+			fetchModules: c.fetchModules,
+		}
+	}
+	if len(c.moduleArchives) > 0 {
+		finder = &archiveSourceFinder{archivePaths: c.moduleArchives, fallback: finder, buildConfig: c.buildConfig}
+	}
+	if c.gitRevision != "" {
+		finder = &gitRevisionSourceFinder{revision: c.gitRevision, fallback: finder, buildConfig: c.buildConfig}
+	}
+	if c.cacheWatcher != nil {
+		finder = c.cacheWatcher.watch(finder)
+	}
+	return &astTypeGenerator{
+		sourceFinder: finder,
+		config:       c,
+		fset:         token.NewFileSet(),
+	}
 }
 
 // GenerateTypesFromSpecs find and parses Golang's source code to generate the `Type`s specified by the `typeSpecs`.
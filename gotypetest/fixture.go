@@ -0,0 +1,92 @@
+package gotypetest
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"testing/fstest"
+
+	"github.com/armantarkhanian/gotype"
+)
+
+// FSFinder is a gotype.SourceFinder finding the packages inside a file system, where each package is stored inside the
+// directory named after its path, e.g. "example.com/app/model/model.go". It's meant to test the generators built on
+// gotype without real modules on the disk, see `gotype.WithSourceFinder`.
+type FSFinder struct {
+	fsys fs.FS
+}
+
+// NewFSFinder returns a FSFinder finding the packages inside `fsys`.
+func NewFSFinder(fsys fs.FS) *FSFinder {
+	return &FSFinder{fsys: fsys}
+}
+
+// NewFixtureFinder returns a FSFinder finding the packages of an in-memory file system built from `packages`, which
+// maps the package paths to their files' names and contents, like:
+//
+//	NewFixtureFinder(map[string]map[string]string{
+//		"example.com/app/model": {"model.go": "package model\n\ntype User struct{ ID int }\n"},
+//	})
+func NewFixtureFinder(packages map[string]map[string]string) *FSFinder {
+	fsys := make(fstest.MapFS)
+	for packagePath, files := range packages {
+		for name, content := range files {
+			fsys[path.Join(packagePath, name)] = &fstest.MapFile{Data: []byte(content)}
+		}
+	}
+	return NewFSFinder(fsys)
+}
+
+// GetPackageSourceFiles returns the ".go" files inside the package's directory, sorted by their names.
+func (s *FSFinder) GetPackageSourceFiles(packagePath string) ([]string, error) {
+	entries, err := fs.ReadDir(s.fsys, packagePath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find package %s: %v: %w", packagePath, err, gotype.ErrPackageNotFound)
+	}
+
+	goSources := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".go") {
+			goSources = append(goSources, path.Join(packagePath, entry.Name()))
+		}
+	}
+	return goSources, nil
+}
+
+// ListPackages returns the packages matched by `pattern`. A pattern ending with "/..." matches the directories
+// containing ".go" files under it.
+func (s *FSFinder) ListPackages(pattern string) ([]string, error) {
+	if !strings.HasSuffix(pattern, "/...") {
+		return []string{pattern}, nil
+	}
+
+	rootPackage := strings.TrimSuffix(pattern, "/...")
+	seen := make(map[string]bool)
+	packages := make([]string, 0)
+	err := fs.WalkDir(s.fsys, rootPackage, func(filename string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && strings.HasSuffix(filename, ".go") && !seen[path.Dir(filename)] {
+			seen[path.Dir(filename)] = true
+			packages = append(packages, path.Dir(filename))
+		}
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("cannot list packages %s: %v: %w", pattern, err, gotype.ErrPackageNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot list packages %s: %w", pattern, err)
+	}
+	sort.Strings(packages)
+	return packages, nil
+}
+
+// ReadSourceFile returns the content of a file returned by GetPackageSourceFiles.
+func (s *FSFinder) ReadSourceFile(filename string) ([]byte, error) {
+	return fs.ReadFile(s.fsys, filename)
+}
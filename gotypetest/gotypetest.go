@@ -0,0 +1,175 @@
+// gotypetest package provides helpers to test the code consuming the Types generated by gotype against golden files.
+// The golden files are updated by running the tests with the `-gotypetest.update` flag. The packages under test can
+// be declared in memory using NewFixtureFinder.
+package gotypetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/armantarkhanian/gotype"
+)
+
+// update makes AssertGolden write the golden files instead of comparing them. The flag is prefixed by the package name
+// so it doesn't clash with the flags of the tests.
+var update = flag.Bool("gotypetest.update", false, "update the golden files of gotypetest")
+
+// Snapshot serializes the `types` into a stable and readable JSON document. The nil fields, the empty comments, the
+// zero positions and the false IsAlias flags, like the ones of the nested Types, are omitted and the positions'
+// filenames are made relative to the working directory, so the snapshots don't depend on where the sources are
+// checked out.
+func Snapshot(types ...gotype.Type) ([]byte, error) {
+	data, err := json.Marshal(types)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode the types: %w", err)
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("cannot decode the types: %w", err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the working directory: %w", err)
+	}
+
+	// the keys of the maps are sorted by the encoder, so the output is stable.
+	snapshot, err := json.MarshalIndent(normalize(document, wd), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode the snapshot: %w", err)
+	}
+	return append(snapshot, '\n'), nil
+}
+
+// normalize removes the nil fields, the empty comments, the zero positions and the false IsAlias flags of the decoded
+// JSON `value` and rewrites the filenames relative to `wd`.
+func normalize(value interface{}, wd string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if field == nil || key == "Position" && isZeroPosition(field) || isComment(key) && field == "" ||
+				key == "IsAlias" && field == false {
+				delete(v, key)
+				continue
+			}
+			if filename, ok := field.(string); ok && key == "Filename" {
+				v[key] = relativeFilename(filename, wd)
+				continue
+			}
+			v[key] = normalize(field, wd)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalize(item, wd)
+		}
+	}
+	return value
+}
+
+// isZeroPosition reports whether the decoded JSON `value` is a zero token.Position.
+func isZeroPosition(value interface{}) bool {
+	position, ok := value.(map[string]interface{})
+	return ok && position["Filename"] == "" && position["Line"] == 0.0 && position["Column"] == 0.0 &&
+		position["Offset"] == 0.0
+}
+
+// isComment reports whether the field named `key` contains a comment, like the Doc of a Type.
+func isComment(key string) bool {
+	return key == "Doc" || key == "Comment"
+}
+
+func relativeFilename(filename, wd string) string {
+	if filename == "" || !filepath.IsAbs(filename) {
+		return filepath.ToSlash(filename)
+	}
+	rel, err := filepath.Rel(wd, filename)
+	if err != nil {
+		return filepath.ToSlash(filename)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// AssertGolden compares the snapshot of the `types` with the content of `goldenFile`, and reports the differences
+// line by line. When the tests run with the `-gotypetest.update` flag, the golden file is written instead.
+func AssertGolden(t testing.TB, goldenFile string, types ...gotype.Type) {
+	t.Helper()
+
+	snapshot, err := Snapshot(types...)
+	if err != nil {
+		t.Fatalf("cannot snapshot the types: %v", err)
+	}
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(goldenFile), 0o755); err != nil {
+			t.Fatalf("cannot create the directory of the golden file: %v", err)
+		}
+		if err := ioutil.WriteFile(goldenFile, snapshot, 0o644); err != nil {
+			t.Fatalf("cannot update the golden file: %v", err)
+		}
+		return
+	}
+
+	golden, err := ioutil.ReadFile(goldenFile)
+	if err != nil {
+		t.Fatalf("cannot read the golden file, run the tests with -gotypetest.update to create it: %v", err)
+	}
+	// the golden files checked out on Windows may have CRLF line endings.
+	golden = bytes.ReplaceAll(golden, []byte("\r\n"), []byte("\n"))
+	if !bytes.Equal(golden, snapshot) {
+		t.Errorf("the types don't match the golden file %s (-golden +actual):\n%s", goldenFile,
+			Diff(string(golden), string(snapshot)))
+	}
+}
+
+// Diff returns the differences between `want` and `got` line by line. The removed lines are prefixed by "-", the
+// added ones by "+", and the common ones by a space. It returns an empty string when they are equal.
+func Diff(want, got string) string {
+	if want == got {
+		return ""
+	}
+	a, b := strings.Split(want, "\n"), strings.Split(got, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var diff strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			fmt.Fprintf(&diff, "  %s\n", a[i])
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			fmt.Fprintf(&diff, "- %s\n", a[i])
+			i++
+		default:
+			fmt.Fprintf(&diff, "+ %s\n", b[j])
+			j++
+		}
+	}
+	return diff.String()
+}
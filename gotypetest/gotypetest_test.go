@@ -0,0 +1,62 @@
+package gotypetest
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/armantarkhanian/gotype"
+)
+
+func TestAssertGolden(t *testing.T) {
+	types, err := gotype.GenerateTypesFromSpecs(gotype.TypeSpec{
+		PackagePath: "github.com/armantarkhanian/gotype/testdata/usage/sub",
+		Name:        "Reader",
+	})
+	require.NoError(t, err)
+
+	AssertGolden(t, filepath.Join("testdata", "reader.golden"), types...)
+}
+
+func TestSnapshot(t *testing.T) {
+	snapshot, err := Snapshot(gotype.Type{PrimitiveType: &gotype.PrimitiveType{Kind: gotype.PrimitiveKindString}})
+	require.NoError(t, err)
+	assert.Equal(t, "[\n  {\n    \"PrimitiveType\": {\n      \"Bits\": 0,\n      \"Kind\": \"string\"\n    }\n  }\n]\n",
+		string(snapshot))
+}
+
+func TestDiff(t *testing.T) {
+	assert.Equal(t, "", Diff("a\nb", "a\nb"))
+	assert.Equal(t, "  a\n- b\n+ c\n  d\n", Diff("a\nb\nd", "a\nc\nd"))
+}
+
+func TestFixtureFinder(t *testing.T) {
+	finder := NewFixtureFinder(map[string]map[string]string{
+		"example.com/app/model": {
+			"model.go": "package model\n\nimport \"example.com/app/ids\"\n\ntype User struct {\n\tID ids.ID\n}\n",
+		},
+		"example.com/app/ids": {"ids.go": "package ids\n\ntype ID int64\n\ntype Unused struct{}\n"},
+	})
+	generator := gotype.NewGenerator(gotype.WithSourceFinder(finder), gotype.WithDeepResolution())
+
+	types, err := generator.GenerateTypesFromSpecs(gotype.TypeSpec{PackagePath: "example.com/app/model", Name: "User"})
+	require.NoError(t, err)
+	require.NotNil(t, types[0].StructType)
+	id := types[0].StructType.Fields[0].Type.QualType
+	require.NotNil(t, id)
+	require.NotNil(t, id.Underlying)
+	assert.Equal(t, "int64", id.Underlying.String(""))
+
+	unused, err := generator.FindUnusedExportedTypes("example.com/app/...")
+	require.NoError(t, err)
+	assert.Equal(t, []gotype.QualType{
+		{Package: "example.com/app/ids", Name: "Unused"},
+		{Package: "example.com/app/model", Name: "User"},
+	}, unused)
+
+	_, err = finder.GetPackageSourceFiles("example.com/missing")
+	assert.True(t, errors.Is(err, gotype.ErrPackageNotFound))
+}
@@ -0,0 +1,454 @@
+package gotype
+
+import (
+	"fmt"
+	"go/ast"
+	"path"
+	"sort"
+	"strings"
+)
+
+// protoScalarTypes contains the proto scalar types of the Golang's numeric and string kinds, which are converted by
+// the converters rendered by RenderProtoConverter.
+var protoScalarTypes = map[PrimitiveKind]string{
+	PrimitiveKindString:  "string",
+	PrimitiveKindInt:     "int64",
+	PrimitiveKindInt8:    "int32",
+	PrimitiveKindInt16:   "int32",
+	PrimitiveKindInt32:   "int32",
+	PrimitiveKindRune:    "int32",
+	PrimitiveKindInt64:   "int64",
+	PrimitiveKindUint:    "uint64",
+	PrimitiveKindByte:    "uint32",
+	PrimitiveKindUint8:   "uint32",
+	PrimitiveKindUint16:  "uint32",
+	PrimitiveKindUint32:  "uint32",
+	PrimitiveKindUint64:  "uint64",
+	PrimitiveKindUintptr: "uint64",
+	PrimitiveKindFloat32: "float",
+	PrimitiveKindFloat64: "double",
+}
+
+// protoMapTypes contains the proto scalar types whose protoc-generated Golang's types are the kinds themselves, so
+// the maps using them are copied as they are.
+var protoMapTypes = map[PrimitiveKind]string{
+	PrimitiveKindBool:    "bool",
+	PrimitiveKindString:  "string",
+	PrimitiveKindInt32:   "int32",
+	PrimitiveKindInt64:   "int64",
+	PrimitiveKindUint32:  "uint32",
+	PrimitiveKindUint64:  "uint64",
+	PrimitiveKindFloat32: "float",
+	PrimitiveKindFloat64: "double",
+}
+
+// GRPCService contains the rendered sources exposing an interface as a gRPC service.
+type GRPCService struct {
+	// Proto contains the .proto file declaring the service, and the messages of its requests and responses.
+	Proto string
+
+	// Server contains the Golang's source code of the `<ifaceName>GRPCServer` adapter, implementing the
+	// protoc-generated server interface of the service by calling the interface.
+	Server string
+
+	// Client contains the Golang's source code of the `<ifaceName>GRPCClient` adapter, implementing the interface by
+	// calling the protoc-generated client of the service.
+	Client string
+}
+
+// GRPCServiceOption configures RenderGRPCService.
+type GRPCServiceOption func(*grpcRenderer)
+
+// WithProtoPackage sets the package declared by the .proto file rendered by RenderGRPCService. By default, it's the
+// base name of the package of the protoc-generated code.
+func WithProtoPackage(protoPackage string) GRPCServiceOption {
+	return func(r *grpcRenderer) {
+		r.protoPackage = protoPackage
+	}
+}
+
+// grpcRenderer renders the .proto file and the adapters of a gRPC service.
+type grpcRenderer struct {
+	converter    protoConverter
+	goPackage    string
+	protoPackage string
+
+	// messages contains the structs rendered as messages, in the order they're found, keyed by their names.
+	messages     []grpcMessage
+	messageTypes map[string]QualType
+	protoImports map[string]struct{}
+}
+
+// grpcMessage is a struct rendered as a proto message.
+type grpcMessage struct {
+	name   string
+	fields []string
+}
+
+// rpcMethod is a method of an interface, called as `Method(ctx, request) (response, error)`.
+type rpcMethod struct {
+	name     string
+	context  Type
+	request  Type
+	response Type
+}
+
+// RenderGRPCService renders the sources exposing the interface named `ifaceName`, whose definition is `iface`, as a
+// gRPC service of the same name. The protoc-generated Golang's code of the service is expected to be inside the
+// package `goPackage`.
+//
+// Every method of the interface has to be shaped like `Method(context.Context, Request) (Response, error)`, where
+// the request and the response are structs, or pointers to them. The structs, and the structs used by their fields,
+// are rendered as proto messages of the same names, so `iface` is expected to be generated using
+// `WithDeepResolution`. The fields of a message are named like RenderProtoConverter matches them, that is, by the
+// `proto` tags of the struct's fields, defaulting to the snake case of their names. The fields are mapped as follows:
+//   - the numbers and the strings, including the QualTypes defined by them, are mapped into the scalar types wide
+//     enough to hold them, like `int64` for `int`.
+//   - `bool` and `[]byte` are mapped into `bool` and `bytes`.
+//   - `time.Time` and `time.Duration` are mapped into `google.protobuf.Timestamp` and `google.protobuf.Duration`.
+//   - the structs, or the pointers to them, are mapped into their messages.
+//   - the slices are mapped into repeated fields of their elements.
+//   - the maps whose keys and values are the types generated by protoc as they are, like `map[string]int64`, are
+//     mapped into proto maps.
+//
+// The adapters convert the requests and the responses using the `<name>ToProto` and `<name>FromProto` functions,
+// which are expected to be rendered by RenderProtoConverter for every message. The rendered code only contains the
+// declarations, the package clause and imports are left to the caller.
+func RenderGRPCService(
+	ifaceName string,
+	iface Type,
+	goPackage string,
+	moduleName string,
+	opts ...GRPCServiceOption,
+) (GRPCService, error) {
+	if iface.InterfaceType == nil {
+		return GRPCService{}, fmt.Errorf(
+			"cannot render gRPC service of a non-interface type: %s",
+			iface.String(moduleName),
+		)
+	}
+	if iface.IsGeneric() || len(iface.InterfaceType.Unions) > 0 {
+		return GRPCService{}, fmt.Errorf("cannot render gRPC service of the generic interface %s", ifaceName)
+	}
+
+	r := &grpcRenderer{
+		converter:    protoConverter{moduleName: moduleName},
+		goPackage:    goPackage,
+		protoPackage: path.Base(goPackage),
+		messageTypes: make(map[string]QualType),
+		protoImports: make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	methods := make([]rpcMethod, 0, len(iface.InterfaceType.Methods))
+	for _, method := range iface.InterfaceType.Methods {
+		m, ok := newRPCMethod(method)
+		if !ok || protoMessageName(m.request) == "" || protoMessageName(m.response) == "" {
+			return GRPCService{}, fmt.Errorf(
+				"cannot render gRPC method %s.%s, it isn't shaped like "+
+					"`Method(context.Context, Request) (Response, error)`",
+				ifaceName,
+				method.Name,
+			)
+		}
+		for _, typ := range []Type{m.request, m.response} {
+			if _, err := r.message(typ); err != nil {
+				return GRPCService{}, fmt.Errorf("cannot render gRPC method %s.%s: %w", ifaceName, method.Name, err)
+			}
+		}
+		methods = append(methods, m)
+	}
+
+	return GRPCService{
+		Proto:  r.renderProto(ifaceName, methods),
+		Server: r.renderServer(ifaceName, methods),
+		Client: r.renderClient(ifaceName, methods),
+	}, nil
+}
+
+// newRPCMethod returns the rpcMethod of an interface's method, and false when the method isn't shaped like
+// `Method(context.Context, Request) (Response, error)`.
+func newRPCMethod(method InterfaceTypeMethod) (rpcMethod, bool) {
+	funcType := method.Func
+	if len(funcType.Inputs) != 2 || len(funcType.Outputs) != 2 || funcType.IsVariadic {
+		return rpcMethod{}, false
+	}
+	ctx, request := funcType.Inputs[0].Type, funcType.Inputs[1].Type
+	response, err := funcType.Outputs[0].Type, funcType.Outputs[1].Type
+	isContext := ctx.QualType != nil && ctx.QualType.Package == "context" && ctx.QualType.Name == "Context"
+	isError := err.PrimitiveType != nil && err.PrimitiveType.Kind == PrimitiveKindError
+	if !isContext || !isError {
+		return rpcMethod{}, false
+	}
+	return rpcMethod{name: method.Name, context: ctx, request: request, response: response}, true
+}
+
+// message adds the message of the struct type `typ`, or of a pointer to it, and the messages of its fields, unless
+// they're added already. It returns the name of the message.
+func (r *grpcRenderer) message(typ Type) (string, error) {
+	if typ.PtrType != nil {
+		typ = typ.PtrType.Elem
+	}
+	qualType := *typ.QualType
+	if len(qualType.TypeArgs) > 0 {
+		return "", fmt.Errorf("cannot map the generic struct %s to a proto message", typ.String(r.converter.moduleName))
+	}
+	if added, ok := r.messageTypes[qualType.Name]; ok {
+		if added.Package != qualType.Package {
+			return "", fmt.Errorf(
+				"the structs %s and %s have the same message name",
+				Type{QualType: &added}.String(r.converter.moduleName),
+				typ.String(r.converter.moduleName),
+			)
+		}
+		return qualType.Name, nil
+	}
+
+	// the message is added before its fields, so the recursive structs are added once.
+	r.messageTypes[qualType.Name] = qualType
+	r.messages = append(r.messages, grpcMessage{name: qualType.Name})
+	index := len(r.messages) - 1
+
+	fields := make([]string, 0)
+	for _, field := range qualType.Underlying.StructType.Fields {
+		if !ast.IsExported(field.Name) {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("proto"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = snakeCase(field.Name)
+		}
+		fieldType, err := r.fieldType(field.Type)
+		if err != nil {
+			return "", fmt.Errorf("cannot map field %s.%s: %w", qualType.Name, field.Name, err)
+		}
+		fields = append(fields, fmt.Sprintf("%s %s = %d;", fieldType, name, len(fields)+1))
+	}
+	r.messages[index].fields = fields
+	return qualType.Name, nil
+}
+
+// added reports whether the message of the struct type `typ`, or of a pointer to it, is added already. The deep
+// resolution doesn't fill the definitions of the recursive structs, which are known by their messages.
+func (r *grpcRenderer) added(typ Type) bool {
+	if typ.PtrType != nil {
+		typ = typ.PtrType.Elem
+	}
+	if typ.QualType == nil {
+		return false
+	}
+	added, ok := r.messageTypes[typ.QualType.Name]
+	return ok && added.Package == typ.QualType.Package
+}
+
+// fieldType returns the proto type of a message's field whose struct's field type is `typ`.
+func (r *grpcRenderer) fieldType(typ Type) (string, error) {
+	switch {
+	case isTimeType(typ):
+		r.protoImports["google/protobuf/timestamp.proto"] = struct{}{}
+		return "google.protobuf.Timestamp", nil
+	case isDurationType(typ):
+		r.protoImports["google/protobuf/duration.proto"] = struct{}{}
+		return "google.protobuf.Duration", nil
+	case isByteSlice(typ):
+		return "bytes", nil
+	case typ.PrimitiveType != nil && typ.PrimitiveType.Kind == PrimitiveKindBool:
+		return "bool", nil
+	case protoMessageName(typ) != "" || r.added(typ):
+		return r.message(typ)
+	case typ.SliceType != nil:
+		if typ.SliceType.Elem.SliceType != nil && !isByteSlice(typ.SliceType.Elem) {
+			return "", fmt.Errorf("cannot map the nested slice %s to a proto type", typ.String(r.converter.moduleName))
+		}
+		elem, err := r.fieldType(typ.SliceType.Elem)
+		if err != nil {
+			return "", err
+		}
+		return "repeated " + elem, nil
+	case typ.MapType != nil:
+		key, value := typ.MapType.Key.PrimitiveType, typ.MapType.Elem.PrimitiveType
+		// the proto maps can't be keyed by floating-point numbers.
+		if key != nil && value != nil && protoMapTypes[key.Kind] != "" && !key.Kind.IsFloat() &&
+			protoMapTypes[value.Kind] != "" {
+			return fmt.Sprintf("map<%s, %s>", protoMapTypes[key.Kind], protoMapTypes[value.Kind]), nil
+		}
+	}
+
+	if underlying := validationUnderlying(typ); underlying.PrimitiveType != nil {
+		if scalar, ok := protoScalarTypes[underlying.PrimitiveType.Kind]; ok {
+			return scalar, nil
+		}
+	}
+	if typ.QualType != nil && typ.QualType.Underlying == nil {
+		return "", fmt.Errorf(
+			"cannot map %s to a proto type, its definition is unknown without the deep resolution",
+			typ.String(r.converter.moduleName),
+		)
+	}
+	return "", fmt.Errorf("cannot map %s to a proto type", typ.String(r.converter.moduleName))
+}
+
+func (r *grpcRenderer) renderProto(serviceName string, methods []rpcMethod) string {
+	b := strings.Builder{}
+	b.WriteString("syntax = \"proto3\";\n\n")
+	fmt.Fprintf(&b, "package %s;\n\n", r.protoPackage)
+
+	imports := make([]string, 0, len(r.protoImports))
+	for protoImport := range r.protoImports {
+		imports = append(imports, protoImport)
+	}
+	sort.Strings(imports)
+	for _, protoImport := range imports {
+		fmt.Fprintf(&b, "import %q;\n", protoImport)
+	}
+	if len(imports) > 0 {
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "option go_package = %q;\n", r.goPackage)
+
+	fmt.Fprintf(&b, "\nservice %s {\n", serviceName)
+	for _, method := range methods {
+		fmt.Fprintf(
+			&b,
+			"  rpc %s(%s) returns (%s);\n",
+			method.name,
+			protoMessageName(method.request),
+			protoMessageName(method.response),
+		)
+	}
+	b.WriteString("}\n")
+
+	for _, message := range r.messages {
+		fmt.Fprintf(&b, "\nmessage %s {\n", message.name)
+		for _, field := range message.fields {
+			fmt.Fprintf(&b, "  %s\n", field)
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+func (r *grpcRenderer) renderServer(ifaceName string, methods []rpcMethod) string {
+	w := &codeWriter{}
+	serverName := ifaceName + "GRPCServer"
+	pbServer := r.pbName(ifaceName + "Server")
+	w.line(0, "// %s implements %s by calling a %s.", serverName, pbServer, ifaceName)
+	w.line(0, "type %s struct {", serverName)
+	w.line(1, "%s", r.pbName("Unimplemented"+ifaceName+"Server"))
+	w.line(1, "impl %s", ifaceName)
+	w.line(0, "}")
+	w.line(0, "")
+	w.line(0, "var _ %s = (*%s)(nil)", pbServer, serverName)
+	w.line(0, "")
+	w.line(0, "// New%s returns a %s calling `impl`.", serverName, serverName)
+	w.line(0, "func New%s(impl %s) *%s {", serverName, ifaceName, serverName)
+	w.line(1, "return &%s{impl: impl}", serverName)
+	w.line(0, "}")
+
+	for _, method := range methods {
+		w.line(0, "")
+		w.line(
+			0,
+			"func (s *%s) %s(ctx %s, m *%s) (*%s, error) {",
+			serverName,
+			method.name,
+			method.context.String(r.converter.moduleName),
+			r.pbName(protoMessageName(method.request)),
+			r.pbName(protoMessageName(method.response)),
+		)
+		request := r.converter.converterName(method.request) + "FromProto(m)"
+		if method.request.PtrType == nil {
+			value := w.newVar("v")
+			w.line(1, "var req %s", method.request.String(r.converter.moduleName))
+			w.line(1, "if %s := %s; %s != nil {", value, request, value)
+			w.line(2, "req = *%s", value)
+			w.line(1, "}")
+			request = "req"
+		}
+		w.line(1, "resp, err := s.impl.%s(ctx, %s)", method.name, request)
+		w.line(1, "if err != nil {")
+		w.line(2, "return nil, err")
+		w.line(1, "}")
+		response := "resp"
+		if method.response.PtrType == nil {
+			response = "&resp"
+		}
+		w.line(1, "return %sToProto(%s), nil", r.converter.converterName(method.response), response)
+		w.line(0, "}")
+	}
+	return w.b.String()
+}
+
+func (r *grpcRenderer) renderClient(ifaceName string, methods []rpcMethod) string {
+	w := &codeWriter{}
+	clientName := ifaceName + "GRPCClient"
+	pbClient := r.pbName(ifaceName + "Client")
+	w.line(0, "// %s implements %s by calling a %s.", clientName, ifaceName, pbClient)
+	w.line(0, "type %s struct {", clientName)
+	w.line(1, "client %s", pbClient)
+	w.line(0, "}")
+	w.line(0, "")
+	w.line(0, "var _ %s = (*%s)(nil)", ifaceName, clientName)
+	w.line(0, "")
+	w.line(0, "// New%s returns a %s calling the service through `conn`.", clientName, clientName)
+	w.line(0, "func New%s(conn grpc.ClientConnInterface) *%s {", clientName, clientName)
+	w.line(1, "return &%s{client: %s(conn)}", clientName, r.pbName("New"+ifaceName+"Client"))
+	w.line(0, "}")
+
+	for _, method := range methods {
+		responseType := method.response.String(r.converter.moduleName)
+		w.line(0, "")
+		w.line(
+			0,
+			"func (c *%s) %s(ctx %s, req %s) (%s, error) {",
+			clientName,
+			method.name,
+			method.context.String(r.converter.moduleName),
+			method.request.String(r.converter.moduleName),
+			responseType,
+		)
+		request := "req"
+		if method.request.PtrType == nil {
+			request = "&req"
+		}
+		response := r.converter.converterName(method.response) + "FromProto(m)"
+		if method.response.PtrType != nil {
+			w.line(1, "m, err := c.client.%s(ctx, %sToProto(%s))", method.name,
+				r.converter.converterName(method.request), request)
+			w.line(1, "if err != nil {")
+			w.line(2, "return nil, err")
+			w.line(1, "}")
+			w.line(1, "return %s, nil", response)
+			w.line(0, "}")
+			continue
+		}
+
+		value := w.newVar("v")
+		w.line(1, "var resp %s", responseType)
+		w.line(1, "m, err := c.client.%s(ctx, %sToProto(%s))", method.name,
+			r.converter.converterName(method.request), request)
+		w.line(1, "if err != nil {")
+		w.line(2, "return resp, err")
+		w.line(1, "}")
+		w.line(1, "if %s := %s; %s != nil {", value, response, value)
+		w.line(2, "resp = *%s", value)
+		w.line(1, "}")
+		w.line(1, "return resp, nil")
+		w.line(0, "}")
+	}
+	return w.b.String()
+}
+
+// pbName returns the name of a declaration of the protoc-generated code, qualified by its package.
+func (r *grpcRenderer) pbName(name string) string {
+	return Type{QualType: &QualType{
+		Package:          r.goPackage,
+		ShortPackagePath: path.Base(r.goPackage),
+		Name:             name,
+	}}.String(r.converter.moduleName)
+}
@@ -0,0 +1,89 @@
+package gotype
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderGRPCService(t *testing.T) {
+	types, err := NewGenerator(WithDeepResolution()).GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: testdataPackage + "/service", Name: "UserService"},
+		TypeSpec{PackagePath: testdataPackage + "/service", Name: "Deleter"},
+		TypeSpec{PackagePath: testdataPackage + "/service", Name: "Streamer"},
+		TypeSpec{PackagePath: testdataPackage + "/service", Name: "GetUserRequest"},
+	)
+	require.NoError(t, err)
+
+	service, err := RenderGRPCService("UserService", types[0], testdataPackage+"/service/pb", "service")
+	require.NoError(t, err)
+
+	assert.Contains(t, service.Proto, "package pb;\n\nimport \"google/protobuf/duration.proto\";\n"+
+		"import \"google/protobuf/timestamp.proto\";\n\noption go_package = \""+testdataPackage+"/service/pb\";\n")
+	assert.Contains(t, service.Proto, "service UserService {\n"+
+		"  rpc GetUser(GetUserRequest) returns (User);\n"+
+		"  rpc ListUsers(ListUsersRequest) returns (ListUsersResponse);\n}\n")
+	assert.Contains(t, service.Proto, "message User {\n"+
+		"  int64 id = 1;\n"+
+		"  string full_name = 2;\n"+
+		"  uint32 role = 3;\n"+
+		"  bool active = 4;\n"+
+		"  bytes avatar = 5;\n"+
+		"  google.protobuf.Timestamp created = 6;\n"+
+		"  repeated User friends = 7;\n"+
+		"  map<string, string> labels = 8;\n}\n")
+	assert.Contains(t, service.Proto, "message ListUsersRequest {\n"+
+		"  int32 limit = 1;\n  google.protobuf.Duration ttl = 2;\n}\n")
+	assert.Contains(t, service.Proto, "message ListUsersResponse {\n  repeated User users = 1;\n}\n")
+
+	_, err = parser.ParseFile(token.NewFileSet(), "", "package service\n"+service.Server, 0)
+	require.NoError(t, err, service.Server)
+	assert.Contains(t, service.Server, "type UserServiceGRPCServer struct {\n"+
+		"\tpb.UnimplementedUserServiceServer\n\timpl UserService\n}\n")
+	assert.Contains(t, service.Server, "var _ pb.UserServiceServer = (*UserServiceGRPCServer)(nil)\n")
+	assert.Contains(t, service.Server, "func (s *UserServiceGRPCServer) GetUser("+
+		"ctx context.Context, m *pb.GetUserRequest) (*pb.User, error) {\n"+
+		"\tresp, err := s.impl.GetUser(ctx, GetUserRequestFromProto(m))\n")
+	assert.Contains(t, service.Server, "\tvar req ListUsersRequest\n"+
+		"\tif v1 := ListUsersRequestFromProto(m); v1 != nil {\n\t\treq = *v1\n\t}\n"+
+		"\tresp, err := s.impl.ListUsers(ctx, req)\n")
+	assert.Contains(t, service.Server, "\treturn ListUsersResponseToProto(&resp), nil\n")
+
+	_, err = parser.ParseFile(token.NewFileSet(), "", "package service\n"+service.Client, 0)
+	require.NoError(t, err, service.Client)
+	assert.Contains(t, service.Client, "func NewUserServiceGRPCClient(conn grpc.ClientConnInterface) "+
+		"*UserServiceGRPCClient {\n\treturn &UserServiceGRPCClient{client: pb.NewUserServiceClient(conn)}\n}\n")
+	assert.Contains(t, service.Client, "func (c *UserServiceGRPCClient) GetUser("+
+		"ctx context.Context, req *GetUserRequest) (*User, error) {\n"+
+		"\tm, err := c.client.GetUser(ctx, GetUserRequestToProto(req))\n")
+	assert.Contains(t, service.Client, "\tm, err := c.client.ListUsers(ctx, ListUsersRequestToProto(&req))\n"+
+		"\tif err != nil {\n\t\treturn resp, err\n\t}\n")
+
+	service, err = RenderGRPCService(
+		"UserService",
+		types[0],
+		testdataPackage+"/service/pb",
+		"service",
+		WithProtoPackage("users.v1"),
+	)
+	require.NoError(t, err)
+	assert.Contains(t, service.Proto, "package users.v1;\n")
+
+	_, err = RenderGRPCService("Deleter", types[1], testdataPackage+"/service/pb", "service")
+	assert.EqualError(
+		t,
+		err,
+		"cannot render gRPC method Deleter.Delete, it isn't shaped like "+
+			"`Method(context.Context, Request) (Response, error)`",
+	)
+
+	_, err = RenderGRPCService("Streamer", types[2], testdataPackage+"/service/pb", "service")
+	assert.EqualError(t, err, "cannot render gRPC method Streamer.Stream: cannot map field Event.Updates: "+
+		"cannot map chan string to a proto type")
+
+	_, err = RenderGRPCService("GetUserRequest", types[3], testdataPackage+"/service/pb", "service")
+	assert.EqualError(t, err, "cannot render gRPC service of a non-interface type: struct {\n    ID int\n}")
+}
@@ -0,0 +1,246 @@
+package gotype
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+)
+
+// HTTPService contains the rendered sources exposing an interface as JSON requests over HTTP.
+type HTTPService struct {
+	// Handler contains the Golang's source code of the `<ifaceName>HTTPHandler`, an `http.Handler` serving the
+	// methods of the interface.
+	Handler string
+
+	// Client contains the Golang's source code of the `<ifaceName>HTTPClient`, implementing the interface by sending
+	// the requests to the handler.
+	Client string
+}
+
+// HTTPServiceOption configures RenderHTTPService.
+type HTTPServiceOption func(*httpRenderer)
+
+// WithHTTPPathPrefix sets the prefix of the paths of the methods rendered by RenderHTTPService, like "/api/users".
+func WithHTTPPathPrefix(prefix string) HTTPServiceOption {
+	return func(r *httpRenderer) {
+		r.pathPrefix = strings.TrimSuffix(prefix, "/")
+		if r.pathPrefix != "" && !strings.HasPrefix(r.pathPrefix, "/") {
+			r.pathPrefix = "/" + r.pathPrefix
+		}
+	}
+}
+
+// httpRenderer renders the handler and the client of an interface exposed over HTTP.
+type httpRenderer struct {
+	moduleName string
+	pathPrefix string
+}
+
+// RenderHTTPService renders the sources exposing the interface named `ifaceName`, whose definition is `iface`, as
+// JSON requests over HTTP.
+//
+// Every method of the interface has to be shaped like `Method(context.Context, Request) (Response, error)`, where
+// the request and the response are encoded by encoding/json. The method is served by the `POST /<Method>` route,
+// prefixed by the path set by `WithHTTPPathPrefix`. The handler decodes the request from the JSON body, calls the
+// interface using the request's context, and encodes the response into the JSON body. The invalid requests are
+// answered by `400 Bad Request`, and the errors returned by the interface by `500 Internal Server Error` having the
+// error's message as the body. The client returns the failed responses as errors containing their status and
+// message. The handler and the client only use the standard library.
+//
+// The rendered code only contains the declarations, the package clause and imports are left to the caller.
+func RenderHTTPService(
+	ifaceName string,
+	iface Type,
+	moduleName string,
+	opts ...HTTPServiceOption,
+) (HTTPService, error) {
+	if iface.InterfaceType == nil {
+		return HTTPService{}, fmt.Errorf(
+			"cannot render HTTP service of a non-interface type: %s",
+			iface.String(moduleName),
+		)
+	}
+	if iface.IsGeneric() || len(iface.InterfaceType.Unions) > 0 {
+		return HTTPService{}, fmt.Errorf("cannot render HTTP service of the generic interface %s", ifaceName)
+	}
+
+	r := &httpRenderer{moduleName: moduleName}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	methods := make([]rpcMethod, 0, len(iface.InterfaceType.Methods))
+	for _, method := range iface.InterfaceType.Methods {
+		m, ok := newRPCMethod(method)
+		if !ok {
+			return HTTPService{}, fmt.Errorf(
+				"cannot render HTTP method %s.%s, it isn't shaped like "+
+					"`Method(context.Context, Request) (Response, error)`",
+				ifaceName,
+				method.Name,
+			)
+		}
+		for _, typ := range []Type{m.request, m.response} {
+			if !jsonEncodable(typ) {
+				return HTTPService{}, fmt.Errorf(
+					"cannot render HTTP method %s.%s, %s can't be encoded by encoding/json",
+					ifaceName,
+					method.Name,
+					typ.String(moduleName),
+				)
+			}
+		}
+		methods = append(methods, m)
+	}
+
+	return HTTPService{
+		Handler: r.renderHandler(ifaceName, methods),
+		Client:  r.renderClient(ifaceName, methods),
+	}, nil
+}
+
+// jsonEncodable reports whether the values of the Type can be encoded by encoding/json. The QualTypes are expected
+// to be encodable, unless their definitions filled by the deep resolution aren't.
+func jsonEncodable(typ Type) bool {
+	switch {
+	case typ.PtrType != nil:
+		return jsonEncodable(typ.PtrType.Elem)
+	case typ.SliceType != nil:
+		return jsonEncodable(typ.SliceType.Elem)
+	case typ.ArrayType != nil:
+		return jsonEncodable(typ.ArrayType.Elem)
+	case typ.MapType != nil:
+		return jsonEncodable(typ.MapType.Elem)
+	case typ.ChanType != nil, typ.FuncType != nil:
+		return false
+	case typ.StructType != nil:
+		for _, field := range typ.StructType.Fields {
+			name, ok := jsonFieldName(string(field.Tag))
+			if ok && (ast.IsExported(field.Name) || name != "") && !jsonEncodable(field.Type) {
+				return false
+			}
+		}
+		return true
+	case typ.PrimitiveType != nil:
+		return !typ.PrimitiveType.Kind.IsComplex()
+	case typ.QualType != nil && typ.QualType.Underlying != nil && typ.QualType.Package != "time":
+		return jsonEncodable(*typ.QualType.Underlying)
+	}
+	return true
+}
+
+func (r *httpRenderer) renderHandler(ifaceName string, methods []rpcMethod) string {
+	w := &codeWriter{}
+	handlerName := ifaceName + "HTTPHandler"
+	w.line(0, "// %s serves the methods of a %s as JSON requests posted to their paths.", handlerName, ifaceName)
+	w.line(0, "type %s struct {", handlerName)
+	w.line(1, "impl %s", ifaceName)
+	w.line(1, "mux  *http.ServeMux")
+	w.line(0, "}")
+	w.line(0, "")
+	w.line(0, "var _ http.Handler = (*%s)(nil)", handlerName)
+	w.line(0, "")
+	w.line(0, "// New%s returns a %s calling `impl`.", handlerName, handlerName)
+	w.line(0, "func New%s(impl %s) *%s {", handlerName, ifaceName, handlerName)
+	w.line(1, "h := &%s{impl: impl, mux: http.NewServeMux()}", handlerName)
+	for _, method := range methods {
+		w.line(1, "h.mux.HandleFunc(%q, h.handle%s)", r.pathPrefix+"/"+method.name, method.name)
+	}
+	w.line(1, "return h")
+	w.line(0, "}")
+	w.line(0, "")
+	w.line(0, "func (h *%s) ServeHTTP(w http.ResponseWriter, r *http.Request) {", handlerName)
+	w.line(1, "h.mux.ServeHTTP(w, r)")
+	w.line(0, "}")
+
+	for _, method := range methods {
+		w.line(0, "")
+		w.line(0, "func (h *%s) handle%s(w http.ResponseWriter, r *http.Request) {", handlerName, method.name)
+		w.line(1, "if r.Method != http.MethodPost {")
+		w.line(2, "http.Error(w, \"method not allowed\", http.StatusMethodNotAllowed)")
+		w.line(2, "return")
+		w.line(1, "}")
+		w.line(1, "var req %s", method.request.String(r.moduleName))
+		w.line(1, "if err := json.NewDecoder(r.Body).Decode(&req); err != nil {")
+		w.line(2, "http.Error(w, err.Error(), http.StatusBadRequest)")
+		w.line(2, "return")
+		w.line(1, "}")
+		w.line(1, "resp, err := h.impl.%s(r.Context(), req)", method.name)
+		w.line(1, "if err != nil {")
+		w.line(2, "http.Error(w, err.Error(), http.StatusInternalServerError)")
+		w.line(2, "return")
+		w.line(1, "}")
+		w.line(1, "body, err := json.Marshal(resp)")
+		w.line(1, "if err != nil {")
+		w.line(2, "http.Error(w, err.Error(), http.StatusInternalServerError)")
+		w.line(2, "return")
+		w.line(1, "}")
+		w.line(1, "w.Header().Set(\"Content-Type\", \"application/json\")")
+		w.line(1, "_, _ = w.Write(body)")
+		w.line(0, "}")
+	}
+	return w.b.String()
+}
+
+func (r *httpRenderer) renderClient(ifaceName string, methods []rpcMethod) string {
+	w := &codeWriter{}
+	clientName := ifaceName + "HTTPClient"
+	w.line(0, "// %s implements %s by posting JSON requests to a %sHTTPHandler.", clientName, ifaceName, ifaceName)
+	w.line(0, "type %s struct {", clientName)
+	w.line(1, "baseURL string")
+	w.line(1, "client  *http.Client")
+	w.line(0, "}")
+	w.line(0, "")
+	w.line(0, "var _ %s = (*%s)(nil)", ifaceName, clientName)
+	w.line(0, "")
+	w.line(0, "// New%s returns a %s posting the requests to `baseURL` by `client`, or by", clientName, clientName)
+	w.line(0, "// http.DefaultClient when it's nil.")
+	w.line(0, "func New%s(baseURL string, client *http.Client) *%s {", clientName, clientName)
+	w.line(1, "if client == nil {")
+	w.line(2, "client = http.DefaultClient")
+	w.line(1, "}")
+	w.line(1, "return &%s{baseURL: strings.TrimSuffix(baseURL, \"/\"), client: client}", clientName)
+	w.line(0, "}")
+
+	for _, method := range methods {
+		w.line(0, "")
+		w.line(
+			0,
+			"func (c *%s) %s(ctx %s, req %s) (%s, error) {",
+			clientName,
+			method.name,
+			method.context.String(r.moduleName),
+			method.request.String(r.moduleName),
+			method.response.String(r.moduleName),
+		)
+		w.line(1, "var resp %s", method.response.String(r.moduleName))
+		w.line(1, "err := c.call(ctx, %q, req, &resp)", r.pathPrefix+"/"+method.name)
+		w.line(1, "return resp, err")
+		w.line(0, "}")
+	}
+
+	w.line(0, "")
+	w.line(0, "func (c *%s) call(ctx context.Context, path string, req, resp interface{}) error {", clientName)
+	w.line(1, "body, err := json.Marshal(req)")
+	w.line(1, "if err != nil {")
+	w.line(2, "return err")
+	w.line(1, "}")
+	w.line(1, "httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))")
+	w.line(1, "if err != nil {")
+	w.line(2, "return err")
+	w.line(1, "}")
+	w.line(1, "httpReq.Header.Set(\"Content-Type\", \"application/json\")")
+	w.line(1, "httpResp, err := c.client.Do(httpReq)")
+	w.line(1, "if err != nil {")
+	w.line(2, "return err")
+	w.line(1, "}")
+	w.line(1, "defer httpResp.Body.Close()")
+	w.line(1, "if httpResp.StatusCode != http.StatusOK {")
+	w.line(2, "message, _ := io.ReadAll(httpResp.Body)")
+	w.line(2, "return fmt.Errorf(\"%%s%%s: %%s: %%s\", c.baseURL, path, httpResp.Status, "+
+		"strings.TrimSpace(string(message)))")
+	w.line(1, "}")
+	w.line(1, "return json.NewDecoder(httpResp.Body).Decode(resp)")
+	w.line(0, "}")
+	return w.b.String()
+}
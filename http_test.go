@@ -0,0 +1,52 @@
+package gotype
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderHTTPService(t *testing.T) {
+	types, err := NewGenerator(WithDeepResolution()).GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: testdataPackage + "/service", Name: "UserService"},
+		TypeSpec{PackagePath: testdataPackage + "/service", Name: "Deleter"},
+		TypeSpec{PackagePath: testdataPackage + "/service", Name: "Streamer"},
+	)
+	require.NoError(t, err)
+
+	service, err := RenderHTTPService("UserService", types[0], "service", WithHTTPPathPrefix("/api/users/"))
+	require.NoError(t, err)
+
+	_, err = parser.ParseFile(token.NewFileSet(), "", "package service\n"+service.Handler, 0)
+	require.NoError(t, err, service.Handler)
+	assert.Contains(t, service.Handler, "\th.mux.HandleFunc(\"/api/users/GetUser\", h.handleGetUser)\n"+
+		"\th.mux.HandleFunc(\"/api/users/ListUsers\", h.handleListUsers)\n")
+	assert.Contains(t, service.Handler, "func (h *UserServiceHTTPHandler) handleGetUser("+
+		"w http.ResponseWriter, r *http.Request) {\n")
+	assert.Contains(t, service.Handler, "\tvar req *GetUserRequest\n"+
+		"\tif err := json.NewDecoder(r.Body).Decode(&req); err != nil {\n")
+	assert.Contains(t, service.Handler, "\tresp, err := h.impl.ListUsers(r.Context(), req)\n")
+
+	_, err = parser.ParseFile(token.NewFileSet(), "", "package service\n"+service.Client, 0)
+	require.NoError(t, err, service.Client)
+	assert.Contains(t, service.Client, "var _ UserService = (*UserServiceHTTPClient)(nil)\n")
+	assert.Contains(t, service.Client, "func (c *UserServiceHTTPClient) ListUsers("+
+		"ctx context.Context, req ListUsersRequest) (ListUsersResponse, error) {\n"+
+		"\tvar resp ListUsersResponse\n"+
+		"\terr := c.call(ctx, \"/api/users/ListUsers\", req, &resp)\n"+
+		"\treturn resp, err\n}\n")
+
+	_, err = RenderHTTPService("Deleter", types[1], "service")
+	assert.EqualError(
+		t,
+		err,
+		"cannot render HTTP method Deleter.Delete, it isn't shaped like "+
+			"`Method(context.Context, Request) (Response, error)`",
+	)
+
+	_, err = RenderHTTPService("Streamer", types[2], "service")
+	assert.EqualError(t, err, "cannot render HTTP method Streamer.Stream, *Event can't be encoded by encoding/json")
+}
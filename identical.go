@@ -0,0 +1,152 @@
+package gotype
+
+// Identical reports whether `x` and `y` are identical types, following the Golang's type identity rules. Named types
+// are identical when they have the same package path and name, while the names of function parameters are ignored.
+func Identical(x, y Type) bool {
+	switch {
+	case x.PrimitiveType != nil && y.PrimitiveType != nil:
+		return primitiveKindAlias(x.PrimitiveType.Kind) == primitiveKindAlias(y.PrimitiveType.Kind)
+	case x.QualType != nil && y.QualType != nil:
+		return x.QualType.Package == y.QualType.Package && x.QualType.Name == y.QualType.Name &&
+			identicalTypeList(x.QualType.TypeArgs, y.QualType.TypeArgs)
+	case x.ChanType != nil && y.ChanType != nil:
+		return x.ChanType.Dir == y.ChanType.Dir && Identical(x.ChanType.Elem, y.ChanType.Elem)
+	case x.SliceType != nil && y.SliceType != nil:
+		return Identical(x.SliceType.Elem, y.SliceType.Elem)
+	case x.PtrType != nil && y.PtrType != nil:
+		return Identical(x.PtrType.Elem, y.PtrType.Elem)
+	case x.ArrayType != nil && y.ArrayType != nil:
+		return x.ArrayType.Len == y.ArrayType.Len && Identical(x.ArrayType.Elem, y.ArrayType.Elem)
+	case x.MapType != nil && y.MapType != nil:
+		return Identical(x.MapType.Key, y.MapType.Key) && Identical(x.MapType.Elem, y.MapType.Elem)
+	case x.FuncType != nil && y.FuncType != nil:
+		return identicalFunc(*x.FuncType, *y.FuncType)
+	case x.StructType != nil && y.StructType != nil:
+		return identicalStruct(*x.StructType, *y.StructType)
+	case x.InterfaceType != nil && y.InterfaceType != nil:
+		return identicalInterface(*x.InterfaceType, *y.InterfaceType)
+	case x.TypeParamType != nil && y.TypeParamType != nil:
+		return x.TypeParamType.Name == y.TypeParamType.Name
+	}
+	return false
+}
+
+func identicalTypeList(x, y []Type) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if !Identical(x[i], y[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func identicalFunc(x, y FuncType) bool {
+	if x.IsVariadic != y.IsVariadic || len(x.Inputs) != len(y.Inputs) || len(x.Outputs) != len(y.Outputs) {
+		return false
+	}
+	for i := range x.Inputs {
+		if !Identical(x.Inputs[i].Type, y.Inputs[i].Type) {
+			return false
+		}
+	}
+	for i := range x.Outputs {
+		if !Identical(x.Outputs[i].Type, y.Outputs[i].Type) {
+			return false
+		}
+	}
+	return true
+}
+
+func identicalStruct(x, y StructType) bool {
+	if len(x.Fields) != len(y.Fields) {
+		return false
+	}
+	for i := range x.Fields {
+		if x.Fields[i].Name != y.Fields[i].Name ||
+			x.Fields[i].Embedded != y.Fields[i].Embedded ||
+			x.Fields[i].Tag != y.Fields[i].Tag ||
+			!Identical(x.Fields[i].Type, y.Fields[i].Type) {
+			return false
+		}
+	}
+	return true
+}
+
+func identicalInterface(x, y InterfaceType) bool {
+	if len(x.Methods) != len(y.Methods) || len(x.Unions) != len(y.Unions) || !identicalEmbedded(x.Embedded, y.Embedded) {
+		return false
+	}
+
+	for i := range x.Unions {
+		if !identicalUnion(x.Unions[i], y.Unions[i]) {
+			return false
+		}
+	}
+
+	methods := make(map[string]FuncType, len(x.Methods))
+	for _, method := range x.Methods {
+		methods[method.Name] = method.Func
+	}
+	for _, method := range y.Methods {
+		funcType, ok := methods[method.Name]
+		if !ok || !identicalFunc(funcType, method.Func) {
+			return false
+		}
+	}
+	return true
+}
+
+// identicalEmbedded reports whether both interfaces embed the same interfaces, regardless of their order, like the
+// methods. The embedded interfaces matter when they aren't flattened into the methods, see `WithEmbeddedInterfaces`.
+func identicalEmbedded(x, y []QualType) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for _, a := range x {
+		found := false
+		for _, b := range y {
+			if Identical(Type{QualType: &a}, Type{QualType: &b}) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// primitiveKindAlias returns the kind aliased by `byte` and `rune`, since they are identical to `uint8` and `int32`.
+func primitiveKindAlias(kind PrimitiveKind) PrimitiveKind {
+	switch kind {
+	case PrimitiveKindByte:
+		return PrimitiveKindUint8
+	case PrimitiveKindRune:
+		return PrimitiveKindInt32
+	}
+	return kind
+}
+
+// identicalUnion reports whether both unions have the same terms, regardless of their order.
+func identicalUnion(x, y []TypeTerm) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for _, a := range x {
+		found := false
+		for _, b := range y {
+			if a.Tilde == b.Tilde && Identical(a.Type, b.Type) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package gotype
+
+import (
+	"strconv"
+)
+
+// Import represents an imported Golang's package.
+type Import struct {
+	// Name contains the name used to refer to the package inside the source code.
+	Name string
+
+	// Package contains the imported package path.
+	Package string
+}
+
+// Imports returns the packages referenced by the Type, including the packages of the type arguments of instantiated
+// generic types and of the nested types, in the order of their first appearance.
+func (i Type) Imports() []Import {
+	imports := make([]Import, 0)
+	seen := make(map[string]struct{})
+	mapType(i, func(t Type) (Type, bool) {
+		if t.QualType != nil && t.QualType.Package != "" {
+			if _, ok := seen[t.QualType.Package]; !ok {
+				seen[t.QualType.Package] = struct{}{}
+				imports = append(imports, Import{Name: t.QualType.ShortPackagePath, Package: t.QualType.Package})
+			}
+		}
+		return t, false
+	})
+	return imports
+}
+
+// AliasImports returns a copy of `imports` where the packages sharing the same name are given unique aliases, by
+// appending a number to the names of the later ones, e.g. `model` and `model2`. The same package imported twice is
+// deduplicated.
+func AliasImports(imports []Import) []Import {
+	result := make([]Import, 0, len(imports))
+	usedNames := make(map[string]struct{})
+	seenPackages := make(map[string]struct{})
+	for _, imp := range imports {
+		if _, ok := seenPackages[imp.Package]; ok {
+			continue
+		}
+		seenPackages[imp.Package] = struct{}{}
+
+		name := imp.Name
+		for n := 2; ; n++ {
+			if _, ok := usedNames[name]; !ok {
+				break
+			}
+			name = imp.Name + strconv.Itoa(n)
+		}
+		usedNames[name] = struct{}{}
+
+		result = append(result, Import{Name: name, Package: imp.Package})
+	}
+	return result
+}
+
+// WithImports returns a copy of the Type where the QualTypes refer to their packages using the names in `imports`, so
+// the Type can be rendered using aliased imports. Packages missing from `imports` keep their names.
+func (i Type) WithImports(imports []Import) Type {
+	names := make(map[string]string, len(imports))
+	for _, imp := range imports {
+		names[imp.Package] = imp.Name
+	}
+
+	return mapType(i, func(t Type) (Type, bool) {
+		if t.QualType == nil {
+			return t, false
+		}
+		name, ok := names[t.QualType.Package]
+		if !ok {
+			return t, false
+		}
+
+		q := *t.QualType
+		q.ShortPackagePath = name
+		q.TypeArgs = mapTypes(q.TypeArgs, func(arg Type) (Type, bool) {
+			return arg.WithImports(imports), true
+		})
+		return Type{QualType: &q}, true
+	})
+}
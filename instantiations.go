@@ -0,0 +1,154 @@
+package gotype
+
+import (
+	"go/ast"
+)
+
+// FindInstantiations lists the concrete instantiations of the generic type specified by `typeSpec` used inside the
+// packages matched by `packagePatterns`. See `TypeGenerator.FindInstantiations` for the details.
+func FindInstantiations(typeSpec TypeSpec, packagePatterns ...string) ([]QualType, error) {
+	return defaultAstTypeGenerator.FindInstantiations(typeSpec, packagePatterns...)
+}
+
+func (f *astTypeGenerator) FindInstantiations(typeSpec TypeSpec, packagePatterns ...string) ([]QualType, error) {
+	f = f.fork()
+	packages, err := f.expandPackagePatterns(packagePatterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]QualType, 0)
+	for _, packagePath := range packages {
+		goSources, err := f.getPackageSourceFiles(packagePath)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, source := range goSources {
+			fileAst, err := f.parseAstFile(source)
+			if err != nil {
+				return nil, err
+			}
+
+			importMap := f.generateImportMap(packagePath, fileAst)
+			for _, decl := range fileAst.Decls {
+				scoped := f.withDeclTypeParams(decl, importMap)
+				inspectTypeExprs(decl, func(expr ast.Expr) {
+					typ, err := f.generateTypeFromExpr(expr, packagePath, scoped)
+					if err != nil || typ.QualType == nil {
+						return
+					}
+					q := typ.QualType
+					if q.Package != typeSpec.PackagePath || q.Name != typeSpec.Name || containsTypeParam(typ) {
+						return
+					}
+
+					for _, result := range results {
+						if Identical(result.Type(), typ) {
+							return
+						}
+					}
+					results = append(results, *q)
+				})
+			}
+		}
+	}
+
+	for i, result := range results {
+		results[i] = f.rewriteQualType(result)
+	}
+	return results, nil
+}
+
+// withDeclTypeParams returns the import map scoped with the type parameters declared by a top level declaration,
+// either by a generic type declaration, a generic function or the receiver of a method of a generic type.
+func (f *astTypeGenerator) withDeclTypeParams(decl ast.Decl, importMap map[string]string) map[string]string {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		importMap = f.withTypeParams(importMap, d.Type.TypeParams)
+		if receiverTypeParams := f.getReceiverTypeParams(d); len(receiverTypeParams) > 0 {
+			importMap = f.withTypeParams(importMap, &ast.FieldList{List: []*ast.Field{{Names: receiverTypeParams}}})
+		}
+	case *ast.GenDecl:
+		for _, spec := range d.Specs {
+			if typeSpec, ok := spec.(*ast.TypeSpec); ok {
+				importMap = f.withTypeParams(importMap, typeSpec.TypeParams)
+			}
+		}
+	}
+	return importMap
+}
+
+// containsTypeParam reports whether `t` refers to any type parameter.
+func containsTypeParam(t Type) bool {
+	found := false
+	mapType(t, func(t Type) (Type, bool) {
+		if t.TypeParamType != nil {
+			found = true
+		}
+		return t, found
+	})
+	return found
+}
+
+// inspectTypeExprs calls `fn` for the generic type instantiations, like `Tree[int]`, found in the type positions of
+// `node`: the types of the fields, parameters, results, variables and type declarations, of the composite literals and
+// of the type assertions, and the conversions. The index expressions of the values, like `trees[i]`, aren't visited.
+func inspectTypeExprs(node ast.Node, fn func(ast.Expr)) {
+	ast.Inspect(node, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.Field:
+			inspectTypeExpr(n.Type, fn)
+		case *ast.TypeSpec:
+			inspectTypeExpr(n.Type, fn)
+		case *ast.ValueSpec:
+			inspectTypeExpr(n.Type, fn)
+		case *ast.CompositeLit:
+			inspectTypeExpr(n.Type, fn)
+		case *ast.TypeAssertExpr:
+			inspectTypeExpr(n.Type, fn)
+		case *ast.CallExpr:
+			// a call of an instantiated type is a conversion.
+			switch n.Fun.(type) {
+			case *ast.IndexExpr, *ast.IndexListExpr:
+				inspectTypeExpr(n.Fun, fn)
+			}
+		}
+		return true
+	})
+}
+
+// inspectTypeExpr calls `fn` for the instantiations found inside the type expression. The fields of the struct,
+// interface and function types are visited by inspectTypeExprs.
+func inspectTypeExpr(expr ast.Expr, fn func(ast.Expr)) {
+	switch e := expr.(type) {
+	case *ast.IndexExpr:
+		fn(e)
+		inspectTypeExpr(e.Index, fn)
+	case *ast.IndexListExpr:
+		fn(e)
+		for _, index := range e.Indices {
+			inspectTypeExpr(index, fn)
+		}
+	case *ast.ParenExpr:
+		inspectTypeExpr(e.X, fn)
+	case *ast.StarExpr:
+		inspectTypeExpr(e.X, fn)
+	case *ast.Ellipsis:
+		inspectTypeExpr(e.Elt, fn)
+	case *ast.ArrayType:
+		inspectTypeExpr(e.Elt, fn)
+	case *ast.MapType:
+		inspectTypeExpr(e.Key, fn)
+		inspectTypeExpr(e.Value, fn)
+	case *ast.ChanType:
+		inspectTypeExpr(e.Value, fn)
+	case *ast.UnaryExpr:
+		// the approximation elements of the constraints, like `~[]T`.
+		inspectTypeExpr(e.X, fn)
+	case *ast.BinaryExpr:
+		// the unions of the constraints, like `int | Tree[int]`.
+		inspectTypeExpr(e.X, fn)
+		inspectTypeExpr(e.Y, fn)
+	}
+}
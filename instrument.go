@@ -0,0 +1,48 @@
+package gotype
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// The phases of the generator, reported as the names of the spans and as the "gotype.phase" profiler label.
+const (
+	phaseFindPackage     = "find_package"
+	phaseParseFile       = "parse_file"
+	phaseGeneratePackage = "generate_package"
+	phaseResolveType     = "resolve_type"
+)
+
+// startPhase reports the beginning of a phase of the generator, described by the `attributes` key-value pairs, like
+// "gotype.package" and "gotype.file". The phase is reported as a span to `Hooks.StartSpan`, and its goroutine is
+// labeled for the profiler when configured by `WithProfilerLabels`. The returned function ends the phase.
+func (f *astTypeGenerator) startPhase(phase string, attributes ...string) func(err error) {
+	var endSpan func(error)
+	if f.config.hooks.StartSpan != nil {
+		spanAttributes := make(map[string]string, len(attributes)/2)
+		for i := 0; i+1 < len(attributes); i += 2 {
+			spanAttributes[attributes[i]] = attributes[i+1]
+		}
+		endSpan = f.config.hooks.StartSpan("gotype."+phase, spanAttributes)
+	}
+
+	var parent context.Context
+	if f.config.profilerLabels != nil {
+		parent = f.labels
+		if parent == nil {
+			parent = f.config.profilerLabels
+		}
+		f.labels = pprof.WithLabels(parent, pprof.Labels(append([]string{"gotype.phase", phase}, attributes...)...))
+		pprof.SetGoroutineLabels(f.labels)
+	}
+
+	return func(err error) {
+		if parent != nil {
+			f.labels = parent
+			pprof.SetGoroutineLabels(parent)
+		}
+		if endSpan != nil {
+			endSpan(err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package gotype
+
+import (
+	"context"
+	"runtime/pprof"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstrumentation(t *testing.T) {
+	type span struct {
+		name       string
+		attributes map[string]string
+		ended      bool
+	}
+	spans := make([]*span, 0)
+	hooks := Hooks{
+		StartSpan: func(name string, attributes map[string]string) func(error) {
+			s := &span{name: name, attributes: attributes}
+			spans = append(spans, s)
+			return func(error) { s.ended = true }
+		},
+	}
+
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("service", "codegen"))
+	generator := NewGenerator(WithDeepResolution(), WithHooks(hooks), WithProfilerLabels(ctx))
+	_, err := generator.GenerateTypesFromSpecs(TypeSpec{PackagePath: testdataPackage + "/chain", Name: "Holder"})
+	require.NoError(t, err)
+
+	names := make(map[string]bool)
+	for _, s := range spans {
+		assert.True(t, s.ended, s.name)
+		assert.True(t, strings.HasPrefix(s.name, "gotype."), s.name)
+		names[s.name] = true
+	}
+	assert.Equal(t, map[string]bool{
+		"gotype.find_package":     true,
+		"gotype.parse_file":       true,
+		"gotype.generate_package": true,
+		"gotype.resolve_type":     true,
+	}, names)
+	assert.Equal(t, map[string]string{"gotype.package": testdataPackage + "/chain"}, spans[0].attributes)
+}
+
+func TestProfilerLabels(t *testing.T) {
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("service", "codegen"))
+	f := NewGenerator(WithProfilerLabels(ctx)).(*astTypeGenerator).fork()
+
+	endPackage := f.startPhase(phaseGeneratePackage, "gotype.package", "example.com/pkg")
+	endFile := f.startPhase(phaseParseFile, "gotype.file", "pkg.go")
+	labels := f.labels
+	endFile(nil)
+	phase, _ := pprof.Label(f.labels, "gotype.phase")
+	assert.Equal(t, phaseGeneratePackage, phase)
+	endPackage(nil)
+	assert.Equal(t, ctx, f.labels)
+
+	for key, value := range map[string]string{
+		"service":        "codegen",
+		"gotype.phase":   phaseParseFile,
+		"gotype.package": "example.com/pkg",
+		"gotype.file":    "pkg.go",
+	} {
+		label, ok := pprof.Label(labels, key)
+		assert.True(t, ok, key)
+		assert.Equal(t, value, label)
+	}
+}
@@ -0,0 +1,103 @@
+package gotype
+
+import (
+	"fmt"
+)
+
+// MergeInterfaces returns the union of the methods of `a` and `b`. The methods of `a` come first, followed by the
+// methods of `b` which are not in `a`. A method declared in both interfaces must have identical signatures, otherwise
+// an error is returned. The embedded interfaces of both interfaces are merged as well, and so are the unions of
+// constraint interfaces, so the type set of the result is the intersection of the type sets of `a` and `b`.
+func MergeInterfaces(a, b InterfaceType) (InterfaceType, error) {
+	methods := make([]InterfaceTypeMethod, 0, len(a.Methods)+len(b.Methods))
+	methods = append(methods, a.Methods...)
+
+	for _, method := range b.Methods {
+		existing, ok := findInterfaceMethod(a, method.Name)
+		if !ok {
+			methods = append(methods, method)
+			continue
+		}
+		if !identicalFunc(existing.Func, method.Func) {
+			return InterfaceType{}, fmt.Errorf(
+				"cannot merge method %s with different signatures: %s and %s",
+				method.Name,
+				existing.Func.String(""),
+				method.Func.String(""),
+			)
+		}
+	}
+
+	embedded := append([]QualType(nil), a.Embedded...)
+	for _, qualType := range b.Embedded {
+		if !containsQualType(embedded, qualType) {
+			embedded = append(embedded, qualType)
+		}
+	}
+
+	unions := append([][]TypeTerm(nil), a.Unions...)
+	for _, union := range b.Unions {
+		if !containsUnion(unions, union) {
+			unions = append(unions, union)
+		}
+	}
+
+	return InterfaceType{Methods: methods, Embedded: embedded, Unions: unions}, nil
+}
+
+// SubtractInterface returns the methods of `a` which are not in `b`. A method of `a` is only considered being in `b`
+// when `b` has a method with the same name and an identical signature, so the result tells which methods are still
+// needed to implement `a` by something that already implements `b`. The embedded interfaces of `a` which are also
+// embedded by `b` are removed as well, and so are the unions of `a` which are also unions of `b`, since the type set of
+// `b` is already restricted by them.
+func SubtractInterface(a, b InterfaceType) InterfaceType {
+	result := InterfaceType{}
+	for _, method := range a.Methods {
+		if other, ok := findInterfaceMethod(b, method.Name); ok && identicalFunc(method.Func, other.Func) {
+			continue
+		}
+		result.Methods = append(result.Methods, method)
+	}
+
+	for _, qualType := range a.Embedded {
+		if !containsQualType(b.Embedded, qualType) {
+			result.Embedded = append(result.Embedded, qualType)
+		}
+	}
+
+	for _, union := range a.Unions {
+		if !containsUnion(b.Unions, union) {
+			result.Unions = append(result.Unions, union)
+		}
+	}
+
+	return result
+}
+
+func findInterfaceMethod(i InterfaceType, name string) (InterfaceTypeMethod, bool) {
+	for _, method := range i.Methods {
+		if method.Name == name {
+			return method, true
+		}
+	}
+	return InterfaceTypeMethod{}, false
+}
+
+func containsQualType(qualTypes []QualType, qualType QualType) bool {
+	for _, q := range qualTypes {
+		if q.Package == qualType.Package && q.Name == qualType.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// containsUnion reports whether `unions` contains a union identical to `union`, see `identicalUnion`.
+func containsUnion(unions [][]TypeTerm, union []TypeTerm) bool {
+	for _, u := range unions {
+		if identicalUnion(u, union) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package gotype
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeInterfaces(t *testing.T) {
+	errorType := PrimitiveType{Kind: PrimitiveKindError}.Type()
+	stringType := PrimitiveType{Kind: PrimitiveKindString}.Type()
+	closeMethod := InterfaceTypeMethod{Name: "Close", Func: FuncType{Outputs: []TypeField{{Name: "err", Type: errorType}}}}
+	nameMethod := InterfaceTypeMethod{Name: "Name", Func: FuncType{Outputs: []TypeField{{Name: "out1", Type: stringType}}}}
+
+	merged, err := MergeInterfaces(
+		InterfaceType{Methods: []InterfaceTypeMethod{closeMethod}},
+		InterfaceType{Methods: []InterfaceTypeMethod{nameMethod, closeMethod}},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []InterfaceTypeMethod{closeMethod, nameMethod}, merged.Methods)
+
+	conflicting := InterfaceTypeMethod{Name: "Close", Func: FuncType{}}
+	_, err = MergeInterfaces(
+		InterfaceType{Methods: []InterfaceTypeMethod{closeMethod}},
+		InterfaceType{Methods: []InterfaceTypeMethod{conflicting}},
+	)
+	assert.EqualError(t, err, "cannot merge method Close with different signatures: func() (err error) and func()")
+
+	// the type sets are intersected, so the unions of both constraints are kept.
+	reader := QualType{Package: "io", ShortPackagePath: "io", Name: "Reader"}
+	numbers := []TypeTerm{{Tilde: true, Type: PrimitiveType{Kind: PrimitiveKindInt}.Type()}, {Type: stringType}}
+	texts := []TypeTerm{{Type: stringType}}
+	merged, err = MergeInterfaces(
+		InterfaceType{Embedded: []QualType{reader}, Unions: [][]TypeTerm{numbers}},
+		InterfaceType{Unions: [][]TypeTerm{texts, {numbers[1], numbers[0]}}},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []QualType{reader}, merged.Embedded)
+	assert.Equal(t, [][]TypeTerm{numbers, texts}, merged.Unions)
+}
+
+func TestSubtractInterface(t *testing.T) {
+	errorType := PrimitiveType{Kind: PrimitiveKindError}.Type()
+	closeMethod := InterfaceTypeMethod{Name: "Close", Func: FuncType{Outputs: []TypeField{{Name: "err", Type: errorType}}}}
+	flushMethod := InterfaceTypeMethod{Name: "Flush", Func: FuncType{Outputs: []TypeField{{Name: "err", Type: errorType}}}}
+	resetMethod := InterfaceTypeMethod{Name: "Reset", Func: FuncType{}}
+
+	result := SubtractInterface(
+		InterfaceType{Methods: []InterfaceTypeMethod{closeMethod, flushMethod, resetMethod}},
+		InterfaceType{Methods: []InterfaceTypeMethod{closeMethod, {Name: "Reset", Func: FuncType{IsVariadic: true}}}},
+	)
+	assert.Equal(t, []InterfaceTypeMethod{flushMethod, resetMethod}, result.Methods)
+
+	reader := QualType{Package: "io", ShortPackagePath: "io", Name: "Reader"}
+	closer := QualType{Package: "io", ShortPackagePath: "io", Name: "Closer"}
+	numbers := []TypeTerm{{Type: PrimitiveType{Kind: PrimitiveKindInt}.Type()}}
+	texts := []TypeTerm{{Tilde: true, Type: PrimitiveType{Kind: PrimitiveKindString}.Type()}}
+	result = SubtractInterface(
+		InterfaceType{Embedded: []QualType{reader, closer}, Unions: [][]TypeTerm{numbers, texts}},
+		InterfaceType{Embedded: []QualType{closer}, Unions: [][]TypeTerm{numbers}},
+	)
+	assert.Equal(t, []QualType{reader}, result.Embedded)
+	assert.Equal(t, [][]TypeTerm{texts}, result.Unions)
+}
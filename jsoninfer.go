@@ -0,0 +1,429 @@
+package gotype
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// uuidPattern matches the textual representation of the UUIDs, like "123e4567-e89b-12d3-a456-426614174000".
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// jsonInitialisms contains the initialisms kept upper case by the Golang's naming conventions, which are applied to the
+// names of the inferred fields and types, like `UserID` for "user_id".
+var jsonInitialisms = map[string]struct{}{
+	"ACL": {}, "API": {}, "ASCII": {}, "CPU": {}, "CSS": {}, "DNS": {}, "EOF": {}, "GUID": {}, "HTML": {}, "HTTP": {},
+	"HTTPS": {}, "ID": {}, "IP": {}, "JSON": {}, "LHS": {}, "QPS": {}, "RAM": {}, "RHS": {}, "RPC": {}, "SLA": {},
+	"SMTP": {}, "SQL": {}, "SSH": {}, "TCP": {}, "TLS": {}, "TTL": {}, "UDP": {}, "UI": {}, "UID": {}, "UUID": {},
+	"URI": {}, "URL": {}, "UTF8": {}, "VM": {}, "XML": {}, "XMPP": {}, "XSRF": {}, "XSS": {},
+}
+
+// InferredType is a named type inferred from JSON documents by InferTypesFromJSON.
+type InferredType struct {
+	// Name contains the type's name.
+	Name string
+
+	// Type contains the type's definition. The other inferred types are referenced as QualTypes without a package,
+	// whose Underlying contain their definitions.
+	Type Type
+
+	// Consts contains the constants of the type, like the values of a JSON Schema's enum.
+	Consts []InferredConst
+
+	// Implements contains the names of the sealed interfaces implemented by the type, like the interfaces imported
+	// from the JSON Schema's oneOf. A sealed interface `Pet` has the single method `isPet()`.
+	Implements []string
+}
+
+// InferredConst is a constant of an InferredType.
+type InferredConst struct {
+	// Name contains the constant's name.
+	Name string
+
+	// Value contains the constant's value as a Golang's literal, like `"active"` for a string.
+	Value string
+}
+
+// JSONInferenceOption configures InferTypesFromJSON.
+type JSONInferenceOption func(*jsonInferrer)
+
+// WithUUIDType sets the type of the strings which are UUIDs in all the samples. By default, they're inferred as
+// `uuid.UUID` of the "github.com/google/uuid" package. Pass the `string` PrimitiveType to disable the detection.
+func WithUUIDType(typ Type) JSONInferenceOption {
+	return func(i *jsonInferrer) {
+		i.uuidType = typ
+	}
+}
+
+// WithoutTimeDetection infers the strings formatted by RFC 3339 as `string` instead of `time.Time`.
+func WithoutTimeDetection() JSONInferenceOption {
+	return func(i *jsonInferrer) {
+		i.detectTime = false
+	}
+}
+
+// jsonInferrer infers the types of the sampled JSON values.
+type jsonInferrer struct {
+	uuidType   Type
+	detectTime bool
+
+	// types contains the inferred named types, in the order of their first reference.
+	types []InferredType
+	used  map[string]struct{}
+}
+
+// InferTypesFromJSON infers the Golang's types of the JSON `documents`, which are samples of the same schema, like the
+// payloads of an API. It's the inverse of the schema generators like MongoJSONSchema. The root type is named
+// `rootName`, and it's the first of the returned types, followed by the types of the nested objects, named after their
+// fields, in the order of their first appearance.
+//
+// The fields of the objects are the union of the fields of all the samples, named by the Golang's conventions, like
+// `UserID` for "user_id", and tagged by their JSON names. The fields missing from some of the samples are optional and
+// have the `omitempty` option, and the optional and the null fields are pointers, unless their values are nilable
+// already. The numbers are `int64` when all their samples are integers, and `float64` otherwise. The strings are
+// `time.Time` when all their samples are formatted by RFC 3339, and UUIDs when all their samples are UUIDs, see
+// WithUUIDType. The values whose samples have different types, or are always null, are `interface{}`.
+//
+// Use RenderInferredTypes to render the types' declarations.
+func InferTypesFromJSON(rootName string, documents [][]byte, opts ...JSONInferenceOption) ([]InferredType, error) {
+	if len(documents) == 0 {
+		return nil, errors.New("cannot infer types without JSON documents")
+	}
+	i := &jsonInferrer{
+		uuidType: Type{QualType: &QualType{
+			Package:          "github.com/google/uuid",
+			ShortPackagePath: "uuid",
+			Name:             "UUID",
+		}},
+		detectTime: true,
+		used:       map[string]struct{}{rootName: {}},
+	}
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	root := &jsonShape{}
+	for n, document := range documents {
+		decoder := json.NewDecoder(bytes.NewReader(document))
+		decoder.UseNumber()
+		value, err := decodeJSONValue(decoder)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode JSON document %d: %w", n+1, err)
+		}
+		if _, err := decoder.Token(); err != io.EOF {
+			return nil, fmt.Errorf("cannot decode JSON document %d: unexpected data after the value", n+1)
+		}
+		root.add(value)
+	}
+
+	if root.kinds&^jsonShapeNull == jsonShapeObject {
+		definition := i.structType(root)
+		i.types = append([]InferredType{{Name: rootName, Type: definition}}, i.types...)
+		return i.types, nil
+	}
+	// the root type is declared first, so it's named before the types nested inside it.
+	i.types = append(i.types, InferredType{Name: rootName})
+	i.types[0].Type = i.typeOf(root, rootName)
+	return i.types, nil
+}
+
+// jsonShapeKinds is a set of the kinds of the sampled JSON values.
+type jsonShapeKinds int
+
+const (
+	jsonShapeNull jsonShapeKinds = 1 << iota
+	jsonShapeBool
+	jsonShapeInteger
+	jsonShapeFloat
+	jsonShapeString
+	jsonShapeArray
+	jsonShapeObject
+)
+
+// jsonShape merges the samples of a JSON value.
+type jsonShape struct {
+	kinds jsonShapeKinds
+
+	// notTime and notUUID are set when a sampled string isn't a time or a UUID.
+	notTime bool
+	notUUID bool
+
+	// elem merges the elements of the sampled arrays.
+	elem *jsonShape
+
+	// objects contains the number of the sampled objects, and fields merges their fields, keyed by their JSON names in
+	// the order of their first appearance.
+	objects    int
+	fieldNames []string
+	fields     map[string]*jsonShape
+	counts     map[string]int
+}
+
+func (s *jsonShape) add(value interface{}) {
+	switch value := value.(type) {
+	case nil:
+		s.kinds |= jsonShapeNull
+	case bool:
+		s.kinds |= jsonShapeBool
+	case json.Number:
+		if strings.ContainsAny(value.String(), ".eE") {
+			s.kinds |= jsonShapeFloat
+		} else {
+			s.kinds |= jsonShapeInteger
+		}
+	case string:
+		s.kinds |= jsonShapeString
+		if _, err := time.Parse(time.RFC3339Nano, value); err != nil {
+			s.notTime = true
+		}
+		if !uuidPattern.MatchString(value) {
+			s.notUUID = true
+		}
+	case []interface{}:
+		s.kinds |= jsonShapeArray
+		if s.elem == nil {
+			s.elem = &jsonShape{}
+		}
+		for _, elem := range value {
+			s.elem.add(elem)
+		}
+	case jsonObject:
+		s.kinds |= jsonShapeObject
+		s.objects++
+		if s.fields == nil {
+			s.fields = make(map[string]*jsonShape)
+			s.counts = make(map[string]int)
+		}
+		for n, name := range value.keys {
+			if _, ok := s.fields[name]; !ok {
+				s.fieldNames = append(s.fieldNames, name)
+				s.fields[name] = &jsonShape{}
+			}
+			s.fields[name].add(value.values[n])
+			s.counts[name]++
+		}
+	}
+}
+
+// jsonObject is a decoded JSON object, keeping the order of its keys. The duplicate keys are kept as well.
+type jsonObject struct {
+	keys   []string
+	values []interface{}
+}
+
+// decodeJSONValue decodes the next JSON value, like `json.Decoder.Decode` decodes it into an `interface{}`, except that
+// the objects are decoded as jsonObjects, so the inferred fields follow the order of the samples.
+func decodeJSONValue(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('['):
+		array := make([]interface{}, 0)
+		for decoder.More() {
+			elem, err := decodeJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, elem)
+		}
+		_, err := decoder.Token()
+		return array, err
+	case json.Delim('{'):
+		object := jsonObject{}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object.keys = append(object.keys, key.(string))
+			object.values = append(object.values, value)
+		}
+		_, err := decoder.Token()
+		return object, err
+	}
+	return token, nil
+}
+
+// typeOf returns the type of the sampled values. The objects are declared as types named `name`.
+func (i *jsonInferrer) typeOf(s *jsonShape, name string) Type {
+	switch s.kinds &^ jsonShapeNull {
+	case jsonShapeBool:
+		return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindBool}}
+	case jsonShapeInteger:
+		return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindInt64}}
+	case jsonShapeFloat, jsonShapeInteger | jsonShapeFloat:
+		return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindFloat64}}
+	case jsonShapeString:
+		switch {
+		case i.detectTime && !s.notTime:
+			return Type{QualType: &QualType{Package: "time", ShortPackagePath: "time", Name: "Time"}}
+		case !s.notUUID:
+			return i.uuidType
+		}
+		return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindString}}
+	case jsonShapeArray:
+		return Type{SliceType: &SliceType{Elem: i.typeOf(s.elem, singularize(name))}}
+	case jsonShapeObject:
+		name = uniqueName(name, i.used)
+		n := len(i.types)
+		// the type is declared before the types nested inside it.
+		i.types = append(i.types, InferredType{Name: name})
+		definition := i.structType(s)
+		i.types[n].Type = definition
+		return Type{QualType: &QualType{Name: name, Underlying: &definition}}
+	}
+	return Type{InterfaceType: &InterfaceType{}}
+}
+
+func (i *jsonInferrer) structType(s *jsonShape) Type {
+	structType := &StructType{Fields: make([]TypeField, 0, len(s.fieldNames))}
+	used := make(map[string]struct{})
+	for _, jsonName := range s.fieldNames {
+		shape := s.fields[jsonName]
+		name := uniqueName(goIdentifier(jsonName), used)
+		typ := i.typeOf(shape, name)
+
+		tag := jsonName
+		optional := s.counts[jsonName] < s.objects
+		if optional {
+			tag += ",omitempty"
+		}
+		// the unresolved types, like time.Time, are pointers too, since they may be structs.
+		if (optional || shape.kinds&jsonShapeNull != 0) && !typ.IsNilable() {
+			typ = Type{PtrType: &PtrType{Elem: typ}}
+		}
+		structType.Fields = append(structType.Fields, TypeField{
+			Name: name,
+			Type: typ,
+			Tag:  reflect.StructTag(fmt.Sprintf("json:%q", tag)),
+		})
+	}
+	return Type{StructType: structType}
+}
+
+// goIdentifier converts a JSON name into an exported Golang's identifier, like `UserID` for "user_id" and "userId".
+func goIdentifier(jsonName string) string {
+	name := camelCaseWords(jsonName)
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "Field" + name
+	}
+	return name
+}
+
+// camelCaseWords joins the words of `s` in the upper camel case, keeping the initialisms upper case. The result may be
+// empty or start with a digit.
+func camelCaseWords(s string) string {
+	words := make([]string, 0)
+	word := make([]rune, 0)
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = word[:0]
+		}
+	}
+	runes := []rune(s)
+	for n, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+			continue
+		case unicode.IsUpper(r) && n > 0 && len(word) > 0:
+			prevLower := unicode.IsLower(runes[n-1]) || unicode.IsDigit(runes[n-1])
+			nextLower := n+1 < len(runes) && unicode.IsLower(runes[n+1])
+			if prevLower || (unicode.IsUpper(runes[n-1]) && nextLower) {
+				flush()
+			}
+		}
+		word = append(word, r)
+	}
+	flush()
+
+	var b strings.Builder
+	for _, word := range words {
+		if _, ok := jsonInitialisms[strings.ToUpper(word)]; ok {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+		b.WriteString(exportedName(strings.ToLower(word)))
+	}
+	return b.String()
+}
+
+// singularize returns the singular form of an english noun using the common rules, reversing pluralize. The names
+// which can't be singularized are suffixed by "Item", like "DataItem".
+func singularize(noun string) string {
+	switch {
+	case strings.HasSuffix(noun, "ies") && len(noun) > 3:
+		return noun[:len(noun)-3] + "y"
+	case strings.HasSuffix(noun, "ses"), strings.HasSuffix(noun, "xes"), strings.HasSuffix(noun, "zes"),
+		strings.HasSuffix(noun, "ches"), strings.HasSuffix(noun, "shes"):
+		return noun[:len(noun)-2]
+	case strings.HasSuffix(noun, "s") && !strings.HasSuffix(noun, "ss") && !strings.HasSuffix(noun, "us") &&
+		!strings.HasSuffix(noun, "is") && len(noun) > 1:
+		return noun[:len(noun)-1]
+	}
+	return noun + "Item"
+}
+
+// RenderInferredTypes renders the declarations of the types inferred by InferTypesFromJSON or imported by
+// ImportJSONSchema, followed by their constants and the methods implementing their sealed interfaces.
+//
+// The rendered code only contains the declarations, the package clause and imports are left to the caller.
+func RenderInferredTypes(types []InferredType) string {
+	w := &codeWriter{}
+	for n, typ := range types {
+		if n > 0 {
+			w.line(0, "")
+		}
+		switch {
+		case typ.Type.StructType != nil:
+			w.line(0, "type %s struct {", typ.Name)
+			for _, field := range typ.Type.StructType.Fields {
+				declaration := field.Name + " " + field.Type.String("")
+				if field.Embedded {
+					declaration = field.Type.String("")
+				}
+				if field.Tag == "" {
+					w.line(1, "%s", declaration)
+				} else {
+					w.line(1, "%s `%s`", declaration, field.Tag)
+				}
+			}
+			w.line(0, "}")
+		case typ.Type.InterfaceType != nil && len(typ.Type.InterfaceType.Methods) > 0:
+			w.line(0, "type %s interface {", typ.Name)
+			for _, method := range typ.Type.InterfaceType.Methods {
+				w.line(1, "%s%s", method.Name, strings.TrimPrefix(method.Func.String(""), "func"))
+			}
+			w.line(0, "}")
+		default:
+			w.line(0, "type %s %s", typ.Name, typ.Type.String(""))
+		}
+		if len(typ.Consts) > 0 {
+			w.line(0, "")
+			w.line(0, "const (")
+			for _, constant := range typ.Consts {
+				w.line(1, "%s %s = %s", constant.Name, typ.Name, constant.Value)
+			}
+			w.line(0, ")")
+		}
+		for _, sealed := range typ.Implements {
+			w.line(0, "")
+			w.line(0, "func (%s) is%s() {}", typ.Name, sealed)
+		}
+	}
+	return w.b.String()
+}
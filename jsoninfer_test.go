@@ -0,0 +1,110 @@
+package gotype
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInferTypesFromJSON(t *testing.T) {
+	types, err := InferTypesFromJSON("User", [][]byte{
+		[]byte(`{
+			"id": "123e4567-e89b-12d3-a456-426614174000",
+			"user_name": "alice",
+			"age": 30,
+			"score": 1,
+			"createdAt": "2021-01-02T03:04:05Z",
+			"address": {"city": "Paris", "zip": "75001"},
+			"tags": ["a", "b"],
+			"addresses": [{"city": "Paris"}],
+			"manager": null,
+			"extra": 1
+		}`),
+		[]byte(`{
+			"id": "00000000-0000-0000-0000-000000000000",
+			"user_name": "bob",
+			"age": null,
+			"score": 2.5,
+			"createdAt": "2022-01-02T03:04:05.123+02:00",
+			"address": {"city": "Rome", "zip": "00100", "street": "Via Roma"},
+			"tags": [],
+			"addresses": [{"city": "Rome", "country": "IT"}],
+			"manager": null,
+			"extra": "one",
+			"homepage_url": "https://example.com"
+		}`),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, `type User struct {
+	ID uuid.UUID `+"`json:\"id\"`"+`
+	UserName string `+"`json:\"user_name\"`"+`
+	Age *int64 `+"`json:\"age\"`"+`
+	Score float64 `+"`json:\"score\"`"+`
+	CreatedAt time.Time `+"`json:\"createdAt\"`"+`
+	Address Address `+"`json:\"address\"`"+`
+	Tags []string `+"`json:\"tags\"`"+`
+	Addresses []Address2 `+"`json:\"addresses\"`"+`
+	Manager interface{} `+"`json:\"manager\"`"+`
+	Extra interface{} `+"`json:\"extra\"`"+`
+	HomepageURL *string `+"`json:\"homepage_url,omitempty\"`"+`
+}
+
+type Address struct {
+	City string `+"`json:\"city\"`"+`
+	Zip string `+"`json:\"zip\"`"+`
+	Street *string `+"`json:\"street,omitempty\"`"+`
+}
+
+type Address2 struct {
+	City string `+"`json:\"city\"`"+`
+	Country *string `+"`json:\"country,omitempty\"`"+`
+}
+`, RenderInferredTypes(types))
+
+	require.Len(t, types, 3)
+	address := types[0].Type.StructType.Fields[5].Type
+	require.NotNil(t, address.QualType)
+	assert.Equal(t, types[1].Type, *address.QualType.Underlying)
+}
+
+func TestInferTypesFromJSON_Options(t *testing.T) {
+	types, err := InferTypesFromJSON("Events", [][]byte{
+		[]byte(`[{"event_id": "123e4567-e89b-12d3-a456-426614174000", "at": "2021-01-02T03:04:05Z"}]`),
+	}, WithUUIDType(Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindString}}), WithoutTimeDetection())
+	require.NoError(t, err)
+	assert.Equal(t, `type Events []Event
+
+type Event struct {
+	EventID string `+"`json:\"event_id\"`"+`
+	At string `+"`json:\"at\"`"+`
+}
+`, RenderInferredTypes(types))
+
+	types, err = InferTypesFromJSON("Data", [][]byte{[]byte(`[[1, 2], [3]]`)})
+	require.NoError(t, err)
+	assert.Equal(t, "type Data [][]int64\n", RenderInferredTypes(types))
+
+	_, err = InferTypesFromJSON("User", nil)
+	assert.EqualError(t, err, "cannot infer types without JSON documents")
+	_, err = InferTypesFromJSON("User", [][]byte{[]byte(`{}`), []byte(`{"a": }`)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot decode JSON document 2")
+	_, err = InferTypesFromJSON("User", [][]byte{[]byte(`{} {}`)})
+	assert.EqualError(t, err, "cannot decode JSON document 1: unexpected data after the value")
+}
+
+func TestGoIdentifier(t *testing.T) {
+	for jsonName, name := range map[string]string{
+		"user_id":     "UserID",
+		"userId":      "UserID",
+		"HTTPStatus":  "HTTPStatus",
+		"first-name":  "FirstName",
+		"2fa_enabled": "Field2faEnabled",
+		"":            "Field",
+		"api_url":     "APIURL",
+	} {
+		assert.Equal(t, name, goIdentifier(jsonName), jsonName)
+	}
+}
@@ -0,0 +1,595 @@
+package gotype
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ImportJSONSchema converts the JSON Schema `schema` into Golang's types, like the ones inferred by
+// InferTypesFromJSON, so RenderInferredTypes renders their declarations. The root type is named `rootName`, and it's
+// the first of the returned types, followed by the other declared types in the order of their first reference.
+//
+// The objects having properties are structs, whose fields are named by the Golang's conventions and tagged by their
+// JSON names. The fields which aren't required have the `omitempty` option, and the optional and the nullable fields
+// are pointers, unless their values are nilable already. The objects without properties are maps, whose values are
+// described by `additionalProperties`. The properties of the `allOf` subschemas are merged into a single struct.
+//
+// The integers are `int64` and the numbers are `float64`, unless their format is "int32" or "float". The strings are
+// `time.Time` for the "date-time" format, `uuid.UUID` of the "github.com/google/uuid" package for the "uuid" format,
+// and `[]byte` for the "byte" format and the base64 content encoding.
+//
+// The references within the schema, like "#/$defs/Pet", are declared as types named after the last segment of their
+// paths. A reference to a type which is being declared, like the reference of a recursive type, is a QualType without
+// Underlying. The enums are declared as types having a constant per value, named after the type and the value, like
+// `StatusActive` for the value "active" of `Status`.
+//
+// A `oneOf` or `anyOf` whose alternatives are all objects is declared as a sealed interface, which is listed by the
+// Implements of the alternatives' types. A nullable alternative, like `{"type": "null"}`, makes the value nullable.
+// The other alternatives, as well as the schemas allowing several types, are `interface{}`, unless the alternatives
+// have the same type.
+func ImportJSONSchema(rootName string, schema []byte) ([]InferredType, error) {
+	decoder := json.NewDecoder(bytes.NewReader(schema))
+	decoder.UseNumber()
+	root, err := decodeJSONValue(decoder)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode JSON schema: %w", err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("cannot decode JSON schema: unexpected data after the value")
+	}
+
+	i := &jsonSchemaImporter{
+		root: root,
+		used: map[string]struct{}{rootName: {}},
+		// the root type is being declared by any reference to it.
+		refs: map[string]Type{"#": {QualType: &QualType{Name: rootName}}},
+	}
+	rootObject, _ := root.(jsonObject)
+	if _, err := i.declare(rootName, rootObject); err != nil {
+		return nil, err
+	}
+	return i.types, nil
+}
+
+// jsonSchemaImporter converts the schemas of a JSON Schema document.
+type jsonSchemaImporter struct {
+	root interface{}
+
+	// types contains the declared types, in the order of their first reference.
+	types []InferredType
+	used  map[string]struct{}
+
+	// refs contains the types declared for the references, keyed by the references.
+	refs map[string]Type
+}
+
+func (o jsonObject) get(key string) (interface{}, bool) {
+	for n := len(o.keys) - 1; n >= 0; n-- {
+		if o.keys[n] == key {
+			return o.values[n], true
+		}
+	}
+	return nil, false
+}
+
+func (o jsonObject) getString(key string) string {
+	value, _ := o.get(key)
+	s, _ := value.(string)
+	return s
+}
+
+func (o jsonObject) getObject(key string) (jsonObject, bool) {
+	value, _ := o.get(key)
+	object, ok := value.(jsonObject)
+	return object, ok
+}
+
+func (o jsonObject) getArray(key string) []interface{} {
+	value, _ := o.get(key)
+	array, _ := value.([]interface{})
+	return array
+}
+
+// typeOf returns the type of the values described by `schema`. The enums, the objects having properties and the
+// sealed interfaces are declared as types named `name`.
+func (i *jsonSchemaImporter) typeOf(schema interface{}, name string) (Type, error) {
+	object, ok := schema.(jsonObject)
+	if !ok {
+		return Type{InterfaceType: &InterfaceType{}}, nil
+	}
+	if ref := object.getString("$ref"); ref != "" {
+		return i.ref(ref)
+	}
+	isObject, err := i.isObject(object)
+	if err != nil {
+		return Type{}, err
+	}
+	alternatives, err := i.sealedAlternatives(object)
+	if err != nil {
+		return Type{}, err
+	}
+	if len(jsonSchemaEnum(object)) > 0 || isObject || len(alternatives) > 0 {
+		return i.declare(uniqueName(name, i.used), object)
+	}
+	return i.definition(object, name)
+}
+
+// declare declares the type `name` described by `schema`. The name must be unique already.
+func (i *jsonSchemaImporter) declare(name string, schema jsonObject) (Type, error) {
+	n := len(i.types)
+	// the type is declared before the types nested inside it.
+	i.types = append(i.types, InferredType{Name: name})
+	definition, err := i.definition(schema, name)
+	if err != nil {
+		return Type{}, err
+	}
+	i.types[n].Type = definition
+	i.types[n].Consts = i.enumConsts(name, jsonSchemaEnum(schema))
+	return Type{QualType: &QualType{Name: name, Underlying: &definition}}, nil
+}
+
+// ref returns the type declared for the reference `ref`, declaring it on the first reference.
+func (i *jsonSchemaImporter) ref(ref string) (Type, error) {
+	if typ, ok := i.refs[ref]; ok {
+		return typ, nil
+	}
+	schema, name, err := i.resolve(ref)
+	if err != nil {
+		return Type{}, err
+	}
+	name = uniqueName(goIdentifier(name), i.used)
+	i.refs[ref] = Type{QualType: &QualType{Name: name}}
+	object, _ := schema.(jsonObject)
+	typ, err := i.declare(name, object)
+	if err != nil {
+		return Type{}, err
+	}
+	i.refs[ref] = typ
+	return typ, nil
+}
+
+// resolve returns the schema referenced by the JSON pointer `ref`, and the last segment of its path.
+func (i *jsonSchemaImporter) resolve(ref string) (interface{}, string, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, "", fmt.Errorf("unsupported reference %q: only the references within the schema are supported", ref)
+	}
+	schema := i.root
+	name := ""
+	for _, segment := range strings.Split(strings.TrimPrefix(ref, "#"), "/")[1:] {
+		name = strings.NewReplacer("~1", "/", "~0", "~").Replace(segment)
+		switch value := schema.(type) {
+		case jsonObject:
+			next, ok := value.get(name)
+			if !ok {
+				return nil, "", fmt.Errorf("cannot resolve reference %q: %q not found", ref, name)
+			}
+			schema = next
+		case []interface{}:
+			index, err := strconv.Atoi(name)
+			if err != nil || index < 0 || index >= len(value) {
+				return nil, "", fmt.Errorf("cannot resolve reference %q: %q not found", ref, name)
+			}
+			schema = value[index]
+		default:
+			return nil, "", fmt.Errorf("cannot resolve reference %q: %q not found", ref, name)
+		}
+	}
+	return schema, name, nil
+}
+
+// resolveObject follows the references of `schema` without declaring them.
+func (i *jsonSchemaImporter) resolveObject(schema interface{}) (jsonObject, bool) {
+	for depth := 0; depth < 32; depth++ {
+		object, ok := schema.(jsonObject)
+		if !ok {
+			return jsonObject{}, false
+		}
+		ref := object.getString("$ref")
+		if ref == "" {
+			return object, true
+		}
+		if schema, _, _ = i.resolve(ref); schema == nil {
+			return jsonObject{}, false
+		}
+	}
+	return jsonObject{}, false
+}
+
+// isObject reports whether the schema describes the objects having properties, which are declared as structs.
+func (i *jsonSchemaImporter) isObject(schema jsonObject) (bool, error) {
+	return i.isObjectVisiting(schema, make(map[string]struct{}))
+}
+
+func (i *jsonSchemaImporter) isObjectVisiting(schema jsonObject, visiting map[string]struct{}) (bool, error) {
+	if _, ok := schema.get("properties"); ok {
+		return true, nil
+	}
+	for _, subschema := range schema.getArray("allOf") {
+		ref, err := enterSubschema(subschema, visiting)
+		if err != nil {
+			return false, err
+		}
+		if object, ok := i.resolveObject(subschema); ok {
+			isObject, err := i.isObjectVisiting(object, visiting)
+			if err != nil || isObject {
+				return isObject, err
+			}
+		}
+		delete(visiting, ref)
+	}
+	return false, nil
+}
+
+// enterSubschema adds the reference of the allOf subschema to `visiting`, which contains the references of the
+// subschemas being visited, and returns it. A reference which is visited already is an allOf including itself.
+func enterSubschema(subschema interface{}, visiting map[string]struct{}) (string, error) {
+	object, _ := subschema.(jsonObject)
+	ref := object.getString("$ref")
+	if ref == "" {
+		return "", nil
+	}
+	if _, ok := visiting[ref]; ok {
+		return "", fmt.Errorf("cannot resolve reference %q: allOf includes itself", ref)
+	}
+	visiting[ref] = struct{}{}
+	return ref, nil
+}
+
+// sealedAlternatives returns the alternatives of the schema's oneOf or anyOf when they're all objects, so they're
+// declared as the implementations of a sealed interface.
+func (i *jsonSchemaImporter) sealedAlternatives(schema jsonObject) ([]interface{}, error) {
+	alternatives := jsonSchemaAlternatives(schema)
+	if len(alternatives) < 2 {
+		return nil, nil
+	}
+	for _, alternative := range alternatives {
+		object, ok := i.resolveObject(alternative)
+		if !ok {
+			return nil, nil
+		}
+		if isObject, err := i.isObject(object); err != nil || !isObject {
+			return nil, err
+		}
+	}
+	return alternatives, nil
+}
+
+// definition returns the definition of the type described by `schema`, whose nested types are named after `name`.
+func (i *jsonSchemaImporter) definition(schema jsonObject, name string) (Type, error) {
+	if ref := schema.getString("$ref"); ref != "" {
+		return i.ref(ref)
+	}
+	isObject, err := i.isObject(schema)
+	if err != nil {
+		return Type{}, err
+	}
+	if isObject {
+		return i.structType(schema)
+	}
+	if _, ok := schema.get("oneOf"); ok {
+		return i.unionType(schema, name)
+	}
+	if _, ok := schema.get("anyOf"); ok {
+		return i.unionType(schema, name)
+	}
+	if values := jsonSchemaEnum(schema); len(values) > 0 {
+		return jsonEnumType(schema, values), nil
+	}
+	if value, ok := schema.get("const"); ok {
+		return jsonEnumType(schema, []interface{}{value}), nil
+	}
+	if allOf := schema.getArray("allOf"); len(allOf) == 1 {
+		return i.typeOf(allOf[0], name)
+	}
+
+	types, _ := jsonSchemaTypes(schema)
+	if len(types) == 0 {
+		if _, ok := schema.get("items"); ok {
+			types = []string{"array"}
+		} else if _, ok := schema.get("additionalProperties"); ok {
+			types = []string{"object"}
+		}
+	}
+	if len(types) != 1 {
+		return Type{InterfaceType: &InterfaceType{}}, nil
+	}
+	switch types[0] {
+	case "boolean":
+		return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindBool}}, nil
+	case "integer":
+		if schema.getString("format") == "int32" {
+			return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindInt32}}, nil
+		}
+		return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindInt64}}, nil
+	case "number":
+		if schema.getString("format") == "float" {
+			return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindFloat32}}, nil
+		}
+		return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindFloat64}}, nil
+	case "string":
+		switch {
+		case schema.getString("format") == "date-time":
+			return Type{QualType: &QualType{Package: "time", ShortPackagePath: "time", Name: "Time"}}, nil
+		case schema.getString("format") == "uuid":
+			return Type{QualType: &QualType{
+				Package:          "github.com/google/uuid",
+				ShortPackagePath: "uuid",
+				Name:             "UUID",
+			}}, nil
+		case schema.getString("format") == "byte", schema.getString("contentEncoding") == "base64":
+			return Type{SliceType: &SliceType{Elem: Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindByte}}}}, nil
+		}
+		return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindString}}, nil
+	case "array":
+		items, _ := schema.get("items")
+		elem, err := i.typeOf(items, singularize(name))
+		if err != nil {
+			return Type{}, err
+		}
+		return Type{SliceType: &SliceType{Elem: elem}}, nil
+	case "object":
+		additional, _ := schema.get("additionalProperties")
+		elem, err := i.typeOf(additional, name+"Value")
+		if err != nil {
+			return Type{}, err
+		}
+		return Type{MapType: &MapType{Key: Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindString}}, Elem: elem}}, nil
+	}
+	return Type{InterfaceType: &InterfaceType{}}, nil
+}
+
+// jsonSchemaProperty is a property of an object's schema.
+type jsonSchemaProperty struct {
+	name     string
+	schema   interface{}
+	required bool
+}
+
+// properties returns the properties of the object's schema, merging the properties of its allOf subschemas.
+func (i *jsonSchemaImporter) properties(
+	schema jsonObject, properties []jsonSchemaProperty, visiting map[string]struct{},
+) ([]jsonSchemaProperty, error) {
+	for _, subschema := range schema.getArray("allOf") {
+		ref, err := enterSubschema(subschema, visiting)
+		if err != nil {
+			return nil, err
+		}
+		if object, ok := i.resolveObject(subschema); ok {
+			if properties, err = i.properties(object, properties, visiting); err != nil {
+				return nil, err
+			}
+		}
+		delete(visiting, ref)
+	}
+	if object, ok := schema.getObject("properties"); ok {
+		for n, name := range object.keys {
+			found := false
+			for k := range properties {
+				if properties[k].name == name {
+					properties[k].schema = object.values[n]
+					found = true
+				}
+			}
+			if !found {
+				properties = append(properties, jsonSchemaProperty{name: name, schema: object.values[n]})
+			}
+		}
+	}
+	for _, required := range schema.getArray("required") {
+		for k := range properties {
+			if properties[k].name == required {
+				properties[k].required = true
+			}
+		}
+	}
+	return properties, nil
+}
+
+func (i *jsonSchemaImporter) structType(schema jsonObject) (Type, error) {
+	properties, err := i.properties(schema, nil, make(map[string]struct{}))
+	if err != nil {
+		return Type{}, err
+	}
+	structType := &StructType{Fields: make([]TypeField, 0, len(properties))}
+	used := make(map[string]struct{})
+	for _, property := range properties {
+		name := uniqueName(goIdentifier(property.name), used)
+		typ, err := i.typeOf(property.schema, name)
+		if err != nil {
+			return Type{}, err
+		}
+
+		tag := property.name
+		if !property.required {
+			tag += ",omitempty"
+		}
+		// the unresolved types, like time.Time and the references to the types being declared, are pointers too,
+		// since they may be structs, and the recursive structs need them.
+		if (!property.required || i.nullable(property.schema)) && !typ.IsNilable() {
+			typ = Type{PtrType: &PtrType{Elem: typ}}
+		}
+		structType.Fields = append(structType.Fields, TypeField{
+			Name: name,
+			Type: typ,
+			Tag:  reflect.StructTag(fmt.Sprintf("json:%q", tag)),
+		})
+	}
+	return Type{StructType: structType}, nil
+}
+
+// unionType returns the type of the schema's oneOf or anyOf. The alternatives which are all objects are declared as
+// the implementations of the sealed interface `name`.
+func (i *jsonSchemaImporter) unionType(schema jsonObject, name string) (Type, error) {
+	alternatives, err := i.sealedAlternatives(schema)
+	if err != nil {
+		return Type{}, err
+	}
+	if len(alternatives) > 0 {
+		for n, alternative := range alternatives {
+			alternativeName := name + strconv.Itoa(n+1)
+			if object, ok := alternative.(jsonObject); ok && object.getString("title") != "" {
+				alternativeName = goIdentifier(object.getString("title"))
+			}
+			typ, err := i.typeOf(alternative, alternativeName)
+			if err != nil {
+				return Type{}, err
+			}
+			for k := range i.types {
+				if i.types[k].Name == typ.QualType.Name {
+					i.types[k].Implements = append(i.types[k].Implements, name)
+				}
+			}
+		}
+		return Type{InterfaceType: &InterfaceType{Methods: []InterfaceTypeMethod{{Name: "is" + name}}}}, nil
+	}
+
+	var union *Type
+	for n, alternative := range jsonSchemaAlternatives(schema) {
+		typ, err := i.typeOf(alternative, name+strconv.Itoa(n+1))
+		if err != nil {
+			return Type{}, err
+		}
+		if union != nil && !Identical(*union, typ) {
+			return Type{InterfaceType: &InterfaceType{}}, nil
+		}
+		union = &typ
+	}
+	if union == nil {
+		return Type{InterfaceType: &InterfaceType{}}, nil
+	}
+	return *union, nil
+}
+
+// nullable reports whether the schema allows null.
+func (i *jsonSchemaImporter) nullable(schema interface{}) bool {
+	object, ok := i.resolveObject(schema)
+	if !ok {
+		return false
+	}
+	if _, nullable := jsonSchemaTypes(object); nullable {
+		return true
+	}
+	if nullable, _ := object.get("nullable"); nullable == true {
+		return true
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		for _, alternative := range object.getArray(key) {
+			if types, _ := jsonSchemaTypes(alternative); len(types) == 1 && types[0] == "null" {
+				return true
+			}
+		}
+	}
+	for _, value := range object.getArray("enum") {
+		if value == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (i *jsonSchemaImporter) enumConsts(typeName string, values []interface{}) []InferredConst {
+	consts := make([]InferredConst, 0, len(values))
+	for _, value := range values {
+		var literal, suffix string
+		switch value := value.(type) {
+		case string:
+			literal = strconv.Quote(value)
+			suffix = camelCaseWords(value)
+		case json.Number:
+			literal = value.String()
+			suffix = camelCaseWords(strings.Replace(value.String(), "-", "Minus", 1))
+		case bool:
+			literal = strconv.FormatBool(value)
+			suffix = exportedName(literal)
+		default:
+			continue
+		}
+		if suffix == "" {
+			suffix = "Empty"
+		}
+		consts = append(consts, InferredConst{Name: uniqueName(typeName+suffix, i.used), Value: literal})
+	}
+	if len(consts) == 0 {
+		return nil
+	}
+	return consts
+}
+
+// jsonSchemaTypes returns the types allowed by the schema's "type", except "null", and whether "null" is allowed.
+func jsonSchemaTypes(schema interface{}) ([]string, bool) {
+	object, _ := schema.(jsonObject)
+	value, _ := object.get("type")
+	values, ok := value.([]interface{})
+	if !ok {
+		values = []interface{}{value}
+	}
+	types := make([]string, 0, len(values))
+	nullable := false
+	for _, value := range values {
+		switch value {
+		case nil:
+		case "null":
+			nullable = true
+		default:
+			if s, ok := value.(string); ok {
+				types = append(types, s)
+			}
+		}
+	}
+	return types, nullable
+}
+
+// jsonSchemaEnum returns the values of the schema's enum, except null.
+func jsonSchemaEnum(schema jsonObject) []interface{} {
+	values := make([]interface{}, 0)
+	for _, value := range schema.getArray("enum") {
+		if value != nil {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// jsonSchemaAlternatives returns the alternatives of the schema's oneOf or anyOf, except the null alternatives.
+func jsonSchemaAlternatives(schema jsonObject) []interface{} {
+	alternatives := schema.getArray("oneOf")
+	if alternatives == nil {
+		alternatives = schema.getArray("anyOf")
+	}
+	result := make([]interface{}, 0, len(alternatives))
+	for _, alternative := range alternatives {
+		if types, nullable := jsonSchemaTypes(alternative); len(types) == 0 && nullable {
+			continue
+		}
+		result = append(result, alternative)
+	}
+	return result
+}
+
+// jsonEnumType returns the type of the enum's values, falling back to the schema's "type".
+func jsonEnumType(schema jsonObject, values []interface{}) Type {
+	shape := &jsonShape{}
+	for _, value := range values {
+		shape.add(value)
+	}
+	switch shape.kinds &^ jsonShapeNull {
+	case jsonShapeBool:
+		return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindBool}}
+	case jsonShapeString:
+		return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindString}}
+	case jsonShapeInteger:
+		if types, _ := jsonSchemaTypes(schema); len(types) == 1 && types[0] == "number" {
+			return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindFloat64}}
+		}
+		return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindInt64}}
+	case jsonShapeFloat, jsonShapeInteger | jsonShapeFloat:
+		return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindFloat64}}
+	}
+	return Type{InterfaceType: &InterfaceType{}}
+}
@@ -0,0 +1,122 @@
+package gotype
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportJSONSchema(t *testing.T) {
+	types, err := ImportJSONSchema("Order", []byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"required": ["id", "status", "items"],
+		"properties": {
+			"id": {"type": "string", "format": "uuid"},
+			"status": {"$ref": "#/$defs/Status"},
+			"items": {"type": "array", "items": {"$ref": "#/$defs/Item"}},
+			"created_at": {"type": "string", "format": "date-time"},
+			"note": {"type": ["string", "null"]},
+			"labels": {"type": "object", "additionalProperties": {"type": "integer", "format": "int32"}},
+			"payment": {"oneOf": [{"$ref": "#/$defs/Card"}, {"title": "bank transfer", "properties": {"iban": {}}}]},
+			"discount": {"anyOf": [{"type": "number"}, {"type": "null"}]},
+			"parent": {"$ref": "#"},
+			"priority": {"enum": [1, 2, 3]}
+		},
+		"$defs": {
+			"Status": {"type": "string", "enum": ["pending", "shipped", "on-hold"]},
+			"Item": {
+				"allOf": [{"$ref": "#/$defs/Named"}],
+				"required": ["quantity"],
+				"properties": {"quantity": {"type": "integer"}, "children": {"type": "array", "items": {"$ref": "#/$defs/Item"}}}
+			},
+			"Named": {"properties": {"name": {"type": "string"}}, "required": ["name"]},
+			"Card": {"properties": {"number": {"type": "string"}}}
+		}
+	}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, `type Order struct {
+	ID uuid.UUID `+"`json:\"id\"`"+`
+	Status Status `+"`json:\"status\"`"+`
+	Items []Item `+"`json:\"items\"`"+`
+	CreatedAt *time.Time `+"`json:\"created_at,omitempty\"`"+`
+	Note *string `+"`json:\"note,omitempty\"`"+`
+	Labels map[string]int32 `+"`json:\"labels,omitempty\"`"+`
+	Payment Payment `+"`json:\"payment,omitempty\"`"+`
+	Discount *float64 `+"`json:\"discount,omitempty\"`"+`
+	Parent *Order `+"`json:\"parent,omitempty\"`"+`
+	Priority *Priority `+"`json:\"priority,omitempty\"`"+`
+}
+
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusShipped Status = "shipped"
+	StatusOnHold Status = "on-hold"
+)
+
+type Item struct {
+	Name string `+"`json:\"name\"`"+`
+	Quantity int64 `+"`json:\"quantity\"`"+`
+	Children []Item `+"`json:\"children,omitempty\"`"+`
+}
+
+type Payment interface {
+	isPayment()
+}
+
+type Card struct {
+	Number *string `+"`json:\"number,omitempty\"`"+`
+}
+
+func (Card) isPayment() {}
+
+type BankTransfer struct {
+	Iban interface{} `+"`json:\"iban,omitempty\"`"+`
+}
+
+func (BankTransfer) isPayment() {}
+
+type Priority int64
+
+const (
+	Priority1 Priority = 1
+	Priority2 Priority = 2
+	Priority3 Priority = 3
+)
+`, RenderInferredTypes(types))
+
+	order := types[0].Type.StructType
+	// the references to the types which are being declared aren't resolved.
+	assert.Nil(t, order.Fields[8].Type.PtrType.Elem.QualType.Underlying)
+	assert.Nil(t, types[2].Type.StructType.Fields[2].Type.SliceType.Elem.QualType.Underlying)
+	assert.NotNil(t, order.Fields[2].Type.SliceType.Elem.QualType.Underlying)
+}
+
+func TestImportJSONSchema_Errors(t *testing.T) {
+	_, err := ImportJSONSchema("Root", []byte(`{"type": `))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot decode JSON schema")
+
+	_, err = ImportJSONSchema("Root", []byte(`{"properties": {"a": {"$ref": "other.json#/$defs/A"}}}`))
+	assert.EqualError(t, err,
+		`unsupported reference "other.json#/$defs/A": only the references within the schema are supported`)
+
+	_, err = ImportJSONSchema("Root", []byte(`{"properties": {"a": {"$ref": "#/$defs/A"}}}`))
+	assert.EqualError(t, err, `cannot resolve reference "#/$defs/A": "$defs" not found`)
+
+	_, err = ImportJSONSchema("Root", []byte(`{"allOf": [{"$ref": "#"}]}`))
+	assert.EqualError(t, err, `cannot resolve reference "#": allOf includes itself`)
+
+	_, err = ImportJSONSchema("Root", []byte(`{"properties": {"a": {"type": "string"}}, "allOf": [{"$ref": "#"}]}`))
+	assert.EqualError(t, err, `cannot resolve reference "#": allOf includes itself`)
+
+	_, err = ImportJSONSchema("Root", []byte(`{
+		"properties": {"a": {"$ref": "#/$defs/A"}},
+		"$defs": {"A": {"allOf": [{"$ref": "#/$defs/B"}]}, "B": {"allOf": [{"$ref": "#/$defs/A"}]}}
+	}`))
+	assert.EqualError(t, err, `cannot resolve reference "#/$defs/B": allOf includes itself`)
+}
@@ -0,0 +1,74 @@
+package gotype
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// getLocalDeclarationByName returns the declaration of the type declared inside a function body, whose `name` is
+// qualified by the name of the function, like "Handler.request", or by the names of the receiver's type and of the
+// method, like "Server.Serve.request". It also returns a copy of `importMap` scoped to the function: the type
+// parameters of the function or of the receiver, and the other types declared inside the body are recognized. The
+// blocks of the body aren't scoped, the first declaration of a name wins.
+func (f *astTypeGenerator) getLocalDeclarationByName(
+	fileAst *ast.File,
+	name string,
+	importMap map[string]string,
+) (*ast.TypeSpec, map[string]string) {
+	dot := strings.LastIndex(name, ".")
+	if dot < 0 {
+		return nil, importMap
+	}
+	funcName, localName := name[:dot], name[dot+1:]
+
+	funcDecl := f.getFuncDeclByName(fileAst, funcName)
+	if funcDecl == nil || funcDecl.Body == nil {
+		return nil, importMap
+	}
+
+	locals := make(map[string]*ast.TypeSpec)
+	ast.Inspect(funcDecl.Body, func(node ast.Node) bool {
+		if typeSpec, ok := node.(*ast.TypeSpec); ok {
+			if _, ok := locals[typeSpec.Name.Name]; !ok {
+				locals[typeSpec.Name.Name] = typeSpec
+			}
+		}
+		return true
+	})
+	spec, ok := locals[localName]
+	if !ok {
+		return nil, importMap
+	}
+
+	scoped := make(map[string]string, len(importMap)+len(locals))
+	for k, v := range f.withTypeParams(importMap, funcDecl.Type.TypeParams) {
+		scoped[k] = v
+	}
+	for _, ident := range f.getReceiverTypeParams(funcDecl) {
+		scoped[ident.Name+typeParamSuffix] = ident.Name
+	}
+	for local := range locals {
+		scoped[local+localTypeSuffix] = funcName + "." + local
+	}
+	return spec, scoped
+}
+
+// getFuncDeclByName returns the declaration of the function named `name`, or of the method named like
+// "Server.Serve".
+func (f *astTypeGenerator) getFuncDeclByName(fileAst *ast.File, name string) *ast.FuncDecl {
+	receiverName, funcName := "", name
+	if dot := strings.Index(name, "."); dot >= 0 {
+		receiverName, funcName = name[:dot], name[dot+1:]
+	}
+
+	for _, decl := range fileAst.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Name.Name != funcName {
+			continue
+		}
+		if f.getReceiverTypeName(funcDecl) == receiverName {
+			return funcDecl
+		}
+	}
+	return nil
+}
@@ -0,0 +1,308 @@
+package gotype
+
+import (
+	"fmt"
+	"go/ast"
+	"sort"
+	"strings"
+)
+
+// declaredMethod represents a method declared with a receiver.
+type declaredMethod struct {
+	method   InterfaceTypeMethod
+	receiver Receiver
+}
+
+// generateMethods returns the methods declared using `typeName` or `*typeName` as their receiver inside the package.
+// The methods are returned in the order of their declaration. Methods declared inside test files are ignored since
+// they are not part of the package's method set, and so are the unexported methods when `WithExportedOnly` is set.
+func (f *astTypeGenerator) generateMethods(packagePath, typeName string) ([]declaredMethod, error) {
+	goSources, err := f.getPackageSourceFiles(packagePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var declTypeParams []TypeParam
+	methods := make([]declaredMethod, 0)
+	for _, source := range goSources {
+		if strings.HasSuffix(source, "_test.go") {
+			continue
+		}
+
+		fileAst, err := f.parseAstFile(source)
+		if err != nil {
+			return nil, err
+		}
+
+		importMap := f.generateImportMap(packagePath, fileAst)
+		for _, decl := range fileAst.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || f.getReceiverTypeName(funcDecl) != typeName || f.isOmitted(funcDecl.Name.Name) {
+				continue
+			}
+
+			receiverTypeParams := f.getReceiverTypeParams(funcDecl)
+			if len(receiverTypeParams) > 0 && declTypeParams == nil {
+				types, err := f.generateTypesFromSpecs([]TypeSpec{{PackagePath: packagePath, Name: typeName}})
+				if err != nil {
+					return nil, err
+				}
+				declTypeParams = types[0].TypeParams
+			}
+
+			funcType, err := f.generateMethodFuncType(funcDecl, receiverTypeParams, declTypeParams, packagePath, importMap)
+			if err != nil {
+				return nil, err
+			}
+
+			methods = append(methods, declaredMethod{
+				method: InterfaceTypeMethod{
+					Name:     funcDecl.Name.String(),
+					Func:     funcType,
+					Doc:      funcDecl.Doc.Text(),
+					Position: f.position(funcDecl.Name.Pos()),
+				},
+				receiver: f.generateReceiver(funcDecl, receiverTypeParams, packagePath, importMap),
+			})
+		}
+	}
+
+	return methods, nil
+}
+
+// withMethods returns `typ`, generated from `spec`, with its `Type.Methods` filled when the generator is configured
+// using `WithMethods`.
+func (f *astTypeGenerator) withMethods(spec TypeSpec, typ Type) (Type, error) {
+	if !f.config.methods {
+		return typ, nil
+	}
+	if f.config.typeChecking {
+		methods, err := f.generateMethodsUsingTypeChecker(spec)
+		if err != nil {
+			return Type{}, err
+		}
+		typ.Methods = methods
+		return typ, nil
+	}
+
+	declared, err := f.generateMethods(spec.PackagePath, spec.Name)
+	if err != nil {
+		return Type{}, err
+	}
+	for _, m := range declared {
+		typ.Methods = append(typ.Methods, Method{
+			Name:            m.method.Name,
+			Func:            m.method.Func,
+			PointerReceiver: m.receiver.Pointer,
+			Doc:             m.method.Doc,
+			Position:        m.method.Position,
+		})
+	}
+	return typ, nil
+}
+
+// generateMethodFuncType generates the signature of a method. The receiver of a method of a generic type declares its
+// own names for the type's type parameters, like `U` in `func (r *Repo[U]) Get(id U) U`. They are bound to the type
+// parameters of the type declaration, so the method's signature refers to the type parameters using the names declared
+// by the type.
+func (f *astTypeGenerator) generateMethodFuncType(
+	funcDecl *ast.FuncDecl,
+	receiverTypeParams []*ast.Ident,
+	declTypeParams []TypeParam,
+	packagePath string,
+	importMap map[string]string,
+) (FuncType, error) {
+	if len(receiverTypeParams) == 0 {
+		return f.generateTypeFromFuncType(funcDecl.Type, packagePath, importMap)
+	}
+
+	if len(receiverTypeParams) != len(declTypeParams) {
+		return FuncType{}, fmt.Errorf(
+			"receiver of method %s has %d type parameters, but the type has %d",
+			funcDecl.Name.Name,
+			len(receiverTypeParams),
+			len(declTypeParams),
+		)
+	}
+
+	scoped := f.withTypeParams(importMap, &ast.FieldList{List: []*ast.Field{{Names: receiverTypeParams}}})
+	funcType, err := f.generateTypeFromFuncType(funcDecl.Type, packagePath, scoped)
+	if err != nil {
+		return FuncType{}, err
+	}
+
+	mapping := make(map[string]Type, len(receiverTypeParams))
+	for i, ident := range receiverTypeParams {
+		mapping[ident.Name] = TypeParamType{Name: declTypeParams[i].Name}.Type()
+	}
+	return substituteTypeParamsInFunc(funcType, mapping), nil
+}
+
+// generateReceiver generates the receiver of the method declaration `funcDecl`, whose type is declared inside the
+// package.
+func (f *astTypeGenerator) generateReceiver(
+	funcDecl *ast.FuncDecl,
+	receiverTypeParams []*ast.Ident,
+	packagePath string,
+	importMap map[string]string,
+) Receiver {
+	field := funcDecl.Recv.List[0]
+	_, isPointer := field.Type.(*ast.StarExpr)
+	unversionedPath, _ := splitPackageVersion(packagePath)
+	receiver := Receiver{
+		Pointer: isPointer,
+		Type: QualType{
+			Package:          unversionedPath,
+			ShortPackagePath: importMap[packagePath+"__short"],
+			Name:             f.getReceiverTypeName(funcDecl),
+		},
+	}
+	if len(field.Names) > 0 {
+		receiver.Name = field.Names[0].Name
+	}
+	for _, ident := range receiverTypeParams {
+		receiver.TypeParams = append(receiver.TypeParams, ident.Name)
+		receiver.Type.TypeArgs = append(receiver.Type.TypeArgs, TypeParamType{Name: ident.Name}.Type())
+	}
+	return receiver
+}
+
+// getReceiverTypeName returns the name of the receiver's type of a method declaration, or an empty string when
+// `funcDecl` is not a method.
+func (*astTypeGenerator) getReceiverTypeName(funcDecl *ast.FuncDecl) string {
+	if funcDecl.Recv == nil || len(funcDecl.Recv.List) == 0 {
+		return ""
+	}
+
+	expr := funcDecl.Recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	switch v := expr.(type) {
+	case *ast.IndexExpr:
+		expr = v.X
+	case *ast.IndexListExpr:
+		expr = v.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// getReceiverTypeParams returns the type parameters declared by the receiver of a method of a generic type, like `K`
+// and `V` in `func (m *Map[K, V]) Get(key K) V`.
+func (*astTypeGenerator) getReceiverTypeParams(funcDecl *ast.FuncDecl) []*ast.Ident {
+	if funcDecl.Recv == nil || len(funcDecl.Recv.List) == 0 {
+		return nil
+	}
+
+	expr := funcDecl.Recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+
+	var indices []ast.Expr
+	switch v := expr.(type) {
+	case *ast.IndexExpr:
+		indices = []ast.Expr{v.Index}
+	case *ast.IndexListExpr:
+		indices = v.Indices
+	}
+
+	params := make([]*ast.Ident, 0, len(indices))
+	for _, index := range indices {
+		if ident, ok := index.(*ast.Ident); ok {
+			params = append(params, ident)
+		}
+	}
+	return params
+}
+
+// methodSet returns the method set of the type having the `methods`, or the method set of the pointer to the type
+// when `pointer` is true.
+func methodSet(methods []declaredMethod, pointer bool) []InterfaceTypeMethod {
+	result := make([]InterfaceTypeMethod, 0, len(methods))
+	for _, m := range methods {
+		if pointer || !m.receiver.Pointer {
+			result = append(result, m.method)
+		}
+	}
+	return result
+}
+
+// getDeclaredTypeNames returns the names of the types declared at the top level of the package, in the order of their
+// declaration: the files are sorted by their names, see `getPackageSourceFiles`, and the types of a file by their
+// positions. Types declared inside test files are ignored, and so are the unexported types when `WithExportedOnly` is
+// set.
+func (f *astTypeGenerator) getDeclaredTypeNames(packagePath string) ([]string, error) {
+	goSources, err := f.getPackageSourceFiles(packagePath)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0)
+	for _, source := range goSources {
+		if strings.HasSuffix(source, "_test.go") {
+			continue
+		}
+
+		fileAst, err := f.parseAstFile(source)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range f.getFileTypeNames(fileAst) {
+			if !f.isOmitted(name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names, nil
+}
+
+// getFileTypeNames returns the names of the types declared at the top level of the file, in the order of their
+// declaration.
+func (*astTypeGenerator) getFileTypeNames(fileAst *ast.File) []string {
+	names := make([]string, 0)
+	for _, decl := range fileAst.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			if typeSpec, ok := spec.(*ast.TypeSpec); ok {
+				names = append(names, typeSpec.Name.Name)
+			}
+		}
+	}
+	return names
+}
+
+// expandPackagePatterns expands the package patterns using the sourceFinder. When the sourceFinder doesn't support
+// pattern expansion, the patterns are treated as package paths.
+func (f *astTypeGenerator) expandPackagePatterns(patterns ...string) ([]string, error) {
+	lister, ok := f.sourceFinder.(packageLister)
+	if !ok {
+		return patterns, nil
+	}
+
+	packages := make([]string, 0, len(patterns))
+	seen := make(map[string]struct{})
+	for _, pattern := range patterns {
+		matches, err := lister.ListPackages(pattern)
+		if err != nil {
+			return nil, err
+		}
+		matches = append([]string(nil), matches...)
+		sort.Strings(matches)
+		for _, match := range matches {
+			if _, ok := seen[match]; !ok {
+				seen[match] = struct{}{}
+				packages = append(packages, match)
+			}
+		}
+	}
+	return packages, nil
+}
@@ -0,0 +1,161 @@
+package gotype
+
+import "sort"
+
+// TypeMetrics contains the complexity metrics of a type declaration, computed by `TypeGenerator.AnalyzeTypeMetrics`.
+type TypeMetrics struct {
+	// Type identifies the declared type.
+	Type QualType
+
+	// FieldCount contains the number of fields of a struct type, including the embedded ones. It's zero for the other
+	// types.
+	FieldCount int
+
+	// NestingDepth contains the highest number of nested type literals inside the declaration, e.g. 1 for
+	// `struct{ ID int }` and 3 for `struct{ Tags []map[string]string }`. The referenced named types aren't followed.
+	NestingDepth int
+
+	// ReferencedPackages contains the distinct packages of the named types referenced by the declaration, excluding the
+	// type's own package, sorted by their paths.
+	ReferencedPackages []string
+
+	// MethodCount contains the number of methods of an interface type, or the number of methods declared with the
+	// type or its pointer as their receiver.
+	MethodCount int
+}
+
+// AnalyzeTypeMetrics computes the complexity metrics of the types declared in the packages matched by
+// `packagePatterns`. See `TypeGenerator.AnalyzeTypeMetrics` for the details.
+func AnalyzeTypeMetrics(packagePatterns ...string) ([]TypeMetrics, error) {
+	return defaultAstTypeGenerator.AnalyzeTypeMetrics(packagePatterns...)
+}
+
+func (f *astTypeGenerator) AnalyzeTypeMetrics(packagePatterns ...string) ([]TypeMetrics, error) {
+	f = f.fork()
+	packages, err := f.expandPackagePatterns(packagePatterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]TypeMetrics, 0)
+	for _, packagePath := range packages {
+		names, err := f.getDeclaredTypeNames(packagePath)
+		if err != nil {
+			return nil, err
+		}
+
+		specs := make([]TypeSpec, 0, len(names))
+		for _, name := range names {
+			specs = append(specs, TypeSpec{PackagePath: packagePath, Name: name})
+		}
+		types, err := f.generateTypesFromSpecs(specs)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, typ := range types {
+			metrics := TypeMetrics{
+				Type:               f.rewriteQualType(QualType{Package: packagePath, Name: specs[i].Name}),
+				NestingDepth:       nestingDepth(typ),
+				ReferencedPackages: make([]string, 0),
+			}
+			for _, referenced := range referencedPackages(typ) {
+				if referenced != packagePath {
+					metrics.ReferencedPackages = append(metrics.ReferencedPackages, f.rewritePackagePath(referenced))
+				}
+			}
+			sort.Strings(metrics.ReferencedPackages)
+			if typ.StructType != nil {
+				metrics.FieldCount = len(typ.StructType.Fields)
+			}
+
+			if typ.InterfaceType != nil {
+				metrics.MethodCount = len(typ.InterfaceType.Methods)
+			} else {
+				methods, err := f.generateMethods(packagePath, specs[i].Name)
+				if err != nil {
+					return nil, err
+				}
+				metrics.MethodCount = len(methods)
+			}
+
+			results = append(results, metrics)
+		}
+	}
+	return results, nil
+}
+
+// nestingDepth returns the highest number of nested type literals inside `t`, see `TypeMetrics.NestingDepth`.
+func nestingDepth(t Type) int {
+	switch {
+	case t.QualType != nil:
+		depth := 0
+		for _, arg := range t.QualType.TypeArgs {
+			depth = maxInt(depth, nestingDepth(arg))
+		}
+		return depth
+	case t.PtrType != nil:
+		return 1 + nestingDepth(t.PtrType.Elem)
+	case t.SliceType != nil:
+		return 1 + nestingDepth(t.SliceType.Elem)
+	case t.ArrayType != nil:
+		return 1 + nestingDepth(t.ArrayType.Elem)
+	case t.ChanType != nil:
+		return 1 + nestingDepth(t.ChanType.Elem)
+	case t.MapType != nil:
+		return 1 + maxInt(nestingDepth(t.MapType.Key), nestingDepth(t.MapType.Elem))
+	case t.FuncType != nil:
+		return 1 + funcNestingDepth(*t.FuncType)
+	case t.StructType != nil:
+		depth := 0
+		for _, field := range t.StructType.Fields {
+			depth = maxInt(depth, nestingDepth(field.Type))
+		}
+		return 1 + depth
+	case t.InterfaceType != nil:
+		depth := 0
+		for _, method := range t.InterfaceType.Methods {
+			depth = maxInt(depth, funcNestingDepth(method.Func))
+		}
+		return 1 + depth
+	}
+	return 0
+}
+
+// funcNestingDepth returns the highest nesting depth of the function's parameters.
+func funcNestingDepth(funcType FuncType) int {
+	depth := 0
+	for _, param := range append(append([]TypeField{}, funcType.Inputs...), funcType.Outputs...) {
+		depth = maxInt(depth, nestingDepth(param.Type))
+	}
+	return depth
+}
+
+// referencedPackages returns the packages of the QualTypes inside `t`, including the embedded interfaces and
+// the type parameters' constraints.
+func referencedPackages(t Type) []string {
+	packageSet := make(map[string]struct{})
+	collect := func(t Type) (Type, bool) {
+		switch {
+		case t.QualType != nil && t.QualType.Package != "":
+			packageSet[t.QualType.Package] = struct{}{}
+		case t.InterfaceType != nil:
+			for _, embedded := range t.InterfaceType.Embedded {
+				if embedded.Package != "" {
+					packageSet[embedded.Package] = struct{}{}
+				}
+			}
+		}
+		return t, false
+	}
+	mapType(t, collect)
+	for _, param := range t.TypeParams {
+		mapType(param.Constraint, collect)
+	}
+
+	packages := make([]string, 0, len(packageSet))
+	for packagePath := range packageSet {
+		packages = append(packages, packagePath)
+	}
+	return packages
+}
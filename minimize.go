@@ -0,0 +1,172 @@
+package gotype
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// MinimalInterface computes the minimal interface required by the `consumerPackages` from the type specified by
+// `typeSpec`. The resulting interface only contains the methods of the type that are used inside the consumer
+// packages. See `TypeGenerator.MinimalInterface` for the details.
+func MinimalInterface(typeSpec TypeSpec, consumerPackages ...string) (InterfaceType, error) {
+	return defaultAstTypeGenerator.MinimalInterface(typeSpec, consumerPackages...)
+}
+
+func (f *astTypeGenerator) MinimalInterface(typeSpec TypeSpec, consumerPackages ...string) (InterfaceType, error) {
+	f = f.fork()
+	methods, err := f.generateMethods(typeSpec.PackagePath, typeSpec.Name)
+	if err != nil {
+		return InterfaceType{}, err
+	}
+
+	usedMethods := make(map[string]struct{})
+	for _, packagePath := range consumerPackages {
+		usages, err := f.collectMethodUsages(typeSpec, packagePath)
+		if err != nil {
+			return InterfaceType{}, err
+		}
+		for _, usage := range usages {
+			for name := range usage {
+				usedMethods[name] = struct{}{}
+			}
+		}
+	}
+
+	result := InterfaceType{}
+	for _, method := range methodSet(methods, true) {
+		if _, ok := usedMethods[method.Name]; ok {
+			result.Methods = append(result.Methods, method)
+		}
+	}
+	return *f.rewritePackagePaths(Type{InterfaceType: &result}).InterfaceType, nil
+}
+
+// collectMethodUsages collects the names selected from the values having the type specified by `typeSpec` inside the
+// package. The names are grouped by the top level declaration selecting them, so each element of the result contains
+// the names used together by a single function. The analysis is syntactic: a value is considered having the type when
+// it's declared as a variable, parameter, result or struct field of the type (or pointer to the type), or when it's
+// initialized using a composite literal of the type.
+func (f *astTypeGenerator) collectMethodUsages(typeSpec TypeSpec, packagePath string) ([]map[string]struct{}, error) {
+	goSources, err := f.getPackageSourceFiles(packagePath)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]*ast.File, 0, len(goSources))
+	for _, source := range goSources {
+		fileAst, err := f.parseAstFile(source)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, fileAst)
+	}
+
+	typedNames := make(map[string]struct{})
+	for _, file := range files {
+		importMap := f.generateImportMap(packagePath, file)
+		isTarget := func(e ast.Expr) bool {
+			return f.isExprOfType(e, typeSpec, packagePath, importMap)
+		}
+
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.Field:
+				if isTarget(n.Type) {
+					addIdentNames(typedNames, n.Names...)
+				}
+			case *ast.ValueSpec:
+				if n.Type != nil && isTarget(n.Type) {
+					addIdentNames(typedNames, n.Names...)
+				}
+				for i, value := range n.Values {
+					if i < len(n.Names) && isTarget(getCompositeLitType(value)) {
+						addIdentNames(typedNames, n.Names[i])
+					}
+				}
+			case *ast.AssignStmt:
+				if n.Tok != token.DEFINE || len(n.Lhs) != len(n.Rhs) {
+					return true
+				}
+				for i, value := range n.Rhs {
+					if ident, ok := n.Lhs[i].(*ast.Ident); ok && isTarget(getCompositeLitType(value)) {
+						addIdentNames(typedNames, ident)
+					}
+				}
+			}
+			return true
+		})
+	}
+
+	usages := make([]map[string]struct{}, 0)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			usage := make(map[string]struct{})
+			ast.Inspect(decl, func(node ast.Node) bool {
+				selector, ok := node.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+
+				var name string
+				switch x := unparen(selector.X).(type) {
+				case *ast.Ident:
+					name = x.Name
+				case *ast.SelectorExpr:
+					name = x.Sel.Name
+				}
+				if _, ok := typedNames[name]; ok {
+					usage[selector.Sel.Name] = struct{}{}
+				}
+				return true
+			})
+
+			if len(usage) > 0 {
+				usages = append(usages, usage)
+			}
+		}
+	}
+
+	return usages, nil
+}
+
+func (f *astTypeGenerator) isExprOfType(e ast.Expr, typeSpec TypeSpec, packagePath string, importMap map[string]string) bool {
+	if e == nil {
+		return false
+	}
+
+	typ, err := f.generateTypeFromExpr(e, packagePath, importMap)
+	if err != nil {
+		return false
+	}
+	if typ.PtrType != nil {
+		typ = typ.PtrType.Elem
+	}
+	return typ.QualType != nil && typ.QualType.Package == typeSpec.PackagePath && typ.QualType.Name == typeSpec.Name
+}
+
+func addIdentNames(names map[string]struct{}, idents ...*ast.Ident) {
+	for _, ident := range idents {
+		names[ident.Name] = struct{}{}
+	}
+}
+
+// getCompositeLitType returns the type of a `T{...}` or `&T{...}` expression, or nil for other expressions.
+func getCompositeLitType(e ast.Expr) ast.Expr {
+	if unary, ok := e.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		e = unary.X
+	}
+	if lit, ok := e.(*ast.CompositeLit); ok {
+		return lit.Type
+	}
+	return nil
+}
+
+func unparen(e ast.Expr) ast.Expr {
+	for {
+		paren, ok := e.(*ast.ParenExpr)
+		if !ok {
+			return e
+		}
+		e = paren.X
+	}
+}
@@ -0,0 +1,147 @@
+package gotype
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RenderMock renders the Golang's source code of a mock implementing the interface `iface`. The mock is a struct named
+// `mockName` having a function field for each method, e.g. the method `Get` is implemented by calling the `GetFunc`
+// field. When `iface` is a generic interface, the mock is generic as well, using the same type parameters and
+// constraints. The rendered code only contains the declarations, the package clause and imports are left to the
+// caller.
+func RenderMock(mockName string, iface Type, moduleName string) (string, error) {
+	if iface.InterfaceType == nil {
+		return "", fmt.Errorf("cannot render mock of a non-interface type: %s", iface.String(moduleName))
+	}
+	if len(iface.InterfaceType.Unions) > 0 {
+		return "", fmt.Errorf("cannot render mock of a constraint interface")
+	}
+
+	typeParams := TypeParamsString(iface.TypeParams, moduleName)
+	typeArgs := typeParamNames(iface.TypeParams)
+
+	b := strings.Builder{}
+	fmt.Fprintf(&b, "type %s%s struct {\n", mockName, typeParams)
+	for _, method := range iface.InterfaceType.Methods {
+		fmt.Fprintf(&b, "\t%sFunc %s\n", method.Name, mockFuncType(method.Func).String(moduleName))
+	}
+	b.WriteString("}\n")
+
+	for _, method := range iface.InterfaceType.Methods {
+		funcType := mockFuncType(method.Func)
+		signature := strings.TrimPrefix(funcType.String(moduleName), "func")
+		fmt.Fprintf(&b, "\nfunc (%s *%s%s) %s%s {\n", mockReceiverName, mockName, typeArgs, method.Name, signature)
+
+		call := fmt.Sprintf("%s.%sFunc(%s)", mockReceiverName, method.Name, mockCallArgs(funcType))
+		if len(funcType.Outputs) > 0 {
+			fmt.Fprintf(&b, "\treturn %s\n", call)
+		} else {
+			fmt.Fprintf(&b, "\t%s\n", call)
+		}
+		b.WriteString("}\n")
+	}
+
+	return b.String(), nil
+}
+
+// TypeParamsString renders a type parameter list, like `[K comparable, V any]`. It returns an empty string when there
+// are no type parameters.
+func TypeParamsString(typeParams []TypeParam, moduleName string) string {
+	if len(typeParams) == 0 {
+		return ""
+	}
+
+	params := make([]string, 0, len(typeParams))
+	for _, param := range typeParams {
+		params = append(params, param.Name+" "+constraintString(param.Constraint, moduleName))
+	}
+	return "[" + strings.Join(params, ", ") + "]"
+}
+
+func constraintString(constraint Type, moduleName string) string {
+	i := constraint.InterfaceType
+	if i == nil {
+		return constraint.String(moduleName)
+	}
+	if len(i.Methods) == 0 && len(i.Embedded) == 0 && len(i.Unions) == 0 {
+		return "any"
+	}
+	if len(i.Methods) == 0 && len(i.Embedded) == 0 && len(i.Unions) == 1 {
+		terms := make([]string, 0, len(i.Unions[0]))
+		for _, term := range i.Unions[0] {
+			terms = append(terms, term.String(moduleName))
+		}
+		return strings.Join(terms, " | ")
+	}
+	return strings.ReplaceAll(i.String(moduleName), "\n", "; ")
+}
+
+// typeParamNames renders the type parameters as type arguments, like `[K, V]`.
+func typeParamNames(typeParams []TypeParam) string {
+	if len(typeParams) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(typeParams))
+	for _, param := range typeParams {
+		names = append(names, param.Name)
+	}
+	return "[" + strings.Join(names, ", ") + "]"
+}
+
+// mockReceiverName is the name of the mock's receiver in the rendered methods.
+const mockReceiverName = "m"
+
+// mockFuncType returns a copy of `funcType` whose blank or empty input names are replaced, so they can be passed to the
+// mock's function field. The inputs and outputs named after the receiver are renamed, so they don't shadow it.
+func mockFuncType(funcType FuncType) FuncType {
+	used := make(map[string]struct{}, len(funcType.Inputs)+len(funcType.Outputs))
+	for _, field := range append(append([]TypeField(nil), funcType.Inputs...), funcType.Outputs...) {
+		used[field.Name] = struct{}{}
+	}
+
+	inputs := make([]TypeField, 0, len(funcType.Inputs))
+	for i, input := range funcType.Inputs {
+		if input.Name == "" || input.Name == "_" {
+			input.Name = fmt.Sprintf("arg%d", i+1)
+		}
+		input.Name = mockParamName(input.Name, used)
+		inputs = append(inputs, input)
+	}
+	outputs := make([]TypeField, 0, len(funcType.Outputs))
+	for _, output := range funcType.Outputs {
+		output.Name = mockParamName(output.Name, used)
+		outputs = append(outputs, output)
+	}
+	funcType.Inputs = inputs
+	funcType.Outputs = outputs
+	return funcType
+}
+
+// mockParamName returns `name`, unless it's the receiver's name, which is suffixed by underscores until it isn't one
+// of the `used` names.
+func mockParamName(name string, used map[string]struct{}) string {
+	if name != mockReceiverName {
+		return name
+	}
+	for {
+		name += "_"
+		if _, ok := used[name]; !ok {
+			used[name] = struct{}{}
+			return name
+		}
+	}
+}
+
+func mockCallArgs(funcType FuncType) string {
+	args := make([]string, 0, len(funcType.Inputs))
+	for i, input := range funcType.Inputs {
+		if funcType.IsVariadic && i == len(funcType.Inputs)-1 {
+			args = append(args, input.Name+"...")
+			continue
+		}
+		args = append(args, input.Name)
+	}
+	return strings.Join(args, ", ")
+}
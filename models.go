@@ -2,6 +2,9 @@ package gotype
 
 import (
 	"fmt"
+	"go/token"
+	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -10,7 +13,7 @@ import (
 // source files and parse the abstract syntax tree. Note that because Type is generated statically, it doesn't contain
 // the internal representation of Golang's interface.
 //
-// There are 10 kind of Golang's type supported:
+// There are 11 kind of Golang's type supported:
 //   - PrimitiveType: represents bool, byte, int, int8, int16, int64, uint, uint16, uint32, uint64, uintptr, float32,
 //                  float64, complex64, complex128, string, and error.
 //   - QualType: represents a pair of Golang's type identified by package path and the type's name within it's package.
@@ -22,6 +25,7 @@ import (
 //   - FuncType: represents Golang's function.
 //   - StructType: represents Golang's struct.
 //   - InterfaceType: represents Golang's interface.
+//   - TypeParamType: represents a reference to a type parameter of a generic type.
 //
 // Type contains a bunch of pointers which represents the information of each type. There is only one non-null pointer
 // inside Type. For example, if the Type represents a Golang's map, the `MapType` field will be a non-null pointer and
@@ -57,6 +61,31 @@ type Type struct {
 
 	// InterfaceType represents Golang's interface.
 	InterfaceType *InterfaceType
+
+	// TypeParamType represents a reference to a type parameter of a generic type or function.
+	TypeParamType *TypeParamType
+
+	// TypeParams contains the type parameters of a generic type declaration. It's empty for non-generic types.
+	TypeParams []TypeParam
+
+	// Position contains the location of the type declaration the Type is generated from, like the types returned by
+	// GenerateTypesFromSpecs and the definitions filled by the deep resolution, e.g. to write "generated from" comments.
+	// It's zero for the Types nested inside the others, whose fields and methods carry their own positions.
+	Position token.Position
+
+	// Doc contains the documentation comment written above the type declaration the Type is generated from. Like
+	// Position, it's empty for the Types nested inside the others.
+	Doc string
+
+	// IsAlias is true when the type declaration the Type is generated from is an alias, like `type A = b.B`, rather
+	// than a type definition, like `type A b.B`. It's always false for the Types nested inside the others.
+	IsAlias bool
+
+	// Methods contains the methods declared with the type declaration the Type is generated from as their receiver,
+	// like `func (u *User) Validate() error`, in the order of their declaration. It's only filled when the generator
+	// is configured using `WithMethods`, and it's nil for the types without methods and for the Types nested inside
+	// the others.
+	Methods []Method
 }
 
 func primitiveTypeDefault(i *PrimitiveType) string {
@@ -119,6 +148,8 @@ func (i Type) Default(shortPackageName string) (string, string) {
 		return shortPackageName, "struct{}"
 	case i.InterfaceType != nil:
 		return shortPackageName, "interface{}"
+	case i.TypeParamType != nil:
+		return shortPackageName, "*new(" + i.TypeParamType.Name + ")"
 	}
 	return shortPackageName, "nil"
 }
@@ -138,14 +169,23 @@ func (i Type) String(moduleName string) string {
 	case i.PrimitiveType != nil:
 		return string(i.PrimitiveType.Kind)
 	case i.QualType != nil:
-		if i.QualType.ShortPackagePath == moduleName {
-			return i.QualType.Name
+		typeArgs := ""
+		if len(i.QualType.TypeArgs) > 0 {
+			args := make([]string, 0, len(i.QualType.TypeArgs))
+			for _, arg := range i.QualType.TypeArgs {
+				args = append(args, arg.String(moduleName))
+			}
+			typeArgs = "[" + strings.Join(args, ", ") + "]"
+		}
+
+		if i.QualType.ShortPackagePath == moduleName || i.QualType.Package == "" {
+			return i.QualType.Name + typeArgs
 		}
 
 		packageName := i.QualType.ShortPackagePath
 
 		packageName = strings.TrimPrefix(packageName, moduleName+"/")
-		return packageName + "." + i.QualType.Name
+		return packageName + "." + i.QualType.Name + typeArgs
 	case i.ChanType != nil:
 		dir := "chan"
 		// ChanTypeDirRecv represents a `<-chan`
@@ -169,16 +209,33 @@ func (i Type) String(moduleName string) string {
 	case i.StructType != nil:
 		str := "struct {"
 		for _, field := range i.StructType.Fields {
-			str += "\n    " + field.Name + " " + field.Type.String(moduleName)
+			if field.Embedded {
+				str += "\n    " + field.Type.String(moduleName)
+			} else {
+				str += "\n    " + field.Name + " " + field.Type.String(moduleName)
+			}
+			if field.Tag != "" {
+				str += " " + tagLiteral(field.Tag)
+			}
 		}
 		str += "\n}"
 		return str
 	case i.InterfaceType != nil:
-		return "interface{}"
+		return i.InterfaceType.String(moduleName)
+	case i.TypeParamType != nil:
+		return i.TypeParamType.Name
 	}
 	return "unknown"
 }
 
+// tagLiteral renders a struct's field tag as a raw string literal when possible.
+func tagLiteral(tag reflect.StructTag) string {
+	if strings.Contains(string(tag), "`") {
+		return strconv.Quote(string(tag))
+	}
+	return "`" + string(tag) + "`"
+}
+
 // PrimitiveKind represents the type of primitive type.
 type PrimitiveKind string
 
@@ -244,11 +301,53 @@ const (
 	PrimitiveKindError PrimitiveKind = "error"
 )
 
+// IsNumeric reports whether the kind is an integer, a floating-point or a complex number.
+func (k PrimitiveKind) IsNumeric() bool { return k.IsInteger() || k.IsFloat() || k.IsComplex() }
+
+// IsInteger reports whether the kind is a signed or unsigned integer, including byte, rune and uintptr.
+func (k PrimitiveKind) IsInteger() bool {
+	switch k {
+	case PrimitiveKindInt, PrimitiveKindInt8, PrimitiveKindInt16, PrimitiveKindInt32, PrimitiveKindInt64,
+		PrimitiveKindRune:
+		return true
+	}
+	return k.IsUnsigned()
+}
+
+// IsUnsigned reports whether the kind is an unsigned integer, including byte and uintptr.
+func (k PrimitiveKind) IsUnsigned() bool {
+	switch k {
+	case PrimitiveKindUint, PrimitiveKindUint8, PrimitiveKindUint16, PrimitiveKindUint32, PrimitiveKindUint64,
+		PrimitiveKindByte, PrimitiveKindUintptr:
+		return true
+	}
+	return false
+}
+
+// IsFloat reports whether the kind is float32 or float64.
+func (k PrimitiveKind) IsFloat() bool { return k == PrimitiveKindFloat32 || k == PrimitiveKindFloat64 }
+
+// IsComplex reports whether the kind is complex64 or complex128.
+func (k PrimitiveKind) IsComplex() bool {
+	return k == PrimitiveKindComplex64 || k == PrimitiveKindComplex128
+}
+
+// IsString reports whether the kind is string.
+func (k PrimitiveKind) IsString() bool { return k == PrimitiveKindString }
+
+// IsBoolLike reports whether the kind is bool, that is, the values can be used as conditions.
+func (k PrimitiveKind) IsBoolLike() bool { return k == PrimitiveKindBool }
+
 // PrimitiveType represents bool, byte, int, int8, int16, int64, uint, uint16, uint32, uint64, uintptr, float32,
 // float64, complex64, complex128, string, and error.
 type PrimitiveType struct {
 	// Kind contains the kind of represented primitive.
 	Kind PrimitiveKind
+
+	// Bits contains the width of the platform-dependent kinds, that is, int, uint and uintptr, under the target
+	// architecture of the Config, see `WithConfig`. It's zero for the other kinds, and when the generator has no
+	// Config, since the target architecture is unknown.
+	Bits int
 }
 
 // QualType represents a pair of Golang's type identified by package path and the type's name within it's package.
@@ -261,6 +360,38 @@ type QualType struct {
 	// Name contains the type's name inside the package.
 	// The combination of Package and Name uniquely indentifies a Golang's type.
 	Name string
+
+	// TypeArgs contains the type arguments of an instantiated generic type, e.g. `string` and `*User` in
+	// `cache.Store[string, *User]`. It's empty for non-generic types.
+	TypeArgs []Type
+
+	// Underlying contains the resolved definition of the type. It's only filled when the generator is configured
+	// using `WithDeepResolution`. For an instantiated generic type, it contains the instantiated definition, e.g. the
+	// struct of `Option[int]` has a `Value int` field. Underlying is nil for predeclared types and for references
+	// closing a cycle, like the `*Node` field inside `Node`.
+	Underlying *Type
+
+	// Chain contains the declarations followed by the deep resolution to find the definition of the type, starting
+	// from the type's own declaration. E.g. given `type A = B` and `type B C`, the Chain of `A` contains the alias `A`
+	// followed by the definitions of `B` and `C`, and `Underlying` contains the Type of `B`, whose `Underlying`
+	// contains the Type of `C`. The Chain is only filled along with `Underlying`.
+	Chain []DeclarationLink
+}
+
+// DeclarationLink is a type declaration followed by the deep resolution, see `QualType.Chain`.
+type DeclarationLink struct {
+	// Package contains the package path of the declared type.
+	Package string
+
+	// Name contains the name of the declared type.
+	Name string
+
+	// Alias is true when the declaration is an alias, like `type A = B`, rather than a type definition, like
+	// `type B C`.
+	Alias bool
+
+	// Position contains the location of the declaration.
+	Position token.Position
 }
 
 // ChanTypeDir represents the direction of Golang's channel.
@@ -332,6 +463,28 @@ type TypeField struct {
 
 	// Type represents the type of the field/parameter.
 	Type Type
+
+	// Position contains the location where the field/parameter is declared.
+	Position token.Position
+
+	// Tag contains the struct's field tag. It's always empty for function parameters.
+	Tag reflect.StructTag
+
+	// Annotations contains the annotations of the struct's field parsed from its comments, like "min": "1" for the
+	// `// +gotype:min=1` marker, see `WithAnnotationMarkers`. It's nil when the field has no annotations.
+	Annotations map[string]string
+
+	// Embedded is true for the embedded struct's fields, like `io.Reader` in `struct { io.Reader }`, whose Name is the
+	// name of their type. It's always false for function parameters.
+	Embedded bool
+
+	// Doc contains the documentation comment written above the struct's field. It's always empty for function
+	// parameters.
+	Doc string
+
+	// Comment contains the comment written after the struct's field on the same line. It's always empty for function
+	// parameters.
+	Comment string
 }
 
 // FuncType represents a Golang's function.
@@ -406,6 +559,26 @@ func (i FuncType) StringWithoutTypes(moduleName string) string {
 	return str
 }
 
+// Method represents a method declared with a named type as its receiver.
+type Method struct {
+	// Name contains the method's name.
+	Name string
+
+	// Func contains the signature of the method, without its receiver. The signature of a method of a generic type
+	// refers to the type parameters using the names declared by the type.
+	Func FuncType
+
+	// PointerReceiver is true when the method is declared with a pointer receiver, like `func (u *User) Validate()`,
+	// so it only belongs to the method set of the pointer to the type.
+	PointerReceiver bool
+
+	// Doc contains the documentation comment written above the method.
+	Doc string
+
+	// Position contains the location where the method is declared.
+	Position token.Position
+}
+
 // InterfaceTypeMethod represents a Golang's interface method.
 type InterfaceTypeMethod struct {
 	// Name contains the interface's method name.
@@ -413,12 +586,95 @@ type InterfaceTypeMethod struct {
 
 	// Func contains the type of the method.
 	Func FuncType
+
+	// Origin contains the embedded interface which declares the method. Origin is nil when the method is declared
+	// directly inside the interface.
+	Origin *QualType
+
+	// Doc contains the documentation comment written above the method.
+	Doc string
+
+	// Comment contains the comment written after the method on the same line.
+	Comment string
+
+	// Position contains the location where the method is declared.
+	Position token.Position
 }
 
 // InterfaceType represents a Golang's interface.
 type InterfaceType struct {
-	// Methods contains the methods inside the interface.
+	// Methods contains the methods inside the interface. By default, the methods of the embedded interfaces are
+	// flattened into Methods as well. When the generator is configured using `WithEmbeddedInterfaces`, Methods only
+	// contains the methods declared directly inside the interface.
 	Methods []InterfaceTypeMethod
+
+	// Embedded contains the interfaces embedded inside the interface, in their declaration order.
+	Embedded []QualType
+
+	// Unions contains the union elements of a constraint interface, like `int | string`. A non-interface type
+	// embedded inside a constraint is represented as a union with a single term. The type set of the interface is the
+	// intersection of all its unions.
+	Unions [][]TypeTerm
+}
+
+// TypeTerm represents a single term of a union element inside a constraint interface.
+type TypeTerm struct {
+	// Tilde is true for an approximation element like `~string`, which includes all types whose underlying type is
+	// Type. When Tilde is false, the term only includes Type itself.
+	Tilde bool
+
+	// Type contains the type of the term.
+	Type Type
+}
+
+func (i InterfaceType) String(moduleName string) string {
+	if len(i.Methods) == 0 && len(i.Embedded) == 0 && len(i.Unions) == 0 {
+		return "interface{}"
+	}
+
+	str := "interface {"
+	for _, embedded := range i.Embedded {
+		str += "\n    " + embedded.Type().String(moduleName)
+	}
+	for _, union := range i.Unions {
+		terms := make([]string, 0, len(union))
+		for _, term := range union {
+			terms = append(terms, term.String(moduleName))
+		}
+		str += "\n    " + strings.Join(terms, " | ")
+	}
+	for _, method := range i.Methods {
+		str += "\n    " + method.Name + strings.TrimPrefix(method.Func.String(moduleName), "func")
+	}
+	str += "\n}"
+	return str
+}
+
+// TypeParam represents a type parameter of a generic type or function.
+type TypeParam struct {
+	// Name contains the type parameter's name.
+	Name string
+
+	// Constraint contains the type constraint of the type parameter, as written in the source code.
+	Constraint Type
+
+	// ConstraintInterface contains the full interface of the constraint. When the constraint is a named interface,
+	// possibly declared in another package like `constraints.Ordered`, it's resolved to the interface's definition,
+	// including its type set. ConstraintInterface is nil for the predeclared `comparable`.
+	ConstraintInterface *InterfaceType
+}
+
+// TypeParamType represents a reference to a type parameter, e.g. the `T` in `type List[T any] struct { Head T }`.
+type TypeParamType struct {
+	// Name contains the referenced type parameter's name.
+	Name string
+}
+
+func (t TypeTerm) String(moduleName string) string {
+	if t.Tilde {
+		return "~" + t.Type.String(moduleName)
+	}
+	return t.Type.String(moduleName)
 }
 
 // Type converts the PrimitiveType to a Type.
@@ -451,6 +707,9 @@ func (t StructType) Type() Type { return Type{StructType: &t} }
 // Type converts the InterfaceType to a Type.
 func (t InterfaceType) Type() Type { return Type{InterfaceType: &t} }
 
+// Type converts the TypeParamType to a Type.
+func (t TypeParamType) Type() Type { return Type{TypeParamType: &t} }
+
 // IsPrimitive returns true if the Type is a PrimitiveType.
 func (t Type) IsPrimitive() bool { return t.PrimitiveType != nil }
 
@@ -481,12 +740,99 @@ func (t Type) IsStruct() bool { return t.StructType != nil }
 // IsInterface returns true if the Type is a InterfaceType.
 func (t Type) IsInterface() bool { return t.InterfaceType != nil }
 
+// IsTypeParam returns true if the Type is a TypeParamType.
+func (t Type) IsTypeParam() bool { return t.TypeParamType != nil }
+
+// IsGeneric returns true if the Type is a generic type declaration, that is, it has type parameters.
+func (t Type) IsGeneric() bool { return len(t.TypeParams) > 0 }
+
+// IsNumeric returns true if the Type is a numeric PrimitiveType, see `PrimitiveKind.IsNumeric`. Like the other
+// predicates on the PrimitiveKind below, it's also true for a QualType whose resolved underlying type is, like
+// `time.Duration`, see `WithDeepResolution`.
+func (t Type) IsNumeric() bool { return t.hasPrimitiveKind(PrimitiveKind.IsNumeric) }
+
+// IsInteger returns true if the Type is an integer PrimitiveType, see `PrimitiveKind.IsInteger`.
+func (t Type) IsInteger() bool { return t.hasPrimitiveKind(PrimitiveKind.IsInteger) }
+
+// IsUnsigned returns true if the Type is an unsigned integer PrimitiveType, see `PrimitiveKind.IsUnsigned`.
+func (t Type) IsUnsigned() bool { return t.hasPrimitiveKind(PrimitiveKind.IsUnsigned) }
+
+// IsFloat returns true if the Type is a floating-point PrimitiveType, see `PrimitiveKind.IsFloat`.
+func (t Type) IsFloat() bool { return t.hasPrimitiveKind(PrimitiveKind.IsFloat) }
+
+// IsComplex returns true if the Type is a complex PrimitiveType, see `PrimitiveKind.IsComplex`.
+func (t Type) IsComplex() bool { return t.hasPrimitiveKind(PrimitiveKind.IsComplex) }
+
+// IsString returns true if the Type is a string PrimitiveType, see `PrimitiveKind.IsString`.
+func (t Type) IsString() bool { return t.hasPrimitiveKind(PrimitiveKind.IsString) }
+
+// IsBoolLike returns true if the Type is a bool PrimitiveType, see `PrimitiveKind.IsBoolLike`.
+func (t Type) IsBoolLike() bool { return t.hasPrimitiveKind(PrimitiveKind.IsBoolLike) }
+
+// hasPrimitiveKind reports whether the Type, or the resolved underlying type of the QualType, is a PrimitiveType
+// whose kind satisfies `predicate`.
+func (t Type) hasPrimitiveKind(predicate func(PrimitiveKind) bool) bool {
+	for t.QualType != nil && t.QualType.Underlying != nil {
+		t = *t.QualType.Underlying
+	}
+	return t.PrimitiveType != nil && predicate(t.PrimitiveType.Kind)
+}
+
+// Nilability represents whether the zero value of a type is nil.
+type Nilability int
+
+const (
+	// NotNilable represents the types whose zero value isn't nil, like the numbers, the strings, the structs and the
+	// arrays.
+	NotNilable Nilability = iota
+
+	// NilableConcrete represents the pointers, slices, maps, channels and functions. Their nil values are still
+	// values of their types, e.g. they're encoded as null, and a method can be called on a nil pointer.
+	NilableConcrete
+
+	// NilableInterface represents the interfaces, including error. Only their zero value is nil: an interface holding
+	// a nil pointer isn't, so they need a different handling than the concrete types to detect missing values.
+	NilableInterface
+
+	// NilabilityUnknown represents the QualTypes which aren't resolved, like `io.Reader` without its Underlying, whose
+	// zero value may or may not be nil.
+	NilabilityUnknown
+)
+
+// Nilability returns whether the zero value of the Type is nil. The QualTypes are classified using their resolved
+// underlying types, and are NilabilityUnknown when they're not resolved. The type parameters are NotNilable, since
+// their zero value depends on the type arguments.
+func (t Type) Nilability() Nilability {
+	for t.QualType != nil && t.QualType.Underlying != nil {
+		t = *t.QualType.Underlying
+	}
+	switch {
+	case t.QualType != nil:
+		return NilabilityUnknown
+	case t.InterfaceType != nil, t.PrimitiveType != nil && t.PrimitiveType.Kind == PrimitiveKindError:
+		return NilableInterface
+	case t.PtrType != nil, t.SliceType != nil, t.MapType != nil, t.ChanType != nil, t.FuncType != nil:
+		return NilableConcrete
+	}
+	return NotNilable
+}
+
+// IsNilable returns true if the zero value of the Type is known to be nil, see `Type.Nilability`.
+func (t Type) IsNilable() bool {
+	nilability := t.Nilability()
+	return nilability == NilableConcrete || nilability == NilableInterface
+}
+
 // TypeSpec represents a combination of package path and the type's name which can uniquely identified Golang's type.
-// TypeSpec is used as a query to `gotype`.
+// TypeSpec is used as a query to `gotype`. See Spec to query the other kinds of declarations.
 type TypeSpec struct {
 	// PackagePath contains a defined type's package path, that is, the import path
 	// that uniquely identifies the package, such as "encoding/base64".
+	// It may end with the version of the package's module, such as "example.com/mod/pkg@v1.2.3",
+	// to use that version instead of the one required by the go.mod file. The module is looked up
+	// inside the module cache, and downloaded using the go tool when it's missing.
 	PackagePath string
-	// Name contains the type's name inside the package.
+	// Name contains the type's name inside the package. The types declared inside function
+	// bodies are named after their functions, like "Handler.request", see `WithLocalTypes`.
 	Name string
 }
@@ -0,0 +1,210 @@
+package gotype
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"strings"
+)
+
+// mongoPrimitivePackage is the package of the MongoDB driver containing the BSON specific types.
+const mongoPrimitivePackage = "go.mongodb.org/mongo-driver/bson/primitive"
+
+// bsonTypes maps the primitive kinds to their BSON types, following the MongoDB driver's default encoding. The `int`
+// and `uint` values are encoded as 32-bit integers when they fit, and as 64-bit integers otherwise.
+var bsonTypes = map[PrimitiveKind]interface{}{
+	PrimitiveKindBool:    "bool",
+	PrimitiveKindString:  "string",
+	PrimitiveKindInt8:    "int",
+	PrimitiveKindInt16:   "int",
+	PrimitiveKindInt32:   "int",
+	PrimitiveKindRune:    "int",
+	PrimitiveKindUint8:   "int",
+	PrimitiveKindByte:    "int",
+	PrimitiveKindUint16:  "int",
+	PrimitiveKindInt:     []string{"int", "long"},
+	PrimitiveKindUint:    []string{"int", "long"},
+	PrimitiveKindInt64:   "long",
+	PrimitiveKindUint32:  "long",
+	PrimitiveKindUint64:  "long",
+	PrimitiveKindFloat32: "double",
+	PrimitiveKindFloat64: "double",
+}
+
+// bsonQualTypes maps the well known QualTypes to their BSON types.
+var bsonQualTypes = map[string]string{
+	"time.Time":                                 "date",
+	mongoPrimitivePackage + ".ObjectID":         "objectId",
+	mongoPrimitivePackage + ".DateTime":         "date",
+	mongoPrimitivePackage + ".Decimal128":       "decimal",
+	mongoPrimitivePackage + ".Binary":           "binData",
+	mongoPrimitivePackage + ".Timestamp":        "timestamp",
+	mongoPrimitivePackage + ".Regex":            "regex",
+	mongoPrimitivePackage + ".JavaScript":       "javascript",
+	mongoPrimitivePackage + ".MinKey":           "minKey",
+	mongoPrimitivePackage + ".MaxKey":           "maxKey",
+	mongoPrimitivePackage + ".Symbol":           "symbol",
+	mongoPrimitivePackage + ".DBPointer":        "dbPointer",
+	mongoPrimitivePackage + ".CodeWithScope":    "javascriptWithScope",
+	mongoPrimitivePackage + ".Undefined":        "undefined",
+	mongoPrimitivePackage + ".Null":             "null",
+	"go.mongodb.org/mongo-driver/bson.D":        "object",
+	"go.mongodb.org/mongo-driver/bson.M":        "object",
+	"go.mongodb.org/mongo-driver/bson.A":        "array",
+	"go.mongodb.org/mongo-driver/bson.Raw":      "object",
+	"go.mongodb.org/mongo-driver/bson.RawValue": "",
+}
+
+// MongoJSONSchema returns the MongoDB's `$jsonSchema` of the documents encoded from the struct type `typ` by the
+// MongoDB driver. The documents' field names are read from the `bson` tags, and default to the lowercase fields'
+// names. The fields without the `omitempty` option are required since the driver always encodes them, and the fields
+// having the `inline` option are flattened into the document. The QualTypes are described using their `Underlying`
+// definitions filled by the deep resolution, and left unconstrained when they're not resolved.
+func MongoJSONSchema(typ Type) (map[string]interface{}, error) {
+	if typ.StructType == nil {
+		return nil, fmt.Errorf("cannot generate the json schema of a non-struct type: %s", typ.String(""))
+	}
+	return bsonSchema(typ), nil
+}
+
+// RenderMongoValidator renders the validator of a MongoDB collection containing the documents encoded from the struct
+// type `typ`, which can be passed to the `validator` option of the `createCollection` and `collMod` commands.
+func RenderMongoValidator(typ Type) (string, error) {
+	schema, err := MongoJSONSchema(typ)
+	if err != nil {
+		return "", err
+	}
+
+	validator, err := json.MarshalIndent(map[string]interface{}{"$jsonSchema": schema}, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("cannot encode the json schema: %w", err)
+	}
+	return string(validator), nil
+}
+
+func bsonSchema(typ Type) map[string]interface{} {
+	schema := bsonValueSchema(typ)
+	// the driver encodes the nil pointers, slices and maps as null. The unresolved QualTypes, whose nilability is
+	// unknown, are either the well-known value types, like time.Time, or don't have a bsonType.
+	if bsonType, ok := schema["bsonType"]; ok && typ.Nilability() == NilableConcrete {
+		schema["bsonType"] = appendBSONType(bsonType, "null")
+	}
+	return schema
+}
+
+// bsonValueSchema returns the schema of the non-nil values of the Type.
+func bsonValueSchema(typ Type) map[string]interface{} {
+	switch {
+	case typ.PtrType != nil:
+		return bsonSchema(typ.PtrType.Elem)
+	case typ.PrimitiveType != nil:
+		if bsonType, ok := bsonTypes[typ.PrimitiveType.Kind]; ok {
+			return map[string]interface{}{"bsonType": bsonType}
+		}
+	case typ.QualType != nil:
+		if bsonType, ok := bsonQualTypes[typ.QualType.Package+"."+typ.QualType.Name]; ok {
+			if bsonType == "" {
+				return map[string]interface{}{}
+			}
+			return map[string]interface{}{"bsonType": bsonType}
+		}
+		if typ.QualType.Underlying != nil {
+			return bsonSchema(*typ.QualType.Underlying)
+		}
+	case typ.SliceType != nil:
+		elem := typ.SliceType.Elem.PrimitiveType
+		if elem != nil && (elem.Kind == PrimitiveKindByte || elem.Kind == PrimitiveKindUint8) {
+			return map[string]interface{}{"bsonType": "binData"}
+		}
+		return map[string]interface{}{
+			"bsonType": "array",
+			"items":    bsonSchema(typ.SliceType.Elem),
+		}
+	case typ.ArrayType != nil:
+		return map[string]interface{}{
+			"bsonType": "array",
+			"items":    bsonSchema(typ.ArrayType.Elem),
+			"minItems": typ.ArrayType.Len,
+			"maxItems": typ.ArrayType.Len,
+		}
+	case typ.MapType != nil:
+		return map[string]interface{}{
+			"bsonType":             "object",
+			"additionalProperties": bsonSchema(typ.MapType.Elem),
+		}
+	case typ.StructType != nil:
+		properties := make(map[string]interface{})
+		required := make([]string, 0)
+		appendBSONProperties(*typ.StructType, properties, &required)
+
+		schema := map[string]interface{}{"bsonType": "object", "properties": properties}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	}
+	return map[string]interface{}{}
+}
+
+// appendBSONProperties appends the properties of the document encoded from `structType`.
+func appendBSONProperties(structType StructType, properties map[string]interface{}, required *[]string) {
+	for _, field := range structType.Fields {
+		if !ast.IsExported(field.Name) {
+			continue
+		}
+
+		tag := strings.Split(field.Tag.Get("bson"), ",")
+		name, options := tag[0], tag[1:]
+		if name == "-" && len(options) == 0 {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		omitEmpty, inline := false, false
+		for _, option := range options {
+			switch option {
+			case "omitempty":
+				omitEmpty = true
+			case "inline":
+				inline = true
+			}
+		}
+
+		if inline {
+			inner := field.Type
+			if inner.PtrType != nil {
+				inner = inner.PtrType.Elem
+			}
+			if inner.QualType != nil && inner.QualType.Underlying != nil {
+				inner = *inner.QualType.Underlying
+			}
+			if inner.StructType != nil {
+				appendBSONProperties(*inner.StructType, properties, required)
+				continue
+			}
+		}
+
+		properties[name] = bsonSchema(field.Type)
+		if !omitEmpty {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// appendBSONType adds `extra` to the allowed BSON types, unless it's already allowed.
+func appendBSONType(bsonType interface{}, extra string) interface{} {
+	switch t := bsonType.(type) {
+	case string:
+		return []string{t, extra}
+	case []string:
+		for _, existing := range t {
+			if existing == extra {
+				return t
+			}
+		}
+		return append(append([]string{}, t...), extra)
+	}
+	return bsonType
+}
@@ -0,0 +1,38 @@
+package gotype
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderMongoValidator(t *testing.T) {
+	types, err := NewGenerator(WithDeepResolution()).GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: testdataPackage + "/mongo", Name: "Article"},
+	)
+	require.NoError(t, err)
+
+	validator, err := RenderMongoValidator(types[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$jsonSchema": {
+			"bsonType": "object",
+			"properties": {
+				"title": {"bsonType": "string"},
+				"views": {"bsonType": "long"},
+				"rating": {"bsonType": "double"},
+				"tags": {"bsonType": ["array", "null"], "items": {"bsonType": "string"}},
+				"meta": {"bsonType": ["object", "null"], "additionalProperties": {"bsonType": "string"}},
+				"body": {"bsonType": ["binData", "null"]},
+				"draft": {"bsonType": "bool"},
+				"created_at": {"bsonType": "date"},
+				"updated_at": {"bsonType": ["date", "null"]}
+			},
+			"required": ["title", "views", "tags", "draft", "created_at"]
+		}
+	}`, validator)
+
+	_, err = MongoJSONSchema(PrimitiveType{Kind: PrimitiveKindString}.Type())
+	assert.Error(t, err)
+}
@@ -0,0 +1,261 @@
+package gotype
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+)
+
+// msgpSuffixes maps the primitive kinds to the suffixes of the msgp's append and read functions, like `AppendString`
+// and `ReadStringBytes`.
+var msgpSuffixes = map[PrimitiveKind]string{
+	PrimitiveKindBool:       "Bool",
+	PrimitiveKindString:     "String",
+	PrimitiveKindInt:        "Int",
+	PrimitiveKindInt8:       "Int8",
+	PrimitiveKindInt16:      "Int16",
+	PrimitiveKindInt32:      "Int32",
+	PrimitiveKindRune:       "Int32",
+	PrimitiveKindInt64:      "Int64",
+	PrimitiveKindUint:       "Uint",
+	PrimitiveKindUint8:      "Uint8",
+	PrimitiveKindByte:       "Uint8",
+	PrimitiveKindUint16:     "Uint16",
+	PrimitiveKindUint32:     "Uint32",
+	PrimitiveKindUint64:     "Uint64",
+	PrimitiveKindFloat32:    "Float32",
+	PrimitiveKindFloat64:    "Float64",
+	PrimitiveKindComplex64:  "Complex64",
+	PrimitiveKindComplex128: "Complex128",
+}
+
+// msgpackRenderer renders the statements encoding and decoding the values of a struct's fields.
+type msgpackRenderer struct {
+	codeWriter
+	moduleName string
+}
+
+// RenderMsgpackCodec renders the Golang's source code of the `MarshalMsg` and `UnmarshalMsg` methods of the struct type
+// named `typeName`, whose definition is `typ`, implementing the `msgp.Marshaler` and `msgp.Unmarshaler` interfaces of
+// the "github.com/tinylib/msgp/msgp" package without reflection. The struct is encoded as a map keyed by the names
+// declared by the `msg` or `msgpack` tags, which default to the fields' names. The fields tagged by "-" are skipped,
+// and the tag options, like `omitempty`, are ignored.
+//
+// The fields of the QualTypes are encoded using their `Underlying` definitions filled by the deep resolution, except
+// `time.Time`. The unresolved QualTypes, and the resolved QualTypes of struct types, are expected to implement the
+// msgp's interfaces as well, e.g. by rendering their codecs using RenderMsgpackCodec. The rendered code only contains
+// the methods, the package clause and imports are left to the caller.
+func RenderMsgpackCodec(typeName string, typ Type, moduleName string) (string, error) {
+	if typ.StructType == nil {
+		return "", fmt.Errorf("cannot render msgpack codec of a non-struct type: %s", typ.String(moduleName))
+	}
+	if typ.IsGeneric() {
+		return "", fmt.Errorf("cannot render msgpack codec of the generic type %s", typeName)
+	}
+
+	type codecField struct {
+		key string
+		TypeField
+	}
+	fields := make([]codecField, 0, len(typ.StructType.Fields))
+	for _, field := range typ.StructType.Fields {
+		if !ast.IsExported(field.Name) {
+			continue
+		}
+		tag, ok := field.Tag.Lookup("msg")
+		if !ok {
+			tag = field.Tag.Get("msgpack")
+		}
+		key := strings.Split(tag, ",")[0]
+		if key == "-" {
+			continue
+		}
+		if key == "" {
+			key = field.Name
+		}
+		fields = append(fields, codecField{key: key, TypeField: field})
+	}
+
+	r := &msgpackRenderer{moduleName: moduleName}
+	r.line(0, "// MarshalMsg implements msgp.Marshaler.")
+	r.line(0, "func (z *%s) MarshalMsg(b []byte) (o []byte, err error) {", typeName)
+	r.line(1, "o = msgp.AppendMapHeader(b, %d)", len(fields))
+	for _, field := range fields {
+		r.line(1, "o = msgp.AppendString(o, %q)", field.key)
+		if err := r.encode(1, "z."+field.Name, field.Type); err != nil {
+			return "", fmt.Errorf("cannot render encoding of field %s: %w", field.Name, err)
+		}
+	}
+	r.line(1, "return o, nil")
+	r.line(0, "}")
+
+	r.line(0, "")
+	r.line(0, "// UnmarshalMsg implements msgp.Unmarshaler.")
+	r.line(0, "func (z *%s) UnmarshalMsg(bts []byte) (o []byte, err error) {", typeName)
+	r.line(1, "var n uint32")
+	r.line(1, "n, bts, err = msgp.ReadMapHeaderBytes(bts)")
+	r.returnOnError(1)
+	r.line(1, "for n > 0 {")
+	r.line(2, "n--")
+	r.line(2, "var field []byte")
+	r.line(2, "field, bts, err = msgp.ReadMapKeyZC(bts)")
+	r.returnOnError(2)
+	r.line(2, "switch msgp.UnsafeString(field) {")
+	for _, field := range fields {
+		r.line(2, "case %q:", field.key)
+		if err := r.decode(3, "z."+field.Name, field.Type); err != nil {
+			return "", fmt.Errorf("cannot render decoding of field %s: %w", field.Name, err)
+		}
+	}
+	r.line(2, "default:")
+	r.line(3, "bts, err = msgp.Skip(bts)")
+	r.returnOnError(3)
+	r.line(2, "}")
+	r.line(1, "}")
+	r.line(1, "return bts, nil")
+	r.line(0, "}")
+
+	return r.b.String(), nil
+}
+
+func (r *msgpackRenderer) returnOnError(indent int) {
+	r.line(indent, "if err != nil {")
+	r.line(indent+1, "return nil, err")
+	r.line(indent, "}")
+}
+
+// encode renders the statements appending the encoded `expr` of type `typ` into `o`.
+func (r *msgpackRenderer) encode(indent int, expr string, typ Type) error {
+	switch {
+	case isByteSlice(typ):
+		r.line(indent, "o = msgp.AppendBytes(o, %s)", expr)
+	case isTimeType(typ):
+		r.line(indent, "o = msgp.AppendTime(o, %s)", expr)
+	case typ.PrimitiveType != nil:
+		suffix, ok := msgpSuffixes[typ.PrimitiveType.Kind]
+		if !ok {
+			return fmt.Errorf("unsupported type %s", typ.String(r.moduleName))
+		}
+		r.line(indent, "o = msgp.Append%s(o, %s)", suffix, expr)
+	case typ.QualType != nil:
+		underlying := typ.QualType.Underlying
+		if underlying == nil || underlying.StructType != nil {
+			r.line(indent, "o, err = %s.MarshalMsg(o)", operand(expr))
+			r.returnOnError(indent)
+			return nil
+		}
+		return r.encode(indent, underlying.String(r.moduleName)+"("+expr+")", *underlying)
+	case typ.PtrType != nil:
+		r.line(indent, "if %s == nil {", expr)
+		r.line(indent+1, "o = msgp.AppendNil(o)")
+		r.line(indent, "} else {")
+		if err := r.encode(indent+1, "*"+expr, typ.PtrType.Elem); err != nil {
+			return err
+		}
+		r.line(indent, "}")
+	case typ.SliceType != nil, typ.ArrayType != nil:
+		elem := r.newVar("v")
+		r.line(indent, "o = msgp.AppendArrayHeader(o, uint32(len(%s)))", expr)
+		r.line(indent, "for _, %s := range %s {", elem, expr)
+		if err := r.encode(indent+1, elem, elemType(typ)); err != nil {
+			return err
+		}
+		r.line(indent, "}")
+	case typ.MapType != nil:
+		key, elem := r.newVar("k"), r.newVar("v")
+		r.line(indent, "o = msgp.AppendMapHeader(o, uint32(len(%s)))", expr)
+		r.line(indent, "for %s, %s := range %s {", key, elem, expr)
+		if err := r.encode(indent+1, key, typ.MapType.Key); err != nil {
+			return err
+		}
+		if err := r.encode(indent+1, elem, typ.MapType.Elem); err != nil {
+			return err
+		}
+		r.line(indent, "}")
+	default:
+		return fmt.Errorf("unsupported type %s", typ.String(r.moduleName))
+	}
+	return nil
+}
+
+// decode renders the statements reading a value of type `typ` from `bts` into `target`.
+func (r *msgpackRenderer) decode(indent int, target string, typ Type) error {
+	switch {
+	case isByteSlice(typ):
+		r.line(indent, "%s, bts, err = msgp.ReadBytesBytes(bts, %s)", target, target)
+		r.returnOnError(indent)
+	case isTimeType(typ):
+		r.line(indent, "%s, bts, err = msgp.ReadTimeBytes(bts)", target)
+		r.returnOnError(indent)
+	case typ.PrimitiveType != nil:
+		suffix, ok := msgpSuffixes[typ.PrimitiveType.Kind]
+		if !ok {
+			return fmt.Errorf("unsupported type %s", typ.String(r.moduleName))
+		}
+		r.line(indent, "%s, bts, err = msgp.Read%sBytes(bts)", target, suffix)
+		r.returnOnError(indent)
+	case typ.QualType != nil:
+		underlying := typ.QualType.Underlying
+		if underlying == nil || underlying.StructType != nil {
+			r.line(indent, "bts, err = %s.UnmarshalMsg(bts)", operand(target))
+			r.returnOnError(indent)
+			return nil
+		}
+		value := r.newVar("v")
+		r.line(indent, "var %s %s", value, underlying.String(r.moduleName))
+		if err := r.decode(indent, value, *underlying); err != nil {
+			return err
+		}
+		r.line(indent, "%s = %s(%s)", target, typ.String(r.moduleName), value)
+	case typ.PtrType != nil:
+		r.line(indent, "if msgp.IsNil(bts) {")
+		r.line(indent+1, "bts, err = msgp.ReadNilBytes(bts)")
+		r.returnOnError(indent + 1)
+		r.line(indent+1, "%s = nil", target)
+		r.line(indent, "} else {")
+		r.line(indent+1, "if %s == nil {", target)
+		r.line(indent+2, "%s = new(%s)", target, typ.PtrType.Elem.String(r.moduleName))
+		r.line(indent+1, "}")
+		if err := r.decode(indent+1, "*"+target, typ.PtrType.Elem); err != nil {
+			return err
+		}
+		r.line(indent, "}")
+	case typ.SliceType != nil, typ.ArrayType != nil:
+		size, index := r.newVar("n"), r.newVar("i")
+		r.line(indent, "var %s uint32", size)
+		r.line(indent, "%s, bts, err = msgp.ReadArrayHeaderBytes(bts)", size)
+		r.returnOnError(indent)
+		if typ.SliceType != nil {
+			r.line(indent, "%s = make(%s, %s)", target, typ.String(r.moduleName), size)
+		} else {
+			r.line(indent, "if %s != %d {", size, typ.ArrayType.Len)
+			r.line(indent+1, "return nil, msgp.ArrayError{Wanted: %d, Got: %s}", typ.ArrayType.Len, size)
+			r.line(indent, "}")
+		}
+		r.line(indent, "for %s := range %s {", index, target)
+		if err := r.decode(indent+1, target+"["+index+"]", elemType(typ)); err != nil {
+			return err
+		}
+		r.line(indent, "}")
+	case typ.MapType != nil:
+		size, key, elem := r.newVar("n"), r.newVar("k"), r.newVar("v")
+		r.line(indent, "var %s uint32", size)
+		r.line(indent, "%s, bts, err = msgp.ReadMapHeaderBytes(bts)", size)
+		r.returnOnError(indent)
+		r.line(indent, "%s = make(%s, %s)", target, typ.String(r.moduleName), size)
+		r.line(indent, "for ; %s > 0; %s-- {", size, size)
+		r.line(indent+1, "var %s %s", key, typ.MapType.Key.String(r.moduleName))
+		r.line(indent+1, "var %s %s", elem, typ.MapType.Elem.String(r.moduleName))
+		if err := r.decode(indent+1, key, typ.MapType.Key); err != nil {
+			return err
+		}
+		if err := r.decode(indent+1, elem, typ.MapType.Elem); err != nil {
+			return err
+		}
+		r.line(indent+1, "%s[%s] = %s", target, key, elem)
+		r.line(indent, "}")
+	default:
+		return fmt.Errorf("unsupported type %s", typ.String(r.moduleName))
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package gotype
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NamingPolicy decides the names of the unnamed parameters and results of the generated FuncTypes, see
+// `WithNamingPolicy`. The default policy names them "arg1", "arg2", ..., and "out1", "out2", ... .
+type NamingPolicy struct {
+	// InputPrefix is the prefix of the names of the unnamed parameters, followed by their 1-based index among the
+	// unnamed parameters. It defaults to "arg".
+	InputPrefix string
+
+	// OutputPrefix is the prefix of the names of the unnamed results, followed by their 1-based index among the unnamed
+	// results. It defaults to "out".
+	OutputPrefix string
+
+	// Separator is written between the prefix and the index, like "_" for "arg_1".
+	Separator string
+
+	// Capitalized makes the names start with an upper case letter, like "Arg1" and "Ctx", e.g. when they're used as
+	// the names of the struct fields recording the calls of a mock.
+	Capitalized bool
+
+	// TypeNames maps the types to the names given to the unnamed parameters and results of these types, instead of the
+	// prefixed names, like "ctx" for `context.Context` and "err" for `error`. The named types are keyed by their
+	// package path and name, like "context.Context", the primitive types by their names, like "error". See
+	// ConventionalTypeNames.
+	TypeNames map[string]string
+}
+
+// ConventionalTypeNames contains the conventional names of the parameters and results of the common types, to be used
+// as `NamingPolicy.TypeNames`.
+var ConventionalTypeNames = map[string]string{
+	"context.Context": "ctx",
+	"error":           "err",
+}
+
+// nameUnnamedFields names the unnamed parameters and results of a function according to the configured NamingPolicy.
+// The parameters and results share a single scope, so a synthesized name never clashes with another name of the
+// function: a prefixed name takes the next free index, like "out2", and a name given by `NamingPolicy.TypeNames` is
+// suffixed by the lowest number making it unique, like "err2".
+func (f *astTypeGenerator) nameUnnamedFields(inputs, outputs []TypeField) {
+	policy := f.config.namingPolicy
+	if policy.InputPrefix == "" {
+		policy.InputPrefix = "arg"
+	}
+	if policy.OutputPrefix == "" {
+		policy.OutputPrefix = "out"
+	}
+
+	used := make(map[string]struct{})
+	for _, field := range append(append([]TypeField{}, inputs...), outputs...) {
+		if field.Name != "" && field.Name != "_" {
+			used[field.Name] = struct{}{}
+		}
+	}
+	isUsed := func(name string) bool {
+		_, ok := used[name]
+		return ok
+	}
+	capitalize := func(name string) string {
+		if !policy.Capitalized {
+			return name
+		}
+		r, size := utf8.DecodeRuneInString(name)
+		return string(unicode.ToUpper(r)) + name[size:]
+	}
+
+	nameFields := func(fields []TypeField, prefix string) {
+		index := 0
+		for i := range fields {
+			if fields[i].Name != "" {
+				continue
+			}
+			index++
+
+			var name string
+			if typeName := policy.TypeNames[namingTypeKey(fields[i].Type)]; typeName != "" {
+				name = capitalize(typeName)
+				for n := 2; isUsed(name); n++ {
+					name = capitalize(typeName + strconv.Itoa(n))
+				}
+			} else {
+				name = capitalize(prefix + policy.Separator + strconv.Itoa(index))
+				for isUsed(name) {
+					index++
+					name = capitalize(prefix + policy.Separator + strconv.Itoa(index))
+				}
+			}
+			used[name] = struct{}{}
+			fields[i].Name = name
+		}
+	}
+	nameFields(inputs, policy.InputPrefix)
+	nameFields(outputs, policy.OutputPrefix)
+}
+
+// namingTypeKey returns the key of `t` inside `NamingPolicy.TypeNames`, or an empty string for the unnamed types.
+func namingTypeKey(t Type) string {
+	switch {
+	case t.QualType != nil:
+		return strings.TrimPrefix(t.QualType.Package+"."+t.QualType.Name, ".")
+	case t.PrimitiveType != nil:
+		return string(t.PrimitiveType.Kind)
+	}
+	return ""
+}
@@ -0,0 +1,357 @@
+package gotype
+
+import (
+	"context"
+	"time"
+)
+
+// defaultMaxEmbeddingDepth is the default maximum depth of the embedded interfaces. See `WithMaxEmbeddingDepth`.
+const defaultMaxEmbeddingDepth = 64
+
+// Option configures the behaviour of a TypeGenerator.
+type Option func(*config)
+
+type config struct {
+	keepEmbeddedInterfaces bool
+	ordering               Ordering
+	deepResolution         bool
+	warningHandler         func(Warning)
+	logger                 Logger
+	hooks                  Hooks
+	partialResults         bool
+	tolerantParsing        bool
+	trace                  *Trace
+	maxEmbeddingDepth      int
+	fetchModules           bool
+	moduleArchives         []string
+	targetPackage          string
+	packagePathRewrites    []func(string) string
+	buildConfig            *Config
+	gitRevision            string
+	sourceFinder           SourceFinder
+	determinismCheck       bool
+	concurrency            int
+	profilerLabels         context.Context
+	namingPolicy           NamingPolicy
+	exportedOnly           bool
+	maxDepth               int
+	localTypes             bool
+	ignoreLineDirectives   bool
+	annotationMarkers      []string
+	cacheWatcher           *CacheWatcher
+	typeChecking           bool
+	methods                bool
+}
+
+func newConfig(opts ...Option) config {
+	c := config{maxEmbeddingDepth: defaultMaxEmbeddingDepth, annotationMarkers: defaultAnnotationMarkers}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// WithEmbeddedInterfaces keeps the embedded interfaces unflattened. The generated InterfaceType only lists the methods
+// declared directly inside the interface, and the embedded interfaces can be found in `InterfaceType.Embedded`.
+func WithEmbeddedInterfaces() Option {
+	return func(c *config) {
+		c.keepEmbeddedInterfaces = true
+	}
+}
+
+// WithOrdering sets the order of `InterfaceType.Methods` and `StructType.Fields`. By default, they are kept in their
+// source order.
+func WithOrdering(ordering Ordering) Option {
+	return func(c *config) {
+		c.ordering = ordering
+	}
+}
+
+// WithDeepResolution makes the generator follow the QualTypes and fill `QualType.Underlying` with their resolved
+// definitions, recursively. Instantiated generic types are resolved into their instantiated definitions.
+func WithDeepResolution() Option {
+	return func(c *config) {
+		c.deepResolution = true
+	}
+}
+
+// WithWarningHandler makes the generator report the constructs it can't fully model to `handler` instead of failing.
+// For example, an unrecognized type expression is reported as a Warning and generated as an empty Type, and an array
+// with an unrecognized length is generated with zero length. Without a warning handler, such constructs are errors.
+// Some constructs, like cgo references or skipped embedded fields, are only reported when a handler is configured.
+func WithWarningHandler(handler func(Warning)) Option {
+	return func(c *config) {
+		c.warningHandler = handler
+	}
+}
+
+// Logger is the minimal logging interface used by the generator. It's implemented by the standard library's
+// `*log.Logger`.
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+// WithLogger makes the generator log which files are parsed, the source files cache hits and misses, and how the
+// identifiers are resolved. It's useful to debug why a type is resolved the wrong way.
+func WithLogger(logger Logger) Option {
+	return func(c *config) {
+		c.logger = logger
+	}
+}
+
+// Hooks contains the callbacks called by the generator to report its progress and performance. Any of the callbacks
+// can be nil.
+type Hooks struct {
+	// PackageResolved is called after the source files of a package are found, with the number of the source files
+	// and the time spent to find them.
+	PackageResolved func(packagePath string, files int, duration time.Duration)
+
+	// FileParsed is called after a source file is parsed, with the number of bytes read and the time spent to read and
+	// parse the file.
+	FileParsed func(filename string, bytes int, duration time.Duration)
+
+	// StartSpan is called when a phase of the generator starts, like finding a package, parsing a file, generating the
+	// types of a package, or resolving a type deeply, with the phase's name, like "gotype.parse_file", and its
+	// attributes, like "gotype.package" and "gotype.file". The returned function, if not nil, is called with the
+	// phase's error when it ends. It maps to the OpenTelemetry spans: the callback can start a span using
+	// `tracer.Start` and return a function recording the error and ending the span. The phases are nested, the spans
+	// of a call are started and ended by the same goroutine.
+	StartSpan func(name string, attributes map[string]string) (end func(err error))
+}
+
+// WithHooks sets the callbacks reporting the generator's progress and metrics, e.g. to render a progress bar or to
+// collect the performance metrics of long extractions.
+func WithHooks(hooks Hooks) Option {
+	return func(c *config) {
+		c.hooks = hooks
+	}
+}
+
+// WithPartialResults makes GenerateTypesFromSpecs return the successfully generated types even when some of the specs
+// fail. The failed specs are generated as empty Types, and the returned error is a *PartialError containing the error
+// of each failed spec.
+func WithPartialResults() Option {
+	return func(c *config) {
+		c.partialResults = true
+	}
+}
+
+// WithTolerantParsing makes the generator keep searching for the declarations when some source files of a package have
+// syntax errors. The parsable parts of such files are still searched. When a declaration can't be found, the returned
+// error wraps the ParseErrors of the package, otherwise the syntax errors are reported as warnings. By default, the
+// first syntax error fails the generation.
+func WithTolerantParsing() Option {
+	return func(c *config) {
+		c.tolerantParsing = true
+	}
+}
+
+// WithTrace makes the generator record the decisions taken while resolving each type into `trace`, like the file
+// where a declaration is found, the import used to resolve a selector, or the fallback used for an unknown identifier.
+func WithTrace(trace *Trace) Option {
+	return func(c *config) {
+		c.trace = trace
+	}
+}
+
+// WithMaxEmbeddingDepth sets the maximum depth of the embedded interfaces and constraints followed by the generator.
+// Deeper embeddings fail with an error naming the embedding chain. By default, the maximum depth is 64. A
+// non-positive depth doesn't bound the embeddings, which still fail when they're recursive.
+func WithMaxEmbeddingDepth(depth int) Option {
+	return func(c *config) {
+		c.maxEmbeddingDepth = depth
+	}
+}
+
+// WithMaxDepth bounds how deep the resolution enabled by `WithDeepResolution` expands the nested QualTypes, to keep
+// huge model graphs, like the Kubernetes API types, tractable. The QualTypes referenced by the generated types are at
+// depth 1, the ones referenced by their definitions at depth 2, and so on. The QualTypes deeper than `depth` are kept
+// as references, without `QualType.Underlying`. A non-positive depth, the default, doesn't bound the resolution.
+func WithMaxDepth(depth int) Option {
+	return func(c *config) {
+		c.maxDepth = depth
+	}
+}
+
+// WithLocalTypes makes the generator find the types declared inside the function bodies, named after their functions,
+// like "Handler.request" for the type `request` declared inside the function `Handler`, or "Server.Serve.request"
+// for the method `Serve` of the type `Server`. The local types referring to each other are qualified the same way.
+func WithLocalTypes() Option {
+	return func(c *config) {
+		c.localTypes = true
+	}
+}
+
+// WithoutLineDirectives makes the generator ignore the //line directives of the source files, so the reported
+// positions, like `TypeField.Position`, point at the files being analyzed instead of the original source files of the
+// generated code. By default, the positions honor the //line directives, so the diagnostics of the tools built on top
+// of the generator point at the real origin.
+func WithoutLineDirectives() Option {
+	return func(c *config) {
+		c.ignoreLineDirectives = true
+	}
+}
+
+// WithModuleFetching makes the generator download the modules required by the go.mod file which aren't inside the
+// module cache, instead of failing, so the types can be generated in clean environments like CI. The modules are
+// downloaded using `go mod download`, so the go tool's environment is respected: the proxies are configured by
+// GOPROXY, and the private modules matched by GOPRIVATE, GONOPROXY and GONOSUMDB are fetched directly and aren't
+// verified against the checksum database.
+func WithModuleFetching() Option {
+	return func(c *config) {
+		c.fetchModules = true
+	}
+}
+
+// WithModuleArchives makes the generator read the packages of the modules stored inside the zip archives found at
+// `archivePaths`, like the ".zip" files of the module cache or the ones served by a module proxy, without unpacking
+// them. A package path having a version, like "example.com/mod/pkg@v1.2.3", only matches the archive of that version.
+// The packages of the other modules are found using the go.mod file.
+func WithModuleArchives(archivePaths ...string) Option {
+	return func(c *config) {
+		c.moduleArchives = append(c.moduleArchives, archivePaths...)
+	}
+}
+
+// WithTargetPackage sets the package where the code using the generated types lives, like the package of a generated
+// mock. The generator checks that the packages of the generated types, and of the QualTypes inside them, can be
+// imported by the target package, so the code doesn't fail to compile later because of an internal package. A
+// violation wraps ErrInternalPackage, and it's reported as a Warning when a warning handler is configured.
+func WithTargetPackage(packagePath string) Option {
+	return func(c *config) {
+		c.targetPackage = packagePath
+	}
+}
+
+// WithPackagePathRewrite makes the generator rewrite the package paths of the generated QualTypes using `rewrite`,
+// e.g. for a codebase in the middle of a module rename, or to generate code into a different module than the analyzed
+// one. `RewriteModulePath` returns the rewrite of a module path. The rewrites are applied in order, after the types
+// are generated, so the source files are still found using the original package paths.
+func WithPackagePathRewrite(rewrite func(packagePath string) string) Option {
+	return func(c *config) {
+		c.packagePathRewrites = append(c.packagePathRewrites, rewrite)
+	}
+}
+
+// WithConfig makes the built-in source finders look for the packages in the directory and environment of `buildConfig`,
+// instead of inheriting the process' working directory and environment, and read the packages' files matching the
+// build constraints of `buildConfig`. See `Config`.
+func WithConfig(buildConfig Config) Option {
+	return func(c *config) {
+		c.buildConfig = &buildConfig
+	}
+}
+
+// WithGitRevision makes the generator read the packages of the main module as they're stored at the git `revision`,
+// like a tag, a branch or a commit hash, without checking it out, e.g. to compare the types of HEAD with the ones of
+// the latest release. The repository and the main module are the ones of the working directory, see `WithConfig`.
+// The packages of the other modules are found using the go.mod file of the working directory.
+func WithGitRevision(revision string) Option {
+	return func(c *config) {
+		c.gitRevision = revision
+	}
+}
+
+// WithSourceFinder makes the generator find the packages' source files using `finder`, instead of the go.mod file of
+// the working directory. The built-in SourceFinders, like NewModuleFinder, NewGoListFinder, NewDirFinder and
+// NewVendorFinder, can be composed using NewChainFinder and NewMergeFinder to describe the layout of a monorepo. The
+// options `WithConfig` and `WithModuleFetching` don't apply to `finder`, the built-in SourceFinders take their own
+// Config.
+func WithSourceFinder(finder SourceFinder) Option {
+	return func(c *config) {
+		c.sourceFinder = finder
+	}
+}
+
+// WithDeterminismCheck makes GenerateTypesFromSpecs generate the types twice, the second time from a fresh state, and
+// fail with an error wrapping ErrNondeterministicOutput when the results differ. The generated types are deterministic
+// by design, the option lets the builds committing generated code verify it at the cost of a slower generation.
+func WithDeterminismCheck() Option {
+	return func(c *config) {
+		c.determinismCheck = true
+	}
+}
+
+// WithConcurrency makes GenerateTypesFromSpecs generate the types of different packages using up to `workers`
+// goroutines, e.g. to speed up the codegen pipelines extracting many packages. The results and the errors don't depend
+// on the scheduling, but the warning handler, the logger and the hooks may be called concurrently. A TypeGenerator can
+// be used by multiple goroutines concurrently regardless of this option.
+func WithConcurrency(workers int) Option {
+	return func(c *config) {
+		c.concurrency = workers
+	}
+}
+
+// WithProfilerLabels labels the goroutines running the generator with runtime/pprof labels, so the CPU profiles of the
+// services embedding the generator tell which phase and which package or file is slow. The labels are
+// "gotype.phase", like "parse_file", "gotype.package", "gotype.file" and "gotype.type". They're added to the labels
+// of `ctx`, which are restored when a phase ends, so `ctx` should carry the labels of the calling goroutine.
+func WithProfilerLabels(ctx context.Context) Option {
+	return func(c *config) {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		c.profilerLabels = ctx
+	}
+}
+
+// WithNamingPolicy sets the policy naming the unnamed parameters and results of the generated FuncTypes, e.g. to name
+// them after their types using ConventionalTypeNames:
+//
+//	WithNamingPolicy(NamingPolicy{TypeNames: ConventionalTypeNames})
+func WithNamingPolicy(policy NamingPolicy) Option {
+	return func(c *config) {
+		c.namingPolicy = policy
+	}
+}
+
+// WithExportedOnly restricts the extraction to the exported identifiers, e.g. for the documentation and client
+// generators focused on the public API. The unexported fields of the generated StructTypes, the unexported methods of
+// the generated InterfaceTypes and of the method sets, and the unexported types of the package-wide analyses are
+// omitted. The declarations specified explicitly, like by a TypeSpec, are still generated. Since the unexported
+// methods are omitted, the analyses comparing method sets, like FindImplementations, ignore them as well.
+func WithExportedOnly() Option {
+	return func(c *config) {
+		c.exportedOnly = true
+	}
+}
+
+// WithAnnotationMarkers sets the prefixes of the marker comments parsed into `TypeField.Annotations`, like
+// "+kubebuilder:validation:" for the `// +kubebuilder:validation:Minimum=1` markers of controller-gen. The keys of the
+// annotations don't contain the prefixes. By default, the markers are prefixed by "+gotype:".
+func WithAnnotationMarkers(prefixes ...string) Option {
+	return func(c *config) {
+		c.annotationMarkers = prefixes
+	}
+}
+
+// WithCacheWatcher makes the generator watch the directories of the packages it reads using `watcher`, which
+// invalidates the cached source files of the packages whose files change, so a long-lived generator doesn't need to
+// be recreated to pick up the new and the removed files. See `CacheWatcher`.
+func WithCacheWatcher(watcher *CacheWatcher) Option {
+	return func(c *config) {
+		c.cacheWatcher = watcher
+	}
+}
+
+// WithTypeChecking makes GenerateTypesFromSpecs generate the types from their packages type-checked by go/types,
+// instead of resolving the identifiers of the syntax trees, so the dot imports, the shadowed names, the aliases and the
+// constant expressions are resolved like by the compiler. The packages and their imports are found by the generator's
+// SourceFinder, and the files which don't type-check only fail the types depending on the invalid code. The
+// QualTypes are named after the packages' names rather than the names of their imports, the aliases are replaced by
+// the types they denote, and `TypeField.Annotations` and `QualType.Chain` aren't filled.
+// The other methods of the TypeGenerator aren't affected.
+func WithTypeChecking() Option {
+	return func(c *config) {
+		c.typeChecking = true
+	}
+}
+
+// WithMethods makes GenerateTypesFromSpecs fill `Type.Methods` of the generated types with the methods declared with
+// the types as their receivers, found inside the source files of their packages, except the test files. The methods
+// are omitted like the fields when `WithExportedOnly` is set.
+func WithMethods() Option {
+	return func(c *config) {
+		c.methods = true
+	}
+}
@@ -0,0 +1,51 @@
+package gotype
+
+import (
+	"go/token"
+	"sort"
+)
+
+// Ordering represents the policy used to order the methods of an interface and the fields of a struct.
+type Ordering int
+
+const (
+	// OrderingSource keeps the methods and fields in the order they are written in the source code. The methods of an
+	// embedded interface are placed where the interface is embedded. This is the default ordering.
+	OrderingSource Ordering = iota
+
+	// OrderingAlphabetical sorts the methods and fields by their names. Fields with the same name, like the blank
+	// fields, keep their source order.
+	OrderingAlphabetical
+
+	// OrderingPosition sorts the methods and fields by their positions, that is, by file name, line and column. It's
+	// useful to get a deterministic order for the methods of embedded interfaces declared in other files.
+	OrderingPosition
+)
+
+func (f *astTypeGenerator) sortFields(fields []TypeField) {
+	switch f.config.ordering {
+	case OrderingAlphabetical:
+		sort.SliceStable(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+	case OrderingPosition:
+		sort.SliceStable(fields, func(i, j int) bool { return positionLess(fields[i].Position, fields[j].Position) })
+	}
+}
+
+func (f *astTypeGenerator) sortMethods(methods []InterfaceTypeMethod) {
+	switch f.config.ordering {
+	case OrderingAlphabetical:
+		sort.SliceStable(methods, func(i, j int) bool { return methods[i].Name < methods[j].Name })
+	case OrderingPosition:
+		sort.SliceStable(methods, func(i, j int) bool { return positionLess(methods[i].Position, methods[j].Position) })
+	}
+}
+
+func positionLess(a, b token.Position) bool {
+	if a.Filename != b.Filename {
+		return a.Filename < b.Filename
+	}
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Column < b.Column
+}
@@ -0,0 +1,290 @@
+package gotype
+
+import (
+	"fmt"
+	"go/ast"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// RelationKind represents the kind of a relation between two ORM models.
+type RelationKind string
+
+const (
+	// RelationBelongsTo represents a relation where the model holds the foreign key of the related model.
+	RelationBelongsTo RelationKind = "belongs_to"
+
+	// RelationHasOne represents a relation where the related model holds the foreign key of the model.
+	RelationHasOne RelationKind = "has_one"
+
+	// RelationHasMany represents a relation where many related models hold the foreign key of the model.
+	RelationHasMany RelationKind = "has_many"
+
+	// RelationManyToMany represents a relation using a join table.
+	RelationManyToMany RelationKind = "many_to_many"
+)
+
+// Model is the database mapping of a struct type, as understood by gorm and sqlx.
+type Model struct {
+	// Name is the name of the struct type.
+	Name string
+
+	// Table is the name of the table, which is the plural snake case of the type's name.
+	Table string
+
+	Columns   []Column
+	Indexes   []Index
+	Relations []Relation
+}
+
+// Column is the mapping of a struct's field into a table's column.
+type Column struct {
+	// Field is the name of the struct's field.
+	Field string
+
+	// Name is the name of the column.
+	Name string
+
+	// Type is the type of the struct's field.
+	Type Type
+
+	// SQLType is the column's type declared by the `type` gorm tag, if any.
+	SQLType string
+
+	// Size is the column's size declared by the `size` gorm tag, or zero.
+	Size int
+
+	// Default is the column's default value declared by the `default` gorm tag, if any.
+	Default string
+
+	PrimaryKey    bool
+	AutoIncrement bool
+	NotNull       bool
+	Unique        bool
+}
+
+// Index is an index of a table.
+type Index struct {
+	Name    string
+	Columns []string
+	Unique  bool
+}
+
+// Relation is the relation between a model and another model, declared by a struct's field.
+type Relation struct {
+	// Field is the name of the struct's field.
+	Field string
+
+	Kind RelationKind
+
+	// Model is the related model's type.
+	Model QualType
+
+	// ForeignKey is the name of the field holding the foreign key, declared by the `foreignKey` gorm tag or found by
+	// gorm's naming convention.
+	ForeignKey string
+
+	// References is the name of the field referenced by the foreign key, declared by the `references` gorm tag.
+	References string
+
+	// JoinTable is the name of the join table of a many to many relation.
+	JoinTable string
+}
+
+// AnalyzeModel returns the database mapping of the struct type named `typeName`, whose definition is `typ`. The
+// mapping is read from the `gorm` and `db` (sqlx) struct tags, and follows gorm's conventions otherwise: the column
+// names are the snake case of the fields' names and the `ID` field is the primary key.
+//
+// A field whose type is a struct, a pointer to a struct or a slice of them is a relation. The QualTypes are recognized
+// as structs using their `Underlying` definitions filled by the deep resolution. Unresolved QualTypes are columns,
+// unless the field is tagged by `foreignKey`, `references` or `many2many`. The embedded fields, like `gorm.Model`, are
+// skipped, so their columns are not part of the model.
+func AnalyzeModel(typeName string, typ Type) (Model, error) {
+	if typ.StructType == nil {
+		return Model{}, fmt.Errorf("cannot analyze model of a non-struct type: %s", typ.String(""))
+	}
+
+	model := Model{Name: typeName, Table: pluralize(snakeCase(typeName))}
+	fieldNames := make(map[string]struct{})
+	for _, field := range typ.StructType.Fields {
+		fieldNames[field.Name] = struct{}{}
+	}
+
+	indexes := make(map[string]*Index)
+	indexNames := make([]string, 0)
+	for _, field := range typ.StructType.Fields {
+		if !ast.IsExported(field.Name) || field.Embedded {
+			continue
+		}
+
+		gormTag := parseGormTag(field.Tag.Get("gorm"))
+		dbTag := strings.Split(field.Tag.Get("db"), ",")[0]
+		if _, ok := gormTag["-"]; ok || dbTag == "-" {
+			continue
+		}
+
+		if relation, ok := modelRelation(field, gormTag, fieldNames); ok {
+			model.Relations = append(model.Relations, relation)
+			continue
+		}
+
+		column := Column{Field: field.Name, Name: snakeCase(field.Name), Type: field.Type}
+		if dbTag != "" {
+			column.Name = dbTag
+		}
+		if name, ok := gormTag["column"]; ok {
+			column.Name = name
+		}
+		column.SQLType = gormTag["type"]
+		column.Size, _ = strconv.Atoi(gormTag["size"])
+		column.Default = gormTag["default"]
+		_, column.PrimaryKey = gormTag["primarykey"]
+		column.PrimaryKey = column.PrimaryKey || (field.Name == "ID" && !hasPrimaryKeyTag(typ.StructType))
+		_, column.AutoIncrement = gormTag["autoincrement"]
+		_, column.NotNull = gormTag["not null"]
+		_, column.Unique = gormTag["unique"]
+		model.Columns = append(model.Columns, column)
+
+		for _, key := range []string{"index", "uniqueindex"} {
+			value, ok := gormTag[key]
+			if !ok {
+				continue
+			}
+
+			name, options := value, ""
+			if i := strings.Index(value, ","); i >= 0 {
+				name, options = value[:i], value[i+1:]
+			}
+			if name == "" {
+				name = "idx_" + model.Table + "_" + column.Name
+			}
+
+			index, ok := indexes[name]
+			if !ok {
+				index = &Index{Name: name}
+				indexes[name] = index
+				indexNames = append(indexNames, name)
+			}
+			index.Columns = append(index.Columns, column.Name)
+			index.Unique = index.Unique || key == "uniqueindex" || strings.Contains(options, "unique")
+		}
+	}
+
+	for _, name := range indexNames {
+		model.Indexes = append(model.Indexes, *indexes[name])
+	}
+	return model, nil
+}
+
+// modelRelation returns the relation declared by a struct's field. It returns false when the field is a column.
+func modelRelation(field TypeField, gormTag map[string]string, fieldNames map[string]struct{}) (Relation, bool) {
+	typ, many := field.Type, false
+	if typ.SliceType != nil {
+		typ, many = typ.SliceType.Elem, true
+	}
+	if typ.PtrType != nil {
+		typ = typ.PtrType.Elem
+	}
+	if typ.QualType == nil || typ.QualType.Package == "time" || typ.QualType.Package == "database/sql" {
+		return Relation{}, false
+	}
+
+	_, hasForeignKey := gormTag["foreignkey"]
+	_, hasReferences := gormTag["references"]
+	joinTable, hasJoinTable := gormTag["many2many"]
+	isStruct := typ.QualType.Underlying != nil && typ.QualType.Underlying.StructType != nil
+	if !isStruct && !hasForeignKey && !hasReferences && !hasJoinTable {
+		return Relation{}, false
+	}
+
+	relation := Relation{
+		Field:      field.Name,
+		Model:      *typ.QualType,
+		ForeignKey: gormTag["foreignkey"],
+		References: gormTag["references"],
+	}
+	switch {
+	case hasJoinTable:
+		relation.Kind = RelationManyToMany
+		relation.JoinTable = joinTable
+	case many:
+		relation.Kind = RelationHasMany
+	default:
+		foreignKey := relation.ForeignKey
+		if foreignKey == "" {
+			foreignKey = field.Name + "ID"
+		}
+		if _, ok := fieldNames[foreignKey]; ok {
+			relation.Kind = RelationBelongsTo
+			relation.ForeignKey = foreignKey
+		} else {
+			relation.Kind = RelationHasOne
+		}
+	}
+	return relation, true
+}
+
+func hasPrimaryKeyTag(structType *StructType) bool {
+	for _, field := range structType.Fields {
+		if _, ok := parseGormTag(field.Tag.Get("gorm"))["primarykey"]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// parseGormTag parses a gorm tag like `column:name;not null;index:idx_name,unique` into a map of lowercase keys to
+// their values. The keys are case-insensitive in gorm.
+func parseGormTag(tag string) map[string]string {
+	result := make(map[string]string)
+	for _, setting := range strings.Split(tag, ";") {
+		setting = strings.TrimSpace(setting)
+		if setting == "" {
+			continue
+		}
+
+		key, value := setting, ""
+		if i := strings.Index(setting, ":"); i >= 0 {
+			key, value = setting[:i], setting[i+1:]
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "primary_key" {
+			key = "primarykey"
+		}
+		result[key] = strings.TrimSpace(value)
+	}
+	return result
+}
+
+// snakeCase converts a Golang's identifier into snake case, keeping the initialisms together, like `user_id` for
+// `UserID`.
+func snakeCase(name string) string {
+	runes := []rune(name)
+	b := strings.Builder{}
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// pluralize returns the plural form of an english noun using the common rules.
+func pluralize(noun string) string {
+	switch {
+	case noun == "":
+		return noun
+	case strings.HasSuffix(noun, "y") && len(noun) > 1 && !strings.ContainsRune("aeiou", rune(noun[len(noun)-2])):
+		return noun[:len(noun)-1] + "ies"
+	case strings.HasSuffix(noun, "s"), strings.HasSuffix(noun, "x"), strings.HasSuffix(noun, "z"),
+		strings.HasSuffix(noun, "ch"), strings.HasSuffix(noun, "sh"):
+		return noun + "es"
+	}
+	return noun + "s"
+}
@@ -0,0 +1,106 @@
+package gotype
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeModel(t *testing.T) {
+	types, err := NewGenerator(WithDeepResolution()).GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: testdataPackage + "/orm", Name: "User"},
+	)
+	require.NoError(t, err)
+
+	model, err := AnalyzeModel("User", types[0])
+	require.NoError(t, err)
+	assert.Equal(t, "users", model.Table)
+
+	columns := make([]string, 0)
+	for _, column := range model.Columns {
+		columns = append(columns, column.Name)
+	}
+	assert.Equal(
+		t,
+		[]string{"id", "email", "first_name", "last_name", "nick", "state", "created_at", "company_id"},
+		columns,
+	)
+
+	id := model.Columns[0]
+	assert.True(t, id.PrimaryKey)
+	assert.True(t, id.AutoIncrement)
+	email := model.Columns[1]
+	assert.Equal(t, 255, email.Size)
+	assert.True(t, email.NotNull)
+	assert.Equal(t, "'active'", model.Columns[5].Default)
+	assert.Equal(t, "timestamptz", model.Columns[6].SQLType)
+
+	assert.Equal(t, []Index{
+		{Name: "idx_users_email", Columns: []string{"email"}, Unique: true},
+		{Name: "idx_name", Columns: []string{"first_name", "last_name"}},
+	}, model.Indexes)
+
+	require.Len(t, model.Relations, 4)
+	assert.Equal(t, RelationBelongsTo, model.Relations[0].Kind)
+	assert.Equal(t, "CompanyID", model.Relations[0].ForeignKey)
+	assert.Equal(t, "Company", model.Relations[0].Model.Name)
+	assert.Equal(t, RelationHasOne, model.Relations[1].Kind)
+	assert.Equal(t, RelationHasMany, model.Relations[2].Kind)
+	assert.Equal(t, RelationManyToMany, model.Relations[3].Kind)
+	assert.Equal(t, "user_roles", model.Relations[3].JoinTable)
+}
+
+func TestSnakeCaseAndPluralize(t *testing.T) {
+	assert.Equal(t, "user_id", snakeCase("UserID"))
+	assert.Equal(t, "http_server", snakeCase("HTTPServer"))
+	assert.Equal(t, "categories", pluralize("category"))
+	assert.Equal(t, "addresses", pluralize("address"))
+	assert.Equal(t, "keys", pluralize("key"))
+}
+
+func TestRenderEntSchema(t *testing.T) {
+	types, err := NewGenerator(WithDeepResolution()).GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: testdataPackage + "/orm", Name: "User"},
+	)
+	require.NoError(t, err)
+
+	code, err := RenderEntSchema("User", types[0], []string{"Company", "Profile", "Order", "Role"}, "orm")
+	require.NoError(t, err)
+	assert.Equal(t, `// User holds the schema definition for the User entity.
+type User struct {
+	ent.Schema
+}
+
+// Fields of the User.
+func (User) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("email"),
+		field.String("first_name"),
+		field.String("last_name"),
+		field.String("nickname"),
+		field.String("status"),
+		field.Time("created_at"),
+		field.Uint("company_id"),
+		field.String("password"),
+	}
+}
+
+// Edges of the User.
+func (User) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.To("company", Company.Type).Unique().Field("company_id"),
+		edge.To("profile", Profile.Type).Unique(),
+		edge.To("orders", Order.Type),
+		edge.To("roles", Role.Type),
+	}
+}
+`, code)
+
+	code, err = RenderEntSchema("Order", Type{StructType: &StructType{Fields: []TypeField{
+		{Name: "UserID", Type: PrimitiveType{Kind: PrimitiveKindUint}.Type()},
+	}}}, []string{"User"}, "orm")
+	require.NoError(t, err)
+	assert.Contains(t, code, "\t\tfield.Uint(\"user_id\"),\n")
+	assert.Contains(t, code, "\t\tedge.To(\"user\", User.Type).Unique().Field(\"user_id\"),\n")
+}
@@ -0,0 +1,52 @@
+package gotype
+
+import (
+	"errors"
+	"go/ast"
+	"go/scanner"
+	"strings"
+)
+
+// FileParseError contains the syntax errors found inside a single source file.
+type FileParseError struct {
+	Filename string
+	Errors   scanner.ErrorList
+}
+
+func (e FileParseError) Error() string {
+	return e.Errors.Error()
+}
+
+// ParseErrors contains the syntax errors of the source files of a package, grouped by file. See `WithTolerantParsing`.
+type ParseErrors []FileParseError
+
+func (e ParseErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fileErr := range e {
+		messages = append(messages, fileErr.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// tolerateParseError checks whether the generation can continue after failing to parse `filename`. It returns the
+// syntax errors of the file and true when tolerant parsing is enabled and a partial AST of the file is available.
+func (f *astTypeGenerator) tolerateParseError(filename string, fileAst *ast.File, err error) (FileParseError, bool) {
+	if !f.config.tolerantParsing || fileAst == nil {
+		return FileParseError{}, false
+	}
+
+	var syntaxErrs scanner.ErrorList
+	if !errors.As(err, &syntaxErrs) {
+		return FileParseError{}, false
+	}
+	return FileParseError{Filename: filename, Errors: syntaxErrs}, true
+}
+
+// warnParseErrors reports each syntax error as a warning.
+func (f *astTypeGenerator) warnParseErrors(parseErrs ParseErrors) {
+	for _, fileErr := range parseErrs {
+		for _, syntaxErr := range fileErr.Errors {
+			f.warnAt(syntaxErr.Pos, "%s", syntaxErr.Msg)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package gotype
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PartialError is returned by GenerateTypesFromSpecs when the generator is configured using `WithPartialResults` and
+// some of the specs can't be generated. The types of the other specs are still returned.
+type PartialError struct {
+	// Errors contains the error of each failed spec.
+	Errors map[TypeSpec]error
+}
+
+func (e *PartialError) Error() string {
+	specs := make([]TypeSpec, 0, len(e.Errors))
+	for spec := range e.Errors {
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool {
+		if specs[i].PackagePath != specs[j].PackagePath {
+			return specs[i].PackagePath < specs[j].PackagePath
+		}
+		return specs[i].Name < specs[j].Name
+	})
+
+	messages := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		messages = append(messages, fmt.Sprintf("%s.%s: %s", spec.PackagePath, spec.Name, e.Errors[spec]))
+	}
+	return fmt.Sprintf("cannot generate %d types: %s", len(specs), strings.Join(messages, "; "))
+}
+
+// generatePartialTypesFromSpecs generates the types like GenerateTypesFromSpecs, but keeps going when a spec fails.
+// The types of a package are generated together, and only when that fails, each of them is generated alone to find
+// out which specs are failing.
+func (f *astTypeGenerator) generatePartialTypesFromSpecs(typeSpecs []TypeSpec) ([]Type, error) {
+	packagePaths, packagePathToSpecs := f.groupTypeSpecByPackage(typeSpecs)
+
+	resultMap := make(map[TypeSpec]Type)
+	errs := make(map[TypeSpec]error)
+	for _, packagePath := range packagePaths {
+		names := packagePathToSpecs[packagePath]
+		types, err := f.generateTypesInSinglePackage(packagePath, names...)
+		if err == nil {
+			for i, typ := range types {
+				resultMap[TypeSpec{PackagePath: packagePath, Name: names[i]}] = typ
+			}
+			continue
+		}
+
+		for _, name := range names {
+			spec := TypeSpec{PackagePath: packagePath, Name: name}
+			types, err := f.generateTypesInSinglePackage(packagePath, name)
+			if err != nil {
+				errs[spec] = err
+				continue
+			}
+			resultMap[spec] = types[0]
+		}
+	}
+
+	results := make([]Type, 0, len(typeSpecs))
+	for _, spec := range typeSpecs {
+		results = append(results, resultMap[spec])
+	}
+
+	if f.config.deepResolution {
+		resolver := newDeepResolver(f)
+		for i, spec := range typeSpecs {
+			if _, failed := errs[spec]; failed {
+				continue
+			}
+			resolved, err := resolver.resolveDeclaration(spec, results[i])
+			if err != nil {
+				errs[spec] = err
+				results[i] = Type{}
+				continue
+			}
+			results[i] = resolved
+		}
+	}
+
+	for i, spec := range typeSpecs {
+		if _, failed := errs[spec]; failed {
+			continue
+		}
+		typ, err := f.withMethods(spec, results[i])
+		if err != nil {
+			errs[spec] = err
+			results[i] = Type{}
+			continue
+		}
+		results[i] = typ
+		if err := f.checkVisibility(spec, results[i]); err != nil {
+			errs[spec] = err
+			results[i] = Type{}
+			continue
+		}
+		results[i] = f.rewritePackagePaths(results[i])
+	}
+
+	if len(errs) > 0 {
+		return results, &PartialError{Errors: errs}
+	}
+	return results, nil
+}
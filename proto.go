@@ -0,0 +1,373 @@
+package gotype
+
+import (
+	"fmt"
+	"go/ast"
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	timestampPackage = "google.golang.org/protobuf/types/known/timestamppb"
+	durationPackage  = "google.golang.org/protobuf/types/known/durationpb"
+)
+
+// ProtoMismatch describes a field of a struct, or of a protoc-generated message, which can't be converted.
+type ProtoMismatch struct {
+	// Field is the name of the struct's field. It's empty when the message's field doesn't have a counterpart.
+	Field string
+
+	// MessageField is the name of the message's field. It's empty when the struct's field doesn't have a counterpart.
+	MessageField string
+
+	// Reason describes why the field can't be converted.
+	Reason string
+}
+
+func (m ProtoMismatch) String() string {
+	switch {
+	case m.MessageField == "":
+		return "field " + m.Field + ": " + m.Reason
+	case m.Field == "":
+		return "message field " + m.MessageField + ": " + m.Reason
+	}
+	return "field " + m.Field + " and message field " + m.MessageField + ": " + m.Reason
+}
+
+// ProtoMismatchError is returned by RenderProtoConverter when some fields can't be converted.
+type ProtoMismatchError struct {
+	TypeName    string
+	MessageName string
+	Mismatches  []ProtoMismatch
+}
+
+func (e *ProtoMismatchError) Error() string {
+	mismatches := make([]string, 0, len(e.Mismatches))
+	for _, mismatch := range e.Mismatches {
+		mismatches = append(mismatches, mismatch.String())
+	}
+	return fmt.Sprintf(
+		"cannot convert %s to %s: %s",
+		e.TypeName,
+		e.MessageName,
+		strings.Join(mismatches, "; "),
+	)
+}
+
+// ProtoConverterOption configures RenderProtoConverter.
+type ProtoConverterOption func(*protoConverter)
+
+// WithSkippedProtoMismatches makes RenderProtoConverter skip the fields which can't be converted, instead of failing
+// with a ProtoMismatchError. The skipped fields are listed by comments inside the rendered functions.
+func WithSkippedProtoMismatches() ProtoConverterOption {
+	return func(c *protoConverter) {
+		c.skipMismatches = true
+	}
+}
+
+// protoConverter renders the statements converting the fields of a struct from and to a protoc-generated message.
+type protoConverter struct {
+	codeWriter
+	moduleName     string
+	skipMismatches bool
+}
+
+// protoField is a pair of matching fields of a struct and a message.
+type protoField struct {
+	field        TypeField
+	messageField TypeField
+}
+
+// RenderProtoConverter renders the Golang's source code of the `<typeName>ToProto` and `<typeName>FromProto`
+// functions, converting the struct type named `typeName`, whose definition is `typ`, from and to the protoc-generated
+// message type identified by `message`, whose definition is `messageType`.
+//
+// The struct's fields are matched with the message's fields by their proto names, which are read from the `name` of
+// the `protobuf` tags of the message's fields, and from the `proto` tags of the struct's fields, defaulting to the
+// snake case of the fields' names. The fields having the same names are matched as well. The values are converted
+// when:
+//   - their types are identical.
+//   - both are numbers, or both are strings, including the QualTypes defined by them, like the protobuf enums, using
+//     their `Underlying` definitions filled by the deep resolution.
+//   - the struct's field is a `time.Time` or `time.Duration`, and the message's field is a `*timestamppb.Timestamp`
+//     or a `*durationpb.Duration`.
+//   - the struct's field is a struct type, or a pointer to it, and the message's field is a pointer to a message.
+//     The struct is converted by the `<name>ToProto` and `<name>FromProto` functions, which are expected to be
+//     rendered by RenderProtoConverter as well.
+//   - both are slices of convertible elements.
+//
+// The fields without counterparts, and the fields which can't be converted, are mismatches failing the rendering with
+// a ProtoMismatchError, unless the WithSkippedProtoMismatches option is given. The rendered code only contains the
+// functions, the package clause and imports are left to the caller.
+func RenderProtoConverter(
+	typeName string,
+	typ Type,
+	message TypeSpec,
+	messageType Type,
+	moduleName string,
+	opts ...ProtoConverterOption,
+) (string, error) {
+	if typ.StructType == nil {
+		return "", fmt.Errorf("cannot render proto converter of a non-struct type: %s", typ.String(moduleName))
+	}
+	if typ.IsGeneric() {
+		return "", fmt.Errorf("cannot render proto converter of the generic type %s", typeName)
+	}
+	if messageType.StructType == nil {
+		return "", fmt.Errorf("cannot render proto converter of a non-struct message: %s", messageType.String(moduleName))
+	}
+
+	c := &protoConverter{moduleName: moduleName}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	messageName := Type{QualType: &QualType{
+		Package:          message.PackagePath,
+		ShortPackagePath: path.Base(message.PackagePath),
+		Name:             message.Name,
+	}}.String(moduleName)
+
+	fields, mismatches := matchProtoFields(*typ.StructType, *messageType.StructType)
+	// the conversions are checked before rendering, so all the mismatches are reported at once.
+	convertible := make([]protoField, 0, len(fields))
+	for _, pair := range fields {
+		if !c.convertible(pair.field.Type, pair.messageField.Type) {
+			mismatches = append(mismatches, ProtoMismatch{
+				Field:        pair.field.Name,
+				MessageField: pair.messageField.Name,
+				Reason: fmt.Sprintf(
+					"cannot convert %s to %s",
+					pair.field.Type.String(moduleName),
+					pair.messageField.Type.String(moduleName),
+				),
+			})
+			continue
+		}
+		convertible = append(convertible, pair)
+	}
+	if len(mismatches) > 0 && !c.skipMismatches {
+		return "", &ProtoMismatchError{TypeName: typeName, MessageName: messageName, Mismatches: mismatches}
+	}
+
+	c.line(0, "// %sToProto converts a %s into a %s.", typeName, typeName, messageName)
+	c.line(0, "func %sToProto(s *%s) *%s {", typeName, typeName, messageName)
+	c.line(1, "if s == nil {")
+	c.line(2, "return nil")
+	c.line(1, "}")
+	c.line(1, "m := &%s{}", messageName)
+	for _, pair := range convertible {
+		c.toProto(1, "m."+pair.messageField.Name, "s."+pair.field.Name, pair.field.Type, pair.messageField.Type)
+	}
+	c.mismatchComments(mismatches)
+	c.line(1, "return m")
+	c.line(0, "}")
+
+	c.line(0, "")
+	c.line(0, "// %sFromProto converts a %s into a %s.", typeName, messageName, typeName)
+	c.line(0, "func %sFromProto(m *%s) *%s {", typeName, messageName, typeName)
+	c.line(1, "if m == nil {")
+	c.line(2, "return nil")
+	c.line(1, "}")
+	c.line(1, "s := &%s{}", typeName)
+	for _, pair := range convertible {
+		c.fromProto(1, "s."+pair.field.Name, "m."+pair.messageField.Name, pair.field.Type, pair.messageField.Type)
+	}
+	c.mismatchComments(mismatches)
+	c.line(1, "return s")
+	c.line(0, "}")
+
+	return c.b.String(), nil
+}
+
+// matchProtoFields returns the pairs of matching fields of a struct and a message, in the struct's order, and the
+// fields without counterparts.
+func matchProtoFields(structType, messageType StructType) ([]protoField, []ProtoMismatch) {
+	byProtoName := make(map[string]TypeField)
+	byName := make(map[string]TypeField)
+	for _, field := range messageType.Fields {
+		tag, ok := field.Tag.Lookup("protobuf")
+		if !ast.IsExported(field.Name) || !ok {
+			continue
+		}
+		protoName := ""
+		for _, option := range strings.Split(tag, ",") {
+			switch {
+			case strings.HasPrefix(option, "name="):
+				protoName = strings.TrimPrefix(option, "name=")
+			case strings.HasPrefix(option, "enum=") && field.Type.QualType != nil && field.Type.QualType.Underlying == nil:
+				// the enums are defined by int32, which is known without the deep resolution of the message's package.
+				enum := *field.Type.QualType
+				enum.Underlying = &Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindInt32}}
+				field.Type = Type{QualType: &enum}
+			}
+		}
+		byProtoName[protoName] = field
+		byName[field.Name] = field
+	}
+
+	fields := make([]protoField, 0)
+	mismatches := make([]ProtoMismatch, 0)
+	matched := make(map[string]struct{})
+	for _, field := range structType.Fields {
+		if !ast.IsExported(field.Name) {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("proto"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = snakeCase(field.Name)
+		}
+
+		messageField, ok := byProtoName[name]
+		if !ok {
+			messageField, ok = byName[field.Name]
+		}
+		if !ok {
+			mismatches = append(mismatches, ProtoMismatch{Field: field.Name, Reason: "no matching message field"})
+			continue
+		}
+		matched[messageField.Name] = struct{}{}
+		fields = append(fields, protoField{field: field, messageField: messageField})
+	}
+
+	unmatched := make([]string, 0)
+	for name := range byName {
+		if _, ok := matched[name]; !ok {
+			unmatched = append(unmatched, name)
+		}
+	}
+	sort.Strings(unmatched)
+	for _, name := range unmatched {
+		mismatches = append(mismatches, ProtoMismatch{MessageField: name, Reason: "no matching struct field"})
+	}
+	return fields, mismatches
+}
+
+func (c *protoConverter) mismatchComments(mismatches []ProtoMismatch) {
+	for _, mismatch := range mismatches {
+		c.line(1, "// not converted: %s", mismatch)
+	}
+}
+
+// convertible reports whether a value of the struct's field type `from` can be converted to the message's field type
+// `to`, and back.
+func (c *protoConverter) convertible(from, to Type) bool {
+	switch {
+	case Identical(from, to):
+		return true
+	case isTimeType(from):
+		return isProtoWellKnownType(to, timestampPackage, "Timestamp")
+	case isDurationType(from):
+		return isProtoWellKnownType(to, durationPackage, "Duration")
+	case protoMessageName(from) != "":
+		return to.PtrType != nil && to.PtrType.Elem.QualType != nil
+	case from.SliceType != nil && to.SliceType != nil:
+		return c.convertible(from.SliceType.Elem, to.SliceType.Elem)
+	}
+	from, to = validationUnderlying(from), validationUnderlying(to)
+	return (isNumericType(from) && isNumericType(to)) || (isStringType(from) && isStringType(to))
+}
+
+// toProto renders the statements converting the struct's value `expr` of type `from` into the message's `target` of
+// type `to`.
+func (c *protoConverter) toProto(indent int, target, expr string, from, to Type) {
+	switch {
+	case Identical(from, to):
+		c.line(indent, "%s = %s", target, expr)
+	case isTimeType(from):
+		c.line(indent, "%s = timestamppb.New(%s)", target, expr)
+	case isDurationType(from):
+		c.line(indent, "%s = durationpb.New(%s)", target, expr)
+	case protoMessageName(from) != "":
+		if from.PtrType == nil {
+			expr = "&" + expr
+		}
+		c.line(indent, "%s = %sToProto(%s)", target, c.converterName(from), expr)
+	case from.SliceType != nil:
+		c.convertSlice(indent, target, expr, to, func(indent int, target, elem string) {
+			c.toProto(indent, target, elem, from.SliceType.Elem, to.SliceType.Elem)
+		})
+	default:
+		c.line(indent, "%s = %s(%s)", target, to.String(c.moduleName), expr)
+	}
+}
+
+// fromProto renders the statements converting the message's value `expr` of type `to` into the struct's `target`
+// of type `from`.
+func (c *protoConverter) fromProto(indent int, target, expr string, from, to Type) {
+	switch {
+	case Identical(from, to):
+		c.line(indent, "%s = %s", target, expr)
+	case isTimeType(from):
+		c.line(indent, "%s = %s.AsTime()", target, expr)
+	case isDurationType(from):
+		c.line(indent, "%s = %s.AsDuration()", target, expr)
+	case protoMessageName(from) != "":
+		if from.PtrType != nil {
+			c.line(indent, "%s = %sFromProto(%s)", target, c.converterName(from), expr)
+			return
+		}
+		value := c.newVar("v")
+		c.line(indent, "if %s := %sFromProto(%s); %s != nil {", value, c.converterName(from), expr, value)
+		c.line(indent+1, "%s = *%s", target, value)
+		c.line(indent, "}")
+	case from.SliceType != nil:
+		c.convertSlice(indent, target, expr, from, func(indent int, target, elem string) {
+			c.fromProto(indent, target, elem, from.SliceType.Elem, to.SliceType.Elem)
+		})
+	default:
+		c.line(indent, "%s = %s(%s)", target, from.String(c.moduleName), expr)
+	}
+}
+
+// convertSlice renders a loop converting the elements of the slice `expr` into the new slice `target` of type `typ`.
+func (c *protoConverter) convertSlice(
+	indent int,
+	target, expr string,
+	typ Type,
+	convert func(indent int, target, elem string),
+) {
+	index, elem := c.newVar("i"), c.newVar("v")
+	c.line(indent, "if %s != nil {", expr)
+	c.line(indent+1, "%s = make(%s, len(%s))", target, typ.String(c.moduleName), expr)
+	c.line(indent+1, "for %s, %s := range %s {", index, elem, expr)
+	convert(indent+2, target+"["+index+"]", elem)
+	c.line(indent+1, "}")
+	c.line(indent, "}")
+}
+
+// converterName returns the prefix of the converter functions of a struct type, or of a pointer to it.
+func (c *protoConverter) converterName(typ Type) string {
+	if typ.PtrType != nil {
+		typ = typ.PtrType.Elem
+	}
+	qualType := *typ.QualType
+	qualType.Name = protoMessageName(typ)
+	qualType.TypeArgs = nil
+	return Type{QualType: &qualType}.String(c.moduleName)
+}
+
+// protoMessageName returns the name of a struct type, or of a pointer to it, which is converted by its own converter
+// functions. It returns an empty string for the other types.
+func protoMessageName(typ Type) string {
+	if typ.PtrType != nil {
+		typ = typ.PtrType.Elem
+	}
+	if typ.QualType == nil || typ.QualType.Underlying == nil || typ.QualType.Underlying.StructType == nil {
+		return ""
+	}
+	return typ.QualType.Name
+}
+
+func isProtoWellKnownType(typ Type, packagePath, name string) bool {
+	return typ.PtrType != nil && typ.PtrType.Elem.QualType != nil &&
+		typ.PtrType.Elem.QualType.Package == packagePath && typ.PtrType.Elem.QualType.Name == name
+}
+
+func isDurationType(typ Type) bool {
+	return typ.QualType != nil && typ.QualType.Package == "time" && typ.QualType.Name == "Duration"
+}
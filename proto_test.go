@@ -0,0 +1,61 @@
+package gotype
+
+import (
+	"errors"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderProtoConverter(t *testing.T) {
+	message := TypeSpec{PackagePath: testdataPackage + "/proto/pb", Name: "User"}
+	// the message's package imports the protobuf runtime, so it's generated without the deep resolution.
+	messageTypes, err := NewGenerator().GenerateTypesFromSpecs(message)
+	require.NoError(t, err)
+	types, err := NewGenerator(WithDeepResolution()).GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: testdataPackage + "/proto", Name: "User"},
+		TypeSpec{PackagePath: testdataPackage + "/proto", Name: "Broken"},
+	)
+	require.NoError(t, err)
+
+	_, err = RenderProtoConverter("User", types[0], message, messageTypes[0], "proto")
+	assert.EqualError(
+		t,
+		err,
+		"cannot convert User to pb.User: field Nickname: no matching message field; "+
+			"message field Legacy: no matching struct field",
+	)
+
+	code, err := RenderProtoConverter("User", types[0], message, messageTypes[0], "proto", WithSkippedProtoMismatches())
+	require.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), "", "package proto\n"+code, 0)
+	require.NoError(t, err, code)
+
+	assert.Contains(t, code, "func UserToProto(s *User) *pb.User {\n")
+	assert.Contains(t, code, "\tm.Id = int64(s.ID)\n")
+	assert.Contains(t, code, "\tm.FullName = s.Name\n")
+	assert.Contains(t, code, "\tm.Role = pb.Role(s.Role)\n")
+	assert.Contains(t, code, "\tm.Address = AddressToProto(&s.Address)\n")
+	assert.Contains(t, code, "\t\t\tm.Previous[i1] = AddressToProto(v2)\n")
+	assert.Contains(t, code, "\tm.CreatedAt = timestamppb.New(s.Created)\n")
+	assert.Contains(t, code, "\tm.Ttl = durationpb.New(s.TTL)\n")
+	assert.Contains(t, code, "\tm.Tags = s.Tags\n")
+	assert.Contains(t, code, "\t// not converted: field Nickname: no matching message field\n")
+
+	assert.Contains(t, code, "func UserFromProto(m *pb.User) *User {\n")
+	assert.Contains(t, code, "\ts.Role = Role(m.Role)\n")
+	assert.Contains(t, code, "\tif v3 := AddressFromProto(m.Address); v3 != nil {\n\t\ts.Address = *v3\n")
+	assert.Contains(t, code, "\ts.Created = m.CreatedAt.AsTime()\n")
+	assert.Contains(t, code, "\ts.Score = float64(m.Score)\n")
+
+	_, err = RenderProtoConverter("Broken", types[1], message, messageTypes[0], "proto")
+	var mismatchErr *ProtoMismatchError
+	require.True(t, errors.As(err, &mismatchErr))
+	assert.Equal(t, []ProtoMismatch{
+		{Field: "ID", MessageField: "Id", Reason: "cannot convert string to int64"},
+		{Field: "Address", MessageField: "Address", Reason: "cannot convert string to *pb.Address"},
+	}, mismatchErr.Mismatches[len(mismatchErr.Mismatches)-2:])
+}
@@ -0,0 +1,633 @@
+package gotype
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// errTruncatedProto is returned when a serialized descriptor ends in the middle of a field.
+var errTruncatedProto = errors.New("truncated protobuf message")
+
+// The types of the fields of the messages, following `google.protobuf.FieldDescriptorProto.Type`.
+const (
+	protoTypeDouble   = 1
+	protoTypeFloat    = 2
+	protoTypeInt64    = 3
+	protoTypeUint64   = 4
+	protoTypeInt32    = 5
+	protoTypeFixed64  = 6
+	protoTypeFixed32  = 7
+	protoTypeBool     = 8
+	protoTypeString   = 9
+	protoTypeGroup    = 10
+	protoTypeMessage  = 11
+	protoTypeBytes    = 12
+	protoTypeUint32   = 13
+	protoTypeEnum     = 14
+	protoTypeSfixed32 = 15
+	protoTypeSfixed64 = 16
+	protoTypeSint32   = 17
+	protoTypeSint64   = 18
+)
+
+// protoWellKnownPackages contains the Golang's packages of the well known types, whose files are usually left out of
+// the descriptor sets.
+var protoWellKnownPackages = map[string]string{
+	"google/protobuf/any.proto":        "google.golang.org/protobuf/types/known/anypb",
+	"google/protobuf/duration.proto":   durationPackage,
+	"google/protobuf/empty.proto":      "google.golang.org/protobuf/types/known/emptypb",
+	"google/protobuf/field_mask.proto": "google.golang.org/protobuf/types/known/fieldmaskpb",
+	"google/protobuf/struct.proto":     "google.golang.org/protobuf/types/known/structpb",
+	"google/protobuf/timestamp.proto":  timestampPackage,
+	"google/protobuf/wrappers.proto":   "google.golang.org/protobuf/types/known/wrapperspb",
+}
+
+// protoWellKnownTypes maps the full names of the well known types to their files.
+var protoWellKnownTypes = map[string]string{
+	"google.protobuf.Any":         "google/protobuf/any.proto",
+	"google.protobuf.Duration":    "google/protobuf/duration.proto",
+	"google.protobuf.Empty":       "google/protobuf/empty.proto",
+	"google.protobuf.FieldMask":   "google/protobuf/field_mask.proto",
+	"google.protobuf.Struct":      "google/protobuf/struct.proto",
+	"google.protobuf.Value":       "google/protobuf/struct.proto",
+	"google.protobuf.ListValue":   "google/protobuf/struct.proto",
+	"google.protobuf.Timestamp":   "google/protobuf/timestamp.proto",
+	"google.protobuf.DoubleValue": "google/protobuf/wrappers.proto",
+	"google.protobuf.FloatValue":  "google/protobuf/wrappers.proto",
+	"google.protobuf.Int64Value":  "google/protobuf/wrappers.proto",
+	"google.protobuf.UInt64Value": "google/protobuf/wrappers.proto",
+	"google.protobuf.Int32Value":  "google/protobuf/wrappers.proto",
+	"google.protobuf.UInt32Value": "google/protobuf/wrappers.proto",
+	"google.protobuf.BoolValue":   "google/protobuf/wrappers.proto",
+	"google.protobuf.StringValue": "google/protobuf/wrappers.proto",
+	"google.protobuf.BytesValue":  "google/protobuf/wrappers.proto",
+}
+
+// ProtoType is a message or an enum imported from protobuf descriptors.
+type ProtoType struct {
+	// FullName contains the fully qualified name of the message or the enum, like "shop.v1.Order".
+	FullName string
+
+	// Type contains the QualType of the protoc-generated Golang's type, whose Underlying contains its definition.
+	Type Type
+}
+
+// ImportProtoDescriptorSet imports the messages and the enums of a serialized `google.protobuf.FileDescriptorSet`,
+// like the ones written by `protoc --descriptor_set_out` and `buf build`. See ImportProtoFileDescriptors for the
+// details.
+func ImportProtoDescriptorSet(descriptorSet []byte) ([]ProtoType, error) {
+	files := make([][]byte, 0)
+	err := readProtoFields(descriptorSet, func(number int, value protoValue) error {
+		if number == 1 {
+			files = append(files, value.bytes)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode file descriptor set: %w", err)
+	}
+	return ImportProtoFileDescriptors(files...)
+}
+
+// ImportProtoFileDescriptors imports the messages and the enums of the serialized `google.protobuf.FileDescriptorProto`
+// `files`, like the raw descriptors embedded inside the protoc-generated code, into the Type model, so they can be
+// compared with the Golang's structs, see CompareWireFormat, and converted from and to them, see
+// RenderProtoConverter.
+//
+// The messages and the enums are described like the types generated by protoc-gen-go: they're QualTypes of the
+// packages set by the `go_package` options of their files, named after their messages, like `Order_Item` for the
+// nested `Order.Item`. The messages are structs whose fields have the `protobuf` and `json` tags written by
+// protoc-gen-go, and the enums are defined by `int32`. The references to the messages are pointers, and the map fields
+// are maps. The members of the oneofs are flattened into fields having the `protobuf_oneof` tag, instead of the
+// interfaces wrapping them. The Underlying of the references to the messages and the enums are filled, except for the
+// references closing a cycle, and the types of the files missing from `files` are left unresolved. The imported types
+// are returned in the order of their declaration.
+func ImportProtoFileDescriptors(files ...[]byte) ([]ProtoType, error) {
+	i := &protoImporter{declarations: make(map[string]*protoDeclaration)}
+	for n, file := range files {
+		if err := i.readFile(file); err != nil {
+			return nil, fmt.Errorf("cannot decode file descriptor %d: %w", n+1, err)
+		}
+	}
+
+	types := make([]ProtoType, 0, len(i.order))
+	for _, fullName := range i.order {
+		// the map entries are only used by the map fields.
+		if i.declarations[fullName].mapEntry {
+			continue
+		}
+		typ, err := i.resolve(fullName, make(map[string]struct{}))
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, ProtoType{FullName: fullName, Type: typ})
+	}
+	return types, nil
+}
+
+// protoValue is a decoded field of a serialized protobuf message. The varints are stored by number, the
+// length-delimited fields by bytes.
+type protoValue struct {
+	number uint64
+	bytes  []byte
+}
+
+// readProtoFields decodes the fields of a serialized protobuf message, calling `read` for each of them.
+func readProtoFields(data []byte, read func(number int, value protoValue) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errTruncatedProto
+		}
+		data = data[n:]
+
+		var value protoValue
+		switch wireType := key & 7; wireType {
+		case 0:
+			if value.number, n = binary.Uvarint(data); n <= 0 {
+				return errTruncatedProto
+			}
+			data = data[n:]
+		case 1, 5:
+			size := 8
+			if wireType == 5 {
+				size = 4
+			}
+			if len(data) < size {
+				return errTruncatedProto
+			}
+			data = data[size:]
+		case 2:
+			size, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < size {
+				return errTruncatedProto
+			}
+			value.bytes = data[n : n+int(size)]
+			data = data[n+int(size):]
+		default:
+			return fmt.Errorf("unsupported wire type %d", wireType)
+		}
+		if err := read(int(key>>3), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// protoImporter imports the declarations of the file descriptors.
+type protoImporter struct {
+	// declarations contains the messages and the enums, keyed by their full names, and order contains their full
+	// names in the order of their declaration.
+	declarations map[string]*protoDeclaration
+	order        []string
+}
+
+// protoDeclaration is a message or an enum declared by a file descriptor.
+type protoDeclaration struct {
+	qualType QualType
+	enum     bool
+	mapEntry bool
+	proto3   bool
+	fields   []protoFieldDescriptor
+	oneofs   []string
+}
+
+// protoFieldDescriptor is a decoded `google.protobuf.FieldDescriptorProto`.
+type protoFieldDescriptor struct {
+	name           string
+	jsonName       string
+	number         int
+	label          int
+	typ            int
+	typeName       string
+	oneofIndex     int
+	proto3Optional bool
+}
+
+func (i *protoImporter) readFile(data []byte) error {
+	var name, protoPackage, goPackage, syntax string
+	messages, enums := make([][]byte, 0), make([][]byte, 0)
+	err := readProtoFields(data, func(number int, value protoValue) error {
+		switch number {
+		case 1:
+			name = string(value.bytes)
+		case 2:
+			protoPackage = string(value.bytes)
+		case 4:
+			messages = append(messages, value.bytes)
+		case 5:
+			enums = append(enums, value.bytes)
+		case 8:
+			return readProtoFields(value.bytes, func(number int, value protoValue) error {
+				if number == 11 {
+					goPackage = string(value.bytes)
+				}
+				return nil
+			})
+		case 12:
+			syntax = string(value.bytes)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	packagePath, shortPackagePath := protoGoPackage(name, protoPackage, goPackage)
+	scope := &protoScope{
+		fullName:         protoPackage,
+		packagePath:      packagePath,
+		shortPackagePath: shortPackagePath,
+		proto3:           syntax == "proto3",
+	}
+	for _, message := range messages {
+		if err := i.readMessage(scope, message); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	for _, enum := range enums {
+		if err := i.readEnum(scope, enum); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// protoGoPackage returns the Golang's package path and name of the types declared by a file, read from its
+// `go_package` option, like "example.com/shop/v1;shopv1". It defaults to the file's directory.
+func protoGoPackage(filename, protoPackage, goPackage string) (string, string) {
+	if goPackage == "" {
+		goPackage = protoWellKnownPackages[filename]
+	}
+	if goPackage == "" {
+		goPackage = path.Dir(filename)
+		if goPackage == "." {
+			goPackage = strings.ReplaceAll(protoPackage, ".", "/")
+		}
+	}
+	if i := strings.Index(goPackage, ";"); i >= 0 {
+		return goPackage[:i], goPackage[i+1:]
+	}
+	return goPackage, strings.NewReplacer(".", "_", "-", "_").Replace(path.Base(goPackage))
+}
+
+// protoScope is the file or the message declaring the nested messages and enums.
+type protoScope struct {
+	fullName         string
+	goName           string
+	packagePath      string
+	shortPackagePath string
+	proto3           bool
+}
+
+func (s *protoScope) nested(name string) *protoScope {
+	nested := *s
+	nested.fullName = strings.TrimPrefix(s.fullName+"."+name, ".")
+	nested.goName = strings.TrimPrefix(s.goName+"_"+protoGoCamelCase(name), "_")
+	return &nested
+}
+
+func (s *protoScope) declare(i *protoImporter, declaration *protoDeclaration) {
+	declaration.qualType = QualType{Package: s.packagePath, ShortPackagePath: s.shortPackagePath, Name: s.goName}
+	declaration.proto3 = s.proto3
+	i.declarations[s.fullName] = declaration
+	i.order = append(i.order, s.fullName)
+}
+
+func (i *protoImporter) readMessage(parent *protoScope, data []byte) error {
+	declaration := &protoDeclaration{}
+	var name string
+	nestedMessages, nestedEnums := make([][]byte, 0), make([][]byte, 0)
+	err := readProtoFields(data, func(number int, value protoValue) error {
+		switch number {
+		case 1:
+			name = string(value.bytes)
+		case 2:
+			field, err := readProtoField(value.bytes)
+			declaration.fields = append(declaration.fields, field)
+			return err
+		case 3:
+			nestedMessages = append(nestedMessages, value.bytes)
+		case 4:
+			nestedEnums = append(nestedEnums, value.bytes)
+		case 7:
+			return readProtoFields(value.bytes, func(number int, value protoValue) error {
+				if number == 7 {
+					declaration.mapEntry = value.number != 0
+				}
+				return nil
+			})
+		case 8:
+			return readProtoFields(value.bytes, func(number int, value protoValue) error {
+				if number == 1 {
+					declaration.oneofs = append(declaration.oneofs, string(value.bytes))
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	scope := parent.nested(name)
+	scope.declare(i, declaration)
+	for _, message := range nestedMessages {
+		if err := i.readMessage(scope, message); err != nil {
+			return err
+		}
+	}
+	for _, enum := range nestedEnums {
+		if err := i.readEnum(scope, enum); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *protoImporter) readEnum(parent *protoScope, data []byte) error {
+	var name string
+	err := readProtoFields(data, func(number int, value protoValue) error {
+		if number == 1 {
+			name = string(value.bytes)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	parent.nested(name).declare(i, &protoDeclaration{enum: true})
+	return nil
+}
+
+func readProtoField(data []byte) (protoFieldDescriptor, error) {
+	field := protoFieldDescriptor{oneofIndex: -1}
+	err := readProtoFields(data, func(number int, value protoValue) error {
+		switch number {
+		case 1:
+			field.name = string(value.bytes)
+		case 3:
+			field.number = int(value.number)
+		case 4:
+			field.label = int(value.number)
+		case 5:
+			field.typ = int(value.number)
+		case 6:
+			field.typeName = strings.TrimPrefix(string(value.bytes), ".")
+		case 9:
+			field.oneofIndex = int(value.number)
+		case 10:
+			field.jsonName = string(value.bytes)
+		case 17:
+			field.proto3Optional = value.number != 0
+		}
+		return nil
+	})
+	return field, err
+}
+
+// resolve returns the QualType of the declaration named `fullName`, whose Underlying contains its definition unless
+// it's `resolving` already.
+func (i *protoImporter) resolve(fullName string, resolving map[string]struct{}) (Type, error) {
+	declaration, ok := i.declarations[fullName]
+	if !ok {
+		return Type{}, fmt.Errorf("cannot find protobuf type %s", fullName)
+	}
+	qualType := declaration.qualType
+	if _, ok := resolving[fullName]; ok {
+		return Type{QualType: &qualType}, nil
+	}
+	resolving[fullName] = struct{}{}
+	defer delete(resolving, fullName)
+
+	var definition Type
+	if declaration.enum {
+		definition = Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindInt32}}
+	} else {
+		structType := &StructType{Fields: make([]TypeField, 0, len(declaration.fields))}
+		for _, field := range declaration.fields {
+			typ, err := i.fieldType(declaration, field, resolving)
+			if err != nil {
+				return Type{}, fmt.Errorf("%s.%s: %w", fullName, field.name, err)
+			}
+			structType.Fields = append(structType.Fields, TypeField{
+				Name: protoGoCamelCase(field.name),
+				Type: typ,
+				Tag:  protoFieldTag(declaration, field),
+			})
+		}
+		definition = Type{StructType: structType}
+	}
+	qualType.Underlying = &definition
+	return Type{QualType: &qualType}, nil
+}
+
+// fieldType returns the type of the field of a message generated by protoc-gen-go.
+func (i *protoImporter) fieldType(
+	message *protoDeclaration,
+	field protoFieldDescriptor,
+	resolving map[string]struct{},
+) (Type, error) {
+	var typ Type
+	switch field.typ {
+	case protoTypeMessage, protoTypeGroup, protoTypeEnum:
+		declaration, ok := i.declarations[field.typeName]
+		if !ok {
+			typ = Type{QualType: i.unresolvedQualType(field.typeName)}
+			break
+		}
+		if declaration.mapEntry && len(declaration.fields) == 2 && field.label == protoLabelRepeated {
+			key, err := i.fieldType(declaration, declaration.fields[0], resolving)
+			if err != nil {
+				return Type{}, err
+			}
+			value, err := i.fieldType(declaration, declaration.fields[1], resolving)
+			if err != nil {
+				return Type{}, err
+			}
+			return Type{MapType: &MapType{Key: key, Elem: value}}, nil
+		}
+		var err error
+		if typ, err = i.resolve(field.typeName, resolving); err != nil {
+			return Type{}, err
+		}
+	case protoTypeBytes:
+		typ = Type{SliceType: &SliceType{Elem: Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindByte}}}}
+	default:
+		kind, ok := protoScalarKinds[field.typ]
+		if !ok {
+			return Type{}, fmt.Errorf("unknown field type %d", field.typ)
+		}
+		typ = Type{PrimitiveType: &PrimitiveType{Kind: kind}}
+	}
+
+	switch {
+	case field.label == protoLabelRepeated:
+		if field.typ == protoTypeMessage || field.typ == protoTypeGroup {
+			typ = Type{PtrType: &PtrType{Elem: typ}}
+		}
+		return Type{SliceType: &SliceType{Elem: typ}}, nil
+	case field.typ == protoTypeMessage || field.typ == protoTypeGroup:
+		return Type{PtrType: &PtrType{Elem: typ}}, nil
+	case field.typ != protoTypeBytes && protoFieldHasPresence(message, field):
+		return Type{PtrType: &PtrType{Elem: typ}}, nil
+	}
+	return typ, nil
+}
+
+// unresolvedQualType returns the QualType of a type declared by a file missing from the imported files. Its package
+// is only known for the well known types.
+func (i *protoImporter) unresolvedQualType(fullName string) *QualType {
+	packagePath, shortPackagePath := protoGoPackage(protoWellKnownTypes[fullName], "", "")
+	name := fullName[strings.LastIndex(fullName, ".")+1:]
+	if protoWellKnownTypes[fullName] == "" {
+		packagePath = strings.ReplaceAll(strings.TrimSuffix(fullName, "."+name), ".", "/")
+		shortPackagePath = path.Base(packagePath)
+	}
+	return &QualType{Package: packagePath, ShortPackagePath: shortPackagePath, Name: protoGoCamelCase(name)}
+}
+
+// The labels of the fields, following `google.protobuf.FieldDescriptorProto.Label`.
+const (
+	protoLabelOptional = 1
+	protoLabelRequired = 2
+	protoLabelRepeated = 3
+)
+
+// protoScalarKinds contains the Golang's kinds of the protobuf scalar types.
+var protoScalarKinds = map[int]PrimitiveKind{
+	protoTypeDouble:   PrimitiveKindFloat64,
+	protoTypeFloat:    PrimitiveKindFloat32,
+	protoTypeInt64:    PrimitiveKindInt64,
+	protoTypeUint64:   PrimitiveKindUint64,
+	protoTypeInt32:    PrimitiveKindInt32,
+	protoTypeFixed64:  PrimitiveKindUint64,
+	protoTypeFixed32:  PrimitiveKindUint32,
+	protoTypeBool:     PrimitiveKindBool,
+	protoTypeString:   PrimitiveKindString,
+	protoTypeUint32:   PrimitiveKindUint32,
+	protoTypeSfixed32: PrimitiveKindInt32,
+	protoTypeSfixed64: PrimitiveKindInt64,
+	protoTypeSint32:   PrimitiveKindInt32,
+	protoTypeSint64:   PrimitiveKindInt64,
+}
+
+// protoEncodings contains the encodings written into the `protobuf` tags, keyed by the field types.
+var protoEncodings = map[int]string{
+	protoTypeDouble:   "fixed64",
+	protoTypeFloat:    "fixed32",
+	protoTypeInt64:    "varint",
+	protoTypeUint64:   "varint",
+	protoTypeInt32:    "varint",
+	protoTypeFixed64:  "fixed64",
+	protoTypeFixed32:  "fixed32",
+	protoTypeBool:     "varint",
+	protoTypeString:   "bytes",
+	protoTypeGroup:    "group",
+	protoTypeMessage:  "bytes",
+	protoTypeBytes:    "bytes",
+	protoTypeUint32:   "varint",
+	protoTypeEnum:     "varint",
+	protoTypeSfixed32: "fixed32",
+	protoTypeSfixed64: "fixed64",
+	protoTypeSint32:   "zigzag32",
+	protoTypeSint64:   "zigzag64",
+}
+
+// protoFieldHasPresence reports whether the scalar field tracks its presence, so protoc-gen-go generates a pointer.
+func protoFieldHasPresence(message *protoDeclaration, field protoFieldDescriptor) bool {
+	if field.oneofIndex >= 0 || field.proto3Optional {
+		return true
+	}
+	return !message.proto3 && field.label != protoLabelRepeated
+}
+
+// protoFieldTag returns the tag of the field, like protoc-gen-go writes it.
+func protoFieldTag(message *protoDeclaration, field protoFieldDescriptor) reflect.StructTag {
+	cardinality := "opt"
+	switch field.label {
+	case protoLabelRequired:
+		cardinality = "req"
+	case protoLabelRepeated:
+		cardinality = "rep"
+	}
+	tag := []string{protoEncodings[field.typ], strconv.Itoa(field.number), cardinality, "name=" + field.name}
+	jsonName := field.jsonName
+	if jsonName == "" {
+		jsonName = protoJSONName(field.name)
+	}
+	if jsonName != field.name {
+		tag = append(tag, "json="+jsonName)
+	}
+	if message.proto3 {
+		tag = append(tag, "proto3")
+	}
+	if field.typ == protoTypeEnum {
+		tag = append(tag, "enum="+field.typeName)
+	}
+	if field.oneofIndex >= 0 && field.oneofIndex < len(message.oneofs) && !field.proto3Optional {
+		tag = append(tag, "oneof")
+		return reflect.StructTag(fmt.Sprintf(`protobuf:"%s" protobuf_oneof:"%s"`, strings.Join(tag, ","),
+			message.oneofs[field.oneofIndex]))
+	}
+	return reflect.StructTag(fmt.Sprintf(`protobuf:"%s" json:"%s,omitempty"`, strings.Join(tag, ","), field.name))
+}
+
+// protoJSONName returns the default JSON name of a field, like protoc computes it: the underscores are removed, and the
+// letters following them are capitalized.
+func protoJSONName(name string) string {
+	var b strings.Builder
+	upper := false
+	for _, r := range name {
+		switch {
+		case r == '_':
+			upper = true
+		case upper && 'a' <= r && r <= 'z':
+			b.WriteRune(r - 'a' + 'A')
+			upper = false
+		default:
+			b.WriteRune(r)
+			upper = false
+		}
+	}
+	return b.String()
+}
+
+// protoGoCamelCase returns the Golang's name of a protobuf identifier, like protoc-gen-go computes it, e.g.
+// `FullName` for "full_name".
+func protoGoCamelCase(name string) string {
+	isLower := func(c byte) bool { return 'a' <= c && c <= 'z' }
+	isDigit := func(c byte) bool { return '0' <= c && c <= '9' }
+
+	b := make([]byte, 0, len(name))
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == '.' && i+1 < len(name) && isLower(name[i+1]):
+			// the dot is skipped, and the following letter is capitalized.
+		case c == '.':
+			b = append(b, '_')
+		case c == '_' && (i == 0 || name[i-1] == '.'):
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(name) && isLower(name[i+1]):
+			// the underscore is skipped, and the following letter is capitalized.
+		case isDigit(c):
+			b = append(b, c)
+		default:
+			if isLower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+			for ; i+1 < len(name) && isLower(name[i+1]); i++ {
+				b = append(b, name[i+1])
+			}
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,166 @@
+package gotype
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func protoVarintField(number int, value uint64) []byte {
+	data := binary.AppendUvarint(nil, uint64(number)<<3)
+	return binary.AppendUvarint(data, value)
+}
+
+func protoBytesField(number int, value []byte) []byte {
+	data := binary.AppendUvarint(nil, uint64(number)<<3|2)
+	data = binary.AppendUvarint(data, uint64(len(value)))
+	return append(data, value...)
+}
+
+func protoStringField(number int, value string) []byte {
+	return protoBytesField(number, []byte(value))
+}
+
+func protoMessage(fields ...[]byte) []byte {
+	message := make([]byte, 0)
+	for _, field := range fields {
+		message = append(message, field...)
+	}
+	return message
+}
+
+// protoFieldDescriptorBytes encodes a FieldDescriptorProto.
+func protoFieldDescriptorBytes(name string, number, label, typ int, typeName string, extra ...[]byte) []byte {
+	fields := [][]byte{
+		protoStringField(1, name),
+		protoVarintField(3, uint64(number)),
+		protoVarintField(4, uint64(label)),
+		protoVarintField(5, uint64(typ)),
+	}
+	if typeName != "" {
+		fields = append(fields, protoStringField(6, typeName))
+	}
+	return protoMessage(append(fields, extra...)...)
+}
+
+func TestImportProtoDescriptorSet(t *testing.T) {
+	order := protoMessage(
+		protoStringField(1, "Order"),
+		protoBytesField(2, protoFieldDescriptorBytes("id", 1, 1, protoTypeInt64, "")),
+		protoBytesField(2, protoFieldDescriptorBytes("full_name", 2, 1, protoTypeString, "")),
+		protoBytesField(2, protoFieldDescriptorBytes("items", 3, 3, protoTypeMessage, ".shop.v1.Order.Item")),
+		protoBytesField(2, protoFieldDescriptorBytes("labels", 4, 3, protoTypeMessage, ".shop.v1.Order.LabelsEntry")),
+		protoBytesField(2, protoFieldDescriptorBytes("status", 5, 1, protoTypeEnum, ".shop.v1.Status")),
+		protoBytesField(2, protoFieldDescriptorBytes("created", 6, 1, protoTypeMessage, ".google.protobuf.Timestamp")),
+		protoBytesField(2, protoFieldDescriptorBytes("priority", 7, 1, protoTypeSint32, "",
+			protoVarintField(9, 0), protoVarintField(17, 1))),
+		protoBytesField(2, protoFieldDescriptorBytes("card", 8, 1, protoTypeString, "", protoVarintField(9, 1))),
+		protoBytesField(2, protoFieldDescriptorBytes("parent", 9, 1, protoTypeMessage, ".shop.v1.Order")),
+		protoBytesField(3, protoMessage(
+			protoStringField(1, "Item"),
+			protoBytesField(2, protoFieldDescriptorBytes("sku", 1, 1, protoTypeString, "")),
+		)),
+		protoBytesField(3, protoMessage(
+			protoStringField(1, "LabelsEntry"),
+			protoBytesField(2, protoFieldDescriptorBytes("key", 1, 1, protoTypeString, "")),
+			protoBytesField(2, protoFieldDescriptorBytes("value", 2, 1, protoTypeInt32, "")),
+			protoBytesField(7, protoVarintField(7, 1)),
+		)),
+		protoBytesField(8, protoStringField(1, "_priority")),
+		protoBytesField(8, protoStringField(1, "payment")),
+	)
+	file := protoMessage(
+		protoStringField(1, "shop/v1/shop.proto"),
+		protoStringField(2, "shop.v1"),
+		protoStringField(3, "google/protobuf/timestamp.proto"),
+		protoBytesField(4, order),
+		protoBytesField(5, protoMessage(protoStringField(1, "Status"))),
+		protoBytesField(8, protoStringField(11, "example.com/shop/v1;shopv1")),
+		protoStringField(12, "proto3"),
+	)
+
+	types, err := ImportProtoDescriptorSet(protoBytesField(1, file))
+	require.NoError(t, err)
+	require.Len(t, types, 3)
+	assert.Equal(t, "shop.v1.Order", types[0].FullName)
+	assert.Equal(t, "shop.v1.Order.Item", types[1].FullName)
+	assert.Equal(t, "shop.v1.Status", types[2].FullName)
+
+	orderType := types[0].Type.QualType
+	assert.Equal(t, "example.com/shop/v1", orderType.Package)
+	assert.Equal(t, "shopv1", orderType.ShortPackagePath)
+	assert.Equal(t, "Order", orderType.Name)
+	assert.Equal(t, "Order_Item", types[1].Type.QualType.Name)
+	assert.Equal(t, Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindInt32}}, *types[2].Type.QualType.Underlying)
+
+	fields := orderType.Underlying.StructType.Fields
+	fieldStrings := make([]string, 0, len(fields))
+	for _, field := range fields {
+		fieldStrings = append(fieldStrings, field.Name+" "+field.Type.String("shopv1")+" `"+string(field.Tag)+"`")
+	}
+	assert.Equal(t, []string{
+		"Id int64 `protobuf:\"varint,1,opt,name=id,proto3\" json:\"id,omitempty\"`",
+		"FullName string `protobuf:\"bytes,2,opt,name=full_name,json=fullName,proto3\" json:\"full_name,omitempty\"`",
+		"Items []*Order_Item `protobuf:\"bytes,3,rep,name=items,proto3\" json:\"items,omitempty\"`",
+		"Labels map[string]int32 `protobuf:\"bytes,4,rep,name=labels,proto3\" json:\"labels,omitempty\"`",
+		"Status Status `protobuf:\"varint,5,opt,name=status,proto3,enum=shop.v1.Status\" json:\"status,omitempty\"`",
+		"Created *timestamppb.Timestamp `protobuf:\"bytes,6,opt,name=created,proto3\" json:\"created,omitempty\"`",
+		"Priority *int32 `protobuf:\"zigzag32,7,opt,name=priority,proto3\" json:\"priority,omitempty\"`",
+		"Card *string `protobuf:\"bytes,8,opt,name=card,proto3,oneof\" protobuf_oneof:\"payment\"`",
+		"Parent *Order `protobuf:\"bytes,9,opt,name=parent,proto3\" json:\"parent,omitempty\"`",
+	}, fieldStrings)
+
+	assert.NotNil(t, fields[2].Type.SliceType.Elem.PtrType.Elem.QualType.Underlying)
+	assert.Nil(t, fields[5].Type.PtrType.Elem.QualType.Underlying)
+	assert.Equal(t, timestampPackage, fields[5].Type.PtrType.Elem.QualType.Package)
+	// the reference closing the cycle isn't resolved.
+	assert.Nil(t, fields[8].Type.PtrType.Elem.QualType.Underlying)
+}
+
+func TestImportProtoFileDescriptors_CompareWireFormat(t *testing.T) {
+	// the descriptor of the message declared by testdata/wire/v1.
+	file := protoMessage(
+		protoStringField(1, "wire.proto"),
+		protoStringField(2, "wire"),
+		protoBytesField(4, protoMessage(
+			protoStringField(1, "UserMessage"),
+			protoBytesField(2, protoFieldDescriptorBytes("id", 1, 1, protoTypeInt32, "")),
+			protoBytesField(2, protoFieldDescriptorBytes("name", 2, 1, protoTypeString, "")),
+			protoBytesField(2, protoFieldDescriptorBytes("email", 3, 1, protoTypeString, "")),
+			protoBytesField(2, protoFieldDescriptorBytes("tags", 4, 3, protoTypeString, "")),
+			protoBytesField(2, protoFieldDescriptorBytes("score", 5, 1, protoTypeSint32, "")),
+			protoBytesField(2, protoFieldDescriptorBytes("avatar", 6, 1, protoTypeString, "")),
+			protoBytesField(2, protoFieldDescriptorBytes("parent", 7, 1, protoTypeMessage, ".wire.UserMessage")),
+		)),
+		protoStringField(12, "proto3"),
+	)
+	types, err := ImportProtoFileDescriptors(file)
+	require.NoError(t, err)
+	require.Len(t, types, 1)
+	assert.Equal(t, "wire", types[0].Type.QualType.Package)
+
+	goTypes, err := NewGenerator(WithDeepResolution()).GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: testdataPackage + "/wire/v1", Name: "UserMessage"},
+	)
+	require.NoError(t, err)
+	report, err := CompareWireFormat(types[0].Type, goTypes[0], WireFormatProtobuf)
+	require.NoError(t, err)
+	assert.Empty(t, report.Changes)
+}
+
+func TestImportProtoFileDescriptors_Errors(t *testing.T) {
+	_, err := ImportProtoDescriptorSet([]byte{0x0a, 0x05, 0x01})
+	assert.EqualError(t, err, "cannot decode file descriptor set: truncated protobuf message")
+
+	file := protoMessage(
+		protoStringField(1, "broken.proto"),
+		protoBytesField(4, protoMessage(
+			protoStringField(1, "Broken"),
+			protoBytesField(2, protoFieldDescriptorBytes("value", 1, 1, 42, "")),
+		)),
+	)
+	_, err = ImportProtoFileDescriptors(file)
+	assert.EqualError(t, err, "Broken.value: unknown field type 42")
+}
@@ -0,0 +1,585 @@
+package gotype
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ProviderStyle represents the kind of source code rendered by RenderProviders.
+type ProviderStyle int
+
+const (
+	// ProviderStyleWire renders a google/wire provider set, like `var ProviderSet = wire.NewSet(NewRepo, NewService)`.
+	ProviderStyleWire ProviderStyle = iota
+
+	// ProviderStyleFx renders an uber-go/fx option providing the constructors, like
+	// `var Module = fx.Provide(NewRepo, NewService)`.
+	ProviderStyleFx
+
+	// ProviderStyleContainer renders a hand-rolled container, a struct holding the provided values, and its
+	// constructor calling the constructors in the order of their dependencies.
+	ProviderStyleContainer
+)
+
+// Provider is a constructor function, like `func NewService(repo Repository) (*Service, error)`, providing a value to
+// the constructors depending on its type.
+type Provider struct {
+	// Func contains the constructor function, identified by its package and name.
+	Func QualType
+
+	// Provides contains the type of the value returned by the constructor.
+	Provides Type
+
+	// Dependencies contains the types of the constructor's inputs. The variadic input isn't a dependency, the
+	// constructor is called without it.
+	Dependencies []Type
+
+	// ReturnsError reports whether the constructor returns an error as well.
+	ReturnsError bool
+}
+
+// ProviderBinding binds an interface to the provided type implementing it, so the value of the type is provided to
+// the constructors depending on the interface.
+type ProviderBinding struct {
+	Interface      Type
+	Implementation Type
+}
+
+// ProviderGraph contains the constructors found by `TypeGenerator.FindProviders`.
+type ProviderGraph struct {
+	// Providers contains the constructors, each of them placed after the constructors of its dependencies.
+	Providers []Provider
+
+	// Bindings contains the interfaces bound to the provided types implementing them.
+	Bindings []ProviderBinding
+
+	// Missing contains the dependencies which aren't provided by any constructor, so they have to be supplied by the
+	// caller, in the order they're found.
+	Missing []Type
+}
+
+// FindProviders finds the constructors declared inside the packages and orders them by their dependencies. See
+// `TypeGenerator.FindProviders` for the details.
+func FindProviders(packagePatterns ...string) (ProviderGraph, error) {
+	return defaultAstTypeGenerator.FindProviders(packagePatterns...)
+}
+
+func (f *astTypeGenerator) FindProviders(packagePatterns ...string) (ProviderGraph, error) {
+	f = f.fork()
+	packages, err := f.expandPackagePatterns(packagePatterns...)
+	if err != nil {
+		return ProviderGraph{}, err
+	}
+
+	providers := make([]Provider, 0)
+	for _, packagePath := range packages {
+		specs, err := f.getConstructorSpecs(packagePath)
+		if err != nil {
+			return ProviderGraph{}, err
+		}
+		if len(specs) == 0 {
+			continue
+		}
+		declarations, err := f.generateDeclarationsInSinglePackage(specs)
+		if err != nil {
+			return ProviderGraph{}, err
+		}
+		for _, spec := range specs {
+			if provider, ok := newProvider(declarations[spec]); ok {
+				provider.Func.ShortPackagePath = f.getImportNameFromPackagePath(packagePath)
+				providers = append(providers, provider)
+			}
+		}
+	}
+
+	graph, err := f.orderProviders(providers, packages)
+	if err != nil {
+		return ProviderGraph{}, err
+	}
+
+	for i, provider := range graph.Providers {
+		provider.Func = f.rewriteQualType(provider.Func)
+		provider.Provides = f.rewritePackagePaths(provider.Provides)
+		provider.Dependencies = mapTypes(provider.Dependencies, func(t Type) (Type, bool) {
+			return f.rewritePackagePaths(t), true
+		})
+		graph.Providers[i] = provider
+	}
+	for i, binding := range graph.Bindings {
+		graph.Bindings[i] = ProviderBinding{
+			Interface:      f.rewritePackagePaths(binding.Interface),
+			Implementation: f.rewritePackagePaths(binding.Implementation),
+		}
+	}
+	graph.Missing = mapTypes(graph.Missing, func(t Type) (Type, bool) { return f.rewritePackagePaths(t), true })
+	return graph, nil
+}
+
+// getConstructorSpecs returns the Specs of the exported non-generic functions whose names start with "New", like
+// `New` and `NewService`, declared inside the package.
+func (f *astTypeGenerator) getConstructorSpecs(packagePath string) ([]Spec, error) {
+	goSources, err := f.getPackageSourceFiles(packagePath)
+	if err != nil {
+		return nil, err
+	}
+
+	specs := make([]Spec, 0)
+	for _, source := range goSources {
+		if strings.HasSuffix(source, "_test.go") {
+			continue
+		}
+		fileAst, err := f.parseAstFile(source)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, decl := range fileAst.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || funcDecl.Recv != nil || funcDecl.Type.TypeParams != nil {
+				continue
+			}
+			if isConstructorName(funcDecl.Name.Name) {
+				specs = append(specs, Spec{PackagePath: packagePath, Name: funcDecl.Name.Name, Kind: SpecKindFunc})
+			}
+		}
+	}
+	return specs, nil
+}
+
+// isConstructorName reports whether the function's name is `New`, or starts with "New" followed by a word, like
+// `NewService`, but not like `Newsletter`.
+func isConstructorName(name string) bool {
+	if !strings.HasPrefix(name, "New") {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(name[len("New"):])
+	return len(name) == len("New") || !unicode.IsLower(r)
+}
+
+// newProvider returns the Provider of a constructor returning a value, or a value and an error. It returns false for
+// the other functions.
+func newProvider(declaration Declaration) (Provider, bool) {
+	funcType := declaration.Type.FuncType
+	if funcType == nil || len(funcType.Outputs) == 0 || len(funcType.Outputs) > 2 {
+		return Provider{}, false
+	}
+	returnsError := len(funcType.Outputs) == 2
+	if returnsError {
+		last := funcType.Outputs[1].Type
+		if last.PrimitiveType == nil || last.PrimitiveType.Kind != PrimitiveKindError {
+			return Provider{}, false
+		}
+	}
+
+	inputs := funcType.Inputs
+	if funcType.IsVariadic {
+		inputs = inputs[:len(inputs)-1]
+	}
+	dependencies := make([]Type, 0, len(inputs))
+	for _, input := range inputs {
+		dependencies = append(dependencies, input.Type)
+	}
+	return Provider{
+		Func:         QualType{Package: declaration.Spec.PackagePath, Name: declaration.Spec.Name},
+		Provides:     funcType.Outputs[0].Type,
+		Dependencies: dependencies,
+		ReturnsError: returnsError,
+	}, true
+}
+
+// orderProviders orders the providers by their dependencies, keeping the order of their declarations when they're
+// independent. The interfaces declared inside the `packages` are bound to the provided types implementing them.
+func (f *astTypeGenerator) orderProviders(providers []Provider, packages []string) (ProviderGraph, error) {
+	for i, provider := range providers {
+		for _, previous := range providers[:i] {
+			if Identical(provider.Provides, previous.Provides) {
+				return ProviderGraph{}, fmt.Errorf(
+					"type %s is provided by both %s and %s",
+					provider.Provides.String(""),
+					previous.Func.Type().String(""),
+					provider.Func.Type().String(""),
+				)
+			}
+		}
+	}
+
+	graph := ProviderGraph{
+		Providers: make([]Provider, 0, len(providers)),
+		Bindings:  make([]ProviderBinding, 0),
+		Missing:   make([]Type, 0),
+	}
+	analyzed := make(map[string]struct{}, len(packages))
+	for _, packagePath := range packages {
+		analyzed[packagePath] = struct{}{}
+	}
+
+	// resolve returns the index of the provider of a dependency, or -1 when it's missing.
+	resolve := func(dependency Type) (int, error) {
+		for i, provider := range providers {
+			if Identical(dependency, provider.Provides) {
+				return i, nil
+			}
+		}
+		for _, binding := range graph.Bindings {
+			if Identical(dependency, binding.Interface) {
+				return providerIndex(providers, binding.Implementation), nil
+			}
+		}
+		for _, missing := range graph.Missing {
+			if Identical(dependency, missing) {
+				return -1, nil
+			}
+		}
+
+		index, err := f.implementingProvider(dependency, providers, analyzed)
+		if err != nil {
+			return 0, err
+		}
+		if index < 0 {
+			graph.Missing = append(graph.Missing, dependency)
+			return -1, nil
+		}
+		graph.Bindings = append(graph.Bindings, ProviderBinding{
+			Interface:      dependency,
+			Implementation: providers[index].Provides,
+		})
+		return index, nil
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := make([]int, len(providers))
+	stack := make([]string, 0)
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch states[i] {
+		case visited:
+			return nil
+		case visiting:
+			cycle := append(stack, providers[i].Func.Type().String(""))
+			for len(cycle) > 0 && cycle[0] != cycle[len(cycle)-1] {
+				cycle = cycle[1:]
+			}
+			return fmt.Errorf("dependency cycle: %s", strings.Join(cycle, " -> "))
+		}
+
+		states[i] = visiting
+		stack = append(stack, providers[i].Func.Type().String(""))
+		for _, dependency := range providers[i].Dependencies {
+			index, err := resolve(dependency)
+			if err != nil {
+				return err
+			}
+			if index >= 0 {
+				if err := visit(index); err != nil {
+					return err
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		states[i] = visited
+		graph.Providers = append(graph.Providers, providers[i])
+		return nil
+	}
+	for i := range providers {
+		if err := visit(i); err != nil {
+			return ProviderGraph{}, err
+		}
+	}
+	return graph, nil
+}
+
+// providerIndex returns the index of the provider of the type `provides`, or -1 when it isn't provided.
+func providerIndex(providers []Provider, provides Type) int {
+	for i, provider := range providers {
+		if Identical(provider.Provides, provides) {
+			return i
+		}
+	}
+	return -1
+}
+
+// implementingProvider returns the index of the only provider whose provided type implements the interface
+// `dependency`, declared inside one of the `analyzed` packages. It returns -1 when the dependency isn't such an
+// interface, or when it isn't implemented by any provided type.
+func (f *astTypeGenerator) implementingProvider(
+	dependency Type,
+	providers []Provider,
+	analyzed map[string]struct{},
+) (int, error) {
+	qualType := dependency.QualType
+	if qualType == nil || len(qualType.TypeArgs) > 0 {
+		return -1, nil
+	}
+	if _, ok := analyzed[qualType.Package]; !ok {
+		return -1, nil
+	}
+	types, err := f.generateTypesFromSpecs([]TypeSpec{{PackagePath: qualType.Package, Name: qualType.Name}})
+	if err != nil {
+		return 0, err
+	}
+	iface := types[0].InterfaceType
+	if iface == nil || len(iface.Unions) > 0 {
+		return -1, nil
+	}
+
+	implementations := make([]int, 0)
+	for i, provider := range providers {
+		provided, pointer := provider.Provides, false
+		if provided.PtrType != nil {
+			provided, pointer = provided.PtrType.Elem, true
+		}
+		if provided.QualType == nil || len(provided.QualType.TypeArgs) > 0 {
+			continue
+		}
+		methods, err := f.generateMethods(provided.QualType.Package, provided.QualType.Name)
+		if err != nil {
+			return 0, err
+		}
+		if Implements(methodSet(methods, pointer), *iface) {
+			implementations = append(implementations, i)
+		}
+	}
+
+	switch len(implementations) {
+	case 0:
+		return -1, nil
+	case 1:
+		return implementations[0], nil
+	}
+	names := make([]string, 0, len(implementations))
+	for _, i := range implementations {
+		names = append(names, providers[i].Provides.String(""))
+	}
+	return 0, fmt.Errorf(
+		"interface %s is implemented by several provided types: %s",
+		dependency.String(""),
+		strings.Join(names, ", "),
+	)
+}
+
+// RenderProviders renders the Golang's source file of the package `packageName`, whose path is `packagePath`, wiring
+// the constructors of the `graph` in the `style`. The wire provider set and the fx option are declared as the
+// variable `name`. The container is the struct `name`, having a field for each provided value, built by the
+// `New<name>` function whose inputs are the missing dependencies. The types declared inside the package itself are
+// referred to without their package names, and the imported packages sharing the same name are aliased.
+func RenderProviders(
+	packagePath string,
+	packageName string,
+	name string,
+	graph ProviderGraph,
+	style ProviderStyle,
+) (string, error) {
+	imports := []Import{{Name: packageName, Package: packagePath}}
+	switch style {
+	case ProviderStyleWire:
+		imports = append(imports, Import{Name: "wire", Package: "github.com/google/wire"})
+	case ProviderStyleFx:
+		imports = append(imports, Import{Name: "fx", Package: "go.uber.org/fx"})
+	case ProviderStyleContainer:
+		for _, provider := range graph.Providers {
+			if provider.ReturnsError {
+				imports = append(imports, Import{Name: "fmt", Package: "fmt"})
+				break
+			}
+		}
+	default:
+		return "", fmt.Errorf("unknown provider style %d", style)
+	}
+	for _, provider := range graph.Providers {
+		imports = append(imports, provider.Func.Type().Imports()...)
+		if style == ProviderStyleContainer {
+			imports = append(imports, provider.Provides.Imports()...)
+		}
+	}
+	if style == ProviderStyleContainer {
+		for _, missing := range graph.Missing {
+			imports = append(imports, missing.Imports()...)
+		}
+	} else {
+		for _, binding := range graph.Bindings {
+			imports = append(imports, binding.Interface.Imports()...)
+			imports = append(imports, binding.Implementation.Imports()...)
+		}
+	}
+	for i, imp := range imports {
+		if imp.Name == "" {
+			imports[i].Name = path.Base(imp.Package)
+		}
+	}
+	imports = AliasImports(imports)
+	typeString := func(typ Type) string {
+		return typ.WithImports(imports).String(packageName)
+	}
+
+	w := &codeWriter{}
+	w.line(0, "package %s", packageName)
+	if len(imports) > 1 {
+		w.line(0, "")
+		w.line(0, "import (")
+		for _, imp := range imports[1:] {
+			if imp.Name == path.Base(imp.Package) {
+				w.line(1, "%s", strconv.Quote(imp.Package))
+			} else {
+				w.line(1, "%s %s", imp.Name, strconv.Quote(imp.Package))
+			}
+		}
+		w.line(0, ")")
+	}
+	w.line(0, "")
+
+	switch style {
+	case ProviderStyleWire:
+		w.line(0, "var %s = wire.NewSet(", name)
+		for _, provider := range graph.Providers {
+			w.line(1, "%s,", typeString(provider.Func.Type()))
+		}
+		for _, binding := range graph.Bindings {
+			w.line(1, "wire.Bind(new(%s), new(%s)),", typeString(binding.Interface), typeString(binding.Implementation))
+		}
+		w.line(0, ")")
+	case ProviderStyleFx:
+		w.line(0, "var %s = fx.Provide(", name)
+		for _, provider := range graph.Providers {
+			w.line(1, "%s,", typeString(provider.Func.Type()))
+		}
+		for _, binding := range graph.Bindings {
+			w.line(
+				1,
+				"func(v %s) %s { return v },",
+				typeString(binding.Implementation),
+				typeString(binding.Interface),
+			)
+		}
+		w.line(0, ")")
+	case ProviderStyleContainer:
+		renderContainer(w, name, graph, typeString)
+	}
+	return w.b.String(), nil
+}
+
+// renderContainer renders the container struct `name` and its constructor.
+func renderContainer(w *codeWriter, name string, graph ProviderGraph, typeString func(Type) string) {
+	fields := make([]string, 0, len(graph.Providers))
+	usedFields := make(map[string]struct{})
+	width := 0
+	for _, provider := range graph.Providers {
+		field := strings.TrimPrefix(provider.Func.Name, "New")
+		if field == "" {
+			field = exportedName(typeName(provider.Provides))
+		}
+		field = uniqueName(field, usedFields)
+		fields = append(fields, field)
+		if len(field) > width {
+			width = len(field)
+		}
+	}
+
+	// the missing dependencies are the constructor's inputs, named after their types.
+	params := make([]string, 0, len(graph.Missing))
+	usedParams := map[string]struct{}{"c": {}, "err": {}}
+	for _, missing := range graph.Missing {
+		param := unexportedName(typeName(missing))
+		if token.IsKeyword(param) {
+			param += "Value"
+		}
+		params = append(params, uniqueName(param, usedParams))
+	}
+
+	// argument returns the expression passing the value of a dependency.
+	argument := func(dependency Type) string {
+		for i, provider := range graph.Providers {
+			if Identical(dependency, provider.Provides) {
+				return "c." + fields[i]
+			}
+		}
+		for _, binding := range graph.Bindings {
+			if Identical(dependency, binding.Interface) {
+				for i, provider := range graph.Providers {
+					if Identical(binding.Implementation, provider.Provides) {
+						return "c." + fields[i]
+					}
+				}
+			}
+		}
+		for i, missing := range graph.Missing {
+			if Identical(dependency, missing) {
+				return params[i]
+			}
+		}
+		return "nil"
+	}
+
+	w.line(0, "// %s contains the values provided by the constructors.", name)
+	w.line(0, "type %s struct {", name)
+	for i, provider := range graph.Providers {
+		w.line(1, "%-*s %s", width, fields[i], typeString(provider.Provides))
+	}
+	w.line(0, "}")
+	w.line(0, "")
+
+	inputs := make([]string, 0, len(params))
+	for i, param := range params {
+		inputs = append(inputs, param+" "+typeString(graph.Missing[i]))
+	}
+	w.line(0, "// New%s builds a %s by calling the constructors in the order of their dependencies.", name, name)
+	w.line(0, "func New%s(%s) (*%s, error) {", name, strings.Join(inputs, ", "), name)
+	w.line(1, "c := &%s{}", name)
+	for _, provider := range graph.Providers {
+		if provider.ReturnsError {
+			w.line(1, "var err error")
+			break
+		}
+	}
+	for i, provider := range graph.Providers {
+		args := make([]string, 0, len(provider.Dependencies))
+		for _, dependency := range provider.Dependencies {
+			args = append(args, argument(dependency))
+		}
+		call := typeString(provider.Func.Type()) + "(" + strings.Join(args, ", ") + ")"
+		if !provider.ReturnsError {
+			w.line(1, "c.%s = %s", fields[i], call)
+			continue
+		}
+		w.line(1, "if c.%s, err = %s; err != nil {", fields[i], call)
+		w.line(2, "return nil, fmt.Errorf(\"%s: %%w\", err)", provider.Func.Name)
+		w.line(1, "}")
+	}
+	w.line(1, "return c, nil")
+	w.line(0, "}")
+}
+
+// typeName returns the name of a named type, or of a pointer to it, like "Service" for `*app.Service`. It returns
+// "value" for the other types.
+func typeName(typ Type) string {
+	if typ.PtrType != nil {
+		typ = typ.PtrType.Elem
+	}
+	if typ.QualType == nil {
+		return "value"
+	}
+	return typ.QualType.Name
+}
+
+// uniqueName returns `name`, or `name` followed by a number when it's used already, like "config2", and marks the
+// returned name as used.
+func uniqueName(name string, used map[string]struct{}) string {
+	unique := name
+	for n := 2; ; n++ {
+		if _, ok := used[unique]; !ok {
+			break
+		}
+		unique = name + strconv.Itoa(n)
+	}
+	used[unique] = struct{}{}
+	return unique
+}
@@ -0,0 +1,89 @@
+package gotype
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindProviders(t *testing.T) {
+	graph, err := NewGenerator().FindProviders(testdataPackage + "/providers")
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(graph.Providers))
+	for _, provider := range graph.Providers {
+		names = append(names, provider.Func.Name)
+	}
+	assert.Equal(t, []string{"NewSQLRepository", "NewService", "NewHandler"}, names)
+
+	assert.True(t, graph.Providers[0].ReturnsError)
+	assert.Equal(t, "*providers.SQLRepository", graph.Providers[0].Provides.String(""))
+	assert.Len(t, graph.Providers[1].Dependencies, 2)
+
+	require.Len(t, graph.Bindings, 1)
+	assert.Equal(t, "providers.Repository", graph.Bindings[0].Interface.String(""))
+	assert.Equal(t, "*providers.SQLRepository", graph.Bindings[0].Implementation.String(""))
+
+	missing := make([]string, 0, len(graph.Missing))
+	for _, typ := range graph.Missing {
+		missing = append(missing, typ.String(""))
+	}
+	assert.Equal(t, []string{"*sql.DB", "providers.Config", "*log.Logger"}, missing)
+
+	_, err = NewGenerator().FindProviders(testdataPackage + "/providers/cycle")
+	assert.EqualError(t, err, "dependency cycle: cycle.NewA -> cycle.NewB -> cycle.NewC -> cycle.NewA")
+}
+
+func TestRenderProviders(t *testing.T) {
+	graph, err := NewGenerator().FindProviders(testdataPackage + "/providers")
+	require.NoError(t, err)
+	packagePath := testdataPackage + "/providers"
+
+	code, err := RenderProviders(packagePath, "providers", "ProviderSet", graph, ProviderStyleWire)
+	require.NoError(t, err)
+	assert.Equal(t, `package providers
+
+import (
+	"github.com/google/wire"
+)
+
+var ProviderSet = wire.NewSet(
+	NewSQLRepository,
+	NewService,
+	NewHandler,
+	wire.Bind(new(Repository), new(*SQLRepository)),
+)
+`, code)
+
+	code, err = RenderProviders(testdataPackage+"/app", "app", "Module", graph, ProviderStyleFx)
+	require.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), "", code, 0)
+	require.NoError(t, err, code)
+	assert.Contains(t, code, "\t\"go.uber.org/fx\"\n\t\""+packagePath+"\"\n")
+	assert.Contains(t, code, "\tproviders.NewHandler,\n")
+	assert.Contains(t, code, "\tfunc(v *providers.SQLRepository) providers.Repository { return v },\n")
+
+	code, err = RenderProviders(packagePath, "providers", "Container", graph, ProviderStyleContainer)
+	require.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), "", code, 0)
+	require.NoError(t, err, code)
+	assert.Contains(t, code, "type Container struct {\n"+
+		"\tSQLRepository *SQLRepository\n"+
+		"\tService       *Service\n"+
+		"\tHandler       *Handler\n}\n")
+	assert.Contains(t, code, "func NewContainer(db *sql.DB, config Config, logger *log.Logger) (*Container, error) {\n"+
+		"\tc := &Container{}\n"+
+		"\tvar err error\n"+
+		"\tif c.SQLRepository, err = NewSQLRepository(db, config); err != nil {\n"+
+		"\t\treturn nil, fmt.Errorf(\"NewSQLRepository: %w\", err)\n"+
+		"\t}\n"+
+		"\tc.Service = NewService(c.SQLRepository, logger)\n"+
+		"\tc.Handler = NewHandler(c.Service)\n"+
+		"\treturn c, nil\n}\n")
+
+	_, err = RenderProviders(packagePath, "providers", "Container", graph, ProviderStyle(42))
+	assert.EqualError(t, err, "unknown provider style 42")
+}
@@ -0,0 +1,119 @@
+package gotype
+
+import (
+	"go/ast"
+	"strconv"
+	"strings"
+)
+
+// referenceIndex maps the QualType keys of the referenced declarations to the set of packages referring to them.
+type referenceIndex map[string]map[string]struct{}
+
+// FindUnusedExportedTypes reports the exported types which are never referenced outside their own package. See
+// `TypeGenerator.FindUnusedExportedTypes` for the details.
+func FindUnusedExportedTypes(packagePatterns ...string) ([]QualType, error) {
+	return defaultAstTypeGenerator.FindUnusedExportedTypes(packagePatterns...)
+}
+
+func (f *astTypeGenerator) FindUnusedExportedTypes(packagePatterns ...string) ([]QualType, error) {
+	f = f.fork()
+	packages, err := f.expandPackagePatterns(packagePatterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := f.buildReferenceIndex(packages)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]QualType, 0)
+	for _, packagePath := range packages {
+		names, err := f.getDeclaredTypeNames(packagePath)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range names {
+			if !ast.IsExported(name) {
+				continue
+			}
+			used := false
+			for referencing := range index[qualTypeKey(QualType{Package: packagePath, Name: name})] {
+				used = used || referencing != packagePath
+			}
+			if !used {
+				results = append(results, f.rewriteQualType(QualType{Package: packagePath, Name: name}))
+			}
+		}
+	}
+	return results, nil
+}
+
+// buildReferenceIndex indexes the references to the declarations of the other packages made by the `packages`. The
+// references are found syntactically: a reference is a selector like `pkg.Name` whose `pkg` is an imported package,
+// or an identifier declared by a dot-imported package. The test files are skipped, since they aren't part of the
+// packages.
+func (f *astTypeGenerator) buildReferenceIndex(packages []string) (referenceIndex, error) {
+	index := make(referenceIndex)
+	add := func(referenced, name, referencing string) {
+		key := qualTypeKey(QualType{Package: referenced, Name: name})
+		if index[key] == nil {
+			index[key] = make(map[string]struct{})
+		}
+		index[key][referencing] = struct{}{}
+	}
+
+	for _, packagePath := range packages {
+		goSources, err := f.getPackageSourceFiles(packagePath)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, source := range goSources {
+			if strings.HasSuffix(source, "_test.go") {
+				continue
+			}
+			fileAst, err := f.parseAstFile(source)
+			if err != nil {
+				return nil, err
+			}
+
+			importMap := f.generateImportMap(packagePath, fileAst)
+			dotImports := make([]string, 0)
+			for _, importSpec := range fileAst.Imports {
+				if importSpec.Name != nil && importSpec.Name.Name == "." {
+					if importPath, err := strconv.Unquote(importSpec.Path.Value); err == nil {
+						dotImports = append(dotImports, importPath)
+					}
+				}
+			}
+
+			var visit func(node ast.Node) bool
+			visit = func(node ast.Node) bool {
+				switch n := node.(type) {
+				case *ast.SelectorExpr:
+					// the identifiers referring to the local declarations are resolved by the parser, unlike the
+					// package names.
+					if x, ok := n.X.(*ast.Ident); ok && x.Obj == nil {
+						if importPath, ok := importMap[x.Name]; ok {
+							add(importPath, n.Sel.Name, packagePath)
+						}
+					}
+					// the selected name isn't an identifier of the file's scope, so it's not visited.
+					ast.Inspect(n.X, visit)
+					return false
+				case *ast.Ident:
+					if n.Obj == nil && ast.IsExported(n.Name) {
+						for _, importPath := range dotImports {
+							add(importPath, n.Name, packagePath)
+						}
+					}
+				}
+				return true
+			}
+			ast.Inspect(fileAst, visit)
+		}
+	}
+	return index, nil
+}
@@ -0,0 +1,261 @@
+package gotype
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// schemaRegistryContentType is the content type of the requests and responses of the schema registry's REST API.
+const schemaRegistryContentType = "application/vnd.schemaregistry.v1+json"
+
+// SchemaType represents the format of a schema registered with a schema registry.
+type SchemaType string
+
+const (
+	// SchemaTypeAvro represents an Avro schema. It's the default type of the schema registry.
+	SchemaTypeAvro SchemaType = "AVRO"
+
+	// SchemaTypeProtobuf represents a .proto file, like the one rendered by RenderGRPCService.
+	SchemaTypeProtobuf SchemaType = "PROTOBUF"
+
+	// SchemaTypeJSON represents a JSON schema.
+	SchemaTypeJSON SchemaType = "JSON"
+)
+
+// Compatibility represents the compatibility mode of a subject, which is checked by the schema registry when a new
+// version of the subject's schema is registered.
+type Compatibility string
+
+const (
+	// CompatibilityBackward allows the consumers using the new schema to read the data written by the previous one.
+	CompatibilityBackward Compatibility = "BACKWARD"
+
+	// CompatibilityBackwardTransitive allows the consumers using the new schema to read the data written by all the
+	// previous ones.
+	CompatibilityBackwardTransitive Compatibility = "BACKWARD_TRANSITIVE"
+
+	// CompatibilityForward allows the consumers using the previous schema to read the data written by the new one.
+	CompatibilityForward Compatibility = "FORWARD"
+
+	// CompatibilityForwardTransitive allows the consumers using any of the previous schemas to read the data written
+	// by the new one.
+	CompatibilityForwardTransitive Compatibility = "FORWARD_TRANSITIVE"
+
+	// CompatibilityFull requires both the backward and the forward compatibility with the previous schema.
+	CompatibilityFull Compatibility = "FULL"
+
+	// CompatibilityFullTransitive requires both the backward and the forward compatibility with all the previous
+	// schemas.
+	CompatibilityFullTransitive Compatibility = "FULL_TRANSITIVE"
+
+	// CompatibilityNone disables the compatibility checks.
+	CompatibilityNone Compatibility = "NONE"
+)
+
+// SubjectNameStrategy returns the subject of a schema used by the messages of the Kafka's topic `topic`, as their
+// keys when `isKey` is true, or as their values otherwise. `recordName` is the fully qualified name of the schema's
+// record, like "com.example.User".
+type SubjectNameStrategy func(topic string, isKey bool, recordName string) string
+
+// TopicNameStrategy names the subjects after their topics, like "users-value", so a topic has a single schema for its
+// keys and a single schema for its values. It's the default strategy of the schema registry's serializers.
+func TopicNameStrategy(topic string, isKey bool, _ string) string {
+	if isKey {
+		return topic + "-key"
+	}
+	return topic + "-value"
+}
+
+// RecordNameStrategy names the subjects after their records, like "com.example.User", so a record has the same schema
+// in all the topics.
+func RecordNameStrategy(_ string, _ bool, recordName string) string {
+	return recordName
+}
+
+// TopicRecordNameStrategy names the subjects after their topics and records, like "users-com.example.User", so a
+// topic can contain several records.
+func TopicRecordNameStrategy(topic string, _ bool, recordName string) string {
+	return topic + "-" + recordName
+}
+
+// Schema is a schema registered with a schema registry.
+type Schema struct {
+	// Type contains the format of the schema.
+	Type SchemaType
+
+	// RecordName contains the fully qualified name of the schema's record, like "com.example.User". It's used by the
+	// subject name strategies naming the subjects after their records.
+	RecordName string
+
+	// Schema contains the schema's source, like the source of a .proto file.
+	Schema string
+}
+
+// SchemaRegistryError is returned by SchemaPublisher when the schema registry rejects a request, like a schema
+// which isn't compatible with the previous versions of its subject.
+type SchemaRegistryError struct {
+	// StatusCode contains the HTTP status code of the response.
+	StatusCode int
+
+	// ErrorCode contains the schema registry's error code, like 409 for an incompatible schema. It's zero when the
+	// response doesn't contain it.
+	ErrorCode int
+
+	// Message contains the schema registry's error message.
+	Message string
+}
+
+func (e *SchemaRegistryError) Error() string {
+	if e.ErrorCode == 0 {
+		return fmt.Sprintf("schema registry responded with status %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("schema registry error %d: %s", e.ErrorCode, e.Message)
+}
+
+// SchemaPublisherOption configures NewSchemaPublisher.
+type SchemaPublisherOption func(*SchemaPublisher)
+
+// WithSubjectNameStrategy sets the strategy naming the subjects of the published schemas. By default, the subjects
+// are named by TopicNameStrategy.
+func WithSubjectNameStrategy(strategy SubjectNameStrategy) SchemaPublisherOption {
+	return func(p *SchemaPublisher) {
+		p.strategy = strategy
+	}
+}
+
+// WithCompatibility sets the compatibility mode of the subjects of the published schemas before registering them.
+// By default, the subjects use the global compatibility mode of the schema registry.
+func WithCompatibility(compatibility Compatibility) SchemaPublisherOption {
+	return func(p *SchemaPublisher) {
+		p.compatibility = compatibility
+	}
+}
+
+// WithRegistryHTTPClient sets the HTTP client sending the requests to the schema registry. By default, the requests
+// are sent by http.DefaultClient.
+func WithRegistryHTTPClient(client *http.Client) SchemaPublisherOption {
+	return func(p *SchemaPublisher) {
+		p.client = client
+	}
+}
+
+// WithRegistryBasicAuth authenticates the requests sent to the schema registry using the HTTP basic authentication.
+func WithRegistryBasicAuth(username, password string) SchemaPublisherOption {
+	return func(p *SchemaPublisher) {
+		p.username = username
+		p.password = password
+	}
+}
+
+// SchemaPublisher registers the schemas with a Confluent-compatible schema registry, using its REST API.
+type SchemaPublisher struct {
+	url           string
+	client        *http.Client
+	strategy      SubjectNameStrategy
+	compatibility Compatibility
+	username      string
+	password      string
+}
+
+// NewSchemaPublisher returns a SchemaPublisher registering the schemas with the schema registry served at
+// `registryURL`, like "http://localhost:8081".
+func NewSchemaPublisher(registryURL string, opts ...SchemaPublisherOption) *SchemaPublisher {
+	p := &SchemaPublisher{
+		url:      strings.TrimSuffix(registryURL, "/"),
+		client:   http.DefaultClient,
+		strategy: TopicNameStrategy,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Subject returns the subject of the schema used by the messages of the topic, as their keys when `isKey` is true, or
+// as their values otherwise.
+func (p *SchemaPublisher) Subject(topic string, isKey bool, schema Schema) string {
+	return p.strategy(topic, isKey, schema.RecordName)
+}
+
+// Publish registers the schema used by the messages of the topic, as their keys when `isKey` is true, or as their
+// values otherwise, under the subject named by the publisher's SubjectNameStrategy. The compatibility mode set by
+// WithCompatibility is set on the subject first. Publishing a schema which is registered already is a no-op. It
+// returns the schema's ID assigned by the schema registry.
+func (p *SchemaPublisher) Publish(ctx context.Context, topic string, isKey bool, schema Schema) (int, error) {
+	subject := p.Subject(topic, isKey, schema)
+	if subject == "" {
+		return 0, fmt.Errorf("cannot publish schema of topic %s without a subject", topic)
+	}
+
+	if p.compatibility != "" {
+		request := map[string]interface{}{"compatibility": p.compatibility}
+		if err := p.do(ctx, http.MethodPut, "/config/"+url.PathEscape(subject), request, nil); err != nil {
+			return 0, fmt.Errorf("cannot set compatibility of subject %s: %w", subject, err)
+		}
+	}
+
+	request := map[string]interface{}{"schema": schema.Schema}
+	// the registry assumes the Avro schemas when the type isn't given, and the older registries don't know the type.
+	if schema.Type != "" && schema.Type != SchemaTypeAvro {
+		request["schemaType"] = schema.Type
+	}
+	response := struct {
+		ID int `json:"id"`
+	}{}
+	path := "/subjects/" + url.PathEscape(subject) + "/versions"
+	if err := p.do(ctx, http.MethodPost, path, request, &response); err != nil {
+		return 0, fmt.Errorf("cannot register schema of subject %s: %w", subject, err)
+	}
+	return response.ID, nil
+}
+
+// do sends a request to the schema registry, and decodes its response into `response`, unless it's nil.
+func (p *SchemaPublisher) do(ctx context.Context, method, path string, request, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("cannot encode request: %w", err)
+	}
+	httpRequest, err := http.NewRequestWithContext(ctx, method, p.url+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpRequest.Header.Set("Content-Type", schemaRegistryContentType)
+	httpRequest.Header.Set("Accept", schemaRegistryContentType)
+	if p.username != "" || p.password != "" {
+		httpRequest.SetBasicAuth(p.username, p.password)
+	}
+
+	httpResponse, err := p.client.Do(httpRequest)
+	if err != nil {
+		return err
+	}
+	defer httpResponse.Body.Close()
+
+	responseBody, err := io.ReadAll(httpResponse.Body)
+	if err != nil {
+		return fmt.Errorf("cannot read response: %w", err)
+	}
+	if httpResponse.StatusCode < 200 || httpResponse.StatusCode >= 300 {
+		registryErr := &SchemaRegistryError{StatusCode: httpResponse.StatusCode}
+		if err := json.Unmarshal(responseBody, &struct {
+			ErrorCode *int    `json:"error_code"`
+			Message   *string `json:"message"`
+		}{&registryErr.ErrorCode, &registryErr.Message}); err != nil || registryErr.Message == "" {
+			registryErr.Message = strings.TrimSpace(string(responseBody))
+		}
+		return registryErr
+	}
+	if response == nil {
+		return nil
+	}
+	if err := json.Unmarshal(responseBody, response); err != nil {
+		return fmt.Errorf("cannot decode response: %w", err)
+	}
+	return nil
+}
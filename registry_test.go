@@ -0,0 +1,95 @@
+package gotype
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubjectNameStrategies(t *testing.T) {
+	assert.Equal(t, "users-key", TopicNameStrategy("users", true, "com.example.User"))
+	assert.Equal(t, "users-value", TopicNameStrategy("users", false, "com.example.User"))
+	assert.Equal(t, "com.example.User", RecordNameStrategy("users", false, "com.example.User"))
+	assert.Equal(t, "users-com.example.User", TopicRecordNameStrategy("users", false, "com.example.User"))
+}
+
+func TestSchemaPublisher(t *testing.T) {
+	type request struct {
+		method, path string
+		body         map[string]interface{}
+	}
+	requests := make([]request, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ := r.BasicAuth()
+		assert.Equal(t, "user", username)
+		assert.Equal(t, "secret", password)
+		assert.Equal(t, schemaRegistryContentType, r.Header.Get("Content-Type"))
+
+		body := make(map[string]interface{})
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests = append(requests, request{method: r.Method, path: r.URL.EscapedPath(), body: body})
+
+		switch {
+		case r.Method == http.MethodPut:
+			_, _ = w.Write([]byte(`{"compatibility": "BACKWARD"}`))
+		case body["schema"] == "incompatible":
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"error_code": 409, "message": "Schema being registered is incompatible"}`))
+		default:
+			_, _ = w.Write([]byte(`{"id": 42}`))
+		}
+	}))
+	defer server.Close()
+
+	publisher := NewSchemaPublisher(
+		server.URL+"/",
+		WithSubjectNameStrategy(TopicRecordNameStrategy),
+		WithCompatibility(CompatibilityBackward),
+		WithRegistryBasicAuth("user", "secret"),
+	)
+	schema := Schema{Type: SchemaTypeProtobuf, RecordName: "users.v1.User", Schema: "syntax = \"proto3\";"}
+	id, err := publisher.Publish(context.Background(), "users", false, schema)
+	require.NoError(t, err)
+	assert.Equal(t, 42, id)
+	assert.Equal(t, []request{
+		{
+			method: http.MethodPut,
+			path:   "/config/users-users.v1.User",
+			body:   map[string]interface{}{"compatibility": "BACKWARD"},
+		},
+		{
+			method: http.MethodPost,
+			path:   "/subjects/users-users.v1.User/versions",
+			body:   map[string]interface{}{"schema": "syntax = \"proto3\";", "schemaType": "PROTOBUF"},
+		},
+	}, requests)
+
+	requests = requests[:0]
+	publisher = NewSchemaPublisher(server.URL, WithRegistryBasicAuth("user", "secret"))
+	_, err = publisher.Publish(context.Background(), "users", true, Schema{Type: SchemaTypeAvro, Schema: `"string"`})
+	require.NoError(t, err)
+	assert.Equal(t, []request{{
+		method: http.MethodPost,
+		path:   "/subjects/users-key/versions",
+		body:   map[string]interface{}{"schema": `"string"`},
+	}}, requests)
+
+	_, err = publisher.Publish(context.Background(), "users", false, Schema{Type: SchemaTypeJSON, Schema: "incompatible"})
+	require.Error(t, err)
+	assert.Equal(
+		t,
+		"cannot register schema of subject users-value: schema registry error 409: "+
+			"Schema being registered is incompatible",
+		err.Error(),
+	)
+	var registryErr *SchemaRegistryError
+	require.True(t, errors.As(err, &registryErr))
+	assert.Equal(t, http.StatusConflict, registryErr.StatusCode)
+	assert.Equal(t, 409, registryErr.ErrorCode)
+}
@@ -0,0 +1,206 @@
+package gotype
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+)
+
+// deepResolver fills `QualType.Underlying` of the QualTypes found inside a Type, recursively.
+type deepResolver struct {
+	generator *astTypeGenerator
+
+	// resolving contains the keys of the QualTypes being resolved, used to detect the references closing a cycle.
+	resolving map[string]struct{}
+
+	// resolved caches the resolved definition of each QualType, see `cacheKey`.
+	resolved map[string]Type
+
+	// chains caches the declaration chain of each QualType, see `cacheKey` and `QualType.Chain`.
+	chains map[string][]DeclarationLink
+
+	// packages contains the chain of the packages being resolved, used to detect import cycles.
+	packages []string
+
+	// depth contains the number of the nested QualTypes being resolved, bounded by `WithMaxDepth`.
+	depth int
+}
+
+func newDeepResolver(generator *astTypeGenerator) *deepResolver {
+	return &deepResolver{
+		generator: generator,
+		resolving: make(map[string]struct{}),
+		resolved:  make(map[string]Type),
+		chains:    make(map[string][]DeclarationLink),
+	}
+}
+
+// resolveDeclaration resolves the QualTypes inside `typ`, which is the declaration of the type specified by `spec`.
+func (r *deepResolver) resolveDeclaration(spec TypeSpec, typ Type) (_ Type, err error) {
+	end := r.generator.startPhase(phaseResolveType, "gotype.package", spec.PackagePath, "gotype.type", spec.Name)
+	defer func() { end(err) }()
+
+	// the QualTypes referring to a version-pinned package don't contain the version.
+	spec.PackagePath, _ = splitPackageVersion(spec.PackagePath)
+	key := qualTypeKey(QualType{Package: spec.PackagePath, Name: spec.Name})
+	r.resolving[key] = struct{}{}
+	defer delete(r.resolving, key)
+	r.packages = append(r.packages, spec.PackagePath)
+	defer func() { r.packages = r.packages[:len(r.packages)-1] }()
+
+	r.generator.beginTrace(spec)
+	defer r.generator.endTrace()
+	return r.resolve(typ)
+}
+
+func (r *deepResolver) resolve(typ Type) (Type, error) {
+	var resolveErr error
+	result := mapType(typ, func(t Type) (Type, bool) {
+		if resolveErr != nil || t.QualType == nil || t.QualType.Package == "" {
+			return t, false
+		}
+		if maxDepth := r.generator.config.maxDepth; maxDepth > 0 && r.depth >= maxDepth {
+			r.generator.explain(
+				token.NoPos,
+				"reference to %s is deeper than %d levels, it's left unresolved",
+				qualTypeKey(*t.QualType),
+				maxDepth,
+			)
+			return t, true
+		}
+
+		q := *t.QualType
+		args := make([]Type, 0, len(q.TypeArgs))
+		for _, arg := range q.TypeArgs {
+			resolvedArg, err := r.resolve(arg)
+			if err != nil {
+				resolveErr = err
+				return t, true
+			}
+			args = append(args, resolvedArg)
+		}
+		if len(args) > 0 {
+			q.TypeArgs = args
+		}
+
+		underlying, err := r.resolveQualType(q)
+		if err != nil {
+			resolveErr = err
+			return t, true
+		}
+		q.Underlying = underlying
+		if underlying != nil {
+			q.Chain = r.chains[r.cacheKey(q)]
+		}
+		t.QualType = &q
+		return t, true
+	})
+	if resolveErr != nil {
+		return Type{}, resolveErr
+	}
+	return result, nil
+}
+
+// resolveQualType returns the resolved definition of `q`, or nil when `q` closes a cycle.
+func (r *deepResolver) resolveQualType(q QualType) (*Type, error) {
+	key := qualTypeKey(q)
+	if resolved, ok := r.resolved[r.cacheKey(q)]; ok {
+		return &resolved, nil
+	}
+	if _, ok := r.resolving[key]; ok {
+		r.generator.explain(token.NoPos, "reference to %s closes a cycle, it's left unresolved", key)
+		return nil, nil
+	}
+
+	if cycle := r.importCycle(q.Package); cycle != nil {
+		chain := strings.Join(cycle, " -> ")
+		r.generator.explain(token.NoPos, "reference to %s closes the import cycle %s, it's left unresolved", key, chain)
+		r.generator.warnAt(token.Position{}, "import cycle %s", chain)
+		return nil, nil
+	}
+
+	r.resolving[key] = struct{}{}
+	defer delete(r.resolving, key)
+	r.packages = append(r.packages, q.Package)
+	defer func() { r.packages = r.packages[:len(r.packages)-1] }()
+
+	types, err := r.generator.generateTypesInSinglePackage(q.Package, q.Name)
+	if err != nil {
+		return nil, err
+	}
+	definition := types[0]
+	if len(q.TypeArgs) > 0 {
+		if definition, err = Instantiate(definition, q.TypeArgs...); err != nil {
+			return nil, err
+		}
+	}
+
+	r.depth++
+	resolved, err := r.resolve(definition)
+	r.depth--
+	if err != nil {
+		return nil, err
+	}
+	r.resolved[r.cacheKey(q)] = resolved
+	r.chains[r.cacheKey(q)] = r.declarationChain(q, resolved)
+	return &resolved, nil
+}
+
+// cacheKey returns the key caching the resolution of `q`. When the depth is bounded, the resolution of a QualType
+// depends on the depth it's found at, which is part of the key.
+func (r *deepResolver) cacheKey(q QualType) string {
+	if r.generator.config.maxDepth > 0 {
+		return fmt.Sprintf("%s@%d", qualTypeKey(q), r.depth)
+	}
+	return qualTypeKey(q)
+}
+
+// declarationChain returns the chain of declarations of `q`, whose resolved definition is `resolved`: the
+// declaration of `q`, followed by the chain of the QualType it's declared as, if any.
+func (r *deepResolver) declarationChain(q QualType, resolved Type) []DeclarationLink {
+	link, ok := r.generator.declarations[qualTypeKey(QualType{Package: q.Package, Name: q.Name})]
+	if !ok {
+		link = DeclarationLink{Package: q.Package, Name: q.Name}
+	}
+	chain := []DeclarationLink{link}
+	if resolved.QualType != nil {
+		chain = append(chain, resolved.QualType.Chain...)
+	}
+	return chain
+}
+
+// importCycle returns the chain of packages closed by resolving a type of `packagePath`, or nil when it doesn't close
+// an import cycle. The types of the package being resolved can refer to each other freely.
+func (r *deepResolver) importCycle(packagePath string) []string {
+	if len(r.packages) == 0 || r.packages[len(r.packages)-1] == packagePath {
+		return nil
+	}
+	for i, p := range r.packages {
+		if p == packagePath {
+			return append(append([]string{}, r.packages[i:]...), packagePath)
+		}
+	}
+	return nil
+}
+
+// qualTypeKey returns a string identifying a QualType including its type arguments.
+func qualTypeKey(q QualType) string {
+	key := q.Package + "." + q.Name
+	if len(q.TypeArgs) > 0 {
+		args := make([]string, 0, len(q.TypeArgs))
+		for _, arg := range q.TypeArgs {
+			// rendering the full package paths avoids clashes between packages having the same name.
+			canonical := mapType(arg, func(t Type) (Type, bool) {
+				if t.QualType != nil {
+					c := *t.QualType
+					c.ShortPackagePath = c.Package
+					t.QualType = &c
+				}
+				return t, false
+			})
+			args = append(args, canonical.String(""))
+		}
+		key += "[" + strings.Join(args, ",") + "]"
+	}
+	return key
+}
@@ -0,0 +1,105 @@
+package gotype
+
+import "strings"
+
+// RewriteModulePath returns a package path rewrite, see `WithPackagePathRewrite`, which moves the packages of the
+// module `oldModulePath` into the module `newModulePath`, e.g. "example.com/old/pkg" into "example.com/new/pkg". The
+// other package paths are kept.
+func RewriteModulePath(oldModulePath, newModulePath string) func(packagePath string) string {
+	return func(packagePath string) string {
+		if packagePath == oldModulePath || strings.HasPrefix(packagePath, oldModulePath+"/") {
+			return newModulePath + strings.TrimPrefix(packagePath, oldModulePath)
+		}
+		return packagePath
+	}
+}
+
+// rewritePackagePaths returns a copy of `typ` whose QualTypes' packages are rewritten by the configured rewrites,
+// including the QualTypes inside the `Underlying` definitions, the interfaces' embedded interfaces and methods'
+// origins, and the type parameters' constraints.
+func (f *astTypeGenerator) rewritePackagePaths(typ Type) Type {
+	if len(f.config.packagePathRewrites) == 0 {
+		return typ
+	}
+
+	typ = mapType(typ, func(t Type) (Type, bool) {
+		switch {
+		case t.QualType != nil:
+			q := f.rewriteQualType(*t.QualType)
+			t.QualType = &q
+			return t, true
+		case t.InterfaceType != nil:
+			i := f.rewriteInterfaceOrigins(*t.InterfaceType)
+			t.InterfaceType = &i
+		}
+		return t, false
+	})
+
+	if typ.TypeParams != nil {
+		params := make([]TypeParam, 0, len(typ.TypeParams))
+		for _, param := range typ.TypeParams {
+			param.Constraint = f.rewritePackagePaths(param.Constraint)
+			if param.ConstraintInterface != nil {
+				constraint := *f.rewritePackagePaths(Type{InterfaceType: param.ConstraintInterface}).InterfaceType
+				param.ConstraintInterface = &constraint
+			}
+			params = append(params, param)
+		}
+		typ.TypeParams = params
+	}
+	return typ
+}
+
+// rewriteQualType returns a copy of `q` whose package, type arguments, underlying definition and declaration chain are
+// rewritten by the configured rewrites.
+func (f *astTypeGenerator) rewriteQualType(q QualType) QualType {
+	q.Package = f.rewritePackagePath(q.Package)
+	if len(q.TypeArgs) > 0 {
+		q.TypeArgs = mapTypes(q.TypeArgs, func(t Type) (Type, bool) { return f.rewritePackagePaths(t), true })
+	}
+	if q.Underlying != nil {
+		underlying := f.rewritePackagePaths(*q.Underlying)
+		q.Underlying = &underlying
+	}
+	if q.Chain != nil {
+		chain := make([]DeclarationLink, 0, len(q.Chain))
+		for _, link := range q.Chain {
+			link.Package = f.rewritePackagePath(link.Package)
+			chain = append(chain, link)
+		}
+		q.Chain = chain
+	}
+	return q
+}
+
+// rewriteInterfaceOrigins returns a copy of `i` whose embedded interfaces and methods' origins are rewritten by the
+// configured rewrites. The methods' signatures are left to mapType.
+func (f *astTypeGenerator) rewriteInterfaceOrigins(i InterfaceType) InterfaceType {
+	if i.Embedded != nil {
+		embedded := make([]QualType, 0, len(i.Embedded))
+		for _, q := range i.Embedded {
+			embedded = append(embedded, f.rewriteQualType(q))
+		}
+		i.Embedded = embedded
+	}
+	if i.Methods != nil {
+		methods := make([]InterfaceTypeMethod, 0, len(i.Methods))
+		for _, method := range i.Methods {
+			if method.Origin != nil {
+				origin := f.rewriteQualType(*method.Origin)
+				method.Origin = &origin
+			}
+			methods = append(methods, method)
+		}
+		i.Methods = methods
+	}
+	return i
+}
+
+// rewritePackagePath returns the package path rewritten by the configured rewrites.
+func (f *astTypeGenerator) rewritePackagePath(packagePath string) string {
+	for _, rewrite := range f.config.packagePathRewrites {
+		packagePath = rewrite(packagePath)
+	}
+	return packagePath
+}
@@ -0,0 +1,394 @@
+package gotype
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// VersionBump represents the part of a semantic version which has to be incremented to release a change.
+type VersionBump int
+
+const (
+	// VersionBumpPatch is required by the changes keeping the exported API as it is.
+	VersionBumpPatch VersionBump = iota
+
+	// VersionBumpMinor is required by the changes adding to the exported API without breaking its users.
+	VersionBumpMinor
+
+	// VersionBumpMajor is required by the changes which may break the users of the exported API, like a removed
+	// function or a changed field's type.
+	VersionBumpMajor
+)
+
+func (b VersionBump) String() string {
+	switch b {
+	case VersionBumpPatch:
+		return "patch"
+	case VersionBumpMinor:
+		return "minor"
+	case VersionBumpMajor:
+		return "major"
+	}
+	return fmt.Sprintf("VersionBump(%d)", int(b))
+}
+
+// APIChange is a change of the exported API of a package.
+type APIChange struct {
+	// Name contains the name of the changed declaration, qualified by the name of its type for the fields and the
+	// methods, like "Client.Do".
+	Name string
+
+	// Kind contains the kind of the changed declaration. The fields and the interface methods are reported as changes
+	// of their types.
+	Kind SpecKind
+
+	// Bump contains the version bump required by the change.
+	Bump VersionBump
+
+	// Message describes the change.
+	Message string
+}
+
+func (c APIChange) String() string {
+	return fmt.Sprintf("%s: %s %s: %s", c.Bump, c.Kind, c.Name, c.Message)
+}
+
+// APIChangeReport contains the changes of the exported API of a package between two versions.
+type APIChangeReport struct {
+	// Changes contains the changes, the ones of the declarations of the older version first, in the order of their
+	// declaration.
+	Changes []APIChange
+}
+
+// Bump returns the version bump required by the changes, which is the largest bump required by any of them.
+func (r APIChangeReport) Bump() VersionBump {
+	bump := VersionBumpPatch
+	for _, change := range r.Changes {
+		if change.Bump > bump {
+			bump = change.Bump
+		}
+	}
+	return bump
+}
+
+// NextVersion returns the version following the semantic version `current`, like "v1.2.3", incremented by the
+// report's Bump. The pre-release and build suffixes of `current` are dropped. Like the go tool assumes, the v0
+// versions don't promise any compatibility, so the major bumps of a v0 version increment its minor version.
+func (r APIChangeReport) NextVersion(current string) (string, error) {
+	if !semver.IsValid(current) {
+		return "", fmt.Errorf("invalid semantic version %s", current)
+	}
+	canonical := strings.TrimSuffix(semver.Canonical(current), semver.Prerelease(current))
+	parts := strings.Split(strings.TrimPrefix(canonical, "v"), ".")
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil {
+			return "", fmt.Errorf("invalid semantic version %s: %w", current, err)
+		}
+		numbers[i] = number
+	}
+
+	switch bump := r.Bump(); {
+	case bump == VersionBumpMajor && numbers[0] > 0:
+		numbers = []int{numbers[0] + 1, 0, 0}
+	case bump >= VersionBumpMinor:
+		numbers = []int{numbers[0], numbers[1] + 1, 0}
+	default:
+		numbers[2]++
+	}
+	return fmt.Sprintf("v%d.%d.%d", numbers[0], numbers[1], numbers[2]), nil
+}
+
+// AnalyzeAPIChanges compares the exported API of the package `packagePath` with the one of its version at the git
+// revision `baseRevision`. See `TypeGenerator.AnalyzeAPIChanges` for the details.
+func AnalyzeAPIChanges(packagePath, baseRevision string) (APIChangeReport, error) {
+	return defaultAstTypeGenerator.AnalyzeAPIChanges(packagePath, baseRevision)
+}
+
+func (f *astTypeGenerator) AnalyzeAPIChanges(packagePath, baseRevision string) (APIChangeReport, error) {
+	f = f.fork()
+	before, err := f.forkAtRevision(baseRevision).generateExportedPackageModel(packagePath)
+	if err != nil {
+		return APIChangeReport{}, fmt.Errorf("cannot generate package %s at %s: %w", packagePath, baseRevision, err)
+	}
+	after, err := f.generateExportedPackageModel(packagePath)
+	if err != nil {
+		return APIChangeReport{}, err
+	}
+	return CompareAPI(before, after), nil
+}
+
+// generateExportedPackageModel generates the model of the package's exported declarations, including the exported
+// methods of the exported types. Test files are ignored.
+func (f *astTypeGenerator) generateExportedPackageModel(packagePath string) (PackageModel, error) {
+	goSources, err := f.getPackageSourceFiles(packagePath)
+	if err != nil {
+		return PackageModel{}, err
+	}
+
+	model := PackageModel{Path: packagePath}
+	specs := make([]Spec, 0)
+	imports := make(map[Import]struct{})
+	for _, source := range goSources {
+		if strings.HasSuffix(source, "_test.go") {
+			continue
+		}
+		fileAst, err := f.parseAstFile(source)
+		if err != nil {
+			return PackageModel{}, err
+		}
+		model.Name = fileAst.Name.Name
+
+		for _, spec := range f.fileSpecs(packagePath, fileAst) {
+			if isExportedSpec(spec) {
+				specs = append(specs, spec)
+			}
+		}
+		for _, importSpec := range fileAst.Imports {
+			importPath, err := strconv.Unquote(importSpec.Path.Value)
+			if err != nil {
+				return PackageModel{}, fmt.Errorf("invalid import path %s: %w", importSpec.Path.Value, err)
+			}
+			name := f.getImportNameFromPackagePath(importPath)
+			if importSpec.Name != nil {
+				name = importSpec.Name.Name
+			}
+			imports[Import{Name: name, Package: importPath}] = struct{}{}
+		}
+	}
+
+	if model.Declarations, err = f.GenerateFromSpecs(specs...); err != nil {
+		return PackageModel{}, err
+	}
+	for imp := range imports {
+		model.Imports = append(model.Imports, imp)
+	}
+	sort.Slice(model.Imports, func(i, j int) bool {
+		if model.Imports[i].Name != model.Imports[j].Name {
+			return model.Imports[i].Name < model.Imports[j].Name
+		}
+		return model.Imports[i].Package < model.Imports[j].Package
+	})
+	return model, nil
+}
+
+// CompareAPI compares the exported declarations of the `before` and the `after` versions of a package, like the
+// `apidiff` tool does, and reports the changes of the exported API with the version bumps they require. The
+// unexported declarations, the unexported fields and the methods of the unexported types are ignored.
+//
+// The removed declarations, fields and methods, and the changed types of the declarations are major changes, and so
+// are the changed values of the constants, the methods added to the interfaces, unless the interfaces can't be
+// implemented outside their packages because of their unexported methods, and the value receivers changed to the
+// pointer ones, which shrinks the method sets of the values. The added declarations, fields and methods are minor
+// changes. The named types are compared by their names, so the changes of a type are only reported at its
+// declaration.
+func CompareAPI(before, after PackageModel) APIChangeReport {
+	c := &apiComparator{packageName: after.Name}
+	afterDeclarations := make(map[Spec]Declaration, len(after.Declarations))
+	for _, declaration := range after.Declarations {
+		afterDeclarations[apiSpec(declaration.Spec)] = declaration
+	}
+
+	beforeSpecs := make(map[Spec]struct{}, len(before.Declarations))
+	for _, declaration := range before.Declarations {
+		if !isExportedSpec(declaration.Spec) {
+			continue
+		}
+		spec := apiSpec(declaration.Spec)
+		beforeSpecs[spec] = struct{}{}
+		afterDeclaration, ok := afterDeclarations[spec]
+		if !ok {
+			c.report(spec, VersionBumpMajor, "removed")
+			continue
+		}
+		c.compareDeclarations(declaration, afterDeclaration)
+	}
+	for _, declaration := range after.Declarations {
+		spec := apiSpec(declaration.Spec)
+		if _, ok := beforeSpecs[spec]; !ok && isExportedSpec(spec) {
+			c.report(spec, VersionBumpMinor, "added")
+		}
+	}
+	return APIChangeReport{Changes: c.changes}
+}
+
+// apiSpec returns the Spec identifying a declaration in both versions of a package, whose path may differ.
+func apiSpec(spec Spec) Spec {
+	return Spec{Name: spec.Name, Kind: spec.Kind}
+}
+
+// isExportedSpec reports whether the declaration is a part of the exported API, including the methods of the exported
+// types.
+func isExportedSpec(spec Spec) bool {
+	for _, name := range strings.Split(spec.Name, ".") {
+		if !ast.IsExported(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// apiComparator collects the APIChanges of the compared declarations.
+type apiComparator struct {
+	changes []APIChange
+
+	// packageName contains the name of the compared package, whose types aren't qualified by the messages.
+	packageName string
+}
+
+func (c *apiComparator) report(spec Spec, bump VersionBump, format string, args ...interface{}) {
+	c.changes = append(c.changes, APIChange{
+		Name:    spec.Name,
+		Kind:    spec.Kind,
+		Bump:    bump,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (c *apiComparator) compareDeclarations(before, after Declaration) {
+	spec := apiSpec(before.Spec)
+	if !c.compareTypeParams(spec, before.Type.TypeParams, after.Type.TypeParams) {
+		return
+	}
+
+	switch spec.Kind {
+	case SpecKindType:
+		c.compareTypes(spec, before.Type, after.Type)
+		return
+	case SpecKindMethod:
+		if before.Receiver != nil && after.Receiver != nil && before.Receiver.Pointer != after.Receiver.Pointer {
+			if after.Receiver.Pointer {
+				c.report(spec, VersionBumpMajor, "receiver changed from value to pointer")
+			} else {
+				c.report(spec, VersionBumpMinor, "receiver changed from pointer to value")
+			}
+		}
+	case SpecKindConst:
+		if before.Constant != nil && after.Constant != nil && !constant.Compare(before.Constant, token.EQL, after.Constant) {
+			c.report(spec, VersionBumpMajor, "value changed from %s to %s",
+				before.Constant.ExactString(), after.Constant.ExactString())
+		}
+	}
+	// the types which can't be inferred syntactically can't be compared.
+	if !isEmptyType(before.Type) && !isEmptyType(after.Type) && !Identical(before.Type, after.Type) {
+		c.report(spec, VersionBumpMajor, "type changed from %s to %s", before.Type.String(c.packageName),
+			after.Type.String(c.packageName))
+	}
+}
+
+// compareTypeParams reports the changed type parameters of a generic declaration, and whether they're the same.
+func (c *apiComparator) compareTypeParams(spec Spec, before, after []TypeParam) bool {
+	same := len(before) == len(after)
+	for i := 0; same && i < len(before); i++ {
+		same = Identical(before[i].Constraint, after[i].Constraint)
+	}
+	if !same {
+		c.report(spec, VersionBumpMajor, "type parameters changed from [%s] to [%s]",
+			c.typeParamsString(before), c.typeParamsString(after))
+	}
+	return same
+}
+
+func (c *apiComparator) typeParamsString(typeParams []TypeParam) string {
+	params := make([]string, 0, len(typeParams))
+	for _, param := range typeParams {
+		params = append(params, param.Name+" "+param.Constraint.String(c.packageName))
+	}
+	return strings.Join(params, ", ")
+}
+
+func (c *apiComparator) compareTypes(spec Spec, before, after Type) {
+	switch {
+	case before.StructType != nil && after.StructType != nil:
+		c.compareStructs(spec, *before.StructType, *after.StructType)
+	case before.InterfaceType != nil && after.InterfaceType != nil:
+		c.compareInterfaces(spec, *before.InterfaceType, *after.InterfaceType)
+	case !Identical(before, after):
+		c.report(spec, VersionBumpMajor, "definition changed from %s to %s", before.String(c.packageName),
+			after.String(c.packageName))
+	}
+}
+
+func (c *apiComparator) compareStructs(spec Spec, before, after StructType) {
+	afterFields := make(map[string]TypeField, len(after.Fields))
+	for _, field := range after.Fields {
+		afterFields[field.Name] = field
+	}
+
+	beforeFields := make(map[string]struct{}, len(before.Fields))
+	for _, field := range before.Fields {
+		if !ast.IsExported(field.Name) {
+			continue
+		}
+		beforeFields[field.Name] = struct{}{}
+		afterField, ok := afterFields[field.Name]
+		switch {
+		case !ok:
+			c.report(spec, VersionBumpMajor, "field %s removed", field.Name)
+		case !Identical(field.Type, afterField.Type):
+			c.report(spec, VersionBumpMajor, "type of field %s changed from %s to %s", field.Name,
+				field.Type.String(c.packageName), afterField.Type.String(c.packageName))
+		}
+	}
+	for _, field := range after.Fields {
+		if _, ok := beforeFields[field.Name]; !ok && ast.IsExported(field.Name) {
+			c.report(spec, VersionBumpMinor, "field %s added", field.Name)
+		}
+	}
+}
+
+func (c *apiComparator) compareInterfaces(spec Spec, before, after InterfaceType) {
+	afterMethods := make(map[string]InterfaceTypeMethod, len(after.Methods))
+	for _, method := range after.Methods {
+		afterMethods[method.Name] = method
+	}
+
+	// the interfaces having unexported methods can only be implemented inside their packages.
+	sealed := false
+	beforeMethods := make(map[string]struct{}, len(before.Methods))
+	for _, method := range before.Methods {
+		beforeMethods[method.Name] = struct{}{}
+		if !ast.IsExported(method.Name) {
+			sealed = true
+			continue
+		}
+		afterMethod, ok := afterMethods[method.Name]
+		switch {
+		case !ok:
+			c.report(spec, VersionBumpMajor, "method %s removed", method.Name)
+		case !identicalFunc(method.Func, afterMethod.Func):
+			c.report(spec, VersionBumpMajor, "signature of method %s changed from %s to %s", method.Name,
+				method.Func.String(c.packageName), afterMethod.Func.String(c.packageName))
+		}
+	}
+	becameSealed := false
+	for _, method := range after.Methods {
+		if _, ok := beforeMethods[method.Name]; ok {
+			continue
+		}
+		if !ast.IsExported(method.Name) {
+			// an unexported method stops the implementations outside the package from satisfying the interface.
+			if !sealed && !becameSealed {
+				becameSealed = true
+				c.report(spec, VersionBumpMajor, "interface became sealed by method %s", method.Name)
+			}
+			continue
+		}
+		if sealed {
+			c.report(spec, VersionBumpMinor, "method %s added", method.Name)
+		} else {
+			c.report(spec, VersionBumpMajor, "method %s added, breaking the implementations", method.Name)
+		}
+	}
+	if !identicalInterface(InterfaceType{Unions: before.Unions}, InterfaceType{Unions: after.Unions}) {
+		c.report(spec, VersionBumpMajor, "type set changed")
+	}
+}
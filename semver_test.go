@@ -0,0 +1,223 @@
+package gotype
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareAPI(t *testing.T) {
+	before, err := AnalyzeSource([]byte(`package api
+
+const Version = "1.0"
+
+var Default = Config{}
+
+type Config struct {
+	Name    string
+	Timeout int
+	Debug   bool
+	secret  string
+}
+
+type Store interface {
+	Get(key string) (string, error)
+	Delete(key string) error
+}
+
+type sealed interface {
+	Get() string
+	seal()
+}
+
+type Sealed interface {
+	Get() string
+	seal()
+}
+
+type Handler interface {
+	Handle()
+}
+
+type ID int
+
+type Set[T comparable] map[T]struct{}
+
+type Client struct{}
+
+func (c Client) Do(path string) error { return nil }
+
+func (c *Client) Close() error { return nil }
+
+func (c Client) Reset() {}
+
+func (c client) Hidden() {}
+
+type client struct{}
+
+func New(name string) *Client { return nil }
+
+func Deprecated() {}
+
+func helper() {}
+`), nil)
+	require.NoError(t, err)
+
+	after, err := AnalyzeSource([]byte(`package api
+
+const Version = "2.0"
+
+var Default = &Config{}
+
+type Config struct {
+	Name    string
+	Timeout int64
+	Retries int
+	other   string
+}
+
+type Store interface {
+	Get(key string) (string, error)
+	Put(key, value string) error
+}
+
+type Sealed interface {
+	Get() string
+	Len() int
+	seal()
+}
+
+type Handler interface {
+	Handle()
+	seal()
+	check()
+}
+
+type ID string
+
+type Set[T any] map[T]struct{}
+
+type Client struct{}
+
+func (c *Client) Do(path string) error { return nil }
+
+func (c Client) Close() error { return nil }
+
+func (c Client) Reset() {}
+
+func (c client) Hidden(int) {}
+
+type client struct{}
+
+func New(name string, opts ...string) *Client { return nil }
+
+func Open() *Client { return nil }
+
+func helper(int) {}
+`), nil)
+	require.NoError(t, err)
+
+	report := CompareAPI(before, after)
+	assert.Equal(t, []APIChange{
+		{Name: "Version", Kind: SpecKindConst, Bump: VersionBumpMajor, Message: `value changed from "1.0" to "2.0"`},
+		{Name: "Default", Kind: SpecKindVar, Bump: VersionBumpMajor, Message: "type changed from Config to *Config"},
+		{Name: "Config", Kind: SpecKindType, Bump: VersionBumpMajor,
+			Message: "type of field Timeout changed from int to int64"},
+		{Name: "Config", Kind: SpecKindType, Bump: VersionBumpMajor, Message: "field Debug removed"},
+		{Name: "Config", Kind: SpecKindType, Bump: VersionBumpMinor, Message: "field Retries added"},
+		{Name: "Store", Kind: SpecKindType, Bump: VersionBumpMajor, Message: "method Delete removed"},
+		{Name: "Store", Kind: SpecKindType, Bump: VersionBumpMajor,
+			Message: "method Put added, breaking the implementations"},
+		{Name: "Sealed", Kind: SpecKindType, Bump: VersionBumpMinor, Message: "method Len added"},
+		{Name: "Handler", Kind: SpecKindType, Bump: VersionBumpMajor,
+			Message: "interface became sealed by method seal"},
+		{Name: "ID", Kind: SpecKindType, Bump: VersionBumpMajor, Message: "definition changed from int to string"},
+		{Name: "Set", Kind: SpecKindType, Bump: VersionBumpMajor,
+			Message: "type parameters changed from [T comparable] to [T interface{}]"},
+		{Name: "Client.Do", Kind: SpecKindMethod, Bump: VersionBumpMajor,
+			Message: "receiver changed from value to pointer"},
+		{Name: "Client.Close", Kind: SpecKindMethod, Bump: VersionBumpMinor,
+			Message: "receiver changed from pointer to value"},
+		{Name: "New", Kind: SpecKindFunc, Bump: VersionBumpMajor,
+			Message: "type changed from func(name string) (out1 *Client) to " +
+				"func(name string, opts ...string) (out1 *Client)"},
+		{Name: "Deprecated", Kind: SpecKindFunc, Bump: VersionBumpMajor, Message: "removed"},
+		{Name: "Open", Kind: SpecKindFunc, Bump: VersionBumpMinor, Message: "added"},
+	}, report.Changes)
+	assert.Equal(t, VersionBumpMajor, report.Bump())
+	assert.Equal(t, "major: func Deprecated: removed", report.Changes[14].String())
+
+	report = CompareAPI(before, before)
+	assert.Empty(t, report.Changes)
+	assert.Equal(t, VersionBumpPatch, report.Bump())
+}
+
+func TestAPIChangeReport_NextVersion(t *testing.T) {
+	major := APIChangeReport{Changes: []APIChange{{Bump: VersionBumpMinor}, {Bump: VersionBumpMajor}}}
+	minor := APIChangeReport{Changes: []APIChange{{Bump: VersionBumpMinor}}}
+	patch := APIChangeReport{}
+
+	for _, tc := range []struct {
+		report  APIChangeReport
+		current string
+		next    string
+	}{
+		{major, "v1.2.3", "v2.0.0"},
+		{major, "v0.2.3", "v0.3.0"},
+		{minor, "v1.2.3", "v1.3.0"},
+		{minor, "v1.2", "v1.3.0"},
+		{patch, "v1.2.3", "v1.2.4"},
+		{patch, "v1.2.3-rc.1+build", "v1.2.4"},
+	} {
+		next, err := tc.report.NextVersion(tc.current)
+		require.NoError(t, err)
+		assert.Equal(t, tc.next, next, tc.current)
+	}
+
+	_, err := patch.NextVersion("1.2.3")
+	assert.EqualError(t, err, "invalid semantic version 1.2.3")
+}
+
+func TestAnalyzeAPIChanges(t *testing.T) {
+	repoDir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repoDir
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	writeFile := func(name, content string) {
+		filename := filepath.Join(repoDir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0o755))
+		require.NoError(t, os.WriteFile(filename, []byte(content), 0o644))
+	}
+
+	git("init", "-q")
+	writeFile("go.mod", "module example.com/repo\n\ngo 1.18\n")
+	writeFile("api/api.go", "package api\n\ntype User struct {\n\tName string\n}\n\nfunc Get() User { return User{} }\n")
+	writeFile("api/api_test.go", "package api\n\nfunc TestHelper() {}\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "v1")
+	git("tag", "v1.0.0")
+	writeFile("api/api.go", "package api\n\ntype User struct {\n\tName string\n\tAge  int\n}\n\n"+
+		"func Get() User { return User{} }\n\nfunc get() {}\n")
+	writeFile("api/api_test.go", "package api\n")
+
+	generator := NewGenerator(WithConfig(Config{Dir: repoDir}))
+	report, err := generator.AnalyzeAPIChanges("example.com/repo/api", "v1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, []APIChange{
+		{Name: "User", Kind: SpecKindType, Bump: VersionBumpMinor, Message: "field Age added"},
+	}, report.Changes)
+	next, err := report.NextVersion("v1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.1.0", next)
+
+	_, err = generator.AnalyzeAPIChanges("example.com/repo/api", "v9")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot generate package example.com/repo/api at v9")
+}
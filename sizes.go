@@ -0,0 +1,163 @@
+package gotype
+
+import (
+	"fmt"
+)
+
+// typeLayout describes how the gc compiler lays out the values in memory on a family of architectures.
+type typeLayout struct {
+	wordSize int64
+	maxAlign int64
+}
+
+var (
+	// layout32 is the layout of the 32-bit architectures, like 386 and arm, where the 64-bit values are aligned to 4
+	// bytes.
+	layout32 = typeLayout{wordSize: 4, maxAlign: 4}
+
+	// layout64 is the layout of the 64-bit architectures, like amd64 and arm64.
+	layout64 = typeLayout{wordSize: 8, maxAlign: 8}
+)
+
+// primitiveSizes contains the sizes of the primitive kinds whose width doesn't depend on the architecture.
+var primitiveSizes = map[PrimitiveKind]int64{
+	PrimitiveKindBool:       1,
+	PrimitiveKindByte:       1,
+	PrimitiveKindInt8:       1,
+	PrimitiveKindUint8:      1,
+	PrimitiveKindInt16:      2,
+	PrimitiveKindUint16:     2,
+	PrimitiveKindRune:       4,
+	PrimitiveKindInt32:      4,
+	PrimitiveKindUint32:     4,
+	PrimitiveKindFloat32:    4,
+	PrimitiveKindInt64:      8,
+	PrimitiveKindUint64:     8,
+	PrimitiveKindFloat64:    8,
+	PrimitiveKindComplex64:  8,
+	PrimitiveKindComplex128: 16,
+}
+
+// MinSize returns the size in bytes of the struct's values on the 32-bit architectures, which is the smallest size
+// among the architectures supported by the gc compiler. The QualTypes are sized using their `Underlying` definitions
+// filled by the deep resolution, an error is returned when they're not resolved. When MinSize and MaxSize differ, the
+// struct's layout depends on the platform, e.g. because it contains an int or a pointer, so it shouldn't be copied
+// as is into a binary format.
+func (t StructType) MinSize() (int64, error) {
+	return layout32.sizeof(t.Type())
+}
+
+// MaxSize returns the size in bytes of the struct's values on the 64-bit architectures, which is the largest size
+// among the architectures supported by the gc compiler. See `StructType.MinSize`.
+func (t StructType) MaxSize() (int64, error) {
+	return layout64.sizeof(t.Type())
+}
+
+func (l typeLayout) sizeof(typ Type) (int64, error) {
+	switch {
+	case typ.PrimitiveType != nil:
+		if size, ok := primitiveSizes[typ.PrimitiveType.Kind]; ok {
+			return size, nil
+		}
+		if typ.PrimitiveType.Kind == PrimitiveKindString || typ.PrimitiveType.Kind == PrimitiveKindError {
+			return 2 * l.wordSize, nil
+		}
+		return l.wordSize, nil
+	case typ.QualType != nil:
+		if typ.QualType.Package == "unsafe" && typ.QualType.Name == "Pointer" {
+			return l.wordSize, nil
+		}
+		if typ.QualType.Underlying == nil {
+			return 0, fmt.Errorf("cannot compute the size of %s, it's not resolved", typ.String(""))
+		}
+		return l.sizeof(*typ.QualType.Underlying)
+	case typ.PtrType != nil, typ.MapType != nil, typ.ChanType != nil, typ.FuncType != nil:
+		return l.wordSize, nil
+	case typ.SliceType != nil:
+		return 3 * l.wordSize, nil
+	case typ.InterfaceType != nil:
+		return 2 * l.wordSize, nil
+	case typ.ArrayType != nil:
+		elemSize, err := l.sizeof(typ.ArrayType.Elem)
+		if err != nil {
+			return 0, err
+		}
+		return int64(typ.ArrayType.Len) * elemSize, nil
+	case typ.StructType != nil:
+		return l.structSize(*typ.StructType)
+	}
+	return 0, fmt.Errorf("cannot compute the size of %s", typ.String(""))
+}
+
+// structSize returns the size of the struct, including the padding inserted to align its fields.
+func (l typeLayout) structSize(s StructType) (int64, error) {
+	offset, lastSize := int64(0), int64(0)
+	for _, field := range s.Fields {
+		size, err := l.sizeof(field.Type)
+		if err != nil {
+			return 0, fmt.Errorf("cannot compute the size of field %s: %w", field.Name, err)
+		}
+		align, err := l.alignof(field.Type)
+		if err != nil {
+			return 0, fmt.Errorf("cannot compute the alignment of field %s: %w", field.Name, err)
+		}
+		offset = alignTo(offset, align) + size
+		lastSize = size
+	}
+	// like the gc compiler, a zero-sized final field is padded, so a pointer to it doesn't point past the struct.
+	if offset > 0 && lastSize == 0 {
+		offset++
+	}
+
+	align, err := l.alignof(s.Type())
+	if err != nil {
+		return 0, err
+	}
+	return alignTo(offset, align), nil
+}
+
+func (l typeLayout) alignof(typ Type) (int64, error) {
+	switch {
+	case typ.PrimitiveType != nil:
+		size, err := l.sizeof(typ)
+		if err != nil {
+			return 0, err
+		}
+		switch typ.PrimitiveType.Kind {
+		case PrimitiveKindComplex64, PrimitiveKindComplex128:
+			// the complex numbers are aligned like their real and imaginary parts.
+			size /= 2
+		case PrimitiveKindString, PrimitiveKindError:
+			size = l.wordSize
+		}
+		if size > l.maxAlign {
+			return l.maxAlign, nil
+		}
+		return size, nil
+	case typ.QualType != nil && typ.QualType.Underlying != nil:
+		return l.alignof(*typ.QualType.Underlying)
+	case typ.ArrayType != nil:
+		return l.alignof(typ.ArrayType.Elem)
+	case typ.StructType != nil:
+		align := int64(1)
+		for _, field := range typ.StructType.Fields {
+			fieldAlign, err := l.alignof(field.Type)
+			if err != nil {
+				return 0, err
+			}
+			if fieldAlign > align {
+				align = fieldAlign
+			}
+		}
+		return align, nil
+	}
+	if _, err := l.sizeof(typ); err != nil {
+		return 0, err
+	}
+	return l.wordSize, nil
+}
+
+// alignTo rounds `offset` up to a multiple of `align`.
+func alignTo(offset, align int64) int64 {
+	return (offset + align - 1) / align * align
+}
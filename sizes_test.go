@@ -0,0 +1,49 @@
+package gotype
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructSizes(t *testing.T) {
+	types, err := NewGenerator(WithDeepResolution()).GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: testdataPackage + "/sizes", Name: "Layout"},
+		TypeSpec{PackagePath: testdataPackage + "/sizes", Name: "Trailing"},
+	)
+	require.NoError(t, err)
+
+	minSize, err := types[0].StructType.MinSize()
+	require.NoError(t, err)
+	assert.Equal(t, int64(64), minSize)
+	maxSize, err := types[0].StructType.MaxSize()
+	require.NoError(t, err)
+	assert.Equal(t, int64(104), maxSize)
+
+	minSize, err = types[1].StructType.MinSize()
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), minSize)
+
+	types, err = NewGenerator().GenerateTypesFromSpecs(TypeSpec{PackagePath: testdataPackage + "/sizes", Name: "Layout"})
+	require.NoError(t, err)
+	_, err = types[0].StructType.MaxSize()
+	assert.EqualError(t, err,
+		"cannot compute the size of field K: cannot compute the size of time.Month, it's not resolved")
+}
+
+func TestPlatformDependentWidth(t *testing.T) {
+	spec := TypeSpec{PackagePath: testdataPackage + "/sizes", Name: "Layout"}
+	types, err := NewGenerator(WithConfig(Config{Env: []string{"GOARCH=386"}})).GenerateTypesFromSpecs(spec)
+	require.NoError(t, err)
+	assert.Equal(t, 32, types[0].StructType.Fields[3].Type.SliceType.Elem.PrimitiveType.Bits)
+	assert.Equal(t, 0, types[0].StructType.Fields[1].Type.PrimitiveType.Bits)
+
+	types, err = NewGenerator(WithConfig(Config{Env: []string{"GOARCH=arm64"}})).GenerateTypesFromSpecs(spec)
+	require.NoError(t, err)
+	assert.Equal(t, 64, types[0].StructType.Fields[3].Type.SliceType.Elem.PrimitiveType.Bits)
+
+	types, err = NewGenerator().GenerateTypesFromSpecs(spec)
+	require.NoError(t, err)
+	assert.Equal(t, 0, types[0].StructType.Fields[3].Type.SliceType.Elem.PrimitiveType.Bits)
+}
@@ -0,0 +1,174 @@
+package gotype
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SourcePackagePath is the package path of the source code analyzed by AnalyzeSource, like the go tool names the
+// packages built from a list of files.
+const SourcePackagePath = "command-line-arguments"
+
+// sourceFilename is the name of the file containing the source code analyzed by AnalyzeSource.
+const sourceFilename = "source.go"
+
+// defaultSourcePackageName is the package name of the source code without a package clause.
+const defaultSourcePackageName = "main"
+
+// PackageModel contains the declarations of a package.
+type PackageModel struct {
+	// Name contains the name of the package.
+	Name string
+
+	// Path contains the package path.
+	Path string
+
+	// Imports contains the packages imported by the package, sorted by their names.
+	Imports []Import
+
+	// Declarations contains the declarations of the package, in the order of their declaration.
+	Declarations []Declaration
+}
+
+// AnalyzeSource analyzes the Golang's source code `src` as a standalone package. See `TypeGenerator.AnalyzeSource` for
+// the details.
+func AnalyzeSource(src []byte, imports map[string]string) (PackageModel, error) {
+	return defaultAstTypeGenerator.AnalyzeSource(src, imports)
+}
+
+func (f *astTypeGenerator) AnalyzeSource(src []byte, imports map[string]string) (PackageModel, error) {
+	f = f.fork()
+	src, err := f.prepareSource(src, imports)
+	if err != nil {
+		return PackageModel{}, err
+	}
+
+	fileAst, err := parser.ParseFile(token.NewFileSet(), sourceFilename, src, 0)
+	if err != nil {
+		return PackageModel{}, fmt.Errorf("cannot parse go code: %w", err)
+	}
+
+	// the declarations of the source code may refer to the packages found by the generator's SourceFinder.
+	f.sourceFinder = NewChainFinder(&sourceCodeFinder{src: src}, f.sourceFinder)
+	declarations, err := f.GenerateFromSpecs(f.fileSpecs(SourcePackagePath, fileAst)...)
+	if err != nil {
+		return PackageModel{}, err
+	}
+
+	model := PackageModel{Name: fileAst.Name.Name, Path: SourcePackagePath, Declarations: declarations}
+	for _, importSpec := range fileAst.Imports {
+		importPath, err := strconv.Unquote(importSpec.Path.Value)
+		if err != nil {
+			return PackageModel{}, fmt.Errorf("invalid import path %s: %w", importSpec.Path.Value, err)
+		}
+		name := f.getImportNameFromPackagePath(importPath)
+		if importSpec.Name != nil {
+			name = importSpec.Name.Name
+		}
+		model.Imports = append(model.Imports, Import{Name: name, Package: importPath})
+	}
+	sort.SliceStable(model.Imports, func(i, j int) bool { return model.Imports[i].Name < model.Imports[j].Name })
+	return model, nil
+}
+
+// prepareSource prepends the package clause to `src` when it's missing, and adds the `imports`, keyed by their names,
+// which aren't imported by `src` already. They're added on the line of the package clause, so the positions of the
+// declarations are kept.
+func (f *astTypeGenerator) prepareSource(src []byte, imports map[string]string) ([]byte, error) {
+	fset := token.NewFileSet()
+	if _, err := parser.ParseFile(fset, sourceFilename, src, parser.PackageClauseOnly); err != nil {
+		src = append([]byte("package "+defaultSourcePackageName+";"), src...)
+	}
+	fileAst, err := parser.ParseFile(fset, sourceFilename, src, parser.ImportsOnly)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse go code: %w", err)
+	}
+
+	imported := make(map[string]struct{})
+	for _, importSpec := range fileAst.Imports {
+		if importSpec.Name != nil {
+			imported[importSpec.Name.Name] = struct{}{}
+		} else if importPath, err := strconv.Unquote(importSpec.Path.Value); err == nil {
+			imported[f.getImportNameFromPackagePath(importPath)] = struct{}{}
+		}
+	}
+
+	names := make([]string, 0, len(imports))
+	for name := range imports {
+		if _, ok := imported[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var clause strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&clause, "; import %s %s", name, strconv.Quote(imports[name]))
+	}
+	end := fset.Position(fileAst.Name.End()).Offset
+	prepared := make([]byte, 0, len(src)+clause.Len())
+	prepared = append(prepared, src[:end]...)
+	prepared = append(prepared, clause.String()...)
+	return append(prepared, src[end:]...), nil
+}
+
+// fileSpecs returns the Specs of the declarations of the file belonging to the package `packagePath`, in the order of
+// their declaration. The blank declarations and the init functions are skipped.
+func (f *astTypeGenerator) fileSpecs(packagePath string, fileAst *ast.File) []Spec {
+	specs := make([]Spec, 0)
+	add := func(name string, kind SpecKind) {
+		if name != "_" {
+			specs = append(specs, Spec{PackagePath: packagePath, Name: name, Kind: kind})
+		}
+	}
+
+	for _, decl := range fileAst.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			receiver := f.getReceiverTypeName(decl)
+			switch {
+			case receiver != "":
+				add(receiver+"."+decl.Name.Name, SpecKindMethod)
+			case decl.Name.Name != "init":
+				add(decl.Name.Name, SpecKindFunc)
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					add(spec.Name.Name, SpecKindType)
+				case *ast.ValueSpec:
+					kind := SpecKindVar
+					if decl.Tok == token.CONST {
+						kind = SpecKindConst
+					}
+					for _, name := range spec.Names {
+						add(name.Name, kind)
+					}
+				}
+			}
+		}
+	}
+	return specs
+}
+
+// sourceCodeFinder finds the package of the source code analyzed by AnalyzeSource.
+type sourceCodeFinder struct {
+	src []byte
+}
+
+func (s *sourceCodeFinder) GetPackageSourceFiles(packagePath string) ([]string, error) {
+	if packagePath != SourcePackagePath {
+		return nil, fmt.Errorf("cannot find package %s: %w", packagePath, ErrPackageNotFound)
+	}
+	return []string{sourceFilename}, nil
+}
+
+func (s *sourceCodeFinder) ReadSourceFile(filename string) ([]byte, error) {
+	return s.src, nil
+}
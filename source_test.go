@@ -0,0 +1,55 @@
+package gotype
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeSource(t *testing.T) {
+	src := `type User struct {
+	Name    string
+	Created time.Time
+	Raw     json.RawMessage
+}
+
+func (u *User) Greet(greeting string) string { return greeting + u.Name }
+
+const Limit = 10
+
+var _ = Limit
+
+func init() {}
+`
+	model, err := AnalyzeSource([]byte(src), map[string]string{"json": "encoding/json", "time": "time"})
+	require.NoError(t, err)
+	assert.Equal(t, "main", model.Name)
+	assert.Equal(t, SourcePackagePath, model.Path)
+	assert.Equal(t, []Import{{Name: "json", Package: "encoding/json"}, {Name: "time", Package: "time"}}, model.Imports)
+
+	require.Len(t, model.Declarations, 3)
+	user := model.Declarations[0]
+	assert.Equal(t, Spec{PackagePath: SourcePackagePath, Name: "User", Kind: SpecKindType}, user.Spec)
+	assert.Equal(t, "struct {\n    Name string\n    Created time.Time\n    Raw json.RawMessage\n}", user.Type.String(""))
+	assert.Equal(t, "source.go", user.Position.Filename)
+	assert.Equal(t, 1, user.Position.Line)
+
+	greet := model.Declarations[1]
+	assert.Equal(t, "User.Greet", greet.Spec.Name)
+	assert.Equal(t, "func(greeting string) (out1 string)", greet.Type.String(""))
+	require.NotNil(t, greet.Receiver)
+	assert.Equal(t, "main.User", greet.Receiver.Type.Type().String(""))
+	assert.Equal(t, 7, greet.Position.Line)
+
+	assert.Equal(t, "10", model.Declarations[2].Value)
+
+	model, err = AnalyzeSource([]byte("package model\n\nimport \"time\"\n\ntype Event struct{ At time.Time }\n"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "model", model.Name)
+	assert.Equal(t, "struct {\n    At time.Time\n}", model.Declarations[0].Type.String(""))
+
+	_, err = AnalyzeSource([]byte("type Broken struct {"), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot parse go code")
+}
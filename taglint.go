@@ -0,0 +1,384 @@
+package gotype
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DefaultTagOptions contains the options known for the struct tag keys of the standard library's encoders and of the
+// common YAML libraries, checked by `TypeGenerator.LintStructTags` by default.
+var DefaultTagOptions = map[string][]string{
+	"json": {"omitempty", "omitzero", "string"},
+	"xml":  {"attr", "chardata", "cdata", "innerxml", "comment", "any", "omitempty"},
+	"yaml": {"omitempty", "flow", "inline"},
+}
+
+// TagIssue describes a problem of a struct tag found by `TypeGenerator.LintStructTags`.
+type TagIssue struct {
+	// Struct contains the type declaring the struct.
+	Struct QualType
+
+	// Field contains the name of the field whose tag has the problem. The fields of the anonymous structs nested
+	// inside the type are qualified by the names of their enclosing fields, like "Server.Port".
+	Field string
+
+	// Key contains the tag key having the problem, like "json". It's empty when the whole tag is malformed.
+	Key string
+
+	// Message describes the problem.
+	Message string
+
+	// Position contains the location of the tag, or of the field when the problem isn't inside its tag, like a JSON
+	// name used by several fields.
+	Position token.Position
+}
+
+func (i TagIssue) String() string {
+	return fmt.Sprintf("%s: %s.%s: %s", i.Position, i.Struct.Name, i.Field, i.Message)
+}
+
+// LintStructTags validates the struct tags of the types declared inside the packages. See
+// `TypeGenerator.LintStructTags` for the details.
+func LintStructTags(tagOptions map[string][]string, packagePatterns ...string) ([]TagIssue, error) {
+	return defaultAstTypeGenerator.LintStructTags(tagOptions, packagePatterns...)
+}
+
+func (f *astTypeGenerator) LintStructTags(
+	tagOptions map[string][]string,
+	packagePatterns ...string,
+) ([]TagIssue, error) {
+	f = f.fork()
+	packages, err := f.expandPackagePatterns(packagePatterns...)
+	if err != nil {
+		return nil, err
+	}
+	if tagOptions == nil {
+		tagOptions = DefaultTagOptions
+	}
+
+	linter := &tagLinter{generator: f, tagOptions: tagOptions, issues: make([]TagIssue, 0)}
+	for _, packagePath := range packages {
+		goSources, err := f.getPackageSourceFiles(packagePath)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, source := range goSources {
+			if strings.HasSuffix(source, "_test.go") {
+				continue
+			}
+			fileAst, err := f.parseAstFile(source)
+			if err != nil {
+				return nil, err
+			}
+
+			importMap := f.generateImportMap(packagePath, fileAst)
+			for _, decl := range fileAst.Decls {
+				genDecl, ok := decl.(*ast.GenDecl)
+				if !ok || genDecl.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range genDecl.Specs {
+					typeSpec := spec.(*ast.TypeSpec)
+					if structType, ok := typeSpec.Type.(*ast.StructType); ok {
+						qualType := f.rewriteQualType(QualType{Package: packagePath, Name: typeSpec.Name.Name})
+						scope := tagScope{qualType: qualType, packagePath: packagePath, importMap: importMap}
+						if err := linter.lintStruct(structType, scope, ""); err != nil {
+							return nil, err
+						}
+					}
+				}
+			}
+		}
+	}
+	return linter.issues, nil
+}
+
+// tagLinter collects the TagIssues of the structs.
+type tagLinter struct {
+	generator  *astTypeGenerator
+	tagOptions map[string][]string
+	issues     []TagIssue
+}
+
+// tagScope is the declaration whose structs are linted.
+type tagScope struct {
+	qualType    QualType
+	packagePath string
+	importMap   map[string]string
+}
+
+// jsonField is a field encoded by encoding/json, named `name`.
+type jsonField struct {
+	name  string
+	field string
+	pos   token.Pos
+}
+
+// lintStruct lints the tags of the fields of `structType`, and of the anonymous structs nested inside it. The names of
+// its fields are prefixed by `prefix`.
+func (l *tagLinter) lintStruct(structType *ast.StructType, scope tagScope, prefix string) error {
+	direct := make([]jsonField, 0)
+	promoted := make([]jsonField, 0)
+	for _, field := range structType.Fields.List {
+		names := make([]string, 0, len(field.Names))
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+		if len(field.Names) == 0 {
+			names = append(names, embeddedFieldName(field.Type))
+		}
+
+		tag, tagOk := l.lintTag(field, scope, prefix+names[0])
+		jsonName, jsonOk := "", true
+		if tagOk {
+			jsonName, jsonOk = jsonFieldName(tag)
+		}
+
+		for _, name := range names {
+			switch {
+			case !jsonOk:
+			case len(field.Names) == 0 && jsonName == "":
+				fields, isStruct, err := l.promotedJSONFields(field, scope)
+				if err != nil {
+					return err
+				}
+				promoted = append(promoted, fields...)
+				if !isStruct && ast.IsExported(name) {
+					direct = append(direct, jsonField{name: name, field: prefix + name, pos: field.Pos()})
+				}
+			case ast.IsExported(name):
+				if jsonName == "" {
+					jsonName = name
+				}
+				direct = append(direct, jsonField{name: jsonName, field: prefix + name, pos: field.Pos()})
+			}
+		}
+
+		if nested, ok := field.Type.(*ast.StructType); ok {
+			for _, name := range names {
+				if err := l.lintStruct(nested, scope, prefix+name+"."); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	l.lintJSONNames(direct, promoted, scope)
+	return nil
+}
+
+// lintTag reports the problems of the tag of `field`, and returns the tag when it's well-formed.
+func (l *tagLinter) lintTag(field *ast.Field, scope tagScope, fieldName string) (string, bool) {
+	if field.Tag == nil {
+		return "", true
+	}
+
+	report := func(key, format string, args ...interface{}) {
+		l.issues = append(l.issues, TagIssue{
+			Struct:   scope.qualType,
+			Field:    fieldName,
+			Key:      key,
+			Message:  fmt.Sprintf(format, args...),
+			Position: l.generator.position(field.Tag.Pos()),
+		})
+	}
+
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		report("", "malformed tag literal %s", field.Tag.Value)
+		return "", false
+	}
+	pairs, err := parseStructTag(tag)
+	if err != nil {
+		report("", "%v", err)
+		return "", false
+	}
+
+	for _, pair := range pairs {
+		known, ok := l.tagOptions[pair.key]
+		if !ok {
+			continue
+		}
+		options := strings.Split(pair.value, ",")[1:]
+		for _, option := range options {
+			if !containsString(known, option) {
+				report(pair.key, "unknown option %q of key %q", option, pair.key)
+			}
+		}
+	}
+	return tag, true
+}
+
+// promotedJSONFields returns the fields promoted by the embedded struct `field`, which is declared inside the package
+// or inside an imported package. Only the fields declared directly by the embedded struct are promoted. It returns
+// false when the embedded type isn't a struct, or isn't known.
+func (l *tagLinter) promotedJSONFields(field *ast.Field, scope tagScope) ([]jsonField, bool, error) {
+	expr := field.Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+
+	spec := TypeSpec{}
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		if types.Universe.Lookup(expr.Name) != nil {
+			return nil, false, nil
+		}
+		spec = TypeSpec{PackagePath: scope.packagePath, Name: expr.Name}
+	case *ast.SelectorExpr:
+		x, ok := expr.X.(*ast.Ident)
+		if !ok || scope.importMap[x.Name] == "" {
+			return nil, false, nil
+		}
+		spec = TypeSpec{PackagePath: scope.importMap[x.Name], Name: expr.Sel.Name}
+	default:
+		return nil, false, nil
+	}
+
+	generated, err := l.generator.generateTypesFromSpecs([]TypeSpec{spec})
+	if err != nil {
+		return nil, false, err
+	}
+	if generated[0].StructType == nil {
+		return nil, false, nil
+	}
+
+	fields := make([]jsonField, 0)
+	for _, promoted := range generated[0].StructType.Fields {
+		jsonName, ok := jsonFieldName(string(promoted.Tag))
+		if !ok || !ast.IsExported(promoted.Name) || promoted.Embedded {
+			continue
+		}
+		if jsonName == "" {
+			jsonName = promoted.Name
+		}
+		fieldName := embeddedFieldName(field.Type) + "." + promoted.Name
+		fields = append(fields, jsonField{name: jsonName, field: fieldName, pos: field.Pos()})
+	}
+	return fields, true, nil
+}
+
+// lintJSONNames reports the JSON names used by several fields at the same depth, which are dropped by encoding/json.
+// The promoted fields shadowed by the direct ones are allowed.
+func (l *tagLinter) lintJSONNames(direct, promoted []jsonField, scope tagScope) {
+	report := func(field jsonField, format string, args ...interface{}) {
+		l.issues = append(l.issues, TagIssue{
+			Struct:   scope.qualType,
+			Field:    field.field,
+			Key:      "json",
+			Message:  fmt.Sprintf(format, args...),
+			Position: l.generator.position(field.pos),
+		})
+	}
+
+	seen := make(map[string]jsonField)
+	for _, field := range direct {
+		if previous, ok := seen[field.name]; ok {
+			report(field, "json name %q is also used by field %s", field.name, previous.field)
+			continue
+		}
+		seen[field.name] = field
+	}
+
+	seenPromoted := make(map[string]jsonField)
+	for _, field := range promoted {
+		if _, ok := seen[field.name]; ok {
+			continue
+		}
+		if previous, ok := seenPromoted[field.name]; ok {
+			report(field, "json name %q of promoted field %s conflicts with promoted field %s, both are dropped",
+				field.name, field.field, previous.field)
+			continue
+		}
+		seenPromoted[field.name] = field
+	}
+}
+
+// structTagPair is a key-value pair of a struct tag.
+type structTagPair struct {
+	key   string
+	value string
+}
+
+// parseStructTag parses the conventional format of the struct tags, like `json:"name,omitempty" xml:"name"`, which is
+// the format understood by `reflect.StructTag.Get`.
+func parseStructTag(tag string) ([]structTagPair, error) {
+	pairs := make([]structTagPair, 0)
+	for {
+		tag = strings.TrimLeft(tag, " ")
+		if tag == "" {
+			return pairs, nil
+		}
+
+		i := 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 {
+			return nil, fmt.Errorf("bad syntax for struct tag pair")
+		}
+		key := tag[:i]
+		if i+1 >= len(tag) || tag[i] != ':' {
+			return nil, fmt.Errorf("bad syntax for struct tag key %q, missing colon", key)
+		}
+		if tag[i+1] != '"' {
+			return nil, fmt.Errorf("bad syntax for struct tag value of key %q, it isn't quoted", key)
+		}
+
+		quoted, err := strconv.QuotedPrefix(tag[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("bad syntax for struct tag value of key %q: %w", key, err)
+		}
+		value, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, fmt.Errorf("bad syntax for struct tag value of key %q: %w", key, err)
+		}
+		for _, pair := range pairs {
+			if pair.key == key {
+				return nil, fmt.Errorf("struct tag key %q is repeated", key)
+			}
+		}
+		pairs = append(pairs, structTagPair{key: key, value: value})
+		tag = tag[i+1+len(quoted):]
+	}
+}
+
+// jsonFieldName returns the name of a field set by its json tag, if any. It returns false when the field is skipped
+// by encoding/json, using the "-" name.
+func jsonFieldName(tag string) (string, bool) {
+	value := reflect.StructTag(tag).Get("json")
+	if value == "-" {
+		return "", false
+	}
+	return strings.Split(value, ",")[0], true
+}
+
+// embeddedFieldName returns the name of an embedded field, which is the name of its type.
+func embeddedFieldName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedFieldName(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	case *ast.IndexExpr:
+		return embeddedFieldName(e.X)
+	case *ast.IndexListExpr:
+		return embeddedFieldName(e.X)
+	}
+	return types.ExprString(expr)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
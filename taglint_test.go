@@ -0,0 +1,46 @@
+package gotype
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintStructTags(t *testing.T) {
+	issues, err := LintStructTags(nil, testdataPackage+"/taglint")
+	require.NoError(t, err)
+
+	messages := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		assert.Equal(t, "Order", issue.Struct.Name)
+		messages = append(messages, issue.Field+": "+issue.Message)
+	}
+	assert.Equal(t, []string{
+		`Price: unknown option "strnig" of key "json"`,
+		`Quantity: struct tag key "json" is repeated`,
+		`Note: bad syntax for struct tag value of key "json", it isn't quoted`,
+		`Shipping.City: unknown option "atr" of key "xml"`,
+		`Shipping.Town: json name "city" is also used by field Shipping.Zip`,
+		`Title: json name "name" is also used by field Name`,
+		`Audit.ID: json name "id" of promoted field Audit.ID conflicts with promoted field Base.ID, both are dropped`,
+	}, messages)
+	assert.Equal(t, 24, issues[0].Position.Line)
+	assert.Equal(t, "json", issues[0].Key)
+	assert.Equal(t, "", issues[1].Key)
+
+	// only the keys of the configured options are checked.
+	issues, err = LintStructTags(map[string][]string{"custom": {"a"}}, testdataPackage+"/taglint")
+	require.NoError(t, err)
+	messages = messages[:0]
+	for _, issue := range issues {
+		if issue.Key != "json" {
+			messages = append(messages, issue.Field+": "+issue.Message)
+		}
+	}
+	assert.Equal(t, []string{
+		`Quantity: struct tag key "json" is repeated`,
+		`Note: bad syntax for struct tag value of key "json", it isn't quoted`,
+		`Extra: unknown option "b" of key "custom"`,
+	}, messages)
+}
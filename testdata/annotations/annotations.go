@@ -0,0 +1,14 @@
+package annotations
+
+type User struct {
+	// Name is the user's name.
+	// +gotype:min=1
+	// +gotype:trim
+	Name string // required, max 64 chars
+
+	// +kubebuilder:validation:Minimum=18
+	Age int // +gotype:max=150
+
+	Email string // format: email
+	Notes string
+}
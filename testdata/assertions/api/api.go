@@ -0,0 +1,11 @@
+package api
+
+type Store interface {
+	Get(key string) (string, error)
+}
+
+type Closer interface {
+	Close() error
+}
+
+type Empty interface{}
@@ -0,0 +1,17 @@
+package impl
+
+type MemoryStore struct{}
+
+func (s MemoryStore) Get(key string) (string, error) { return "", nil }
+
+func (s *MemoryStore) Close() error { return nil }
+
+type FileStore struct{}
+
+func (s *FileStore) Get(key string) (string, error) { return "", nil }
+
+type ID int
+
+type unexportedStore struct{}
+
+func (s unexportedStore) Get(key string) (string, error) { return "", nil }
@@ -0,0 +1,21 @@
+package bloat
+
+type Repository interface {
+	Get(id int) (string, error)
+	Put(id int, value string) error
+	Delete(id int) error
+}
+
+type Getter interface {
+	Get(id int) (string, error)
+}
+
+func Show(repo Repository, getter Getter) {
+	repo.Get(1)
+	getter.Get(1)
+}
+
+func Replace(repo Repository) {
+	repo.Delete(1)
+	repo.Put(1, "")
+}
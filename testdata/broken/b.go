@@ -0,0 +1,5 @@
+package broken
+
+type Valid struct {
+	Name string
+}
@@ -0,0 +1,3 @@
+package buildtags
+
+type Common struct{}
@@ -0,0 +1,5 @@
+//go:build special
+
+package buildtags
+
+type Special struct{}
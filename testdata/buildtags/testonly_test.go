@@ -0,0 +1,3 @@
+package buildtags
+
+type TestOnly struct{}
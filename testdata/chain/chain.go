@@ -0,0 +1,13 @@
+package chain
+
+import "github.com/armantarkhanian/gotype/testdata/chain/inner"
+
+type Holder struct {
+	Field A
+}
+
+type A = B
+
+type B C
+
+type C inner.Shape
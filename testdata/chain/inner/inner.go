@@ -0,0 +1,7 @@
+package inner
+
+type Shape = shape
+
+type shape struct {
+	Sides int
+}
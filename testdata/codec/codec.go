@@ -0,0 +1,36 @@
+package codec
+
+import "time"
+
+type Level int
+
+type Point struct {
+	X int
+	Y int
+}
+
+type Event struct {
+	ID       string `msgpack:"id"`
+	Level    Level  `msg:"level"`
+	Payload  []byte
+	Labels   map[string]string
+	Points   []Point
+	Origin   *Point
+	Digest   [4]uint8
+	At       time.Time
+	Ignored  string `msgpack:"-"`
+	internal int
+}
+
+type Packet struct {
+	Seq      uint32
+	Level    Level
+	Ratio    float64
+	Name     string
+	Payload  []byte
+	Points   []Point
+	Origin   *Point
+	Flags    [2]bool
+	At       time.Time
+	internal int
+}
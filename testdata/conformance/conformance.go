@@ -0,0 +1,15 @@
+package conformance
+
+type Celsius float64
+
+func (c Celsius) String() string { return "" }
+
+func (c *Celsius) MarshalJSON() ([]byte, error) { return nil, nil }
+
+func (c *Celsius) Read(buf []uint8) (int, error) { return 0, nil }
+
+type Temperatures []Celsius
+
+func (t Temperatures) Len() int           { return len(t) }
+func (t Temperatures) Less(i, j int) bool { return t[i] < t[j] }
+func (t Temperatures) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
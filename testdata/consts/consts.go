@@ -0,0 +1,78 @@
+package consts
+
+import (
+	"math/bits"
+	"time"
+	"unsafe"
+
+	u "github.com/armantarkhanian/gotype/testdata/consts/units"
+)
+
+type Weekday int
+
+const (
+	Sunday Weekday = iota
+	Monday
+	Tuesday
+	_
+	Thursday
+)
+
+const (
+	FlagA = 1 << iota
+	FlagB
+	FlagC
+)
+
+const (
+	BufferSize         = 4 * u.KB
+	Name               = u.Prefix + "s"
+	NameLen            = len(Name)
+	Half               = float64(1) / 2
+	Quotient           = 7 / 2
+	Ratio      float64 = 7
+	Timeout            = 3 * time.Second
+	Enabled            = BufferSize > 1024 && !false
+)
+
+type Celsius float64
+
+const (
+	HalfDegree Celsius = 1
+	Third              = HalfDegree / 3
+	MaxU32             = ^uint32(0)
+	MaxByte            = ^byte(0)
+	MinusOne           = ^0
+)
+
+type Complement [^uint8(0)]byte
+
+type Buffer struct {
+	Data  [BufferSize / 1024]byte
+	Flags [FlagC | FlagA]bool
+}
+
+type Key struct {
+	Data  [u.MaxKeyLen]byte
+	Words [bits.UintSize / 8]byte
+}
+
+var key Key
+
+var (
+	table    [3]int
+	tablePtr *[5]int
+)
+
+type Sized struct {
+	Word   [unsafe.Sizeof(uint64(0))]byte
+	Key    [unsafe.Sizeof(key)]byte
+	Align  [unsafe.Alignof(Buffer{})]byte
+	Halves [2 * unsafe.Sizeof(int32(0))]byte
+	Words  [unsafe.Sizeof(uint(0)) / 2]byte
+}
+
+type Lengths struct {
+	Table [len(table) + cap(table)]byte
+	Ptr   [len(tablePtr)]byte
+}
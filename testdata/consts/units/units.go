@@ -0,0 +1,7 @@
+package units
+
+const KB = 1 << 10
+
+const MaxKeyLen = KB / 16
+
+const Prefix = "unit"
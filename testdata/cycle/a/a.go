@@ -0,0 +1,12 @@
+package a
+
+import "github.com/armantarkhanian/gotype/testdata/cycle/b"
+
+// A and b.B refer to each other's packages, which can't be compiled but can still be parsed.
+type A struct {
+	B b.B
+}
+
+type C struct {
+	Name string
+}
@@ -0,0 +1,7 @@
+package b
+
+import "github.com/armantarkhanian/gotype/testdata/cycle/a"
+
+type B struct {
+	C a.C
+}
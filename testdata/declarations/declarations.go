@@ -0,0 +1,35 @@
+package declarations
+
+import (
+	"context"
+	"time"
+)
+
+// Client calls the remote service.
+type Client struct {
+	Timeout time.Duration
+}
+
+// Do sends the request.
+func (c *Client) Do(ctx context.Context, request string) (string, error) { return "", nil }
+
+// New creates a Client.
+func New(timeout time.Duration) *Client { return &Client{Timeout: timeout} }
+
+// Map applies fn to the items.
+func Map[T, U any](items []T, fn func(T) U) []U { return nil }
+
+type Level int
+
+const (
+	// LevelDebug is the most verbose level.
+	LevelDebug Level = iota
+	LevelInfo
+)
+
+const Ratio = 2 * 1.5
+
+// DefaultClient is used by default.
+var DefaultClient = &Client{Timeout: time.Second}
+
+var retries, delay = 3, time.Now()
@@ -0,0 +1,48 @@
+// Package docs is documented.
+package docs
+
+import "time"
+
+// Version is the version.
+const Version = "1.0"
+
+// Default is the default order.
+var Default = &Order{}
+
+// Status is the status of an Order.
+type Status int
+
+// Order is an order.
+type Order struct {
+	// ID identifies the order.
+	ID     int    `json:"id"`
+	Status Status // Status is the order's status.
+	Items  []Item
+	Placed time.Time
+	notes  string
+}
+
+// Total returns the total price.
+func (o *Order) Total() float64 { return 0 }
+
+func (o Order) count() int { return 0 }
+
+// Item is an item.
+type Item struct {
+	Name string
+}
+
+// Store stores the orders.
+type Store interface {
+	// Get returns an order.
+	Get(id int) (*Order, error)
+	close()
+}
+
+// Set is a set.
+type Set[T comparable] map[T]bool
+
+// NewOrder creates an Order.
+func NewOrder(items ...Item) *Order { return nil }
+
+func helper() {}
@@ -0,0 +1,34 @@
+package embedding
+
+import "io"
+
+// Ping and Pong embed each other, which isn't valid Go but can still be parsed.
+type Ping interface {
+	Pong
+}
+
+type Pong interface {
+	Ping
+}
+
+type Outer interface {
+	Middle
+}
+
+type Middle interface {
+	Inner
+}
+
+type Inner interface {
+	Close() error
+}
+
+type Base struct {
+	ID int
+}
+
+type Document struct {
+	io.Reader
+	*Base `json:"base"`
+	Title string
+}
@@ -0,0 +1,9 @@
+package a
+
+type Fetcher interface {
+	Fetch(url string) ([]byte, error)
+}
+
+type Pinger interface {
+	Ping() error
+}
@@ -0,0 +1,5 @@
+package b
+
+type Downloader interface {
+	Fetch(address string) ([]uint8, error)
+}
@@ -0,0 +1,17 @@
+package exported
+
+type User struct {
+	Name     string
+	password string
+}
+
+func (u User) Greet() string { return "hello " + u.Name }
+
+func (u *User) hash() string { return u.password }
+
+type Store interface {
+	Get(id string) (User, error)
+	lock()
+}
+
+type session struct{}
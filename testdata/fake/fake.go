@@ -0,0 +1,25 @@
+package fake
+
+import "time"
+
+type Status string
+
+type Address struct {
+	City   string
+	street string
+}
+
+type User struct {
+	ID        string `fake:"uuid"`
+	Contact   string `fake:"email"`
+	Name      string
+	Birthday  string `fake:"time=2006-01-02"`
+	Age       int
+	Score     float64
+	Active    bool
+	Status    Status
+	Address   *Address
+	Tags      []string
+	CreatedAt time.Time
+	Secret    string `fake:"-"`
+}
@@ -0,0 +1,5 @@
+package finders
+
+type Model struct {
+	ID ModelID
+}
@@ -0,0 +1,40 @@
+package generics
+
+import "github.com/armantarkhanian/gotype/testdata/generics/constraints"
+
+type Repository[K comparable, V any] interface {
+	Get(id K) (V, error)
+	List() []V
+}
+
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value *V
+}
+
+type Max[T constraints.Ordered] func(a, b T) T
+
+type Vector[T int | float64] []T
+
+type Mapper[T, U any] func(items []T, fn func(T) U) []U
+
+type Appender[T any] func(items []T, values ...T) []T
+
+type Index[K comparable, V any] struct {
+	Pairs map[K]Pair[K, *V]
+	Tree  *Tree[Pair[string, V]]
+}
+
+type Tree[T any] struct {
+	Value       T
+	Left, Right *Tree[T]
+}
+
+func (t *Tree[U]) Insert(value U) *Tree[U] { return t }
+
+func (t Tree[_]) Size() int { return 0 }
+
+type Option[T any] struct {
+	Value T
+	Valid bool
+}
@@ -0,0 +1,35 @@
+package usage
+
+import "github.com/armantarkhanian/gotype/testdata/generics"
+
+type User struct{}
+
+type Forest[T any] []generics.Tree[T]
+
+type Catalog struct {
+	Users  *generics.Tree[User]
+	Names  generics.Tree[string]
+	Others []generics.Tree[User]
+}
+
+func Build() generics.Tree[map[string]int] {
+	return generics.Tree[map[string]int]{}
+}
+
+type Profile struct {
+	Age   generics.Option[int]
+	Owner *generics.Tree[User]
+}
+
+type Cache struct {
+	Entries generics.Pair[string, *User]
+}
+
+type index struct {
+	Tree []int
+}
+
+// First indexes a value named like the generics package, which isn't an instantiation.
+func First(generics index, n int) int {
+	return generics.Tree[n]
+}
@@ -0,0 +1,46 @@
+package ifaces
+
+import "io"
+
+type Reader interface {
+	Read(p []byte) (n int, err error)
+}
+
+type ReadCloser interface {
+	Reader
+	io.Closer
+}
+
+type NamedReadCloser interface {
+	ReadCloser
+
+	// Name returns the name of the reader.
+	// It never returns an empty string.
+	Name() string // e.g. "stdin"
+
+}
+
+type Closer interface {
+	Close() error
+}
+
+type Diamond interface {
+	ReadCloser
+	NamedReadCloser
+	Closer
+}
+
+type Namer interface {
+	Name() int
+}
+
+type Conflict interface {
+	NamedReadCloser
+	Namer
+}
+
+type CodedError interface {
+	error
+	any
+	Code() int
+}
@@ -0,0 +1,31 @@
+package local
+
+type Server struct{}
+
+func Handler() {
+	type address struct {
+		Host string
+	}
+	type request struct {
+		ID   int
+		From address
+	}
+	_ = request{}
+}
+
+func (s *Server) Serve() {
+	if true {
+		type response struct {
+			Body []byte
+		}
+		_ = response{}
+	}
+}
+
+func Map[K comparable, V any]() {
+	type entry struct {
+		Key   K
+		Value V
+	}
+	_ = entry{}
+}
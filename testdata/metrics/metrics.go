@@ -0,0 +1,24 @@
+package metrics
+
+import (
+	"io"
+	"time"
+
+	"github.com/armantarkhanian/gotype/testdata/metrics/sub"
+)
+
+type Order struct {
+	ID     int
+	Items  []map[string]*sub.Item
+	At     time.Time
+	writer io.Writer
+}
+
+func (o *Order) Total() int { return 0 }
+
+func (o Order) Valid() bool { return true }
+
+type Store interface {
+	Get(id int) (Order, error)
+	Put(order Order) error
+}
@@ -0,0 +1,5 @@
+package sub
+
+type Item struct {
+	Price float64
+}
@@ -0,0 +1,22 @@
+package consumer
+
+import (
+	kv "github.com/armantarkhanian/gotype/testdata/minimize/store"
+)
+
+type Service struct {
+	store *kv.Store
+}
+
+func (s *Service) Lookup(key string) (string, error) {
+	return s.store.Get(key)
+}
+
+func Save(st *kv.Store, key string) error {
+	return st.Put(key, key)
+}
+
+func Fresh() {
+	fresh := &kv.Store{}
+	defer fresh.Close()
+}
@@ -0,0 +1,12 @@
+package store
+
+type Store struct{}
+
+// Get returns the value of the key.
+func (s *Store) Get(key string) (string, error) { return "", nil }
+
+func (s *Store) Put(key, value string) error { return nil }
+
+func (s Store) Delete(key string) error { return nil }
+
+func (s *Store) Close() error { return nil }
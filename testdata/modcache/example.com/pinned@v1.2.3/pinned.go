@@ -0,0 +1,9 @@
+package pinned
+
+import "example.com/pinned/sub"
+
+type Config struct {
+	Name    string
+	Retries int
+	Option  sub.Option
+}
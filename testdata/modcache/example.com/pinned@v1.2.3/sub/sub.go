@@ -0,0 +1,5 @@
+package sub
+
+type Option struct {
+	Enabled bool
+}
@@ -0,0 +1,20 @@
+package mongo
+
+import "time"
+
+type Audit struct {
+	CreatedAt time.Time  `bson:"created_at"`
+	UpdatedAt *time.Time `bson:"updated_at,omitempty"`
+}
+
+type Article struct {
+	Title    string            `bson:"title"`
+	Views    int64             `bson:"views"`
+	Rating   float64           `bson:"rating,omitempty"`
+	Tags     []string          `bson:"tags"`
+	Meta     map[string]string `bson:"meta,omitempty"`
+	Body     []byte            `bson:"body,omitempty"`
+	Draft    bool
+	Audit    Audit  `bson:"audit,inline"`
+	Internal string `bson:"-"`
+}
@@ -0,0 +1,8 @@
+package naming
+
+import "context"
+
+type Handler interface {
+	Handle(context.Context, string, error) (int, error)
+	Named(out1 int) (string, error)
+}
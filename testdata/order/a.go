@@ -0,0 +1,3 @@
+package order
+
+type First struct{}
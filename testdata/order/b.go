@@ -0,0 +1,5 @@
+package order
+
+type Second struct{}
+
+type Third int
@@ -0,0 +1,40 @@
+package orm
+
+import "time"
+
+type Company struct {
+	ID   uint
+	Name string
+}
+
+type Profile struct {
+	UserID uint
+	Bio    string
+}
+
+type Order struct {
+	ID     uint
+	UserID uint
+}
+
+type Role struct {
+	ID   uint
+	Name string
+}
+
+type User struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	Email     string    `gorm:"size:255;not null;uniqueIndex"`
+	FirstName string    `gorm:"index:idx_name"`
+	LastName  string    `gorm:"index:idx_name"`
+	Nickname  string    `db:"nick"`
+	Status    string    `gorm:"column:state;default:'active'"`
+	CreatedAt time.Time `gorm:"type:timestamptz"`
+	CompanyID uint
+	Company   Company
+	Profile   *Profile
+	Orders    []Order
+	Roles     []Role `gorm:"many2many:user_roles"`
+	Password  string `gorm:"-"`
+	cache     string
+}
@@ -0,0 +1,39 @@
+package pb
+
+import (
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type Role int32
+
+const (
+	Role_ROLE_UNSPECIFIED Role = 0
+	Role_ROLE_ADMIN       Role = 1
+)
+
+type User struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id        int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	FullName  string                 `protobuf:"bytes,2,opt,name=full_name,json=fullName,proto3" json:"full_name,omitempty"`
+	Role      Role                   `protobuf:"varint,3,opt,name=role,proto3,enum=pb.Role" json:"role,omitempty"`
+	Address   *Address               `protobuf:"bytes,4,opt,name=address,proto3" json:"address,omitempty"`
+	Previous  []*Address             `protobuf:"bytes,5,rep,name=previous,proto3" json:"previous,omitempty"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Ttl       *durationpb.Duration   `protobuf:"bytes,7,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	Tags      []string               `protobuf:"bytes,8,rep,name=tags,proto3" json:"tags,omitempty"`
+	Score     float32                `protobuf:"fixed32,9,opt,name=score,proto3" json:"score,omitempty"`
+	Legacy    string                 `protobuf:"bytes,10,opt,name=legacy,proto3" json:"legacy,omitempty"`
+}
+
+type Address struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	City string `protobuf:"bytes,1,opt,name=city,proto3" json:"city,omitempty"`
+}
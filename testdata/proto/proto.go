@@ -0,0 +1,28 @@
+package proto
+
+import "time"
+
+type Role uint8
+
+type Address struct {
+	City string
+}
+
+type User struct {
+	ID       int
+	Name     string `proto:"full_name"`
+	Role     Role
+	Address  Address
+	Previous []*Address
+	Created  time.Time `proto:"created_at"`
+	TTL      time.Duration
+	Tags     []string
+	Score    float64
+	Nickname string
+}
+
+type Broken struct {
+	ID      string
+	Name    string `proto:"full_name"`
+	Address string
+}
@@ -0,0 +1,25 @@
+package cycle
+
+type A struct {
+	b *B
+}
+
+type B struct {
+	c *C
+}
+
+type C struct {
+	a *A
+}
+
+func NewA(b *B) *A {
+	return &A{b: b}
+}
+
+func NewB(c *C) *B {
+	return &B{c: c}
+}
+
+func NewC(a *A) *C {
+	return &C{a: a}
+}
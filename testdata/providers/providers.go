@@ -0,0 +1,62 @@
+package providers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+)
+
+type Config struct {
+	DSN string
+}
+
+type Repository interface {
+	Find(id int) (string, error)
+}
+
+type SQLRepository struct {
+	db     *sql.DB
+	config Config
+}
+
+func (r *SQLRepository) Find(id int) (string, error) {
+	return "", errors.New("not found")
+}
+
+type Service struct {
+	repo   Repository
+	logger *log.Logger
+}
+
+type Option func(*Service)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+func NewService(repo Repository, logger *log.Logger, opts ...Option) *Service {
+	s := &Service{repo: repo, logger: logger}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func NewSQLRepository(db *sql.DB, config Config) (*SQLRepository, error) {
+	if config.DSN == "" {
+		return nil, errors.New("missing dsn")
+	}
+	return &SQLRepository{db: db, config: config}, nil
+}
+
+func Newsletter() string {
+	return ""
+}
+
+func NewPair() (int, int) {
+	return 0, 0
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+type Role uint8
+
+type User struct {
+	ID       int
+	Name     string `proto:"full_name"`
+	Role     Role
+	Active   bool
+	Avatar   []byte
+	Created  time.Time
+	Friends  []*User
+	Labels   map[string]string
+	password string
+}
+
+type GetUserRequest struct {
+	ID int
+}
+
+type ListUsersRequest struct {
+	Limit int32
+	TTL   time.Duration
+}
+
+type ListUsersResponse struct {
+	Users []User
+}
+
+type UserService interface {
+	GetUser(ctx context.Context, req *GetUserRequest) (*User, error)
+	ListUsers(context.Context, ListUsersRequest) (ListUsersResponse, error)
+}
+
+type Deleter interface {
+	Delete(id int) error
+}
+
+type Event struct {
+	Updates chan string
+}
+
+type Streamer interface {
+	Stream(ctx context.Context, req *Event) (*Event, error)
+}
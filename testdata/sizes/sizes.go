@@ -0,0 +1,21 @@
+package sizes
+
+import "time"
+
+type Layout struct {
+	A bool
+	B int64
+	C string
+	D []int
+	E [3]uint16
+	F complex64
+	G struct{}
+	H *int
+	I interface{}
+	K time.Month
+}
+
+type Trailing struct {
+	A int32
+	Z struct{}
+}
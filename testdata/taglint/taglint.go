@@ -0,0 +1,35 @@
+package taglint
+
+import "time"
+
+type Base struct {
+	ID        int       `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Audit struct {
+	ID   int `json:"id"`
+	User string
+}
+
+type Status string
+
+type Order struct {
+	Base
+	*Audit
+	Status
+	error
+	Name     string `json:"name,omitempty"`
+	Title    string `json:"name"`
+	Price    int    `json:"price,omitempty,strnig"`
+	Quantity int    `json:"quantity" json:"qty"`
+	Note     string `json:note`
+	User     string `json:"-"`
+	secret   string `json:"name"`
+	Shipping struct {
+		City string `xml:"city,atr"`
+		Zip  string `json:"city"`
+		Town string `json:"city"`
+	}
+	Extra string `yaml:"extra,inline" custom:"a,b"`
+}
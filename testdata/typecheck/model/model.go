@@ -0,0 +1,9 @@
+package model
+
+const KeyLen = 16
+
+type Customer struct {
+	Name string
+}
+
+type Status int
@@ -0,0 +1,19 @@
+package typecheck
+
+import (
+	. "github.com/armantarkhanian/gotype/testdata/typecheck/model"
+	m "github.com/armantarkhanian/gotype/testdata/typecheck/model"
+)
+
+type Label = string
+
+type Order struct {
+	Customer Customer
+	Owner    *m.Customer
+	Label    Label
+	Status   Status
+	Key      [KeyLen * 2]byte
+	Notify   func(string, ...int) error
+}
+
+type Set[T comparable] map[T]struct{}
@@ -0,0 +1,15 @@
+package api
+
+type Used struct{}
+
+type Unused struct{}
+
+type UsedInOwnPackage struct{}
+
+type UsedByDotImport struct{}
+
+type UsedByTest struct{}
+
+type internalOnly struct {
+	Own UsedInOwnPackage
+}
@@ -0,0 +1,7 @@
+package consumer
+
+import "github.com/armantarkhanian/gotype/testdata/unused/api"
+
+type Consumer struct {
+	API api.Used
+}
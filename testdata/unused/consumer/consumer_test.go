@@ -0,0 +1,11 @@
+package consumer
+
+import (
+	"testing"
+
+	"github.com/armantarkhanian/gotype/testdata/unused/api"
+)
+
+func TestConsumer(t *testing.T) {
+	_ = api.UsedByTest{}
+}
@@ -0,0 +1,5 @@
+package consumer
+
+import . "github.com/armantarkhanian/gotype/testdata/unused/api"
+
+func consume(UsedByDotImport) {}
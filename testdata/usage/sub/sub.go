@@ -0,0 +1,13 @@
+package sub
+
+type Item struct {
+	Name string
+}
+
+type Address struct {
+	City string
+}
+
+type Reader interface {
+	Read(id int) (Item, error)
+}
@@ -0,0 +1,24 @@
+package usage
+
+import (
+	"time"
+
+	"github.com/armantarkhanian/gotype/testdata/usage/sub"
+)
+
+type Order struct {
+	Customer Customer
+	Items    []sub.Item
+	At       time.Time
+}
+
+func (o *Order) Ship(to sub.Address) (time.Time, error) { return time.Time{}, nil }
+
+type Customer struct {
+	Address sub.Address
+}
+
+type Store interface {
+	sub.Reader
+	Save(order Order) error
+}
@@ -0,0 +1,16 @@
+package validate
+
+import "time"
+
+type Role string
+
+type Account struct {
+	Name      string    `validate:"required,min=3,max=32"`
+	Email     string    `validate:"omitempty,email"`
+	Age       int       `validate:"min=18"`
+	Role      Role      `validate:"oneof=admin user"`
+	Tags      []string  `validate:"max=5"`
+	CreatedAt time.Time `validate:"required"`
+	Code      string    `check:"len=6"`
+	Ignored   string
+}
@@ -0,0 +1,8 @@
+package api
+
+import "github.com/armantarkhanian/gotype/testdata/visibility/internal/secret"
+
+type Client struct {
+	Name   string
+	Tokens map[string]secret.Token
+}
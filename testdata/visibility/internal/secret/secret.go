@@ -0,0 +1,3 @@
+package secret
+
+type Token string
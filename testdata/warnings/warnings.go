@@ -0,0 +1,11 @@
+package warnings
+
+import "io"
+
+type Degraded struct {
+	io.Reader
+	Buffer [cap(sizes)]byte
+	Name   string
+}
+
+var sizes []int
@@ -0,0 +1,34 @@
+package v1
+
+type Status int32
+
+type Address struct {
+	City string `json:"city"`
+	Zip  string `json:"zip"`
+}
+
+type Event struct {
+	ID       int32             `json:"id"`
+	Name     string            `json:"Name"`
+	Count    int64             `json:"count"`
+	Score    int               `json:"score"`
+	Status   Status            `json:"status"`
+	Address  Address           `json:"address"`
+	Tags     []string          `json:"tags"`
+	Labels   map[string]string `json:"labels"`
+	Amount   int64             `json:"amount,string"`
+	Legacy   string            `json:"legacy"`
+	Ignored  chan int          `json:"-"`
+	internal string
+}
+
+type UserMessage struct {
+	state  int
+	Id     int32        `protobuf:"varint,1,opt,name=id,proto3"`
+	Name   string       `protobuf:"bytes,2,opt,name=name,proto3"`
+	Email  string       `protobuf:"bytes,3,opt,name=email,proto3"`
+	Tags   []string     `protobuf:"bytes,4,rep,name=tags,proto3"`
+	Score  int32        `protobuf:"zigzag32,5,opt,name=score,proto3"`
+	Avatar string       `protobuf:"bytes,6,opt,name=avatar,proto3"`
+	Parent *UserMessage `protobuf:"bytes,7,opt,name=parent,proto3"`
+}
@@ -0,0 +1,36 @@
+package v2
+
+import "time"
+
+type Status int64
+
+type Location struct {
+	City    string `json:"city"`
+	Zip     int    `json:"zip"`
+	Country string `json:"country"`
+}
+
+type Event struct {
+	ID      int64             `json:"id"`
+	Name    string            `json:"name"`
+	Count   int32             `json:"count"`
+	Score   float64           `json:"score"`
+	Status  Status            `json:"status"`
+	Address *Location         `json:"address"`
+	Tags    [3]string         `json:"tags"`
+	Labels  map[string][]byte `json:"labels"`
+	Amount  int64             `json:"amount"`
+	Created time.Time         `json:"created"`
+}
+
+type UserMessage struct {
+	state    int
+	Id       int64        `protobuf:"varint,1,opt,name=id,proto3"`
+	FullName string       `protobuf:"bytes,2,opt,name=full_name,proto3"`
+	Age      uint32       `protobuf:"fixed32,3,opt,name=age,proto3"`
+	Tags     string       `protobuf:"bytes,4,opt,name=tags,proto3"`
+	Score    int64        `protobuf:"zigzag64,5,opt,name=score,proto3"`
+	Avatar   []byte       `protobuf:"bytes,6,opt,name=avatar,proto3"`
+	Parent   *UserMessage `protobuf:"bytes,7,opt,name=parent,proto3"`
+	Country  string       `protobuf:"bytes,8,opt,name=country,proto3"`
+}
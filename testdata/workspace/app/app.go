@@ -0,0 +1,7 @@
+package app
+
+import "example.com/lib"
+
+type App struct {
+	Lib lib.Lib
+}
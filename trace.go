@@ -0,0 +1,89 @@
+package gotype
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+	"sync"
+)
+
+// TraceStep is a single decision taken by the generator while resolving a type, like the file where the declaration
+// is found or the import used to resolve a selector.
+type TraceStep struct {
+	// Position contains the location of the construct the decision is about, if any.
+	Position token.Position
+
+	// Message describes the decision.
+	Message string
+}
+
+func (s TraceStep) String() string {
+	if !s.Position.IsValid() {
+		return s.Message
+	}
+	return s.Position.String() + ": " + s.Message
+}
+
+// Trace records the decisions taken by the generator while resolving each type. It's useful to find out why a type is
+// resolved the wrong way. See `WithTrace`.
+type Trace struct {
+	// mu guards steps, since the generator may record the decisions of multiple goroutines.
+	mu    sync.Mutex
+	steps map[TypeSpec][]TraceStep
+}
+
+// NewTrace creates an empty Trace. The zero value of Trace is empty as well.
+func NewTrace() *Trace {
+	return &Trace{steps: make(map[TypeSpec][]TraceStep)}
+}
+
+// Steps returns the decisions taken while resolving the type specified by `spec`, in the order they're taken. The
+// decisions taken while resolving the types the declaration depends on, like its embedded interfaces, are recorded
+// under their own specs.
+func (t *Trace) Steps(spec TypeSpec) []TraceStep {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]TraceStep(nil), t.steps[spec]...)
+}
+
+// Explain returns the decisions taken while resolving the type specified by `spec`, one per line.
+func (t *Trace) Explain(spec TypeSpec) string {
+	steps := t.Steps(spec)
+	lines := make([]string, 0, len(steps))
+	for _, step := range steps {
+		lines = append(lines, step.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// beginTrace makes the decisions taken until the matching endTrace call to be recorded under `spec`.
+func (f *astTypeGenerator) beginTrace(spec TypeSpec) {
+	if f.config.trace != nil {
+		f.tracing = append(f.tracing, spec)
+	}
+}
+
+func (f *astTypeGenerator) endTrace() {
+	if f.config.trace != nil {
+		f.tracing = f.tracing[:len(f.tracing)-1]
+	}
+}
+
+// explain logs a decision and records it inside the configured Trace, under the spec being resolved.
+func (f *astTypeGenerator) explain(pos token.Pos, format string, args ...interface{}) {
+	f.logf(format, args...)
+	if f.config.trace == nil || len(f.tracing) == 0 {
+		return
+	}
+
+	spec := f.tracing[len(f.tracing)-1]
+	f.config.trace.mu.Lock()
+	defer f.config.trace.mu.Unlock()
+	if f.config.trace.steps == nil {
+		f.config.trace.steps = make(map[TypeSpec][]TraceStep)
+	}
+	f.config.trace.steps[spec] = append(f.config.trace.steps[spec], TraceStep{
+		Position: f.position(pos),
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
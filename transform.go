@@ -0,0 +1,105 @@
+package gotype
+
+// mapType returns a copy of `t` where every type inside it, including `t` itself, is transformed by `fn`. When `fn`
+// reports true, its result is used as is. Otherwise, mapType continues transforming the types nested inside the
+// result. The original `t` is left untouched.
+func mapType(t Type, fn func(Type) (Type, bool)) Type {
+	t, done := fn(t)
+	if done {
+		return t
+	}
+
+	switch {
+	case t.QualType != nil:
+		if len(t.QualType.TypeArgs) > 0 {
+			q := *t.QualType
+			q.TypeArgs = mapTypes(q.TypeArgs, fn)
+			t.QualType = &q
+		}
+	case t.ChanType != nil:
+		c := *t.ChanType
+		c.Elem = mapType(c.Elem, fn)
+		t.ChanType = &c
+	case t.SliceType != nil:
+		s := *t.SliceType
+		s.Elem = mapType(s.Elem, fn)
+		t.SliceType = &s
+	case t.PtrType != nil:
+		p := *t.PtrType
+		p.Elem = mapType(p.Elem, fn)
+		t.PtrType = &p
+	case t.ArrayType != nil:
+		a := *t.ArrayType
+		a.Elem = mapType(a.Elem, fn)
+		t.ArrayType = &a
+	case t.MapType != nil:
+		m := *t.MapType
+		m.Key = mapType(m.Key, fn)
+		m.Elem = mapType(m.Elem, fn)
+		t.MapType = &m
+	case t.FuncType != nil:
+		funcType := mapFuncType(*t.FuncType, fn)
+		t.FuncType = &funcType
+	case t.StructType != nil:
+		structType := *t.StructType
+		structType.Fields = mapFields(structType.Fields, fn)
+		t.StructType = &structType
+	case t.InterfaceType != nil:
+		i := *t.InterfaceType
+		if i.Methods != nil {
+			i.Methods = make([]InterfaceTypeMethod, 0, len(t.InterfaceType.Methods))
+			for _, method := range t.InterfaceType.Methods {
+				method.Func = mapFuncType(method.Func, fn)
+				i.Methods = append(i.Methods, method)
+			}
+		}
+		if i.Unions != nil {
+			i.Unions = make([][]TypeTerm, 0, len(t.InterfaceType.Unions))
+			for _, union := range t.InterfaceType.Unions {
+				terms := make([]TypeTerm, 0, len(union))
+				for _, term := range union {
+					term.Type = mapType(term.Type, fn)
+					terms = append(terms, term)
+				}
+				i.Unions = append(i.Unions, terms)
+			}
+		}
+		t.InterfaceType = &i
+	}
+	if t.Methods != nil {
+		methods := make([]Method, 0, len(t.Methods))
+		for _, method := range t.Methods {
+			method.Func = mapFuncType(method.Func, fn)
+			methods = append(methods, method)
+		}
+		t.Methods = methods
+	}
+	return t
+}
+
+func mapTypes(types []Type, fn func(Type) (Type, bool)) []Type {
+	result := make([]Type, 0, len(types))
+	for _, t := range types {
+		result = append(result, mapType(t, fn))
+	}
+	return result
+}
+
+func mapFuncType(funcType FuncType, fn func(Type) (Type, bool)) FuncType {
+	funcType.Inputs = mapFields(funcType.Inputs, fn)
+	funcType.Outputs = mapFields(funcType.Outputs, fn)
+	return funcType
+}
+
+func mapFields(fields []TypeField, fn func(Type) (Type, bool)) []TypeField {
+	if fields == nil {
+		return nil
+	}
+
+	result := make([]TypeField, 0, len(fields))
+	for _, field := range fields {
+		field.Type = mapType(field.Type, fn)
+		result = append(result, field)
+	}
+	return result
+}
@@ -0,0 +1,644 @@
+package gotype
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// typeCheckedPackage is a package type-checked by go/types, see `WithTypeChecking`.
+type typeCheckedPackage struct {
+	pkg     *types.Package
+	info    *types.Info
+	files   []*ast.File
+	sources []string
+
+	// err contains the first type error of the package. The type checker keeps going after an error, so the
+	// declarations which don't depend on the invalid code are still resolved.
+	err error
+
+	// fields contains the struct's fields and the interface's methods declared by the package, by the positions of
+	// their names, so their comments, which aren't kept by go/types, are found. It's built on the first lookup.
+	fields map[token.Pos]*ast.Field
+}
+
+// typeCheckImporter imports the packages by type-checking their source files, found by the generator's SourceFinder.
+type typeCheckImporter struct {
+	generator *astTypeGenerator
+}
+
+func (i typeCheckImporter) Import(packagePath string) (*types.Package, error) {
+	checked, err := i.generator.typeCheckPackage(packagePath)
+	if err != nil {
+		return nil, err
+	}
+	return checked.pkg, nil
+}
+
+// typeCheckPackage type-checks the package, once per call. The bodies of the functions aren't checked, since they
+// don't declare the package-level types.
+func (f *astTypeGenerator) typeCheckPackage(packagePath string) (_ *typeCheckedPackage, err error) {
+	if checked, ok := f.typeChecked[packagePath]; ok {
+		if checked == nil {
+			return nil, fmt.Errorf("import cycle through package %s", packagePath)
+		}
+		return checked, nil
+	}
+	if packagePath == "unsafe" {
+		return &typeCheckedPackage{pkg: types.Unsafe}, nil
+	}
+
+	if f.typeChecked == nil {
+		f.typeChecked = make(map[string]*typeCheckedPackage)
+	}
+	// the package is marked as being checked, so an import cycle is reported instead of overflowing the stack.
+	f.typeChecked[packagePath] = nil
+	defer func() {
+		if err != nil {
+			delete(f.typeChecked, packagePath)
+		}
+	}()
+
+	goSources, err := f.getPackageSourceFiles(packagePath)
+	if err != nil {
+		return nil, err
+	}
+	// a package only type-checks for a single target, so the build constraints are applied even without a Config.
+	buildConfig := f.config.buildConfig
+	if buildConfig == nil {
+		buildConfig = &Config{}
+	}
+	open := func(filename string) (io.ReadCloser, error) {
+		content, err := f.readSourceFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.NopCloser(bytes.NewReader(content)), nil
+	}
+
+	checked := &typeCheckedPackage{info: &types.Info{Types: make(map[ast.Expr]types.TypeAndValue)}}
+	for _, source := range goSources {
+		// the external test packages can't be checked along with the package.
+		if strings.HasSuffix(source, "_test.go") {
+			continue
+		}
+		match, err := buildConfig.matchFile(filepath.Dir(source), filepath.Base(source), open)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read build constraints of %s: %w", source, err)
+		}
+		if !match {
+			continue
+		}
+		file, err := f.parseAstFile(source)
+		if err != nil {
+			return nil, err
+		}
+		checked.files = append(checked.files, file)
+		checked.sources = append(checked.sources, source)
+	}
+
+	conf := types.Config{
+		Importer:         typeCheckImporter{generator: f},
+		IgnoreFuncBodies: true,
+		FakeImportC:      true,
+		Error: func(err error) {
+			if checked.err == nil {
+				checked.err = err
+			}
+		},
+	}
+	checked.pkg, _ = conf.Check(packagePath, f.fset, checked.files, checked.info)
+	f.typeChecked[packagePath] = checked
+	return checked, nil
+}
+
+// generateTypesUsingTypeChecker generates the types specified by `typeSpecs` from their packages type-checked by
+// go/types, see `WithTypeChecking`.
+func (f *astTypeGenerator) generateTypesUsingTypeChecker(typeSpecs []TypeSpec) ([]Type, error) {
+	results := make([]Type, 0, len(typeSpecs))
+	for _, spec := range typeSpecs {
+		checked, err := f.typeCheckPackage(spec.PackagePath)
+		if err != nil {
+			return nil, err
+		}
+
+		typeSpec, doc := checked.lookupTypeSpec(spec.Name)
+		if typeSpec == nil {
+			return nil, &TypeNotFoundError{
+				PackagePath:   spec.PackagePath,
+				Name:          spec.Name,
+				SearchedFiles: checked.sources,
+				FoundTypes:    checked.typeNames(),
+			}
+		}
+
+		converter := &typeConverter{generator: f, checked: checked, resolving: make(map[string]struct{})}
+		typ, err := converter.convert(checked.info.TypeOf(typeSpec.Type))
+		if err != nil {
+			return nil, fmt.Errorf("cannot generate type %s of package %s: %w", spec.Name, spec.PackagePath, err)
+		}
+		if named, ok := checked.pkg.Scope().Lookup(spec.Name).Type().(*types.Named); ok && !typeSpec.Assign.IsValid() {
+			if typ.TypeParams, err = converter.convertTypeParams(named.TypeParams()); err != nil {
+				return nil, fmt.Errorf("cannot generate type %s of package %s: %w", spec.Name, spec.PackagePath, err)
+			}
+		}
+		typ.Position = f.position(typeSpec.Name.Pos())
+		typ.Doc = doc
+		typ.IsAlias = typeSpec.Assign.IsValid()
+		results = append(results, typ)
+	}
+	return results, nil
+}
+
+// generateMethodsUsingTypeChecker returns the methods declared with the type specified by `spec` as their receiver,
+// from its package type-checked by go/types, see `WithMethods`.
+func (f *astTypeGenerator) generateMethodsUsingTypeChecker(spec TypeSpec) ([]Method, error) {
+	checked, err := f.typeCheckPackage(spec.PackagePath)
+	if err != nil {
+		return nil, err
+	}
+	obj := checked.pkg.Scope().Lookup(spec.Name)
+	if obj == nil {
+		return nil, nil
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return nil, nil
+	}
+
+	converter := &typeConverter{generator: f, checked: checked, resolving: make(map[string]struct{})}
+	var methods []Method
+	for i := 0; i < named.NumMethods(); i++ {
+		method := named.Method(i)
+		if f.isOmitted(method.Name()) {
+			continue
+		}
+		signature := method.Type().(*types.Signature)
+		funcType, err := converter.convertSignature(signature)
+		if err != nil {
+			return nil, fmt.Errorf("cannot generate method %s of type %s: %w", method.Name(), spec.Name, err)
+		}
+		// the receiver of a method of a generic type declares its own names for the type's type parameters.
+		if params := signature.RecvTypeParams(); params.Len() > 0 {
+			mapping := make(map[string]Type, params.Len())
+			for j := 0; j < params.Len(); j++ {
+				mapping[params.At(j).Obj().Name()] = TypeParamType{Name: named.TypeParams().At(j).Obj().Name()}.Type()
+			}
+			funcType = substituteTypeParamsInFunc(funcType, mapping)
+		}
+		_, pointer := signature.Recv().Type().(*types.Pointer)
+		methods = append(methods, Method{
+			Name:            method.Name(),
+			Func:            funcType,
+			PointerReceiver: pointer,
+			Doc:             checked.lookupFuncDoc(method.Pos()),
+			Position:        f.position(method.Pos()),
+		})
+	}
+	return methods, nil
+}
+
+// lookupTypeSpec returns the package-level declaration of the type named `name` and its documentation comment, or nil.
+func (p *typeCheckedPackage) lookupTypeSpec(name string) (*ast.TypeSpec, string) {
+	for _, file := range p.files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				if typeSpec := spec.(*ast.TypeSpec); typeSpec.Name.Name == name {
+					return typeSpec, specDoc(genDecl, typeSpec.Doc)
+				}
+			}
+		}
+	}
+	return nil, ""
+}
+
+// lookupField returns the declaration of the struct's field or the interface's method named at `pos`, or nil.
+func (p *typeCheckedPackage) lookupField(pos token.Pos) *ast.Field {
+	if p.fields == nil {
+		p.fields = make(map[token.Pos]*ast.Field)
+		index := func(list *ast.FieldList) {
+			for _, field := range list.List {
+				for _, name := range field.Names {
+					p.fields[name.Pos()] = field
+				}
+				// go/types declares an embedded field at the name of its type, like `Reader` of `*io.Reader`.
+				if ident := embeddedFieldIdent(field.Type); len(field.Names) == 0 && ident != nil {
+					p.fields[ident.Pos()] = field
+				}
+			}
+		}
+		for _, file := range p.files {
+			ast.Inspect(file, func(node ast.Node) bool {
+				switch n := node.(type) {
+				case *ast.StructType:
+					index(n.Fields)
+				case *ast.InterfaceType:
+					index(n.Methods)
+				}
+				return true
+			})
+		}
+	}
+	return p.fields[pos]
+}
+
+// lookupFuncDoc returns the documentation comment of the function or the method named at `pos`.
+func (p *typeCheckedPackage) lookupFuncDoc(pos token.Pos) string {
+	for _, file := range p.files {
+		for _, decl := range file.Decls {
+			if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Name.Pos() == pos {
+				return funcDecl.Doc.Text()
+			}
+		}
+	}
+	return ""
+}
+
+// embeddedFieldIdent returns the identifier naming the type of an embedded field, see `embeddedFieldName`.
+func embeddedFieldIdent(expr ast.Expr) *ast.Ident {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e
+	case *ast.StarExpr:
+		return embeddedFieldIdent(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel
+	case *ast.IndexExpr:
+		return embeddedFieldIdent(e.X)
+	case *ast.IndexListExpr:
+		return embeddedFieldIdent(e.X)
+	}
+	return nil
+}
+
+// typeNames returns the names of the package-level types, sorted.
+func (p *typeCheckedPackage) typeNames() []string {
+	names := make([]string, 0)
+	for _, name := range p.pkg.Scope().Names() {
+		if _, ok := p.pkg.Scope().Lookup(name).(*types.TypeName); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// typeConverter converts the types of go/types into Types.
+type typeConverter struct {
+	generator *astTypeGenerator
+	checked   *typeCheckedPackage
+
+	// resolving contains the named types whose definitions are being converted by the deep resolution, so the
+	// references closing a cycle aren't resolved again.
+	resolving map[string]struct{}
+}
+
+func (c *typeConverter) convert(t types.Type) (Type, error) {
+	switch t := unalias(t).(type) {
+	case nil:
+		return Type{}, errors.New("missing type")
+	case *types.Basic:
+		return c.convertBasic(t)
+	case *types.Named:
+		return c.convertNamed(t)
+	case *types.Pointer:
+		elem, err := c.convert(t.Elem())
+		if err != nil {
+			return Type{}, err
+		}
+		return Type{PtrType: &PtrType{Elem: elem}}, nil
+	case *types.Slice:
+		elem, err := c.convert(t.Elem())
+		if err != nil {
+			return Type{}, err
+		}
+		return Type{SliceType: &SliceType{Elem: elem}}, nil
+	case *types.Array:
+		elem, err := c.convert(t.Elem())
+		if err != nil {
+			return Type{}, err
+		}
+		return Type{ArrayType: &ArrayType{Len: int(t.Len()), Elem: elem}}, nil
+	case *types.Map:
+		key, err := c.convert(t.Key())
+		if err != nil {
+			return Type{}, err
+		}
+		elem, err := c.convert(t.Elem())
+		if err != nil {
+			return Type{}, err
+		}
+		return Type{MapType: &MapType{Key: key, Elem: elem}}, nil
+	case *types.Chan:
+		elem, err := c.convert(t.Elem())
+		if err != nil {
+			return Type{}, err
+		}
+		dir := ChanTypeDir(ChanTypeDirBoth)
+		switch t.Dir() {
+		case types.SendOnly:
+			dir = ChanTypeDirSend
+		case types.RecvOnly:
+			dir = ChanTypeDirRecv
+		}
+		return Type{ChanType: &ChanType{Dir: dir, Elem: elem}}, nil
+	case *types.Signature:
+		funcType, err := c.convertSignature(t)
+		if err != nil {
+			return Type{}, err
+		}
+		return Type{FuncType: &funcType}, nil
+	case *types.Struct:
+		structType, err := c.convertStruct(t)
+		if err != nil {
+			return Type{}, err
+		}
+		return Type{StructType: &structType}, nil
+	case *types.Interface:
+		interfaceType, err := c.convertInterface(t)
+		if err != nil {
+			return Type{}, err
+		}
+		return Type{InterfaceType: &interfaceType}, nil
+	case *types.TypeParam:
+		return Type{TypeParamType: &TypeParamType{Name: t.Obj().Name()}}, nil
+	}
+	return Type{}, fmt.Errorf("unrecognized type: %s", t)
+}
+
+func (c *typeConverter) convertBasic(t *types.Basic) (Type, error) {
+	switch t.Kind() {
+	case types.Invalid:
+		if c.checked.err != nil {
+			return Type{}, fmt.Errorf("invalid type: %w", c.checked.err)
+		}
+		return Type{}, errors.New("invalid type")
+	case types.UnsafePointer:
+		return Type{QualType: &QualType{Package: "unsafe", ShortPackagePath: "unsafe", Name: "Pointer"}}, nil
+	case types.Int, types.Uint, types.Uintptr:
+		bits := c.generator.config.buildConfig.wordBits()
+		return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKind(t.Name()), Bits: bits}}, nil
+	}
+	if t.Info()&types.IsUntyped != 0 {
+		return Type{}, fmt.Errorf("unrecognized type: %s", t)
+	}
+	// the names of the basic types, including the aliases byte and rune, are the PrimitiveKinds.
+	return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKind(t.Name())}}, nil
+}
+
+func (c *typeConverter) convertNamed(t *types.Named) (Type, error) {
+	obj := t.Obj()
+	if obj.Pkg() == nil {
+		if obj.Name() == "error" {
+			return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindError}}, nil
+		}
+		// comparable is a predeclared interface, so it doesn't belong to any package.
+		return Type{QualType: &QualType{Name: obj.Name()}}, nil
+	}
+
+	qualType := QualType{Package: obj.Pkg().Path(), ShortPackagePath: obj.Pkg().Name(), Name: obj.Name()}
+	for i := 0; i < t.TypeArgs().Len(); i++ {
+		arg, err := c.convert(t.TypeArgs().At(i))
+		if err != nil {
+			return Type{}, err
+		}
+		qualType.TypeArgs = append(qualType.TypeArgs, arg)
+	}
+
+	key := t.String()
+	if _, ok := c.resolving[key]; c.generator.config.deepResolution && !ok {
+		c.resolving[key] = struct{}{}
+		underlying, err := c.convert(t.Underlying())
+		delete(c.resolving, key)
+		if err != nil {
+			return Type{}, err
+		}
+		underlying.Position = c.generator.position(obj.Pos())
+		if checked := c.generator.typeChecked[obj.Pkg().Path()]; checked != nil {
+			_, underlying.Doc = checked.lookupTypeSpec(obj.Name())
+		}
+		qualType.Underlying = &underlying
+	}
+	return Type{QualType: &qualType}, nil
+}
+
+func (c *typeConverter) convertSignature(t *types.Signature) (FuncType, error) {
+	inputs, err := c.convertTuple(t.Params())
+	if err != nil {
+		return FuncType{}, err
+	}
+	if t.Variadic() {
+		// like inside the source code, the variadic parameter has the type of its elements.
+		last := &inputs[len(inputs)-1]
+		last.Type = last.Type.SliceType.Elem
+	}
+	var outputs []TypeField = nil
+	if t.Results().Len() > 0 {
+		if outputs, err = c.convertTuple(t.Results()); err != nil {
+			return FuncType{}, err
+		}
+	}
+	return FuncType{Inputs: inputs, Outputs: outputs, IsVariadic: t.Variadic()}, nil
+}
+
+func (c *typeConverter) convertTuple(t *types.Tuple) ([]TypeField, error) {
+	fields := make([]TypeField, 0, t.Len())
+	for i := 0; i < t.Len(); i++ {
+		v := t.At(i)
+		typ, err := c.convert(v.Type())
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, TypeField{Name: v.Name(), Type: typ, Position: c.generator.position(v.Pos())})
+	}
+	return fields, nil
+}
+
+func (c *typeConverter) convertStruct(t *types.Struct) (StructType, error) {
+	fields := make([]TypeField, 0, t.NumFields())
+	for i := 0; i < t.NumFields(); i++ {
+		v := t.Field(i)
+		if c.generator.isOmitted(v.Name()) {
+			continue
+		}
+		typ, err := c.convert(v.Type())
+		if err != nil {
+			return StructType{}, err
+		}
+		field := c.lookupField(v)
+		fields = append(fields, TypeField{
+			Name:     v.Name(),
+			Type:     typ,
+			Position: c.generator.position(v.Pos()),
+			Tag:      reflect.StructTag(t.Tag(i)),
+			Embedded: v.Embedded(),
+			Doc:      field.Doc.Text(),
+			Comment:  field.Comment.Text(),
+		})
+	}
+	c.generator.sortFields(fields)
+	return StructType{Fields: fields}, nil
+}
+
+// lookupField returns the declaration of the struct's field or the interface's method `obj`, or an empty field when
+// it isn't found, so its comments are empty.
+func (c *typeConverter) lookupField(obj types.Object) *ast.Field {
+	if obj.Pkg() != nil {
+		if checked := c.generator.typeChecked[obj.Pkg().Path()]; checked != nil {
+			if field := checked.lookupField(obj.Pos()); field != nil {
+				return field
+			}
+		}
+	}
+	return &ast.Field{}
+}
+
+func (c *typeConverter) convertInterface(t *types.Interface) (InterfaceType, error) {
+	if t.NumExplicitMethods() == 0 && t.NumEmbeddeds() == 0 {
+		return InterfaceType{Methods: nil}, nil
+	}
+
+	// the type checker sorts the methods by their names, they are sorted back into the source order.
+	explicit := make([]*types.Func, 0, t.NumExplicitMethods())
+	for i := 0; i < t.NumExplicitMethods(); i++ {
+		explicit = append(explicit, t.ExplicitMethod(i))
+	}
+	sort.SliceStable(explicit, func(i, j int) bool { return explicit[i].Pos() < explicit[j].Pos() })
+
+	methods := make([]InterfaceTypeMethod, 0, len(explicit))
+	for _, method := range explicit {
+		if c.generator.isOmitted(method.Name()) {
+			continue
+		}
+		funcType, err := c.convertSignature(method.Type().(*types.Signature))
+		if err != nil {
+			return InterfaceType{}, err
+		}
+		field := c.lookupField(method)
+		methods, err = c.generator.appendInterfaceMethods(methods, InterfaceTypeMethod{
+			Name:     method.Name(),
+			Func:     funcType,
+			Doc:      field.Doc.Text(),
+			Comment:  field.Comment.Text(),
+			Position: c.generator.position(method.Pos()),
+		})
+		if err != nil {
+			return InterfaceType{}, err
+		}
+	}
+
+	var embedded []QualType
+	var unions [][]TypeTerm
+	for i := 0; i < t.NumEmbeddeds(); i++ {
+		switch e := unalias(t.EmbeddedType(i)).(type) {
+		case *types.Union:
+			terms := make([]TypeTerm, 0, e.Len())
+			for j := 0; j < e.Len(); j++ {
+				typ, err := c.convert(e.Term(j).Type())
+				if err != nil {
+					return InterfaceType{}, err
+				}
+				terms = append(terms, TypeTerm{Tilde: e.Term(j).Tilde(), Type: typ})
+			}
+			unions = append(unions, terms)
+		case *types.Named:
+			typ, err := c.convert(e)
+			if err != nil {
+				return InterfaceType{}, err
+			}
+			if typ.PrimitiveType != nil && typ.PrimitiveType.Kind == PrimitiveKindError {
+				embedded = append(embedded, errorOrigin)
+				if c.generator.config.keepEmbeddedInterfaces {
+					continue
+				}
+				if methods, err = c.generator.appendInterfaceMethods(methods, c.generator.errorMethods()...); err != nil {
+					return InterfaceType{}, err
+				}
+				continue
+			}
+			inner, ok := e.Underlying().(*types.Interface)
+			if !ok || typ.QualType == nil {
+				// embedding a non-interface type inside a constraint means a single-term union.
+				unions = append(unions, []TypeTerm{{Type: typ}})
+				continue
+			}
+
+			origin := *typ.QualType
+			origin.Underlying = nil
+			embedded = append(embedded, origin)
+			if c.generator.config.keepEmbeddedInterfaces || origin.Package == "" {
+				continue
+			}
+			innerInterface, err := c.convertInterface(inner)
+			if err != nil {
+				return InterfaceType{}, err
+			}
+			innerMethods := c.generator.embedInterfaceMethods(innerInterface.Type(), origin)
+			if methods, err = c.generator.appendInterfaceMethods(methods, innerMethods...); err != nil {
+				return InterfaceType{}, err
+			}
+			unions = append(unions, innerInterface.Unions...)
+		case *types.Interface:
+			// an unnamed interface, like any, adds its methods and unions.
+			innerInterface, err := c.convertInterface(e)
+			if err != nil {
+				return InterfaceType{}, err
+			}
+			if methods, err = c.generator.appendInterfaceMethods(methods, innerInterface.Methods...); err != nil {
+				return InterfaceType{}, err
+			}
+			unions = append(unions, innerInterface.Unions...)
+		default:
+			typ, err := c.convert(e)
+			if err != nil {
+				return InterfaceType{}, err
+			}
+			unions = append(unions, []TypeTerm{{Type: typ}})
+		}
+	}
+	c.generator.sortMethods(methods)
+	return InterfaceType{Methods: methods, Embedded: embedded, Unions: unions}, nil
+}
+
+func (c *typeConverter) convertTypeParams(list *types.TypeParamList) ([]TypeParam, error) {
+	if list.Len() == 0 {
+		return nil, nil
+	}
+	params := make([]TypeParam, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		param := list.At(i)
+		constraint, err := c.convert(param.Constraint())
+		if err != nil {
+			return nil, err
+		}
+
+		// the constraint interface of the predeclared comparable is nil, like for the generated declarations.
+		var constraintInterface *InterfaceType
+		iface, ok := param.Constraint().Underlying().(*types.Interface)
+		if ok && (constraint.QualType == nil || constraint.QualType.Package != "") {
+			converted, err := c.convertInterface(iface)
+			if err != nil {
+				return nil, err
+			}
+			constraintInterface = &converted
+		}
+		params = append(params, TypeParam{
+			Name:                param.Obj().Name(),
+			Constraint:          constraint,
+			ConstraintInterface: constraintInterface,
+		})
+	}
+	return params, nil
+}
@@ -0,0 +1,59 @@
+package gotype
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypeChecking(t *testing.T) {
+	pkg := testdataPackage + "/typecheck"
+	specs := []TypeSpec{{PackagePath: pkg, Name: "Order"}, {PackagePath: pkg, Name: "Set"}}
+
+	// without type checking, the identifiers of the dot imports are resolved inside the package itself.
+	types, err := NewGenerator(WithWarningHandler(func(Warning) {})).GenerateTypesFromSpecs(specs[0])
+	require.NoError(t, err)
+	assert.Equal(t, pkg, types[0].StructType.Fields[0].Type.QualType.Package)
+
+	types, err = NewGenerator(WithTypeChecking()).GenerateTypesFromSpecs(specs...)
+	require.NoError(t, err)
+	assert.Equal(t, "struct {\n"+
+		"    Customer model.Customer\n"+
+		"    Owner *model.Customer\n"+
+		"    Label string\n"+
+		"    Status model.Status\n"+
+		"    Key [32]byte\n"+
+		"    Notify func( string,  ...int) ( error)\n"+
+		"}", types[0].String(""))
+	fields := types[0].StructType.Fields
+	assert.Equal(t, pkg+"/model", fields[0].Type.QualType.Package)
+	assert.Equal(t, 11, fields[0].Position.Line)
+	assert.True(t, fields[5].Type.FuncType.IsVariadic)
+
+	require.Len(t, types[1].TypeParams, 1)
+	assert.Equal(t, "comparable", types[1].TypeParams[0].Constraint.String(""))
+	assert.Nil(t, types[1].TypeParams[0].ConstraintInterface)
+	assert.Equal(t, "map[T]struct{}", TypeString(Type{MapType: types[1].MapType}))
+
+	// the generated types match the ones generated from the syntax trees, when they are resolved correctly.
+	for _, spec := range []TypeSpec{
+		{PackagePath: testdataPackage + "/generics", Name: "Repository"},
+		{PackagePath: testdataPackage + "/generics", Name: "Vector"},
+		{PackagePath: testdataPackage + "/generics", Name: "Max"},
+		{PackagePath: testdataPackage + "/generics/usage", Name: "Cache"},
+		{PackagePath: testdataPackage + "/ifaces", Name: "Diamond"},
+		{PackagePath: testdataPackage + "/embedding", Name: "Document"},
+	} {
+		expected, err := NewGenerator(WithDeepResolution()).GenerateTypesFromSpecs(spec)
+		require.NoError(t, err)
+		checked, err := NewGenerator(WithDeepResolution(), WithTypeChecking()).GenerateTypesFromSpecs(spec)
+		require.NoError(t, err)
+		assert.Equal(t, TypeString(expected[0]), TypeString(checked[0]), spec.Name)
+		assert.True(t, Identical(expected[0], checked[0]), spec.Name)
+	}
+
+	_, err = NewGenerator(WithTypeChecking()).GenerateTypesFromSpecs(TypeSpec{PackagePath: pkg, Name: "Missing"})
+	assert.True(t, errors.Is(err, ErrDeclarationNotFound))
+}
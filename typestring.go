@@ -0,0 +1,551 @@
+package gotype
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TypeString returns the canonical string of `typ`, like the strings of `go/types.TypeString` qualified by the full
+// package paths, such as "map[string][]*github.com/google/uuid.UUID". Identical types, see Identical, have the same
+// string, so the string can key caches and registries, or identify the types across processes. ParseTypeString
+// parses it back.
+//
+// The named types are identified by their package paths, names and type arguments, so their Underlying isn't part of
+// the string, and the types without a package, like the local types, are prefixed by a dot, like ".Node". The type
+// parameters are identified by their names, and the type parameters of a generic type's definition prefix it, like
+// "[T any]struct{Value T}". The names of the function parameters are omitted, and the methods of the interfaces are
+// sorted by name. An empty Type is "invalid type".
+func TypeString(typ Type) string {
+	var b strings.Builder
+	writeTypeString(&b, typ)
+	return b.String()
+}
+
+func writeTypeString(b *strings.Builder, typ Type) {
+	if len(typ.TypeParams) > 0 {
+		b.WriteString("[")
+		for n, param := range typ.TypeParams {
+			if n > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(param.Name + " ")
+			writeTypeString(b, param.Constraint)
+		}
+		b.WriteString("]")
+	}
+
+	switch {
+	case typ.PrimitiveType != nil:
+		b.WriteString(string(primitiveKindAlias(typ.PrimitiveType.Kind)))
+	case typ.QualType != nil:
+		b.WriteString(typ.QualType.Package + "." + typ.QualType.Name)
+		writeTypeListString(b, typ.QualType.TypeArgs)
+	case typ.ChanType != nil:
+		switch typ.ChanType.Dir {
+		case ChanTypeDirRecv:
+			b.WriteString("<-chan ")
+		case ChanTypeDirSend:
+			b.WriteString("chan<- ")
+		default:
+			b.WriteString("chan ")
+		}
+		elem := typ.ChanType.Elem
+		// `chan (<-chan T)` isn't `chan<- chan T`.
+		parens := typ.ChanType.Dir == ChanTypeDirBoth && elem.ChanType != nil && elem.ChanType.Dir == ChanTypeDirRecv
+		if parens {
+			b.WriteString("(")
+		}
+		writeTypeString(b, elem)
+		if parens {
+			b.WriteString(")")
+		}
+	case typ.SliceType != nil:
+		b.WriteString("[]")
+		writeTypeString(b, typ.SliceType.Elem)
+	case typ.PtrType != nil:
+		b.WriteString("*")
+		writeTypeString(b, typ.PtrType.Elem)
+	case typ.ArrayType != nil:
+		b.WriteString("[" + strconv.Itoa(typ.ArrayType.Len) + "]")
+		writeTypeString(b, typ.ArrayType.Elem)
+	case typ.MapType != nil:
+		b.WriteString("map[")
+		writeTypeString(b, typ.MapType.Key)
+		b.WriteString("]")
+		writeTypeString(b, typ.MapType.Elem)
+	case typ.FuncType != nil:
+		b.WriteString("func")
+		writeSignatureString(b, *typ.FuncType)
+	case typ.StructType != nil:
+		b.WriteString("struct{")
+		for n, field := range typ.StructType.Fields {
+			if n > 0 {
+				b.WriteString("; ")
+			}
+			if !field.Embedded {
+				b.WriteString(field.Name + " ")
+			}
+			writeTypeString(b, field.Type)
+			if field.Tag != "" {
+				b.WriteString(" " + strconv.Quote(string(field.Tag)))
+			}
+		}
+		b.WriteString("}")
+	case typ.InterfaceType != nil:
+		writeInterfaceString(b, *typ.InterfaceType)
+	case typ.TypeParamType != nil:
+		b.WriteString(typ.TypeParamType.Name)
+	default:
+		b.WriteString("invalid type")
+	}
+}
+
+func writeTypeListString(b *strings.Builder, types []Type) {
+	if len(types) == 0 {
+		return
+	}
+	b.WriteString("[")
+	for n, typ := range types {
+		if n > 0 {
+			b.WriteString(", ")
+		}
+		writeTypeString(b, typ)
+	}
+	b.WriteString("]")
+}
+
+func writeSignatureString(b *strings.Builder, funcType FuncType) {
+	b.WriteString("(")
+	for n, input := range funcType.Inputs {
+		if n > 0 {
+			b.WriteString(", ")
+		}
+		if funcType.IsVariadic && n == len(funcType.Inputs)-1 {
+			b.WriteString("...")
+		}
+		writeTypeString(b, input.Type)
+	}
+	b.WriteString(")")
+	switch len(funcType.Outputs) {
+	case 0:
+	case 1:
+		b.WriteString(" ")
+		writeTypeString(b, funcType.Outputs[0].Type)
+	default:
+		b.WriteString(" (")
+		for n, output := range funcType.Outputs {
+			if n > 0 {
+				b.WriteString(", ")
+			}
+			writeTypeString(b, output.Type)
+		}
+		b.WriteString(")")
+	}
+}
+
+func writeInterfaceString(b *strings.Builder, interfaceType InterfaceType) {
+	elements := make([]string, 0, len(interfaceType.Methods)+len(interfaceType.Embedded)+len(interfaceType.Unions))
+	methods := append([]InterfaceTypeMethod(nil), interfaceType.Methods...)
+	sort.Slice(methods, func(i, j int) bool {
+		return methods[i].Name < methods[j].Name
+	})
+	for _, method := range methods {
+		var element strings.Builder
+		element.WriteString(method.Name)
+		writeSignatureString(&element, method.Func)
+		elements = append(elements, element.String())
+	}
+	for _, embedded := range interfaceType.Embedded {
+		elements = append(elements, TypeString(embedded.Type()))
+	}
+	for _, union := range interfaceType.Unions {
+		terms := make([]string, 0, len(union))
+		for _, term := range union {
+			if term.Tilde {
+				terms = append(terms, "~"+TypeString(term.Type))
+			} else {
+				terms = append(terms, TypeString(term.Type))
+			}
+		}
+		sort.Strings(terms)
+		elements = append(elements, strings.Join(terms, " | "))
+	}
+	b.WriteString("interface{" + strings.Join(elements, "; ") + "}")
+}
+
+// ParseTypeString parses a canonical string returned by TypeString. The ShortPackagePath of the parsed QualTypes is
+// the last element of their package paths, without the major version suffix of the paths like "gopkg.in/yaml.v3",
+// which is the package name by convention.
+func ParseTypeString(s string) (Type, error) {
+	p := &typeStringParser{s: s}
+	typ, err := p.parseType()
+	if err == nil && p.pos < len(p.s) {
+		err = p.errorf("unexpected %q", p.s[p.pos:])
+	}
+	if err != nil {
+		return Type{}, fmt.Errorf("cannot parse type string %q: %w", s, err)
+	}
+	return typ, nil
+}
+
+// typeStringParser is a recursive descent parser of the canonical type strings.
+type typeStringParser struct {
+	s   string
+	pos int
+}
+
+func (p *typeStringParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("offset %d: %w", p.pos, fmt.Errorf(format, args...))
+}
+
+func (p *typeStringParser) consume(prefix string) bool {
+	if strings.HasPrefix(p.s[p.pos:], prefix) {
+		p.pos += len(prefix)
+		return true
+	}
+	return false
+}
+
+func (p *typeStringParser) expect(prefix string) error {
+	if !p.consume(prefix) {
+		return p.errorf("expected %q", prefix)
+	}
+	return nil
+}
+
+// name returns the next name, which is an identifier or a package-qualified name.
+func (p *typeStringParser) name() string {
+	start := p.pos
+	for p.pos < len(p.s) && !strings.ContainsRune(" \t,;|()[]{}\"*~", rune(p.s[p.pos])) {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+func (p *typeStringParser) parseType() (Type, error) {
+	if p.consume("[") {
+		if p.consume("]") {
+			elem, err := p.parseType()
+			return Type{SliceType: &SliceType{Elem: elem}}, err
+		}
+		if p.pos < len(p.s) && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+			return p.parseArray()
+		}
+		return p.parseGeneric()
+	}
+	switch {
+	case p.consume("*"):
+		elem, err := p.parseType()
+		return Type{PtrType: &PtrType{Elem: elem}}, err
+	case p.consume("<-chan "):
+		elem, err := p.parseType()
+		return Type{ChanType: &ChanType{Dir: ChanTypeDirRecv, Elem: elem}}, err
+	case p.consume("chan<- "):
+		elem, err := p.parseType()
+		return Type{ChanType: &ChanType{Dir: ChanTypeDirSend, Elem: elem}}, err
+	case p.consume("chan ("):
+		elem, err := p.parseType()
+		if err != nil {
+			return Type{}, err
+		}
+		return Type{ChanType: &ChanType{Dir: ChanTypeDirBoth, Elem: elem}}, p.expect(")")
+	case p.consume("chan "):
+		elem, err := p.parseType()
+		return Type{ChanType: &ChanType{Dir: ChanTypeDirBoth, Elem: elem}}, err
+	case p.consume("map["):
+		key, err := p.parseType()
+		if err != nil {
+			return Type{}, err
+		}
+		if err := p.expect("]"); err != nil {
+			return Type{}, err
+		}
+		elem, err := p.parseType()
+		return Type{MapType: &MapType{Key: key, Elem: elem}}, err
+	case p.consume("func("):
+		p.pos--
+		funcType, err := p.parseSignature()
+		return Type{FuncType: &funcType}, err
+	case p.consume("struct{"):
+		return p.parseStruct()
+	case p.consume("interface{"):
+		interfaceType, err := p.parseInterface()
+		return Type{InterfaceType: &interfaceType}, err
+	case p.consume("invalid type"):
+		return Type{}, nil
+	}
+	return p.parseNamed()
+}
+
+func (p *typeStringParser) parseArray() (Type, error) {
+	start := p.pos
+	for p.pos < len(p.s) && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+		p.pos++
+	}
+	length, err := strconv.Atoi(p.s[start:p.pos])
+	if err != nil {
+		return Type{}, p.errorf("invalid array length: %w", err)
+	}
+	if err := p.expect("]"); err != nil {
+		return Type{}, err
+	}
+	elem, err := p.parseType()
+	return Type{ArrayType: &ArrayType{Len: length, Elem: elem}}, err
+}
+
+// parseGeneric parses the definition of a generic type prefixed by its type parameters, after the "[".
+func (p *typeStringParser) parseGeneric() (Type, error) {
+	params := make([]TypeParam, 0)
+	for {
+		name := p.name()
+		if name == "" {
+			return Type{}, p.errorf("expected a type parameter")
+		}
+		if err := p.expect(" "); err != nil {
+			return Type{}, err
+		}
+		constraint, err := p.parseType()
+		if err != nil {
+			return Type{}, err
+		}
+		params = append(params, TypeParam{Name: name, Constraint: constraint})
+		if p.consume("]") {
+			break
+		}
+		if err := p.expect(", "); err != nil {
+			return Type{}, err
+		}
+	}
+	typ, err := p.parseType()
+	typ.TypeParams = params
+	return typ, err
+}
+
+func (p *typeStringParser) parseNamed() (Type, error) {
+	name := p.name()
+	if name == "" {
+		return Type{}, p.errorf("expected a type")
+	}
+	dot := strings.LastIndex(name, ".")
+	if dot < 0 {
+		if isPrimitiveKind(name) {
+			return Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKind(name)}}, nil
+		}
+		return Type{TypeParamType: &TypeParamType{Name: name}}, nil
+	}
+
+	qualType := &QualType{Package: name[:dot], Name: name[dot+1:]}
+	if qualType.Package != "" {
+		qualType.ShortPackagePath = packageNameOfPath(qualType.Package)
+	}
+	if p.consume("[") {
+		for {
+			arg, err := p.parseType()
+			if err != nil {
+				return Type{}, err
+			}
+			qualType.TypeArgs = append(qualType.TypeArgs, arg)
+			if p.consume("]") {
+				break
+			}
+			if err := p.expect(", "); err != nil {
+				return Type{}, err
+			}
+		}
+	}
+	return Type{QualType: qualType}, nil
+}
+
+// parseSignature parses the parameters and the results of a function, starting at its "(".
+func (p *typeStringParser) parseSignature() (FuncType, error) {
+	funcType := FuncType{}
+	if err := p.expect("("); err != nil {
+		return FuncType{}, err
+	}
+	for !p.consume(")") {
+		if len(funcType.Inputs) > 0 {
+			if err := p.expect(", "); err != nil {
+				return FuncType{}, err
+			}
+		}
+		if funcType.IsVariadic {
+			return FuncType{}, p.errorf("variadic parameter isn't the last one")
+		}
+		funcType.IsVariadic = p.consume("...")
+		input, err := p.parseType()
+		if err != nil {
+			return FuncType{}, err
+		}
+		funcType.Inputs = append(funcType.Inputs, TypeField{Type: input})
+	}
+
+	switch {
+	case p.consume(" ("):
+		for !p.consume(")") {
+			if len(funcType.Outputs) > 0 {
+				if err := p.expect(", "); err != nil {
+					return FuncType{}, err
+				}
+			}
+			output, err := p.parseType()
+			if err != nil {
+				return FuncType{}, err
+			}
+			funcType.Outputs = append(funcType.Outputs, TypeField{Type: output})
+		}
+	case p.startsResult():
+		p.pos++
+		output, err := p.parseType()
+		if err != nil {
+			return FuncType{}, err
+		}
+		funcType.Outputs = append(funcType.Outputs, TypeField{Type: output})
+	}
+	return funcType, nil
+}
+
+// startsResult reports whether the space following the parameters of a function starts its single result, instead
+// of a field tag or the next term of a union.
+func (p *typeStringParser) startsResult() bool {
+	rest := p.s[p.pos:]
+	return strings.HasPrefix(rest, " ") && !strings.HasPrefix(rest, ` "`) && !strings.HasPrefix(rest, " |")
+}
+
+// parseStruct parses the fields of a struct, after the "struct{".
+func (p *typeStringParser) parseStruct() (Type, error) {
+	structType := &StructType{}
+	for !p.consume("}") {
+		if len(structType.Fields) > 0 {
+			if err := p.expect("; "); err != nil {
+				return Type{}, err
+			}
+		}
+		// the embedded fields only contain their type, which is either qualified, or followed by the separator, the end of
+		// the struct or the field's tag.
+		start := p.pos
+		field := TypeField{Name: p.name()}
+		if field.Name == "" || strings.Contains(field.Name, ".") || !p.consume(" ") || strings.HasPrefix(p.s[p.pos:], `"`) {
+			p.pos = start
+			field = TypeField{Embedded: true}
+		}
+		typ, err := p.parseType()
+		if err != nil {
+			return Type{}, err
+		}
+		field.Type = typ
+		if field.Embedded {
+			if field.Name = embeddedTypeName(typ); field.Name == "" {
+				return Type{}, p.errorf("invalid embedded field %s", TypeString(typ))
+			}
+		}
+		if p.consume(" ") {
+			quoted, err := strconv.QuotedPrefix(p.s[p.pos:])
+			if err != nil {
+				return Type{}, p.errorf("invalid field tag: %w", err)
+			}
+			p.pos += len(quoted)
+			tag, _ := strconv.Unquote(quoted)
+			field.Tag = reflect.StructTag(tag)
+		}
+		structType.Fields = append(structType.Fields, field)
+	}
+	return Type{StructType: structType}, nil
+}
+
+// embeddedTypeName returns the name of a field embedding `typ`, which is the name of the type, or of the type it
+// points to. It's empty when the type can't be embedded.
+func embeddedTypeName(typ Type) string {
+	if typ.PtrType != nil {
+		typ = typ.PtrType.Elem
+	}
+	switch {
+	case typ.QualType != nil:
+		return typ.QualType.Name
+	case typ.PrimitiveType != nil:
+		return string(typ.PrimitiveType.Kind)
+	case typ.TypeParamType != nil:
+		return typ.TypeParamType.Name
+	}
+	return ""
+}
+
+// parseInterface parses the elements of an interface, after the "interface{".
+func (p *typeStringParser) parseInterface() (InterfaceType, error) {
+	interfaceType := InterfaceType{}
+	for n := 0; !p.consume("}"); n++ {
+		if n > 0 {
+			if err := p.expect("; "); err != nil {
+				return InterfaceType{}, err
+			}
+		}
+		start := p.pos
+		name := p.name()
+		if name != "" && !strings.Contains(name, ".") && strings.HasPrefix(p.s[p.pos:], "(") {
+			funcType, err := p.parseSignature()
+			if err != nil {
+				return InterfaceType{}, err
+			}
+			interfaceType.Methods = append(interfaceType.Methods, InterfaceTypeMethod{Name: name, Func: funcType})
+			continue
+		}
+
+		p.pos = start
+		terms := make([]TypeTerm, 0)
+		for {
+			tilde := p.consume("~")
+			typ, err := p.parseType()
+			if err != nil {
+				return InterfaceType{}, err
+			}
+			terms = append(terms, TypeTerm{Tilde: tilde, Type: typ})
+			if !p.consume(" | ") {
+				break
+			}
+		}
+		if len(terms) == 1 && !terms[0].Tilde && terms[0].Type.QualType != nil {
+			interfaceType.Embedded = append(interfaceType.Embedded, *terms[0].Type.QualType)
+			continue
+		}
+		interfaceType.Unions = append(interfaceType.Unions, terms)
+	}
+	return interfaceType, nil
+}
+
+// isPrimitiveKind reports whether `name` is the name of a PrimitiveKind.
+func isPrimitiveKind(name string) bool {
+	switch PrimitiveKind(name) {
+	case PrimitiveKindBool, PrimitiveKindByte, PrimitiveKindRune, PrimitiveKindString, PrimitiveKindError,
+		PrimitiveKindInt, PrimitiveKindInt8, PrimitiveKindInt16, PrimitiveKindInt32, PrimitiveKindInt64,
+		PrimitiveKindUint, PrimitiveKindUint8, PrimitiveKindUint16, PrimitiveKindUint32, PrimitiveKindUint64,
+		PrimitiveKindUintptr, PrimitiveKindFloat32, PrimitiveKindFloat64, PrimitiveKindComplex64,
+		PrimitiveKindComplex128:
+		return true
+	}
+	return false
+}
+
+// packageNameOfPath returns the conventional name of the package `packagePath`, which is the last element of the path
+// without its major version suffix, like "yaml" for "gopkg.in/yaml.v3" and "chi" for "github.com/go-chi/chi/v5".
+func packageNameOfPath(packagePath string) string {
+	name := path.Base(packagePath)
+	if isMajorVersion(name) && path.Dir(packagePath) != "." {
+		name = path.Base(path.Dir(packagePath))
+	}
+	if dot := strings.LastIndex(name, ".v"); dot > 0 && isMajorVersion(name[dot+1:]) {
+		name = name[:dot]
+	}
+	return name
+}
+
+// isMajorVersion reports whether `s` is a major version suffix, like "v2".
+func isMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(s[1:])
+	return err == nil
+}
@@ -0,0 +1,96 @@
+package gotype
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypeString(t *testing.T) {
+	uuidType := Type{QualType: &QualType{Package: "github.com/google/uuid", ShortPackagePath: "uuid", Name: "UUID"}}
+	for expected, typ := range map[string]Type{
+		"map[string][]*github.com/google/uuid.UUID": {MapType: &MapType{
+			Key:  Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindString}},
+			Elem: Type{SliceType: &SliceType{Elem: Type{PtrType: &PtrType{Elem: uuidType}}}},
+		}},
+		"[]uint8": {SliceType: &SliceType{Elem: Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindByte}}}},
+		"chan (<-chan int)": {ChanType: &ChanType{Dir: ChanTypeDirBoth, Elem: Type{ChanType: &ChanType{
+			Dir:  ChanTypeDirRecv,
+			Elem: Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindInt}},
+		}}}},
+		"func(int, ...string) (.Node, error)": {FuncType: &FuncType{
+			Inputs: []TypeField{
+				{Name: "n", Type: Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindInt}}},
+				{Name: "values", Type: Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindString}}},
+			},
+			Outputs: []TypeField{
+				{Type: Type{QualType: &QualType{Name: "Node"}}},
+				{Type: Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindError}}},
+			},
+			IsVariadic: true,
+		}},
+		`struct{ID github.com/google/uuid.UUID "json:\"id\""; Next func() [2]int}`: {StructType: &StructType{
+			Fields: []TypeField{
+				{Name: "ID", Type: uuidType, Tag: `json:"id"`},
+				{Name: "Next", Type: Type{FuncType: &FuncType{Outputs: []TypeField{{Type: Type{ArrayType: &ArrayType{
+					Len:  2,
+					Elem: Type{PrimitiveType: &PrimitiveType{Kind: PrimitiveKindInt}},
+				}}}}}}},
+			},
+		}},
+	} {
+		assert.Equal(t, expected, TypeString(typ))
+		parsed, err := ParseTypeString(expected)
+		require.NoError(t, err, expected)
+		assert.True(t, Identical(typ, parsed), expected)
+		assert.Equal(t, expected, TypeString(parsed))
+	}
+
+	parsed, err := ParseTypeString("invalid type")
+	require.NoError(t, err)
+	assert.Equal(t, Type{}, parsed)
+
+	parsed, err = ParseTypeString("gopkg.in/yaml.v3.Node")
+	require.NoError(t, err)
+	assert.Equal(t, QualType{Package: "gopkg.in/yaml.v3", ShortPackagePath: "yaml", Name: "Node"}, *parsed.QualType)
+}
+
+func TestTypeString_Generated(t *testing.T) {
+	for _, name := range []string{"Repository", "Pair", "Max", "Vector", "Mapper", "Appender", "Index", "Tree"} {
+		types, err := NewGenerator(WithDeepResolution()).GenerateTypesFromSpecs(
+			TypeSpec{PackagePath: testdataPackage + "/generics", Name: name},
+		)
+		require.NoError(t, err)
+		typeString := TypeString(types[0])
+		parsed, err := ParseTypeString(typeString)
+		require.NoError(t, err)
+		assert.Equal(t, typeString, TypeString(parsed))
+		assert.True(t, Identical(types[0], parsed), typeString)
+	}
+
+	types, err := GenerateTypesFromSpecs(TypeSpec{PackagePath: testdataPackage + "/generics", Name: "Vector"})
+	require.NoError(t, err)
+	assert.Equal(t, "[T interface{float64 | int}][]T", TypeString(types[0]))
+
+	// the methods are sorted, so the order of the declaration doesn't matter.
+	x := InterfaceType{Methods: []InterfaceTypeMethod{{Name: "B"}, {Name: "A"}}}
+	y := InterfaceType{Methods: []InterfaceTypeMethod{{Name: "A"}, {Name: "B"}}}
+	assert.Equal(t, "interface{A(); B()}", TypeString(Type{InterfaceType: &x}))
+	assert.Equal(t, TypeString(Type{InterfaceType: &x}), TypeString(Type{InterfaceType: &y}))
+}
+
+func TestParseTypeString_Errors(t *testing.T) {
+	for s, message := range map[string]string{
+		"":           `cannot parse type string "": offset 0: expected a type`,
+		"map[string": `cannot parse type string "map[string": offset 10: expected "]"`,
+		"[]int]":     `cannot parse type string "[]int]": offset 5: unexpected "]"`,
+		"func(...int, int)": `cannot parse type string "func(...int, int)": offset 13: ` +
+			`variadic parameter isn't the last one`,
+		`struct{A int "x}`: `cannot parse type string "struct{A int \"x}": offset 13: invalid field tag: ` +
+			`invalid syntax`,
+	} {
+		_, err := ParseTypeString(s)
+		assert.EqualError(t, err, message)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !go1.22
+// +build !go1.22
+
+package gotype
+
+import "go/types"
+
+// unalias returns t unchanged: before Go 1.22, go/types never materializes aliases, so every alias is already
+// replaced by the type it denotes.
+func unalias(t types.Type) types.Type {
+	return t
+}
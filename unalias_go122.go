@@ -0,0 +1,11 @@
+//go:build go1.22
+// +build go1.22
+
+package gotype
+
+import "go/types"
+
+// unalias returns the type denoted by t, following the chain of materialized aliases.
+func unalias(t types.Type) types.Type {
+	return types.Unalias(t)
+}
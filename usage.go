@@ -0,0 +1,260 @@
+package gotype
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"sort"
+	"strconv"
+)
+
+// UsageKind describes where a type is referenced inside a declaration.
+type UsageKind string
+
+const (
+	// UsageKindField is the type of a struct's field, or a type nested inside it, like `sub.Item` in
+	// `Items []*sub.Item`.
+	UsageKindField UsageKind = "field"
+
+	// UsageKindSignature is a parameter or a result of a method, declared either inside an interface or with a
+	// receiver.
+	UsageKindSignature UsageKind = "signature"
+
+	// UsageKindEmbedding is an interface embedded inside an interface.
+	UsageKindEmbedding UsageKind = "embedding"
+
+	// UsageKindDefinition is the definition of a non-struct and non-interface type, like `type IDs []ID`, a union
+	// term of a constraint, or a constraint of a type parameter.
+	UsageKindDefinition UsageKind = "definition"
+)
+
+// TypeUsageReport contains the usage statistics computed by `TypeGenerator.AnalyzeTypeUsage`.
+type TypeUsageReport struct {
+	// Types contains the referenced types, the most referenced first. The types referenced as many times are sorted by
+	// their package and name.
+	Types []TypeUsage `json:"types"`
+}
+
+// TypeUsage contains the references to a single type.
+type TypeUsage struct {
+	// Package contains the package path of the referenced type.
+	Package string `json:"package"`
+
+	// Name contains the name of the referenced type.
+	Name string `json:"name"`
+
+	// Count contains the number of references, that is, the length of References.
+	Count int `json:"count"`
+
+	// References contains the references to the type, in the order of the packages and of their declarations.
+	References []TypeReference `json:"references"`
+}
+
+// TypeReference describes a single reference to a type.
+type TypeReference struct {
+	// Package contains the path of the package making the reference.
+	Package string `json:"package"`
+
+	// Declaration contains the declaration making the reference, like "Order" for a type definition or an embedding,
+	// "Order.Items" for a field, and "Order.Total" for a method.
+	Declaration string `json:"declaration"`
+
+	// Kind describes where the type is referenced inside the declaration.
+	Kind UsageKind `json:"kind"`
+
+	// Position contains the location of the declaration making the reference, like a field or a method.
+	Position string `json:"position"`
+}
+
+// JSON encodes the report as indented JSON.
+func (r TypeUsageReport) JSON() ([]byte, error) {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode the type usage report: %w", err)
+	}
+	return data, nil
+}
+
+// CSV encodes the report as CSV, with a header and a row for each reference. The referenced type and its count are
+// repeated on all its rows.
+func (r TypeUsageReport) CSV() ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	records := [][]string{{"package", "name", "count", "referencing_package", "declaration", "kind", "position"}}
+	for _, usage := range r.Types {
+		for _, reference := range usage.References {
+			records = append(records, []string{
+				usage.Package,
+				usage.Name,
+				strconv.Itoa(usage.Count),
+				reference.Package,
+				reference.Declaration,
+				string(reference.Kind),
+				reference.Position,
+			})
+		}
+	}
+	if err := w.WriteAll(records); err != nil {
+		return nil, fmt.Errorf("cannot encode the type usage report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// AnalyzeTypeUsage reports which types are referenced by the types declared inside the packages matched by
+// `packagePatterns`. See `TypeGenerator.AnalyzeTypeUsage` for the details.
+func AnalyzeTypeUsage(packagePatterns ...string) (TypeUsageReport, error) {
+	return defaultAstTypeGenerator.AnalyzeTypeUsage(packagePatterns...)
+}
+
+func (f *astTypeGenerator) AnalyzeTypeUsage(packagePatterns ...string) (TypeUsageReport, error) {
+	f = f.fork()
+	packages, err := f.expandPackagePatterns(packagePatterns...)
+	if err != nil {
+		return TypeUsageReport{}, err
+	}
+
+	usages := make(map[string]*TypeUsage)
+	collector := usageCollector{
+		add: func(q QualType, reference TypeReference) {
+			key := qualTypeKey(q)
+			if usages[key] == nil {
+				usages[key] = &TypeUsage{
+					Package:    f.rewritePackagePath(q.Package),
+					Name:       q.Name,
+					References: make([]TypeReference, 0),
+				}
+			}
+			reference.Package = f.rewritePackagePath(reference.Package)
+			usages[key].Count++
+			usages[key].References = append(usages[key].References, reference)
+		},
+	}
+
+	for _, packagePath := range packages {
+		names, err := f.getDeclaredTypeNames(packagePath)
+		if err != nil {
+			return TypeUsageReport{}, err
+		}
+
+		specs := make([]TypeSpec, 0, len(names))
+		for _, name := range names {
+			specs = append(specs, TypeSpec{PackagePath: packagePath, Name: name})
+		}
+		types, err := f.generateTypesFromSpecs(specs)
+		if err != nil {
+			return TypeUsageReport{}, err
+		}
+
+		for i, typ := range types {
+			name := specs[i].Name
+			collector.collectDeclaration(packagePath, name, typ)
+			if typ.InterfaceType != nil {
+				continue
+			}
+
+			methods, err := f.generateMethods(packagePath, name)
+			if err != nil {
+				return TypeUsageReport{}, err
+			}
+			for _, method := range methods {
+				collector.collectFunc(
+					packagePath,
+					name+"."+method.method.Name,
+					method.method.Position,
+					method.method.Func,
+				)
+			}
+		}
+	}
+
+	report := TypeUsageReport{Types: make([]TypeUsage, 0, len(usages))}
+	for _, usage := range usages {
+		report.Types = append(report.Types, *usage)
+	}
+	sort.Slice(report.Types, func(i, j int) bool {
+		a, b := report.Types[i], report.Types[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		return a.Name < b.Name
+	})
+	return report, nil
+}
+
+// usageCollector reports the QualTypes referenced by the declarations to `add`.
+type usageCollector struct {
+	add func(q QualType, reference TypeReference)
+}
+
+func (c usageCollector) collectDeclaration(packagePath, name string, typ Type) {
+	definition := TypeReference{Package: packagePath, Declaration: name, Kind: UsageKindDefinition}
+	for _, param := range typ.TypeParams {
+		c.collectType(param.Constraint, definition)
+	}
+
+	switch {
+	case typ.StructType != nil:
+		for _, field := range typ.StructType.Fields {
+			c.collectType(field.Type, TypeReference{
+				Package:     packagePath,
+				Declaration: name + "." + field.Name,
+				Kind:        UsageKindField,
+				Position:    field.Position.String(),
+			})
+		}
+	case typ.InterfaceType != nil:
+		for _, method := range typ.InterfaceType.Methods {
+			// the flattened methods of the embedded interfaces are referenced by the embedded interfaces.
+			if method.Origin == nil {
+				c.collectFunc(packagePath, name+"."+method.Name, method.Position, method.Func)
+			}
+		}
+		for _, embedded := range typ.InterfaceType.Embedded {
+			if embedded.Package != "" {
+				c.add(embedded, TypeReference{Package: packagePath, Declaration: name, Kind: UsageKindEmbedding})
+			}
+		}
+		for _, union := range typ.InterfaceType.Unions {
+			for _, term := range union {
+				c.collectType(term.Type, definition)
+			}
+		}
+	default:
+		c.collectType(typ, definition)
+	}
+}
+
+func (c usageCollector) collectFunc(packagePath, declaration string, position token.Position, funcType FuncType) {
+	reference := TypeReference{
+		Package:     packagePath,
+		Declaration: declaration,
+		Kind:        UsageKindSignature,
+		Position:    position.String(),
+	}
+	for _, param := range append(append([]TypeField{}, funcType.Inputs...), funcType.Outputs...) {
+		c.collectType(param.Type, reference)
+	}
+}
+
+// collectType reports the QualTypes inside `t`, including the type arguments of the generic types. The embedded
+// interfaces of the interface literals are reported as well.
+func (c usageCollector) collectType(t Type, reference TypeReference) {
+	mapType(t, func(t Type) (Type, bool) {
+		switch {
+		case t.QualType != nil && t.QualType.Package != "":
+			c.add(*t.QualType, reference)
+		case t.InterfaceType != nil:
+			for _, embedded := range t.InterfaceType.Embedded {
+				if embedded.Package != "" {
+					c.add(embedded, reference)
+				}
+			}
+		}
+		return t, false
+	})
+}
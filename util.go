@@ -1,6 +1,8 @@
 package gotype
 
 import (
+	"path/filepath"
+	"runtime"
 	"strings"
 )
 
@@ -70,3 +72,51 @@ func maxUint8(a, b uint8) uint8 {
 	}
 	return b
 }
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// normalizePath cleans the path `p`, so the same file is always named the same way. On Windows, it's also normalized
+// by `normalizeWindowsPath`.
+func normalizePath(p string) string {
+	if runtime.GOOS == "windows" {
+		p = normalizeWindowsPath(p)
+	}
+	return filepath.Clean(p)
+}
+
+// normalizeWindowsPath replaces the forward slashes of the Windows path `p`, like the ones printed by git, removes its
+// long path prefix "\\?\", and upper-cases its drive letter. The os package adds the long path prefix back when it's
+// needed.
+func normalizeWindowsPath(p string) string {
+	p = strings.ReplaceAll(p, "/", `\`)
+	switch {
+	case strings.HasPrefix(p, `\\?\UNC\`):
+		p = `\\` + strings.TrimPrefix(p, `\\?\UNC\`)
+	case strings.HasPrefix(p, `\\?\`):
+		p = strings.TrimPrefix(p, `\\?\`)
+	}
+	if len(p) >= 2 && p[1] == ':' && 'a' <= p[0] && p[0] <= 'z' {
+		p = strings.ToUpper(p[:1]) + p[1:]
+	}
+	return p
+}
+
+// pathKey returns the key identifying the path `p` inside the maps, so the paths naming the same file are deduplicated.
+// The file systems used by default on Windows and macOS are case-insensitive, so the keys of their paths are
+// lower-cased.
+func pathKey(p string) string {
+	return pathKeyFor(runtime.GOOS, normalizePath(p))
+}
+
+func pathKeyFor(goos, p string) string {
+	switch goos {
+	case "windows", "darwin", "ios":
+		return strings.ToLower(p)
+	}
+	return p
+}
@@ -42,3 +42,96 @@ func TestParseInt(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeWindowsPath(t *testing.T) {
+	testcases := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "drive letter", path: `c:\Users\go\src`, expected: `C:\Users\go\src`},
+		{name: "forward slashes", path: "C:/Users/go/src", expected: `C:\Users\go\src`},
+		{name: "long path", path: `\\?\c:\Users\go\src`, expected: `C:\Users\go\src`},
+		{name: "unc", path: `\\server\share\go`, expected: `\\server\share\go`},
+		{name: "long unc", path: `\\?\UNC\server\share\go`, expected: `\\server\share\go`},
+		{name: "relative", path: "mod/pkg", expected: `mod\pkg`},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, normalizeWindowsPath(tc.path))
+		})
+	}
+}
+
+func TestPathKey(t *testing.T) {
+	assert.Equal(t, pathKeyFor("windows", `C:\Users\Go`), pathKeyFor("windows", `C:\users\go`))
+	assert.Equal(t, pathKeyFor("darwin", "/Users/Go"), pathKeyFor("darwin", "/Users/go"))
+	assert.NotEqual(t, pathKeyFor("linux", "/home/Go"), pathKeyFor("linux", "/home/go"))
+	assert.Equal(t, pathKey("/home/go/./src/"), pathKey("/home/go/src"))
+}
+
+func TestPrimitiveKindPredicates(t *testing.T) {
+	testcases := []struct {
+		kind                                                      PrimitiveKind
+		numeric, integer, unsigned, float, complex, str, boolLike bool
+	}{
+		{kind: PrimitiveKindInt, numeric: true, integer: true},
+		{kind: PrimitiveKindRune, numeric: true, integer: true},
+		{kind: PrimitiveKindByte, numeric: true, integer: true, unsigned: true},
+		{kind: PrimitiveKindUintptr, numeric: true, integer: true, unsigned: true},
+		{kind: PrimitiveKindFloat32, numeric: true, float: true},
+		{kind: PrimitiveKindComplex128, numeric: true, complex: true},
+		{kind: PrimitiveKindString, str: true},
+		{kind: PrimitiveKindBool, boolLike: true},
+		{kind: PrimitiveKindError},
+	}
+
+	for _, tc := range testcases {
+		t.Run(string(tc.kind), func(t *testing.T) {
+			assert.Equal(t, tc.numeric, tc.kind.IsNumeric())
+			assert.Equal(t, tc.integer, tc.kind.IsInteger())
+			assert.Equal(t, tc.unsigned, tc.kind.IsUnsigned())
+			assert.Equal(t, tc.float, tc.kind.IsFloat())
+			assert.Equal(t, tc.complex, tc.kind.IsComplex())
+			assert.Equal(t, tc.str, tc.kind.IsString())
+			assert.Equal(t, tc.boolLike, tc.kind.IsBoolLike())
+		})
+	}
+
+	int64Type := PrimitiveType{Kind: PrimitiveKindInt64}.Type()
+	duration := Type{QualType: &QualType{Package: "time", Name: "Duration", Underlying: &int64Type}}
+	assert.True(t, duration.IsInteger())
+	assert.False(t, duration.IsUnsigned())
+	assert.False(t, Type{QualType: &QualType{Package: "time", Name: "Duration"}}.IsNumeric())
+	assert.False(t, Type{SliceType: &SliceType{Elem: int64Type}}.IsNumeric())
+}
+
+func TestNilability(t *testing.T) {
+	intType := PrimitiveType{Kind: PrimitiveKindInt}.Type()
+	sliceType := Type{SliceType: &SliceType{Elem: intType}}
+	testcases := []struct {
+		name     string
+		typ      Type
+		expected Nilability
+	}{
+		{name: "int", typ: intType, expected: NotNilable},
+		{name: "array", typ: Type{ArrayType: &ArrayType{Len: 2, Elem: intType}}, expected: NotNilable},
+		{name: "struct", typ: StructType{}.Type(), expected: NotNilable},
+		{name: "pointer", typ: Type{PtrType: &PtrType{Elem: intType}}, expected: NilableConcrete},
+		{name: "slice", typ: sliceType, expected: NilableConcrete},
+		{name: "map", typ: Type{MapType: &MapType{Key: intType, Elem: intType}}, expected: NilableConcrete},
+		{name: "func", typ: FuncType{}.Type(), expected: NilableConcrete},
+		{name: "interface", typ: InterfaceType{}.Type(), expected: NilableInterface},
+		{name: "error", typ: PrimitiveType{Kind: PrimitiveKindError}.Type(), expected: NilableInterface},
+		{name: "named slice", typ: Type{QualType: &QualType{Name: "IDs", Underlying: &sliceType}}, expected: NilableConcrete},
+		{name: "unresolved", typ: Type{QualType: &QualType{Package: "io", Name: "Reader"}}, expected: NilabilityUnknown},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.typ.Nilability())
+			assert.Equal(t, tc.expected == NilableConcrete || tc.expected == NilableInterface, tc.typ.IsNilable())
+		})
+	}
+}
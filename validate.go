@@ -0,0 +1,261 @@
+package gotype
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// defaultValidationTagKey is the default struct tag key containing the validation rules.
+const defaultValidationTagKey = "validate"
+
+// ValidationCheck is the rendered check of a validation rule.
+type ValidationCheck struct {
+	// Condition is a Golang's boolean expression which is true when the value violates the rule.
+	Condition string
+
+	// Message describes the violation. It's prefixed by the field's name, e.g. "is required".
+	Message string
+}
+
+// ValidationRule renders the check of a rule for the value `expr` of type `typ`. The `param` contains the rule's
+// parameter, like "3" in `min=3`, or an empty string when the rule doesn't have one.
+type ValidationRule func(expr string, typ Type, param string) (ValidationCheck, error)
+
+// DefaultValidationRules contains the validation rules supported by default:
+//   - required: the value isn't its zero value.
+//   - min=N, max=N: the number is at least/most N, or the length of the string, slice or map is at least/most N.
+//   - len=N: the length of the string, slice or map is exactly N.
+//   - oneof=a b c: the string or number is one of the space separated values.
+//   - email: the string looks like an email address.
+//
+// Besides the rules, the `omitempty` option skips the other rules when the value is its zero value.
+var DefaultValidationRules = map[string]ValidationRule{
+	"required": validateRequired,
+	"min":      validateBound("<", "at least"),
+	"max":      validateBound(">", "at most"),
+	"len":      validateLen,
+	"oneof":    validateOneOf,
+	"email":    validateEmail,
+}
+
+// ValidatorOption configures RenderValidator.
+type ValidatorOption func(*validatorConfig)
+
+type validatorConfig struct {
+	tagKey string
+	rules  map[string]ValidationRule
+}
+
+// WithValidationTagKey sets the struct tag key containing the validation rules. By default, it's "validate".
+func WithValidationTagKey(tagKey string) ValidatorOption {
+	return func(c *validatorConfig) {
+		c.tagKey = tagKey
+	}
+}
+
+// WithValidationRule adds a validation rule named `name`, or replaces the existing rule having the same name.
+func WithValidationRule(name string, rule ValidationRule) ValidatorOption {
+	return func(c *validatorConfig) {
+		c.rules[name] = rule
+	}
+}
+
+// RenderValidator renders the Golang's source code of the `Validate() error` method of the struct type named
+// `typeName`, whose definition is `typ`. The method checks the rules listed inside the fields' tags, like
+// `validate:"required,min=3"`, and returns an error describing the first violation. The QualTypes are validated using
+// their `Underlying` definitions filled by the deep resolution.
+//
+// The rendered code only contains the method, the package clause and imports are left to the caller. It uses the
+// "errors" package, and the "strings" package when the email rule is used.
+func RenderValidator(typeName string, typ Type, moduleName string, opts ...ValidatorOption) (string, error) {
+	if typ.StructType == nil {
+		return "", fmt.Errorf("cannot render validator of a non-struct type: %s", typ.String(moduleName))
+	}
+
+	c := validatorConfig{tagKey: defaultValidationTagKey, rules: make(map[string]ValidationRule)}
+	for name, rule := range DefaultValidationRules {
+		c.rules[name] = rule
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	receiver := string(unicode.ToLower([]rune(typeName)[0]))
+	b := strings.Builder{}
+	fmt.Fprintf(&b, "func (%s %s%s) Validate() error {\n", receiver, typeName, typeParamNames(typ.TypeParams))
+	for _, field := range typ.StructType.Fields {
+		tag, ok := field.Tag.Lookup(c.tagKey)
+		if !ok || tag == "" || tag == "-" {
+			continue
+		}
+
+		checks, err := c.renderChecks(receiver+"."+field.Name, field.Type, tag)
+		if err != nil {
+			return "", fmt.Errorf("cannot render validation of field %s: %w", field.Name, err)
+		}
+		for _, check := range checks {
+			fmt.Fprintf(&b, "\tif %s {\n", check.Condition)
+			fmt.Fprintf(&b, "\t\treturn errors.New(%s)\n", strconv.Quote(field.Name+" "+check.Message))
+			b.WriteString("\t}\n")
+		}
+	}
+	b.WriteString("\treturn nil\n}\n")
+
+	return b.String(), nil
+}
+
+// renderChecks renders the checks of the comma separated rules inside `tag`.
+func (c validatorConfig) renderChecks(expr string, typ Type, tag string) ([]ValidationCheck, error) {
+	omitEmpty := false
+	checks := make([]ValidationCheck, 0)
+	for _, rule := range strings.Split(tag, ",") {
+		name, param := rule, ""
+		if i := strings.Index(rule, "="); i >= 0 {
+			name, param = rule[:i], rule[i+1:]
+		}
+		if name == "omitempty" {
+			omitEmpty = true
+			continue
+		}
+
+		validate, ok := c.rules[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown validation rule %s", name)
+		}
+		check, err := validate(expr, typ, param)
+		if err != nil {
+			return nil, fmt.Errorf("invalid validation rule %s: %w", rule, err)
+		}
+		checks = append(checks, check)
+	}
+
+	if omitEmpty {
+		empty, err := validateRequired(expr, typ, "")
+		if err != nil {
+			return nil, err
+		}
+		for i := range checks {
+			checks[i].Condition = "!(" + empty.Condition + ") && (" + checks[i].Condition + ")"
+		}
+	}
+	return checks, nil
+}
+
+// validationUnderlying returns the type behind a QualType, which is resolved by the deep resolution.
+func validationUnderlying(typ Type) Type {
+	for typ.QualType != nil && typ.QualType.Underlying != nil {
+		typ = *typ.QualType.Underlying
+	}
+	return typ
+}
+
+func validateRequired(expr string, typ Type, _ string) (ValidationCheck, error) {
+	check := ValidationCheck{Message: "is required"}
+	if typ.QualType != nil && typ.QualType.Package == "time" && typ.QualType.Name == "Time" {
+		check.Condition = expr + ".IsZero()"
+		return check, nil
+	}
+
+	u := validationUnderlying(typ)
+	switch {
+	case isStringType(u):
+		check.Condition = expr + ` == ""`
+	case u.PrimitiveType != nil && u.PrimitiveType.Kind == PrimitiveKindBool:
+		check.Condition = "!" + expr
+	case isNumericType(u):
+		check.Condition = expr + " == 0"
+	case u.IsNilable():
+		check.Condition = expr + " == nil"
+	default:
+		return ValidationCheck{}, fmt.Errorf("cannot check whether %s is its zero value", typ.String(""))
+	}
+	return check, nil
+}
+
+// validateBound returns the rule comparing a number, or a length, with the rule's parameter using `op`.
+func validateBound(op, description string) ValidationRule {
+	return func(expr string, typ Type, param string) (ValidationCheck, error) {
+		u := validationUnderlying(typ)
+		switch {
+		case isNumericType(u):
+			if _, err := strconv.ParseFloat(param, 64); err != nil {
+				return ValidationCheck{}, fmt.Errorf("%q is not a number", param)
+			}
+			return ValidationCheck{
+				Condition: expr + " " + op + " " + param,
+				Message:   "must be " + description + " " + param,
+			}, nil
+		case hasLength(u):
+			if _, err := strconv.Atoi(param); err != nil {
+				return ValidationCheck{}, fmt.Errorf("%q is not a length", param)
+			}
+			return ValidationCheck{
+				Condition: "len(" + expr + ") " + op + " " + param,
+				Message:   "must have a length of " + description + " " + param,
+			}, nil
+		}
+		return ValidationCheck{}, fmt.Errorf("%s is neither a number nor has a length", typ.String(""))
+	}
+}
+
+func validateLen(expr string, typ Type, param string) (ValidationCheck, error) {
+	if !hasLength(validationUnderlying(typ)) {
+		return ValidationCheck{}, fmt.Errorf("%s doesn't have a length", typ.String(""))
+	}
+	if _, err := strconv.Atoi(param); err != nil {
+		return ValidationCheck{}, fmt.Errorf("%q is not a length", param)
+	}
+	return ValidationCheck{Condition: "len(" + expr + ") != " + param, Message: "must have a length of " + param}, nil
+}
+
+func validateOneOf(expr string, typ Type, param string) (ValidationCheck, error) {
+	values := strings.Fields(param)
+	if len(values) == 0 {
+		return ValidationCheck{}, fmt.Errorf("missing values")
+	}
+
+	u := validationUnderlying(typ)
+	conditions := make([]string, 0, len(values))
+	for _, value := range values {
+		switch {
+		case isStringType(u):
+			conditions = append(conditions, expr+" != "+strconv.Quote(value))
+		case isNumericType(u):
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return ValidationCheck{}, fmt.Errorf("%q is not a number", value)
+			}
+			conditions = append(conditions, expr+" != "+value)
+		default:
+			return ValidationCheck{}, fmt.Errorf("%s is neither a string nor a number", typ.String(""))
+		}
+	}
+	return ValidationCheck{
+		Condition: strings.Join(conditions, " && "),
+		Message:   "must be one of " + strings.Join(values, ", "),
+	}, nil
+}
+
+func validateEmail(expr string, typ Type, _ string) (ValidationCheck, error) {
+	if !isStringType(validationUnderlying(typ)) {
+		return ValidationCheck{}, fmt.Errorf("%s is not a string", typ.String(""))
+	}
+	return ValidationCheck{
+		Condition: "!strings.Contains(string(" + expr + "), \"@\")",
+		Message:   "must be an email address",
+	}, nil
+}
+
+func isStringType(typ Type) bool {
+	return typ.PrimitiveType != nil && typ.PrimitiveType.Kind.IsString()
+}
+
+// isNumericType reports whether the values of the Type are ordered numbers, that is, integers or floating-point numbers.
+func isNumericType(typ Type) bool {
+	return typ.PrimitiveType != nil && (typ.PrimitiveType.Kind.IsInteger() || typ.PrimitiveType.Kind.IsFloat())
+}
+
+func hasLength(typ Type) bool {
+	return isStringType(typ) || typ.SliceType != nil || typ.MapType != nil || typ.ArrayType != nil || typ.ChanType != nil
+}
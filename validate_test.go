@@ -0,0 +1,69 @@
+package gotype
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderValidator(t *testing.T) {
+	types, err := NewGenerator(WithDeepResolution()).GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: testdataPackage + "/validate", Name: "Account"},
+	)
+	require.NoError(t, err)
+
+	code, err := RenderValidator("Account", types[0], "validate")
+	require.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), "", "package validate\n"+code, 0)
+	require.NoError(t, err, code)
+
+	assert.Equal(t, `func (a Account) Validate() error {
+	if a.Name == "" {
+		return errors.New("Name is required")
+	}
+	if len(a.Name) < 3 {
+		return errors.New("Name must have a length of at least 3")
+	}
+	if len(a.Name) > 32 {
+		return errors.New("Name must have a length of at most 32")
+	}
+	if !(a.Email == "") && (!strings.Contains(string(a.Email), "@")) {
+		return errors.New("Email must be an email address")
+	}
+	if a.Age < 18 {
+		return errors.New("Age must be at least 18")
+	}
+	if a.Role != "admin" && a.Role != "user" {
+		return errors.New("Role must be one of admin, user")
+	}
+	if len(a.Tags) > 5 {
+		return errors.New("Tags must have a length of at most 5")
+	}
+	if a.CreatedAt.IsZero() {
+		return errors.New("CreatedAt is required")
+	}
+	return nil
+}
+`, code)
+
+	code, err = RenderValidator("Account", types[0], "validate", WithValidationTagKey("check"))
+	require.NoError(t, err)
+	assert.Contains(t, code, "\tif len(a.Code) != 6 {\n\t\treturn errors.New(\"Code must have a length of 6\")\n\t}\n")
+	assert.NotContains(t, code, "a.Name")
+
+	code, err = RenderValidator("Account", types[0], "validate", WithValidationRule(
+		"email",
+		func(expr string, typ Type, param string) (ValidationCheck, error) {
+			return ValidationCheck{Condition: "!isEmail(" + expr + ")", Message: "is invalid"}, nil
+		},
+	))
+	require.NoError(t, err)
+	assert.Contains(t, code, "!(a.Email == \"\") && (!isEmail(a.Email))")
+
+	types[0].StructType.Fields[2].Tag = `validate:"unknown"`
+	_, err = RenderValidator("Account", types[0], "validate")
+	assert.EqualError(t, err, "cannot render validation of field Age: unknown validation rule unknown")
+}
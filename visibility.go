@@ -0,0 +1,80 @@
+package gotype
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"strings"
+)
+
+// ErrInternalPackage is wrapped by the errors about the types depending on internal packages which can't be imported
+// by the target package. See `WithTargetPackage`.
+var ErrInternalPackage = errors.New("use of internal package")
+
+// checkVisibility checks that the packages of the type specified by `spec`, whose definition is `typ`, and of the
+// QualTypes inside it can be imported by the target package. The QualTypes' `Underlying` definitions aren't checked,
+// since the code using the type doesn't refer to them. A violation is reported as a warning when a warning handler is
+// configured, and returned as an error otherwise.
+func (f *astTypeGenerator) checkVisibility(spec TypeSpec, typ Type) error {
+	if f.config.targetPackage == "" {
+		return nil
+	}
+
+	packagePath, _ := splitPackageVersion(spec.PackagePath)
+	packages := []string{packagePath}
+	mapType(typ, func(t Type) (Type, bool) {
+		if t.QualType != nil && t.QualType.Package != "" {
+			packages = append(packages, t.QualType.Package)
+		}
+		return t, false
+	})
+
+	seen := make(map[string]struct{})
+	for _, importPath := range packages {
+		if _, ok := seen[importPath]; ok {
+			continue
+		}
+		seen[importPath] = struct{}{}
+		if isImportable(importPath, f.config.targetPackage) {
+			continue
+		}
+
+		err := fmt.Errorf(
+			"%w: %s.%s depends on %s, which cannot be imported by %s",
+			ErrInternalPackage,
+			packagePath,
+			spec.Name,
+			importPath,
+			f.config.targetPackage,
+		)
+		if f.config.warningHandler == nil {
+			return err
+		}
+		f.warnAt(token.Position{}, "%s", err.Error())
+	}
+	return nil
+}
+
+// isImportable reports whether the package `importPath` can be imported by the package `importer`, following the
+// rules of the internal packages: a package inside an "internal" directory can only be imported by the packages
+// rooted at the parent of the "internal" directory. The internal packages of the standard library, like
+// "internal/poll", can only be imported by the standard library.
+func isImportable(importPath, importer string) bool {
+	elems := strings.Split(importPath, "/")
+	internal := -1
+	for i, elem := range elems {
+		if elem == "internal" {
+			internal = i
+		}
+	}
+	if internal < 0 {
+		return true
+	}
+
+	root := strings.Join(elems[:internal], "/")
+	if root == "" {
+		// the paths of the standard library's packages don't have a domain name.
+		return !strings.Contains(strings.Split(importer, "/")[0], ".")
+	}
+	return importer == root || strings.HasPrefix(importer, root+"/")
+}
@@ -0,0 +1,45 @@
+package gotype
+
+import (
+	"fmt"
+	"go/token"
+)
+
+// Warning describes a construct that the generator can't fully model. See `WithWarningHandler`.
+type Warning struct {
+	// Position contains the location of the construct.
+	Position token.Position
+
+	// Message describes the problem.
+	Message string
+}
+
+func (w Warning) String() string {
+	if !w.Position.IsValid() {
+		return w.Message
+	}
+	return w.Position.String() + ": " + w.Message
+}
+
+// warn reports a warning to the configured warning handler, if any.
+func (f *astTypeGenerator) warn(pos token.Pos, format string, args ...interface{}) {
+	f.warnAt(f.position(pos), format, args...)
+}
+
+// warnAt reports a warning located at `position` to the configured warning handler, if any.
+func (f *astTypeGenerator) warnAt(position token.Position, format string, args ...interface{}) {
+	if f.config.warningHandler == nil {
+		return
+	}
+	f.config.warningHandler(Warning{Position: position, Message: fmt.Sprintf(format, args...)})
+}
+
+// degrade reports `err` as a warning and returns nil when a warning handler is configured, so the generation can
+// continue. Otherwise, it returns `err` as is.
+func (f *astTypeGenerator) degrade(pos token.Pos, err error) error {
+	if f.config.warningHandler == nil {
+		return err
+	}
+	f.warn(pos, "%s", err.Error())
+	return nil
+}
@@ -0,0 +1,195 @@
+package gotype
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// cacheInvalidator is implemented by the SourceFinders caching the source files of the packages, and by the
+// SourceFinders wrapping them, so a CacheWatcher invalidates the packages whose files changed.
+type cacheInvalidator interface {
+	invalidatePackage(packagePath string)
+}
+
+// CacheWatcher watches the directories of the packages read by the generators using it, see `WithCacheWatcher`, so
+// the long-lived generators, like the ones of a daemon or of a watch mode, pick up the changed packages without being
+// recreated. When a ".go" file of a watched directory is created, written, removed or renamed, the cached source files
+// of the packages found inside the directory are invalidated, so the next calls list their files again, and the
+// handler of the watcher is called. The other packages stay cached.
+//
+// A CacheWatcher can be shared by several generators, and it must be closed once they aren't used anymore.
+type CacheWatcher struct {
+	watcher      *fsnotify.Watcher
+	onInvalidate func(packagePath string)
+	done         chan struct{}
+
+	// mu guards the watched directories and the finders.
+	mu sync.Mutex
+	// packages contains the packages found inside the watched directories, keyed by the path keys of the directories.
+	packages map[string]map[string]struct{}
+	finders  []SourceFinder
+}
+
+// NewCacheWatcher returns a CacheWatcher calling `onInvalidate`, when it's not nil, with the path of each invalidated
+// package. The handler is called by the watcher's goroutine, so it should return quickly.
+func NewCacheWatcher(onInvalidate func(packagePath string)) (*CacheWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("cannot create file watcher: %w", err)
+	}
+	w := &CacheWatcher{
+		watcher:      watcher,
+		onInvalidate: onInvalidate,
+		done:         make(chan struct{}),
+		packages:     make(map[string]map[string]struct{}),
+	}
+	go w.run()
+	return w, nil
+}
+
+// Close stops watching the directories. The cached source files aren't invalidated anymore.
+func (w *CacheWatcher) Close() error {
+	err := w.watcher.Close()
+	<-w.done
+	return err
+}
+
+func (w *CacheWatcher) run() {
+	defer close(w.done)
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			w.handle(event)
+		case _, ok := <-w.watcher.Errors:
+			// an overflow loses events, which can't be recovered: the watcher keeps invalidating the next changes.
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+func (w *CacheWatcher) handle(event fsnotify.Event) {
+	if filepath.Ext(event.Name) != ".go" || event.Op == fsnotify.Chmod {
+		return
+	}
+
+	w.mu.Lock()
+	packagePaths := make([]string, 0)
+	for packagePath := range w.packages[pathKey(filepath.Dir(event.Name))] {
+		packagePaths = append(packagePaths, packagePath)
+	}
+	finders := w.finders
+	w.mu.Unlock()
+
+	sort.Strings(packagePaths)
+	for _, packagePath := range packagePaths {
+		for _, finder := range finders {
+			if invalidator, ok := finder.(cacheInvalidator); ok {
+				invalidator.invalidatePackage(packagePath)
+			}
+		}
+		if w.onInvalidate != nil {
+			w.onInvalidate(packagePath)
+		}
+	}
+}
+
+// watch returns a SourceFinder watching the directories of the packages found by `finder`.
+func (w *CacheWatcher) watch(finder SourceFinder) SourceFinder {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.finders = append(w.finders, finder)
+	return &watchedSourceFinder{fallback: finder, watcher: w}
+}
+
+// add watches the directories of the package's source files. The directories which can't be watched, like the ones of
+// the files read from the archives, are skipped.
+func (w *CacheWatcher) add(packagePath string, sourceFiles []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, sourceFile := range sourceFiles {
+		dir := filepath.Dir(sourceFile)
+		key := pathKey(dir)
+		if _, ok := w.packages[key][packagePath]; ok {
+			continue
+		}
+		if w.packages[key] == nil {
+			if err := w.watcher.Add(dir); err != nil {
+				continue
+			}
+			w.packages[key] = make(map[string]struct{})
+		}
+		w.packages[key][packagePath] = struct{}{}
+	}
+}
+
+// watchedSourceFinder registers the packages found by its fallback to a CacheWatcher, see `WithCacheWatcher`.
+type watchedSourceFinder struct {
+	fallback SourceFinder
+	watcher  *CacheWatcher
+}
+
+func (s *watchedSourceFinder) GetPackageSourceFiles(packagePath string) ([]string, error) {
+	sourceFiles, err := s.fallback.GetPackageSourceFiles(packagePath)
+	if err != nil {
+		return nil, err
+	}
+	s.watcher.add(packagePath, sourceFiles)
+	return sourceFiles, nil
+}
+
+func (s *watchedSourceFinder) ReadSourceFile(filename string) ([]byte, error) {
+	if reader, ok := s.fallback.(sourceReader); ok {
+		return reader.ReadSourceFile(filename)
+	}
+	return ioutil.ReadFile(filename)
+}
+
+// ListPackages returns the packages matched by `pattern`, listed by the fallback SourceFinder when it's able to.
+func (s *watchedSourceFinder) ListPackages(pattern string) ([]string, error) {
+	if lister, ok := s.fallback.(packageLister); ok {
+		return lister.ListPackages(pattern)
+	}
+	return []string{pattern}, nil
+}
+
+// invalidatePackage removes the cached source files of the package, see CacheWatcher.
+func (s *defaultSourceFinder) invalidatePackage(packagePath string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cache, packagePath)
+}
+
+func (s *ChainFinder) invalidatePackage(packagePath string) {
+	invalidatePackage(packagePath, s.finders...)
+}
+
+func (s *MergeFinder) invalidatePackage(packagePath string) {
+	invalidatePackage(packagePath, s.finders...)
+}
+
+func (s *archiveSourceFinder) invalidatePackage(packagePath string) {
+	invalidatePackage(packagePath, s.fallback)
+}
+
+func (s *gitRevisionSourceFinder) invalidatePackage(packagePath string) {
+	invalidatePackage(packagePath, s.fallback)
+}
+
+// invalidatePackage invalidates the package cached by the `finders`.
+func invalidatePackage(packagePath string, finders ...SourceFinder) {
+	for _, finder := range finders {
+		if invalidator, ok := finder.(cacheInvalidator); ok {
+			invalidator.invalidatePackage(packagePath)
+		}
+	}
+}
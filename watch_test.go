@@ -0,0 +1,67 @@
+package gotype
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheWatcher(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0o755))
+		require.NoError(t, os.WriteFile(filename, []byte(content), 0o644))
+	}
+	writeFile("go.mod", "module example.com/watch\n\ngo 1.18\n")
+	writeFile("user/user.go", "package user\n\ntype User struct{}\n")
+	writeFile("order/order.go", "package order\n\ntype Order struct{}\n")
+
+	invalidated := make(chan string, 64)
+	watcher, err := NewCacheWatcher(func(packagePath string) {
+		invalidated <- packagePath
+	})
+	require.NoError(t, err)
+	defer watcher.Close()
+
+	// waitInvalidated waits for the invalidation of `expected`. A change may be reported by several events, so the
+	// previous package may be invalidated again in the meantime.
+	previous := ""
+	waitInvalidated := func(expected string) {
+		for {
+			select {
+			case packagePath := <-invalidated:
+				if packagePath == expected {
+					previous = expected
+					return
+				}
+				require.Equal(t, previous, packagePath)
+			case <-time.After(5 * time.Second):
+				require.Fail(t, "the package isn't invalidated", expected)
+			}
+		}
+	}
+
+	generator := NewGenerator(WithConfig(Config{Dir: dir}), WithCacheWatcher(watcher))
+	_, err = generator.GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: "example.com/watch/user", Name: "User"},
+		TypeSpec{PackagePath: "example.com/watch/order", Name: "Order"},
+	)
+	require.NoError(t, err)
+
+	// the new file is found once the cached source files of its package are invalidated.
+	writeFile("user/group.go", "package user\n\ntype Group struct{}\n")
+	waitInvalidated("example.com/watch/user")
+	_, err = generator.GenerateTypesFromSpecs(TypeSpec{PackagePath: "example.com/watch/user", Name: "Group"})
+	require.NoError(t, err)
+
+	// the files which aren't Golang's source files are ignored.
+	writeFile("user/README.md", "# user\n")
+	writeFile("order/order.go", "package order\n\ntype Order struct {\n\tID int\n}\n")
+	waitInvalidated("example.com/watch/order")
+
+	require.NoError(t, watcher.Close())
+}
@@ -0,0 +1,522 @@
+package gotype
+
+import (
+	"fmt"
+	"go/ast"
+	"strconv"
+	"strings"
+)
+
+// WireFormat represents a serialization whose compatibility is checked by CompareWireFormat.
+type WireFormat int
+
+const (
+	// WireFormatJSON checks the values encoded by encoding/json. The struct's fields are identified by their JSON
+	// names, which are matched case-insensitively like encoding/json does.
+	WireFormatJSON WireFormat = iota
+
+	// WireFormatProtobuf checks the protoc-generated messages encoded in the protobuf's binary format. The message's
+	// fields are identified by the numbers of their `protobuf` tags. The oneof fields aren't checked.
+	WireFormatProtobuf
+)
+
+func (f WireFormat) String() string {
+	switch f {
+	case WireFormatJSON:
+		return "json"
+	case WireFormatProtobuf:
+		return "protobuf"
+	}
+	return fmt.Sprintf("WireFormat(%d)", int(f))
+}
+
+// WireChange describes a change between two versions of a type affecting its serialization.
+type WireChange struct {
+	// Path contains the serialized path of the changed value, like "address.city", "items[]" for the elements of a
+	// list, and "labels{}" for the values of a map. It's empty for the type itself.
+	Path string
+
+	// Breaking reports whether the values written using one of the versions can't be read using the other one, or
+	// lose some of their fields.
+	Breaking bool
+
+	// Message describes the change.
+	Message string
+}
+
+func (c WireChange) String() string {
+	kind := "compatible"
+	if c.Breaking {
+		kind = "breaking"
+	}
+	if c.Path == "" {
+		return kind + ": " + c.Message
+	}
+	return kind + ": " + c.Path + ": " + c.Message
+}
+
+// WireCompatibilityReport contains the changes between two versions of a type affecting its serialization.
+type WireCompatibilityReport struct {
+	Format WireFormat
+
+	// Changes contains the changes, in the order of the old version's fields, followed by the added fields.
+	Changes []WireChange
+}
+
+// Compatible reports whether none of the changes is breaking.
+func (r WireCompatibilityReport) Compatible() bool {
+	for _, change := range r.Changes {
+		if change.Breaking {
+			return false
+		}
+	}
+	return true
+}
+
+// BreakingChanges returns the breaking changes.
+func (r WireCompatibilityReport) BreakingChanges() []WireChange {
+	changes := make([]WireChange, 0)
+	for _, change := range r.Changes {
+		if change.Breaking {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// CheckWireCompatibility compares the type specified by `typeSpec` with its version at the git revision
+// `baseRevision`. See `TypeGenerator.CheckWireCompatibility` for the details.
+func CheckWireCompatibility(
+	typeSpec TypeSpec,
+	baseRevision string,
+	format WireFormat,
+) (WireCompatibilityReport, error) {
+	return defaultAstTypeGenerator.CheckWireCompatibility(typeSpec, baseRevision, format)
+}
+
+func (f *astTypeGenerator) CheckWireCompatibility(
+	typeSpec TypeSpec,
+	baseRevision string,
+	format WireFormat,
+) (WireCompatibilityReport, error) {
+	f = f.fork()
+	// the nested types are compared by their definitions.
+	f.config.deepResolution = true
+
+	oldTypes, err := f.forkAtRevision(baseRevision).GenerateTypesFromSpecs(typeSpec)
+	if err != nil {
+		return WireCompatibilityReport{}, fmt.Errorf("cannot generate %s at %s: %w", typeSpec.Name, baseRevision, err)
+	}
+	newTypes, err := f.GenerateTypesFromSpecs(typeSpec)
+	if err != nil {
+		return WireCompatibilityReport{}, err
+	}
+	return CompareWireFormat(oldTypes[0], newTypes[0], format)
+}
+
+// CompareWireFormat compares the serializations of the `before` and the `after` versions of a type in the `format`.
+// The nested named types are compared by their definitions, so the versions are expected to be generated using
+// `WithDeepResolution`, the named types without definitions are compared by their names.
+//
+// The removed fields and the changed types are breaking, while the added fields are compatible. The types whose
+// serializations are the same are compatible, like a pointer and its element, a slice and an array, and the integers
+// widened without changing their sign, like `int32` to `int64`. In the protobuf format, the renamed fields and the
+// fields switched between `string` and `[]byte` are compatible, and the fields changing their wire type or their
+// cardinality are breaking.
+func CompareWireFormat(before, after Type, format WireFormat) (WireCompatibilityReport, error) {
+	c := &wireComparator{visited: make(map[[2]string]struct{})}
+	switch format {
+	case WireFormatJSON:
+		c.compareJSON("", before, after)
+	case WireFormatProtobuf:
+		oldMessage, newMessage := wireStruct(before), wireStruct(after)
+		if oldMessage == nil || newMessage == nil {
+			return WireCompatibilityReport{}, fmt.Errorf("cannot compare protobuf format of non-message types")
+		}
+		c.visit(before, after)
+		c.compareProtoMessages("", *oldMessage, *newMessage)
+	default:
+		return WireCompatibilityReport{}, fmt.Errorf("unknown wire format %s", format)
+	}
+	return WireCompatibilityReport{Format: format, Changes: c.changes}, nil
+}
+
+// wireComparator collects the WireChanges of the compared types.
+type wireComparator struct {
+	changes []WireChange
+
+	// visited contains the pairs of named types being compared, so the recursive types are compared once.
+	visited map[[2]string]struct{}
+}
+
+func (c *wireComparator) report(path string, breaking bool, format string, args ...interface{}) {
+	c.changes = append(c.changes, WireChange{Path: path, Breaking: breaking, Message: fmt.Sprintf(format, args...)})
+}
+
+// visit reports whether the pair of named types is compared already, and marks it as compared. The unnamed types are
+// never compared already.
+func (c *wireComparator) visit(before, after Type) bool {
+	if c.visiting(before, after) {
+		return true
+	}
+	before, after = derefType(before), derefType(after)
+	if before.QualType != nil && after.QualType != nil {
+		c.visited[[2]string{before.String(""), after.String("")}] = struct{}{}
+	}
+	return false
+}
+
+// visiting reports whether the pair of named types is compared already. The deep resolution doesn't fill the
+// definitions of the recursive references, so the named types without definitions are compared by their names,
+// ignoring their packages which differ between the compared versions.
+func (c *wireComparator) visiting(before, after Type) bool {
+	before, after = derefType(before), derefType(after)
+	if before.QualType == nil || after.QualType == nil {
+		return false
+	}
+	if before.QualType.Underlying == nil && after.QualType.Underlying == nil {
+		return before.QualType.Name == after.QualType.Name
+	}
+	_, ok := c.visited[[2]string{before.String(""), after.String("")}]
+	return ok
+}
+
+// jsonKind is the kind of a JSON value encoding a Golang's type.
+type jsonKind int
+
+const (
+	jsonKindOpaque jsonKind = iota
+	jsonKindBool
+	jsonKindInteger
+	jsonKindFloat
+	jsonKindString
+	jsonKindBytes
+	jsonKindArray
+	jsonKindMap
+	jsonKindObject
+)
+
+// jsonKindOf returns the kind of the JSON values encoding the type, and the type's definition. The named types without
+// definitions, the interfaces and time.Time are opaque.
+func jsonKindOf(typ Type) (jsonKind, Type) {
+	typ = derefType(typ)
+	if isTimeType(typ) {
+		return jsonKindOpaque, typ
+	}
+	typ = validationUnderlying(typ)
+	switch {
+	case typ.PrimitiveType != nil:
+		kind := typ.PrimitiveType.Kind
+		switch {
+		case kind == PrimitiveKindBool:
+			return jsonKindBool, typ
+		case kind.IsInteger():
+			return jsonKindInteger, typ
+		case kind.IsFloat():
+			return jsonKindFloat, typ
+		case kind.IsString():
+			return jsonKindString, typ
+		}
+	case isByteSlice(typ):
+		return jsonKindBytes, typ
+	case typ.SliceType != nil, typ.ArrayType != nil:
+		return jsonKindArray, typ
+	case typ.MapType != nil:
+		return jsonKindMap, typ
+	case typ.StructType != nil:
+		return jsonKindObject, typ
+	}
+	return jsonKindOpaque, typ
+}
+
+func (c *wireComparator) compareJSON(path string, before, after Type) {
+	if c.visiting(before, after) {
+		return
+	}
+	oldKind, oldDef := jsonKindOf(before)
+	newKind, newDef := jsonKindOf(after)
+	switch {
+	case oldKind == jsonKindOpaque || newKind == jsonKindOpaque:
+		if !Identical(derefType(before), derefType(after)) {
+			c.report(path, true, "type changed from %s to %s", before.String(""), after.String(""))
+		}
+	case oldKind == jsonKindInteger && newKind == jsonKindInteger:
+		c.compareIntegers(path, before, after, oldDef.PrimitiveType.Kind, newDef.PrimitiveType.Kind)
+	case oldKind == jsonKindFloat && newKind == jsonKindFloat:
+		if oldDef.PrimitiveType.Kind == PrimitiveKindFloat64 && newDef.PrimitiveType.Kind == PrimitiveKindFloat32 {
+			c.report(path, true, "type changed from %s to %s, the values may lose precision", before.String(""),
+				after.String(""))
+		}
+	case oldKind == jsonKindInteger && newKind == jsonKindFloat:
+		// the integers are decoded into floats, as long as they're exact.
+	case oldKind != newKind:
+		c.report(path, true, "type changed from %s to %s", before.String(""), after.String(""))
+	case oldKind == jsonKindArray:
+		c.compareJSON(path+"[]", elemType(oldDef), elemType(newDef))
+	case oldKind == jsonKindMap:
+		oldKeyKind, _ := jsonKindOf(oldDef.MapType.Key)
+		newKeyKind, _ := jsonKindOf(newDef.MapType.Key)
+		if oldKeyKind != newKeyKind {
+			c.report(path, true, "type changed from %s to %s", before.String(""), after.String(""))
+			return
+		}
+		c.compareJSON(path+"{}", oldDef.MapType.Elem, newDef.MapType.Elem)
+	case oldKind == jsonKindObject:
+		if !c.visit(before, after) {
+			c.compareJSONObjects(path, *oldDef.StructType, *newDef.StructType)
+		}
+	}
+}
+
+// jsonObjectField is a field of a struct encoded by encoding/json.
+type jsonObjectField struct {
+	name         string
+	stringOption bool
+	field        TypeField
+}
+
+// jsonObjectFields returns the fields of a struct encoded by encoding/json, with their JSON names.
+func jsonObjectFields(structType StructType) []jsonObjectField {
+	fields := make([]jsonObjectField, 0, len(structType.Fields))
+	for _, field := range structType.Fields {
+		name, ok := jsonFieldName(string(field.Tag))
+		if !ok || !ast.IsExported(field.Name) {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		options := strings.Split(field.Tag.Get("json"), ",")[1:]
+		fields = append(fields, jsonObjectField{name: name, stringOption: containsString(options, "string"), field: field})
+	}
+	return fields
+}
+
+func (c *wireComparator) compareJSONObjects(path string, before, after StructType) {
+	oldFields, newFields := jsonObjectFields(before), jsonObjectFields(after)
+	matched := make(map[string]struct{})
+	find := func(name string) (jsonObjectField, bool) {
+		for _, field := range newFields {
+			if field.name == name {
+				return field, true
+			}
+		}
+		// encoding/json matches the names case-insensitively, preferring the exact match.
+		for _, field := range newFields {
+			if _, ok := matched[field.name]; !ok && strings.EqualFold(field.name, name) {
+				return field, true
+			}
+		}
+		return jsonObjectField{}, false
+	}
+
+	for _, oldField := range oldFields {
+		fieldPath := joinWirePath(path, oldField.name)
+		newField, ok := find(oldField.name)
+		if !ok {
+			c.report(fieldPath, true, "field removed")
+			continue
+		}
+		matched[newField.name] = struct{}{}
+		if newField.name != oldField.name {
+			c.report(fieldPath, false, "field renamed to %s, which is matched case-insensitively", newField.name)
+		}
+		if oldField.stringOption != newField.stringOption {
+			c.report(fieldPath, true, "the `string` option of the json tag is changed")
+		}
+		c.compareJSON(fieldPath, oldField.field.Type, newField.field.Type)
+	}
+	for _, newField := range newFields {
+		if _, ok := matched[newField.name]; !ok {
+			c.report(joinWirePath(path, newField.name), false, "field added")
+		}
+	}
+}
+
+// compareIntegers reports the integers which are narrowed, or whose sign is changed.
+func (c *wireComparator) compareIntegers(path string, before, after Type, oldKind, newKind PrimitiveKind) {
+	oldSigned, oldBits := integerRange(oldKind)
+	newSigned, newBits := integerRange(newKind)
+	widened := (oldSigned == newSigned && newBits >= oldBits) || (!oldSigned && newSigned && newBits > oldBits)
+	if !widened {
+		c.report(path, true, "type changed from %s to %s, the values may overflow", before.String(""), after.String(""))
+	}
+}
+
+// integerRange returns whether the integer kind is signed, and its size in bits.
+func integerRange(kind PrimitiveKind) (bool, int) {
+	switch kind {
+	case PrimitiveKindInt8:
+		return true, 8
+	case PrimitiveKindInt16:
+		return true, 16
+	case PrimitiveKindInt32, PrimitiveKindRune:
+		return true, 32
+	case PrimitiveKindInt, PrimitiveKindInt64:
+		return true, 64
+	case PrimitiveKindUint8, PrimitiveKindByte:
+		return false, 8
+	case PrimitiveKindUint16:
+		return false, 16
+	case PrimitiveKindUint32:
+		return false, 32
+	}
+	return false, 64
+}
+
+// protoMessageField is a field of a protoc-generated message, described by its `protobuf` tag, like
+// `protobuf:"varint,1,opt,name=id,proto3"`.
+type protoMessageField struct {
+	number      int
+	name        string
+	encoding    string
+	cardinality string
+	field       TypeField
+}
+
+// protoMessageFields returns the fields of a protoc-generated message having a `protobuf` tag.
+func protoMessageFields(structType StructType) []protoMessageField {
+	fields := make([]protoMessageField, 0, len(structType.Fields))
+	for _, field := range structType.Fields {
+		tag, ok := field.Tag.Lookup("protobuf")
+		if !ok {
+			continue
+		}
+		options := strings.Split(tag, ",")
+		if len(options) < 3 {
+			continue
+		}
+		number, err := strconv.Atoi(options[1])
+		if err != nil {
+			continue
+		}
+		messageField := protoMessageField{
+			number:      number,
+			name:        field.Name,
+			encoding:    options[0],
+			cardinality: options[2],
+			field:       field,
+		}
+		for _, option := range options[3:] {
+			if strings.HasPrefix(option, "name=") {
+				messageField.name = strings.TrimPrefix(option, "name=")
+			}
+		}
+		fields = append(fields, messageField)
+	}
+	return fields
+}
+
+func (c *wireComparator) compareProtoMessages(path string, before, after StructType) {
+	oldFields, newFields := protoMessageFields(before), protoMessageFields(after)
+	byNumber := make(map[int]protoMessageField, len(newFields))
+	for _, field := range newFields {
+		byNumber[field.number] = field
+	}
+
+	matched := make(map[int]struct{})
+	for _, oldField := range oldFields {
+		fieldPath := joinWirePath(path, oldField.name)
+		newField, ok := byNumber[oldField.number]
+		if !ok {
+			c.report(fieldPath, true, "field %d removed", oldField.number)
+			continue
+		}
+		matched[newField.number] = struct{}{}
+		if newField.name != oldField.name {
+			c.report(fieldPath, false, "field %d renamed to %s", oldField.number, newField.name)
+		}
+		c.compareProtoFields(fieldPath, oldField, newField)
+	}
+	for _, newField := range newFields {
+		if _, ok := matched[newField.number]; !ok {
+			c.report(joinWirePath(path, newField.name), false, "field %d added", newField.number)
+		}
+	}
+}
+
+func (c *wireComparator) compareProtoFields(path string, before, after protoMessageField) {
+	zigzag := func(encoding string) bool { return encoding == "zigzag32" || encoding == "zigzag64" }
+	switch {
+	case (before.cardinality == "rep") != (after.cardinality == "rep"):
+		c.report(path, true, "field %d changed from %s to %s", before.number, protoCardinality(before.cardinality),
+			protoCardinality(after.cardinality))
+		return
+	case before.encoding != after.encoding && !(zigzag(before.encoding) && zigzag(after.encoding)):
+		c.report(path, true, "wire type of field %d changed from %s to %s", before.number, before.encoding, after.encoding)
+		return
+	}
+
+	oldType, newType := before.field.Type, after.field.Type
+	if before.cardinality == "rep" && oldType.SliceType != nil && newType.SliceType != nil {
+		oldType, newType = oldType.SliceType.Elem, newType.SliceType.Elem
+	}
+	c.compareProtoValues(path, before.number, oldType, newType)
+}
+
+// compareProtoValues compares the values of a field having the same wire type in both versions.
+func (c *wireComparator) compareProtoValues(path string, number int, before, after Type) {
+	if c.visiting(before, after) {
+		return
+	}
+	oldMessage, newMessage := wireStruct(before), wireStruct(after)
+	switch {
+	case oldMessage != nil && newMessage != nil:
+		if !c.visit(before, after) {
+			c.compareProtoMessages(path, *oldMessage, *newMessage)
+		}
+	case oldMessage != nil || newMessage != nil:
+		if !Identical(before, after) {
+			c.report(path, true, "type of field %d changed from %s to %s", number, before.String(""), after.String(""))
+		}
+	default:
+		oldDef, newDef := validationUnderlying(derefType(before)), validationUnderlying(derefType(after))
+		switch {
+		case isIntegerType(oldDef) && isIntegerType(newDef):
+			c.compareIntegers(path, before, after, oldDef.PrimitiveType.Kind, newDef.PrimitiveType.Kind)
+		case isStringType(oldDef) && isByteSlice(newDef), isByteSlice(oldDef) && isStringType(newDef):
+			c.report(path, false, "type of field %d changed from %s to %s, the bytes have to be valid UTF-8",
+				number, before.String(""), after.String(""))
+		case oldDef.MapType != nil && newDef.MapType != nil:
+			c.compareProtoValues(path+"{}", number, oldDef.MapType.Elem, newDef.MapType.Elem)
+		case !Identical(oldDef, newDef) && !isBoolType(oldDef) && !isBoolType(newDef):
+			c.report(path, true, "type of field %d changed from %s to %s", number, before.String(""), after.String(""))
+		}
+	}
+}
+
+func protoCardinality(cardinality string) string {
+	if cardinality == "rep" {
+		return "repeated"
+	}
+	return "singular"
+}
+
+// wireStruct returns the definition of a struct, or of a pointer to it, and nil for the other types.
+func wireStruct(typ Type) *StructType {
+	typ = validationUnderlying(derefType(typ))
+	return typ.StructType
+}
+
+// derefType returns the element of a pointer type, and the other types as they are.
+func derefType(typ Type) Type {
+	if typ.PtrType != nil {
+		return typ.PtrType.Elem
+	}
+	return typ
+}
+
+func isIntegerType(typ Type) bool {
+	return typ.PrimitiveType != nil && typ.PrimitiveType.Kind.IsInteger()
+}
+
+func isBoolType(typ Type) bool {
+	return typ.PrimitiveType != nil && typ.PrimitiveType.Kind == PrimitiveKindBool
+}
+
+func joinWirePath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
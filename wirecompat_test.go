@@ -0,0 +1,92 @@
+package gotype
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareWireFormat(t *testing.T) {
+	types, err := NewGenerator(WithDeepResolution()).GenerateTypesFromSpecs(
+		TypeSpec{PackagePath: testdataPackage + "/wire/v1", Name: "Event"},
+		TypeSpec{PackagePath: testdataPackage + "/wire/v2", Name: "Event"},
+		TypeSpec{PackagePath: testdataPackage + "/wire/v1", Name: "UserMessage"},
+		TypeSpec{PackagePath: testdataPackage + "/wire/v2", Name: "UserMessage"},
+	)
+	require.NoError(t, err)
+
+	report, err := CompareWireFormat(types[0], types[1], WireFormatJSON)
+	require.NoError(t, err)
+	assert.Equal(t, []WireChange{
+		{Path: "Name", Message: "field renamed to name, which is matched case-insensitively"},
+		{Path: "count", Breaking: true, Message: "type changed from int64 to int32, the values may overflow"},
+		{Path: "address.zip", Breaking: true, Message: "type changed from string to int"},
+		{Path: "address.country", Message: "field added"},
+		{Path: "labels{}", Breaking: true, Message: "type changed from string to []byte"},
+		{Path: "amount", Breaking: true, Message: "the `string` option of the json tag is changed"},
+		{Path: "legacy", Breaking: true, Message: "field removed"},
+		{Path: "created", Message: "field added"},
+	}, report.Changes)
+	assert.False(t, report.Compatible())
+	assert.Len(t, report.BreakingChanges(), 5)
+	assert.Equal(t, "breaking: legacy: field removed", report.Changes[6].String())
+
+	report, err = CompareWireFormat(types[0], types[0], WireFormatJSON)
+	require.NoError(t, err)
+	assert.Empty(t, report.Changes)
+	assert.True(t, report.Compatible())
+
+	report, err = CompareWireFormat(types[2], types[3], WireFormatProtobuf)
+	require.NoError(t, err)
+	assert.Equal(t, []WireChange{
+		{Path: "name", Message: "field 2 renamed to full_name"},
+		{Path: "email", Message: "field 3 renamed to age"},
+		{Path: "email", Breaking: true, Message: "wire type of field 3 changed from bytes to fixed32"},
+		{Path: "tags", Breaking: true, Message: "field 4 changed from repeated to singular"},
+		{Path: "avatar", Message: "type of field 6 changed from string to []byte, the bytes have to be valid UTF-8"},
+		{Path: "country", Message: "field 8 added"},
+	}, report.Changes)
+
+	_, err = CompareWireFormat(types[0].StructType.Fields[0].Type, types[3], WireFormatProtobuf)
+	assert.EqualError(t, err, "cannot compare protobuf format of non-message types")
+	_, err = CompareWireFormat(types[0], types[1], WireFormat(42))
+	assert.EqualError(t, err, "unknown wire format WireFormat(42)")
+}
+
+func TestCheckWireCompatibility(t *testing.T) {
+	repoDir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repoDir
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	writeFile := func(name, content string) {
+		filename := filepath.Join(repoDir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0o755))
+		require.NoError(t, os.WriteFile(filename, []byte(content), 0o644))
+	}
+
+	git("init", "-q")
+	writeFile("go.mod", "module example.com/repo\n\ngo 1.18\n")
+	writeFile("event/event.go", "package event\n\ntype Event struct {\n\tID int `json:\"id\"`\n}\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "v1")
+	git("tag", "v1.0.0")
+	writeFile("event/event.go", "package event\n\ntype Event struct {\n\tID string `json:\"id\"`\n}\n")
+
+	spec := TypeSpec{PackagePath: "example.com/repo/event", Name: "Event"}
+	report, err := NewGenerator(WithConfig(Config{Dir: repoDir})).CheckWireCompatibility(spec, "v1.0.0", WireFormatJSON)
+	require.NoError(t, err)
+	assert.Equal(t, []WireChange{
+		{Path: "id", Breaking: true, Message: "type changed from int to string"},
+	}, report.Changes)
+
+	_, err = NewGenerator(WithConfig(Config{Dir: repoDir})).CheckWireCompatibility(spec, "v9", WireFormatJSON)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot generate Event at v9")
+}